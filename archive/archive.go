@@ -0,0 +1,129 @@
+// Package archive decodes the EXIF data of the images stored in tar and zip
+// archives without extracting them to disk, e.g. to analyze backups.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// A ScanFunc is called by ScanTar and ScanZip for each image in an archive,
+// with the name of its entry and the result of decoding it with
+// exif.Decoder.Decode: x may be usable along with a non-critical err.
+// Returning a non-nil error stops the scan, which returns that error.
+type ScanFunc func(name string, x *exif.Exif, err error) error
+
+// signatures holds the leading bytes of the image formats exif.Decode
+// handles.
+var signatures = [][]byte{
+	{0xFF, 0xD8, 0xFF},                 // JPEG
+	[]byte("II*\x00"),                  // TIFF, little endian
+	[]byte("MM\x00*"),                  // TIFF, big endian
+	[]byte("IIU\x00"),                  // Panasonic RW2
+	[]byte("IIRO"),                     // Olympus ORF, little endian
+	[]byte("IIRS"),                     // Olympus ORF, little endian
+	[]byte("MMOR"),                     // Olympus ORF, big endian
+	[]byte("FOVb"),                     // Sigma X3F
+	[]byte("8BPS"),                     // Photoshop PSD
+	[]byte("Exif\x00\x00"),             // raw EXIF data
+	{0x00, 0x00, 0x00, 0x0C, 'J', 'X'}, // JPEG XL container
+}
+
+// headerLen is the number of leading bytes IsImage needs.
+const headerLen = 8
+
+// IsImage reports whether head, the first bytes of a file, starts with the
+// signature of an image format exif.Decode handles. ISO BMFF files (e.g.
+// CR3) are recognized by the "ftyp" box type at offset 4.
+func IsImage(head []byte) bool {
+	for _, sig := range signatures {
+		if bytes.HasPrefix(head, sig) {
+			return true
+		}
+	}
+	return len(head) >= 8 && string(head[4:8]) == "ftyp"
+}
+
+// ScanTar reads the tar archive in r, which may be gzip-compressed, and
+// calls fn for each regular file detected as an image by IsImage, in
+// archive order, decoding it with dec (or exif.Decode if dec is nil). Other
+// entries are skipped. Only errors reading the archive itself, or returned by
+// fn, stop the scan.
+func ScanTar(r io.Reader, dec *exif.Decoder, fn ScanFunc) error {
+	br := bufio.NewReader(r)
+	if head, _ := br.Peek(2); bytes.Equal(head, []byte{0x1F, 0x8B}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		r = br
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := scanEntry(hdr.Name, tr, dec, fn); err != nil {
+			return err
+		}
+	}
+}
+
+// ScanZip reads the zip archive in r, which is size bytes long, and calls fn
+// for each file detected as an image by IsImage, in archive order, decoding
+// it with dec (or exif.Decode if dec is nil). Other entries are skipped.
+// Unlike tar archives, zip archives cannot be read as a stream: their
+// directory is stored at the end. Only errors reading the archive itself, or
+// returned by fn, stop the scan.
+func ScanZip(r io.ReaderAt, size int64, dec *exif.Decoder, fn ScanFunc) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = scanEntry(f.Name, rc, dec, fn)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanEntry decodes the entry read from r and passes it to fn if it is an
+// image.
+func scanEntry(name string, r io.Reader, dec *exif.Decoder, fn ScanFunc) error {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(headerLen)
+	if !IsImage(head) {
+		return nil
+	}
+	if dec == nil {
+		dec = new(exif.Decoder)
+	}
+	x, err := dec.Decode(br)
+	return fn(name, x, err)
+}
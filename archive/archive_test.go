@@ -0,0 +1,121 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// entries lists the files of the test archives: an image, a text file with
+// an image extension and an image without one.
+func entries(t *testing.T) map[string][]byte {
+	jpeg, err := os.ReadFile(filepath.Join("..", "exif", "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string][]byte{
+		"a/sample1.jpg": jpeg,
+		"a/notes.jpg":   []byte("not an image"),
+		"b/noext":       jpeg,
+	}
+}
+
+var order = []string{"a/sample1.jpg", "a/notes.jpg", "b/noext"}
+
+func collect(t *testing.T, scan func(ScanFunc) error) []string {
+	var names []string
+	err := scan(func(name string, x *exif.Exif, err error) error {
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if _, err := x.Get(exif.Model); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func TestScanTar(t *testing.T) {
+	files := entries(t)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "a/", Typeflag: tar.TypeDir, Mode: 0755})
+	for _, name := range order {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(files[name]))})
+		tw.Write(files[name])
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"a/sample1.jpg", "b/noext"}
+	got := collect(t, func(fn ScanFunc) error {
+		return ScanTar(bytes.NewReader(buf.Bytes()), new(exif.Decoder), fn)
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(buf.Bytes())
+	zw.Close()
+	got = collect(t, func(fn ScanFunc) error {
+		return ScanTar(&gz, new(exif.Decoder), fn)
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("gzipped: got %q, want %q", got, want)
+	}
+
+	stop := errors.New("stop")
+	err := ScanTar(bytes.NewReader(buf.Bytes()), new(exif.Decoder), func(string, *exif.Exif, error) error {
+		return stop
+	})
+	if err != stop {
+		t.Errorf("got error %v, want the one returned by fn", err)
+	}
+}
+
+func TestScanZip(t *testing.T) {
+	files := entries(t)
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range order {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(files[name])
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := collect(t, func(fn ScanFunc) error {
+		return ScanZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()), new(exif.Decoder), fn)
+	})
+	if want := []string{"a/sample1.jpg", "b/noext"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	nilDec := collect(t, func(fn ScanFunc) error {
+		return ScanZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()), nil, fn)
+	})
+	if !reflect.DeepEqual(nilDec, got) {
+		t.Errorf("nil decoder: got %q, want %q", nilDec, got)
+	}
+	if err := ScanZip(bytes.NewReader([]byte("junk")), 4, new(exif.Decoder), nil); err == nil {
+		t.Error("no error scanning a non-zip stream")
+	}
+}
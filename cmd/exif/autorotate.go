@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"image/jpeg"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/jpegstructure"
+)
+
+func autorotate(args []string) bool {
+	fs := flag.NewFlagSet("autorotate", flag.ExitOnError)
+	tagOnly := fs.Bool("tagonly", false, "only reset the Orientation tag, for images whose pixels already are upright, instead of rotating the pixels of JPEG images")
+	quality := fs.Int("quality", 95, "JPEG quality of re-encoded images")
+	dryRun := fs.Bool("n", false, "print what would be done without changing any file")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal(usage)
+	}
+
+	var failed bool
+	for _, fname := range fs.Args() {
+		msg, err := rotateFile(fname, !*tagOnly, *quality, *dryRun)
+		switch {
+		case err != nil:
+			log.Printf("%v: %v", fname, err)
+			failed = true
+		case msg != "":
+			fmt.Printf("%v: %v\n", fname, msg)
+		}
+	}
+	return failed
+}
+
+// rotateFile makes the image in the named file upright with Orientation 1
+// and returns a description of what was done, or "" if the image already
+// was upright.
+func rotateFile(name string, reencode bool, quality int, dryRun bool) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil && exif.IsCriticalError(err) {
+		return "", err
+	}
+	orientation, err := x.GetInt64(exif.Orientation)
+	if err != nil || orientation <= 1 || orientation > 8 {
+		return "", nil
+	}
+
+	msg := fmt.Sprintf("Orientation %d reset to 1", orientation)
+	if reencode {
+		msg = fmt.Sprintf("rotated upright from Orientation %d", orientation)
+	}
+	if reencode && !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+		return "", errors.New("only JPEG images can be rotated, use -tagonly if the pixels already are upright")
+	}
+	if dryRun {
+		return "would be " + msg, nil
+	}
+
+	if reencode {
+		if data, err = rotatePixels(data, quality); err != nil {
+			return "", err
+		}
+		if x, err = exif.Decode(bytes.NewReader(data)); err != nil && exif.IsCriticalError(err) {
+			return "", err
+		}
+	}
+	w := writerAt(data)
+	if err := x.PatchInt(w, exif.Orientation, 1); err != nil {
+		return "", err
+	}
+	if reencode && orientation >= 5 {
+		// the transpositions swap width and height
+		width, err1 := x.GetInt64(exif.PixelXDimension)
+		height, err2 := x.GetInt64(exif.PixelYDimension)
+		if err1 == nil && err2 == nil {
+			x.PatchInt(w, exif.PixelXDimension, height)
+			x.PatchInt(w, exif.PixelYDimension, width)
+		}
+	}
+	return msg, writeFile(name, data)
+}
+
+// rotatePixels returns the JPEG image data re-encoded upright, with the
+// metadata segments of data (APPn and COM segments, such as the EXIF, XMP,
+// ICC profile and IPTC segments) copied unchanged, except for the Adobe
+// APP14 segment, which describes the color encoding of the replaced image
+// data.
+func rotatePixels(data []byte, quality int) ([]byte, error) {
+	orig, err := jpegstructure.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := exif.DecodeImageOriented(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var upright, out bytes.Buffer
+	if err := jpeg.Encode(&upright, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	s, err := jpegstructure.Parse(&upright)
+	if err != nil {
+		return nil, err
+	}
+	var segs []jpegstructure.Segment
+	for _, seg := range orig.Segments {
+		if metadata(seg) && !(seg.Marker == adobeAPP14 && bytes.HasPrefix(seg.Data, []byte("Adobe"))) {
+			segs = append(segs, seg)
+		}
+	}
+	for _, seg := range s.Segments {
+		if !metadata(seg) {
+			segs = append(segs, seg)
+		}
+	}
+	s.Segments = segs
+	if _, err := s.WriteTo(&out); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// adobeAPP14 is the marker of the Adobe segment, which records the color
+// transform of the image data.
+const adobeAPP14 = 0xEE
+
+// metadata reports whether seg is an APPn or COM segment.
+func metadata(seg jpegstructure.Segment) bool {
+	return seg.Marker >= jpegstructure.APP0 && seg.Marker <= jpegstructure.APP15 || seg.Marker == jpegstructure.COM
+}
+
+// writerAt is an io.WriterAt writing into a byte slice, used to patch the
+// tags of files held in memory.
+type writerAt []byte
+
+func (w writerAt) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(w)) {
+		return 0, errors.New("write outside of the data")
+	}
+	return copy(w[off:], p), nil
+}
+
+// writeFile replaces the named file with data by renaming a temporary file
+// in the same directory over it, so that the file is never left partially
+// written, e.g. when a batch is interrupted.
+func writeFile(name string, data []byte) error {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(fi.Mode()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/mknote"
+	"github.com/rwcarlsen/goexif/stats"
+)
+
+// defaultIndexFields are the fields index catalogues by default.
+var defaultIndexFields = []exif.FieldName{
+	exif.DateTimeOriginal, exif.Make, exif.Model, exif.LensModel,
+	exif.FNumber, exif.ExposureTime, exif.ISOSpeedRatings, exif.FocalLength,
+	exif.PixelXDimension, exif.PixelYDimension,
+}
+
+var valueStyles = map[string]exif.ValueStyle{
+	"raw":     exif.ValueRaw,
+	"numeric": exif.ValueNumeric,
+	"human":   exif.ValueHuman,
+}
+
+// A row holds the catalogued fields of a file.
+type row struct {
+	path   string
+	values map[string]interface{}
+}
+
+func index(args []string) bool {
+	fs := flag.NewFlagSet("index", flag.ExitOnError)
+	format := fs.String("format", "csv", "output format: csv or sql")
+	fieldList := fs.String("fields", joinFields(defaultIndexFields), "comma separated fields to catalogue")
+	style := fs.String("values", "numeric", "value rendering: raw, numeric or human")
+	table := fs.String("table", "photos", "table created by the sql format")
+	mnote := fs.Bool("mknote", false, "try to parse makernote data")
+	workers := fs.Int("j", 0, "number of files to decode concurrently (default: number of CPUs)")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal(usage)
+	}
+	if *format != "csv" && *format != "sql" {
+		log.Fatalf("unknown format %q, want csv or sql", *format)
+	}
+	values, ok := valueStyles[*style]
+	if !ok {
+		log.Fatalf("unknown value style %q, want raw, numeric or human", *style)
+	}
+	var fields []string
+	for _, f := range strings.Split(*fieldList, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	if *mnote {
+		exif.RegisterParsers(mknote.All...)
+	}
+
+	var rows []row
+	var failed bool
+	for _, root := range fs.Args() {
+		err := stats.Each(root, new(exif.Decoder), *workers, func(path string, x *exif.Exif) {
+			if x == nil {
+				log.Printf("%v: cannot decode EXIF data", path)
+				failed = true
+				return
+			}
+			rows = append(rows, row{path, x.ToMap(exif.MapOptions{Values: values})})
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].path < rows[j].path })
+
+	var err error
+	if *format == "csv" {
+		err = writeCSV(os.Stdout, fields, rows)
+	} else {
+		err = writeSQL(os.Stdout, *table, fields, rows)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	return failed
+}
+
+func joinFields(names []exif.FieldName) string {
+	s := make([]string, len(names))
+	for i, n := range names {
+		s[i] = string(n)
+	}
+	return strings.Join(s, ",")
+}
+
+// writeCSV writes rows as CSV records with a header row naming the columns.
+// Missing fields are empty.
+func writeCSV(w io.Writer, fields []string, rows []row) error {
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"path"}, fields...))
+	for _, r := range rows {
+		rec := []string{r.path}
+		for _, f := range fields {
+			rec = append(rec, text(r.values[f]))
+		}
+		cw.Write(rec)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeSQL writes rows as SQL statements, in the dialect of SQLite, creating
+// table with a column for the path and each field and filling it in a single
+// transaction. Numbers are stored as numbers, missing fields as NULL and
+// everything else as text.
+func writeSQL(w io.Writer, table string, fields []string, rows []row) error {
+	cols := []string{quoteIdent("path") + " TEXT PRIMARY KEY"}
+	for _, f := range fields {
+		cols = append(cols, quoteIdent(f))
+	}
+	fmt.Fprintf(w, "BEGIN;\nCREATE TABLE IF NOT EXISTS %s (%s);\n", quoteIdent(table), strings.Join(cols, ", "))
+	names := []string{quoteIdent("path")}
+	for _, f := range fields {
+		names = append(names, quoteIdent(f))
+	}
+	for _, r := range rows {
+		vals := []string{quoteString(r.path)}
+		for _, f := range fields {
+			vals = append(vals, sqlValue(r.values[f]))
+		}
+		if _, err := fmt.Fprintf(w, "INSERT OR REPLACE INTO %s (%s) VALUES (%s);\n", quoteIdent(table), strings.Join(names, ", "), strings.Join(vals, ", ")); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "COMMIT;")
+	return err
+}
+
+// text returns a value of exif.Exif.ToMap as text, with the elements of
+// slices separated by spaces.
+func text(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []int64:
+		s := make([]string, len(v))
+		for i, n := range v {
+			s[i] = strconv.FormatInt(n, 10)
+		}
+		return strings.Join(s, " ")
+	case []float64:
+		s := make([]string, len(v))
+		for i, f := range v {
+			s[i] = strconv.FormatFloat(f, 'g', -1, 64)
+		}
+		return strings.Join(s, " ")
+	case []string:
+		return strings.Join(v, " ")
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func sqlValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case json.Number:
+		return string(v)
+	}
+	return quoteString(text(v))
+}
+
+func quoteString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
@@ -0,0 +1,151 @@
+// Command exif compares, validates and catalogues the EXIF metadata of
+// images, e.g. to check the output of image pipelines in CI, and normalizes
+// their orientation.
+//
+// Usage:
+//
+//	exif diff [-mknote] file1 file2
+//	exif verify [-schema exif2.32] [-mknote] file...
+//	exif index [-format csv|sql] [-fields f1,f2,...] [-values raw|numeric|human] [-table name] [-mknote] dir...
+//	exif autorotate [-n] [-tagonly] [-quality 95] file...
+//
+// diff prints the fields that differ between the two files and exits with
+// status 1 if there are any. verify checks each file against the schema and
+// the layout of its tiff data (see exif.Exif.Validate and exif.Exif.Verify),
+// prints the problems found and exits with status 1 if there are any. index
+// walks the directory trees and prints a catalogue of the selected fields of
+// each image, one row per file sorted by path: CSV records with a header
+// row, or SQL statements creating and filling a table, to be run with e.g.
+// "sqlite3 photos.db". Files that fail to decode are reported on stderr.
+//
+// autorotate makes each file that is not upright upright with Orientation 1
+// and prints what was done. By default JPEG images are decoded, rotated
+// upright and re-encoded, which is lossy, keeping their metadata segments;
+// other formats fail. With -tagonly, only the tag is patched in place, for
+// images whose pixels already are upright (e.g. after rotating them in a
+// tool that kept the tag). Files are replaced atomically, and -n prints what
+// would be done without changing any file. It exits with status 1 if any
+// file failed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/mknote"
+)
+
+const usage = `usage:
+	exif diff [-mknote] file1 file2
+	exif verify [-schema name] [-mknote] file...
+	exif index [-format csv|sql] [-fields list] [-values style] [-table name] [-mknote] dir...
+	exif autorotate [-n] [-tagonly] [-quality n] file...`
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+	var failed bool
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "diff":
+		failed = diff(args)
+	case "verify":
+		failed = verify(args)
+	case "index":
+		failed = index(args)
+	case "autorotate":
+		failed = autorotate(args)
+	default:
+		log.Fatalf("unknown command %q\n%s", cmd, usage)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func diff(args []string) bool {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	mnote := fs.Bool("mknote", false, "try to parse makernote data")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		log.Fatal(usage)
+	}
+	if *mnote {
+		exif.RegisterParsers(mknote.All...)
+	}
+
+	a, err := decode(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := decode(fs.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	diffs := exif.Diff(a, b)
+	for _, d := range diffs {
+		fmt.Println(d)
+	}
+	return len(diffs) > 0
+}
+
+func verify(args []string) bool {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	name := fs.String("schema", "exif2.32", "schema to validate the fields against: "+schemaNames())
+	mnote := fs.Bool("mknote", false, "try to parse makernote data")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		log.Fatal(usage)
+	}
+	validate, ok := exif.Validators[*name]
+	if !ok {
+		log.Fatalf("unknown schema %q, want one of %s", *name, schemaNames())
+	}
+	if *mnote {
+		exif.RegisterParsers(mknote.All...)
+	}
+
+	var failed bool
+	for _, fname := range fs.Args() {
+		x, err := decode(fname)
+		if err != nil {
+			log.Print(err)
+			failed = true
+			continue
+		}
+		problems := append(validate(x), x.Verify()...)
+		for _, err := range problems {
+			fmt.Printf("%v: %v\n", fname, err)
+		}
+		failed = failed || len(problems) > 0
+	}
+	return failed
+}
+
+func decode(name string) (*exif.Exif, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	x, err := exif.Decode(f)
+	if err != nil && exif.IsCriticalError(err) {
+		return nil, fmt.Errorf("%v: %v", name, err)
+	}
+	return x, nil
+}
+
+func schemaNames() string {
+	var names []string
+	for name := range exif.Validators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,60 @@
+// Command exifstats scans directory trees of images and reports aggregate
+// EXIF statistics: how often each tag is present, camera model counts, and
+// focal length and ISO speed histograms.
+//
+// Usage:
+//
+//	exifstats [-format csv|json] [-mknote] [-lenient] dir...
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/mknote"
+	"github.com/rwcarlsen/goexif/stats"
+)
+
+var format = flag.String("format", "csv", "output format: csv or json")
+var mnote = flag.Bool("mknote", false, "try to parse makernote data")
+var lenient = flag.Bool("lenient", false, "skip undecodable tags instead of failing the whole file")
+var workers = flag.Int("j", 0, "number of files to decode concurrently (default: number of CPUs)")
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: exifstats [flags] dir...")
+	}
+	if *format != "csv" && *format != "json" {
+		log.Fatalf("unknown format %q", *format)
+	}
+
+	if *mnote {
+		exif.RegisterParsers(mknote.All...)
+	}
+
+	s := stats.New()
+	s.Workers = *workers
+	dec := &exif.Decoder{Lenient: *lenient}
+	for _, root := range flag.Args() {
+		if err := s.Scan(root, dec); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	switch *format {
+	case "csv":
+		if err := s.WriteCSV(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(s); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package exif
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrNoAdobe is returned by Exif.Adobe when the JPEG stream has no Adobe
+// APP14 segment.
+var ErrNoAdobe = errors.New("exif: no Adobe segment")
+
+// AdobeTransform is the color transform flag of the Adobe APP14 segment,
+// telling how the JPEG components were encoded. Color management needs it
+// alongside the ICC profile to interpret the pixels of 3 and 4 component
+// images.
+type AdobeTransform byte
+
+const (
+	// AdobeUnknown means no transform: the components are RGB for 3
+	// components and CMYK for 4 components.
+	AdobeUnknown AdobeTransform = 0
+	// AdobeYCbCr means 3 YCbCr components.
+	AdobeYCbCr AdobeTransform = 1
+	// AdobeYCCK means 4 YCCK components, YCbCr plus black, as written for
+	// CMYK images by Photoshop.
+	AdobeYCCK AdobeTransform = 2
+)
+
+var adobeTransformNames = map[AdobeTransform]string{
+	AdobeUnknown: "Unknown (RGB or CMYK)",
+	AdobeYCbCr:   "YCbCr",
+	AdobeYCCK:    "YCCK",
+}
+
+func (t AdobeTransform) String() string {
+	if name, ok := adobeTransformNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("AdobeTransform(%d)", byte(t))
+}
+
+// Adobe holds the data of an Adobe APP14 segment.
+type Adobe struct {
+	// Version is the DCT encoder version, usually 100.
+	Version        uint16
+	Flags0, Flags1 uint16
+	Transform      AdobeTransform
+}
+
+// Adobe parses seg as an Adobe APP14 segment. It returns ErrNoAdobe if seg
+// is not one.
+func (seg *Segment) Adobe() (*Adobe, error) {
+	if seg.Marker != jpeg_APP14 || len(seg.Data) < 5 || string(seg.Data[:5]) != "Adobe" {
+		return nil, ErrNoAdobe
+	}
+	b := seg.Data
+	if len(b) < 12 {
+		return nil, errors.New("exif: Adobe segment too short")
+	}
+	return &Adobe{
+		Version:   binary.BigEndian.Uint16(b[5:]),
+		Flags0:    binary.BigEndian.Uint16(b[7:]),
+		Flags1:    binary.BigEndian.Uint16(b[9:]),
+		Transform: AdobeTransform(b[11]),
+	}, nil
+}
+
+// Adobe returns the data of the first Adobe APP14 segment of the JPEG stream
+// the EXIF data was decoded from, or ErrNoAdobe.
+func (x *Exif) Adobe() (*Adobe, error) {
+	for i := range x.segments {
+		if a, err := x.segments[i].Adobe(); err != ErrNoAdobe {
+			return a, err
+		}
+	}
+	return nil, ErrNoAdobe
+}
@@ -0,0 +1,425 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNoAuxImages is returned by ExtractAuxImages for files without
+// auxiliary images.
+var ErrNoAuxImages = errors.New("exif: no auxiliary images found")
+
+// AuxKind is the kind of an auxiliary image.
+type AuxKind int
+
+const (
+	AuxOther AuxKind = iota
+	// AuxDepth images hold the depth (or disparity) of each pixel of the
+	// primary image, as captured by portrait modes.
+	AuxDepth
+	// AuxConfidence images hold the confidence of the values of a depth
+	// image.
+	AuxConfidence
+	// AuxAlpha images hold the transparency of the primary image.
+	AuxAlpha
+	// AuxMatte images are segmentation mattes, such as the portrait effects
+	// and skin, hair or sky mattes of Apple cameras.
+	AuxMatte
+	// AuxGainMap images map the primary image to an HDR rendition.
+	AuxGainMap
+)
+
+var auxKindNames = map[AuxKind]string{
+	AuxOther:      "other",
+	AuxDepth:      "depth",
+	AuxConfidence: "confidence",
+	AuxAlpha:      "alpha",
+	AuxMatte:      "matte",
+	AuxGainMap:    "gain map",
+}
+
+func (k AuxKind) String() string {
+	if s, ok := auxKindNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("AuxKind(%d)", int(k))
+}
+
+// An AuxImage is an auxiliary image stored alongside the primary image of a
+// file, such as a depth map, for computational photography.
+type AuxImage struct {
+	Kind AuxKind
+	// Type is the type of the image given by the file: the URN of the auxC
+	// property of HEIF items (e.g.
+	// "urn:com:apple:photo:2018:aux:portraiteffectsmatte"), or the item
+	// semantic of Dynamic Depth containers (e.g. "Depth").
+	Type string
+	// Codec is the item type of HEIF items, e.g. "hvc1", "av01" or "jpeg",
+	// and Config the payload of the codec configuration property needed to
+	// decode Data, such as the hvcC property of HEVC coded items.
+	Codec  string
+	Config []byte
+	// MIME is the media type of Data, if known, e.g. "image/jpeg".
+	MIME string
+	// Item is the HEIF item ID of the image, and Of that of the image it is
+	// auxiliary to, or 0.
+	Item, Of uint32
+	// Width and Height are the dimensions of the image, or 0 if unknown.
+	Width, Height int
+	// Offset is the offset of Data in the file, or -1 if Data is not
+	// contiguous in the file.
+	Offset int64
+	Data   []byte
+}
+
+// auxKinds maps the standard auxC types of HEIF auxiliary images to their
+// kind.
+var auxKinds = map[string]AuxKind{
+	"urn:mpeg:hevc:2015:auxid:1":                  AuxAlpha,
+	"urn:mpeg:hevc:2015:auxid:2":                  AuxDepth,
+	"urn:mpeg:mpegB:cicp:systems:auxiliary:alpha": AuxAlpha,
+	"urn:mpeg:mpegB:cicp:systems:auxiliary:depth": AuxDepth,
+}
+
+// auxKind returns the kind of HEIF auxiliary images of type typ.
+func auxKind(typ string) AuxKind {
+	if k, ok := auxKinds[typ]; ok {
+		return k
+	}
+	switch {
+	case strings.HasSuffix(typ, "matte"):
+		return AuxMatte
+	case strings.HasSuffix(typ, "gainmap"):
+		return AuxGainMap
+	case strings.HasSuffix(typ, "depth"), strings.HasSuffix(typ, "disparity"):
+		return AuxDepth
+	}
+	return AuxOther
+}
+
+// ExtractAuxImages returns the auxiliary images of the HEIF (e.g. HEIC)
+// file in r: the items linked to another by an auxl reference, with the
+// type of their auxC property. The depth maps and portrait mattes of Apple
+// cameras are stored this way. Dynamic Depth JPEG files store their depth
+// maps after the primary image, as described by their XMP packet: see the
+// xmp package. ErrNoAuxImages is returned if r holds no auxiliary image.
+func ExtractAuxImages(r io.Reader) ([]AuxImage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 8 || string(data[4:8]) != "ftyp" {
+		return nil, ErrNoAuxImages
+	}
+	var meta *heifMeta
+	err = eachBox(data, func(typ string, payload []byte) error {
+		if typ == "meta" && meta == nil && len(payload) >= 4 {
+			var err error
+			meta, err = parseHeifMeta(payload[4:])
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, ErrNoAuxImages
+	}
+
+	var imgs []AuxImage
+	for _, ref := range meta.refs {
+		if ref.typ != "auxl" || len(ref.to) == 0 {
+			continue
+		}
+		img := AuxImage{Item: ref.from, Of: ref.to[0], Codec: meta.types[ref.from], Offset: -1}
+		if img.Codec == "jpeg" {
+			img.MIME = "image/jpeg"
+		}
+		for _, p := range meta.itemProps(ref.from) {
+			switch p.typ {
+			case "auxC":
+				if len(p.data) > 4 {
+					img.Type = string(cstring(p.data[4:]))
+				}
+			case "ispe":
+				if len(p.data) >= 12 {
+					img.Width = int(binary.BigEndian.Uint32(p.data[4:]))
+					img.Height = int(binary.BigEndian.Uint32(p.data[8:]))
+				}
+			case "hvcC", "av1C", "jpgC":
+				img.Config = p.data
+			}
+		}
+		img.Kind = auxKind(img.Type)
+		img.Data, img.Offset, err = meta.itemData(data, ref.from)
+		if err != nil {
+			return nil, err
+		}
+		imgs = append(imgs, img)
+	}
+	if len(imgs) == 0 {
+		return nil, ErrNoAuxImages
+	}
+	return imgs, nil
+}
+
+// cstring returns b up to its first NUL byte.
+func cstring(b []byte) []byte {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		return b[:i]
+	}
+	return b
+}
+
+// heifMeta holds the content of a HEIF meta box.
+type heifMeta struct {
+	primary uint32
+	props   []heifProp
+	assoc   map[uint32][]int // 1-based property indices by item ID
+	types   map[uint32]string
+	locs    map[uint32]heifLoc
+	refs    []heifRef
+	idat    []byte
+}
+
+type heifRef struct {
+	typ  string
+	from uint32
+	to   []uint32
+}
+
+// A heifLoc is the location of the data of an item given by an iloc box.
+type heifLoc struct {
+	method  int // 0 for file offsets, 1 for offsets in the idat box
+	extents [][2]uint64
+}
+
+// parseHeifMeta parses meta, the payload of a HEIF meta box after its
+// version and flags.
+func parseHeifMeta(meta []byte) (*heifMeta, error) {
+	m := &heifMeta{assoc: map[uint32][]int{}, types: map[uint32]string{}, locs: map[uint32]heifLoc{}}
+	err := eachBox(meta, func(typ string, payload []byte) error {
+		switch typ {
+		case "pitm":
+			if len(payload) >= 6 && payload[0] == 0 {
+				m.primary = uint32(binary.BigEndian.Uint16(payload[4:]))
+			} else if len(payload) >= 8 {
+				m.primary = binary.BigEndian.Uint32(payload[4:])
+			}
+		case "iprp":
+			return eachBox(payload, func(typ string, payload []byte) error {
+				switch typ {
+				case "ipco":
+					return eachBox(payload, func(typ string, payload []byte) error {
+						m.props = append(m.props, heifProp{typ, payload})
+						return nil
+					})
+				case "ipma":
+					return parseIpma(payload, m.assoc)
+				}
+				return nil
+			})
+		case "iinf":
+			return m.parseIinf(payload)
+		case "iloc":
+			return m.parseIloc(payload)
+		case "iref":
+			return m.parseIref(payload)
+		case "idat":
+			m.idat = payload
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// itemProps returns the properties associated with item.
+func (m *heifMeta) itemProps(item uint32) []heifProp {
+	var props []heifProp
+	for _, i := range m.assoc[item] {
+		if i >= 1 && i <= len(m.props) {
+			props = append(props, m.props[i-1])
+		}
+	}
+	return props
+}
+
+// parseIinf records the item types of the infe boxes of the payload of an
+// iinf box.
+func (m *heifMeta) parseIinf(b []byte) error {
+	if len(b) < 6 {
+		return errors.New("exif: HEIC iinf box too short")
+	}
+	entries := b[6:]
+	if b[0] != 0 {
+		if len(b) < 8 {
+			return errors.New("exif: HEIC iinf box too short")
+		}
+		entries = b[8:]
+	}
+	return eachBox(entries, func(typ string, p []byte) error {
+		if typ != "infe" || len(p) < 4 || p[0] < 2 {
+			// versions 0 and 1 have no item type
+			return nil
+		}
+		var item uint32
+		if p[0] == 2 && len(p) >= 12 {
+			item, p = uint32(binary.BigEndian.Uint16(p[4:])), p[8:]
+		} else if p[0] == 3 && len(p) >= 14 {
+			item, p = binary.BigEndian.Uint32(p[4:]), p[10:]
+		} else {
+			return errors.New("exif: HEIC infe box too short")
+		}
+		m.types[item] = string(p[:4])
+		return nil
+	})
+}
+
+// parseIloc records the item locations of the payload of an iloc box.
+func (m *heifMeta) parseIloc(b []byte) error {
+	errShort := errors.New("exif: HEIC iloc box too short")
+	if len(b) < 8 {
+		return errShort
+	}
+	version := b[0]
+	offSize, lenSize := int(b[4]>>4), int(b[4]&15)
+	baseSize, idxSize := int(b[5]>>4), int(b[5]&15)
+	if version == 0 {
+		idxSize = 0
+	}
+	pos := 6
+	next := func(size int) (uint64, bool) {
+		if size != 0 && size != 2 && size != 4 && size != 8 || pos+size > len(b) {
+			return 0, false
+		}
+		var v uint64
+		for _, c := range b[pos : pos+size] {
+			v = v<<8 | uint64(c)
+		}
+		pos += size
+		return v, true
+	}
+	idSize := 2
+	if version == 2 {
+		idSize = 4
+	}
+	n, ok := next(idSize)
+	if !ok {
+		return errShort
+	}
+	for ; n > 0; n-- {
+		item, ok := next(idSize)
+		var loc heifLoc
+		if ok && version >= 1 {
+			var method uint64
+			method, ok = next(2)
+			loc.method = int(method & 15)
+		}
+		var base, count uint64
+		if ok {
+			_, ok = next(2) // data reference index
+		}
+		if ok {
+			base, ok = next(baseSize)
+		}
+		if ok {
+			count, ok = next(2)
+		}
+		for ; ok && count > 0; count-- {
+			var off, length uint64
+			if _, ok = next(idxSize); ok {
+				off, ok = next(offSize)
+			}
+			if ok {
+				length, ok = next(lenSize)
+			}
+			loc.extents = append(loc.extents, [2]uint64{base + off, length})
+		}
+		if !ok {
+			return errShort
+		}
+		m.locs[uint32(item)] = loc
+	}
+	return nil
+}
+
+// parseIref records the item references of the payload of an iref box.
+func (m *heifMeta) parseIref(b []byte) error {
+	if len(b) < 4 {
+		return errors.New("exif: HEIC iref box too short")
+	}
+	idSize := 2
+	if b[0] != 0 {
+		idSize = 4
+	}
+	id := func(b []byte) uint32 {
+		if idSize == 2 {
+			return uint32(binary.BigEndian.Uint16(b))
+		}
+		return binary.BigEndian.Uint32(b)
+	}
+	return eachBox(b[4:], func(typ string, p []byte) error {
+		if len(p) < 2*idSize+2 {
+			return errors.New("exif: HEIC iref box too short")
+		}
+		ref := heifRef{typ: typ, from: id(p)}
+		n := int(binary.BigEndian.Uint16(p[idSize:]))
+		p = p[idSize+2:]
+		if len(p) < n*idSize {
+			return errors.New("exif: HEIC iref box too short")
+		}
+		for i := 0; i < n; i++ {
+			ref.to = append(ref.to, id(p[i*idSize:]))
+		}
+		m.refs = append(m.refs, ref)
+		return nil
+	})
+}
+
+// itemData returns the data of item in the HEIF file data and its offset in
+// data, or -1 if it is not a single extent of data.
+func (m *heifMeta) itemData(data []byte, item uint32) ([]byte, int64, error) {
+	loc, ok := m.locs[item]
+	if !ok {
+		return nil, 0, fmt.Errorf("exif: no location for HEIC item %d", item)
+	}
+	src := data
+	switch loc.method {
+	case 0:
+	case 1:
+		src = m.idat
+	default:
+		return nil, 0, fmt.Errorf("exif: unsupported construction method %d of HEIC item %d", loc.method, item)
+	}
+	var out []byte
+	offset := int64(-1)
+	for _, e := range loc.extents {
+		off, n := e[0], e[1]
+		if off > uint64(len(src)) {
+			return nil, 0, fmt.Errorf("exif: HEIC item %d data out of range", item)
+		}
+		if n == 0 {
+			// the extent runs to the end of the source
+			n = uint64(len(src)) - off
+		}
+		if n > uint64(len(src))-off {
+			return nil, 0, fmt.Errorf("exif: HEIC item %d data out of range", item)
+		}
+		if len(loc.extents) == 1 {
+			if loc.method == 0 {
+				offset = int64(off)
+			}
+			return src[off : off+n], offset, nil
+		}
+		out = append(out, src[off:off+n]...)
+	}
+	return out, offset, nil
+}
@@ -0,0 +1,364 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// binaryMagic starts the encoding produced by Exif.MarshalBinary. The last
+// byte is the format version.
+const binaryMagic = "GXF\x03"
+
+// MarshalBinary implements encoding.BinaryMarshaler. It encodes the decoded
+// state of x (tags, IFD structure, warnings, JPEG segments and the raw EXIF
+// data) in a compact binary form, so that indexers can cache parse results
+// and restore them with UnmarshalBinary much faster than decoding the image
+// again.
+func (x *Exif) MarshalBinary() ([]byte, error) {
+	w := &binWriter{}
+	w.buf.WriteString(binaryMagic)
+
+	// All dirs are written once, in order of first use, and referenced by
+	// index so that tags shared by the tiff structure and fields stay
+	// shared when restored.
+	dirIndex := map[*tiff.Dir]int{}
+	var dirs []*tiff.Dir
+	addDir := func(d *tiff.Dir) int {
+		if i, ok := dirIndex[d]; ok {
+			return i
+		}
+		dirIndex[d] = len(dirs)
+		dirs = append(dirs, d)
+		return len(dirs) - 1
+	}
+	var tiffDirs []int
+	var order binary.ByteOrder = binary.BigEndian
+	if x.Tiff != nil {
+		order = x.Tiff.Order
+		for _, d := range x.Tiff.Dirs {
+			tiffDirs = append(tiffDirs, addDir(d))
+		}
+	}
+	ifdDirs := map[IfdID][]int{}
+	for _, ifd := range x.ifdIDs() {
+		for _, d := range x.dirs[ifd] {
+			ifdDirs[ifd] = append(ifdDirs[ifd], addDir(d))
+		}
+	}
+	tagIndex := map[*tiff.Tag][2]int{}
+	for i, d := range dirs {
+		for j, t := range d.Tags {
+			tagIndex[t] = [2]int{i, j}
+		}
+	}
+
+	w.bool(order == binary.LittleEndian)
+	w.bool(x.Tiff != nil)
+	w.int(x.tiffOffset)
+	w.bytes(x.Raw)
+
+	w.uint(len(dirs))
+	for _, d := range dirs {
+		w.int(d.Layout.Offset)
+		w.int(d.Layout.Length)
+		w.int(int64(d.Layout.Next))
+		w.errors(d.Warnings)
+		w.uint(len(d.Tags))
+		for _, t := range d.Tags {
+			b, err := t.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			w.bytes(b)
+		}
+	}
+	w.ints(tiffDirs)
+	w.uint(len(ifdDirs))
+	for _, ifd := range x.ifdIDs() {
+		if idx, ok := ifdDirs[ifd]; ok {
+			w.int(int64(ifd))
+			w.ints(idx)
+		}
+	}
+
+	// the name each field is available under in main, or "" if shadowed
+	keys := map[*Field]FieldName{}
+	for name, f := range x.main {
+		keys[f] = name
+	}
+	w.uint(len(x.fields))
+	for _, f := range x.fields {
+		idx, ok := tagIndex[f.Tag]
+		if !ok {
+			return nil, fmt.Errorf("exif: cannot marshal field %v: its tag does not belong to a loaded IFD", f.Name)
+		}
+		w.string(string(f.Name))
+		w.int(int64(f.Ifd))
+		w.uint(idx[0])
+		w.uint(idx[1])
+		w.string(string(keys[f]))
+	}
+
+	w.errors(x.warnings)
+	w.uint(len(x.segments))
+	for _, seg := range x.segments {
+		w.buf.WriteByte(seg.Marker)
+		w.int(seg.Offset)
+		w.bytes(seg.Data)
+	}
+	w.uint(len(x.extra))
+	for _, ifd := range x.ifdIDs() {
+		if data, ok := x.extra[ifd]; ok {
+			w.int(int64(ifd))
+			w.bytes(data)
+		}
+	}
+	return w.buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring the
+// state encoded by MarshalBinary into x. Parsers are not run again.
+func (x *Exif) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(binaryMagic)) {
+		return errors.New("exif: unrecognized binary encoding")
+	}
+	r := &binReader{data: data[len(binaryMagic):]}
+	y := &Exif{main: map[FieldName]*Field{}}
+
+	order := binary.ByteOrder(binary.BigEndian)
+	if r.bool() {
+		order = binary.LittleEndian
+	}
+	hasTiff := r.bool()
+	y.tiffOffset = r.int()
+	y.Raw = r.bytes()
+
+	dirs := make([]*tiff.Dir, r.count())
+	for i := range dirs {
+		d := new(tiff.Dir)
+		d.Layout.Offset = r.int()
+		d.Layout.Length = r.int()
+		d.Layout.Next = int32(r.int())
+		d.Warnings = r.errors()
+		d.Tags = make([]*tiff.Tag, r.count())
+		for j := range d.Tags {
+			t := new(tiff.Tag)
+			if err := t.UnmarshalBinary(r.view()); err != nil && r.err == nil {
+				r.err = err
+			}
+			d.Tags[j] = t
+		}
+		dirs[i] = d
+	}
+	dir := func(i int) *tiff.Dir {
+		if i < 0 || i >= len(dirs) {
+			r.fail()
+			return new(tiff.Dir)
+		}
+		return dirs[i]
+	}
+
+	if hasTiff {
+		y.Tiff = &tiff.Tiff{Order: order}
+	}
+	for _, i := range r.ints() {
+		if y.Tiff != nil {
+			y.Tiff.Dirs = append(y.Tiff.Dirs, dir(i))
+		}
+	}
+	y.dirs = map[IfdID][]*tiff.Dir{}
+	for n := r.count(); n > 0; n-- {
+		ifd := IfdID(r.int())
+		for _, i := range r.ints() {
+			y.indexDir(ifd, dir(i))
+		}
+	}
+
+	for n := r.count(); n > 0; n-- {
+		f := &Field{Name: FieldName(r.string()), Ifd: IfdID(r.int())}
+		d, j := dir(r.uint()), r.uint()
+		if j < 0 || j >= len(d.Tags) {
+			r.fail()
+			break
+		}
+		f.Tag = d.Tags[j]
+		if key := FieldName(r.string()); key != "" {
+			y.main[key] = f
+		}
+		y.fields = append(y.fields, f)
+	}
+
+	y.warnings = r.errors()
+	for n := r.count(); n > 0; n-- {
+		seg := Segment{Marker: r.byte(), Offset: r.int(), Data: r.bytes()}
+		y.segments = append(y.segments, seg)
+	}
+	if n := r.count(); n > 0 {
+		y.extra = map[IfdID][]byte{}
+		for ; n > 0; n-- {
+			ifd := IfdID(r.int())
+			y.extra[ifd] = r.bytes()
+		}
+	}
+
+	if r.err == nil && len(r.data) > 0 {
+		r.err = errors.New("exif: trailing data after binary encoding")
+	}
+	if r.err != nil {
+		return r.err
+	}
+	*x = *y
+	return nil
+}
+
+// ifdIDs returns the IFDs x holds dirs or extra tiff data for, in order.
+func (x *Exif) ifdIDs() []IfdID {
+	var ids []IfdID
+	for ifd := Ifd0; ifd <= IfdSubIFD; ifd++ {
+		if x.dirs[ifd] != nil || x.extra[ifd] != nil {
+			ids = append(ids, ifd)
+		}
+	}
+	return ids
+}
+
+// binWriter writes the primitives of the binary encoding.
+type binWriter struct {
+	buf bytes.Buffer
+	tmp [binary.MaxVarintLen64]byte
+}
+
+func (w *binWriter) uint(v int) {
+	n := binary.PutUvarint(w.tmp[:], uint64(v))
+	w.buf.Write(w.tmp[:n])
+}
+
+func (w *binWriter) int(v int64) {
+	n := binary.PutVarint(w.tmp[:], v)
+	w.buf.Write(w.tmp[:n])
+}
+
+func (w *binWriter) bool(v bool) {
+	if v {
+		w.buf.WriteByte(1)
+	} else {
+		w.buf.WriteByte(0)
+	}
+}
+
+func (w *binWriter) bytes(b []byte) {
+	w.uint(len(b))
+	w.buf.Write(b)
+}
+
+func (w *binWriter) string(s string) {
+	w.uint(len(s))
+	w.buf.WriteString(s)
+}
+
+func (w *binWriter) ints(v []int) {
+	w.uint(len(v))
+	for _, i := range v {
+		w.uint(i)
+	}
+}
+
+func (w *binWriter) errors(errs []error) {
+	w.uint(len(errs))
+	for _, err := range errs {
+		w.string(err.Error())
+	}
+}
+
+// binReader reads the primitives written by binWriter. After the first
+// error, all reads return zero values and err holds the error.
+type binReader struct {
+	data []byte
+	err  error
+}
+
+func (r *binReader) fail() {
+	if r.err == nil {
+		r.err = errors.New("exif: corrupt binary encoding")
+	}
+	r.data = nil
+}
+
+func (r *binReader) uint() int {
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 || v > math.MaxInt32 {
+		r.fail()
+		return 0
+	}
+	r.data = r.data[n:]
+	return int(v)
+}
+
+// count reads a number of elements, each of which takes at least one byte.
+func (r *binReader) count() int {
+	n := r.uint()
+	if n > len(r.data) {
+		r.fail()
+		return 0
+	}
+	return n
+}
+
+func (r *binReader) int() int64 {
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.fail()
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *binReader) byte() byte {
+	if len(r.data) < 1 {
+		r.fail()
+		return 0
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b
+}
+
+func (r *binReader) bool() bool { return r.byte() == 1 }
+
+// view returns the next byte slice without copying it.
+func (r *binReader) view() []byte {
+	n := r.uint()
+	if n > len(r.data) {
+		r.fail()
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *binReader) bytes() []byte { return append([]byte(nil), r.view()...) }
+
+func (r *binReader) string() string { return string(r.view()) }
+
+func (r *binReader) ints() []int {
+	v := make([]int, r.count())
+	for i := range v {
+		v[i] = r.uint()
+	}
+	return v
+}
+
+func (r *binReader) errors() []error {
+	var errs []error
+	for n := r.count(); n > 0; n-- {
+		errs = append(errs, errors.New(r.string()))
+	}
+	return errs
+}
@@ -0,0 +1,220 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jxlSignature is the signature box that starts every JPEG XL container
+// (ISO/IEC 18181-2). A bare JPEG XL codestream (starting with 0xFF0A) has no
+// container and therefore cannot carry an Exif box.
+var jxlSignature = []byte{0x00, 0x00, 0x00, 0x0C, 'J', 'X', 'L', ' ', 0x0D, 0x0A, 0x87, 0x0A}
+
+// boxHeader is the header of an ISO base media file format (ISO/IEC
+// 14496-12) box, the structure shared by JPEG XL containers, HEIF and friends.
+type boxHeader struct {
+	typ string
+	// size is the size of the box payload (excluding the header), or -1 if
+	// the box extends to the end of the stream.
+	size int64
+}
+
+// readBoxHeader reads a box header from r.
+func readBoxHeader(r io.Reader) (boxHeader, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return boxHeader{}, err
+	}
+	h := boxHeader{typ: string(buf[4:8])}
+	switch size := int64(binary.BigEndian.Uint32(buf[:4])); size {
+	case 0:
+		h.size = -1
+	case 1:
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return boxHeader{}, err
+		}
+		h.size = int64(binary.BigEndian.Uint64(buf[:])) - 16
+	default:
+		h.size = size - 8
+	}
+	if h.size < -1 {
+		return boxHeader{}, fmt.Errorf("exif: invalid size for box %q", h.typ)
+	}
+	return h, nil
+}
+
+// jxlExifReader scans the boxes of the JPEG XL container in r and returns a
+// reader positioned at the start of the tiff-encoded data of its Exif box.
+// The reader must already be positioned after the signature box. An Exif box
+// larger than maxBytes, if positive, fails with an error wrapping
+// ErrLimitExceeded.
+func jxlExifReader(r io.Reader, maxBytes int64) (*bytes.Reader, error) {
+	for {
+		h, err := readBoxHeader(r)
+		if err == io.EOF {
+			return nil, errors.New("exif: no Exif box in JPEG XL container")
+		} else if err != nil {
+			return nil, fmt.Errorf("exif: JPEG XL box read failed: %v", err)
+		}
+
+		switch h.typ {
+		case "Exif":
+			if h.size < 0 {
+				return nil, errors.New("exif: unterminated JPEG XL Exif box")
+			}
+			data, err := readLimited(io.LimitReader(r, h.size), maxBytes, "JPEG XL Exif box")
+			if err != nil {
+				return nil, err
+			}
+			if int64(len(data)) < h.size {
+				return nil, fmt.Errorf("exif: JPEG XL Exif box read failed: %w", io.ErrUnexpectedEOF)
+			}
+			return exifBoxReader(data)
+		case "brob":
+			// Brotli-compressed boxes carry the original box type in their
+			// first four payload bytes.
+			var typ [4]byte
+			if _, err := io.ReadFull(r, typ[:]); err != nil {
+				return nil, fmt.Errorf("exif: JPEG XL box read failed: %v", err)
+			}
+			if string(typ[:]) == "Exif" {
+				return nil, errors.New("exif: brotli-compressed JPEG XL Exif box is not supported")
+			}
+			if h.size >= 0 {
+				h.size -= 4
+			}
+		}
+
+		if h.size < 0 {
+			return nil, errors.New("exif: no Exif box in JPEG XL container")
+		}
+		if _, err := io.CopyN(io.Discard, r, h.size); err != nil {
+			return nil, fmt.Errorf("exif: JPEG XL box read failed: %v", err)
+		}
+	}
+}
+
+// exifBoxReader returns a reader on the tiff-encoded portion of the payload of
+// an ISO BMFF Exif box, which starts with a 4 byte big-endian offset from the
+// end of that field to the tiff header.
+func exifBoxReader(data []byte) (*bytes.Reader, error) {
+	if len(data) < 4 {
+		return nil, errors.New("exif: Exif box too short")
+	}
+	offset := int64(binary.BigEndian.Uint32(data[:4]))
+	if offset > int64(len(data)-4) {
+		return nil, errors.New("exif: Exif box tiff header offset out of range")
+	}
+	return bytes.NewReader(data[4+offset:]), nil
+}
+
+// cr3UUID is the type of the Canon box inside the moov box of CR3 files,
+// which holds the CMT1 to CMT4 boxes.
+var cr3UUID = []byte{0x85, 0xC0, 0xB6, 0x87, 0x82, 0x0F, 0x11, 0xE0, 0x81, 0x11, 0xF4, 0xCE, 0x46, 0x2B, 0x6A, 0x48}
+
+// maxMoovSize bounds the size of the CR3 moov box read into memory.
+const maxMoovSize = 16 << 20
+
+// cr3Metadata scans the ISO BMFF file in r, which must be positioned at the
+// start of its ftyp box, and returns the payloads of the CMTn boxes of a
+// Canon CR3 file by box type. Each of them holds complete tiff data: CMT1 the
+// IFD0 tags, CMT2 the Exif IFD tags, CMT3 the Canon maker note and CMT4 the
+// GPS IFD tags. A moov box larger than maxBytes, if positive, fails with an
+// error wrapping ErrLimitExceeded.
+func cr3Metadata(r io.Reader, maxBytes int64) (map[string][]byte, error) {
+	h, err := readBoxHeader(r)
+	if err != nil || h.typ != "ftyp" || h.size < 4 {
+		return nil, errors.New("exif: invalid ISO BMFF ftyp box")
+	}
+	// only the major brand is needed: skip the rest of the box rather than
+	// trusting its size to allocate it
+	var brand [4]byte
+	if _, err := io.ReadFull(r, brand[:]); err != nil {
+		return nil, fmt.Errorf("exif: ISO BMFF box read failed: %v", err)
+	}
+	if string(brand[:]) != "crx " {
+		return nil, fmt.Errorf("exif: unsupported ISO BMFF brand %q", brand[:])
+	}
+	if _, err := io.CopyN(io.Discard, r, h.size-4); err != nil {
+		return nil, fmt.Errorf("exif: ISO BMFF box read failed: %v", err)
+	}
+
+	for {
+		h, err := readBoxHeader(r)
+		if err == io.EOF {
+			return nil, errors.New("exif: no moov box in CR3 file")
+		} else if err != nil {
+			return nil, fmt.Errorf("exif: CR3 box read failed: %v", err)
+		}
+		if h.typ == "moov" {
+			if h.size < 0 || h.size > maxMoovSize {
+				return nil, fmt.Errorf("exif: invalid CR3 moov box size %d", h.size)
+			}
+			if maxBytes > 0 && h.size > maxBytes {
+				return nil, decodeError{cause: fmt.Errorf("%w: CR3 moov box of %d bytes exceeds %d bytes", ErrLimitExceeded, h.size, maxBytes)}
+			}
+			moov := make([]byte, h.size)
+			if _, err := io.ReadFull(r, moov); err != nil {
+				return nil, fmt.Errorf("exif: CR3 moov box read failed: %v", err)
+			}
+			return cr3Boxes(moov)
+		}
+		if h.size < 0 {
+			return nil, errors.New("exif: no moov box in CR3 file")
+		}
+		if _, err := io.CopyN(io.Discard, r, h.size); err != nil {
+			return nil, fmt.Errorf("exif: CR3 box read failed: %v", err)
+		}
+	}
+}
+
+// cr3Boxes returns the CMTn boxes in the Canon uuid box of moov, the payload
+// of a CR3 moov box.
+func cr3Boxes(moov []byte) (map[string][]byte, error) {
+	cmt := map[string][]byte{}
+	err := eachBox(moov, func(typ string, payload []byte) error {
+		if typ != "uuid" || len(payload) < 16 || !bytes.Equal(payload[:16], cr3UUID) {
+			return nil
+		}
+		return eachBox(payload[16:], func(typ string, payload []byte) error {
+			if strings.HasPrefix(typ, "CMT") {
+				cmt[typ] = payload
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cmt["CMT1"] == nil {
+		return nil, errors.New("exif: no CMT1 box in CR3 file")
+	}
+	return cmt, nil
+}
+
+// eachBox calls fn with the type and payload of each box in data.
+func eachBox(data []byte, fn func(typ string, payload []byte) error) error {
+	r := bytes.NewReader(data)
+	for r.Len() > 0 {
+		h, err := readBoxHeader(r)
+		if err != nil {
+			return fmt.Errorf("exif: ISO BMFF box read failed: %v", err)
+		}
+		if h.size < 0 {
+			h.size = int64(r.Len())
+		}
+		if h.size > int64(r.Len()) {
+			return fmt.Errorf("exif: ISO BMFF box %q overruns its parent", h.typ)
+		}
+		pos := len(data) - r.Len()
+		if err := fn(h.typ, data[pos:pos+int(h.size)]); err != nil {
+			return err
+		}
+		r.Seek(h.size, io.SeekCurrent)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package exif
+
+import (
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// A Buffer holds memory reused across decodes, for services decoding many
+// files: when Decoder.Buffer is set, the JPEG segments read, the tags and
+// their values, the fields and the indexes of the Exif are carved out of it
+// rather than allocated for each decode. Memory is handed out once until
+// Reset is called, so that the Exif values decoded since stay valid. A
+// Buffer must not be used by concurrent decodes, e.g. each worker of a
+// service keeps its own and resets it once done with the Exif it decoded.
+// Maker notes are decoded without it.
+type Buffer struct {
+	tiff   tiff.Buffer
+	data   []byte
+	fields []Field
+
+	// main, byID and dirs are the indexes of the last Exif decoded, which
+	// are used again by the next one decoded after a Reset.
+	main  map[FieldName]*Field
+	byID  map[TagID]*tiff.Tag
+	dirs  map[IfdID][]*tiff.Dir
+	inUse bool
+}
+
+// Reset makes the memory of b available again to the following decodes. The
+// Exif values decoded with b before must not be used anymore, as their data
+// is overwritten.
+func (b *Buffer) Reset() {
+	b.tiff.Reset()
+	b.data, b.fields = b.data[:0], b.fields[:0]
+	for name := range b.main {
+		delete(b.main, name)
+	}
+	for id := range b.byID {
+		delete(b.byID, id)
+	}
+	for ifd := range b.dirs {
+		delete(b.dirs, ifd)
+	}
+	b.inUse = false
+}
+
+// newData returns n bytes, which are not zeroed.
+func (b *Buffer) newData(n int) []byte {
+	if b == nil {
+		return make([]byte, n)
+	}
+	if cap(b.data)-len(b.data) < n {
+		c := 2 * cap(b.data)
+		if c < 4096 {
+			c = 4096
+		}
+		if c < n {
+			c = n
+		}
+		b.data = make([]byte, 0, c)
+	}
+	i := len(b.data)
+	b.data = b.data[:i+n]
+	return b.data[i : i+n : i+n]
+}
+
+// newFields returns n zeroed fields.
+func (b *Buffer) newFields(n int) []Field {
+	if b == nil {
+		return make([]Field, n)
+	}
+	if cap(b.fields)-len(b.fields) < n {
+		c := 2 * cap(b.fields)
+		if c < 64 {
+			c = 64
+		}
+		if c < n {
+			c = n
+		}
+		b.fields = make([]Field, 0, c)
+	}
+	i := len(b.fields)
+	b.fields = b.fields[:i+n]
+	s := b.fields[i : i+n : i+n]
+	for j := range s {
+		s[j] = Field{}
+	}
+	return s
+}
+
+// indexes sets up the indexes of x, reusing those of b if no Exif decoded
+// since the last Reset holds them.
+func (b *Buffer) indexes(x *Exif) {
+	if b == nil || b.inUse {
+		x.main = map[FieldName]*Field{}
+		return
+	}
+	if b.main == nil {
+		b.main, b.byID, b.dirs = map[FieldName]*Field{}, map[TagID]*tiff.Tag{}, map[IfdID][]*tiff.Dir{}
+	}
+	x.main, x.byID, x.dirs = b.main, b.byID, b.dirs
+	b.inUse = true
+}
+
+// buffer returns the Buffer of the Decoder of x, or nil.
+func (x *Exif) buffer() *Buffer {
+	if x.dec == nil {
+		return nil
+	}
+	return x.dec.Buffer
+}
@@ -0,0 +1,144 @@
+package exif
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// Errors returned (wrapped, see errors.Is) by the Get accessors below when
+// the value of a field cannot be converted to the requested type without
+// loss.
+var (
+	// ErrOverflow reports a value outside the range of the requested type.
+	ErrOverflow = errors.New("exif: value out of range")
+	// ErrPrecision reports a value the requested type cannot represent
+	// exactly, e.g. a rational 7/2 requested as an integer.
+	ErrPrecision = errors.New("exif: value not exactly representable")
+)
+
+// maxExactFloat is the largest integer below which all integers are
+// exactly representable as float64 values.
+const maxExactFloat = 1 << 53
+
+// GetInt64 returns the first value of field name as an integer, converting
+// BYTE, SHORT, LONG, RATIONAL, FLOAT and DOUBLE values alike. Rationals and
+// floating point values must be whole numbers; an error wrapping
+// ErrPrecision is returned otherwise, e.g. for an ExposureTime of 1/250.
+func (x *Exif) GetInt64(name FieldName) (int64, error) {
+	tag, err := x.firstValue(name)
+	if err != nil {
+		return 0, err
+	}
+	switch tag.Format() {
+	case tiff.IntVal:
+		return tag.Int64(0)
+	case tiff.RatVal:
+		num, den, _ := tag.Rat2(0)
+		if den == 0 {
+			return 0, fmt.Errorf("exif: %v has a zero denominator", name)
+		}
+		if num%den != 0 {
+			return 0, fmt.Errorf("exif: %v value %d/%d is not an integer: %w", name, num, den, ErrPrecision)
+		}
+		return num / den, nil
+	case tiff.FloatVal:
+		v, _ := tag.Float(0)
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("exif: %v value %v is not an integer: %w", name, v, ErrPrecision)
+		}
+		if v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, fmt.Errorf("exif: %v value %v overflows int64: %w", name, v, ErrOverflow)
+		}
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("exif: %v is not numeric", name)
+}
+
+// GetUint16 is like GetInt64, but also returns an error wrapping
+// ErrOverflow if the value does not fit in a uint16.
+func (x *Exif) GetUint16(name FieldName) (uint16, error) {
+	v, err := x.getUint(name, math.MaxUint16, "uint16")
+	return uint16(v), err
+}
+
+// GetUint32 is like GetInt64, but also returns an error wrapping
+// ErrOverflow if the value does not fit in a uint32.
+func (x *Exif) GetUint32(name FieldName) (uint32, error) {
+	v, err := x.getUint(name, math.MaxUint32, "uint32")
+	return uint32(v), err
+}
+
+func (x *Exif) getUint(name FieldName, max int64, typ string) (int64, error) {
+	v, err := x.GetInt64(name)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 || v > max {
+		return 0, fmt.Errorf("exif: %v value %d overflows %s: %w", name, v, typ, ErrOverflow)
+	}
+	return v, nil
+}
+
+// GetFloat returns the first value of field name as a floating point number,
+// converting integer, RATIONAL, FLOAT and DOUBLE values alike. Integers too
+// large to be represented exactly return an error wrapping ErrPrecision.
+// Rationals are divided out, rounding as usual.
+func (x *Exif) GetFloat(name FieldName) (float64, error) {
+	tag, err := x.firstValue(name)
+	if err != nil {
+		return 0, err
+	}
+	switch tag.Format() {
+	case tiff.IntVal:
+		v, _ := tag.Int64(0)
+		if v > maxExactFloat || v < -maxExactFloat {
+			return 0, fmt.Errorf("exif: %v value %d does not fit in a float64: %w", name, v, ErrPrecision)
+		}
+		return float64(v), nil
+	case tiff.RatVal:
+		num, den, _ := tag.Rat2(0)
+		if den == 0 {
+			return 0, fmt.Errorf("exif: %v has a zero denominator", name)
+		}
+		return ratFloat(num, den), nil
+	case tiff.FloatVal:
+		return tag.Float(0)
+	}
+	return 0, fmt.Errorf("exif: %v is not numeric", name)
+}
+
+// GetString returns the value of field name as a string without trailing
+// NUL bytes and spaces. Besides ASCII fields, it accepts fields of undefined
+// type holding printable text, such as ExifVersion.
+func (x *Exif) GetString(name FieldName) (string, error) {
+	tag, err := x.Get(name)
+	if err != nil {
+		return "", err
+	}
+	switch tag.Format() {
+	case tiff.StringVal:
+		s, _ := tag.StringVal()
+		return strings.TrimRight(s, "\x00 "), nil
+	case tiff.UndefVal:
+		if !isBinary(tag) {
+			return trimText(tag.Val), nil
+		}
+	}
+	return "", fmt.Errorf("exif: %v is not a string", name)
+}
+
+// firstValue returns the tag of field name, which must hold a value.
+func (x *Exif) firstValue(name FieldName) (*tiff.Tag, error) {
+	tag, err := x.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if tag.Count == 0 {
+		return nil, fmt.Errorf("exif: %v has no value", name)
+	}
+	return tag, nil
+}
@@ -0,0 +1,108 @@
+package exif
+
+import (
+	"sort"
+	"strings"
+)
+
+// A Compliance reports the specification versions an image claims to
+// follow and the fields the Exif 2.32 specification (CIPA DC-008-2019) makes
+// mandatory that it lacks.
+type Compliance struct {
+	// ExifVersion and FlashpixVersion hold the claimed versions as stored,
+	// e.g. "0232" and "0100", or "" if the field is missing.
+	ExifVersion     string
+	FlashpixVersion string
+	// InteropIndex and InteropVersion hold the interoperability rule the
+	// image claims to follow, e.g. "R98" and "0100", or "" if missing.
+	InteropIndex   string
+	InteropVersion string
+	// Uncompressed is set for images whose primary image data is stored as
+	// TIFF strips, which have other mandatory fields than JPEG images.
+	Uncompressed bool
+	// Missing lists the missing mandatory fields, sorted by name.
+	Missing []FieldName
+}
+
+// Compliant reports whether no mandatory field is missing.
+func (c *Compliance) Compliant() bool {
+	return len(c.Missing) == 0
+}
+
+// Mandatory fields of each IFD, from the tables of section 4.6.8 of the
+// Exif 2.32 specification. The fields of IFD1, GPS and the
+// Interoperability IFD are only mandatory if that IFD is present.
+var (
+	mandatoryJPEG = []FieldName{
+		XResolution, YResolution, ResolutionUnit, YCbCrPositioning, ExifIFDPointer,
+		ExifVersion, ComponentsConfiguration, FlashpixVersion, ColorSpace,
+		PixelXDimension, PixelYDimension,
+	}
+	mandatoryTIFF = []FieldName{
+		ImageWidth, ImageLength, BitsPerSample, Compression,
+		PhotometricInterpretation, StripOffsets, SamplesPerPixel,
+		RowsPerStrip, StripByteCounts, XResolution, YResolution,
+		ResolutionUnit, ExifIFDPointer, ExifVersion, FlashpixVersion, ColorSpace,
+	}
+	mandatoryThumb = []FieldName{
+		ThumbCompression, ThumbXResolution, ThumbYResolution, ThumbResolutionUnit,
+	}
+	mandatoryJPEGThumb = []FieldName{
+		ThumbJPEGInterchangeFormat, ThumbJPEGInterchangeFormatLength,
+	}
+	mandatoryGPS     = []FieldName{GPSVersionID}
+	mandatoryInterop = []FieldName{InteroperabilityIndex}
+)
+
+// Compliance returns the claimed versions and the compliance assessment of
+// x, e.g. for camera firmware QA. Only the presence of the mandatory fields
+// is checked; use Validate to check their types and counts.
+func (x *Exif) Compliance() *Compliance {
+	c := &Compliance{
+		ExifVersion:     x.text(ExifVersion),
+		FlashpixVersion: x.text(FlashpixVersion),
+		InteropIndex:    x.text(InteroperabilityIndex),
+		InteropVersion:  x.text(InteroperabilityVersion),
+	}
+	_, c.Uncompressed = x.main[StripOffsets]
+
+	mandatory := mandatoryJPEG
+	if c.Uncompressed {
+		mandatory = mandatoryTIFF
+	}
+	mandatory = append([]FieldName(nil), mandatory...)
+	if x.dirs[Ifd1] != nil {
+		mandatory = append(mandatory, mandatoryThumb...)
+		if _, ok := x.main[ThumbStripOffsets]; !ok {
+			mandatory = append(mandatory, mandatoryJPEGThumb...)
+		}
+	}
+	if x.dirs[IfdGPS] != nil {
+		mandatory = append(mandatory, mandatoryGPS...)
+	}
+	if x.dirs[IfdInterop] != nil {
+		mandatory = append(mandatory, mandatoryInterop...)
+	}
+
+	for _, name := range mandatory {
+		if _, ok := x.main[name]; !ok {
+			c.Missing = append(c.Missing, name)
+		}
+	}
+	sort.Slice(c.Missing, func(i, j int) bool { return c.Missing[i] < c.Missing[j] })
+	return c
+}
+
+// text returns the value of the ASCII or undefined field name without
+// trailing NULs and spaces, or "" if it is missing.
+func (x *Exif) text(name FieldName) string {
+	f, ok := x.main[name]
+	if !ok {
+		return ""
+	}
+	return trimText(f.Tag.Val)
+}
+
+func trimText(val []byte) string {
+	return strings.TrimRight(string(val), "\x00 ")
+}
@@ -0,0 +1,253 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rwcarlsen/goexif/jpegstructure"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// Copy transplants the EXIF metadata of the image in src (any format
+// supported by Decode) into the JPEG image in dst, e.g. after editing the
+// image in a tool that strips metadata, and writes the result to w. The EXIF
+// segments of dst are replaced by a single EXIF segment following its SOI
+// marker (and JFIF APP0 segment, if any); its other segments and image data
+// are copied unchanged.
+//
+// Without fields, all metadata is copied. The EXIF segment of a JPEG src is
+// copied verbatim, including its thumbnail and maker note; for other formats
+// the tags of IFD0 that describe the image data of src (e.g. its strips) are
+// left out. With fields, only the named fields of IFD0 and the Exif, GPS and
+// Interoperability sub-IFDs are copied; those not present in src are skipped.
+// Fields describing the image, such as PixelXDimension, are copied as they
+// are and may need to be patched for the new image.
+//
+// Maker notes often hold offsets relative to the tiff header, so when the
+// tiff data is encoded anew the MakerNote value is stored at its original
+// offset. If the other fields do not fit before it, the maker note is left
+// out when copying all fields, and an error is returned if it was named.
+func Copy(src, dst io.Reader, w io.Writer, fields ...FieldName) error {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+	x, err := Decode(bytes.NewReader(data))
+	if err != nil && (x == nil || IsCriticalError(err)) {
+		return err
+	}
+
+	var tiffData []byte
+	if len(fields) == 0 && bytes.HasPrefix(data, []byte{0xFF, 0xD8}) && x.tiffOffset >= 0 {
+		tiffData = x.Raw
+	} else if tiffData, err = x.encodeFields(fields); err != nil {
+		return err
+	}
+	payload := append(append([]byte(nil), exifHeader...), tiffData...)
+	if len(payload)+2 > 0xFFFF {
+		return fmt.Errorf("exif: EXIF data of %d bytes does not fit in a JPEG segment", len(payload))
+	}
+
+	s, err := jpegstructure.Parse(dst)
+	if err != nil {
+		return fmt.Errorf("exif: destination is not a JPEG image: %v", err)
+	}
+	for i := s.Index(jpeg_APP1, exifHeader); i >= 0; i = s.Index(jpeg_APP1, exifHeader) {
+		s.Delete(i)
+	}
+	i := 0
+	for i < len(s.Segments) && s.Segments[i].Marker == jpeg_APP0 {
+		i++
+	}
+	if err := s.Insert(i, jpegstructure.Segment{Marker: jpeg_APP1, Data: payload}); err != nil {
+		return err
+	}
+	_, err = s.WriteTo(w)
+	return err
+}
+
+// copySkipped holds the tags of IFD0 that describe the image data of the
+// file, which Copy leaves out when copying all fields, and the sub-IFD
+// pointers, which are written as needed.
+var copySkipped = map[uint16]bool{
+	0x0100: true, // ImageWidth
+	0x0101: true, // ImageLength
+	0x0102: true, // BitsPerSample
+	0x0103: true, // Compression
+	0x0106: true, // PhotometricInterpretation
+	0x0111: true, // StripOffsets
+	0x0115: true, // SamplesPerPixel
+	0x0116: true, // RowsPerStrip
+	0x0117: true, // StripByteCounts
+	0x011C: true, // PlanarConfiguration
+	0x0142: true, // TileWidth
+	0x0143: true, // TileLength
+	0x0144: true, // TileOffsets
+	0x0145: true, // TileByteCounts
+	0x014A: true, // SubIFDs
+
+	exifPointer:    true,
+	gpsPointer:     true,
+	interopPointer: true,
+}
+
+// encodeFields encodes the named fields of x (or all tags of IFD0 and the
+// sub-IFDs except copySkipped if there are none) as tiff data, as described
+// by Copy.
+func (x *Exif) encodeFields(fields []FieldName) ([]byte, error) {
+	if x.Tiff == nil {
+		return nil, errors.New("exif: no tiff data to copy")
+	}
+	dirs := map[IfdID]*tiff.Dir{}
+	add := func(ifd IfdID, t *tiff.Tag) {
+		d := dirs[ifd]
+		if d == nil {
+			d = tiff.NewDir()
+			dirs[ifd] = d
+		}
+		for _, u := range d.Tags {
+			if u.Id == t.Id {
+				return
+			}
+		}
+		d.Tags = append(d.Tags, t)
+	}
+
+	named := false
+	if len(fields) == 0 {
+		for _, ifd := range []IfdID{Ifd0, IfdExif, IfdGPS, IfdInterop} {
+			if len(x.dirs[ifd]) == 0 {
+				continue
+			}
+			for _, t := range x.dirs[ifd][0].Tags {
+				if ifd == Ifd0 && copySkipped[t.Id] || t.Id == interopPointer {
+					continue
+				}
+				add(ifd, t)
+			}
+		}
+	}
+	for _, name := range fields {
+		f, err := x.GetField(name)
+		if err != nil {
+			continue
+		}
+		switch f.Ifd {
+		case Ifd0, IfdExif, IfdGPS, IfdInterop:
+		default:
+			return nil, fmt.Errorf("exif: cannot copy %v field %v", f.Ifd, name)
+		}
+		if id := f.Tag.Id; id == exifPointer || id == gpsPointer || id == interopPointer {
+			continue
+		}
+		add(f.Ifd, f.Tag)
+		named = named || name == MakerNote
+	}
+
+	// the maker note is encoded with a placeholder value, patched to point
+	// to its original offset afterwards
+	var note *tiff.Tag
+	if d := dirs[IfdExif]; d != nil {
+		for i, t := range d.Tags {
+			if t.Id == 0x927C && !t.Inlined() && len(t.Val) > 4 {
+				if _, ok := t.ValueOffset(); !ok {
+					break
+				}
+				note = t
+				d.Tags[i], _ = tiff.NewTag(0x927C, tiff.DTUndefined, make([]byte, 4))
+				break
+			}
+		}
+	}
+
+	ifd0 := dirs[Ifd0]
+	if ifd0 == nil {
+		ifd0 = tiff.NewDir()
+	}
+	exif := dirs[IfdExif]
+	if interop := dirs[IfdInterop]; interop != nil {
+		if exif == nil {
+			exif = tiff.NewDir()
+		}
+		exif.SubDirs = map[uint16]*tiff.Dir{interopPointer: interop}
+	}
+	ifd0.SubDirs = map[uint16]*tiff.Dir{}
+	if exif != nil {
+		ifd0.SubDirs[exifPointer] = exif
+	}
+	if gps := dirs[IfdGPS]; gps != nil {
+		ifd0.SubDirs[gpsPointer] = gps
+	}
+
+	var buf bytes.Buffer
+	if err := tiff.NewTiff(x.Tiff.Order, ifd0).Encode(&buf); err != nil {
+		return nil, err
+	}
+	if note == nil {
+		return buf.Bytes(), nil
+	}
+	data, err := placeNote(buf.Bytes(), x.Tiff.Order, note)
+	if err == nil || named {
+		return data, err
+	}
+	d := dirs[IfdExif]
+	for i, t := range d.Tags {
+		if t.Id == 0x927C {
+			d.Tags = append(d.Tags[:i], d.Tags[i+1:]...)
+			break
+		}
+	}
+	buf.Reset()
+	if err := tiff.NewTiff(x.Tiff.Order, ifd0).Encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// placeNote stores the value of the maker note tag note at its original
+// offset in the tiff data data, in order order, whose Exif IFD holds a
+// placeholder MakerNote entry, and patches the entry to point to it. An error
+// is returned if data extends past that offset, or if the maker note would
+// end past the data an EXIF segment can hold.
+func placeNote(data []byte, order binary.ByteOrder, note *tiff.Tag) ([]byte, error) {
+	off, _ := note.ValueOffset()
+	switch {
+	case off < int64(len(data)):
+		return nil, fmt.Errorf("exif: the maker note cannot be kept at offset %d, the other fields take %d bytes", off, len(data))
+	case off+int64(len(note.Val)) > int64(jpegstructure.MaxData-len(exifHeader)):
+		return nil, fmt.Errorf("exif: the maker note at offset %d does not fit in a JPEG segment", off)
+	}
+	tf, err := tiff.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var ptr int64 = -1
+	for _, t := range tf.Dirs[0].Tags {
+		if t.Id == exifPointer {
+			ptr, _ = t.Int64(0)
+		}
+	}
+	r := bytes.NewReader(data)
+	if _, err := r.Seek(ptr, io.SeekStart); err != nil {
+		return nil, err
+	}
+	d, _, err := tiff.DecodeDir(r, order)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range d.Tags {
+		if t.Id != 0x927C {
+			continue
+		}
+		e := t.EntryOffset()
+		order.PutUint16(data[e+2:], uint16(note.Type))
+		order.PutUint32(data[e+4:], note.Count)
+		order.PutUint32(data[e+8:], uint32(off))
+		data = append(data, make([]byte, off-int64(len(data)))...)
+		return append(data, note.Val...), nil
+	}
+	return nil, errors.New("exif: no MakerNote entry in the encoded tiff data")
+}
@@ -0,0 +1,64 @@
+package exif
+
+import (
+	"strings"
+	"sync"
+)
+
+// A Description explains the meaning of a field to users, e.g. in tooltips.
+type Description struct {
+	// Title is a short human readable name, e.g. "Exposure time".
+	Title string
+	// Text explains what the field means.
+	Text string
+	// Unit is the unit of the field's value, e.g. "seconds", or empty if
+	// the value has no unit.
+	Unit string
+}
+
+var (
+	translationsMu sync.RWMutex
+	translations   = map[string]map[FieldName]Description{}
+)
+
+// RegisterTranslations registers descriptions in the language lang (a BCP 47
+// tag such as "de" or "pt-BR") for use by DescribeIn. Registering the same
+// language again adds to or replaces the descriptions registered before.
+func RegisterTranslations(lang string, descs map[FieldName]Description) {
+	translationsMu.Lock()
+	defer translationsMu.Unlock()
+	lang = strings.ToLower(lang)
+	if translations[lang] == nil {
+		translations[lang] = map[FieldName]Description{}
+	}
+	for name, d := range descs {
+		translations[lang][name] = d
+	}
+}
+
+// Describe returns the English description of field name. ok is false if
+// there is no description for it, such as for unknown tags.
+func Describe(name FieldName) (d Description, ok bool) {
+	d, ok = descriptions[name]
+	return d, ok
+}
+
+// DescribeIn returns the description of field name in the language lang,
+// falling back to less specific languages ("pt" for "pt-BR") and finally to
+// English if no translation was registered.
+func DescribeIn(lang string, name FieldName) (d Description, ok bool) {
+	translationsMu.RLock()
+	defer translationsMu.RUnlock()
+	lang = strings.ToLower(lang)
+	for lang != "" {
+		if d, ok := translations[lang][name]; ok {
+			return d, true
+		}
+		i := strings.LastIndexAny(lang, "-_")
+		if i < 0 {
+			break
+		}
+		lang = lang[:i]
+	}
+	return Describe(name)
+}
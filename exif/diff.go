@@ -0,0 +1,57 @@
+package exif
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// A Difference is a field whose tag differs between two Exif values. A or B
+// is nil if the field is only present in the other.
+type Difference struct {
+	Name FieldName
+	A, B *tiff.Tag
+}
+
+func (d Difference) String() string {
+	switch {
+	case d.A == nil:
+		return fmt.Sprintf("+ %v: %v", d.Name, d.B)
+	case d.B == nil:
+		return fmt.Sprintf("- %v: %v", d.Name, d.A)
+	}
+	return fmt.Sprintf("~ %v: %v => %v", d.Name, d.A, d.B)
+}
+
+// Diff compares the fields of a and b available through Get and returns
+// those that differ, sorted by name. Two tags are equal if they have the
+// same data type, count and decoded value; their offsets and byte order are
+// ignored, so that the same metadata written by different tools compares
+// equal.
+func Diff(a, b *Exif) []Difference {
+	var diffs []Difference
+	for name, fa := range a.main {
+		fb, ok := b.main[name]
+		if !ok {
+			diffs = append(diffs, Difference{Name: name, A: fa.Tag})
+		} else if !sameTag(fa.Tag, fb.Tag) {
+			diffs = append(diffs, Difference{Name: name, A: fa.Tag, B: fb.Tag})
+		}
+	}
+	for name, fb := range b.main {
+		if _, ok := a.main[name]; !ok {
+			diffs = append(diffs, Difference{Name: name, B: fb.Tag})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return diffs
+}
+
+func sameTag(a, b *tiff.Tag) bool {
+	if a.Type != b.Type || a.Count != b.Count {
+		return false
+	}
+	return bytes.Equal(a.Val, b.Val) || a.String() == b.String()
+}
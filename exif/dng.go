@@ -0,0 +1,289 @@
+package exif
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// ErrDNGVersion is returned (wrapped) by ValidateDNG for files whose
+// DNGBackwardVersion is newer than DNGVersion16: readers of older versions
+// of the specification must not read them.
+var ErrDNGVersion = errors.New("exif: unsupported DNG version")
+
+// DNGVersion16 is the version of the DNG specification whose tags DNG16 and
+// ValidateDNG know, 1.6.0.0.
+var DNGVersion16 = [4]byte{1, 6, 0, 0}
+
+// PhotometricInterpretation values of DNG raw images.
+const (
+	photometricCFA       = 32803
+	photometricLinearRaw = 34892
+)
+
+// DNG16 holds the constraints of the DNG 1.6 specification on the types and
+// counts of the fields of IFD0 of DNG files. DNGVersion and
+// UniqueCameraModel are required. The fields of the raw IFD are checked by
+// ValidateDNG.
+var DNG16 = Schema{
+	DNGVersion:                   {Ifd0, dtByte, 4, true},
+	DNGBackwardVersion:           {Ifd0, dtByte, 4, false},
+	UniqueCameraModel:            {Ifd0, dtASCII, 0, true},
+	LocalizedCameraModel:         {Ifd0, dtASCIIByte, 0, false},
+	ColorMatrix1:                 {Ifd0, dtSRational, 0, false},
+	ColorMatrix2:                 {Ifd0, dtSRational, 0, false},
+	CameraCalibration1:           {Ifd0, dtSRational, 0, false},
+	CameraCalibration2:           {Ifd0, dtSRational, 0, false},
+	ReductionMatrix1:             {Ifd0, dtSRational, 0, false},
+	ReductionMatrix2:             {Ifd0, dtSRational, 0, false},
+	AnalogBalance:                {Ifd0, dtRational, 0, false},
+	AsShotNeutral:                {Ifd0, dtShortRational, 0, false},
+	AsShotWhiteXY:                {Ifd0, dtRational, 2, false},
+	BaselineExposure:             {Ifd0, dtSRational, 1, false},
+	BaselineNoise:                {Ifd0, dtRational, 1, false},
+	BaselineSharpness:            {Ifd0, dtRational, 1, false},
+	LinearResponseLimit:          {Ifd0, dtRational, 1, false},
+	CameraSerialNumber:           {Ifd0, dtASCII, 0, false},
+	DNGLensInfo:                  {Ifd0, dtRational, 4, false},
+	ShadowScale:                  {Ifd0, dtRational, 1, false},
+	DNGPrivateData:               {Ifd0, dtByte, 0, false},
+	MakerNoteSafety:              {Ifd0, dtShort, 1, false},
+	CalibrationIlluminant1:       {Ifd0, dtShort, 1, false},
+	CalibrationIlluminant2:       {Ifd0, dtShort, 1, false},
+	RawDataUniqueID:              {Ifd0, dtByte, 16, false},
+	OriginalRawFileName:          {Ifd0, dtASCIIByte, 0, false},
+	OriginalRawFileData:          {Ifd0, dtUndef, 0, false},
+	AsShotICCProfile:             {Ifd0, dtUndef, 0, false},
+	AsShotPreProfileMatrix:       {Ifd0, dtSRational, 0, false},
+	CurrentICCProfile:            {Ifd0, dtUndef, 0, false},
+	CurrentPreProfileMatrix:      {Ifd0, dtSRational, 0, false},
+	ColorimetricReference:        {Ifd0, dtShort, 1, false},
+	CameraCalibrationSignature:   {Ifd0, dtASCIIByte, 0, false},
+	ProfileCalibrationSignature:  {Ifd0, dtASCIIByte, 0, false},
+	ExtraCameraProfiles:          {Ifd0, dtLong, 0, false},
+	AsShotProfileName:            {Ifd0, dtASCIIByte, 0, false},
+	NoiseReductionApplied:        {Ifd0, dtRational, 1, false},
+	ProfileName:                  {Ifd0, dtASCIIByte, 0, false},
+	ProfileHueSatMapDims:         {Ifd0, dtLong, 3, false},
+	ProfileHueSatMapData1:        {Ifd0, dtFloat, 0, false},
+	ProfileHueSatMapData2:        {Ifd0, dtFloat, 0, false},
+	ProfileToneCurve:             {Ifd0, dtFloat, 0, false},
+	ProfileEmbedPolicy:           {Ifd0, dtLong, 1, false},
+	ProfileCopyright:             {Ifd0, dtASCIIByte, 0, false},
+	ForwardMatrix1:               {Ifd0, dtSRational, 0, false},
+	ForwardMatrix2:               {Ifd0, dtSRational, 0, false},
+	PreviewApplicationName:       {Ifd0, dtASCIIByte, 0, false},
+	PreviewApplicationVersion:    {Ifd0, dtASCIIByte, 0, false},
+	PreviewSettingsName:          {Ifd0, dtASCIIByte, 0, false},
+	PreviewSettingsDigest:        {Ifd0, dtByte, 16, false},
+	PreviewColorSpace:            {Ifd0, dtLong, 1, false},
+	PreviewDateTime:              {Ifd0, dtASCII, 0, false},
+	RawImageDigest:               {Ifd0, dtByte, 16, false},
+	OriginalRawFileDigest:        {Ifd0, dtByte, 16, false},
+	ProfileLookTableDims:         {Ifd0, dtLong, 3, false},
+	ProfileLookTableData:         {Ifd0, dtFloat, 0, false},
+	TimeCodes:                    {Ifd0, dtByte, 0, false},
+	FrameRate:                    {Ifd0, dtSRational, 1, false},
+	TStop:                        {Ifd0, dtSRational, 0, false},
+	ReelName:                     {Ifd0, dtASCII, 0, false},
+	OriginalDefaultFinalSize:     {Ifd0, dtShortLong, 2, false},
+	OriginalBestQualityFinalSize: {Ifd0, dtShortLong, 2, false},
+	OriginalDefaultCropSize:      {Ifd0, dtShortLongRational, 2, false},
+	CameraLabel:                  {Ifd0, dtASCII, 0, false},
+	ProfileHueSatMapEncoding:     {Ifd0, dtLong, 1, false},
+	ProfileLookTableEncoding:     {Ifd0, dtLong, 1, false},
+	BaselineExposureOffset:       {Ifd0, dtSRational, 1, false},
+	DefaultBlackRender:           {Ifd0, dtLong, 1, false},
+	NewRawImageDigest:            {Ifd0, dtByte, 16, false},
+	RawToPreviewGain:             {Ifd0, dtDouble, 1, false},
+	DepthFormat:                  {Ifd0, dtShort, 1, false},
+	DepthNear:                    {Ifd0, dtRational, 1, false},
+	DepthFar:                     {Ifd0, dtRational, 1, false},
+	DepthUnits:                   {Ifd0, dtShort, 1, false},
+	DepthMeasureType:             {Ifd0, dtShort, 1, false},
+	CalibrationIlluminant3:       {Ifd0, dtShort, 1, false},
+	CameraCalibration3:           {Ifd0, dtSRational, 0, false},
+	ColorMatrix3:                 {Ifd0, dtSRational, 0, false},
+	ForwardMatrix3:               {Ifd0, dtSRational, 0, false},
+	IlluminantData1:              {Ifd0, dtUndef, 0, false},
+	IlluminantData2:              {Ifd0, dtUndef, 0, false},
+	IlluminantData3:              {Ifd0, dtUndef, 0, false},
+	ProfileHueSatMapData3:        {Ifd0, dtFloat, 0, false},
+	ReductionMatrix3:             {Ifd0, dtSRational, 0, false},
+}
+
+// dngRaw holds the constraints of the DNG 1.6 specification on the fields
+// of the raw IFD, which is IFD0 or one of the SubIFDs.
+var dngRaw = Schema{
+	CFARepeatPatternDim: {IfdSubIFD, dtShort, 2, false},
+	CFAPattern2:         {IfdSubIFD, dtByte, 0, false},
+	CFAPlaneColor:       {IfdSubIFD, dtByte, 0, false},
+	CFALayout:           {IfdSubIFD, dtShort, 1, false},
+	LinearizationTable:  {IfdSubIFD, dtShort, 0, false},
+	BlackLevelRepeatDim: {IfdSubIFD, dtShort, 2, false},
+	BlackLevel:          {IfdSubIFD, dtShortLongRational, 0, false},
+	BlackLevelDeltaH:    {IfdSubIFD, dtSRational, 0, false},
+	BlackLevelDeltaV:    {IfdSubIFD, dtSRational, 0, false},
+	WhiteLevel:          {IfdSubIFD, dtShortLong, 0, false},
+	DefaultScale:        {IfdSubIFD, dtRational, 2, false},
+	DefaultCropOrigin:   {IfdSubIFD, dtShortLongRational, 2, false},
+	DefaultCropSize:     {IfdSubIFD, dtShortLongRational, 2, false},
+	BayerGreenSplit:     {IfdSubIFD, dtLong, 1, false},
+	ChromaBlurRadius:    {IfdSubIFD, dtRational, 1, false},
+	AntiAliasStrength:   {IfdSubIFD, dtRational, 1, false},
+	BestQualityScale:    {IfdSubIFD, dtRational, 1, false},
+	ActiveArea:          {IfdSubIFD, dtShortLong, 4, false},
+	MaskedAreas:         {IfdSubIFD, dtShortLong, 0, false},
+	SubTileBlockSize:    {IfdSubIFD, dtShortLong, 2, false},
+	RowInterleaveFactor: {IfdSubIFD, dtShortLong, 1, false},
+	OpcodeList1:         {IfdSubIFD, dtUndef, 0, false},
+	OpcodeList2:         {IfdSubIFD, dtUndef, 0, false},
+	OpcodeList3:         {IfdSubIFD, dtUndef, 0, false},
+	NoiseProfile:        {IfdSubIFD, dtDouble, 0, false},
+	DefaultUserCrop:     {IfdSubIFD, dtRational, 4, false},
+	ProfileGainTableMap: {IfdSubIFD, dtUndef, 0, false},
+}
+
+// ValidateDNG checks x as a DNG file and returns the violations found: those
+// of the fields of IFD0 against DNG16, a DNGVersion other than 1.x, a
+// DNGBackwardVersion newer than DNGVersion or than DNGVersion16 (wrapping
+// ErrDNGVersion), and in the raw IFD, the IFD holding the main CFA or
+// linear raw image, fields with a type or count dngRaw does not allow, a
+// missing CFA pattern and an ActiveArea outside the image. The color
+// matrices of IFD0 must have three rows per color plane of the raw image,
+// and are required unless the image is monochrome.
+func (x *Exif) ValidateDNG() []error {
+	errs := x.Validate(DNG16)
+
+	if v, ok := x.dngVersion(DNGVersion); ok {
+		backward, ok := x.dngVersion(DNGBackwardVersion)
+		if !ok {
+			backward = [4]byte{v[0], v[1], 0, 0}
+		}
+		switch {
+		case v[0] != 1:
+			errs = append(errs, fmt.Errorf("exif: DNGVersion %v is not a DNG 1.x version", dngVersionString(v)))
+		case compareDNGVersions(backward, v) > 0:
+			errs = append(errs, fmt.Errorf("exif: DNGBackwardVersion %v is newer than DNGVersion %v", dngVersionString(backward), dngVersionString(v)))
+		}
+		if compareDNGVersions(backward, DNGVersion16) > 0 {
+			errs = append(errs, fmt.Errorf("%w: the file requires a DNG %v reader, newer than DNG %v", ErrDNGVersion, dngVersionString(backward), dngVersionString(DNGVersion16)))
+		}
+	}
+
+	raw, ifd := x.dngRawDir()
+	if raw == nil {
+		return append(errs, errors.New("exif: no raw IFD holding a CFA or linear raw image found"))
+	}
+	tags := map[FieldName]*tiff.Tag{}
+	for _, t := range raw.Tags {
+		if name, ok := exifFields[t.Id]; ok && tags[name] == nil {
+			tags[name] = t
+		}
+	}
+	for _, n := range sortedNames(dngRaw) {
+		if tag := tags[n]; tag != nil {
+			errs = append(errs, checkField(n, dngRaw[n], tag)...)
+		} else if f := x.main[n]; f != nil && ifd != Ifd0 {
+			errs = append(errs, fmt.Errorf("exif: field %v is stored in %v, want the raw IFD", n, f.Ifd))
+		}
+	}
+
+	planes := 1
+	switch dirInt(raw, 0x0106) {
+	case photometricCFA:
+		planes = 3
+		if t := tags[CFAPlaneColor]; t != nil && t.Count > 0 {
+			planes = int(t.Count)
+		}
+		dim, pattern := tags[CFARepeatPatternDim], tags[CFAPattern2]
+		switch {
+		case dim == nil || pattern == nil:
+			errs = append(errs, errors.New("exif: CFA raw image without CFARepeatPatternDim and CFAPattern"))
+		case dim.Count == 2 && dim.Format() == tiff.IntVal:
+			rows, _ := dim.Int64(0)
+			cols, _ := dim.Int64(1)
+			if rows*cols != int64(pattern.Count) {
+				errs = append(errs, fmt.Errorf("exif: CFAPattern has %d values, want %d for a %dx%d pattern", pattern.Count, rows*cols, rows, cols))
+			}
+		}
+	case photometricLinearRaw:
+		if n := dirInt(raw, 0x0115); n > 1 {
+			planes = int(n)
+		}
+	}
+
+	if planes > 1 {
+		if _, ok := x.main[ColorMatrix1]; !ok {
+			errs = append(errs, fmt.Errorf("exif: required field %v is missing for a raw image with %d color planes", ColorMatrix1, planes))
+		}
+	}
+	for _, n := range []FieldName{ColorMatrix1, ColorMatrix2, ColorMatrix3, ForwardMatrix1, ForwardMatrix2, ForwardMatrix3} {
+		if f, ok := x.main[n]; ok && f.Tag.Count != uint32(3*planes) {
+			errs = append(errs, fmt.Errorf("exif: field %v has %d values, want %d for %d color planes", n, f.Tag.Count, 3*planes, planes))
+		}
+	}
+
+	if t := tags[ActiveArea]; t != nil && t.Count == 4 && t.Format() == tiff.IntVal {
+		var edge [4]int64
+		for i := range edge {
+			edge[i], _ = t.Int64(i)
+		}
+		width, length := dirInt(raw, 0x0100), dirInt(raw, 0x0101)
+		if edge[0] >= edge[2] || edge[1] >= edge[3] || edge[2] > length || edge[3] > width {
+			errs = append(errs, fmt.Errorf("exif: ActiveArea %v does not fit in the %dx%d raw image", edge, width, length))
+		}
+	}
+	return errs
+}
+
+// dngVersion returns the value of the version field name, if it holds 4
+// bytes.
+func (x *Exif) dngVersion(name FieldName) (v [4]byte, ok bool) {
+	tag, err := x.Get(name)
+	if err != nil || tag.Type != tiff.DTByte || len(tag.Val) != 4 {
+		return v, false
+	}
+	copy(v[:], tag.Val)
+	return v, true
+}
+
+func dngVersionString(v [4]byte) string {
+	return fmt.Sprintf("%d.%d.%d.%d", v[0], v[1], v[2], v[3])
+}
+
+func compareDNGVersions(a, b [4]byte) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return int(a[i]) - int(b[i])
+		}
+	}
+	return 0
+}
+
+// dngRawDir returns the IFD holding the main raw image of a DNG file and the
+// IfdID it was loaded as: the first of IFD0 and the SubIFDs with a
+// NewSubfileType of 0 and a CFA or linear raw PhotometricInterpretation.
+func (x *Exif) dngRawDir() (*tiff.Dir, IfdID) {
+	for _, ifd := range []IfdID{Ifd0, IfdSubIFD} {
+		for _, d := range x.dirs[ifd] {
+			if p := dirInt(d, 0x0106); dirInt(d, 0x00FE) == 0 && (p == photometricCFA || p == photometricLinearRaw) {
+				return d, ifd
+			}
+		}
+	}
+	return nil, 0
+}
+
+// dirInt returns the first value of the integer tag id of d, or 0 if d does
+// not hold it.
+func dirInt(d *tiff.Dir, id uint16) int64 {
+	for _, t := range d.Tags {
+		if t.Id == id && t.Count > 0 && t.Format() == tiff.IntVal {
+			v, _ := t.Int64(0)
+			return v
+		}
+	}
+	return 0
+}
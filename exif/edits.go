@@ -0,0 +1,170 @@
+package exif
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ClueKind classifies the evidence of editing reported in an EditReport.
+type ClueKind int
+
+const (
+	// ClueSoftware is an editor named by the Software field (or a similar
+	// field of other metadata).
+	ClueSoftware ClueKind = iota
+	// ClueProcessingSoftware is a ProcessingSoftware field, which is
+	// written by raw converters and other software processing the image.
+	ClueProcessingSoftware
+	// ClueDates is a DateTime more than a minute later than
+	// DateTimeOriginal.
+	ClueDates
+	// ClueHistory is an editing event recorded in the XMP history (see the
+	// xmp package).
+	ClueHistory
+	// ClueStructure is a layout of the tiff data cameras do not write:
+	// padding tags or IFDs stored before the IFD pointing to them.
+	ClueStructure
+)
+
+func (k ClueKind) String() string {
+	switch k {
+	case ClueSoftware:
+		return "software"
+	case ClueProcessingSoftware:
+		return "processing software"
+	case ClueDates:
+		return "dates"
+	case ClueHistory:
+		return "history"
+	case ClueStructure:
+		return "structure"
+	}
+	return fmt.Sprintf("ClueKind(%d)", int(k))
+}
+
+// An EditClue is a piece of evidence that an image was edited after it was
+// taken.
+type EditClue struct {
+	Kind   ClueKind
+	Detail string
+}
+
+func (c EditClue) String() string {
+	return c.Kind.String() + ": " + c.Detail
+}
+
+// An EditReport holds the evidence of editing found in the metadata of an
+// image, for forensics tools telling camera-original files from edited ones.
+// The evidence is heuristic: its absence does not prove that an image is
+// camera-original, as editors may preserve or forge metadata, and some
+// cameras write metadata the way editors do.
+type EditReport struct {
+	// Software and ProcessingSoftware hold the values of the fields of the
+	// same names, or "" if missing.
+	Software           string
+	ProcessingSoftware string
+	// Clues lists the evidence found.
+	Clues []EditClue
+}
+
+// Edited reports whether any evidence of editing was found.
+func (r *EditReport) Edited() bool {
+	return len(r.Clues) > 0
+}
+
+func (r *EditReport) add(kind ClueKind, format string, args ...interface{}) {
+	r.Clues = append(r.Clues, EditClue{kind, fmt.Sprintf(format, args...)})
+}
+
+// Editors lists the names of image editors and converters, as found in the
+// Software field and similar fields of the files they write. IsEditor
+// matches them case-insensitively anywhere in the field.
+var Editors = []string{
+	"Photoshop", "Lightroom", "Camera Raw", "GIMP", "Capture One",
+	"Aperture", "iPhoto", "Photos", "Picasa", "Paint.NET", "Pixelmator",
+	"Affinity", "darktable", "RawTherapee", "digiKam", "ACDSee",
+	"Snapseed", "PhotoScape", "IrfanView", "XnView", "Luminar",
+	"DxO", "ON1", "Corel", "PaintShop", "ImageMagick", "GraphicsMagick",
+	"Microsoft Windows Photo", "Windows Live Photo", "Digital Photo Professional",
+	"Capture NX", "ViewNX", "NX Studio", "Silkypix", "QuickTime", "Opanda",
+}
+
+// IsEditor reports whether software names one of Editors.
+func IsEditor(software string) bool {
+	s := strings.ToLower(software)
+	for _, e := range Editors {
+		if strings.Contains(s, strings.ToLower(e)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tags of the tiff data only written by editors.
+const (
+	processingSoftware = 0x000B
+	padding            = 0xEA1C // written by Microsoft Windows
+	offsetSchema       = 0xEA1D // written by Microsoft Windows
+)
+
+// maxWriteDelay bounds the time between DateTimeOriginal and DateTime
+// Edits accepts from cameras, some of which set DateTime when they are
+// done writing the file.
+const maxWriteDelay = time.Minute
+
+// Edits inspects the metadata of x for evidence of editing: an editor named
+// by the Software field, a ProcessingSoftware field, a DateTime more than a
+// minute later than DateTimeOriginal, and a layout of the tiff data cameras do not write. Use
+// the Edits function of the xmp package to also inspect the XMP history.
+func (x *Exif) Edits() *EditReport {
+	r := &EditReport{Software: x.text(Software)}
+	if r.Software != "" && IsEditor(r.Software) {
+		r.add(ClueSoftware, "Software is %q", r.Software)
+	}
+	if tag, err := x.GetTagByID(Ifd0, processingSoftware); err == nil {
+		r.ProcessingSoftware = trimText(tag.Val)
+		r.add(ClueProcessingSoftware, "ProcessingSoftware is %q", r.ProcessingSoftware)
+	}
+
+	const layout = "2006:01:02 15:04:05"
+	modified, err1 := time.Parse(layout, x.text(DateTime))
+	taken, err2 := time.Parse(layout, x.text(DateTimeOriginal))
+	if err1 == nil && err2 == nil && modified.Sub(taken) > maxWriteDelay {
+		r.add(ClueDates, "DateTime %v is %v after DateTimeOriginal", x.text(DateTime), modified.Sub(taken))
+	}
+
+	x.structureClues(r)
+	return r
+}
+
+// structureClues adds the evidence of editing found in the layout of the
+// tiff data of x to r.
+func (x *Exif) structureClues(r *EditReport) {
+	for _, ifd := range []IfdID{Ifd0, IfdExif} {
+		if _, err := x.GetTagByID(ifd, padding); err == nil {
+			r.add(ClueStructure, "%v holds a Padding tag", ifd)
+		}
+	}
+	if _, err := x.GetTagByID(IfdExif, offsetSchema); err == nil {
+		r.add(ClueStructure, "%v holds an OffsetSchema tag", IfdExif)
+	}
+
+	offset := func(ifd IfdID) int64 {
+		if _, ok := x.extra[ifd]; ok || len(x.dirs[ifd]) == 0 {
+			return -1
+		}
+		return x.dirs[ifd][0].Layout.Offset
+	}
+	for _, p := range []struct{ parent, child IfdID }{
+		{Ifd0, Ifd1},
+		{Ifd0, IfdExif},
+		{Ifd0, IfdGPS},
+		{IfdExif, IfdInterop},
+	} {
+		parent, child := offset(p.parent), offset(p.child)
+		if parent >= 0 && child >= 0 && child < parent {
+			r.add(ClueStructure, "%v at %d is stored before %v at %d", p.child, child, p.parent, parent)
+		}
+	}
+}
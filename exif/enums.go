@@ -0,0 +1,219 @@
+package exif
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FlashStatus is the value of the Flash field: a bit field describing the
+// status of the flash when the photo was taken (EXIF 2.2, sec. 4.6.5).
+type FlashStatus uint16
+
+// Bits and bit combinations of a FlashStatus. The return light and mode
+// values occupy two bits each and should be compared after masking with
+// FlashReturnMask and FlashModeMask respectively.
+const (
+	FlashFired             FlashStatus = 0x01
+	FlashReturnNotDetected FlashStatus = 0x04
+	FlashReturnDetected    FlashStatus = 0x06
+	FlashModeOn            FlashStatus = 0x08
+	FlashModeOff           FlashStatus = 0x10
+	FlashModeAuto          FlashStatus = 0x18
+	FlashNoFunction        FlashStatus = 0x20
+	FlashRedEyeReduction   FlashStatus = 0x40
+
+	FlashReturnMask FlashStatus = 0x06
+	FlashModeMask   FlashStatus = 0x18
+)
+
+// Fired reports whether the flash fired.
+func (f FlashStatus) Fired() bool { return f&FlashFired != 0 }
+
+func (f FlashStatus) String() string {
+	var parts []string
+	switch f & FlashModeMask {
+	case FlashModeOn:
+		parts = append(parts, "On")
+	case FlashModeOff:
+		parts = append(parts, "Off")
+	case FlashModeAuto:
+		parts = append(parts, "Auto")
+	}
+	switch {
+	case f&FlashNoFunction != 0:
+		parts = append(parts, "No flash function")
+	case f.Fired():
+		parts = append(parts, "Fired")
+	default:
+		parts = append(parts, "Did not fire")
+	}
+	if f&FlashRedEyeReduction != 0 {
+		parts = append(parts, "Red-eye reduction")
+	}
+	switch f & FlashReturnMask {
+	case FlashReturnNotDetected:
+		parts = append(parts, "Return not detected")
+	case FlashReturnDetected:
+		parts = append(parts, "Return detected")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ExposureProgramType is the value of the ExposureProgram field: the class of
+// program used by the camera to set exposure (EXIF 2.2, sec. 4.6.5).
+type ExposureProgramType uint16
+
+const (
+	ExposureProgramNotDefined       ExposureProgramType = 0
+	ExposureProgramManual           ExposureProgramType = 1
+	ExposureProgramNormal           ExposureProgramType = 2
+	ExposureProgramAperturePriority ExposureProgramType = 3
+	ExposureProgramShutterPriority  ExposureProgramType = 4
+	ExposureProgramCreative         ExposureProgramType = 5 // biased toward depth of field
+	ExposureProgramAction           ExposureProgramType = 6 // biased toward fast shutter speed
+	ExposureProgramPortrait         ExposureProgramType = 7
+	ExposureProgramLandscape        ExposureProgramType = 8
+)
+
+var exposureProgramNames = map[ExposureProgramType]string{
+	ExposureProgramNotDefined:       "Not defined",
+	ExposureProgramManual:           "Manual",
+	ExposureProgramNormal:           "Normal program",
+	ExposureProgramAperturePriority: "Aperture priority",
+	ExposureProgramShutterPriority:  "Shutter priority",
+	ExposureProgramCreative:         "Creative program",
+	ExposureProgramAction:           "Action program",
+	ExposureProgramPortrait:         "Portrait mode",
+	ExposureProgramLandscape:        "Landscape mode",
+}
+
+func (p ExposureProgramType) String() string {
+	if name, ok := exposureProgramNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("ExposureProgramType(%d)", uint16(p))
+}
+
+// MeteringModeType is the value of the MeteringMode field (EXIF 2.2, sec.
+// 4.6.5).
+type MeteringModeType uint16
+
+const (
+	MeteringModeUnknown               MeteringModeType = 0
+	MeteringModeAverage               MeteringModeType = 1
+	MeteringModeCenterWeightedAverage MeteringModeType = 2
+	MeteringModeSpot                  MeteringModeType = 3
+	MeteringModeMultiSpot             MeteringModeType = 4
+	MeteringModePattern               MeteringModeType = 5
+	MeteringModePartial               MeteringModeType = 6
+	MeteringModeOther                 MeteringModeType = 255
+)
+
+var meteringModeNames = map[MeteringModeType]string{
+	MeteringModeUnknown:               "Unknown",
+	MeteringModeAverage:               "Average",
+	MeteringModeCenterWeightedAverage: "Center-weighted average",
+	MeteringModeSpot:                  "Spot",
+	MeteringModeMultiSpot:             "Multi-spot",
+	MeteringModePattern:               "Pattern",
+	MeteringModePartial:               "Partial",
+	MeteringModeOther:                 "Other",
+}
+
+func (m MeteringModeType) String() string {
+	if name, ok := meteringModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("MeteringModeType(%d)", uint16(m))
+}
+
+// LightSourceType is the value of the LightSource field (EXIF 2.2, sec.
+// 4.6.5).
+type LightSourceType uint16
+
+const (
+	LightSourceUnknown              LightSourceType = 0
+	LightSourceDaylight             LightSourceType = 1
+	LightSourceFluorescent          LightSourceType = 2
+	LightSourceTungsten             LightSourceType = 3
+	LightSourceFlash                LightSourceType = 4
+	LightSourceFineWeather          LightSourceType = 9
+	LightSourceCloudy               LightSourceType = 10
+	LightSourceShade                LightSourceType = 11
+	LightSourceDaylightFluorescent  LightSourceType = 12
+	LightSourceDayWhiteFluorescent  LightSourceType = 13
+	LightSourceCoolWhiteFluorescent LightSourceType = 14
+	LightSourceWhiteFluorescent     LightSourceType = 15
+	LightSourceWarmWhiteFluorescent LightSourceType = 16
+	LightSourceStandardA            LightSourceType = 17
+	LightSourceStandardB            LightSourceType = 18
+	LightSourceStandardC            LightSourceType = 19
+	LightSourceD55                  LightSourceType = 20
+	LightSourceD65                  LightSourceType = 21
+	LightSourceD75                  LightSourceType = 22
+	LightSourceD50                  LightSourceType = 23
+	LightSourceISOStudioTungsten    LightSourceType = 24
+	LightSourceOther                LightSourceType = 255
+)
+
+var lightSourceNames = map[LightSourceType]string{
+	LightSourceUnknown:              "Unknown",
+	LightSourceDaylight:             "Daylight",
+	LightSourceFluorescent:          "Fluorescent",
+	LightSourceTungsten:             "Tungsten",
+	LightSourceFlash:                "Flash",
+	LightSourceFineWeather:          "Fine weather",
+	LightSourceCloudy:               "Cloudy",
+	LightSourceShade:                "Shade",
+	LightSourceDaylightFluorescent:  "Daylight fluorescent",
+	LightSourceDayWhiteFluorescent:  "Day white fluorescent",
+	LightSourceCoolWhiteFluorescent: "Cool white fluorescent",
+	LightSourceWhiteFluorescent:     "White fluorescent",
+	LightSourceWarmWhiteFluorescent: "Warm white fluorescent",
+	LightSourceStandardA:            "Standard light A",
+	LightSourceStandardB:            "Standard light B",
+	LightSourceStandardC:            "Standard light C",
+	LightSourceD55:                  "D55",
+	LightSourceD65:                  "D65",
+	LightSourceD75:                  "D75",
+	LightSourceD50:                  "D50",
+	LightSourceISOStudioTungsten:    "ISO studio tungsten",
+	LightSourceOther:                "Other",
+}
+
+func (l LightSourceType) String() string {
+	if name, ok := lightSourceNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LightSourceType(%d)", uint16(l))
+}
+
+// intField returns the first value of field name as an int (see GetInt64).
+func (x *Exif) intField(name FieldName) (int, error) {
+	v, err := x.GetInt64(name)
+	return int(v), err
+}
+
+// Flash returns the value of the Flash field.
+func (x *Exif) Flash() (FlashStatus, error) {
+	v, err := x.intField(Flash)
+	return FlashStatus(v), err
+}
+
+// ExposureProgram returns the value of the ExposureProgram field.
+func (x *Exif) ExposureProgram() (ExposureProgramType, error) {
+	v, err := x.intField(ExposureProgram)
+	return ExposureProgramType(v), err
+}
+
+// MeteringMode returns the value of the MeteringMode field.
+func (x *Exif) MeteringMode() (MeteringModeType, error) {
+	v, err := x.intField(MeteringMode)
+	return MeteringModeType(v), err
+}
+
+// LightSource returns the value of the LightSource field.
+func (x *Exif) LightSource() (LightSourceType, error) {
+	v, err := x.intField(LightSource)
+	return LightSourceType(v), err
+}
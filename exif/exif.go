@@ -10,17 +10,25 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"io/fs"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rwcarlsen/goexif/tiff"
 )
 
 const (
-	jpeg_APP1 = 0xE1
+	jpeg_APP0  = 0xE0
+	jpeg_APP1  = 0xE1
+	jpeg_APP14 = 0xEE
+	jpeg_APP15 = 0xEF
+	jpeg_SOS   = 0xDA
+	jpeg_EOI   = 0xD9
+	jpeg_COM   = 0xFE
 
 	exifPointer    = 0x8769
 	gpsPointer     = 0x8825
@@ -36,6 +44,14 @@ func (de decodeError) Error() string {
 	return fmt.Sprintf("exif: decode failed (%v) ", de.cause.Error())
 }
 
+func (de decodeError) Unwrap() error {
+	return de.cause
+}
+
+// ErrLimitExceeded is returned (possibly wrapped, see errors.Is) when
+// decoding would exceed one of the limits set on a Decoder.
+var ErrLimitExceeded = tiff.ErrLimitExceeded
+
 // IsShortReadTagValueError identifies a ErrShortReadTagValue error.
 func IsShortReadTagValueError(err error) bool {
 	de, ok := err.(decodeError)
@@ -45,6 +61,15 @@ func IsShortReadTagValueError(err error) bool {
 	return false
 }
 
+// A FieldName is the name of an EXIF field, under which its tag is loaded.
+// The known fields and their tag IDs are generated from the field registry
+// fields.json.
+type FieldName string
+
+// UnknownPrefix is used as the first part of field names for decoded tags for
+// which there is no known/supported EXIF field.
+const UnknownPrefix = "UnknownTag_"
+
 // A TagNotPresentError is returned when the requested field is not
 // present in the EXIF.
 type TagNotPresentError FieldName
@@ -66,18 +91,6 @@ type Parser interface {
 	Parse(x *Exif) error
 }
 
-var parsers []Parser
-
-func init() {
-	RegisterParsers(&parser{})
-}
-
-// RegisterParsers registers one or more parsers to be automatically called
-// when decoding EXIF data via the Decode function.
-func RegisterParsers(ps ...Parser) {
-	parsers = append(parsers, ps...)
-}
-
 type parser struct{}
 
 type tiffErrors map[tiffError]string
@@ -132,12 +145,14 @@ const (
 	loadExif tiffError = iota
 	loadGPS
 	loadInteroperability
+	loadSubIFDs
 )
 
 var stagePrefix = map[tiffError]string{
 	loadExif:             "loading EXIF sub-IFD",
 	loadGPS:              "loading GPS sub-IFD",
 	loadInteroperability: "loading Interoperability sub-IFD",
+	loadSubIFDs:          "loading SubIFDs",
 }
 
 // Parse reads data from the tiff data in x and populates the tags
@@ -147,25 +162,46 @@ func (p *parser) Parse(x *Exif) error {
 	if len(x.Tiff.Dirs) == 0 {
 		return errors.New("Invalid exif data")
 	}
-	x.LoadTags(x.Tiff.Dirs[0], exifFields, false)
+	x.LoadIfdTags(Ifd0, x.Tiff.Dirs[0], exifFields, false)
 
 	// thumbnails
 	if len(x.Tiff.Dirs) >= 2 {
-		x.LoadTags(x.Tiff.Dirs[1], thumbnailFields, false)
+		x.LoadIfdTags(Ifd1, x.Tiff.Dirs[1], thumbnailFields, false)
 	}
 
 	te := make(tiffErrors)
 
-	// recurse into exif, gps, and interop sub-IFDs
-	if err := loadSubDir(x, ExifIFDPointer, exifFields); err != nil {
+	// recurse into exif, gps, and interop sub-IFDs, as long as DecodeTags
+	// has not found all fields requested
+	if x.foundAll() {
+		return nil
+	}
+	if err := loadSubDir(x, IfdExif, ExifIFDPointer, exifFields); errors.Is(err, ErrLimitExceeded) {
+		return err
+	} else if err != nil {
 		te[loadExif] = err.Error()
 	}
-	if err := loadSubDir(x, GPSInfoIFDPointer, gpsFields); err != nil {
-		te[loadGPS] = err.Error()
+	if !x.foundAll() {
+		if err := loadSubDir(x, IfdGPS, GPSInfoIFDPointer, gpsFields); errors.Is(err, ErrLimitExceeded) {
+			return err
+		} else if err != nil {
+			te[loadGPS] = err.Error()
+		}
 	}
 
-	if err := loadSubDir(x, InteroperabilityIFDPointer, interopFields); err != nil {
-		te[loadInteroperability] = err.Error()
+	if !x.foundAll() {
+		if err := loadSubDir(x, IfdInterop, InteroperabilityIFDPointer, interopFields); errors.Is(err, ErrLimitExceeded) {
+			return err
+		} else if err != nil {
+			te[loadInteroperability] = err.Error()
+		}
+	}
+	if !x.foundAll() {
+		if err := loadSubIFDDirs(x); errors.Is(err, ErrLimitExceeded) {
+			return err
+		} else if err != nil {
+			te[loadSubIFDs] = err.Error()
+		}
 	}
 	if len(te) > 0 {
 		return te
@@ -173,47 +209,446 @@ func (p *parser) Parse(x *Exif) error {
 	return nil
 }
 
-func loadSubDir(x *Exif, ptr FieldName, fieldMap map[uint16]FieldName) error {
+func loadSubDir(x *Exif, ifd IfdID, ptr FieldName, fieldMap map[uint16]FieldName) error {
+	if data, ok := x.extra[ifd]; ok {
+		return loadExtraDir(x, ifd, data, fieldMap)
+	}
 	r := bytes.NewReader(x.Raw)
 
-	tag, err := x.Get(ptr)
+	offset, err := x.GetInt64(ptr)
 	if err != nil {
 		return nil
 	}
-	offset, err := tag.Int64(0)
-	if err != nil {
-		return nil
+	if len(x.Raw) >= 8 && offset == int64(x.Tiff.Order.Uint32(x.Raw[4:8])) {
+		return fmt.Errorf("exif: sub-IFD %s points back to IFD0", ptr)
+	}
+	if len(x.IfdFields(ifd)) > 0 {
+		return fmt.Errorf("exif: sub-IFD %s already loaded", ptr)
 	}
 
 	_, err = r.Seek(offset, 0)
 	if err != nil {
 		return fmt.Errorf("exif: seek to sub-IFD %s failed: %v", ptr, err)
 	}
-	subDir, _, err := tiff.DecodeDir(r, x.Tiff.Order)
+	subDir, _, err := x.dec.tiffDecoder().WithDir(ifd.String()).DecodeDir(r, x.Tiff.Order)
+	if err != nil {
+		return fmt.Errorf("exif: sub-IFD %s decode failed: %w", ptr, err)
+	}
+	x.addDirWarnings(ifd.String(), subDir)
+	x.LoadIfdTags(ifd, subDir, fieldMap, false)
+	return nil
+}
+
+// loadSubIFDDirs loads the IFDs listed by the SubIFDs tag of IFD0, which
+// describe other images than IFD0, such as the raw image data of TIFF based
+// raw formats (e.g. Hasselblad 3FR). Their fields are attributed to
+// IfdSubIFD, but are not returned by Get, as they would shadow the fields
+// of IFD0.
+func loadSubIFDDirs(x *Exif) error {
+	tag, err := x.Get(SubIFDs)
 	if err != nil {
-		return fmt.Errorf("exif: sub-IFD %s decode failed: %v", ptr, err)
+		return nil
+	}
+	if max := x.dec.Limits.MaxIfds; max > 0 && int(tag.Count) > max {
+		return fmt.Errorf("%w: more than %d SubIFDs", ErrLimitExceeded, max)
+	}
+	r := bytes.NewReader(x.Raw)
+	for i := 0; i < int(tag.Count); i++ {
+		offset, err := tag.Int64(i)
+		if err != nil {
+			return nil
+		}
+		if _, err := r.Seek(offset, 0); err != nil {
+			return fmt.Errorf("exif: seek to SubIFD %d failed: %v", i, err)
+		}
+		name := fmt.Sprintf("%v%d", IfdSubIFD, i)
+		subDir, _, err := x.dec.tiffDecoder().WithDir(name).DecodeDir(r, x.Tiff.Order)
+		if err != nil {
+			return fmt.Errorf("exif: SubIFD %d decode failed: %w", i, err)
+		}
+		x.addDirWarnings(name, subDir)
+		x.loadIfdTags(IfdSubIFD, subDir, exifFields, false, false)
 	}
-	x.LoadTags(subDir, fieldMap, false)
 	return nil
 }
 
+// loadExtraDir loads the tags of ifd from the first IFD of the separate tiff
+// data in data. Tag value offsets are relative to data rather than x.Raw.
+func loadExtraDir(x *Exif, ifd IfdID, data []byte, fieldMap map[uint16]FieldName) error {
+	tif, err := x.dec.tiffDecoder().WithDir(ifd.String()).Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("exif: %v decode failed: %w", ifd, err)
+	}
+	if len(tif.Dirs) == 0 {
+		return fmt.Errorf("exif: %v has no IFD", ifd)
+	}
+	x.warnings = append(x.warnings, tif.Warnings...)
+	x.addDirWarnings(ifd.String(), tif.Dirs[0])
+	x.LoadIfdTags(ifd, tif.Dirs[0], fieldMap, false)
+	return nil
+}
+
+// IfdID identifies the IFD (Image File Directory) a tag was read from.
+type IfdID int
+
+const (
+	Ifd0         IfdID = iota // IFD0, describing the primary image
+	Ifd1                      // IFD1, describing the thumbnail image
+	IfdExif                   // Exif sub-IFD
+	IfdGPS                    // GPS sub-IFD
+	IfdInterop                // Interoperability sub-IFD
+	IfdMakerNote              // maker note data loaded by a Parser
+	IfdSubIFD                 // IFDs listed by the SubIFDs tag of IFD0
+)
+
+var ifdNames = map[IfdID]string{
+	Ifd0:         "IFD0",
+	Ifd1:         "IFD1",
+	IfdExif:      "ExifIFD",
+	IfdGPS:       "GPSIFD",
+	IfdInterop:   "InteropIFD",
+	IfdMakerNote: "MakerNote",
+	IfdSubIFD:    "SubIFD",
+}
+
+func (id IfdID) String() string {
+	if name, ok := ifdNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("IfdID(%d)", int(id))
+}
+
+// Field is a decoded EXIF tag together with the name it was loaded under and
+// the IFD it was read from.
+type Field struct {
+	Name FieldName
+	Ifd  IfdID
+	Tag  *tiff.Tag
+}
+
 // Exif provides access to decoded EXIF metadata fields and values.
 type Exif struct {
 	Tiff *tiff.Tiff
-	main map[FieldName]*tiff.Tag
-	Raw  []byte
+	main map[FieldName]*Field
+	// fields holds every loaded field in load order, including those
+	// shadowed in main by a later field of the same name.
+	fields []*Field
+	// dirs holds the IFDs fields were loaded from, including tags
+	// without a known field name.
+	dirs map[IfdID][]*tiff.Dir
+	// byID indexes the first occurrence of each tag in dirs.
+	byID       map[TagID]*tiff.Tag
+	warnings   []error
+	dec        *Decoder
+	segments   []Segment
+	tiffOffset int64
+	extra      map[IfdID][]byte
+	Raw        []byte
 }
 
-// Decode parses EXIF data from r (a TIFF, JPEG, or raw EXIF block)
-// and returns a queryable Exif object. After the EXIF data section is
-// called and the TIFF structure is decoded, each registered parser is
-// called (in order of registration). If one parser returns an error,
-// decoding terminates and the remaining parsers are not called.
+// A Decoder holds the options used to decode EXIF data. The zero value
+// behaves exactly like the package-level Decode function.
+type Decoder struct {
+	// Lenient makes decoding best-effort: tags, IFDs and maker notes that
+	// cannot be decoded are skipped instead of failing the decode, and the
+	// problems encountered are reported by the Warnings method of the
+	// returned Exif. The length of a JPEG EXIF segment contradicting its
+	// tiff data, as written by some phones, is also corrected (see
+	// fixExifLength).
+	Lenient bool
+	// Limits bounds the resources used while decoding, so that servers
+	// parsing untrusted uploads cannot be exhausted by crafted files.
+	// Exceeding a limit fails the decode with an error wrapping
+	// ErrLimitExceeded, even when decoding leniently. MaxBytes also bounds
+	// the container data read into memory to locate the tiff data: the
+	// Exif box of JPEG XL files, the moov box of CR3 files and the whole of
+	// PSD and X3F files.
+	Limits tiff.Limits
+	// Duplicates decides which field Get returns for a tag that occurs more
+	// than once, in one IFD or across IFDs (e.g. DateTime in IFD0 and in the
+	// Exif sub-IFD).
+	Duplicates DuplicatePolicy
+	// Verify makes Decode check the layout of the tiff data with
+	// Exif.Verify and report the problems found by the Warnings method of
+	// the returned Exif.
+	Verify bool
+	// Recover makes Decode salvage files whose offset to IFD0 is bogus by
+	// scanning the tiff data for a plausible IFD, see tiff.Decoder.Recover.
+	// The recovery is reported by the Warnings method of the returned Exif.
+	Recover bool
+	// Magic decides which magic numbers are accepted in the header of TIFF
+	// files, see tiff.Decoder.Magic. Setting it to tiff.MagicVariants makes
+	// Decode accept the TIFF based raw formats listed in
+	// tiff.HeaderVariants, e.g. Olympus ORF.
+	Magic tiff.MagicPolicy
+	// Order, if set, forces the byte order of the tiff data, see
+	// tiff.Decoder.Order, to decode EXIF data whose header is corrupted.
+	// tiff.CheckOrder suggests the order to use.
+	Order binary.ByteOrder
+	// Trace, if set, is called for each JPEG segment found and for each IFD
+	// and tag decoded, with their offsets, to help debug files that do not
+	// decode as expected. IFDs and tags of maker notes are not reported.
+	Trace tiff.TraceFunc
+	// Hook, if set, is called for each tag before its value is decoded,
+	// see tiff.Decoder.Hook. The Dir of the tags passed to it is the name
+	// of their IfdID (e.g. "ExifIFD"), with the SubIFDs numbered from
+	// "SubIFD0". Tags it returns false for are skipped, as if they were
+	// not present. Tags of maker notes are not passed to it.
+	Hook tiff.HookFunc
+	// Charset decides how ASCII fields such as Artist and Copyright are
+	// decoded into the strings returned by StringVal, see tiff.Charset.
+	// The bytes as stored remain available from Tag.RawStringVal.
+	Charset tiff.Charset
+	// Alloc, if set, is called with the number of bytes about to be
+	// allocated for the decoded data, including the tiff data and JPEG
+	// segments read, so that services can meter the memory used by each
+	// decode. Passing the Alloc method of a tiff.Meter enforces a budget,
+	// failing the decode with an error wrapping ErrLimitExceeded once it
+	// is exceeded. Maker notes are not accounted for.
+	Alloc tiff.AllocFunc
+	// Buffer, if set, holds the memory reused across decodes, see Buffer.
+	// The Exif values decoded with it must not be used once it is reset.
+	Buffer *Buffer
+
+	// only holds the fields requested from DecodeTags, and want the IDs
+	// of their tags and of the sub-IFD pointers.
+	only map[FieldName]bool
+	want map[uint16]bool
+}
+
+// DuplicatePolicy decides how fields of the same name are resolved. IFDs are
+// loaded in the order IFD0, IFD1, Exif, GPS, Interoperability, followed by
+// maker notes. All occurrences are always returned by IfdFields.
+type DuplicatePolicy int
+
+const (
+	// LastWins makes Get return the field loaded last.
+	LastWins DuplicatePolicy = iota
+	// FirstWins makes Get return the field loaded first.
+	FirstWins
+	// KeepAll makes Get return the field loaded first under its name, and
+	// each later occurrence under the name qualified with its IFD, e.g.
+	// "ExifIFD:DateTime", numbered "ExifIFD:DateTime#2" and so on if that
+	// name is taken. Walk and MarshalJSON include the qualified names.
+	KeepAll
+)
+
+func (dec *Decoder) tiffDecoder() *tiff.Decoder {
+	if dec == nil {
+		return new(tiff.Decoder)
+	}
+	td := &tiff.Decoder{Lenient: dec.Lenient, Limits: dec.Limits, Recover: dec.Recover, Magic: dec.Magic, Trace: dec.Trace, Hook: dec.Hook, Charset: dec.Charset, Alloc: dec.Alloc, Order: dec.Order}
+	if dec.want != nil {
+		td.Want = func(id uint16) bool { return dec.want[id] }
+	}
+	if dec.Buffer != nil {
+		td.Buffer = &dec.Buffer.tiff
+	}
+	return td
+}
+
+// foundAll reports whether x was decoded by DecodeTags and all requested
+// fields have been loaded, so that the remaining sub-IFDs can be skipped.
+func (x *Exif) foundAll() bool {
+	if x.dec == nil || x.dec.only == nil {
+		return false
+	}
+	for name := range x.dec.only {
+		if _, ok := x.main[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Decode parses EXIF data from r (a TIFF, Panasonic RW2, JPEG, JPEG XL,
+// Canon CR3, Sigma X3F, Photoshop PSD, Adobe Illustrator, or raw EXIF block)
+// and returns a queryable Exif object. After the EXIF data section is called
+// and the TIFF structure is decoded, each registered parser is called (in
+// order of registration). If one parser returns an error, decoding
+// terminates and the remaining parsers are not called.
 //
 // The error can be inspected with functions such as IsCriticalError
 // to determine whether the returned object might still be usable.
+//
+// r only needs to implement io.Reader, so an *os.File or an fs.File opened
+// from any fs.FS can be passed directly; see also DecodeFS.
 func Decode(r io.Reader) (*Exif, error) {
+	return new(Decoder).Decode(r)
+}
+
+// DecodeFS is like Decode, but reads the named file from fsys, e.g. an
+// embed.FS holding test data or an os.DirFS. Files implementing io.ReaderAt
+// are read with DecodeReaderAt.
+func DecodeFS(fsys fs.FS, name string) (*Exif, error) {
+	return new(Decoder).DecodeFS(fsys, name)
+}
+
+// DecodeFS is like the package-level DecodeFS function, but honors the
+// options set in dec.
+func (dec *Decoder) DecodeFS(fsys fs.FS, name string) (*Exif, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if ra, ok := f.(io.ReaderAt); ok {
+		fi, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return dec.DecodeReaderAt(ra, fi.Size())
+	}
+	return dec.Decode(f)
+}
+
+// DecodeTags is like Decode, but only decodes the tags of the named fields,
+// e.g. DecodeTags(r, Orientation, DateTimeOriginal), for services that need
+// a few fields from many files. The values of other tags are not read, and
+// the sub-IFDs are only decoded as long as some named field has not been
+// found. Get reports the other fields as not present. Maker note fields can
+// only be found if MakerNote is among names.
+func DecodeTags(r io.Reader, names ...FieldName) (*Exif, error) {
+	return new(Decoder).DecodeTags(r, names...)
+}
+
+// DecodeTags is like the package-level DecodeTags function, but honors the
+// options set in dec.
+func (dec *Decoder) DecodeTags(r io.Reader, names ...FieldName) (*Exif, error) {
+	d := *dec
+	d.only = map[FieldName]bool{}
+	d.want = map[uint16]bool{exifPointer: true, gpsPointer: true, interopPointer: true}
+	for _, name := range names {
+		d.only[name] = true
+		for _, m := range []map[uint16]FieldName{exifFields, thumbnailFields, gpsFields, interopFields} {
+			for id, n := range m {
+				if n == name {
+					d.want[id] = true
+				}
+			}
+		}
+	}
+	return d.Decode(r)
+}
+
+// Decode is like the package-level Decode function, but honors the options
+// set in dec. When decoding leniently, errors returned by parsers are
+// recorded as warnings and the remaining parsers are still called.
+func (dec *Decoder) Decode(r io.Reader) (*Exif, error) {
+	src, err := locateExif(r, dec.Limits.MaxBytes, dec.Trace, dec.Lenient, dec.Buffer)
+	if err != nil {
+		return nil, err
+	}
+	if dec.Alloc != nil {
+		if err := dec.Alloc(src.size()); err != nil {
+			return nil, decodeError{cause: err}
+		}
+	}
+	tif, err := dec.tiffDecoder().DecodeBytes(src.raw)
+	if err != nil {
+		return nil, decodeError{cause: err}
+	}
+
+	// build an exif structure from the tiff
+	x := &Exif{
+		Tiff:       tif,
+		Raw:        src.raw,
+		dec:        dec,
+		segments:   src.segments,
+		tiffOffset: src.offset,
+		extra:      src.extra,
+	}
+	dec.Buffer.indexes(x)
+	x.warnings = append(x.warnings, src.warnings...)
+	x.warnings = append(x.warnings, tif.Warnings...)
+	for i, d := range tif.Dirs {
+		x.addDirWarnings(fmt.Sprintf("IFD%d", i), d)
+	}
+
+	for i, p := range Parsers() {
+		if err := p.Parse(x); err != nil {
+			if dec.Lenient && !errors.Is(err, ErrLimitExceeded) {
+				x.addParserWarnings(i, err)
+				continue
+			}
+			if _, ok := err.(tiffErrors); ok {
+				return x, err
+			}
+			// This should never happen, as Parse always returns a tiffError
+			// for now, but that could change.
+			return x, fmt.Errorf("exif: parser %v failed (%w)", i, err)
+		}
+	}
+	if dec.Verify {
+		x.warnings = append(x.warnings, x.Verify()...)
+	}
 
+	return x, nil
+}
+
+// RawExif returns the tiff-encoded EXIF data found in r (any format
+// supported by Decode) without decoding it. For JPEG files this is the exact
+// payload of the EXIF APPn segment following its "Exif\x00\x00" header,
+// which callers can archive, hash or hand to other tools. For TIFF files it
+// is the entire file, for CR3 files the tiff data holding IFD0 (the CMT1
+// box), for X3F files that of the JPEG preview, and for PSD and Illustrator
+// files the EXIF image resource (ID 0x0422).
+func RawExif(r io.Reader) ([]byte, error) {
+	src, err := locateExif(r, 0, nil, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	return src.raw, nil
+}
+
+// exifSource is the tiff-encoded EXIF data located in a stream.
+type exifSource struct {
+	raw []byte
+	// offset of raw in the stream, -1 if unknown.
+	offset int64
+	// JPEG APPn segments other than the one holding raw
+	segments []Segment
+	// extra holds separate tiff data whose first IFD holds the tags of a
+	// sub-IFD, as found in CR3 files.
+	extra map[IfdID][]byte
+	// warnings holds the problems worked around while locating raw.
+	warnings []error
+}
+
+// readLimited reads all of r, the data named what, failing with an error
+// wrapping ErrLimitExceeded if r holds more than maxBytes bytes and maxBytes
+// is positive.
+func readLimited(r io.Reader, maxBytes int64, what string) ([]byte, error) {
+	if maxBytes > 0 {
+		r = io.LimitReader(r, maxBytes+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("exif: %v read failed: %w", what, err)
+	}
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		return nil, decodeError{cause: fmt.Errorf("%w: %v exceeds %d bytes", ErrLimitExceeded, what, maxBytes)}
+	}
+	return data, nil
+}
+
+// size returns the number of bytes of data held by src.
+func (src *exifSource) size() int64 {
+	n := int64(len(src.raw))
+	for _, seg := range src.segments {
+		n += int64(len(seg.Data))
+	}
+	for _, data := range src.extra {
+		n += int64(len(data))
+	}
+	return n
+}
+
+// locateExif finds and reads the tiff-encoded EXIF data in r. If maxBytes is
+// positive, reading more than maxBytes of tiff data fails with an error
+// wrapping ErrLimitExceeded. If lenient is set, wrong JPEG segment lengths
+// are corrected as described by readAppSegs.
+func locateExif(r io.Reader, maxBytes int64, trace tiff.TraceFunc, lenient bool, buf *Buffer) (*exifSource, error) {
 	// EXIF data in JPEG is stored in the APP1 marker. EXIF data uses the TIFF
 	// format to store data.
 	// If we're parsing a TIFF image, we don't need to strip away any data.
@@ -228,28 +663,46 @@ func Decode(r io.Reader) (*Exif, error) {
 
 	var isTiff bool
 	var isRawExif bool
+	var isJXL bool
+	var isCR3 bool
+	var isX3F bool
+	var isPSD bool
 	var assumeJPEG bool
-	switch string(header) {
-	case "II*\x00":
-		// TIFF - Little endian (Intel)
+	switch _, _, ok := tiff.ParseHeader(header); {
+	case ok:
+		// TIFF, or a TIFF variant such as Panasonic RW2 (see
+		// tiff.HeaderVariants). Whether its magic number is accepted is up
+		// to the tiff decoder.
 		isTiff = true
-	case "MM\x00*":
-		// TIFF - Big endian (Motorola)
-		isTiff = true
-	case "Exif":
+	case string(header) == "Exif":
 		isRawExif = true
+	case string(header) == x3fSignature:
+		isX3F = true
+	case string(header) == psdSignature, string(header) == pdfSignature, string(header) == psSignature:
+		// Photoshop PSD, or an Illustrator file holding Photoshop image
+		// resources
+		isPSD = true
+	case string(header) == string(jxlSignature[:4]):
+		// Possibly an ISO BMFF based JPEG XL container
+		isJXL = true
 	default:
-		// Not TIFF, assume JPEG
-		assumeJPEG = true
+		// Not TIFF, assume JPEG unless this is an ISO BMFF file, which
+		// starts with a ftyp box.
+		typ := make([]byte, 4)
+		n, _ := io.ReadFull(r, typ)
+		header = append(header, typ[:n]...)
+		if string(typ[:n]) == "ftyp" {
+			isCR3 = true
+		} else {
+			assumeJPEG = true
+		}
 	}
 
 	// Put the header bytes back into the reader.
 	r = io.MultiReader(bytes.NewReader(header), r)
-	var (
-		er  *bytes.Reader
-		tif *tiff.Tiff
-		sec *appSec
-	)
+	src := &exifSource{}
+	// offset of the JPEG preview holding the EXIF data of X3F files
+	var x3fOffset int64
 
 	switch {
 	case isRawExif:
@@ -260,67 +713,218 @@ func Decode(r io.Reader) (*Exif, error) {
 		if got, want := string(header[:]), "Exif\x00\x00"; got != want {
 			return nil, fmt.Errorf("exif: unexpected raw exif header; got %q, want %q", got, want)
 		}
+		src.offset = int64(len(exifHeader))
 		fallthrough
 	case isTiff:
-		// Functions below need the IFDs from the TIFF data to be stored in a
-		// *bytes.Reader.  We use TeeReader to get a copy of the bytes as a
-		// side-effect of tiff.Decode() doing its work.
-		b := &bytes.Buffer{}
-		tr := io.TeeReader(r, b)
-		tif, err = tiff.Decode(tr)
-		er = bytes.NewReader(b.Bytes())
-	case assumeJPEG:
-		// Locate the JPEG APP1 header.
-		sec, err = newAppSec(jpeg_APP1, r)
+		if maxBytes > 0 {
+			r = io.LimitReader(r, maxBytes+1)
+		}
+		src.raw, err = io.ReadAll(r)
+		if err != nil {
+			return nil, decodeError{cause: errors.New("tiff: could not read data")}
+		}
+		if maxBytes > 0 && int64(len(src.raw)) > maxBytes {
+			return nil, decodeError{cause: fmt.Errorf("%w: tiff data exceeds %d bytes", ErrLimitExceeded, maxBytes)}
+		}
+	case isJXL:
+		sig := make([]byte, len(jxlSignature))
+		if _, err := io.ReadFull(r, sig); err != nil || !bytes.Equal(sig, jxlSignature) {
+			return nil, errors.New("exif: unrecognized ISO BMFF box, expected JPEG XL signature")
+		}
+		er, err := jxlExifReader(r, maxBytes)
 		if err != nil {
 			return nil, err
 		}
-		// Strip away EXIF header.
-		er, err = sec.exifReader()
+		if src.raw, err = io.ReadAll(er); err != nil {
+			return nil, fmt.Errorf("exif: JPEG XL Exif box read failed: %w", err)
+		}
+		src.offset = -1
+	case isCR3:
+		cmt, err := cr3Metadata(r, maxBytes)
 		if err != nil {
 			return nil, err
 		}
-		tif, err = tiff.Decode(er)
+		src.raw = cmt["CMT1"]
+		src.offset = -1
+		src.extra = map[IfdID][]byte{}
+		if data, ok := cmt["CMT2"]; ok {
+			src.extra[IfdExif] = data
+		}
+		if data, ok := cmt["CMT4"]; ok {
+			src.extra[IfdGPS] = data
+		}
+	case isPSD:
+		src.raw, src.offset, err = psdExif(r, maxBytes)
+		if err != nil {
+			return nil, err
+		}
+	case isX3F:
+		// The EXIF data is stored in the JPEG preview.
+		jr, off, err := x3fReader(r, maxBytes)
+		if err != nil {
+			return nil, err
+		}
+		r, x3fOffset = jr, off
+		fallthrough
+	case assumeJPEG:
+		// Locate the JPEG APPn segment holding the EXIF data, which is
+		// normally but not always the first APP1 segment.
+		sec, cont, app, warnings, err := readAppSegs(r, trace, lenient, buf)
+		if err != nil {
+			return nil, err
+		}
+		src.warnings = warnings
+		// Strip away EXIF header. The segment data is not shared, so the
+		// tiff data is used in place.
+		src.raw = sec.Data[len(exifHeader):]
+		src.segments = app
+		// skip the marker, segment length and EXIF header
+		src.offset = sec.Offset + 4 + int64(len(exifHeader))
+		if len(cont) > 0 {
+			// The tiff data is not contiguous in the file.
+			src.offset = -1
+			for _, seg := range cont {
+				src.raw = append(src.raw, seg.Data[len(exifHeader):]...)
+			}
+			if maxBytes > 0 && int64(len(src.raw)) > maxBytes {
+				return nil, decodeError{cause: fmt.Errorf("%w: tiff data exceeds %d bytes", ErrLimitExceeded, maxBytes)}
+			}
+		}
+	}
+	if isX3F && src.offset >= 0 {
+		src.offset += x3fOffset
 	}
+	if trace != nil && !assumeJPEG {
+		// JPEG segments have been reported by readAppSegs
+		var where string
+		switch {
+		case isTiff:
+			where = "TIFF file"
+		case isRawExif:
+			where = "raw EXIF block"
+		case isJXL:
+			where = "JPEG XL Exif box"
+		case isCR3:
+			where = "Canon CR3 CMT1 box"
+		case isX3F:
+			where = "Sigma X3F JPEG preview"
+		case isPSD:
+			where = "Photoshop image resource"
+		}
+		trace(tiff.TraceEvent{
+			Kind:   tiff.TraceSegment,
+			Offset: src.offset,
+			Length: int64(len(src.raw)),
+			Msg:    "EXIF data in " + where,
+		})
+	}
+	return src, nil
+}
 
-	if err != nil {
-		return nil, decodeError{cause: err}
+// Warnings returns the problems encountered while decoding leniently (see
+// Decoder). The corresponding tags, IFDs or maker notes are missing from x.
+// If Decoder.Verify is set, it also returns the problems found by Verify.
+func (x *Exif) Warnings() []error {
+	return x.warnings
+}
+
+// TiffOffset returns the offset of the tiff-encoded EXIF data (i.e. the first
+// byte of x.Raw) from the start of the stream x was decoded from. Tag value
+// offsets are relative to it. ok is false if the offset is not known, or if
+// the data is not contiguous in the stream because it was split across
+// several JPEG segments.
+func (x *Exif) TiffOffset() (offset int64, ok bool) {
+	if x.tiffOffset < 0 || x.Tiff == nil {
+		return 0, false
 	}
+	return x.tiffOffset, true
+}
 
-	er.Seek(0, 0)
-	raw, err := ioutil.ReadAll(er)
-	if err != nil {
-		return nil, decodeError{cause: err}
+// Segments returns the JPEG APPn segments other than the one the EXIF data
+// was decoded from (e.g. JFIF, XMP, ICC profile, Ducky or Adobe segments)
+// and the COM segments, in stream order. It returns nil if the EXIF data did
+// not come from a JPEG.
+func (x *Exif) Segments() []Segment {
+	return x.segments
+}
+
+// AppSegments returns the segments returned by Segments whose identifier
+// (see Segment.Identifier) is id, e.g. AppSegments("Ducky").
+func (x *Exif) AppSegments(id string) []Segment {
+	var segs []Segment
+	for _, seg := range x.segments {
+		if seg.Identifier() == id {
+			segs = append(segs, seg)
+		}
 	}
+	return segs
+}
 
-	// build an exif structure from the tiff
-	x := &Exif{
-		main: map[FieldName]*tiff.Tag{},
-		Tiff: tif,
-		Raw:  raw,
+// Comments returns the text of the JPEG COM segments, in stream order.
+func (x *Exif) Comments() []string {
+	var c []string
+	for _, seg := range x.segments {
+		if seg.Marker == jpeg_COM {
+			c = append(c, strings.TrimRight(string(seg.Data), "\x00"))
+		}
 	}
+	return c
+}
 
-	for i, p := range parsers {
-		if err := p.Parse(x); err != nil {
-			if _, ok := err.(tiffErrors); ok {
-				return x, err
-			}
-			// This should never happen, as Parse always returns a tiffError
-			// for now, but that could change.
-			return x, fmt.Errorf("exif: parser %v failed (%v)", i, err)
+func (x *Exif) addDirWarnings(where string, d *tiff.Dir) {
+	for _, w := range d.Warnings {
+		x.warnings = append(x.warnings, fmt.Errorf("exif: %s: %v", where, w))
+	}
+}
+
+func (x *Exif) addParserWarnings(i int, err error) {
+	te, ok := err.(tiffErrors)
+	if !ok {
+		x.warnings = append(x.warnings, &parserError{i, err})
+		return
+	}
+	for _, stage := range []tiffError{loadExif, loadGPS, loadInteroperability, loadSubIFDs} {
+		if msg, ok := te[stage]; ok {
+			x.warnings = append(x.warnings, fmt.Errorf("exif: %s: %s", stagePrefix[stage], msg))
 		}
 	}
+}
 
-	return x, nil
+// parserError is the warning recorded for the i-th parser failing with err
+// while decoding leniently, e.g. on a maker note that cannot be decoded.
+type parserError struct {
+	i   int
+	err error
+}
+
+func (e *parserError) Error() string {
+	return fmt.Sprintf("exif: parser %v failed (%v)", e.i, e.err)
 }
 
+func (e *parserError) Unwrap() error { return e.err }
+
 // LoadTags loads tags into the available fields from the tiff Directory
 // using the given tagid-fieldname mapping.  Used to load makernote and
 // other meta-data.  If showMissing is true, tags in d that are not in the
 // fieldMap will be loaded with the FieldName UnknownPrefix followed by the
-// tag ID (in hex format).
+// tag ID (in hex format).  The loaded fields are attributed to IfdMakerNote;
+// use LoadIfdTags to attribute them to another IFD.
 func (x *Exif) LoadTags(d *tiff.Dir, fieldMap map[uint16]FieldName, showMissing bool) {
-	for _, tag := range d.Tags {
+	x.LoadIfdTags(IfdMakerNote, d, fieldMap, showMissing)
+}
+
+// LoadIfdTags is like LoadTags, but records ifd as the IFD the loaded fields
+// were read from.
+func (x *Exif) LoadIfdTags(ifd IfdID, d *tiff.Dir, fieldMap map[uint16]FieldName, showMissing bool) {
+	x.loadIfdTags(ifd, d, fieldMap, showMissing, true)
+}
+
+// loadIfdTags is like LoadIfdTags, but only makes the loaded fields
+// available to Get if get is true.
+func (x *Exif) loadIfdTags(ifd IfdID, d *tiff.Dir, fieldMap map[uint16]FieldName, showMissing, get bool) {
+	x.indexDir(ifd, d)
+	fields := x.buffer().newFields(len(d.Tags))
+	for i, tag := range d.Tags {
 		name := fieldMap[tag.Id]
 		if name == "" {
 			if !showMissing {
@@ -328,7 +932,54 @@ func (x *Exif) LoadTags(d *tiff.Dir, fieldMap map[uint16]FieldName, showMissing
 			}
 			name = FieldName(fmt.Sprintf("%v%x", UnknownPrefix, tag.Id))
 		}
-		x.main[name] = tag
+		f := &fields[i]
+		*f = Field{Name: name, Ifd: ifd, Tag: tag}
+		if get {
+			x.setField(f)
+		}
+		x.fields = append(x.fields, f)
+	}
+}
+
+// indexDir adds d to the dirs of ifd and indexes its tags for GetTagByID.
+func (x *Exif) indexDir(ifd IfdID, d *tiff.Dir) {
+	if x.dirs == nil {
+		x.dirs = map[IfdID][]*tiff.Dir{}
+	}
+	if x.byID == nil {
+		x.byID = map[TagID]*tiff.Tag{}
+	}
+	x.dirs[ifd] = append(x.dirs[ifd], d)
+	for _, tag := range d.Tags {
+		id := TagID{ifd, tag.Id}
+		if _, ok := x.byID[id]; !ok {
+			x.byID[id] = tag
+		}
+	}
+}
+
+// setField makes f available to Get under its name, resolving a field of
+// the same name loaded before according to the DuplicatePolicy of x's
+// Decoder.
+func (x *Exif) setField(f *Field) {
+	if _, dup := x.main[f.Name]; !dup {
+		x.main[f.Name] = f
+		return
+	}
+	var policy DuplicatePolicy
+	if x.dec != nil {
+		policy = x.dec.Duplicates
+	}
+	switch policy {
+	case FirstWins:
+	case KeepAll:
+		name := FieldName(fmt.Sprintf("%v:%v", f.Ifd, f.Name))
+		for i := 2; x.main[name] != nil; i++ {
+			name = FieldName(fmt.Sprintf("%v:%v#%d", f.Ifd, f.Name, i))
+		}
+		x.main[name] = f
+	default:
+		x.main[f.Name] = f
 	}
 }
 
@@ -337,12 +988,71 @@ func (x *Exif) LoadTags(d *tiff.Dir, fieldMap map[uint16]FieldName, showMissing
 // If the tag is not known or not present, an error is returned. If the
 // tag name is known, the error will be a TagNotPresentError.
 func (x *Exif) Get(name FieldName) (*tiff.Tag, error) {
-	if tg, ok := x.main[name]; ok {
-		return tg, nil
+	if f, ok := x.main[name]; ok {
+		return f.Tag, nil
 	}
 	return nil, TagNotPresentError(name)
 }
 
+// GetField is like Get, but also reports which IFD the tag was read from.
+func (x *Exif) GetField(name FieldName) (*Field, error) {
+	if f, ok := x.main[name]; ok {
+		return f, nil
+	}
+	return nil, TagNotPresentError(name)
+}
+
+// Has reports whether Get would return a tag for field name, without the
+// cost of building the TagNotPresentError of absent fields.
+func (x *Exif) Has(name FieldName) bool {
+	_, ok := x.main[name]
+	return ok
+}
+
+// Fields returns the names of the fields available through Get, sorted, so
+// that the fields present can be filtered without calling Get for each of
+// the possible field names.
+func (x *Exif) Fields() []FieldName {
+	names := make([]FieldName, 0, len(x.main))
+	for name := range x.main {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// GetTagByID retrieves the tag with the given ID from the given IFD,
+// whether or not it has a known field name. This gives access to proprietary
+// tags and tags not yet listed in this package. If ifd holds the tag more
+// than once, the first occurrence is returned. If the tag is not present, the
+// error will be a TagNotPresentError.
+func (x *Exif) GetTagByID(ifd IfdID, tagID uint16) (*tiff.Tag, error) {
+	if tag, ok := x.byID[TagID{ifd, tagID}]; ok {
+		return tag, nil
+	}
+	return nil, TagNotPresentError(fmt.Sprintf("%v:0x%04x", ifd, tagID))
+}
+
+// IfdFields returns all fields loaded from the given IFD in the order they
+// were loaded. Unlike Get, it also returns fields whose name is shadowed by
+// a field of the same name loaded later from another IFD.
+func (x *Exif) IfdFields(ifd IfdID) []*Field {
+	var fs []*Field
+	for _, f := range x.fields {
+		if f.Ifd == ifd {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// IfdDirs returns the decoded tiff directories tags were loaded from for the
+// given IFD, e.g. to inspect their Layout. It returns nil if the IFD is not
+// present or its tags have not been loaded.
+func (x *Exif) IfdDirs(ifd IfdID) []*tiff.Dir {
+	return x.dirs[ifd]
+}
+
 // Walker is the interface used to traverse all fields of an Exif object.
 type Walker interface {
 	// Walk is called for each non-nil EXIF field. Returning a non-nil
@@ -353,8 +1063,8 @@ type Walker interface {
 // Walk calls the Walk method of w with the name and tag for every non-nil
 // EXIF field.  If w aborts the walk with an error, that error is returned.
 func (x *Exif) Walk(w Walker) error {
-	for name, tag := range x.main {
-		if err := w.Walk(name, tag); err != nil {
+	for name, f := range x.main {
+		if err := w.Walk(name, f.Tag); err != nil {
 			return err
 		}
 	}
@@ -371,6 +1081,17 @@ func (x *Exif) Walk(w Walker) error {
 // If the EXIF lacks timezone information or GPS time, the returned
 // time's Location will be time.Local.
 func (x *Exif) DateTime() (time.Time, error) {
+	// TODO(bradfitz,mpl): look for timezone offset, GPS time, etc.
+	timeZone := time.Local
+	if tz, _ := x.TimeZone(); tz != nil {
+		timeZone = tz
+	}
+	return x.dateTimeIn(timeZone)
+}
+
+// dateTimeIn is like DateTime, but interprets the date and time as being in
+// the location loc.
+func (x *Exif) dateTimeIn(loc *time.Location) (time.Time, error) {
 	var dt time.Time
 	tag, err := x.Get(DateTimeOriginal)
 	if err != nil {
@@ -384,12 +1105,7 @@ func (x *Exif) DateTime() (time.Time, error) {
 	}
 	exifTimeLayout := "2006:01:02 15:04:05"
 	dateStr := strings.TrimRight(string(tag.Val), "\x00")
-	// TODO(bradfitz,mpl): look for timezone offset, GPS time, etc.
-	timeZone := time.Local
-	if tz, _ := x.TimeZone(); tz != nil {
-		timeZone = tz
-	}
-	return time.ParseInLocation(exifTimeLayout, dateStr, timeZone)
+	return time.ParseInLocation(exifTimeLayout, dateStr, loc)
 }
 
 func (x *Exif) TimeZone() (*time.Location, error) {
@@ -468,6 +1184,9 @@ func parseTagDegreesString(s string) (float64, error) {
 
 func parse3Rat2(tag *tiff.Tag) ([3]float64, error) {
 	v := [3]float64{}
+	if tag.Count == 0 {
+		return v, errors.New("exif: tag has no values")
+	}
 	for i := range v {
 		num, den, err := tag.Rat2(i)
 		if err != nil {
@@ -549,8 +1268,8 @@ func (x *Exif) LatLong() (lat, long float64, err error) {
 // String returns a pretty text representation of the decoded exif data.
 func (x *Exif) String() string {
 	var buf bytes.Buffer
-	for name, tag := range x.main {
-		fmt.Fprintf(&buf, "%s: %s\n", name, tag)
+	for name, f := range x.main {
+		fmt.Fprintf(&buf, "%s: %s\n", name, f.Tag)
 	}
 	return buf.String()
 }
@@ -558,98 +1277,271 @@ func (x *Exif) String() string {
 // JpegThumbnail returns the jpeg thumbnail if it exists. If it doesn't exist,
 // TagNotPresentError will be returned
 func (x *Exif) JpegThumbnail() ([]byte, error) {
-	offset, err := x.Get(ThumbJPEGInterchangeFormat)
+	start, err := x.intField(ThumbJPEGInterchangeFormat)
 	if err != nil {
 		return nil, err
 	}
-	start, err := offset.Int(0)
+	l, err := x.intField(ThumbJPEGInterchangeFormatLength)
 	if err != nil {
 		return nil, err
 	}
 
-	length, err := x.Get(ThumbJPEGInterchangeFormatLength)
-	if err != nil {
-		return nil, err
+	if start < 0 || l < 0 || start > len(x.Raw) || l > len(x.Raw)-start {
+		return nil, errors.New("exif: thumbnail data out of range")
 	}
-	l, err := length.Int(0)
+	return x.Raw[start : start+l], nil
+}
+
+// MakerNote returns the raw maker note data of x, for decoding proprietary
+// formats not supported by a registered Parser. Maker notes often hold
+// offsets relative to the start of the tiff data rather than to the maker
+// note itself: such an offset refers to data[offset-base], where base is the
+// offset of the maker note in the tiff data (i.e. in x.Raw, except for CR3
+// files, whose Exif IFD is stored in separate tiff data). order is the byte
+// order of the tiff data, which most maker notes also use. If there is no
+// maker note, TagNotPresentError is returned.
+func (x *Exif) MakerNote() (data []byte, base int64, order binary.ByteOrder, err error) {
+	tag, err := x.GetTagByID(IfdExif, 0x927C)
 	if err != nil {
-		return nil, err
+		return nil, 0, nil, TagNotPresentError(MakerNote)
 	}
-
-	return x.Raw[start : start+l], nil
+	return tag.Val, int64(tag.ValOffset), tag.Order(), nil
 }
 
 // MarshalJson implements the encoding/json.Marshaler interface providing output of
 // all EXIF fields present (names and values).
 func (x Exif) MarshalJSON() ([]byte, error) {
-	return json.Marshal(x.main)
+	tags := make(map[FieldName]*tiff.Tag, len(x.main))
+	for name, f := range x.main {
+		tags[name] = f.Tag
+	}
+	return json.Marshal(tags)
 }
 
-type appSec struct {
-	marker byte
-	data   []byte
+// Segment is a JPEG marker segment.
+type Segment struct {
+	// Marker is the second byte of the segment's marker (e.g. 0xE1 for
+	// APP1).
+	Marker byte
+	// Offset is the offset of the segment's marker from the start of the
+	// JPEG stream.
+	Offset int64
+	// Data holds the segment payload, excluding the marker and length.
+	Data []byte
 }
 
-// newAppSec finds marker in r and returns the corresponding application data
-// section.
-func newAppSec(marker byte, r io.Reader) (*appSec, error) {
-	br := bufio.NewReader(r)
-	app := &appSec{marker: marker}
-	var dataLen int
+// isExif reports whether seg holds EXIF data.
+func (seg *Segment) isExif() bool {
+	return bytes.HasPrefix(seg.Data, exifHeader)
+}
 
-	// seek to marker
-	for dataLen == 0 {
-		if _, err := br.ReadBytes(0xFF); err != nil {
-			return nil, err
+// Identifier returns the NUL-terminated string starting the payload of an
+// APPn segment, which identifies its format (e.g. "Exif", "JFIF", "Ducky" or
+// "Adobe"), or "" if seg is not an APPn segment or has no identifier.
+func (seg *Segment) Identifier() string {
+	if seg.Marker < jpeg_APP0 || seg.Marker > jpeg_APP15 {
+		return ""
+	}
+	if i := bytes.IndexByte(seg.Data, 0); i > 0 && i <= 64 {
+		return string(seg.Data[:i])
+	}
+	return ""
+}
+
+// describe returns the marker of seg and, for APPn segments, the identifier
+// string starting the payload (e.g. `APP1 "Exif"`).
+func (seg *Segment) describe() string {
+	switch {
+	case seg.Marker == jpeg_COM:
+		return "COM"
+	case seg.Marker < jpeg_APP0 || seg.Marker > jpeg_APP15:
+		return fmt.Sprintf("marker 0x%02X", seg.Marker)
+	}
+	s := fmt.Sprintf("APP%d", seg.Marker-jpeg_APP0)
+	if id := seg.Identifier(); id != "" {
+		s += " " + strconv.Quote(id)
+	}
+	return s
+}
+
+// isExifContinuation reports whether seg continues the EXIF data of an
+// earlier segment: it starts with the EXIF header, which is not followed by
+// a tiff header as in the first segment.
+func (seg *Segment) isExifContinuation() bool {
+	if !seg.isExif() {
+		return false
+	}
+	rest := seg.Data[len(exifHeader):]
+	return !bytes.HasPrefix(rest, []byte("II*\x00")) && !bytes.HasPrefix(rest, []byte("MM\x00*"))
+}
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// readerPool holds the buffered readers used by readAppSegs, which would
+// otherwise dominate the allocations of decoding small files.
+var readerPool = sync.Pool{
+	New: func() interface{} { return bufio.NewReader(nil) },
+}
+
+// readAppSegs reads the APPn segments of the JPEG stream in r up to the start
+// of the image data. The first segment holding EXIF data, regardless of its
+// APPn marker and position, is returned as exifSeg. EXIF data too large for a
+// single segment may continue in later segments with the same marker that
+// start with the EXIF header but not with a tiff header; these are returned
+// as cont. All other APPn segments and the COM segments are returned in
+// stream order. Read errors after the EXIF segment has been found (e.g. in
+// truncated files) are ignored.
+//
+// Damaged streams are scanned leniently: leading padding or garbage before
+// the SOI marker is skipped, and whenever the segment structure is lost (an
+// invalid marker or segment length, or the image data has been reached
+// without finding EXIF data, e.g. in a preview image prepended to the
+// file), the scan resyncs to the next SOI or APP1 marker. If lenient is set,
+// the length of the EXIF segment is checked against its tiff data by
+// fixExifLength, and the corrections made are returned as warnings. The
+// segment data is allocated from buf if not nil.
+func readAppSegs(r io.Reader, trace tiff.TraceFunc, lenient bool, buf *Buffer) (exifSeg *Segment, cont, others []Segment, warnings []error, err error) {
+	cr := &countingReader{r: r}
+	br := readerPool.Get().(*bufio.Reader)
+	br.Reset(cr)
+	defer func() {
+		br.Reset(nil)
+		readerPool.Put(br)
+	}()
+	offset := func() int64 { return cr.n - int64(br.Buffered()) }
+
+	// synced is set while the scan is positioned at segment boundaries.
+	synced := false
+	resync := func(why string) {
+		synced = false
+		if trace != nil {
+			trace(tiff.TraceEvent{Kind: tiff.TraceSegment, Offset: offset(), Msg: why + ", resyncing"})
 		}
-		c, err := br.ReadByte()
-		if err != nil {
-			return nil, err
-		} else if c != marker {
+	}
+	for {
+		// seek to marker
+		if err = skipToFF(br); err != nil {
+			break
+		}
+		var c byte
+		if c, err = br.ReadByte(); err != nil {
+			break
+		}
+		if c == 0xFF {
+			// fill byte; the marker follows
+			br.UnreadByte()
 			continue
 		}
-
-		dataLenBytes := make([]byte, 2)
-		for k, _ := range dataLenBytes {
-			c, err := br.ReadByte()
-			if err != nil {
-				return nil, err
+		if !synced {
+			// Only resync to an APP1 marker if it starts an EXIF
+			// segment, as it may be garbage with a bogus length.
+			if c == jpeg_APP1 {
+				head, _ := br.Peek(2 + len(exifHeader))
+				if len(head) < 2+len(exifHeader) || !bytes.Equal(head[2:], exifHeader) {
+					continue
+				}
+			} else if c != 0xD8 {
+				continue
 			}
-			dataLenBytes[k] = c
+			synced = true
 		}
-		dataLen = int(binary.BigEndian.Uint16(dataLenBytes)) - 2
+		switch {
+		case c == 0x01, c >= 0xD0 && c <= 0xD8:
+			// marker without a payload
+			continue
+		case c < 0xC0:
+			resync(fmt.Sprintf("invalid marker 0x%02X", c))
+			continue
+		case c == jpeg_SOS, c == jpeg_EOI:
+			// image data starts, no more metadata segments
+			if exifSeg == nil {
+				resync("no EXIF data before image data")
+				continue
+			}
+		default:
+			seg := Segment{Marker: c, Offset: offset() - 2}
+			var hi, lo byte
+			if hi, err = br.ReadByte(); err != nil {
+				break
+			}
+			if lo, err = br.ReadByte(); err != nil {
+				break
+			}
+			dataLen := int(hi)<<8 | int(lo) - 2
+			if dataLen < 0 {
+				resync(fmt.Sprintf("invalid length of segment at offset %d", seg.Offset))
+				continue
+			}
+			seg.Data = buf.newData(dataLen)
+			var n int
+			if n, err = io.ReadFull(br, seg.Data); err != nil {
+				if !lenient || exifSeg != nil || err != io.ErrUnexpectedEOF || !bytes.HasPrefix(seg.Data[:n], exifHeader) {
+					break
+				}
+				// an EXIF segment whose length exceeds the file
+				seg.Data, err = seg.Data[:n], nil
+			}
+			if lenient && exifSeg == nil && seg.isExif() {
+				var warning error
+				if cr, warning = fixExifLength(&seg, dataLen, br, cr); warning != nil {
+					warnings = append(warnings, warning)
+				}
+			}
+			if trace != nil {
+				trace(tiff.TraceEvent{
+					Kind:   tiff.TraceSegment,
+					Offset: seg.Offset,
+					Length: int64(len(seg.Data) + 4),
+					Msg:    seg.describe(),
+				})
+			}
+			if c == jpeg_COM {
+				others = append(others, seg)
+				continue
+			}
+			if c < jpeg_APP0 || c > jpeg_APP15 {
+				continue
+			}
+			if exifSeg == nil && seg.isExif() {
+				found := seg
+				exifSeg = &found
+			} else if exifSeg != nil && c == exifSeg.Marker && seg.isExifContinuation() {
+				cont = append(cont, seg)
+			} else {
+				others = append(others, seg)
+			}
+			continue
+		}
+		break
 	}
 
-	// read section data
-	nread := 0
-	for nread < dataLen {
-		s := make([]byte, dataLen-nread)
-		n, err := br.Read(s)
-		nread += n
-		if err != nil && nread < dataLen {
-			return nil, err
+	if exifSeg == nil {
+		if err != nil && err != io.EOF {
+			return nil, nil, nil, nil, err
 		}
-		app.data = append(app.data, s[:n]...)
+		return nil, nil, nil, nil, errors.New("exif: failed to find exif intro marker")
 	}
-	return app, nil
-}
-
-// reader returns a reader on this appSec.
-func (app *appSec) reader() *bytes.Reader {
-	return bytes.NewReader(app.data)
+	return exifSeg, cont, others, warnings, nil
 }
 
-// exifReader returns a reader on this appSec with the read cursor advanced to
-// the start of the exif's tiff encoded portion.
-func (app *appSec) exifReader() (*bytes.Reader, error) {
-	if len(app.data) < 6 {
-		return nil, errors.New("exif: failed to find exif intro marker")
-	}
-
-	// read/check for exif special mark
-	exif := app.data[:6]
-	if !bytes.Equal(exif, append([]byte("Exif"), 0x00, 0x00)) {
-		return nil, errors.New("exif: failed to find exif intro marker")
+// skipToFF advances br past the next 0xFF byte.
+func skipToFF(br *bufio.Reader) error {
+	for {
+		_, err := br.ReadSlice(0xFF)
+		if err != bufio.ErrBufferFull {
+			return err
+		}
 	}
-	return bytes.NewReader(app.data[6:]), nil
 }
@@ -2,17 +2,31 @@ package exif
 
 //go:generate go run regen_regress.go -- regress_expected_test.go
 //go:generate go fmt regress_expected_test.go
+//go:generate go run gen_fields.go -o fields.go fields.json
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io/ioutil"
+	"image"
+	"image/jpeg"
+	"io"
+	"io/fs"
+	"log/slog"
 	"math"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/rwcarlsen/goexif/tiff"
 )
@@ -61,7 +75,7 @@ func TestDecode(t *testing.T) {
 
 func TestDecodeRawEXIF(t *testing.T) {
 	rawFile := filepath.Join(*dataDir, "samples", "raw.exif")
-	raw, err := ioutil.ReadFile(rawFile)
+	raw, err := os.ReadFile(rawFile)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -86,6 +100,67 @@ func TestDecodeRawEXIF(t *testing.T) {
 	if g, w := got["DateTime"], `"2018:04:03 07:54:33"`; g != w {
 		t.Errorf("DateTime value = %q; want %q", g, w)
 	}
+	testMaxBytes(t, "raw EXIF", raw)
+}
+
+// testMaxBytes checks that decoding file, in the format named format, fails
+// with ErrLimitExceeded when Limits.MaxBytes is below the size of its
+// metadata, and succeeds when it is the size of file.
+func testMaxBytes(t *testing.T, format string, file []byte) {
+	t.Helper()
+	for _, lenient := range []bool{false, true} {
+		dec := &Decoder{Lenient: lenient, Limits: tiff.Limits{MaxBytes: int64(len(file) / 2)}}
+		if _, err := dec.Decode(bytes.NewReader(file)); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%v (lenient %v): got error %v with MaxBytes %d, want ErrLimitExceeded", format, lenient, err, dec.Limits.MaxBytes)
+		}
+		dec.Limits.MaxBytes = int64(len(file))
+		if _, err := dec.Decode(bytes.NewReader(file)); err != nil {
+			t.Errorf("%v (lenient %v): got error %v with MaxBytes %d", format, lenient, err, dec.Limits.MaxBytes)
+		}
+	}
+}
+
+// jxlBox encodes an ISO BMFF box with the given type and payload.
+func jxlBox(typ string, payload []byte) []byte {
+	b := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(b, uint32(8+len(payload)))
+	copy(b[4:], typ)
+	return append(b, payload...)
+}
+
+func TestDecodeJXL(t *testing.T) {
+	rawFile := filepath.Join(*dataDir, "samples", "raw.exif")
+	raw, err := os.ReadFile(rawFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(jxlSignature)
+	buf.Write(jxlBox("ftyp", []byte("jxl \x00\x00\x00\x00jxl ")))
+	buf.Write(jxlBox("jxlc", []byte{0xFF, 0x0A, 0x00, 0x00}))
+	// Exif box payloads start with the offset to the tiff header.
+	buf.Write(jxlBox("Exif", append([]byte{0, 0, 0, 0}, raw[6:]...)))
+	testMaxBytes(t, "JPEG XL", buf.Bytes())
+
+	x, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	tag, err := x.Get(DateTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := tag.String(), `"2018:04:03 07:54:33"`; g != w {
+		t.Errorf("DateTime value = %q; want %q", g, w)
+	}
+
+	var noExif bytes.Buffer
+	noExif.Write(jxlSignature)
+	noExif.Write(jxlBox("jxlc", []byte{0xFF, 0x0A}))
+	if _, err := Decode(&noExif); err == nil {
+		t.Error("no error decoding JPEG XL container without Exif box")
+	}
 }
 
 type walkFunc func(FieldName, *tiff.Tag) error
@@ -220,7 +295,7 @@ func TestHugeTagError(t *testing.T) {
 	}
 }
 
-// Check for a 0-length tag value
+// Check for a tag of data type 0, whose values have no size
 func TestZeroLengthTagError(t *testing.T) {
 	name := filepath.Join(*dataDir, "corrupt/infinite_loop_exif.jpg")
 	f, err := os.Open(name)
@@ -233,7 +308,3447 @@ func TestZeroLengthTagError(t *testing.T) {
 	if err == nil {
 		t.Fatal("no error on bad exif data")
 	}
-	if !strings.Contains(err.Error(), "zero length tag value") {
+	if !strings.Contains(err.Error(), "unknown data type") {
 		t.Fatal("wrong error:", err.Error())
 	}
 }
+
+func TestDecodeCR3(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join(*dataDir, "samples", "raw.exif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Exif IFD tiff data holding ISOSpeedRatings = 100
+	cmt2, _ := hex.DecodeString("4D4D002A00000008" + "0001" + "88270003000000010064000000000000")
+
+	uuid := append(append([]byte{}, cr3UUID...), jxlBox("CMT1", raw[6:])...)
+	uuid = append(uuid, jxlBox("CMT2", cmt2)...)
+	var buf bytes.Buffer
+	buf.Write(jxlBox("ftyp", []byte("crx \x00\x00\x00\x01crx isom")))
+	buf.Write(jxlBox("moov", append(jxlBox("mvhd", make([]byte, 8)), jxlBox("uuid", uuid)...)))
+	buf.Write(jxlBox("mdat", make([]byte, 16)))
+	testMaxBytes(t, "CR3", buf.Bytes())
+
+	x, err := Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if tag, err := x.Get(DateTime); err != nil || tag.String() != `"2018:04:03 07:54:33"` {
+		t.Errorf("DateTime = %v, %v", tag, err)
+	}
+	f, err := x.GetField(ISOSpeedRatings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := f.Tag.Int(0); v != 100 || f.Ifd != IfdExif {
+		t.Errorf("ISOSpeedRatings = %v from %v, want 100 from ExifIFD", v, f.Ifd)
+	}
+
+	var heif bytes.Buffer
+	heif.Write(jxlBox("ftyp", []byte("heic\x00\x00\x00\x00mif1heic")))
+	if _, err := Decode(&heif); err == nil {
+		t.Error("no error decoding unsupported ISO BMFF brand")
+	}
+}
+
+func TestFieldIfd(t *testing.T) {
+	f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	x, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[FieldName]IfdID{
+		Make:                       Ifd0,
+		ThumbJPEGInterchangeFormat: Ifd1,
+		ThumbCompression:           Ifd1,
+		ExposureTime:               IfdExif,
+	} {
+		fld, err := x.GetField(name)
+		if err != nil {
+			t.Errorf("GetField(%v): %v", name, err)
+			continue
+		}
+		if fld.Ifd != want {
+			t.Errorf("field %v: got IFD %v, want %v", name, fld.Ifd, want)
+		}
+	}
+
+	// IFD1 fields must not shadow the IFD0 fields sharing their tag IDs
+	if fld, err := x.GetField(XResolution); err != nil || fld.Ifd != Ifd0 {
+		t.Errorf("GetField(XResolution) = %v, %v; want field from IFD0", fld, err)
+	}
+
+	exifFlds := x.IfdFields(IfdExif)
+	if len(exifFlds) == 0 {
+		t.Fatal("no fields in Exif sub-IFD")
+	}
+	for _, fld := range exifFlds {
+		if fld.Ifd != IfdExif {
+			t.Errorf("IfdFields(IfdExif) returned %v field %v", fld.Ifd, fld.Name)
+		}
+	}
+}
+
+func TestLenientDecode(t *testing.T) {
+	for _, name := range []string{
+		"corrupt/max_uint32_exif.jpg",
+		"corrupt/huge_tag_exif.jpg",
+		"corrupt/infinite_loop_exif.jpg",
+	} {
+		f, err := os.Open(filepath.Join(*dataDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, err := (&Decoder{Lenient: true}).Decode(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%v: lenient decode failed: %v", name, err)
+			continue
+		}
+		if len(x.Warnings()) == 0 {
+			t.Errorf("%v: no warnings for corrupt exif data", name)
+		}
+		if len(x.fields) == 0 {
+			t.Errorf("%v: no fields salvaged from corrupt exif data", name)
+		}
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	for _, lim := range []tiff.Limits{
+		{MaxBytes: 1024},
+		{MaxIfds: 1},
+		{MaxTagsPerIfd: 4},
+		{MaxTagValueSize: 16},
+	} {
+		for _, lenient := range []bool{false, true} {
+			f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = (&Decoder{Lenient: lenient, Limits: lim}).Decode(f)
+			f.Close()
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Errorf("limits %+v (lenient %v): got error %v, want ErrLimitExceeded", lim, lenient, err)
+			}
+		}
+	}
+}
+
+// jpegSeg encodes a JPEG marker segment with the given marker and payload.
+func jpegSeg(marker byte, payload []byte) []byte {
+	b := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(b[2:], uint16(len(payload)+2))
+	return append(b, payload...)
+}
+
+func TestDecodeExifAfterOtherSegments(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join(*dataDir, "samples", "raw.exif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xmp := []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>")
+	var buf bytes.Buffer
+	buf.Write([]byte{0xFF, 0xD8})
+	buf.Write(jpegSeg(0xE0, []byte("JFIF\x00\x01\x02\x00\x00\x01\x00\x01\x00\x00")))
+	buf.Write(jpegSeg(0xE1, xmp))
+	// EXIF in a non-standard APP2 segment, after XMP.
+	buf.Write(jpegSeg(0xE2, raw))
+	buf.Write([]byte{0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9})
+
+	x, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if _, err := x.Get(DateTime); err != nil {
+		t.Error(err)
+	}
+
+	segs := x.Segments()
+	if len(segs) != 2 {
+		t.Fatalf("got %d other segments, want 2", len(segs))
+	}
+	if segs[0].Marker != 0xE0 || segs[0].Offset != 2 {
+		t.Errorf("first segment: got marker %#x at %d, want APP0 at 2", segs[0].Marker, segs[0].Offset)
+	}
+	if segs[1].Marker != 0xE1 || !bytes.Equal(segs[1].Data, xmp) {
+		t.Errorf("second segment: got marker %#x data %q, want XMP APP1", segs[1].Marker, segs[1].Data)
+	}
+}
+
+func TestRawExif(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join(*dataDir, "samples", "raw.exif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var jpeg bytes.Buffer
+	jpeg.Write([]byte{0xFF, 0xD8})
+	jpeg.Write(jpegSeg(0xE1, raw))
+	jpeg.Write([]byte{0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9})
+
+	for _, tt := range []struct {
+		name string
+		data []byte
+	}{
+		{"raw", raw},
+		{"tiff", raw[6:]},
+		{"jpeg", jpeg.Bytes()},
+	} {
+		got, err := RawExif(bytes.NewReader(tt.data))
+		if err != nil {
+			t.Errorf("%v: %v", tt.name, err)
+			continue
+		}
+		if !bytes.Equal(got, raw[6:]) {
+			t.Errorf("%v: got %d bytes, want the %d bytes after the EXIF header", tt.name, len(got), len(raw)-6)
+		}
+	}
+
+	if _, err := RawExif(bytes.NewReader([]byte{0xFF, 0xD8, 0xFF, 0xD9})); err == nil {
+		t.Error("no error for JPEG without EXIF segment")
+	}
+}
+
+func TestEnumFields(t *testing.T) {
+	f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	x, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flash, err := x.Flash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, _ := x.Get(Flash)
+	if v, _ := tag.Int(0); FlashStatus(v) != flash {
+		t.Errorf("Flash() = %v, want %v", flash, v)
+	}
+	if _, err := x.ExposureProgram(); err != nil {
+		t.Error(err)
+	}
+	if _, err := x.MeteringMode(); err != nil {
+		t.Error(err)
+	}
+	if _, err := x.LightSource(); err != nil {
+		t.Error(err)
+	}
+
+	for v, want := range map[fmt.Stringer]string{
+		FlashStatus(0x00):                 "Did not fire",
+		FlashStatus(0x19):                 "Auto, Fired",
+		FlashStatus(0x5f):                 "Auto, Fired, Red-eye reduction, Return detected",
+		FlashStatus(0x20):                 "No flash function",
+		ExposureProgramAperturePriority:   "Aperture priority",
+		MeteringModeCenterWeightedAverage: "Center-weighted average",
+		MeteringModeType(42):              "MeteringModeType(42)",
+		LightSourceD65:                    "D65",
+		LightSourceType(42):               "LightSourceType(42)",
+	} {
+		if got := v.String(); got != want {
+			t.Errorf("%#v.String() = %q, want %q", v, got, want)
+		}
+	}
+}
+
+// exifFromIFD builds an Exif holding the tags of the big-endian encoded IFD h,
+// loaded as fields of the given IFD.
+func exifFromIFD(t *testing.T, ifd IfdID, fieldMap map[uint16]FieldName, h string) *Exif {
+	b, err := hex.DecodeString(strings.Replace(h, " ", "", -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _, err := tiff.DecodeDir(bytes.NewReader(b), binary.BigEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := &Exif{main: map[FieldName]*Field{}, Raw: b}
+	x.LoadIfdTags(ifd, d, fieldMap, false)
+	return x
+}
+
+func TestGPSAccessors(t *testing.T) {
+	f, err := os.Open(filepath.Join(*dataDir, "samples", "has-lens-info.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	x, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alt, ref, err := x.Altitude()
+	if err != nil || alt != 29 || ref != AboveSeaLevel {
+		t.Errorf("Altitude() = %v, %v, %v; want 29, AboveSeaLevel, nil", alt, ref, err)
+	}
+	dir, north, err := x.ImgDirection()
+	if err != nil || math.Abs(dir-18329.0/175) > 1e-9 || north != TrueNorth {
+		t.Errorf("ImgDirection() = %v, %v, %v; want %v, TrueNorth, nil", dir, north, err, 18329.0/175)
+	}
+	if _, err := x.Speed(); !IsTagNotPresentError(err) {
+		t.Errorf("Speed() error = %v, want TagNotPresentError", err)
+	}
+
+	// 10 knots
+	x = exifFromIFD(t, IfdGPS, gpsFields, "0002"+
+		"000C 0002 00000002 4E000000"+
+		"000D 0005 00000001 0000001E"+
+		"00000000"+
+		"0000000A 00000001")
+	speed, err := x.Speed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(speed.Knots()-10) > 1e-9 || math.Abs(speed.KilometersPerHour()-18.52) > 1e-9 {
+		t.Errorf("Speed() = %v km/h, want 10 knots", float64(speed))
+	}
+}
+
+func TestLocalDateTime(t *testing.T) {
+	f, err := os.Open(filepath.Join(*dataDir, "samples", "2012-12-19-21-38-40-sep-temple_square1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	x, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := time.Date(2012, 12, 20, 4, 38, 40, 0, time.UTC)
+
+	// inferred from the GPS time
+	got, err := x.LocalDateTime(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, offset := got.Zone(); !got.Equal(want) || offset != -7*3600 {
+		t.Errorf("LocalDateTime(nil) = %v, want %v at UTC-7", got, want)
+	}
+
+	// from the lookup
+	denver, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		denver = time.FixedZone("MST", -7*3600)
+	}
+	lookup := TimeZoneLookupFunc(func(lat, long float64) (*time.Location, error) {
+		if math.Abs(lat-40.77) > 0.01 || math.Abs(long+111.89) > 0.01 {
+			t.Errorf("lookup called with %v, %v", lat, long)
+		}
+		return denver, nil
+	})
+	got, err = x.LocalDateTime(lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(want) || got.Location() != denver {
+		t.Errorf("LocalDateTime(lookup) = %v, want %v in %v", got, want, denver)
+	}
+}
+
+func TestGetTagByID(t *testing.T) {
+	// IFD0 with Make and an unnamed vendor tag 0x9999
+	x := exifFromIFD(t, Ifd0, exifFields, "0002"+
+		"010F 0002 00000004 41424300"+
+		"9999 0003 00000001 002A0000"+
+		"00000000")
+
+	tag, err := x.GetTagByID(Ifd0, 0x9999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := tag.Int(0); err != nil || v != 42 {
+		t.Errorf("tag 0x9999 = %v, %v; want 42", v, err)
+	}
+	if tag, err := x.GetTagByID(Ifd0, 0x010F); err != nil || tag.String() != `"ABC"` {
+		t.Errorf("tag 0x010f = %v, %v; want \"ABC\"", tag, err)
+	}
+	if _, err := x.GetTagByID(IfdExif, 0x9999); !IsTagNotPresentError(err) {
+		t.Errorf("tag 0x9999 in Exif IFD: got error %v, want TagNotPresentError", err)
+	}
+}
+
+func TestIfdDirs(t *testing.T) {
+	f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	x, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ptr, err := x.Get(ExifIFDPointer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := ptr.Int64(0)
+	dirs := x.IfdDirs(IfdExif)
+	if len(dirs) != 1 {
+		t.Fatalf("got %d Exif IFD dirs, want 1", len(dirs))
+	}
+	if l := dirs[0].Layout; l.Offset != want || l.Length != 2+12*int64(len(dirs[0].Tags))+4 {
+		t.Errorf("Exif IFD layout %+v, want offset %d and %d tags", l, want, len(dirs[0].Tags))
+	}
+}
+
+func TestPatchTag(t *testing.T) {
+	orig, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Decode(bytes.NewReader(orig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.CreateTemp("", "goexif-patch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := f.Write(orig); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := x.PatchInt(f, Orientation, 3); err != nil {
+		t.Fatal(err)
+	}
+	const date = "2020:01:02 03:04:05"
+	if err := x.PatchString(f, DateTime, date); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.PatchString(f, DateTime, date+" too long"); err == nil {
+		t.Error("no error patching a string that does not fit")
+	}
+	if err := x.PatchStringID(f, ExifIFD.DateTimeOriginal, date); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.PatchIntID(f, IFD0.Orientation, 3, 4); err == nil {
+		t.Error("no error patching more values than the tag holds")
+	}
+
+	patched, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patched) != len(orig) {
+		t.Fatalf("patched file is %d bytes, want %d", len(patched), len(orig))
+	}
+	x, err = Decode(bytes.NewReader(patched))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o, _ := x.Get(Orientation); o.String() != "3" {
+		t.Errorf("patched Orientation = %v, want 3", o)
+	}
+	if d, _ := x.Get(DateTime); d.String() != `"`+date+`"` {
+		t.Errorf("patched DateTime = %v, want %q", d, date)
+	}
+	if d, _ := x.GetTag(ExifIFD.DateTimeOriginal); d.String() != `"`+date+`"` {
+		t.Errorf("patched DateTimeOriginal = %v, want %q", d, date)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	x, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := x.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	y := new(Exif)
+	if err := y.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+
+	want, _ := x.MarshalJSON()
+	got, _ := y.MarshalJSON()
+	if !bytes.Equal(got, want) {
+		t.Errorf("restored fields differ:\ngot  %s\nwant %s", got, want)
+	}
+	for _, ifd := range []IfdID{Ifd0, Ifd1, IfdExif, IfdGPS, IfdInterop} {
+		xf, yf := x.IfdFields(ifd), y.IfdFields(ifd)
+		if len(xf) != len(yf) {
+			t.Errorf("%v: got %d fields, want %d", ifd, len(yf), len(xf))
+			continue
+		}
+		for i := range xf {
+			if xf[i].Name != yf[i].Name || xf[i].Tag.ValOffset != yf[i].Tag.ValOffset {
+				t.Errorf("%v field %d: got %v, want %v", ifd, i, yf[i].Name, xf[i].Name)
+			}
+		}
+	}
+	if got, err := y.GetTagByID(IfdExif, 0x9003); err != nil || got != y.main[DateTimeOriginal].Tag {
+		t.Errorf("restored fields do not share tags with their IFDs: %v", err)
+	}
+	xOff, _ := x.TiffOffset()
+	if yOff, ok := y.TiffOffset(); !ok || yOff != xOff {
+		t.Errorf("TiffOffset: got %v, %v, want %v", yOff, ok, xOff)
+	}
+	xt, _ := x.JpegThumbnail()
+	yt, _ := y.JpegThumbnail()
+	if !bytes.Equal(xt, yt) {
+		t.Errorf("restored thumbnail differs")
+	}
+
+	for n := 0; n < len(data); n += 7 {
+		if err := new(Exif).UnmarshalBinary(data[:n]); err == nil {
+			t.Errorf("no error unmarshaling %d of %d bytes", n, len(data))
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	for _, m := range []map[uint16]FieldName{exifFields, thumbnailFields, gpsFields, interopFields} {
+		for _, name := range m {
+			if d, ok := Describe(name); !ok || d.Title == "" || d.Text == "" {
+				t.Errorf("no description for %v", name)
+			}
+		}
+	}
+
+	RegisterTranslations("de", map[FieldName]Description{
+		ExposureTime: {"Belichtungszeit", "Zeit, in der der Verschluss geöffnet war.", "Sekunden"},
+	})
+	if d, _ := DescribeIn("de-AT", ExposureTime); d.Title != "Belichtungszeit" {
+		t.Errorf("de-AT ExposureTime title = %q, want German translation", d.Title)
+	}
+	if d, _ := DescribeIn("de", FNumber); d.Title != "F-number" {
+		t.Errorf("untranslated de FNumber title = %q, want English fallback", d.Title)
+	}
+	if _, ok := DescribeIn("fr", UnknownPrefix+"9999"); ok {
+		t.Error("got description for unknown tag")
+	}
+}
+
+func TestMarshalExifTool(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join(*dataDir, "samples", "raw.exif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Decode(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	b, err := x.MarshalExifTool("raw.exif")
+	if err != nil {
+		t.Fatalf("MarshalExifTool: %v", err)
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d objects, want 1", len(got))
+	}
+
+	want := map[string]interface{}{
+		"SourceFile":              "raw.exif",
+		"Model":                   "iPhone 7",
+		"ModifyDate":              "2018:04:03 07:54:33",
+		"ISO":                     80.0,
+		"ExposureTime":            "1/15",
+		"FNumber":                 1.8,
+		"FocalLength":             "4.0 mm",
+		"FocalLengthIn35mmFormat": "28 mm",
+		"Flash":                   "Off, Did not fire",
+		"MeteringMode":            "Multi-segment",
+		"ExifImageWidth":          4032.0,
+		"ComponentsConfiguration": "Y, Cb, Cr, -",
+	}
+	for k, w := range want {
+		if g := got[0][k]; g != w {
+			t.Errorf("%s = %#v, want %#v", k, g, w)
+		}
+	}
+	for _, k := range []string{"ExifIFDPointer", "MakerNote", "ISOSpeedRatings"} {
+		if _, ok := got[0][k]; ok {
+			t.Errorf("unexpected key %s", k)
+		}
+	}
+}
+
+func TestExifToolValues(t *testing.T) {
+	tests := []struct {
+		got  interface{}
+		want interface{}
+	}{
+		{exposureTimeString(1.0 / 250), "1/250"},
+		{exposureTimeString(2), json.Number("2")},
+		{exposureTimeString(0.5), json.Number("0.5")},
+		{fractionString(-2, 3), "-2/3"},
+		{fractionString(1, 2), "+1/2"},
+		{fractionString(10, 10), "+1"},
+		{fractionString(0, 1), json.Number("0")},
+		{secondsString(42.79), "42.79"},
+		{secondsString(7), "07"},
+	}
+	for i, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%d: got %#v, want %#v", i, test.got, test.want)
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	// tiffData returns tiff data with the given IFD0 tags (all shorts)
+	tiffData := func(tags map[uint16]int) []byte {
+		d := tiff.NewDir()
+		for id, v := range tags {
+			tag, err := tiff.NewTag(id, tiff.DTShort, v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.Tags = append(d.Tags, tag)
+		}
+		var buf bytes.Buffer
+		if err := tiff.NewTiff(binary.LittleEndian, d).Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	exifData := tiffData(map[uint16]int{0x0112: 6})
+	segment := func(marker byte, data []byte) []byte {
+		b := []byte{0xFF, marker, 0, 0}
+		binary.BigEndian.PutUint16(b[2:], uint16(len(data)+2))
+		return append(b, data...)
+	}
+	chunk := func(typ string, data []byte) []byte {
+		b := make([]byte, 8, 12+len(data))
+		binary.BigEndian.PutUint32(b, uint32(len(data)))
+		copy(b[4:], typ)
+		return append(append(b, data...), 0, 0, 0, 0) // CRC is not checked
+	}
+	riff := func(chunks ...[]byte) []byte {
+		body := []byte("WEBP")
+		for _, c := range chunks {
+			body = append(body, c...)
+		}
+		b := []byte("RIFF\x00\x00\x00\x00")
+		binary.LittleEndian.PutUint32(b[4:], uint32(len(body)))
+		return append(b, body...)
+	}
+	riffChunk := func(typ string, data []byte) []byte {
+		b := append([]byte(typ), 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(b[4:], uint32(len(data)))
+		b = append(b, data...)
+		if len(data)%2 == 1 {
+			b = append(b, 0)
+		}
+		return b
+	}
+	fullBox := func(typ string, payload []byte) []byte {
+		return jxlBox(typ, append([]byte{0, 0, 0, 0}, payload...))
+	}
+
+	jpeg := append([]byte{0xFF, 0xD8}, segment(0xE0, []byte("JFIF\x00\x01\x02"))...)
+	jpeg = append(jpeg, segment(0xE1, append([]byte("Exif\x00\x00"), exifData...))...)
+	jpeg = append(jpeg, segment(0xC0, []byte{8, 0x01, 0xE0, 0x02, 0x80, 3})...)
+	jpeg = append(jpeg, 0xFF, 0xDA)
+
+	png := []byte("\x89PNG\r\n\x1a\n")
+	png = append(png, chunk("IHDR", []byte{0, 0, 3, 0, 0, 0, 2, 0, 8, 2, 0, 0, 0})...)
+	png = append(png, chunk("eXIf", tiffData(map[uint16]int{0x0112: 3}))...)
+	png = append(png, chunk("IDAT", nil)...)
+
+	vp8x := []byte{0x08, 0, 0, 0, 0x7F, 0x07, 0, 0x37, 0x04, 0}
+	webp := riff(riffChunk("VP8X", vp8x), riffChunk("VP8 ", make([]byte, 10)),
+		riffChunk("EXIF", tiffData(map[uint16]int{0x0112: 8})))
+	// VP8L header of a 100x50 image
+	bits := uint32(99) | uint32(49)<<14
+	vp8l := []byte{0x2F, byte(bits), byte(bits >> 8), byte(bits >> 16), byte(bits >> 24)}
+	webpLossless := riff(riffChunk("VP8L", vp8l))
+
+	ispe := fullBox("ispe", []byte{0, 0, 0x0F, 0xC0, 0, 0, 0x0B, 0xD0})
+	irot := jxlBox("irot", []byte{3})
+	ipco := jxlBox("ipco", append(ispe, irot...))
+	// item 1 is associated with properties 1 and 2
+	ipma := fullBox("ipma", []byte{0, 0, 0, 1, 0, 1, 2, 0x81, 0x82})
+	meta := fullBox("meta", append(fullBox("pitm", []byte{0, 1}), jxlBox("iprp", append(ipco, ipma...))...))
+	heic := append(jxlBox("ftyp", []byte("heic\x00\x00\x00\x00mif1heic")), meta...)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		format Format
+		w, h   int
+		orient int
+	}{
+		{"jpeg", jpeg, FormatJPEG, 640, 480, 6},
+		{"tiff", tiffData(map[uint16]int{0x0100: 20, 0x0101: 10, 0x0112: 5}), FormatTIFF, 20, 10, 5},
+		{"png", png, FormatPNG, 768, 512, 3},
+		{"webp", webp, FormatWebP, 1920, 1080, 8},
+		{"webp lossless", webpLossless, FormatWebP, 100, 50, 1},
+		{"heic", heic, FormatHEIC, 4032, 3024, 6},
+	}
+	for _, tt := range tests {
+		f, w, h, o, err := Probe(bytes.NewReader(tt.data))
+		if err != nil {
+			t.Errorf("%v: %v", tt.name, err)
+			continue
+		}
+		if f != tt.format || w != tt.w || h != tt.h || o != tt.orient {
+			t.Errorf("%v: got %v %dx%d orientation %d, want %v %dx%d orientation %d",
+				tt.name, f, w, h, o, tt.format, tt.w, tt.h, tt.orient)
+		}
+	}
+
+	f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if format, w, h, _, err := Probe(f); err != nil || format != FormatJPEG || w == 0 || h == 0 {
+		t.Errorf("sample1.jpg: got %v %dx%d, %v", format, w, h, err)
+	}
+	if _, _, _, _, err := Probe(strings.NewReader("GIF89a")); err != ErrUnknownFormat {
+		t.Errorf("GIF: got error %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestDecodeMultiSegment(t *testing.T) {
+	makeTag, err := tiff.NewTag(0x010F, tiff.DTAscii, "Scanner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// too large for a single JPEG segment
+	blobTag, err := tiff.NewTag(0xC000, tiff.DTUndefined, bytes.Repeat([]byte{0xAB}, 100000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tif bytes.Buffer
+	if err := tiff.NewTiff(binary.BigEndian, tiff.NewDir(makeTag, blobTag)).Encode(&tif); err != nil {
+		t.Fatal(err)
+	}
+
+	jpeg := []byte{0xFF, 0xD8}
+	data := tif.Bytes()
+	for len(data) > 0 {
+		n := len(data)
+		if n > 60000 {
+			n = 60000
+		}
+		payload := append(append([]byte(nil), exifHeader...), data[:n]...)
+		jpeg = append(jpeg, 0xFF, jpeg_APP1, byte((len(payload)+2)>>8), byte(len(payload)+2))
+		jpeg = append(jpeg, payload...)
+		data = data[n:]
+	}
+	jpeg = append(jpeg, 0xFF, jpeg_SOS)
+
+	x, err := Decode(bytes.NewReader(jpeg))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !bytes.Equal(x.Raw, tif.Bytes()) {
+		t.Errorf("got %d bytes of tiff data, want %d", len(x.Raw), tif.Len())
+	}
+	if tag, err := x.Get(Make); err != nil || tag.String() != `"Scanner"` {
+		t.Errorf("Make = %v, %v", tag, err)
+	}
+	if len(x.Segments()) != 0 {
+		t.Errorf("continuation segments returned as other segments: %d", len(x.Segments()))
+	}
+	if _, ok := x.TiffOffset(); ok {
+		t.Error("got tiff offset for non-contiguous EXIF data")
+	}
+	if _, err := (&Decoder{Limits: tiff.Limits{MaxBytes: 80000}}).Decode(bytes.NewReader(jpeg)); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("got error %v, want ErrLimitExceeded", err)
+	}
+}
+
+func TestDecodeFS(t *testing.T) {
+	x, err := DecodeFS(os.DirFS(*dataDir), "sample1.jpg")
+	if err != nil {
+		t.Fatalf("DecodeFS: %v", err)
+	}
+	if _, err := x.Get(Make); err != nil {
+		t.Errorf("Make: %v", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(*dataDir, "samples", "raw.exif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{"testdata/raw.exif": {Data: raw}}
+	if _, err := (&Decoder{Lenient: true}).DecodeFS(fsys, "testdata/raw.exif"); err != nil {
+		t.Errorf("DecodeFS from MapFS: %v", err)
+	}
+	if _, err := DecodeFS(fsys, "missing.jpg"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got error %v, want fs.ErrNotExist", err)
+	}
+}
+
+// maxReaderAt records the end of the furthest read from an io.ReaderAt and
+// the number of bytes read.
+type maxReaderAt struct {
+	r    *bytes.Reader
+	max  int64
+	read int64
+}
+
+func (m *maxReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := m.r.ReadAt(p, off)
+	if end := off + int64(n); end > m.max {
+		m.max = end
+	}
+	m.read += int64(n)
+	return n, err
+}
+
+func TestDecodeReaderAt(t *testing.T) {
+	// IFD0 is stored after the image data and the Exif IFD, pointing back
+	// to both, and more image data follows it.
+	le := binary.LittleEndian
+	data := []byte("II*\x00\x00\x00\x00\x00")
+	data = append(data, "CameraMake\x00\x00"...) // at 8
+	data = append(data, bytes.Repeat([]byte{0xAA}, 1000)...)
+	exifIfd := int64(len(data))
+	data = le.AppendUint16(data, 1)
+	data = le.AppendUint16(data, 0x9003)
+	data = le.AppendUint16(data, 2)
+	data = le.AppendUint32(data, 20)
+	data = le.AppendUint32(data, uint32(exifIfd+18))
+	data = le.AppendUint32(data, 0)
+	data = append(data, "2020:01:02 03:04:05\x00"...)
+	ifd0 := int64(len(data))
+	le.PutUint32(data[4:], uint32(ifd0))
+	data = le.AppendUint16(data, 2)
+	data = le.AppendUint16(data, 0x010F)
+	data = le.AppendUint16(data, 2)
+	data = le.AppendUint32(data, 11)
+	data = le.AppendUint32(data, 8)
+	data = le.AppendUint16(data, exifPointer)
+	data = le.AppendUint16(data, 4)
+	data = le.AppendUint32(data, 1)
+	data = le.AppendUint32(data, uint32(exifIfd))
+	data = le.AppendUint32(data, 0)
+	end := int64(len(data))
+	data = append(data, make([]byte, 5000)...)
+
+	r := &maxReaderAt{r: bytes.NewReader(data)}
+	x, err := DecodeReaderAt(r, int64(len(data)))
+	if err != nil {
+		t.Fatalf("DecodeReaderAt: %v", err)
+	}
+	if r.max != end {
+		t.Errorf("read up to offset %d, want %d", r.max, end)
+	}
+	// the header, the two IFDs and the values of Make and DateTimeOriginal,
+	// but none of the 1000 bytes of image data before IFD0
+	if meta := int64(8 + 30 + 18 + 11 + 20); r.read > 2*meta {
+		t.Errorf("read %d bytes, want only about the %d bytes of metadata", r.read, meta)
+	}
+	if tag, _ := x.Get(Make); !bytes.Equal(x.Raw[8:8+11], tag.Val) || x.Raw[100] != 0 {
+		t.Errorf("Raw does not hold the metadata at its offsets")
+	}
+	for name, want := range map[FieldName]string{Make: "CameraMake", DateTimeOriginal: "2020:01:02 03:04:05"} {
+		tag, err := x.Get(name)
+		if err != nil {
+			t.Errorf("%v: %v", name, err)
+			continue
+		}
+		if got, _ := tag.StringVal(); got != want {
+			t.Errorf("%v = %q, want %q", name, got, want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "backwards.tif")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	y, err := DecodeFile(path)
+	if err != nil {
+		t.Fatalf("DecodeFile: %v", err)
+	}
+	if int64(len(y.Raw)) != end {
+		t.Errorf("DecodeFile read %d bytes of tiff data, want %d", len(y.Raw), end)
+	}
+	if _, err := y.Get(DateTimeOriginal); err != nil {
+		t.Errorf("DecodeFile: %v", err)
+	}
+	if _, err := DecodeFile(filepath.Join(t.TempDir(), "missing.tif")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got error %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestDecodeORF(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join(*dataDir, "samples", "raw.exif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orf := append([]byte(nil), raw[len("Exif\x00\x00"):]...)
+	if string(orf[:2]) == "II" {
+		copy(orf[2:], "RO")
+	} else {
+		copy(orf[2:], "OR")
+	}
+	if _, err := Decode(bytes.NewReader(orf)); err == nil {
+		t.Error("ORF magic accepted by default")
+	}
+	x, err := (&Decoder{Magic: tiff.MagicVariants}).Decode(bytes.NewReader(orf))
+	if err != nil {
+		t.Fatalf("Decode with MagicVariants: %v", err)
+	}
+	if _, err := x.Get(Make); err != nil {
+		t.Errorf("Make: %v", err)
+	}
+}
+
+func TestDecodeHook(t *testing.T) {
+	f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	dirs := map[string]int{}
+	var model []byte
+	dec := &Decoder{Hook: func(rt tiff.RawTag) bool {
+		dirs[rt.Dir]++
+		if rt.Dir == "IFD0" && rt.ID == 0x0110 {
+			model = rt.Val
+			return false
+		}
+		return true
+	}}
+	x, err := dec.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"IFD0", "IFD1", "ExifIFD"} {
+		if dirs[dir] == 0 {
+			t.Errorf("hook not called for tags of %v: %v", dir, dirs)
+		}
+	}
+	if string(model) != "NIKON D2H\x00" {
+		t.Errorf("hook captured Model %q", model)
+	}
+	if _, err := x.Get(Model); !IsTagNotPresentError(err) {
+		t.Errorf("vetoed Model: got error %v, want TagNotPresentError", err)
+	}
+	if _, err := x.Get(Make); err != nil {
+		t.Errorf("Make: %v", err)
+	}
+}
+
+func TestDecodeTrace(t *testing.T) {
+	f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var events []tiff.TraceEvent
+	dec := &Decoder{Trace: func(ev tiff.TraceEvent) { events = append(events, ev) }}
+	x, err := dec.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exifSeg bool
+	dirs := map[string]bool{}
+	tags := 0
+	for _, ev := range events {
+		switch ev.Kind {
+		case tiff.TraceSegment:
+			exifSeg = exifSeg || ev.Msg == `APP1 "Exif"`
+		case tiff.TraceIFD:
+			dirs[ev.Dir] = true
+		case tiff.TraceTag:
+			if ev.Tag != nil {
+				tags++
+			}
+		}
+	}
+	if !exifSeg {
+		t.Error("no trace event for the Exif segment")
+	}
+	for _, d := range []string{"IFD0", "IFD1", "ExifIFD"} {
+		if !dirs[d] {
+			t.Errorf("no trace event for %v, got %v", d, dirs)
+		}
+	}
+	if want := len(x.Tiff.Dirs[0].Tags); tags < want {
+		t.Errorf("got %d tag events, want at least %d", tags, want)
+	}
+}
+
+func TestDuplicatePolicy(t *testing.T) {
+	tag := func(id uint16, val string) *tiff.Tag {
+		tg, err := tiff.NewTag(id, tiff.DTAscii, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tg
+	}
+	ifd0 := tiff.NewDir(tag(0x010F, "First"), tag(0x010F, "Second"), tag(0x0132, "2001:01:01 00:00:00"))
+	ifd0.SubDirs = map[uint16]*tiff.Dir{0x8769: tiff.NewDir(tag(0x0132, "2002:02:02 00:00:00"))}
+	var buf bytes.Buffer
+	if err := tiff.NewTiff(binary.LittleEndian, ifd0).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		policy DuplicatePolicy
+		want   map[FieldName]string
+	}{
+		{LastWins, map[FieldName]string{Make: `"Second"`, DateTime: `"2002:02:02 00:00:00"`}},
+		{FirstWins, map[FieldName]string{Make: `"First"`, DateTime: `"2001:01:01 00:00:00"`}},
+		{KeepAll, map[FieldName]string{
+			Make:               `"First"`,
+			"IFD0:Make":        `"Second"`,
+			DateTime:           `"2001:01:01 00:00:00"`,
+			"ExifIFD:DateTime": `"2002:02:02 00:00:00"`,
+		}},
+	}
+	for _, test := range tests {
+		dec := &Decoder{Duplicates: test.policy}
+		x, err := dec.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("policy %d: %v", test.policy, err)
+		}
+		for name, want := range test.want {
+			if tg, err := x.Get(name); err != nil || tg.String() != want {
+				t.Errorf("policy %d: %v = %v, %v, want %v", test.policy, name, tg, err, want)
+			}
+		}
+		// both Makes, DateTime and ExifIFDPointer
+		if n := len(x.IfdFields(Ifd0)); n != 4 {
+			t.Errorf("policy %d: got %d IFD0 fields, want 4", test.policy, n)
+		}
+
+		data, err := x.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		y := new(Exif)
+		if err := y.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		for name, want := range test.want {
+			if tg, err := y.Get(name); err != nil || tg.String() != want {
+				t.Errorf("policy %d: restored %v = %v, %v, want %v", test.policy, name, tg, err, want)
+			}
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	makeTag, err := tiff.NewTag(0x010F, tiff.DTAscii, "Scanner Co")
+	if err != nil {
+		t.Fatal(err)
+	}
+	artistTag, err := tiff.NewTag(0x013B, tiff.DTAscii, "Someone Else")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tiff.NewTiff(binary.LittleEndian, tiff.NewDir(makeTag, artistTag)).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	good := buf.Bytes()
+
+	x, err := (&Decoder{Verify: true}).Decode(bytes.NewReader(good))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := x.Verify(); len(errs) != 0 || len(x.Warnings()) != 0 {
+		t.Errorf("problems found in valid data: %v", errs)
+	}
+
+	// the value offsets of the 1st and 2nd entries of IFD0 at offset 8
+	makeOff, artistOff := 8+2+8, 8+2+12+8
+	for _, test := range []struct {
+		patch   func(b []byte)
+		overlap string
+	}{
+		{func(b []byte) { copy(b[artistOff:], b[makeOff:makeOff+4]) }, "IFD0 tag 0x013b value"},
+		{func(b []byte) { binary.LittleEndian.PutUint32(b[makeOff:], 8) }, "IFD0 tag 0x010f value"},
+	} {
+		bad := append([]byte(nil), good...)
+		test.patch(bad)
+		x, err := (&Decoder{Verify: true}).Decode(bytes.NewReader(bad))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(x.Warnings()) != 1 || !strings.Contains(x.Warnings()[0].Error(), test.overlap+" at") {
+			t.Errorf("got warnings %v, want an overlap of %v", x.Warnings(), test.overlap)
+		}
+	}
+}
+
+func TestDecodeX3F(t *testing.T) {
+	makeTag, err := tiff.NewTag(0x010F, tiff.DTAscii, "SIGMA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tif bytes.Buffer
+	if err := tiff.NewTiff(binary.LittleEndian, tiff.NewDir(makeTag)).Encode(&tif); err != nil {
+		t.Fatal(err)
+	}
+	app1 := append(append([]byte(nil), exifHeader...), tif.Bytes()...)
+	jpg := append([]byte{0xFF, 0xD8, 0xFF, jpeg_APP1, byte((len(app1) + 2) >> 8), byte(len(app1) + 2)}, app1...)
+	jpg = append(jpg, 0xFF, jpeg_EOI)
+
+	u32 := func(b []byte, vs ...uint32) []byte {
+		for _, v := range vs {
+			b = binary.LittleEndian.AppendUint32(b, v)
+		}
+		return b
+	}
+	// image section: type 2 (processed for preview), format 18 (JPEG)
+	img := u32([]byte("SECi"), 0x00020000, 2, 18, 640, 480, 0)
+	img = append(img, jpg...)
+	// property section: CAMMODEL=DP2
+	var chars []byte
+	for _, c := range "CAMMODEL\x00DP2\x00" {
+		chars = binary.LittleEndian.AppendUint16(chars, uint16(c))
+	}
+	prop := u32([]byte("SECp"), 0x00020000, 1, 0, 0, uint32(len(chars)/2), 0, 9)
+	prop = append(prop, chars...)
+
+	file := u32([]byte("FOVb"), 0x00020003)
+	file = append(file, make([]byte, 32)...)
+	imgOff, propOff := len(file), len(file)+len(img)
+	file = append(append(file, img...), prop...)
+	dirOff := len(file)
+	file = u32(append(file, "SECd"...), 0x00020000, 2)
+	file = append(u32(file, uint32(imgOff), uint32(len(img))), "IMA2"...)
+	file = append(u32(file, uint32(propOff), uint32(len(prop))), "PROP"...)
+	file = u32(file, uint32(dirOff))
+	testMaxBytes(t, "X3F", file)
+
+	x, err := Decode(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag, err := x.Get(Make); err != nil || tag.String() != `"SIGMA"` {
+		t.Errorf("Make = %v, %v", tag, err)
+	}
+	if off, ok := x.TiffOffset(); !ok || !bytes.Equal(file[off:off+int64(tif.Len())], tif.Bytes()) {
+		t.Errorf("TiffOffset = %v, %v", off, ok)
+	}
+
+	props, err := X3FProperties(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(props) != 1 || props["CAMMODEL"] != "DP2" {
+		t.Errorf("got properties %v", props)
+	}
+	if _, err := X3FProperties(bytes.NewReader(file[:len(file)-4])); err == nil {
+		t.Error("no error for X3F file without section directory")
+	}
+}
+
+func TestSubIFDs(t *testing.T) {
+	tag := func(id uint16, typ tiff.DataType, val interface{}) *tiff.Tag {
+		tg, err := tiff.NewTag(id, typ, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tg
+	}
+	ifd0 := tiff.NewDir(tag(0x0100, tiff.DTLong, 160), tag(0x010F, tiff.DTAscii, "Hasselblad"))
+	ifd0.SubDirs = map[uint16]*tiff.Dir{0x014A: tiff.NewDir(tag(0x0100, tiff.DTLong, 11656))}
+	var buf bytes.Buffer
+	if err := tiff.NewTiff(binary.LittleEndian, ifd0).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := x.Get(ImageWidth); err != nil || got.String() != "160" {
+		t.Errorf("ImageWidth = %v, %v, want the width of IFD0", got, err)
+	}
+	fs := x.IfdFields(IfdSubIFD)
+	if len(fs) != 1 || fs[0].Name != ImageWidth || fs[0].Tag.String() != "11656" {
+		t.Errorf("got SubIFD fields %v", fs)
+	}
+	if len(x.IfdDirs(IfdSubIFD)) != 1 {
+		t.Errorf("got %d SubIFDs, want 1", len(x.IfdDirs(IfdSubIFD)))
+	}
+}
+
+func TestMakerNote(t *testing.T) {
+	f, err := os.Open(filepath.Join(*dataDir, "samples", "2006-08-03-16-29-38-sep-2006-08-03-16-29-38a.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	x, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, base, order, err := x.MakerNote()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 || !bytes.Equal(x.Raw[base:base+int64(len(data))], data) {
+		t.Errorf("maker note not found at offset %d of the tiff data", base)
+	}
+	if order != x.Tiff.Order {
+		t.Errorf("got byte order %v, want %v", order, x.Tiff.Order)
+	}
+
+	makeTag, err := tiff.NewTag(0x010F, tiff.DTAscii, "Scanner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tiff.NewTiff(binary.LittleEndian, tiff.NewDir(makeTag)).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if x, err = Decode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := x.MakerNote(); !IsTagNotPresentError(err) {
+		t.Errorf("got error %v, want TagNotPresentError", err)
+	}
+}
+
+func TestGPSDateTime(t *testing.T) {
+	for _, tt := range []struct {
+		date string
+		hms  [][2]int64
+		want string
+	}{
+		{"2021:06:15", [][2]int64{{14, 1}, {30, 1}, {4513, 100}}, "2021-06-15T14:30:45.13Z"},
+		{"2021-06-15", [][2]int64{{14, 1}, {3059, 100}, {0, 1}}, "2021-06-15T14:30:35.4Z"},
+		{"2021:06:15", [][2]int64{{23, 1}, {59, 1}, {59999, 1000}}, "2021-06-15T23:59:59.999Z"},
+	} {
+		dateTag, err := tiff.NewTag(0x1D, tiff.DTAscii, tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		timeTag, err := tiff.NewTag(0x7, tiff.DTRational, tt.hms)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ifd0 := tiff.NewDir()
+		ifd0.SubDirs = map[uint16]*tiff.Dir{0x8825: tiff.NewDir(timeTag, dateTag)}
+		var buf bytes.Buffer
+		if err := tiff.NewTiff(binary.BigEndian, ifd0).Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		x, err := Decode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := x.GPSDateTime()
+		if err != nil || got.Format(time.RFC3339Nano) != tt.want {
+			t.Errorf("GPSDateTime() = %v, %v; want %v", got.Format(time.RFC3339Nano), err, tt.want)
+		}
+	}
+
+	x := exifFromIFD(t, IfdGPS, gpsFields, "0000 00000000")
+	if _, err := x.GPSDateTime(); !IsTagNotPresentError(err) {
+		t.Errorf("GPSDateTime() error = %v, want TagNotPresentError", err)
+	}
+}
+
+func TestTagIDNamespaces(t *testing.T) {
+	spaces := []struct {
+		ns     interface{}
+		ifd    IfdID
+		prefix string
+		table  map[uint16]FieldName
+	}{
+		{IFD0, Ifd0, "", exifFields},
+		{ExifIFD, IfdExif, "", exifFields},
+		{IFD1, Ifd1, "Thumb", thumbnailFields},
+		{GPS, IfdGPS, "GPS", gpsFields},
+		{Interop, IfdInterop, "Interoperability", interopFields},
+	}
+	seen := map[IfdID]map[uint16]bool{}
+	for _, sp := range spaces {
+		v := reflect.ValueOf(sp.ns)
+		for i := 0; i < v.NumField(); i++ {
+			id := v.Field(i).Interface().(TagID)
+			want := FieldName(sp.prefix + v.Type().Field(i).Name)
+			if id.Ifd != sp.ifd || id.Name() != want {
+				t.Errorf("%v.%v = %v (%v), want a %v tag named %v", sp.ifd, v.Type().Field(i).Name, id, id.Name(), sp.ifd, want)
+			}
+			if seen[id.Ifd] == nil {
+				seen[id.Ifd] = map[uint16]bool{}
+			}
+			seen[id.Ifd][id.ID] = true
+		}
+	}
+	for _, sp := range spaces {
+		for id, name := range sp.table {
+			if !seen[Ifd0][id] && !seen[IfdExif][id] && !seen[sp.ifd][id] {
+				t.Errorf("field %v (0x%04x) is missing from the %v namespace", name, id, sp.ifd)
+			}
+		}
+	}
+
+	f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	x, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := x.GetTag(IFD0.Make)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := x.Get(Make); tag != want {
+		t.Errorf("GetTag(IFD0.Make) = %v, want %v", tag, want)
+	}
+	if _, err := x.GetTag(Interop.Index); !IsTagNotPresentError(err) {
+		t.Errorf("GetTag(Interop.Index) err = %v, want TagNotPresentError", err)
+	}
+	if got, want := ExifIFD.DateTimeOriginal.String(), "ExifIFD:0x9003"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	x, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		b.Fatal(err)
+	}
+	names := []FieldName{Make, Model, DateTimeOriginal, Orientation, GPSLatitude}
+	ids := []TagID{IFD0.Make, IFD0.Model, ExifIFD.DateTimeOriginal, IFD0.Orientation, GPS.Latitude}
+	b.Run("Name", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, name := range names {
+				if _, err := x.Get(name); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("TagID", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, id := range ids {
+				if _, err := x.GetTag(id); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+func TestOrient(t *testing.T) {
+	// a 3x2 image with distinct pixels
+	src := image.NewGray(image.Rect(0, 0, 3, 2))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i)
+	}
+	want := map[int][]uint8{
+		1: {0, 1, 2, 3, 4, 5},
+		2: {2, 1, 0, 5, 4, 3},
+		3: {5, 4, 3, 2, 1, 0},
+		4: {3, 4, 5, 0, 1, 2},
+		5: {0, 3, 1, 4, 2, 5},
+		6: {3, 0, 4, 1, 5, 2},
+		7: {5, 2, 4, 1, 3, 0},
+		8: {2, 5, 1, 4, 0, 3},
+	}
+	for o, pix := range want {
+		got := orient(src, o).(*image.Gray)
+		if !bytes.Equal(got.Pix, pix) {
+			t.Errorf("orientation %d: pixels = %v, want %v", o, got.Pix, pix)
+		}
+	}
+
+	// the fast paths copying pixel bytes, for 4x2 images
+	r := image.Rect(0, 0, 4, 2)
+	rgba, nrgba := image.NewRGBA(image.Rect(0, 0, 6, 3)), image.NewNRGBA(r)
+	for i := range rgba.Pix {
+		rgba.Pix[i] = uint8(i)
+	}
+	for i := range nrgba.Pix {
+		nrgba.Pix[i] = uint8(i)
+	}
+	images := []image.Image{rgba.SubImage(image.Rect(1, 1, 5, 3)), nrgba}
+	for _, ratio := range []image.YCbCrSubsampleRatio{image.YCbCrSubsampleRatio444, image.YCbCrSubsampleRatio422, image.YCbCrSubsampleRatio420} {
+		ycc := image.NewYCbCr(r, ratio)
+		for i := range ycc.Y {
+			ycc.Y[i] = uint8(i)
+		}
+		for i := range ycc.Cb {
+			ycc.Cb[i], ycc.Cr[i] = uint8(100+i), uint8(200+i)
+		}
+		images = append(images, ycc)
+	}
+	for _, img := range images {
+		b := img.Bounds()
+		for o := 2; o <= 8; o++ {
+			got := orient(img, o)
+			if fmt.Sprintf("%T", got) != fmt.Sprintf("%T", img) {
+				t.Errorf("%T, orientation %d: got a %T", img, o, got)
+				continue
+			}
+			for y := 0; y < b.Dy(); y++ {
+				for x := 0; x < b.Dx(); x++ {
+					dx, dy := orientPoint(x, y, b.Dx(), b.Dy(), o)
+					if c, want := got.At(dx, dy), img.At(b.Min.X+x, b.Min.Y+y); c != want {
+						t.Errorf("%T, orientation %d: pixel (%d, %d) = %v, want %v", img, o, x, y, c, want)
+					}
+				}
+			}
+		}
+	}
+
+	// a JPEG with an EXIF block holding orientation 6
+	var img bytes.Buffer
+	if err := jpeg.Encode(&img, src, nil); err != nil {
+		t.Fatal(err)
+	}
+	orientation, _ := tiff.NewTag(0x0112, tiff.DTShort, 6)
+	var app1 bytes.Buffer
+	app1.WriteString("Exif\x00\x00")
+	if err := tiff.NewTiff(binary.BigEndian, tiff.NewDir(orientation)).Encode(&app1); err != nil {
+		t.Fatal(err)
+	}
+	var data bytes.Buffer
+	data.Write([]byte{0xFF, 0xD8})
+	data.Write(jpegSeg(0xE1, app1.Bytes()))
+	data.Write(img.Bytes()[2:])
+	upright, x, err := DecodeImageOriented(bytes.NewReader(data.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x == nil {
+		t.Fatal("no EXIF data returned")
+	}
+	if b := upright.Bounds(); b.Dx() != 2 || b.Dy() != 3 {
+		t.Errorf("upright bounds = %v, want 2x3", b)
+	}
+
+	// without EXIF data the image is returned as stored
+	upright, x, err = DecodeImageOriented(bytes.NewReader(img.Bytes()))
+	if err != nil || x != nil {
+		t.Fatalf("DecodeImageOriented without EXIF = %v, %v", x, err)
+	}
+	if b := upright.Bounds(); b.Dx() != 3 || b.Dy() != 2 {
+		t.Errorf("bounds = %v, want 3x2", b)
+	}
+}
+
+func exifFromTags(t *testing.T, ifd IfdID, tags ...*tiff.Tag) *Exif {
+	x := &Exif{main: map[FieldName]*Field{}}
+	x.LoadIfdTags(ifd, tiff.NewDir(tags...), exifFields, false)
+	return x
+}
+
+func mustTag(t *testing.T, id uint16, typ tiff.DataType, val interface{}) *tiff.Tag {
+	tag, err := tiff.NewTag(id, typ, val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tag
+}
+
+func TestDiff(t *testing.T) {
+	a := exifFromTags(t, Ifd0,
+		mustTag(t, 0x010F, tiff.DTAscii, "Canon"),
+		mustTag(t, 0x0112, tiff.DTShort, 1),
+		mustTag(t, 0x0131, tiff.DTAscii, "GIMP"))
+	b := exifFromTags(t, Ifd0,
+		mustTag(t, 0x010F, tiff.DTAscii, "Canon"),
+		mustTag(t, 0x0110, tiff.DTAscii, "EOS"),
+		mustTag(t, 0x0112, tiff.DTShort, 6))
+
+	var got []string
+	for _, d := range Diff(a, b) {
+		got = append(got, d.String())
+	}
+	want := []string{`+ Model: "EOS"`, "~ Orientation: 1 => 6", `- Software: "GIMP"`}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Diff = %q, want %q", got, want)
+	}
+	if d := Diff(a, a); len(d) != 0 {
+		t.Errorf("Diff of a with itself = %v", d)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	x := exifFromTags(t, Ifd0,
+		mustTag(t, 0x010F, tiff.DTAscii, "Canon"),
+		mustTag(t, 0x0112, tiff.DTShort, 1),
+		mustTag(t, 0x011A, tiff.DTRational, [2]int64{72, 1}),
+		mustTag(t, 0x011B, tiff.DTRational, [2]int64{72, 1}),
+		mustTag(t, 0x0128, tiff.DTShort, 2))
+	x.LoadIfdTags(IfdExif, tiff.NewDir(mustTag(t, 0x9000, tiff.DTUndefined, []byte("0232"))), exifFields, false)
+	if errs := x.Validate(Exif232); len(errs) != 0 {
+		t.Errorf("valid fields: %v", errs)
+	}
+
+	x = exifFromTags(t, Ifd0,
+		mustTag(t, 0x0112, tiff.DTLong, []uint32{1, 1}),
+		mustTag(t, 0x011A, tiff.DTRational, [2]int64{72, 1}),
+		mustTag(t, 0x011B, tiff.DTRational, [2]int64{72, 1}),
+		mustTag(t, 0x0128, tiff.DTShort, 2))
+	x.LoadIfdTags(IfdExif, tiff.NewDir(mustTag(t, 0x010F, tiff.DTAscii, "Canon")), exifFields, false)
+	var got []string
+	for _, err := range x.Validate(Exif232) {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		"exif: required field ExifVersion is missing",
+		"exif: field Make is stored in ExifIFD, want IFD0",
+		"exif: field Orientation has type 4, want one of [3]",
+		"exif: field Orientation has 2 values, want 1",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Validate = %q, want %q", got, want)
+	}
+}
+
+func TestValidators(t *testing.T) {
+	for name := range Schemas {
+		if Validators[name] == nil {
+			t.Errorf("no validator for schema %q", name)
+		}
+	}
+	// a DNG file without the fields DNG16 requires
+	x := exifFromTags(t, Ifd0, mustTag(t, 0xC612, tiff.DTByte, []byte{2, 0, 0, 0}))
+	if got, want := len(Validators["dng1.6"](x)), len(x.ValidateDNG()); got != want || got <= len(x.Validate(DNG16)) {
+		t.Errorf("dng1.6 validator found %d violations, want the %d of ValidateDNG", got, want)
+	}
+}
+
+func TestRemoveGPS(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := RemoveGPS(bytes.NewReader(data), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != len(data) {
+		t.Errorf("output is %d bytes, want %d", out.Len(), len(data))
+	}
+
+	before, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := Decode(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := after.Get(GPSLatitude); !IsTagNotPresentError(err) {
+		t.Errorf("GPSLatitude still present, err = %v", err)
+	}
+	if bytes.Contains(out.Bytes(), before.Raw[before.dirs[IfdGPS][0].Layout.Offset:][:before.dirs[IfdGPS][0].Layout.Length]) {
+		t.Error("GPS IFD still present in output")
+	}
+	for _, d := range Diff(before, after) {
+		if d.B != nil || (!strings.HasPrefix(string(d.Name), "GPS") && d.Name != GPSInfoIFDPointer) {
+			t.Errorf("unexpected difference %v", d)
+		}
+	}
+	if _, err := after.Get(Model); err != nil {
+		t.Error(err)
+	}
+
+	// removing again changes nothing
+	var again bytes.Buffer
+	if err := RemoveGPS(bytes.NewReader(out.Bytes()), &again); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Bytes(), out.Bytes()) {
+		t.Error("removing GPS data twice changed the output")
+	}
+}
+
+// tiffJPEG returns a JPEG whose EXIF segment holds the tiff data of ifd0.
+func tiffJPEG(t *testing.T, ifd0 *tiff.Dir) []byte {
+	var app1 bytes.Buffer
+	app1.WriteString("Exif\x00\x00")
+	if err := tiff.NewTiff(binary.BigEndian, ifd0).Encode(&app1); err != nil {
+		t.Fatal(err)
+	}
+	jpeg := append([]byte{0xFF, 0xD8}, jpegSeg(0xE1, app1.Bytes())...)
+	return append(jpeg, 0xFF, 0xD9)
+}
+
+// breakEntry overwrites the IFD entry of the tag id in the JPEG data with
+// val at offset at, e.g. 2 for its type or 8 for its value offset.
+func breakEntry(t *testing.T, data []byte, id TagID, at int, val uint32) {
+	x, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := x.GetTag(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := x.TiffOffset()
+	e := base + tag.EntryOffset() + int64(at)
+	if at == 2 {
+		x.Tiff.Order.PutUint16(data[e:], uint16(val))
+	} else {
+		x.Tiff.Order.PutUint32(data[e:], val)
+	}
+}
+
+// gpsJPEG returns a JPEG with a GPS IFD holding a location.
+func gpsJPEG(t *testing.T) []byte {
+	ifd0 := tiff.NewDir(mustTag(t, 0x010F, tiff.DTAscii, "Canon"))
+	ifd0.SubDirs = map[uint16]*tiff.Dir{0x8825: tiff.NewDir(
+		mustTag(t, 0x0001, tiff.DTAscii, "N"),
+		mustTag(t, 0x0002, tiff.DTRational, [][2]int64{{40, 1}, {45, 1}, {30, 1}}),
+		mustTag(t, 0x0003, tiff.DTAscii, "W"),
+		mustTag(t, 0x0004, tiff.DTRational, [][2]int64{{111, 1}, {53, 1}, {20, 1}}),
+		mustTag(t, 0x0005, tiff.DTByte, []byte{0}),
+	)}
+	return tiffJPEG(t, ifd0)
+}
+
+func TestRemoveGPSCorrupt(t *testing.T) {
+	// an entry of the GPS IFD with an unknown data type
+	data := gpsJPEG(t)
+	breakEntry(t, data, GPS.AltitudeRef, 2, 0xFF)
+	if _, err := Decode(bytes.NewReader(data)); err == nil || IsCriticalError(err) {
+		t.Fatalf("decoding the corrupt GPS IFD: got error %v", err)
+	}
+	var out bytes.Buffer
+	if err := RemoveGPS(bytes.NewReader(data), &out); err != nil {
+		t.Fatal(err)
+	}
+	after, err := (&Decoder{Lenient: true}).Decode(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag, err := after.Get(GPSLatitude); !IsTagNotPresentError(err) {
+		t.Errorf("GPSLatitude still present: %v, %v", tag, err)
+	}
+	if _, err := after.Get(Make); err != nil {
+		t.Error(err)
+	}
+
+	// a GPS IFD that cannot be decoded at all
+	data = gpsJPEG(t)
+	breakEntry(t, data, IFD0.GPSInfoIFDPointer, 8, 0xFFFFFF00)
+	if err := RemoveGPS(bytes.NewReader(data), io.Discard); err == nil {
+		t.Error("no error removing a GPS IFD that cannot be decoded")
+	}
+}
+
+func TestTIFFBaselineFields(t *testing.T) {
+	var buf bytes.Buffer
+	dir := tiff.NewDir(
+		mustTag(t, 0x0100, tiff.DTShort, 2),
+		mustTag(t, 0x0101, tiff.DTShort, 2),
+		mustTag(t, 0x0116, tiff.DTShort, 2),
+		mustTag(t, 0x0140, tiff.DTShort, make([]int, 3*256)),
+		mustTag(t, 0x0153, tiff.DTShort, 1))
+	if err := tiff.NewTiff(binary.LittleEndian, dir).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	x, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []FieldName{RowsPerStrip, ColorMap, SampleFormat} {
+		if _, err := x.Get(name); err != nil {
+			t.Errorf("Get(%v): %v", name, err)
+		}
+	}
+}
+
+func TestDecodeTags(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := DecodeTags(bytes.NewReader(data), Orientation, DateTimeOriginal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []FieldName{Orientation, DateTimeOriginal} {
+		got, err := x.Get(name)
+		if err != nil {
+			t.Fatalf("Get(%v): %v", name, err)
+		}
+		if want, _ := full.Get(name); got.String() != want.String() {
+			t.Errorf("%v = %v, want %v", name, got, want)
+		}
+	}
+	for _, name := range []FieldName{Make, ExposureTime, GPSLatitude} {
+		if _, err := x.Get(name); !IsTagNotPresentError(err) {
+			t.Errorf("Get(%v) err = %v, want TagNotPresentError", name, err)
+		}
+	}
+	if len(x.IfdDirs(IfdGPS)) != 0 {
+		t.Error("GPS sub-IFD decoded after all fields were found")
+	}
+
+	// fields of IFD0 only: no sub-IFD is decoded
+	x, err = DecodeTags(bytes.NewReader(data), Model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.Get(Model); err != nil || len(x.IfdDirs(IfdExif)) != 0 {
+		t.Errorf("Get(Model) err = %v, %d Exif IFDs decoded", err, len(x.IfdDirs(IfdExif)))
+	}
+}
+
+func BenchmarkDecodeTags(b *testing.B) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("Decode", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := Decode(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("DecodeTags", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := DecodeTags(bytes.NewReader(data), Orientation, DateTimeOriginal); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestFormat(t *testing.T) {
+	x := exifFromTags(t, Ifd0,
+		mustTag(t, 0x0110, tiff.DTAscii, "EOS"),
+		mustTag(t, 0x010F, tiff.DTAscii, "Canon"),
+		mustTag(t, 0xABCD, tiff.DTShort, 7))
+	x.LoadIfdTags(IfdExif, tiff.NewDir(mustTag(t, 0x9000, tiff.DTUndefined, []byte("0232"))), exifFields, false)
+
+	if got, want := fmt.Sprintf("%v", x), `{ExifVersion:"0232" Make:"Canon" Model:"EOS"}`; got != want {
+		t.Errorf("%%v = %s, want %s", got, want)
+	}
+	want := "IFD0:\n\tModel: \"EOS\"\n\tMake: \"Canon\"\n\tUnknownTag_abcd: 7\nExifIFD:\n\tExifVersion: \"0232\"\n"
+	if got := fmt.Sprintf("%+v", x); got != want {
+		t.Errorf("%%+v = %q, want %q", got, want)
+	}
+	got := fmt.Sprintf("%#v", x)
+	if !strings.Contains(got, "IFD0 at offset -1") || !strings.Contains(got, "\t0x010f Make type=2 count=6 offset=0 inline=false: \"Canon\"\n") {
+		t.Errorf("%%#v = %q", got)
+	}
+	// String lists the fields in map order
+	lines := func(s string) []string {
+		l := strings.Split(s, "\n")
+		sort.Strings(l)
+		return l
+	}
+	if got := fmt.Sprintf("%s", x); !reflect.DeepEqual(lines(got), lines(x.String())) {
+		t.Errorf("%%s = %q, want String()", got)
+	}
+	if got := fmt.Sprintf("%d", x); got != "%!d(*exif.Exif)" {
+		t.Errorf("%%d = %q", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	x := exifFromTags(t, Ifd0,
+		mustTag(t, 0x0110, tiff.DTAscii, "X-T3 "),
+		mustTag(t, 0x8827, tiff.DTShort, 6400),
+		mustTag(t, 0x920A, tiff.DTRational, [2]int64{235, 10}))
+
+	tests := []struct {
+		f    Filter
+		want bool
+	}{
+		{Filter{Model, OpContains, "X-T"}, true},
+		{Filter{Model, OpEq, "X-T3"}, true},
+		{Filter{Model, OpNe, "X-T3"}, false},
+		{Filter{Model, OpLt, "X-T4"}, true},
+		{Filter{ISOSpeedRatings, OpGt, 3200}, true},
+		{Filter{ISOSpeedRatings, OpGe, int64(6400)}, true},
+		{Filter{ISOSpeedRatings, OpLt, 6400}, false},
+		{Filter{ISOSpeedRatings, OpEq, "6400"}, false},
+		{Filter{ISOSpeedRatings, OpContains, 64}, false},
+		{Filter{FocalLength, OpGt, 23.4}, true},
+		{Filter{FocalLength, OpLe, 23.5}, true},
+		{Filter{FocalLength, OpEq, 23}, false},
+		{Filter{Make, OpNe, "Canon"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Match(x); got != tt.want {
+			t.Errorf("%v: got %v, want %v", tt.f, got, tt.want)
+		}
+	}
+
+	if !MatchAll(x, Filter{ISOSpeedRatings, OpGt, 3200}, Filter{Model, OpContains, "X-T"}) {
+		t.Error("MatchAll: got false, want true")
+	}
+	if MatchAll(x, Filter{ISOSpeedRatings, OpGt, 3200}, Filter{Model, OpContains, "EOS"}) {
+		t.Error("MatchAll: got true, want false")
+	}
+}
+
+func TestCommentAndAppSegments(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join(*dataDir, "samples", "raw.exif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ducky := []byte("Ducky\x00\x01\x00\x04\x00\x00\x00\x50\x00\x00")
+	adobe := []byte("Adobe\x00\x64\x80\x00\x00\x00\x01")
+	var buf bytes.Buffer
+	buf.Write([]byte{0xFF, 0xD8})
+	buf.Write(jpegSeg(0xE1, raw))
+	buf.Write(jpegSeg(0xFE, []byte("first comment")))
+	buf.Write(jpegSeg(0xEC, ducky))
+	buf.Write(jpegSeg(0xEE, adobe))
+	buf.Write(jpegSeg(0xFE, []byte("second\x00")))
+	buf.Write([]byte{0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9})
+
+	x, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got, want := x.Comments(), []string{"first comment", "second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Comments() = %q, want %q", got, want)
+	}
+	if n := len(x.Segments()); n != 4 {
+		t.Errorf("got %d other segments, want 4", n)
+	}
+	if segs := x.AppSegments("Ducky"); len(segs) != 1 || segs[0].Marker != 0xEC || !bytes.Equal(segs[0].Data, ducky) {
+		t.Errorf("AppSegments(Ducky) = %v", segs)
+	}
+	if segs := x.AppSegments("Adobe"); len(segs) != 1 || !bytes.Equal(segs[0].Data, adobe) {
+		t.Errorf("AppSegments(Adobe) = %v", segs)
+	}
+	if a, err := x.Adobe(); err != nil || a.Version != 100 || a.Flags0 != 0x8000 || a.Transform != AdobeYCbCr {
+		t.Errorf("Adobe() = %+v, %v", a, err)
+	} else if a.Transform.String() != "YCbCr" {
+		t.Errorf("Transform.String() = %q", a.Transform)
+	}
+	duckySeg := x.AppSegments("Ducky")[0]
+	if _, err := duckySeg.Adobe(); err != ErrNoAdobe {
+		t.Errorf("Adobe() of Ducky segment: err = %v, want ErrNoAdobe", err)
+	}
+	for _, seg := range x.Segments() {
+		if seg.Marker == 0xFE && seg.Identifier() != "" {
+			t.Errorf("COM segment has identifier %q", seg.Identifier())
+		}
+	}
+}
+
+func TestCompliance(t *testing.T) {
+	f, err := os.Open(filepath.Join(*dataDir, "samples", "2004-01-11-22-45-15-sep-2004-01-11-22-45-15a.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	x, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := x.Compliance()
+	if c.ExifVersion != "0220" || c.FlashpixVersion != "0100" || c.InteropIndex != "R98" || c.InteropVersion != "0100" {
+		t.Errorf("got versions %+v", c)
+	}
+	if c.Uncompressed {
+		t.Error("JPEG reported as uncompressed")
+	}
+
+	x = exifFromTags(t, Ifd0,
+		mustTag(t, 0x011A, tiff.DTRational, [2]int64{72, 1}),
+		mustTag(t, 0x0111, tiff.DTLong, 8),
+		mustTag(t, 0x9000, tiff.DTUndefined, []byte("0232")))
+	c = x.Compliance()
+	if c.ExifVersion != "0232" || c.FlashpixVersion != "" || !c.Uncompressed || c.Compliant() {
+		t.Errorf("got %+v", c)
+	}
+	want := []FieldName{BitsPerSample, ColorSpace, Compression, ExifIFDPointer, FlashpixVersion,
+		ImageLength, ImageWidth, PhotometricInterpretation, ResolutionUnit, RowsPerStrip,
+		SamplesPerPixel, StripByteCounts, YResolution}
+	if !reflect.DeepEqual(c.Missing, want) {
+		t.Errorf("missing %v, want %v", c.Missing, want)
+	}
+}
+
+func TestPseudonymize(t *testing.T) {
+	pseudonymize := func(name string, salt string) (orig, got *Exif, out []byte) {
+		data, err := os.ReadFile(filepath.Join(*dataDir, "samples", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if orig, err = Decode(bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := Pseudonymize(bytes.NewReader(data), &buf, []byte(salt)); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() != len(data) {
+			t.Fatalf("%s: output is %d bytes, want %d", name, buf.Len(), len(data))
+		}
+		if got, err = Decode(bytes.NewReader(buf.Bytes())); err != nil {
+			t.Fatal(err)
+		}
+		return orig, got, buf.Bytes()
+	}
+	str := func(x *Exif, name FieldName) string {
+		tag, err := x.Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, _ := tag.StringVal()
+		return s
+	}
+
+	const serials = "2012-12-21-11-15-19-sep-IMG_0001.jpg"
+	orig, x, out := pseudonymize(serials, "salt")
+	for _, name := range []FieldName{BodySerialNumber, LensSerialNumber} {
+		o, p := str(orig, name), str(x, name)
+		if p == o || len(p) != len(o) {
+			t.Errorf("%v: got %q for %q", name, p, o)
+		}
+	}
+	if s := str(x, CameraOwnerName); s != "" {
+		t.Errorf("empty CameraOwnerName replaced with %q", s)
+	}
+	if _, _, again := pseudonymize(serials, "salt"); !bytes.Equal(again, out) {
+		t.Error("pseudonyms differ for the same salt")
+	}
+	if _, y, _ := pseudonymize(serials, "pepper"); str(y, BodySerialNumber) == str(x, BodySerialNumber) {
+		t.Error("pseudonyms do not depend on the salt")
+	}
+
+	orig, x, _ = pseudonymize("2012-12-19-21-38-40-sep-temple_square1.jpg", "salt")
+	lat0, long0, err := orig.LatLong()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lat, long, err := x.LatLong()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(lat-lat0) < 0.1 && math.Abs(long-long0) < 0.1 {
+		t.Errorf("location %v,%v too close to the original %v,%v", lat, long, lat0, long0)
+	}
+	if lat < -90 || lat > 90 || long < -180 || long > 180 {
+		t.Errorf("location %v,%v out of range", lat, long)
+	}
+	if tag, err := orig.Get(GPSAltitude); err != nil || tag.String() == `"0/1"` {
+		t.Fatalf("original GPSAltitude = %v, %v", tag, err)
+	}
+	for _, f := range x.IfdFields(IfdGPS) {
+		switch f.Name {
+		case GPSVersionID, GPSLatitude, GPSLatitudeRef, GPSLongitude, GPSLongitudeRef:
+			continue
+		}
+		// zero, over one for rationals
+		want := make([]byte, len(f.Tag.Val))
+		if f.Tag.Format() == tiff.RatVal {
+			for i := 4; i < len(want); i += 8 {
+				x.Tiff.Order.PutUint32(want[i:], 1)
+			}
+		}
+		if !bytes.Equal(f.Tag.Val, want) {
+			t.Errorf("%v = %v, want zero", f.Name, f.Tag)
+		}
+	}
+
+	// GPS coordinates that cannot be parsed
+	ifd0 := tiff.NewDir()
+	ifd0.SubDirs = map[uint16]*tiff.Dir{0x8825: tiff.NewDir(mustTag(t, 0x0002, tiff.DTRational, [][2]int64{{1, 0}, {0, 1}, {0, 1}}))}
+	jpeg := tiffJPEG(t, ifd0)
+	if err := Pseudonymize(bytes.NewReader(jpeg), io.Discard, []byte("salt")); err == nil {
+		t.Error("no error pseudonymizing unparsable GPS coordinates")
+	}
+}
+
+func TestPseudonymizeCorrupt(t *testing.T) {
+	serialJPEG := func() []byte {
+		ifd0 := tiff.NewDir(mustTag(t, 0x010F, tiff.DTAscii, "Canon"))
+		ifd0.SubDirs = map[uint16]*tiff.Dir{0x8769: tiff.NewDir(
+			mustTag(t, 0xA431, tiff.DTAscii, "0123456789"),
+			mustTag(t, 0xA433, tiff.DTAscii, "Canon"),
+		)}
+		return tiffJPEG(t, ifd0)
+	}
+
+	// entries of the Exif and GPS IFDs with an unknown data type
+	for _, test := range []struct {
+		data []byte
+		id   TagID
+		name FieldName
+	}{
+		{serialJPEG(), ExifIFD.LensMake, BodySerialNumber},
+		{gpsJPEG(t), GPS.AltitudeRef, GPSLatitude},
+	} {
+		breakEntry(t, test.data, test.id, 2, 0xFF)
+		orig, err := (&Decoder{Lenient: true}).Decode(bytes.NewReader(test.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if err := Pseudonymize(bytes.NewReader(test.data), &out, []byte("salt")); err != nil {
+			t.Fatalf("%v: %v", test.name, err)
+		}
+		x, err := (&Decoder{Lenient: true}).Decode(bytes.NewReader(out.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		before, _ := orig.Get(test.name)
+		after, err := x.Get(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(after.Val, before.Val) {
+			t.Errorf("%v not pseudonymized: %v", test.name, after)
+		}
+	}
+
+	// Exif and GPS IFDs that cannot be decoded at all
+	for _, test := range []struct {
+		data []byte
+		ptr  TagID
+	}{
+		{serialJPEG(), IFD0.ExifIFDPointer},
+		{gpsJPEG(t), IFD0.GPSInfoIFDPointer},
+	} {
+		breakEntry(t, test.data, test.ptr, 8, 0xFFFFFF00)
+		if err := Pseudonymize(bytes.NewReader(test.data), io.Discard, []byte("salt")); err == nil {
+			t.Errorf("no error pseudonymizing with a broken %v", test.ptr)
+		}
+	}
+
+	// a maker note whose parser fails
+	p := &failParser{}
+	RegisterParsers(p)
+	defer UnregisterParsers(p)
+	if err := Pseudonymize(bytes.NewReader(serialJPEG()), io.Discard, []byte("salt")); err == nil {
+		t.Error("no error pseudonymizing with a failing maker note parser")
+	}
+}
+
+type failParser struct{}
+
+func (p *failParser) Parse(x *Exif) error {
+	return errors.New("maker note cannot be decoded")
+}
+
+func TestDecodeResync(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join(*dataDir, "samples", "raw.exif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jfif := jpegSeg(0xE0, []byte("JFIF\x00\x01\x02\x00\x00\x01\x00\x01\x00\x00"))
+	scan := []byte{0xFF, 0xDA, 0x00, 0x02, 0x12, 0xFF, 0x00, 0x34, 0xFF, 0xD9}
+	jpeg := func(segs ...[]byte) []byte {
+		b := []byte{0xFF, 0xD8}
+		for _, seg := range segs {
+			b = append(b, seg...)
+		}
+		return append(b, scan...)
+	}
+	cat := func(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+	tests := map[string][]byte{
+		"leading padding":  cat(make([]byte, 100), jpeg(jfif, jpegSeg(0xE1, raw))),
+		"leading garbage":  cat([]byte("\x12\xFF\xE1\x7F\xFF\xFF\xC4\x00\x01junk"), jpeg(jpegSeg(0xE1, raw))),
+		"prepended image":  cat(jpeg(jfif), jpeg(jfif, jpegSeg(0xE1, raw))),
+		"invalid marker":   jpeg(jfif, []byte("\xFF\x12\x00\x00"), jpegSeg(0xE1, raw)),
+		"invalid length":   jpeg(jfif, []byte("\xFF\xE2\x00\x01"), jpegSeg(0xE1, raw)),
+		"missing SOI":      cat(jpegSeg(0xE1, raw), scan),
+		"progressive scan": jpeg(jfif, jpegSeg(0xC2, make([]byte, 9)), jpegSeg(0xE1, raw)),
+	}
+	for name, data := range tests {
+		x, err := Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if _, err := x.Get(DateTime); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		off, ok := x.TiffOffset()
+		if !ok || !bytes.Equal(data[off:off+int64(len(x.Raw))], x.Raw) {
+			t.Errorf("%s: tiff offset %d, %v does not point at the EXIF data", name, off, ok)
+		}
+	}
+
+	if _, err := Decode(bytes.NewReader(cat(make([]byte, 10), jpeg(jfif), jpeg(jfif)))); err == nil {
+		t.Error("no error decoding images without EXIF data")
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := x.Tiff.Order
+	n := 0
+	for ifd, dirs := range x.dirs {
+		for _, d := range dirs {
+			for _, tag := range d.Tags {
+				p, ok := x.Provenance(tag)
+				if ifd == IfdMakerNote {
+					if ok {
+						t.Errorf("maker note tag 0x%04x has a provenance", tag.Id)
+					}
+					continue
+				}
+				if !ok {
+					t.Errorf("%v tag 0x%04x has no provenance", ifd, tag.Id)
+					continue
+				}
+				n++
+				v := data[p.ValueOffset : p.ValueOffset+p.ValueLength]
+				if !bytes.Equal(v, tag.Val) {
+					t.Errorf("%v tag 0x%04x: value range holds %x, want %x", ifd, tag.Id, v, tag.Val)
+				}
+				e := data[p.EntryOffset : p.EntryOffset+12]
+				if order.Uint16(e) != tag.Id || order.Uint32(e[4:]) != tag.Count {
+					t.Errorf("%v tag 0x%04x: entry range holds %x", ifd, tag.Id, e)
+				}
+				if tag.Inlined() != (p.ValueOffset == p.EntryOffset+8) {
+					t.Errorf("%v tag 0x%04x: inlined value not in entry", ifd, tag.Id)
+				}
+			}
+		}
+	}
+	if n == 0 {
+		t.Fatal("no tags checked")
+	}
+
+	if _, ok := x.Provenance(mustTag(t, 0x010F, tiff.DTAscii, "Canon")); ok {
+		t.Error("foreign tag has a provenance")
+	}
+	var y Exif
+	b, err := x.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := y.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	tag, _ := x.Get(Model)
+	ytag, _ := y.Get(Model)
+	p, _ := x.Provenance(tag)
+	if yp, ok := y.Provenance(ytag); !ok || yp != p {
+		t.Errorf("provenance after binary round trip: got %+v, %v, want %+v", yp, ok, p)
+	}
+}
+
+func TestDecodeAllocs(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocs := testing.AllocsPerRun(20, func() {
+		if _, err := Decode(bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+	})
+	// Tags, fields and inline values are allocated per IFD rather than
+	// per tag, so that services decoding many files stay light on the GC.
+	if max := 250.0; allocs > max {
+		t.Errorf("Decode made %v allocations, want at most %v", allocs, max)
+	}
+}
+
+func TestDecodeBuffer(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := os.ReadFile(filepath.Join(*dataDir, "corrupt/zero_count_exif.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := x.MarshalJSON()
+
+	dec := &Decoder{Buffer: new(Buffer)}
+	first, err := dec.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// without a Reset, later decodes must not overwrite the first
+	if _, err := dec.Decode(bytes.NewReader(other)); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := first.MarshalJSON(); !bytes.Equal(got, want) {
+		t.Errorf("Exif overwritten by a later decode:\n%s\nwant\n%s", got, want)
+	}
+	dec.Buffer.Reset()
+	x, err = dec.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := x.MarshalJSON(); !bytes.Equal(got, want) {
+		t.Errorf("decode after Reset gave\n%s\nwant\n%s", got, want)
+	}
+
+	allocs := testing.AllocsPerRun(20, func() {
+		dec.Buffer.Reset()
+		if _, err := dec.Decode(bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if max := 100.0; allocs > max {
+		t.Errorf("Decode with a Buffer made %v allocations, want at most %v", allocs, max)
+	}
+}
+
+func BenchmarkDecodeBuffer(b *testing.B) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("New", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := Decode(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Reset", func(b *testing.B) {
+		b.ReportAllocs()
+		dec := &Decoder{Buffer: new(Buffer)}
+		for i := 0; i < b.N; i++ {
+			dec.Buffer.Reset()
+			if _, err := dec.Decode(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestCopy(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var img bytes.Buffer
+	if err := jpeg.Encode(&img, image.NewGray(image.Rect(0, 0, 4, 4)), nil); err != nil {
+		t.Fatal(err)
+	}
+	// give the destination an EXIF segment of its own to be replaced
+	old, _ := tiff.NewTag(0x0110, tiff.DTAscii, "OLD MODEL")
+	var app1 bytes.Buffer
+	app1.WriteString("Exif\x00\x00")
+	if err := tiff.NewTiff(binary.LittleEndian, tiff.NewDir(old)).Encode(&app1); err != nil {
+		t.Fatal(err)
+	}
+	var dst bytes.Buffer
+	dst.Write([]byte{0xFF, 0xD8})
+	dst.Write(jpegSeg(0xE1, app1.Bytes()))
+	dst.Write(img.Bytes()[2:])
+
+	want, err := Decode(bytes.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sos := bytes.Index(img.Bytes(), []byte{0xFF, 0xDA})
+
+	var all bytes.Buffer
+	if err := Copy(bytes.NewReader(src), bytes.NewReader(dst.Bytes()), &all); err != nil {
+		t.Fatal(err)
+	}
+	x, err := Decode(bytes.NewReader(all.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(x.Raw, want.Raw) {
+		t.Errorf("copied EXIF data differs from source")
+	}
+	if !bytes.HasSuffix(all.Bytes(), img.Bytes()[sos:]) {
+		t.Errorf("image data of destination not preserved")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(all.Bytes())); err != nil {
+		t.Errorf("result is not a valid JPEG: %v", err)
+	}
+
+	var some bytes.Buffer
+	if err := Copy(bytes.NewReader(src), bytes.NewReader(dst.Bytes()), &some, Make, DateTimeOriginal, GPSLatitude); err != nil {
+		t.Fatal(err)
+	}
+	x, err = Decode(bytes.NewReader(some.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []FieldName{Make, DateTimeOriginal, GPSLatitude} {
+		got, err := x.Get(name)
+		if err != nil {
+			t.Errorf("%v not copied: %v", name, err)
+			continue
+		}
+		w, _ := want.Get(name)
+		if got.String() != w.String() {
+			t.Errorf("%v = %v, want %v", name, got, w)
+		}
+	}
+	for _, name := range []FieldName{Model, ExposureTime, GPSLongitude} {
+		if _, err := x.Get(name); err == nil {
+			t.Errorf("%v copied, want absent", name)
+		}
+	}
+	if !bytes.HasSuffix(some.Bytes(), img.Bytes()[sos:]) {
+		t.Errorf("image data of destination not preserved")
+	}
+
+	if err := Copy(bytes.NewReader(src), bytes.NewReader(dst.Bytes()), &some, ThumbJPEGInterchangeFormat); err == nil {
+		t.Errorf("copying an IFD1 field succeeded")
+	}
+	if err := Copy(bytes.NewReader(src), strings.NewReader("not a jpeg"), &some); err == nil {
+		t.Errorf("copying into a non-JPEG destination succeeded")
+	}
+}
+
+func TestCopyMakerNote(t *testing.T) {
+	desc, _ := tiff.NewTag(0x010E, tiff.DTAscii, strings.Repeat("d", 200))
+	mk, _ := tiff.NewTag(0x010F, tiff.DTAscii, "NIKON")
+	note, _ := tiff.NewTag(0x927C, tiff.DTUndefined, []byte("Nikon\x00\x02\x00\x00\x00MM\x00\x2a\x00\x00\x00\x08"))
+	ifd0 := tiff.NewDir(desc, mk)
+	ifd0.SubDirs = map[uint16]*tiff.Dir{exifPointer: tiff.NewDir(note)}
+	var src bytes.Buffer
+	if err := tiff.NewTiff(binary.LittleEndian, ifd0).Encode(&src); err != nil {
+		t.Fatal(err)
+	}
+	var img bytes.Buffer
+	if err := jpeg.Encode(&img, image.NewGray(image.Rect(0, 0, 4, 4)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	copyNote := func(src []byte, fields ...FieldName) (*tiff.Tag, error) {
+		var out bytes.Buffer
+		if err := Copy(bytes.NewReader(src), bytes.NewReader(img.Bytes()), &out, fields...); err != nil {
+			return nil, err
+		}
+		x, err := Decode(bytes.NewReader(out.Bytes()))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := x.Get(Make); err != nil {
+			t.Errorf("Make not copied: %v", err)
+		}
+		return x.Get(MakerNote)
+	}
+
+	x, err := Decode(bytes.NewReader(src.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := x.Get(MakerNote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOff, _ := want.ValueOffset()
+	for _, fields := range [][]FieldName{nil, {Make, MakerNote}} {
+		got, err := copyNote(src.Bytes(), fields...)
+		if err != nil {
+			t.Errorf("fields %v: %v", fields, err)
+			continue
+		}
+		if off, _ := got.ValueOffset(); off != wantOff || !bytes.Equal(got.Val, want.Val) {
+			t.Errorf("fields %v: maker note %q at offset %d, want %q at %d", fields, got.Val, off, want.Val, wantOff)
+		}
+	}
+
+	// a maker note stored before the other fields cannot keep its offset
+	moved := append([]byte(nil), src.Bytes()...)
+	binary.LittleEndian.PutUint32(moved[want.EntryOffset()+8:], 8)
+	if _, err := copyNote(moved); err == nil {
+		t.Errorf("maker note copied with all fields, want it left out")
+	}
+	if _, err := copyNote(moved, Make, MakerNote); err == nil {
+		t.Errorf("copying a maker note that cannot keep its offset succeeded")
+	}
+}
+
+func TestEdits(t *testing.T) {
+	text := func(id uint16, s string) *tiff.Tag {
+		tag, _ := tiff.NewTag(id, tiff.DTAscii, s)
+		return tag
+	}
+	camera := exifFromTags(t, Ifd0, text(0x0131, "Ver.1.00"), text(0x0132, "2020:05:01 10:00:20"))
+	camera.LoadIfdTags(IfdExif, tiff.NewDir(text(0x9003, "2020:05:01 10:00:00")), exifFields, false)
+	if r := camera.Edits(); r.Edited() || r.Software != "Ver.1.00" {
+		t.Errorf("camera-original: got %+v", r)
+	}
+
+	edited := exifFromTags(t, Ifd0,
+		text(0x0131, "Adobe Photoshop CC 2019 (Windows)"),
+		text(0x000B, "RawConverter 2.1"),
+		text(0x0132, "2020:05:03 18:30:00"),
+	)
+	edited.LoadIfdTags(IfdExif, tiff.NewDir(text(0x9003, "2020:05:01 10:00:00")), exifFields, false)
+	r := edited.Edits()
+	if r.ProcessingSoftware != "RawConverter 2.1" {
+		t.Errorf("ProcessingSoftware = %q", r.ProcessingSoftware)
+	}
+	var kinds []ClueKind
+	for _, c := range r.Clues {
+		kinds = append(kinds, c.Kind)
+	}
+	if want := []ClueKind{ClueSoftware, ClueProcessingSoftware, ClueDates}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("clues = %v, want kinds %v", r.Clues, want)
+	}
+
+	// sub-IFDs stored before IFD0, as left by editors appending a new IFD0
+	exifDir := tiff.NewDir(text(0x9003, "2020:05:01 10:00:00"))
+	exifDir.Layout.Offset = 8
+	ifd0 := tiff.NewDir(text(0x010F, "Make"))
+	ifd0.Layout.Offset = 200
+	moved := exifFromTags(t, Ifd0)
+	moved.dirs = map[IfdID][]*tiff.Dir{Ifd0: {ifd0}, IfdExif: {exifDir}}
+	r = moved.Edits()
+	if len(r.Clues) != 1 || r.Clues[0].Kind != ClueStructure {
+		t.Errorf("moved IFD0: clues = %v, want one structure clue", r.Clues)
+	}
+}
+
+func TestShiftTimes(t *testing.T) {
+	text := func(id uint16, s string) *tiff.Tag {
+		tag, _ := tiff.NewTag(id, tiff.DTAscii, s)
+		return tag
+	}
+	gpsTime, _ := tiff.NewTag(0x0007, tiff.DTRational, [][2]int64{{23, 1}, {30, 1}, {1050, 100}})
+	ifd0 := tiff.NewDir(text(0x0132, "2021:03:28 00:30:10"))
+	ifd0.SubDirs = map[uint16]*tiff.Dir{
+		exifPointer: tiff.NewDir(
+			text(0x9003, "2021:03:28 00:30:10"),
+			text(0x9291, "50"),
+			text(0x9011, "+01:00"),
+		),
+		gpsPointer: tiff.NewDir(text(0x001D, "2021:03:27"), gpsTime),
+	}
+	var buf bytes.Buffer
+	if err := tiff.NewTiff(binary.BigEndian, ifd0).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	shift := func(s TimeShift) *Exif {
+		t.Helper()
+		f, err := os.CreateTemp("", "goexif-shift")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(f.Name())
+		_, err = f.Write(buf.Bytes())
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ShiftFile(f.Name(), s); err != nil {
+			t.Fatal(err)
+		}
+		x, err := DecodeFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return x
+	}
+	check := func(x *Exif, want map[FieldName]string) {
+		t.Helper()
+		for name, w := range want {
+			if got := x.text(name); got != w {
+				t.Errorf("%v = %q, want %q", name, got, w)
+			}
+		}
+	}
+
+	x := shift(TimeShift{By: 90 * time.Minute})
+	check(x, map[FieldName]string{
+		DateTime:           "2021:03:28 02:00:10",
+		DateTimeOriginal:   "2021:03:28 02:00:10",
+		SubSecTimeOriginal: "50",
+		OffsetTimeOriginal: "+01:00",
+		GPSDateStamp:       "2021:03:28",
+	})
+	if got, _ := x.GPSDateTime(); !got.Equal(time.Date(2021, 3, 28, 1, 0, 10, 500000000, time.UTC)) {
+		t.Errorf("GPS time = %v, want 01:00:10.5 UTC", got)
+	}
+
+	x = shift(TimeShift{By: 90 * time.Minute, Zone: time.UTC})
+	check(x, map[FieldName]string{
+		DateTime:           "2021:03:28 01:00:10",
+		DateTimeOriginal:   "2021:03:28 01:00:10",
+		OffsetTimeOriginal: "+00:00",
+	})
+
+	// the shifted time lies after the start of summer time in Berlin
+	if berlin, err := time.LoadLocation("Europe/Berlin"); err == nil {
+		x = shift(TimeShift{By: 90 * time.Minute, Zone: berlin})
+		check(x, map[FieldName]string{
+			DateTimeOriginal:   "2021:03:28 03:00:10",
+			OffsetTimeOriginal: "+02:00",
+			GPSDateStamp:       "2021:03:28",
+		})
+	}
+
+	// without GPS time the zone of DateTime is unknown
+	noGPS := exifFromTags(t, Ifd0, text(0x0132, "2021:03:28 00:30:10"))
+	if err := noGPS.ShiftTimes(nil, TimeShift{Zone: time.UTC}); err == nil {
+		t.Error("no error converting a timestamp without time zone")
+	}
+}
+
+func TestDecodePSD(t *testing.T) {
+	makeTag, err := tiff.NewTag(0x010F, tiff.DTAscii, "Adobe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tif bytes.Buffer
+	if err := tiff.NewTiff(binary.BigEndian, tiff.NewDir(makeTag)).Encode(&tif); err != nil {
+		t.Fatal(err)
+	}
+	resource := func(id uint16, name string, data []byte) []byte {
+		b := binary.BigEndian.AppendUint16([]byte("8BIM"), id)
+		b = append(b, byte(len(name)))
+		b = append(b, name...)
+		if len(name)%2 == 0 {
+			b = append(b, 0)
+		}
+		b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+		b = append(b, data...)
+		if len(data)%2 == 1 {
+			b = append(b, 0)
+		}
+		return b
+	}
+	res := resource(0x0404, "abc", []byte{1, 2, 3}) // IPTC
+	exifOff := len(res) + 4 + 2 + 2 + 4
+	res = append(res, resource(0x0422, "", tif.Bytes())...)
+
+	psd := append([]byte("8BPS\x00\x01"), make([]byte, 6+2+4+4+2+2)...)
+	psd = binary.BigEndian.AppendUint32(psd, 0) // color mode data
+	psd = binary.BigEndian.AppendUint32(psd, uint32(len(res)))
+	exifOff += len(psd)
+	psd = append(psd, res...)
+	psd = binary.BigEndian.AppendUint32(psd, 0) // layer and mask info
+	testMaxBytes(t, "PSD", psd)
+
+	x, err := Decode(bytes.NewReader(psd))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag, err := x.Get(Make); err != nil || tag.String() != `"Adobe"` {
+		t.Errorf("Make = %v, %v", tag, err)
+	}
+	if off, ok := x.TiffOffset(); !ok || off != int64(exifOff) {
+		t.Errorf("TiffOffset = %v, %v, want %v", off, ok, exifOff)
+	}
+
+	// an Illustrator file holding the resource in its private data
+	ai := append([]byte("%PDF-1.6\n%\xe2\xe3\xcf\xd3\n"), resource(0x0422, "", tif.Bytes())...)
+	ai = append(ai, "\nendstream\n%%EOF\n"...)
+	if x, err = Decode(bytes.NewReader(ai)); err != nil {
+		t.Fatal(err)
+	}
+	if tag, err := x.Get(Make); err != nil || tag.String() != `"Adobe"` {
+		t.Errorf("Illustrator Make = %v, %v", tag, err)
+	}
+
+	if _, err := Decode(bytes.NewReader(psd[:40])); err == nil {
+		t.Error("no error for truncated PSD file")
+	}
+}
+
+func TestToMap(t *testing.T) {
+	f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	x, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		opts MapOptions
+		want map[string]interface{}
+	}{
+		{MapOptions{}, map[string]interface{}{
+			"Model":        "NIKON D2H",
+			"Orientation":  int64(1),
+			"FNumber":      "45/10",
+			"ExifVersion":  "0220",
+			"GPSLatitude":  []string{"39/1", "54/1", "56/1"},
+			"GPSVersionID": []int64{2, 2, 0, 0},
+		}},
+		{MapOptions{Values: ValueNumeric}, map[string]interface{}{
+			"FNumber":      4.5,
+			"ExposureTime": 0.008,
+			"GPSLatitude":  []float64{39, 54, 56},
+		}},
+		{MapOptions{Values: ValueHuman}, map[string]interface{}{
+			"Orientation":  "Horizontal (normal)",
+			"ExposureTime": "1/125",
+		}},
+	} {
+		m := x.ToMap(tt.opts)
+		for k, want := range tt.want {
+			if got := m[k]; !reflect.DeepEqual(got, want) {
+				t.Errorf("%+v: %v = %#v, want %#v", tt.opts, k, got, want)
+			}
+		}
+	}
+
+	if b, err := json.Marshal(x.ToMap(MapOptions{Values: ValueNumeric})); err != nil {
+		t.Errorf("numeric map not encodable as JSON: %v", err)
+	} else if !bytes.Contains(b, []byte(`"FNumber":4.5`)) {
+		t.Errorf("JSON = %s", b)
+	}
+
+	components := mustTag(t, 0x9101, tiff.DTUndefined, []byte{1, 2, 3, 0})
+	unknown := mustTag(t, 0x9999, tiff.DTShort, 7)
+	y := exifFromTags(t, IfdExif, components, unknown)
+	if m := y.ToMap(MapOptions{}); len(m) != 0 {
+		t.Errorf("binary and unknown fields included by default: %v", m)
+	}
+	m := y.ToMap(MapOptions{Binary: true, Unknown: true})
+	if v := m[string(ComponentsConfiguration)]; !reflect.DeepEqual(v, []byte{1, 2, 3, 0}) {
+		t.Errorf("ComponentsConfiguration with Binary = %#v", v)
+	}
+	if len(m) != 2 {
+		t.Errorf("got %v, want ComponentsConfiguration and an unknown tag", m)
+	}
+	if v := y.ToMap(MapOptions{Values: ValueHuman})[string(ComponentsConfiguration)]; v != "Y, Cb, Cr, -" {
+		t.Errorf("human ComponentsConfiguration = %#v", v)
+	}
+}
+
+func TestCoercion(t *testing.T) {
+	x := exifFromTags(t, Ifd0,
+		mustTag(t, 0x0100, tiff.DTLong, 70000),                       // ImageWidth
+		mustTag(t, 0x0101, tiff.DTShort, 480),                        // ImageLength
+		mustTag(t, 0x011A, tiff.DTRational, [][2]int64{{300, 1}}),    // XResolution
+		mustTag(t, 0x011B, tiff.DTRational, [][2]int64{{7, 2}}),      // YResolution
+		mustTag(t, 0x0128, tiff.DTSShort, -1),                        // ResolutionUnit
+		mustTag(t, 0x010F, tiff.DTAscii, "Canon "),                   // Make
+		mustTag(t, 0x0131, tiff.DTDouble, 2.0),                       // Software
+		mustTag(t, 0x013B, tiff.DTRational, [][2]int64{{1, 0}}),      // Artist
+		mustTag(t, 0x0110, tiff.DTLong, []uint32{math.MaxUint32, 1}), // Model
+	)
+	if v, err := x.GetUint32(ImageWidth); err != nil || v != 70000 {
+		t.Errorf("GetUint32(ImageWidth) = %v, %v", v, err)
+	}
+	if _, err := x.GetUint16(ImageWidth); !errors.Is(err, ErrOverflow) {
+		t.Errorf("GetUint16(70000) error = %v, want ErrOverflow", err)
+	}
+	if v, err := x.GetUint16(ImageLength); err != nil || v != 480 {
+		t.Errorf("GetUint16(ImageLength) = %v, %v", v, err)
+	}
+	if v, err := x.GetUint32(XResolution); err != nil || v != 300 {
+		t.Errorf("GetUint32(300/1) = %v, %v", v, err)
+	}
+	if _, err := x.GetInt64(YResolution); !errors.Is(err, ErrPrecision) {
+		t.Errorf("GetInt64(7/2) error = %v, want ErrPrecision", err)
+	}
+	if v, err := x.GetFloat(YResolution); err != nil || v != 3.5 {
+		t.Errorf("GetFloat(7/2) = %v, %v", v, err)
+	}
+	if _, err := x.GetUint32(ResolutionUnit); !errors.Is(err, ErrOverflow) {
+		t.Errorf("GetUint32(-1) error = %v, want ErrOverflow", err)
+	}
+	if v, err := x.GetFloat(ImageLength); err != nil || v != 480 {
+		t.Errorf("GetFloat(ImageLength) = %v, %v", v, err)
+	}
+	if v, err := x.GetInt64(Software); err != nil || v != 2 {
+		t.Errorf("GetInt64(2.0) = %v, %v", v, err)
+	}
+	if _, err := x.GetFloat(Artist); err == nil {
+		t.Error("no error for a zero denominator")
+	}
+	if v, err := x.GetUint32(Model); err != nil || v != math.MaxUint32 {
+		t.Errorf("GetUint32(Model) = %v, %v", v, err)
+	}
+	if v, err := x.GetString(Make); err != nil || v != "Canon" {
+		t.Errorf("GetString(Make) = %q, %v", v, err)
+	}
+	if _, err := x.GetString(ImageWidth); err == nil {
+		t.Error("no error getting an integer as a string")
+	}
+	if _, err := x.GetInt64(Make); err == nil {
+		t.Error("no error getting a string as an integer")
+	}
+
+	y := exifFromTags(t, IfdExif, mustTag(t, 0x9000, tiff.DTUndefined, []byte("0232")))
+	if v, err := y.GetString(ExifVersion); err != nil || v != "0232" {
+		t.Errorf("GetString(ExifVersion) = %q, %v", v, err)
+	}
+}
+
+type orderParser struct {
+	name  string
+	calls *[]string
+}
+
+func (p *orderParser) Parse(x *Exif) error {
+	*p.calls = append(*p.calls, p.name)
+	return nil
+}
+
+func TestRegisterParsers(t *testing.T) {
+	var calls []string
+	late := &orderParser{"late", &calls}
+	early := &orderParser{"early", &calls}
+	var ps []Parser
+	for i := 0; i < 10; i++ {
+		ps = append(ps, &orderParser{fmt.Sprint(i), &calls})
+	}
+	t.Cleanup(func() { UnregisterParsers(append(ps, late, early)...) })
+
+	RegisterParsersPriority(10, late)
+	var wg sync.WaitGroup
+	for _, p := range ps {
+		wg.Add(1)
+		go func(p Parser) {
+			defer wg.Done()
+			RegisterParsers(p)
+		}(p)
+	}
+	wg.Wait()
+	RegisterParsersPriority(-10, early)
+
+	if _, ok := Parsers()[0].(*parser); !ok {
+		t.Errorf("first parser is %T, want the standard field parser", Parsers()[0])
+	}
+	f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := Decode(f); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 12 || calls[0] != "early" || calls[11] != "late" {
+		t.Fatalf("parsers called in order %v", calls)
+	}
+	sort.Strings(calls[1:11])
+	for i, name := range calls[1:11] {
+		if name != fmt.Sprint(i) {
+			t.Fatalf("parsers of default priority called %v", calls[1:11])
+		}
+	}
+
+	UnregisterParsers(early, ps[3])
+	calls = nil
+	f.Seek(0, io.SeekStart)
+	if _, err := Decode(f); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 10 || calls[0] == "early" || calls[9] != "late" {
+		t.Errorf("after unregistering, parsers called in order %v", calls)
+	}
+	for _, name := range calls {
+		if name == "3" {
+			t.Errorf("unregistered parser called")
+		}
+	}
+}
+
+func TestExtractPreview(t *testing.T) {
+	encode := func(w, h int) []byte {
+		var b bytes.Buffer
+		if err := jpeg.Encode(&b, image.NewGray(image.Rect(0, 0, w, h)), nil); err != nil {
+			t.Fatal(err)
+		}
+		return b.Bytes()
+	}
+	small, large := encode(16, 8), encode(64, 48)
+	// a lossless JPEG frame, as used for raw image data
+	lossless := []byte{0xFF, 0xD8, 0xFF, 0xC3, 0x00, 0x0B, 0x08, 0x01, 0x00, 0x01, 0x00, 0x01, 0x01, 0x11, 0x00, 0xFF, 0xD9}
+
+	// IFD0 with the small image as its strip and two sub-IFDs, one with
+	// the large image and one with lossless data
+	order := binary.LittleEndian
+	buf := []byte("II*\x00\x08\x00\x00\x00")
+	entry := func(b []byte, id, typ uint16, count, val uint32) []byte {
+		e := make([]byte, 12)
+		order.PutUint16(e, id)
+		order.PutUint16(e[2:], typ)
+		order.PutUint32(e[4:], count)
+		order.PutUint32(e[8:], val)
+		return append(b, e...)
+	}
+	const ifd0, sub0, sub1 = 8, 8 + 2 + 3*12 + 4 + 8, 8 + 2 + 3*12 + 4 + 8 + 30
+	data := uint32(sub1 + 30)
+	buf = append(buf, 3, 0)
+	buf = entry(buf, 0x0111, 4, 1, data)
+	buf = entry(buf, 0x0117, 4, 1, uint32(len(small)))
+	buf = entry(buf, 0x014A, 4, 2, ifd0+2+3*12+4)
+	buf = append(buf, 0, 0, 0, 0)
+	buf = order.AppendUint32(order.AppendUint32(buf, sub0), sub1)
+	for _, img := range []struct {
+		off  uint32
+		data []byte
+	}{{data + uint32(len(small)), large}, {data + uint32(len(small)+len(large)), lossless}} {
+		buf = append(buf, 2, 0)
+		buf = entry(buf, 0x0201, 4, 1, img.off)
+		buf = entry(buf, 0x0202, 4, 1, uint32(len(img.data)))
+		buf = append(buf, 0, 0, 0, 0)
+	}
+	if len(buf) != int(data) {
+		t.Fatalf("bad test data layout: %d bytes before the images, want %d", len(buf), data)
+	}
+	buf = append(append(append(buf, small...), large...), lossless...)
+
+	x, err := Decode(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps := x.Previews()
+	if len(ps) != 2 || ps[0].Source != "IFD0" || ps[1].Source != "SubIFD0" {
+		t.Fatalf("got previews %+v, want the IFD0 and SubIFD0 images", ps)
+	}
+	p, err := ExtractPreview(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p.Data, large) || p.Width != 64 || p.Height != 48 || p.Offset != int64(data)+int64(len(small)) {
+		t.Errorf("got preview %v %dx%d at %d, want the large image", p.Source, p.Width, p.Height, p.Offset)
+	}
+
+	f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	y, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thumb, err := y.JpegThumbnail()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Seek(0, io.SeekStart)
+	if p, err := ExtractPreview(f); err != nil || !bytes.Equal(p.Data, thumb) {
+		t.Errorf("ExtractPreview of a JPEG file: got %v, want its thumbnail", err)
+	}
+	if _, err := ExtractPreview(bytes.NewReader(buf[:data])); err != ErrNoPreview {
+		t.Errorf("got error %v for a file without images, want ErrNoPreview", err)
+	}
+}
+
+// Check that the EXIF data of files with tags without values, as written by
+// some phones, decodes, the empty tags having no value
+func TestZeroCountSamples(t *testing.T) {
+	for _, name := range []string{"corrupt/zero_count_exif.jpg", "corrupt/zero_count.tif"} {
+		f, err := os.Open(filepath.Join(*dataDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, err := Decode(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%v: %v", name, err)
+			continue
+		}
+		if s, err := x.GetString(Make); err != nil || s != "Canon" {
+			t.Errorf("%v: Make = %q, %v, want Canon", name, s, err)
+		}
+		if v, err := x.GetInt64(ISOSpeedRatings); err != nil || v != 100 {
+			t.Errorf("%v: ISOSpeedRatings = %d, %v, want 100", name, v, err)
+		}
+		for _, field := range []FieldName{Model, Orientation, ExposureTime, UserComment, GPSLatitude} {
+			tag, err := x.Get(field)
+			if err != nil {
+				t.Errorf("%v: %v: %v", name, field, err)
+				continue
+			}
+			if tag.Count != 0 || len(tag.Val) != 0 {
+				t.Errorf("%v: %v: got count %d and %d value bytes, want an empty value", name, field, tag.Count, len(tag.Val))
+			}
+		}
+		if _, _, err := x.LatLong(); err == nil {
+			t.Errorf("%v: no error getting an empty latitude", name)
+		}
+		exercise(x)
+	}
+}
+
+func TestDecodeZeroCount(t *testing.T) {
+	// a tag without values for each field of the Exif 2.32 schema
+	dirs := map[IfdID]*tiff.Dir{Ifd0: {}, IfdExif: {}, IfdGPS: {}}
+	names := make([]string, 0, len(Exif232))
+	for name := range Exif232 {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		spec := Exif232[FieldName(name)]
+		id := fieldID(spec.Ifd, FieldName(name))
+		d := dirs[spec.Ifd]
+		d.Tags = append(d.Tags, &tiff.Tag{Id: id, Type: spec.Types[0], Val: []byte{}})
+	}
+	dirs[Ifd0].SubDirs = map[uint16]*tiff.Dir{exifPointer: dirs[IfdExif], gpsPointer: dirs[IfdGPS]}
+	var buf bytes.Buffer
+	if err := tiff.NewTiff(binary.BigEndian, dirs[Ifd0]).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		tag, err := x.Get(FieldName(name))
+		if err != nil {
+			t.Errorf("%v: %v", name, err)
+			continue
+		}
+		if tag.Count != 0 || len(tag.Val) != 0 {
+			t.Errorf("%v: got count %d and %d value bytes, want an empty value", name, tag.Count, len(tag.Val))
+		}
+		if _, err := x.GetInt64(FieldName(name)); err == nil {
+			t.Errorf("%v: no error getting an empty value", name)
+		}
+	}
+	exercise(x)
+}
+
+// fieldID returns the tag ID of the field name of ifd.
+func fieldID(ifd IfdID, name FieldName) uint16 {
+	fields := exifFields
+	if ifd == IfdGPS {
+		fields = gpsFields
+	}
+	for id, n := range fields {
+		if n == name {
+			return id
+		}
+	}
+	panic("unknown field " + name)
+}
+
+func TestFields(t *testing.T) {
+	// IFD0 with Model, Make and an unnamed vendor tag 0x9999
+	x := exifFromIFD(t, Ifd0, exifFields, "0003"+
+		"0110 0002 00000004 58595A00"+
+		"010F 0002 00000004 41424300"+
+		"9999 0003 00000001 002A0000"+
+		"00000000")
+
+	if got, want := x.Fields(), []FieldName{Make, Model}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+	for _, name := range x.Fields() {
+		if !x.Has(name) {
+			t.Errorf("Has(%v) = false, want true", name)
+		}
+	}
+	if x.Has(DateTime) || x.Has("NoSuchField") {
+		t.Error("Has reports absent fields")
+	}
+}
+
+func TestValidateDNG(t *testing.T) {
+	identity := [][2]int64{{1, 1}, {0, 1}, {0, 1}, {0, 1}, {1, 1}, {0, 1}, {0, 1}, {0, 1}, {1, 1}}
+	dng := func(version []byte, raw ...*tiff.Tag) *Exif {
+		x := exifFromTags(t, Ifd0,
+			mustTag(t, 0x00FE, tiff.DTLong, 1),
+			mustTag(t, 0x0106, tiff.DTShort, 6),
+			mustTag(t, 0xC612, tiff.DTByte, version),
+			mustTag(t, 0xC614, tiff.DTAscii, "Canon EOS 5D"),
+			mustTag(t, 0xC621, tiff.DTSRational, identity))
+		x.loadIfdTags(IfdSubIFD, tiff.NewDir(raw...), exifFields, false, false)
+		return x
+	}
+	cfa := []*tiff.Tag{
+		mustTag(t, 0x00FE, tiff.DTLong, 0),
+		mustTag(t, 0x0100, tiff.DTLong, 4000),
+		mustTag(t, 0x0101, tiff.DTLong, 3000),
+		mustTag(t, 0x0106, tiff.DTShort, photometricCFA),
+		mustTag(t, 0x828D, tiff.DTShort, []int{2, 2}),
+		mustTag(t, 0x828E, tiff.DTByte, []byte{0, 1, 1, 2}),
+		mustTag(t, 0xC61D, tiff.DTLong, 16383),
+		mustTag(t, 0xC68D, tiff.DTLong, []int{0, 0, 3000, 4000}),
+	}
+	if errs := dng([]byte{1, 4, 0, 0}, cfa...).ValidateDNG(); len(errs) != 0 {
+		t.Errorf("valid DNG: %v", errs)
+	}
+
+	x := dng([]byte{1, 7, 0, 0},
+		mustTag(t, 0x0100, tiff.DTLong, 4000),
+		mustTag(t, 0x0101, tiff.DTLong, 3000),
+		mustTag(t, 0x0106, tiff.DTShort, photometricCFA),
+		mustTag(t, 0x828D, tiff.DTShort, []int{2, 2}),
+		mustTag(t, 0x828E, tiff.DTByte, []byte{0, 1, 2}),
+		mustTag(t, 0xC61E, tiff.DTRational, [2]int64{1, 1}),
+		mustTag(t, 0xC616, tiff.DTByte, []byte{0, 1, 2, 3}),
+		mustTag(t, 0xC68D, tiff.DTLong, []int{0, 0, 3000, 4010}))
+	var got []string
+	for _, err := range x.ValidateDNG() {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		"exif: unsupported DNG version: the file requires a DNG 1.7.0.0 reader, newer than DNG 1.6.0.0",
+		"exif: field DefaultScale has 1 values, want 2",
+		"exif: CFAPattern has 3 values, want 4 for a 2x2 pattern",
+		"exif: field ColorMatrix1 has 9 values, want 12 for 4 color planes",
+		"exif: ActiveArea [0 0 3000 4010] does not fit in the 4000x3000 raw image",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ValidateDNG() = %q, want %q", got, want)
+	}
+	if errs := x.ValidateDNG(); !errors.Is(errs[0], ErrDNGVersion) {
+		t.Errorf("got error %v, want ErrDNGVersion", errs[0])
+	}
+
+	x = exifFromTags(t, Ifd0, mustTag(t, 0x0106, tiff.DTShort, 2))
+	got = got[:0]
+	for _, err := range x.ValidateDNG() {
+		got = append(got, err.Error())
+	}
+	want = []string{
+		"exif: required field DNGVersion is missing",
+		"exif: required field UniqueCameraModel is missing",
+		"exif: no raw IFD holding a CFA or linear raw image found",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ValidateDNG() = %q, want %q", got, want)
+	}
+}
+
+func TestJFIF(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join(*dataDir, "samples", "raw.exif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decode := func(segs ...[]byte) *Exif {
+		var buf bytes.Buffer
+		buf.Write([]byte{0xFF, 0xD8})
+		for _, seg := range segs {
+			buf.Write(seg)
+		}
+		buf.Write([]byte{0xFF, 0xDA, 0x00, 0x02, 0xFF, 0xD9})
+		x, err := Decode(&buf)
+		if err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		return x
+	}
+
+	x := decode(jpegSeg(0xE1, raw))
+	if _, err := x.JFIF(); err != ErrNoJFIF {
+		t.Errorf("JFIF() error = %v, want ErrNoJFIF", err)
+	}
+	xdpi, ydpi, ok := x.exifDPI()
+	if !ok {
+		t.Fatal("sample has no EXIF resolution")
+	}
+
+	// a 2x1 thumbnail holding a red and a blue pixel
+	jfif := []byte("JFIF\x00\x01\x02\x02\x00\x76\x00\x76\x02\x01\xFF\x00\x00\x00\x00\xFF")
+	jfxx := append([]byte("JFXX\x00\x10"), 0xFF, 0xD8, 0xFF, 0xD9)
+	x = decode(jpegSeg(0xE0, jfif), jpegSeg(0xE0, jfxx), jpegSeg(0xE1, raw))
+	j, err := x.JFIF()
+	if err != nil {
+		t.Fatalf("JFIF: %v", err)
+	}
+	if got, want := j.String(), "JFIF 1.02, 118x118 dpcm, 2x1 thumbnail"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if !bytes.Equal(j.JPEGThumbnail, []byte{0xFF, 0xD8, 0xFF, 0xD9}) {
+		t.Errorf("JPEGThumbnail = %x", j.JPEGThumbnail)
+	}
+	img := j.ThumbnailImage()
+	if r, _, _, _ := img.At(0, 0).RGBA(); r != 0xFFFF {
+		t.Errorf("thumbnail pixel 0 = %v, want red", img.At(0, 0))
+	}
+	if _, _, b, _ := img.At(1, 0).RGBA(); b != 0xFFFF {
+		t.Errorf("thumbnail pixel 1 = %v, want blue", img.At(1, 0))
+	}
+	if jx, _, ok := j.DPI(); !ok || math.Abs(jx-299.72) > 1e-9 {
+		t.Errorf("DPI() = %v, %v", jx, ok)
+	}
+
+	res, err := x.Resolution()
+	if err != nil {
+		t.Fatalf("Resolution: %v", err)
+	}
+	if res.X != xdpi || res.Y != ydpi || res.FromJFIF || res.Mismatch != (math.Round(xdpi/2.54) != 118) {
+		t.Errorf("Resolution() = %+v, EXIF gives %vx%v", res, xdpi, ydpi)
+	}
+
+	// without EXIF resolution, the JFIF densities are used
+	x = decode(jpegSeg(0xE0, jfif), jpegSeg(0xE1, raw))
+	delete(x.main, XResolution)
+	res, err = x.Resolution()
+	if err != nil || !res.FromJFIF || res.Y != 118*2.54 {
+		t.Errorf("Resolution() = %+v, %v, want the JFIF densities", res, err)
+	}
+
+	if _, err := parseJFIF([]byte("JFIF\x00\x01\x02\x00\x00\x01\x00\x01\x02\x02")); err == nil {
+		t.Error("parseJFIF accepted truncated thumbnail data")
+	}
+}
+
+func TestDecodeAlloc(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := new(tiff.Meter)
+	x, err := (&Decoder{Alloc: m.Alloc}).Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Bytes() <= int64(len(x.Raw)) {
+		t.Errorf("counted %d bytes, want more than the %d bytes of tiff data", m.Bytes(), len(x.Raw))
+	}
+
+	for _, max := range []int64{int64(len(x.Raw)) / 2, m.Bytes() - 1} {
+		m := &tiff.Meter{Max: max}
+		_, err := (&Decoder{Lenient: true, Alloc: m.Alloc}).Decode(bytes.NewReader(data))
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("budget %d: got error %v, want ErrLimitExceeded", max, err)
+		}
+	}
+}
+
+func TestRemoveGPSWithin(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lat, long, err := x.LatLong()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// about 111 meters per thousandth of a degree of latitude
+	square := Polygon{{lat - 0.001, long - 0.001}, {lat - 0.001, long + 0.001}, {lat + 0.001, long + 0.001}, {lat + 0.001, long - 0.001}}
+
+	for _, test := range []struct {
+		fences []Geofence
+		want   bool
+	}{
+		{nil, false},
+		{[]Geofence{Circle{lat + 0.001, long, 150}}, true},
+		{[]Geofence{Circle{lat + 0.001, long, 100}}, false},
+		{[]Geofence{Circle{-lat, long, 1000}, square}, true},
+		{[]Geofence{Polygon{{lat + 0.001, long}, {lat + 0.002, long}, {lat + 0.002, long + 0.001}}}, false},
+	} {
+		var out bytes.Buffer
+		removed, err := RemoveGPSWithin(bytes.NewReader(data), &out, test.fences...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if removed != test.want {
+			t.Errorf("fences %v: removed = %v, want %v", test.fences, removed, test.want)
+		}
+		after, err := Decode(bytes.NewReader(out.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := after.Get(GPSLatitude); IsTagNotPresentError(err) != test.want {
+			t.Errorf("fences %v: GPSLatitude error = %v", test.fences, err)
+		}
+		if !test.want && !bytes.Equal(out.Bytes(), data) {
+			t.Errorf("fences %v: image changed", test.fences)
+		}
+	}
+
+	if d := distance(0, 0, 0, 1); math.Abs(d-111195) > 1 {
+		t.Errorf("distance of one degree at the equator = %v", d)
+	}
+
+	// an entry of the GPS IFD with an unknown data type
+	fence := Circle{40.7583, -111.8889, 100}
+	data = gpsJPEG(t)
+	breakEntry(t, data, GPS.AltitudeRef, 2, 0xFF)
+	var out bytes.Buffer
+	if removed, err := RemoveGPSWithin(bytes.NewReader(data), &out, fence); !removed || err != nil {
+		t.Fatalf("corrupt GPS IFD: removed = %v, %v", removed, err)
+	}
+	after, err := (&Decoder{Lenient: true}).Decode(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag, err := after.Get(GPSLatitude); !IsTagNotPresentError(err) {
+		t.Errorf("GPSLatitude still present: %v, %v", tag, err)
+	}
+
+	// a GPS IFD that cannot be decoded, and coordinates that cannot be
+	// parsed
+	data = gpsJPEG(t)
+	breakEntry(t, data, IFD0.GPSInfoIFDPointer, 8, 0xFFFFFF00)
+	if removed, err := RemoveGPSWithin(bytes.NewReader(data), io.Discard, fence); err == nil {
+		t.Errorf("GPS IFD that cannot be decoded: removed = %v, no error", removed)
+	}
+	ifd0 := tiff.NewDir()
+	ifd0.SubDirs = map[uint16]*tiff.Dir{0x8825: tiff.NewDir(mustTag(t, 0x0002, tiff.DTRational, [][2]int64{{1, 0}, {0, 1}, {0, 1}}))}
+	if removed, err := RemoveGPSWithin(bytes.NewReader(tiffJPEG(t, ifd0)), io.Discard, fence); err == nil {
+		t.Errorf("unparsable coordinates: removed = %v, no error", removed)
+	}
+}
+
+func TestSpec(t *testing.T) {
+	spec, ok := Spec(ImageWidth)
+	if !ok || spec.Ifd != Ifd0 || spec.Count != 1 || len(spec.Types) != 2 || spec.Types[0] != tiff.DTShort || spec.Types[1] != tiff.DTLong {
+		t.Errorf("Spec(ImageWidth) = %+v, %v", spec, ok)
+	}
+	spec, ok = Spec(GPSLatitude)
+	if !ok || spec.Ifd != IfdGPS || spec.Count != 3 || len(spec.Types) != 1 || spec.Types[0] != tiff.DTRational {
+		t.Errorf("Spec(GPSLatitude) = %+v, %v", spec, ok)
+	}
+	for name, spec := range fieldSpecs {
+		if _, ok := Describe(name); !ok {
+			t.Errorf("field %v has a spec but no description", name)
+		}
+		if want, ok := Exif232[name]; ok && (want.Ifd != spec.Ifd || want.Count != spec.Count) {
+			t.Errorf("Spec(%v) = %+v, but Exif232 has %+v", name, spec, want)
+		}
+	}
+	if _, ok := Spec("NotAField"); ok {
+		t.Error("Spec of an unknown field succeeded")
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	f, err := os.Open(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	x, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("decoded", "exif", x, "gps", x.Log(GPSLatitude, MakerNote, Model))
+	var got struct {
+		Exif map[string]interface{}
+		GPS  map[string]interface{}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Exif["Model"] == nil || got.Exif["FNumber"] == nil {
+		t.Errorf("LogValue() lacks Model or FNumber: %v", got.Exif)
+	}
+	for name := range got.Exif {
+		if strings.HasPrefix(name, "GPS") || name == string(MakerNote) {
+			t.Errorf("LogValue() logged %v", name)
+		}
+	}
+	if lat, ok := got.GPS["GPSLatitude"].([]interface{}); !ok || len(lat) != 3 {
+		t.Errorf("Log(GPSLatitude) logged %v", got.GPS["GPSLatitude"])
+	}
+	if _, ok := got.GPS[string(MakerNote)]; ok || len(got.GPS) != 2 {
+		t.Errorf("Log(GPSLatitude, MakerNote, Model) logged %v", got.GPS)
+	}
+	attrs := x.Log().LogValue().Group()
+	if len(attrs) <= len(got.Exif) || !sort.SliceIsSorted(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key }) {
+		t.Errorf("Log() logged %v, want all non-binary fields sorted", attrs)
+	}
+}
+
+func TestExtractAuxImages(t *testing.T) {
+	fullBox := func(typ string, payload []byte) []byte {
+		return jxlBox(typ, append([]byte{0, 0, 0, 0}, payload...))
+	}
+	heic := func(primaryOff, matteOff uint32) []byte {
+		infe := func(item byte) []byte {
+			return jxlBox("infe", []byte{2, 0, 0, 0, 0, item, 0, 0, 'h', 'v', 'c', '1', 0})
+		}
+		iinf := fullBox("iinf", append([]byte{0, 2}, append(infe(1), infe(2)...)...))
+		// item 2 is an auxiliary image of item 1
+		iref := fullBox("iref", jxlBox("auxl", []byte{0, 2, 0, 1, 0, 1}))
+		ipco := jxlBox("ipco", bytes.Join([][]byte{
+			fullBox("ispe", []byte{0, 0, 0x02, 0x80, 0, 0, 0x01, 0xE0}),
+			fullBox("ispe", []byte{0, 0, 0x01, 0x40, 0, 0, 0x00, 0xF0}),
+			fullBox("auxC", []byte("urn:com:apple:photo:2018:aux:portraiteffectsmatte\x00")),
+			jxlBox("hvcC", []byte("cfg")),
+		}, nil))
+		ipma := fullBox("ipma", []byte{0, 0, 0, 2, 0, 1, 1, 0x81, 0, 2, 3, 0x82, 0x83, 0x84})
+		iloc := make([]byte, 0, 30)
+		iloc = append(iloc, 0x44, 0x00, 0, 2)
+		iloc = binary.BigEndian.AppendUint16(iloc, 1)
+		iloc = append(iloc, 0, 0, 0, 1)
+		iloc = binary.BigEndian.AppendUint32(iloc, primaryOff)
+		iloc = binary.BigEndian.AppendUint32(iloc, 4)
+		iloc = binary.BigEndian.AppendUint16(iloc, 2)
+		iloc = append(iloc, 0, 0, 0, 1)
+		iloc = binary.BigEndian.AppendUint32(iloc, matteOff)
+		iloc = binary.BigEndian.AppendUint32(iloc, 5)
+		meta := fullBox("meta", bytes.Join([][]byte{
+			fullBox("pitm", []byte{0, 1}), iinf, iref,
+			jxlBox("iprp", append(ipco, ipma...)), fullBox("iloc", iloc),
+		}, nil))
+		f := append(jxlBox("ftyp", []byte("heic\x00\x00\x00\x00mif1heic")), meta...)
+		return append(f, jxlBox("mdat", []byte("PRIMmatte"))...)
+	}
+	n := uint32(len(heic(0, 0)) - len("PRIMmatte"))
+	data := heic(n, n+4)
+
+	imgs, err := ExtractAuxImages(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imgs) != 1 {
+		t.Fatalf("got %d auxiliary images, want 1", len(imgs))
+	}
+	img := imgs[0]
+	if img.Kind != AuxMatte || img.Type != "urn:com:apple:photo:2018:aux:portraiteffectsmatte" || img.Codec != "hvc1" ||
+		img.Item != 2 || img.Of != 1 || img.Width != 320 || img.Height != 240 ||
+		img.Offset != int64(n+4) || string(img.Data) != "matte" || string(img.Config) != "cfg" {
+		t.Errorf("got %+v", img)
+	}
+
+	if _, _, _, err := heifPrimaryImage(data[bytes.Index(data, []byte("meta"))+8:]); err != nil {
+		t.Errorf("heifPrimaryImage: %v", err)
+	}
+	if _, err := ExtractAuxImages(bytes.NewReader(data[:len(data)-4])); err == nil {
+		t.Error("no error for truncated item data")
+	}
+	if _, err := ExtractAuxImages(bytes.NewReader([]byte{0xFF, 0xD8, 0xFF, 0xD9})); err != ErrNoAuxImages {
+		t.Errorf("ExtractAuxImages of a JPEG file = %v, want ErrNoAuxImages", err)
+	}
+	for k, want := range map[string]AuxKind{
+		"urn:mpeg:hevc:2015:auxid:2":                    AuxDepth,
+		"urn:mpeg:mpegB:cicp:systems:auxiliary:alpha":   AuxAlpha,
+		"urn:com:apple:photo:2020:aux:hdrgainmap":       AuxGainMap,
+		"urn:com:apple:photo:2018:aux:semanticskymatte": AuxMatte,
+		"urn:example:thing":                             AuxOther,
+	} {
+		if got := auxKind(k); got != want {
+			t.Errorf("auxKind(%q) = %v, want %v", k, got, want)
+		}
+	}
+}
+
+func TestWrongSegmentLength(t *testing.T) {
+	d := tiff.NewDir()
+	for _, tag := range []struct {
+		id  uint16
+		val string
+	}{{0x010E, "a description stored after the IFD"}, {0x010F, "Phone"}} {
+		tg, err := tiff.NewTag(tag.id, tiff.DTAscii, tag.val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Tags = append(d.Tags, tg)
+	}
+	var buf bytes.Buffer
+	if err := tiff.NewTiff(binary.BigEndian, d).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	payload := append([]byte("Exif\x00\x00"), buf.Bytes()...)
+	comment := []byte("a comment following the EXIF segment")
+
+	// jpeg returns a JPEG stream whose EXIF segment declares delta bytes
+	// more than it holds.
+	jpeg := func(delta int) []byte {
+		n := len(payload) + 2 + delta
+		b := append([]byte{0xFF, 0xD8, 0xFF, jpeg_APP1, byte(n >> 8), byte(n)}, payload...)
+		b = append(b, 0xFF, jpeg_COM, 0, byte(len(comment)+2))
+		return append(append(b, comment...), 0xFF, jpeg_SOS)
+	}
+	for _, tt := range []struct {
+		name  string
+		delta int
+		warn  bool
+	}{
+		{"exact", 0, false},
+		{"short", -10, true},
+		{"long", 10, true},
+		{"past end of file", 1000, true},
+	} {
+		x, err := (&Decoder{Lenient: true}).Decode(bytes.NewReader(jpeg(tt.delta)))
+		if err != nil {
+			t.Errorf("%v: %v", tt.name, err)
+			continue
+		}
+		if s, err := x.GetString(ImageDescription); err != nil || s != "a description stored after the IFD" {
+			t.Errorf("%v: ImageDescription = %q, %v", tt.name, s, err)
+		}
+		if s, err := x.GetString(Make); err != nil || s != "Phone" {
+			t.Errorf("%v: Make = %q, %v", tt.name, s, err)
+		}
+		if !bytes.Equal(x.Raw, buf.Bytes()) {
+			t.Errorf("%v: got %d bytes of tiff data, want %d", tt.name, len(x.Raw), buf.Len())
+		}
+		if got := len(x.Warnings()) > 0; got != tt.warn {
+			t.Errorf("%v: warnings %v, want warnings %v", tt.name, x.Warnings(), tt.warn)
+		}
+		if segs := x.Segments(); len(segs) != 1 || !bytes.Equal(segs[0].Data, comment) {
+			t.Errorf("%v: got segments %v, want the comment", tt.name, segs)
+		}
+	}
+
+	if _, err := Decode(bytes.NewReader(jpeg(1000))); err == nil {
+		t.Error("strict decoding of an EXIF segment exceeding the file succeeded")
+	}
+}
+
+func TestDecoderOrder(t *testing.T) {
+	tag, err := tiff.NewTag(0x010F, tiff.DTAscii, "Camera")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tiff.NewTiff(binary.BigEndian, tiff.NewDir(tag)).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	// the byte order mark of the header is corrupted
+	data := append([]byte("Exif\x00\x00II"), buf.Bytes()[2:]...)
+	if _, err := Decode(bytes.NewReader(data)); !errors.Is(err, tiff.ErrOrderMismatch) {
+		t.Errorf("Decode error = %v, want tiff.ErrOrderMismatch", err)
+	}
+	x, err := (&Decoder{Order: binary.BigEndian}).Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := x.GetString(Make); err != nil || s != "Camera" {
+		t.Errorf("Make = %q, %v", s, err)
+	}
+}
@@ -0,0 +1,459 @@
+package exif
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// exifToolNames maps field names to the tag names used by ExifTool, where
+// they differ.
+var exifToolNames = map[FieldName]string{
+	ImageLength:                      "ImageHeight",
+	DateTime:                         "ModifyDate",
+	DateTimeDigitized:                "CreateDate",
+	ISOSpeedRatings:                  "ISO",
+	ExposureBiasValue:                "ExposureCompensation",
+	PixelXDimension:                  "ExifImageWidth",
+	PixelYDimension:                  "ExifImageHeight",
+	FocalLengthIn35mmFilm:            "FocalLengthIn35mmFormat",
+	ThumbJPEGInterchangeFormat:       "ThumbnailOffset",
+	ThumbJPEGInterchangeFormatLength: "ThumbnailLength",
+	GPSSatelites:                     "GPSSatellites",
+	ThumbStripOffsets:                "StripOffsets",
+	ThumbRowsPerStrip:                "RowsPerStrip",
+	ThumbStripByteCounts:             "StripByteCounts",
+	InteroperabilityIndex:            "InteropIndex",
+	InteroperabilityVersion:          "InteropVersion",
+	NewSubfileType:                   "SubfileType",
+	SubfileType:                      "OldSubfileType",
+	Threshholding:                    "Thresholding",
+	ModelPixelScale:                  "PixelScale",
+	ModelTiepoint:                    "ModelTiePoint",
+	ModelTransformation:              "ModelTransform",
+	CameraOwnerName:                  "OwnerName",
+	BodySerialNumber:                 "SerialNumber",
+}
+
+// exifToolThumbFields maps the IFD1 fields that ExifTool names like their
+// IFD0 counterparts to those.
+var exifToolThumbFields = map[FieldName]FieldName{
+	ThumbImageWidth:                ImageWidth,
+	ThumbImageLength:               ImageLength,
+	ThumbBitsPerSample:             BitsPerSample,
+	ThumbCompression:               Compression,
+	ThumbPhotometricInterpretation: PhotometricInterpretation,
+	ThumbOrientation:               Orientation,
+	ThumbSamplesPerPixel:           SamplesPerPixel,
+	ThumbXResolution:               XResolution,
+	ThumbYResolution:               YResolution,
+	ThumbPlanarConfiguration:       PlanarConfiguration,
+	ThumbResolutionUnit:            ResolutionUnit,
+	ThumbYCbCrSubSampling:          YCbCrSubSampling,
+	ThumbYCbCrPositioning:          YCbCrPositioning,
+}
+
+// exifToolOmitted holds the fields ExifTool does not print by default.
+var exifToolOmitted = map[FieldName]bool{
+	ExifIFDPointer:             true,
+	GPSInfoIFDPointer:          true,
+	InteroperabilityIFDPointer: true,
+	SubIFDs:                    true,
+	GeoKeyDirectory:            true,
+	GeoDoubleParams:            true,
+	GeoASCIIParams:             true,
+	MakerNote:                  true,
+}
+
+// exifToolTables holds the ExifTool print conversions of enumerated fields.
+var exifToolTables = map[FieldName]map[int64]string{
+	Compression:               {1: "Uncompressed", 2: "CCITT 1D", 5: "LZW", 6: "JPEG (old-style)", 7: "JPEG", 8: "Adobe Deflate", 32773: "PackBits"},
+	PhotometricInterpretation: {0: "WhiteIsZero", 1: "BlackIsZero", 2: "RGB", 3: "RGB Palette", 5: "CMYK", 6: "YCbCr", 32803: "Color Filter Array", 34892: "Linear Raw"},
+	Orientation: {
+		1: "Horizontal (normal)",
+		2: "Mirror horizontal",
+		3: "Rotate 180",
+		4: "Mirror vertical",
+		5: "Mirror horizontal and rotate 270 CW",
+		6: "Rotate 90 CW",
+		7: "Mirror horizontal and rotate 90 CW",
+		8: "Rotate 270 CW",
+	},
+	PlanarConfiguration:      {1: "Chunky", 2: "Planar"},
+	ResolutionUnit:           {1: "None", 2: "inches", 3: "cm"},
+	FocalPlaneResolutionUnit: {1: "None", 2: "inches", 3: "cm", 4: "mm", 5: "um"},
+	YCbCrPositioning:         {1: "Centered", 2: "Co-sited"},
+	ColorSpace:               {1: "sRGB", 2: "Adobe RGB", 0xFFFD: "Wide Gamut RGB", 0xFFFE: "ICC Profile", 0xFFFF: "Uncalibrated"},
+	ExposureProgram: {
+		0: "Not Defined",
+		1: "Manual",
+		2: "Program AE",
+		3: "Aperture-priority AE",
+		4: "Shutter speed priority AE",
+		5: "Creative (Slow speed)",
+		6: "Action (High speed)",
+		7: "Portrait",
+		8: "Landscape",
+	},
+	MeteringMode: {0: "Unknown", 1: "Average", 2: "Center-weighted average", 3: "Spot", 4: "Multi-spot", 5: "Multi-segment", 6: "Partial", 255: "Other"},
+	LightSource: {
+		0: "Unknown", 1: "Daylight", 2: "Fluorescent", 3: "Tungsten (Incandescent)", 4: "Flash",
+		9: "Fine Weather", 10: "Cloudy", 11: "Shade", 12: "Daylight Fluorescent", 13: "Day White Fluorescent",
+		14: "Cool White Fluorescent", 15: "White Fluorescent", 16: "Warm White Fluorescent",
+		17: "Standard Light A", 18: "Standard Light B", 19: "Standard Light C",
+		20: "D55", 21: "D65", 22: "D75", 23: "D50", 24: "ISO Studio Tungsten", 255: "Other",
+	},
+	Flash: {
+		0x00: "No Flash",
+		0x01: "Fired",
+		0x05: "Fired, Return not detected",
+		0x07: "Fired, Return detected",
+		0x08: "On, Did not fire",
+		0x09: "On, Fired",
+		0x0D: "On, Return not detected",
+		0x0F: "On, Return detected",
+		0x10: "Off, Did not fire",
+		0x14: "Off, Did not fire, Return not detected",
+		0x18: "Auto, Did not fire",
+		0x19: "Auto, Fired",
+		0x1D: "Auto, Fired, Return not detected",
+		0x1F: "Auto, Fired, Return detected",
+		0x20: "No flash function",
+		0x30: "Off, No flash function",
+		0x41: "Fired, Red-eye reduction",
+		0x45: "Fired, Red-eye reduction, Return not detected",
+		0x47: "Fired, Red-eye reduction, Return detected",
+		0x49: "On, Red-eye reduction",
+		0x4D: "On, Red-eye reduction, Return not detected",
+		0x4F: "On, Red-eye reduction, Return detected",
+		0x50: "Off, Red-eye reduction",
+		0x58: "Auto, Did not fire, Red-eye reduction",
+		0x59: "Auto, Fired, Red-eye reduction",
+		0x5D: "Auto, Fired, Red-eye reduction, Return not detected",
+		0x5F: "Auto, Fired, Red-eye reduction, Return detected",
+	},
+	SensingMethod:        {1: "Not defined", 2: "One-chip color area", 3: "Two-chip color area", 4: "Three-chip color area", 5: "Color sequential area", 7: "Trilinear", 8: "Color sequential linear"},
+	CustomRendered:       {0: "Normal", 1: "Custom"},
+	ExposureMode:         {0: "Auto", 1: "Manual", 2: "Auto bracket"},
+	WhiteBalance:         {0: "Auto", 1: "Manual"},
+	SceneCaptureType:     {0: "Standard", 1: "Landscape", 2: "Portrait", 3: "Night"},
+	GainControl:          {0: "None", 1: "Low gain up", 2: "High gain up", 3: "Low gain down", 4: "High gain down"},
+	Contrast:             {0: "Normal", 1: "Low", 2: "High"},
+	Saturation:           {0: "Normal", 1: "Low", 2: "High"},
+	Sharpness:            {0: "Normal", 1: "Soft", 2: "Hard"},
+	SubjectDistanceRange: {0: "Unknown", 1: "Macro", 2: "Close", 3: "Distant"},
+	GPSAltitudeRef:       {0: "Above Sea Level", 1: "Below Sea Level"},
+	GPSDifferential:      {0: "No Correction", 1: "Differential Corrected"},
+}
+
+// exifToolStrings holds the ExifTool print conversions of enumerated string
+// fields.
+var exifToolStrings = map[FieldName]map[string]string{
+	GPSLatitudeRef:      {"N": "North", "S": "South"},
+	GPSLongitudeRef:     {"E": "East", "W": "West"},
+	GPSDestLatitudeRef:  {"N": "North", "S": "South"},
+	GPSDestLongitudeRef: {"E": "East", "W": "West"},
+	GPSStatus:           {"A": "Measurement Active", "V": "Measurement Void"},
+	GPSMeasureMode:      {"2": "2-Dimensional Measurement", "3": "3-Dimensional Measurement"},
+	GPSSpeedRef:         {"K": "km/h", "M": "mph", "N": "knots"},
+	GPSTrackRef:         {"T": "True North", "M": "Magnetic North"},
+	GPSImgDirectionRef:  {"T": "True North", "M": "Magnetic North"},
+	GPSDestBearingRef:   {"T": "True North", "M": "Magnetic North"},
+	GPSDestDistanceRef:  {"K": "Kilometers", "M": "Miles", "N": "Nautical Miles"},
+}
+
+// ExifToolFields returns the fields of x keyed by the tag names ExifTool
+// uses, with values formatted like ExifTool prints them by default (e.g.
+// "1/250" for ExposureTime and "Rotate 90 CW" for Orientation). Numeric
+// values are json.Number values, and all other values strings. Unknown tags
+// and fields that ExifTool does not print by default are omitted. Like
+// ExifTool, the IFD1 fields shared with IFD0 (e.g. ThumbCompression) use the
+// name of the IFD0 field, which takes precedence if both are present.
+func (x *Exif) ExifToolFields() map[string]interface{} {
+	m := map[string]interface{}{}
+	for name, f := range x.main {
+		if exifToolOmitted[name] || strings.HasPrefix(string(name), UnknownPrefix) {
+			continue
+		}
+		if base, ok := exifToolThumbFields[name]; ok {
+			if _, ok := x.main[base]; ok {
+				continue
+			}
+			name = base
+		}
+		key := exifToolNames[name]
+		if key == "" {
+			key = string(name)
+		}
+		m[key] = x.exifToolValue(name, f.Tag)
+	}
+	return m
+}
+
+// MarshalExifTool returns x encoded like the output of "exiftool -j": a JSON
+// array holding a single object with the EXIF fields (see ExifToolFields)
+// and the SourceFile key set to sourceFile.
+func (x *Exif) MarshalExifTool(sourceFile string) ([]byte, error) {
+	m := x.ExifToolFields()
+	m["SourceFile"] = sourceFile
+	return json.MarshalIndent([]map[string]interface{}{m}, "", "  ")
+}
+
+func (x *Exif) exifToolValue(name FieldName, tag *tiff.Tag) interface{} {
+	if tag.Count == 0 {
+		return ""
+	}
+	if table, ok := exifToolTables[name]; ok {
+		if v, err := tag.Int64(0); err == nil {
+			if s, ok := table[v]; ok {
+				return s
+			}
+			return fmt.Sprintf("Unknown (%d)", v)
+		}
+	}
+	if table, ok := exifToolStrings[name]; ok {
+		if s, err := tag.StringVal(); err == nil {
+			if v, ok := table[strings.TrimSpace(s)]; ok {
+				return v
+			}
+		}
+	}
+
+	switch name {
+	case ExposureTime:
+		if v, ok := ratValue(tag, 0); ok {
+			return exposureTimeString(v)
+		}
+	case ShutterSpeedValue:
+		if v, ok := ratValue(tag, 0); ok {
+			return exposureTimeString(math.Pow(2, -v))
+		}
+	case FNumber:
+		if v, ok := ratValue(tag, 0); ok {
+			return number("%.1f", v)
+		}
+	case ApertureValue, MaxApertureValue:
+		if v, ok := ratValue(tag, 0); ok {
+			return number("%.1f", math.Pow(2, v/2))
+		}
+	case FocalLength:
+		if v, ok := ratValue(tag, 0); ok {
+			return fmt.Sprintf("%.1f mm", v)
+		}
+	case FocalLengthIn35mmFilm:
+		if v, err := tag.Int64(0); err == nil {
+			return fmt.Sprintf("%d mm", v)
+		}
+	case ExposureBiasValue:
+		if num, den, err := tag.SignedRat(0); err == nil {
+			return fractionString(num, den)
+		}
+	case SubjectDistance:
+		if v, ok := ratValue(tag, 0); ok {
+			return fmt.Sprintf("%s m", strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	case GPSLatitude, GPSLongitude, GPSDestLatitude, GPSDestLongitude:
+		return x.exifToolCoord(name, tag)
+	case GPSAltitude:
+		if v, ok := ratValue(tag, 0); ok {
+			s := fmt.Sprintf("%s m", trimZero(fmt.Sprintf("%.1f", v)))
+			if ref, err := x.intField(GPSAltitudeRef); err == nil {
+				s += " " + exifToolTables[GPSAltitudeRef][int64(ref)]
+			}
+			return s
+		}
+	case GPSHPositioningError:
+		if v, ok := ratValue(tag, 0); ok {
+			return fmt.Sprintf("%s m", strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	case GPSTimeStamp:
+		var hms [3]float64
+		for i := range hms {
+			v, ok := ratValue(tag, i)
+			if !ok {
+				return exifToolDefault(tag)
+			}
+			hms[i] = v
+		}
+		return fmt.Sprintf("%02d:%02d:%s", int(hms[0]), int(hms[1]), secondsString(hms[2]))
+	case GPSVersionID:
+		parts := make([]string, tag.Count)
+		for i := range parts {
+			v, err := tag.Int(i)
+			if err != nil {
+				return exifToolDefault(tag)
+			}
+			parts[i] = strconv.Itoa(v)
+		}
+		return strings.Join(parts, ".")
+	case ComponentsConfiguration:
+		names := []string{"-", "Y", "Cb", "Cr", "R", "G", "B"}
+		parts := make([]string, len(tag.Val))
+		for i, b := range tag.Val {
+			if int(b) < len(names) {
+				parts[i] = names[b]
+			} else {
+				parts[i] = strconv.Itoa(int(b))
+			}
+		}
+		return strings.Join(parts, ", ")
+	case ExifVersion, FlashpixVersion, InteroperabilityIndex, InteroperabilityVersion:
+		return strings.TrimRight(string(tag.Val), "\x00")
+	case FileSource:
+		if len(tag.Val) > 0 && tag.Val[0] == 3 {
+			return "Digital Camera"
+		}
+	case SceneType:
+		if len(tag.Val) > 0 && tag.Val[0] == 1 {
+			return "Directly photographed"
+		}
+	case UserComment, GPSProcessingMethod, GPSAreaInformation:
+		// the first 8 bytes identify the character code
+		if len(tag.Val) >= 8 {
+			return strings.TrimRight(string(tag.Val[8:]), "\x00 ")
+		}
+	case XPTitle, XPComment, XPAuthor, XPKeywords, XPSubject:
+		u := make([]uint16, len(tag.Val)/2)
+		for i := range u {
+			u[i] = uint16(tag.Val[2*i]) | uint16(tag.Val[2*i+1])<<8
+		}
+		return strings.TrimRight(string(utf16.Decode(u)), "\x00")
+	}
+
+	return exifToolDefault(tag)
+}
+
+// exifToolDefault formats tag without a field specific print conversion.
+func exifToolDefault(tag *tiff.Tag) interface{} {
+	switch tag.Format() {
+	case tiff.StringVal:
+		s, _ := tag.StringVal()
+		return strings.TrimSpace(s)
+	case tiff.UndefVal, tiff.OtherVal:
+		return fmt.Sprintf("(Binary data %d bytes, use -b option to extract)", len(tag.Val))
+	}
+	parts := make([]string, tag.Count)
+	for i := range parts {
+		switch tag.Format() {
+		case tiff.IntVal:
+			v, _ := tag.Int64(i)
+			parts[i] = strconv.FormatInt(v, 10)
+		case tiff.RatVal:
+			v, ok := ratValue(tag, i)
+			if !ok {
+				parts[i] = "undef"
+				continue
+			}
+			// ExifTool prints rationals with 10 significant digits
+			parts[i] = strconv.FormatFloat(v, 'g', 10, 64)
+		case tiff.FloatVal:
+			v, _ := tag.Float(i)
+			parts[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	if len(parts) == 1 && parts[0] != "undef" {
+		return json.Number(parts[0])
+	}
+	return strings.Join(parts, " ")
+}
+
+// exifToolCoord formats a GPS coordinate like `52 deg 30' 36.00" N`.
+func (x *Exif) exifToolCoord(name FieldName, tag *tiff.Tag) interface{} {
+	var dms [3]float64
+	for i := range dms {
+		v, ok := ratValue(tag, i)
+		if !ok {
+			return exifToolDefault(tag)
+		}
+		dms[i] = v
+	}
+	// normalize fractional degrees and minutes
+	deg := dms[0] + dms[1]/60 + dms[2]/3600
+	d := math.Floor(deg)
+	m := math.Floor((deg - d) * 60)
+	s := (deg - d - m/60) * 3600
+	if s >= 59.995 {
+		s = 0
+		m++
+	}
+	str := fmt.Sprintf(`%d deg %d' %.2f"`, int(d), int(m), s)
+
+	refName := map[FieldName]FieldName{
+		GPSLatitude:      GPSLatitudeRef,
+		GPSLongitude:     GPSLongitudeRef,
+		GPSDestLatitude:  GPSDestLatitudeRef,
+		GPSDestLongitude: GPSDestLongitudeRef,
+	}[name]
+	if ref, err := x.stringField(refName); err == nil && strings.TrimSpace(ref) != "" {
+		str += " " + strings.TrimSpace(ref)
+	}
+	return str
+}
+
+// ratValue returns the i'th rational value of tag as a float, reporting
+// false for missing values and zero denominators.
+func ratValue(tag *tiff.Tag, i int) (float64, bool) {
+	if i >= int(tag.Count) {
+		return 0, false
+	}
+	num, den, err := tag.Rat2(i)
+	if err != nil || den == 0 {
+		return 0, false
+	}
+	return float64(num) / float64(den), true
+}
+
+// exposureTimeString formats an exposure time in seconds like ExifTool.
+func exposureTimeString(secs float64) interface{} {
+	if secs > 0 && secs < 0.25001 {
+		return fmt.Sprintf("1/%d", int(0.5+1/secs))
+	}
+	return number("%.1f", secs)
+}
+
+// fractionString formats v = num/den as a signed fraction like "+1/3" or
+// "-2/3", or an integer, like ExifTool does for exposure compensation.
+func fractionString(num, den int64) interface{} {
+	if den == 0 {
+		return "undef"
+	}
+	v := float64(num) / float64(den)
+	if v == 0 {
+		return json.Number("0")
+	}
+	sign := "+"
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	for _, d := range []float64{1, 2, 3} {
+		if n := math.Round(v * d); math.Abs(n/d-v) < 1e-4 {
+			if d == 1 {
+				return sign + strconv.Itoa(int(n))
+			}
+			return fmt.Sprintf("%s%d/%d", sign, int(n), int(d))
+		}
+	}
+	return sign + strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func secondsString(s float64) string {
+	if s == math.Trunc(s) {
+		return fmt.Sprintf("%02d", int(s))
+	}
+	return trimZero(fmt.Sprintf("%05.2f", s))
+}
+
+// number returns v formatted with format as a json.Number, with a trailing
+// ".0" removed.
+func number(format string, v float64) json.Number {
+	return json.Number(trimZero(fmt.Sprintf(format, v)))
+}
+
+func trimZero(s string) string {
+	return strings.TrimSuffix(s, ".0")
+}
@@ -1,10 +1,8 @@
-package exif
+// Code generated by gen_fields.go from fields.json; DO NOT EDIT.
 
-type FieldName string
+package exif
 
-// UnknownPrefix is used as the first part of field names for decoded tags for
-// which there is no known/supported EXIF field.
-const UnknownPrefix = "UnknownTag_"
+import "github.com/rwcarlsen/goexif/tiff"
 
 // Primary EXIF fields
 const (
@@ -31,6 +29,7 @@ const (
 	ExifIFDPointer             FieldName = "ExifIFDPointer"
 	GPSInfoIFDPointer          FieldName = "GPSInfoIFDPointer"
 	InteroperabilityIFDPointer FieldName = "InteroperabilityIFDPointer"
+	SubIFDs                    FieldName = "SubIFDs"
 	ExifVersion                FieldName = "ExifVersion"
 	FlashpixVersion            FieldName = "FlashpixVersion"
 	ColorSpace                 FieldName = "ColorSpace"
@@ -46,6 +45,9 @@ const (
 	SubSecTime                 FieldName = "SubSecTime"
 	SubSecTimeOriginal         FieldName = "SubSecTimeOriginal"
 	SubSecTimeDigitized        FieldName = "SubSecTimeDigitized"
+	OffsetTime                 FieldName = "OffsetTime"
+	OffsetTimeOriginal         FieldName = "OffsetTimeOriginal"
+	OffsetTimeDigitized        FieldName = "OffsetTimeDigitized"
 	ImageUniqueID              FieldName = "ImageUniqueID"
 	ExposureTime               FieldName = "ExposureTime"
 	FNumber                    FieldName = "FNumber"
@@ -87,8 +89,61 @@ const (
 	Sharpness                  FieldName = "Sharpness"
 	DeviceSettingDescription   FieldName = "DeviceSettingDescription"
 	SubjectDistanceRange       FieldName = "SubjectDistanceRange"
+	CameraOwnerName            FieldName = "CameraOwnerName"
+	BodySerialNumber           FieldName = "BodySerialNumber"
 	LensMake                   FieldName = "LensMake"
 	LensModel                  FieldName = "LensModel"
+	LensSerialNumber           FieldName = "LensSerialNumber"
+)
+
+// TIFF 6.0 baseline and extension fields not used by EXIF, read from IFD0 of
+// plain TIFF files
+const (
+	NewSubfileType        FieldName = "NewSubfileType"
+	SubfileType           FieldName = "SubfileType"
+	Threshholding         FieldName = "Threshholding"
+	CellWidth             FieldName = "CellWidth"
+	CellLength            FieldName = "CellLength"
+	FillOrder             FieldName = "FillOrder"
+	DocumentName          FieldName = "DocumentName"
+	StripOffsets          FieldName = "StripOffsets"
+	RowsPerStrip          FieldName = "RowsPerStrip"
+	StripByteCounts       FieldName = "StripByteCounts"
+	MinSampleValue        FieldName = "MinSampleValue"
+	MaxSampleValue        FieldName = "MaxSampleValue"
+	PageName              FieldName = "PageName"
+	XPosition             FieldName = "XPosition"
+	YPosition             FieldName = "YPosition"
+	FreeOffsets           FieldName = "FreeOffsets"
+	FreeByteCounts        FieldName = "FreeByteCounts"
+	GrayResponseUnit      FieldName = "GrayResponseUnit"
+	GrayResponseCurve     FieldName = "GrayResponseCurve"
+	T4Options             FieldName = "T4Options"
+	T6Options             FieldName = "T6Options"
+	PageNumber            FieldName = "PageNumber"
+	TransferFunction      FieldName = "TransferFunction"
+	HostComputer          FieldName = "HostComputer"
+	Predictor             FieldName = "Predictor"
+	WhitePoint            FieldName = "WhitePoint"
+	PrimaryChromaticities FieldName = "PrimaryChromaticities"
+	ColorMap              FieldName = "ColorMap"
+	HalftoneHints         FieldName = "HalftoneHints"
+	TileWidth             FieldName = "TileWidth"
+	TileLength            FieldName = "TileLength"
+	TileOffsets           FieldName = "TileOffsets"
+	TileByteCounts        FieldName = "TileByteCounts"
+	InkSet                FieldName = "InkSet"
+	InkNames              FieldName = "InkNames"
+	NumberOfInks          FieldName = "NumberOfInks"
+	DotRange              FieldName = "DotRange"
+	TargetPrinter         FieldName = "TargetPrinter"
+	ExtraSamples          FieldName = "ExtraSamples"
+	SampleFormat          FieldName = "SampleFormat"
+	SMinSampleValue       FieldName = "SMinSampleValue"
+	SMaxSampleValue       FieldName = "SMaxSampleValue"
+	TransferRange         FieldName = "TransferRange"
+	YCbCrCoefficients     FieldName = "YCbCrCoefficients"
+	ReferenceBlackWhite   FieldName = "ReferenceBlackWhite"
 )
 
 // Windows-specific tags
@@ -100,58 +155,203 @@ const (
 	XPSubject  FieldName = "XPSubject"
 )
 
-// thumbnail fields
+// GeoTIFF tags, see tiff.DecodeGeoTIFF
+const (
+	ModelPixelScale     FieldName = "ModelPixelScale"
+	ModelTiepoint       FieldName = "ModelTiepoint"
+	ModelTransformation FieldName = "ModelTransformation"
+	GeoKeyDirectory     FieldName = "GeoKeyDirectory"
+	GeoDoubleParams     FieldName = "GeoDoubleParams"
+	GeoASCIIParams      FieldName = "GeoASCIIParams"
+)
+
+// DNG tags, read from IFD0 and the raw IFD (IFD0 or a SubIFD) of DNG
+// files, see ValidateDNG
+const (
+	CFARepeatPatternDim          FieldName = "CFARepeatPatternDim"
+	CFAPattern2                  FieldName = "CFAPattern2"
+	DNGVersion                   FieldName = "DNGVersion"
+	DNGBackwardVersion           FieldName = "DNGBackwardVersion"
+	UniqueCameraModel            FieldName = "UniqueCameraModel"
+	LocalizedCameraModel         FieldName = "LocalizedCameraModel"
+	CFAPlaneColor                FieldName = "CFAPlaneColor"
+	CFALayout                    FieldName = "CFALayout"
+	LinearizationTable           FieldName = "LinearizationTable"
+	BlackLevelRepeatDim          FieldName = "BlackLevelRepeatDim"
+	BlackLevel                   FieldName = "BlackLevel"
+	BlackLevelDeltaH             FieldName = "BlackLevelDeltaH"
+	BlackLevelDeltaV             FieldName = "BlackLevelDeltaV"
+	WhiteLevel                   FieldName = "WhiteLevel"
+	DefaultScale                 FieldName = "DefaultScale"
+	DefaultCropOrigin            FieldName = "DefaultCropOrigin"
+	DefaultCropSize              FieldName = "DefaultCropSize"
+	ColorMatrix1                 FieldName = "ColorMatrix1"
+	ColorMatrix2                 FieldName = "ColorMatrix2"
+	CameraCalibration1           FieldName = "CameraCalibration1"
+	CameraCalibration2           FieldName = "CameraCalibration2"
+	ReductionMatrix1             FieldName = "ReductionMatrix1"
+	ReductionMatrix2             FieldName = "ReductionMatrix2"
+	AnalogBalance                FieldName = "AnalogBalance"
+	AsShotNeutral                FieldName = "AsShotNeutral"
+	AsShotWhiteXY                FieldName = "AsShotWhiteXY"
+	BaselineExposure             FieldName = "BaselineExposure"
+	BaselineNoise                FieldName = "BaselineNoise"
+	BaselineSharpness            FieldName = "BaselineSharpness"
+	BayerGreenSplit              FieldName = "BayerGreenSplit"
+	LinearResponseLimit          FieldName = "LinearResponseLimit"
+	CameraSerialNumber           FieldName = "CameraSerialNumber"
+	DNGLensInfo                  FieldName = "DNGLensInfo"
+	ChromaBlurRadius             FieldName = "ChromaBlurRadius"
+	AntiAliasStrength            FieldName = "AntiAliasStrength"
+	ShadowScale                  FieldName = "ShadowScale"
+	DNGPrivateData               FieldName = "DNGPrivateData"
+	MakerNoteSafety              FieldName = "MakerNoteSafety"
+	CalibrationIlluminant1       FieldName = "CalibrationIlluminant1"
+	CalibrationIlluminant2       FieldName = "CalibrationIlluminant2"
+	BestQualityScale             FieldName = "BestQualityScale"
+	RawDataUniqueID              FieldName = "RawDataUniqueID"
+	OriginalRawFileName          FieldName = "OriginalRawFileName"
+	OriginalRawFileData          FieldName = "OriginalRawFileData"
+	ActiveArea                   FieldName = "ActiveArea"
+	MaskedAreas                  FieldName = "MaskedAreas"
+	AsShotICCProfile             FieldName = "AsShotICCProfile"
+	AsShotPreProfileMatrix       FieldName = "AsShotPreProfileMatrix"
+	CurrentICCProfile            FieldName = "CurrentICCProfile"
+	CurrentPreProfileMatrix      FieldName = "CurrentPreProfileMatrix"
+	ColorimetricReference        FieldName = "ColorimetricReference"
+	CameraCalibrationSignature   FieldName = "CameraCalibrationSignature"
+	ProfileCalibrationSignature  FieldName = "ProfileCalibrationSignature"
+	ExtraCameraProfiles          FieldName = "ExtraCameraProfiles"
+	AsShotProfileName            FieldName = "AsShotProfileName"
+	NoiseReductionApplied        FieldName = "NoiseReductionApplied"
+	ProfileName                  FieldName = "ProfileName"
+	ProfileHueSatMapDims         FieldName = "ProfileHueSatMapDims"
+	ProfileHueSatMapData1        FieldName = "ProfileHueSatMapData1"
+	ProfileHueSatMapData2        FieldName = "ProfileHueSatMapData2"
+	ProfileToneCurve             FieldName = "ProfileToneCurve"
+	ProfileEmbedPolicy           FieldName = "ProfileEmbedPolicy"
+	ProfileCopyright             FieldName = "ProfileCopyright"
+	ForwardMatrix1               FieldName = "ForwardMatrix1"
+	ForwardMatrix2               FieldName = "ForwardMatrix2"
+	PreviewApplicationName       FieldName = "PreviewApplicationName"
+	PreviewApplicationVersion    FieldName = "PreviewApplicationVersion"
+	PreviewSettingsName          FieldName = "PreviewSettingsName"
+	PreviewSettingsDigest        FieldName = "PreviewSettingsDigest"
+	PreviewColorSpace            FieldName = "PreviewColorSpace"
+	PreviewDateTime              FieldName = "PreviewDateTime"
+	RawImageDigest               FieldName = "RawImageDigest"
+	OriginalRawFileDigest        FieldName = "OriginalRawFileDigest"
+	SubTileBlockSize             FieldName = "SubTileBlockSize"
+	RowInterleaveFactor          FieldName = "RowInterleaveFactor"
+	ProfileLookTableDims         FieldName = "ProfileLookTableDims"
+	ProfileLookTableData         FieldName = "ProfileLookTableData"
+	OpcodeList1                  FieldName = "OpcodeList1"
+	OpcodeList2                  FieldName = "OpcodeList2"
+	OpcodeList3                  FieldName = "OpcodeList3"
+	NoiseProfile                 FieldName = "NoiseProfile"
+	TimeCodes                    FieldName = "TimeCodes"
+	FrameRate                    FieldName = "FrameRate"
+	TStop                        FieldName = "TStop"
+	ReelName                     FieldName = "ReelName"
+	OriginalDefaultFinalSize     FieldName = "OriginalDefaultFinalSize"
+	OriginalBestQualityFinalSize FieldName = "OriginalBestQualityFinalSize"
+	OriginalDefaultCropSize      FieldName = "OriginalDefaultCropSize"
+	CameraLabel                  FieldName = "CameraLabel"
+	ProfileHueSatMapEncoding     FieldName = "ProfileHueSatMapEncoding"
+	ProfileLookTableEncoding     FieldName = "ProfileLookTableEncoding"
+	BaselineExposureOffset       FieldName = "BaselineExposureOffset"
+	DefaultBlackRender           FieldName = "DefaultBlackRender"
+	NewRawImageDigest            FieldName = "NewRawImageDigest"
+	RawToPreviewGain             FieldName = "RawToPreviewGain"
+	DefaultUserCrop              FieldName = "DefaultUserCrop"
+	DepthFormat                  FieldName = "DepthFormat"
+	DepthNear                    FieldName = "DepthNear"
+	DepthFar                     FieldName = "DepthFar"
+	DepthUnits                   FieldName = "DepthUnits"
+	DepthMeasureType             FieldName = "DepthMeasureType"
+	EnhanceParams                FieldName = "EnhanceParams"
+	ProfileGainTableMap          FieldName = "ProfileGainTableMap"
+	SemanticName                 FieldName = "SemanticName"
+	SemanticInstanceID           FieldName = "SemanticInstanceID"
+	CalibrationIlluminant3       FieldName = "CalibrationIlluminant3"
+	CameraCalibration3           FieldName = "CameraCalibration3"
+	ColorMatrix3                 FieldName = "ColorMatrix3"
+	ForwardMatrix3               FieldName = "ForwardMatrix3"
+	IlluminantData1              FieldName = "IlluminantData1"
+	IlluminantData2              FieldName = "IlluminantData2"
+	IlluminantData3              FieldName = "IlluminantData3"
+	MaskSubArea                  FieldName = "MaskSubArea"
+	ProfileHueSatMapData3        FieldName = "ProfileHueSatMapData3"
+	ReductionMatrix3             FieldName = "ReductionMatrix3"
+)
+
+// thumbnail fields, read from IFD1. Tags that IFD1 shares with IFD0 are
+// prefixed with Thumb so that they do not shadow the primary image fields.
 const (
 	ThumbJPEGInterchangeFormat       FieldName = "ThumbJPEGInterchangeFormat"       // offset to thumb jpeg SOI
 	ThumbJPEGInterchangeFormatLength FieldName = "ThumbJPEGInterchangeFormatLength" // byte length of thumb
+	ThumbImageWidth                  FieldName = "ThumbImageWidth"
+	ThumbImageLength                 FieldName = "ThumbImageLength"
+	ThumbBitsPerSample               FieldName = "ThumbBitsPerSample"
+	ThumbCompression                 FieldName = "ThumbCompression"
+	ThumbPhotometricInterpretation   FieldName = "ThumbPhotometricInterpretation"
+	ThumbStripOffsets                FieldName = "ThumbStripOffsets"
+	ThumbOrientation                 FieldName = "ThumbOrientation"
+	ThumbSamplesPerPixel             FieldName = "ThumbSamplesPerPixel"
+	ThumbRowsPerStrip                FieldName = "ThumbRowsPerStrip"
+	ThumbStripByteCounts             FieldName = "ThumbStripByteCounts"
+	ThumbXResolution                 FieldName = "ThumbXResolution"
+	ThumbYResolution                 FieldName = "ThumbYResolution"
+	ThumbPlanarConfiguration         FieldName = "ThumbPlanarConfiguration"
+	ThumbResolutionUnit              FieldName = "ThumbResolutionUnit"
+	ThumbYCbCrSubSampling            FieldName = "ThumbYCbCrSubSampling"
+	ThumbYCbCrPositioning            FieldName = "ThumbYCbCrPositioning"
 )
 
 // GPS fields
 const (
-	GPSVersionID        FieldName = "GPSVersionID"
-	GPSLatitudeRef      FieldName = "GPSLatitudeRef"
-	GPSLatitude         FieldName = "GPSLatitude"
-	GPSLongitudeRef     FieldName = "GPSLongitudeRef"
-	GPSLongitude        FieldName = "GPSLongitude"
-	GPSAltitudeRef      FieldName = "GPSAltitudeRef"
-	GPSAltitude         FieldName = "GPSAltitude"
-	GPSTimeStamp        FieldName = "GPSTimeStamp"
-	GPSSatelites        FieldName = "GPSSatelites"
-	GPSStatus           FieldName = "GPSStatus"
-	GPSMeasureMode      FieldName = "GPSMeasureMode"
-	GPSDOP              FieldName = "GPSDOP"
-	GPSSpeedRef         FieldName = "GPSSpeedRef"
-	GPSSpeed            FieldName = "GPSSpeed"
-	GPSTrackRef         FieldName = "GPSTrackRef"
-	GPSTrack            FieldName = "GPSTrack"
-	GPSImgDirectionRef  FieldName = "GPSImgDirectionRef"
-	GPSImgDirection     FieldName = "GPSImgDirection"
-	GPSMapDatum         FieldName = "GPSMapDatum"
-	GPSDestLatitudeRef  FieldName = "GPSDestLatitudeRef"
-	GPSDestLatitude     FieldName = "GPSDestLatitude"
-	GPSDestLongitudeRef FieldName = "GPSDestLongitudeRef"
-	GPSDestLongitude    FieldName = "GPSDestLongitude"
-	GPSDestBearingRef   FieldName = "GPSDestBearingRef"
-	GPSDestBearing      FieldName = "GPSDestBearing"
-	GPSDestDistanceRef  FieldName = "GPSDestDistanceRef"
-	GPSDestDistance     FieldName = "GPSDestDistance"
-	GPSProcessingMethod FieldName = "GPSProcessingMethod"
-	GPSAreaInformation  FieldName = "GPSAreaInformation"
-	GPSDateStamp        FieldName = "GPSDateStamp"
-	GPSDifferential     FieldName = "GPSDifferential"
+	GPSVersionID         FieldName = "GPSVersionID"
+	GPSLatitudeRef       FieldName = "GPSLatitudeRef"
+	GPSLatitude          FieldName = "GPSLatitude"
+	GPSLongitudeRef      FieldName = "GPSLongitudeRef"
+	GPSLongitude         FieldName = "GPSLongitude"
+	GPSAltitudeRef       FieldName = "GPSAltitudeRef"
+	GPSAltitude          FieldName = "GPSAltitude"
+	GPSTimeStamp         FieldName = "GPSTimeStamp"
+	GPSSatelites         FieldName = "GPSSatelites"
+	GPSStatus            FieldName = "GPSStatus"
+	GPSMeasureMode       FieldName = "GPSMeasureMode"
+	GPSDOP               FieldName = "GPSDOP"
+	GPSSpeedRef          FieldName = "GPSSpeedRef"
+	GPSSpeed             FieldName = "GPSSpeed"
+	GPSTrackRef          FieldName = "GPSTrackRef"
+	GPSTrack             FieldName = "GPSTrack"
+	GPSImgDirectionRef   FieldName = "GPSImgDirectionRef"
+	GPSImgDirection      FieldName = "GPSImgDirection"
+	GPSMapDatum          FieldName = "GPSMapDatum"
+	GPSDestLatitudeRef   FieldName = "GPSDestLatitudeRef"
+	GPSDestLatitude      FieldName = "GPSDestLatitude"
+	GPSDestLongitudeRef  FieldName = "GPSDestLongitudeRef"
+	GPSDestLongitude     FieldName = "GPSDestLongitude"
+	GPSDestBearingRef    FieldName = "GPSDestBearingRef"
+	GPSDestBearing       FieldName = "GPSDestBearing"
+	GPSDestDistanceRef   FieldName = "GPSDestDistanceRef"
+	GPSDestDistance      FieldName = "GPSDestDistance"
+	GPSProcessingMethod  FieldName = "GPSProcessingMethod"
+	GPSAreaInformation   FieldName = "GPSAreaInformation"
+	GPSDateStamp         FieldName = "GPSDateStamp"
+	GPSDifferential      FieldName = "GPSDifferential"
+	GPSHPositioningError FieldName = "GPSHPositioningError"
 )
 
 // interoperability fields
 const (
-	InteroperabilityIndex FieldName = "InteroperabilityIndex"
+	InteroperabilityIndex   FieldName = "InteroperabilityIndex"
+	InteroperabilityVersion FieldName = "InteroperabilityVersion"
 )
 
 var exifFields = map[uint16]FieldName{
-	/////////////////////////////////////
-	////////// IFD 0 ////////////////////
-	/////////////////////////////////////
-
-	// image data structure for the thumbnail
+	// IFD0
 	0x0100: ImageWidth,
 	0x0101: ImageLength,
 	0x0102: BitsPerSample,
@@ -165,8 +365,6 @@ var exifFields = map[uint16]FieldName{
 	0x011A: XResolution,
 	0x011B: YResolution,
 	0x0128: ResolutionUnit,
-
-	// Other tags
 	0x0132: DateTime,
 	0x010E: ImageDescription,
 	0x010F: Make,
@@ -174,47 +372,202 @@ var exifFields = map[uint16]FieldName{
 	0x0131: Software,
 	0x013B: Artist,
 	0x8298: Copyright,
+	0x014A: SubIFDs,
+	0x00FE: NewSubfileType,
+	0x00FF: SubfileType,
+	0x0107: Threshholding,
+	0x0108: CellWidth,
+	0x0109: CellLength,
+	0x010A: FillOrder,
+	0x010D: DocumentName,
+	0x0111: StripOffsets,
+	0x0116: RowsPerStrip,
+	0x0117: StripByteCounts,
+	0x0118: MinSampleValue,
+	0x0119: MaxSampleValue,
+	0x011D: PageName,
+	0x011E: XPosition,
+	0x011F: YPosition,
+	0x0120: FreeOffsets,
+	0x0121: FreeByteCounts,
+	0x0122: GrayResponseUnit,
+	0x0123: GrayResponseCurve,
+	0x0124: T4Options,
+	0x0125: T6Options,
+	0x0129: PageNumber,
+	0x012D: TransferFunction,
+	0x013C: HostComputer,
+	0x013D: Predictor,
+	0x013E: WhitePoint,
+	0x013F: PrimaryChromaticities,
+	0x0140: ColorMap,
+	0x0141: HalftoneHints,
+	0x0142: TileWidth,
+	0x0143: TileLength,
+	0x0144: TileOffsets,
+	0x0145: TileByteCounts,
+	0x014C: InkSet,
+	0x014D: InkNames,
+	0x014E: NumberOfInks,
+	0x0150: DotRange,
+	0x0151: TargetPrinter,
+	0x0152: ExtraSamples,
+	0x0153: SampleFormat,
+	0x0154: SMinSampleValue,
+	0x0155: SMaxSampleValue,
+	0x0156: TransferRange,
+	0x0211: YCbCrCoefficients,
+	0x0214: ReferenceBlackWhite,
+	0x9C9B: XPTitle,
+	0x9C9C: XPComment,
+	0x9C9D: XPAuthor,
+	0x9C9E: XPKeywords,
+	0x9C9F: XPSubject,
+	0x830E: ModelPixelScale,
+	0x8482: ModelTiepoint,
+	0x85D8: ModelTransformation,
+	0x87AF: GeoKeyDirectory,
+	0x87B0: GeoDoubleParams,
+	0x87B1: GeoASCIIParams,
+	0x828D: CFARepeatPatternDim,
+	0x828E: CFAPattern2,
+	0xC612: DNGVersion,
+	0xC613: DNGBackwardVersion,
+	0xC614: UniqueCameraModel,
+	0xC615: LocalizedCameraModel,
+	0xC616: CFAPlaneColor,
+	0xC617: CFALayout,
+	0xC618: LinearizationTable,
+	0xC619: BlackLevelRepeatDim,
+	0xC61A: BlackLevel,
+	0xC61B: BlackLevelDeltaH,
+	0xC61C: BlackLevelDeltaV,
+	0xC61D: WhiteLevel,
+	0xC61E: DefaultScale,
+	0xC61F: DefaultCropOrigin,
+	0xC620: DefaultCropSize,
+	0xC621: ColorMatrix1,
+	0xC622: ColorMatrix2,
+	0xC623: CameraCalibration1,
+	0xC624: CameraCalibration2,
+	0xC625: ReductionMatrix1,
+	0xC626: ReductionMatrix2,
+	0xC627: AnalogBalance,
+	0xC628: AsShotNeutral,
+	0xC629: AsShotWhiteXY,
+	0xC62A: BaselineExposure,
+	0xC62B: BaselineNoise,
+	0xC62C: BaselineSharpness,
+	0xC62D: BayerGreenSplit,
+	0xC62E: LinearResponseLimit,
+	0xC62F: CameraSerialNumber,
+	0xC630: DNGLensInfo,
+	0xC631: ChromaBlurRadius,
+	0xC632: AntiAliasStrength,
+	0xC633: ShadowScale,
+	0xC634: DNGPrivateData,
+	0xC635: MakerNoteSafety,
+	0xC65A: CalibrationIlluminant1,
+	0xC65B: CalibrationIlluminant2,
+	0xC65C: BestQualityScale,
+	0xC65D: RawDataUniqueID,
+	0xC68B: OriginalRawFileName,
+	0xC68C: OriginalRawFileData,
+	0xC68D: ActiveArea,
+	0xC68E: MaskedAreas,
+	0xC68F: AsShotICCProfile,
+	0xC690: AsShotPreProfileMatrix,
+	0xC691: CurrentICCProfile,
+	0xC692: CurrentPreProfileMatrix,
+	0xC6BF: ColorimetricReference,
+	0xC6F3: CameraCalibrationSignature,
+	0xC6F4: ProfileCalibrationSignature,
+	0xC6F5: ExtraCameraProfiles,
+	0xC6F6: AsShotProfileName,
+	0xC6F7: NoiseReductionApplied,
+	0xC6F8: ProfileName,
+	0xC6F9: ProfileHueSatMapDims,
+	0xC6FA: ProfileHueSatMapData1,
+	0xC6FB: ProfileHueSatMapData2,
+	0xC6FC: ProfileToneCurve,
+	0xC6FD: ProfileEmbedPolicy,
+	0xC6FE: ProfileCopyright,
+	0xC714: ForwardMatrix1,
+	0xC715: ForwardMatrix2,
+	0xC716: PreviewApplicationName,
+	0xC717: PreviewApplicationVersion,
+	0xC718: PreviewSettingsName,
+	0xC719: PreviewSettingsDigest,
+	0xC71A: PreviewColorSpace,
+	0xC71B: PreviewDateTime,
+	0xC71C: RawImageDigest,
+	0xC71D: OriginalRawFileDigest,
+	0xC71E: SubTileBlockSize,
+	0xC71F: RowInterleaveFactor,
+	0xC725: ProfileLookTableDims,
+	0xC726: ProfileLookTableData,
+	0xC740: OpcodeList1,
+	0xC741: OpcodeList2,
+	0xC74E: OpcodeList3,
+	0xC761: NoiseProfile,
+	0xC763: TimeCodes,
+	0xC764: FrameRate,
+	0xC772: TStop,
+	0xC789: ReelName,
+	0xC791: OriginalDefaultFinalSize,
+	0xC792: OriginalBestQualityFinalSize,
+	0xC793: OriginalDefaultCropSize,
+	0xC7A1: CameraLabel,
+	0xC7A3: ProfileHueSatMapEncoding,
+	0xC7A4: ProfileLookTableEncoding,
+	0xC7A5: BaselineExposureOffset,
+	0xC7A6: DefaultBlackRender,
+	0xC7A7: NewRawImageDigest,
+	0xC7A8: RawToPreviewGain,
+	0xC7B5: DefaultUserCrop,
+	0xC7E9: DepthFormat,
+	0xC7EA: DepthNear,
+	0xC7EB: DepthFar,
+	0xC7EC: DepthUnits,
+	0xC7ED: DepthMeasureType,
+	0xC7EE: EnhanceParams,
+	0xCD2D: ProfileGainTableMap,
+	0xCD2E: SemanticName,
+	0xCD30: SemanticInstanceID,
+	0xCD31: CalibrationIlluminant3,
+	0xCD32: CameraCalibration3,
+	0xCD33: ColorMatrix3,
+	0xCD34: ForwardMatrix3,
+	0xCD35: IlluminantData1,
+	0xCD36: IlluminantData2,
+	0xCD37: IlluminantData3,
+	0xCD38: MaskSubArea,
+	0xCD39: ProfileHueSatMapData3,
+	0xCD3A: ReductionMatrix3,
+	0x8769: ExifIFDPointer,
+	0x8825: GPSInfoIFDPointer,
 
-	// Windows-specific tags
-	0x9c9b: XPTitle,
-	0x9c9c: XPComment,
-	0x9c9d: XPAuthor,
-	0x9c9e: XPKeywords,
-	0x9c9f: XPSubject,
-
-	// private tags
-	exifPointer: ExifIFDPointer,
-
-	/////////////////////////////////////
-	////////// Exif sub IFD /////////////
-	/////////////////////////////////////
-
-	gpsPointer:     GPSInfoIFDPointer,
-	interopPointer: InteroperabilityIFDPointer,
-
+	// Exif
+	0xA005: InteroperabilityIFDPointer,
 	0x9000: ExifVersion,
 	0xA000: FlashpixVersion,
-
 	0xA001: ColorSpace,
-
 	0x9101: ComponentsConfiguration,
 	0x9102: CompressedBitsPerPixel,
 	0xA002: PixelXDimension,
 	0xA003: PixelYDimension,
-
 	0x927C: MakerNote,
 	0x9286: UserComment,
-
 	0xA004: RelatedSoundFile,
 	0x9003: DateTimeOriginal,
 	0x9004: DateTimeDigitized,
 	0x9290: SubSecTime,
 	0x9291: SubSecTimeOriginal,
 	0x9292: SubSecTimeDigitized,
-
+	0x9010: OffsetTime,
+	0x9011: OffsetTimeOriginal,
+	0x9012: OffsetTimeDigitized,
 	0xA420: ImageUniqueID,
-
-	// picture conditions
 	0x829A: ExposureTime,
 	0x829D: FNumber,
 	0x8822: ExposureProgram,
@@ -255,55 +608,1232 @@ var exifFields = map[uint16]FieldName{
 	0xA40A: Sharpness,
 	0xA40B: DeviceSettingDescription,
 	0xA40C: SubjectDistanceRange,
+	0xA430: CameraOwnerName,
+	0xA431: BodySerialNumber,
 	0xA433: LensMake,
 	0xA434: LensModel,
+	0xA435: LensSerialNumber,
+}
+
+var thumbnailFields = map[uint16]FieldName{
+	0x0100: ThumbImageWidth,
+	0x0101: ThumbImageLength,
+	0x0102: ThumbBitsPerSample,
+	0x0103: ThumbCompression,
+	0x0106: ThumbPhotometricInterpretation,
+	0x0111: ThumbStripOffsets,
+	0x0112: ThumbOrientation,
+	0x0115: ThumbSamplesPerPixel,
+	0x0116: ThumbRowsPerStrip,
+	0x0117: ThumbStripByteCounts,
+	0x011A: ThumbXResolution,
+	0x011B: ThumbYResolution,
+	0x011C: ThumbPlanarConfiguration,
+	0x0128: ThumbResolutionUnit,
+	0x0201: ThumbJPEGInterchangeFormat,
+	0x0202: ThumbJPEGInterchangeFormatLength,
+	0x0212: ThumbYCbCrSubSampling,
+	0x0213: ThumbYCbCrPositioning,
 }
 
 var gpsFields = map[uint16]FieldName{
-	/////////////////////////////////////
-	//// GPS sub-IFD ////////////////////
-	/////////////////////////////////////
-	0x0:  GPSVersionID,
-	0x1:  GPSLatitudeRef,
-	0x2:  GPSLatitude,
-	0x3:  GPSLongitudeRef,
-	0x4:  GPSLongitude,
-	0x5:  GPSAltitudeRef,
-	0x6:  GPSAltitude,
-	0x7:  GPSTimeStamp,
-	0x8:  GPSSatelites,
-	0x9:  GPSStatus,
-	0xA:  GPSMeasureMode,
-	0xB:  GPSDOP,
-	0xC:  GPSSpeedRef,
-	0xD:  GPSSpeed,
-	0xE:  GPSTrackRef,
-	0xF:  GPSTrack,
-	0x10: GPSImgDirectionRef,
-	0x11: GPSImgDirection,
-	0x12: GPSMapDatum,
-	0x13: GPSDestLatitudeRef,
-	0x14: GPSDestLatitude,
-	0x15: GPSDestLongitudeRef,
-	0x16: GPSDestLongitude,
-	0x17: GPSDestBearingRef,
-	0x18: GPSDestBearing,
-	0x19: GPSDestDistanceRef,
-	0x1A: GPSDestDistance,
-	0x1B: GPSProcessingMethod,
-	0x1C: GPSAreaInformation,
-	0x1D: GPSDateStamp,
-	0x1E: GPSDifferential,
+	0x0000: GPSVersionID,
+	0x0001: GPSLatitudeRef,
+	0x0002: GPSLatitude,
+	0x0003: GPSLongitudeRef,
+	0x0004: GPSLongitude,
+	0x0005: GPSAltitudeRef,
+	0x0006: GPSAltitude,
+	0x0007: GPSTimeStamp,
+	0x0008: GPSSatelites,
+	0x0009: GPSStatus,
+	0x000A: GPSMeasureMode,
+	0x000B: GPSDOP,
+	0x000C: GPSSpeedRef,
+	0x000D: GPSSpeed,
+	0x000E: GPSTrackRef,
+	0x000F: GPSTrack,
+	0x0010: GPSImgDirectionRef,
+	0x0011: GPSImgDirection,
+	0x0012: GPSMapDatum,
+	0x0013: GPSDestLatitudeRef,
+	0x0014: GPSDestLatitude,
+	0x0015: GPSDestLongitudeRef,
+	0x0016: GPSDestLongitude,
+	0x0017: GPSDestBearingRef,
+	0x0018: GPSDestBearing,
+	0x0019: GPSDestDistanceRef,
+	0x001A: GPSDestDistance,
+	0x001B: GPSProcessingMethod,
+	0x001C: GPSAreaInformation,
+	0x001D: GPSDateStamp,
+	0x001E: GPSDifferential,
+	0x001F: GPSHPositioningError,
 }
 
 var interopFields = map[uint16]FieldName{
-	/////////////////////////////////////
-	//// Interoperability sub-IFD ///////
-	/////////////////////////////////////
-	0x1: InteroperabilityIndex,
+	0x0001: InteroperabilityIndex,
+	0x0002: InteroperabilityVersion,
 }
 
-var thumbnailFields = map[uint16]FieldName{
-	0x0201: ThumbJPEGInterchangeFormat,
-	0x0202: ThumbJPEGInterchangeFormatLength,
+// IFD0 holds the tags of IFD0, which describes the primary image.
+var IFD0 = struct {
+	ImageWidth                   TagID
+	ImageLength                  TagID
+	BitsPerSample                TagID
+	Compression                  TagID
+	PhotometricInterpretation    TagID
+	Orientation                  TagID
+	SamplesPerPixel              TagID
+	PlanarConfiguration          TagID
+	YCbCrSubSampling             TagID
+	YCbCrPositioning             TagID
+	XResolution                  TagID
+	YResolution                  TagID
+	ResolutionUnit               TagID
+	DateTime                     TagID
+	ImageDescription             TagID
+	Make                         TagID
+	Model                        TagID
+	Software                     TagID
+	Artist                       TagID
+	Copyright                    TagID
+	SubIFDs                      TagID
+	NewSubfileType               TagID
+	SubfileType                  TagID
+	Threshholding                TagID
+	CellWidth                    TagID
+	CellLength                   TagID
+	FillOrder                    TagID
+	DocumentName                 TagID
+	StripOffsets                 TagID
+	RowsPerStrip                 TagID
+	StripByteCounts              TagID
+	MinSampleValue               TagID
+	MaxSampleValue               TagID
+	PageName                     TagID
+	XPosition                    TagID
+	YPosition                    TagID
+	FreeOffsets                  TagID
+	FreeByteCounts               TagID
+	GrayResponseUnit             TagID
+	GrayResponseCurve            TagID
+	T4Options                    TagID
+	T6Options                    TagID
+	PageNumber                   TagID
+	TransferFunction             TagID
+	HostComputer                 TagID
+	Predictor                    TagID
+	WhitePoint                   TagID
+	PrimaryChromaticities        TagID
+	ColorMap                     TagID
+	HalftoneHints                TagID
+	TileWidth                    TagID
+	TileLength                   TagID
+	TileOffsets                  TagID
+	TileByteCounts               TagID
+	InkSet                       TagID
+	InkNames                     TagID
+	NumberOfInks                 TagID
+	DotRange                     TagID
+	TargetPrinter                TagID
+	ExtraSamples                 TagID
+	SampleFormat                 TagID
+	SMinSampleValue              TagID
+	SMaxSampleValue              TagID
+	TransferRange                TagID
+	YCbCrCoefficients            TagID
+	ReferenceBlackWhite          TagID
+	XPTitle                      TagID
+	XPComment                    TagID
+	XPAuthor                     TagID
+	XPKeywords                   TagID
+	XPSubject                    TagID
+	ModelPixelScale              TagID
+	ModelTiepoint                TagID
+	ModelTransformation          TagID
+	GeoKeyDirectory              TagID
+	GeoDoubleParams              TagID
+	GeoASCIIParams               TagID
+	CFARepeatPatternDim          TagID
+	CFAPattern2                  TagID
+	DNGVersion                   TagID
+	DNGBackwardVersion           TagID
+	UniqueCameraModel            TagID
+	LocalizedCameraModel         TagID
+	CFAPlaneColor                TagID
+	CFALayout                    TagID
+	LinearizationTable           TagID
+	BlackLevelRepeatDim          TagID
+	BlackLevel                   TagID
+	BlackLevelDeltaH             TagID
+	BlackLevelDeltaV             TagID
+	WhiteLevel                   TagID
+	DefaultScale                 TagID
+	DefaultCropOrigin            TagID
+	DefaultCropSize              TagID
+	ColorMatrix1                 TagID
+	ColorMatrix2                 TagID
+	CameraCalibration1           TagID
+	CameraCalibration2           TagID
+	ReductionMatrix1             TagID
+	ReductionMatrix2             TagID
+	AnalogBalance                TagID
+	AsShotNeutral                TagID
+	AsShotWhiteXY                TagID
+	BaselineExposure             TagID
+	BaselineNoise                TagID
+	BaselineSharpness            TagID
+	BayerGreenSplit              TagID
+	LinearResponseLimit          TagID
+	CameraSerialNumber           TagID
+	DNGLensInfo                  TagID
+	ChromaBlurRadius             TagID
+	AntiAliasStrength            TagID
+	ShadowScale                  TagID
+	DNGPrivateData               TagID
+	MakerNoteSafety              TagID
+	CalibrationIlluminant1       TagID
+	CalibrationIlluminant2       TagID
+	BestQualityScale             TagID
+	RawDataUniqueID              TagID
+	OriginalRawFileName          TagID
+	OriginalRawFileData          TagID
+	ActiveArea                   TagID
+	MaskedAreas                  TagID
+	AsShotICCProfile             TagID
+	AsShotPreProfileMatrix       TagID
+	CurrentICCProfile            TagID
+	CurrentPreProfileMatrix      TagID
+	ColorimetricReference        TagID
+	CameraCalibrationSignature   TagID
+	ProfileCalibrationSignature  TagID
+	ExtraCameraProfiles          TagID
+	AsShotProfileName            TagID
+	NoiseReductionApplied        TagID
+	ProfileName                  TagID
+	ProfileHueSatMapDims         TagID
+	ProfileHueSatMapData1        TagID
+	ProfileHueSatMapData2        TagID
+	ProfileToneCurve             TagID
+	ProfileEmbedPolicy           TagID
+	ProfileCopyright             TagID
+	ForwardMatrix1               TagID
+	ForwardMatrix2               TagID
+	PreviewApplicationName       TagID
+	PreviewApplicationVersion    TagID
+	PreviewSettingsName          TagID
+	PreviewSettingsDigest        TagID
+	PreviewColorSpace            TagID
+	PreviewDateTime              TagID
+	RawImageDigest               TagID
+	OriginalRawFileDigest        TagID
+	SubTileBlockSize             TagID
+	RowInterleaveFactor          TagID
+	ProfileLookTableDims         TagID
+	ProfileLookTableData         TagID
+	OpcodeList1                  TagID
+	OpcodeList2                  TagID
+	OpcodeList3                  TagID
+	NoiseProfile                 TagID
+	TimeCodes                    TagID
+	FrameRate                    TagID
+	TStop                        TagID
+	ReelName                     TagID
+	OriginalDefaultFinalSize     TagID
+	OriginalBestQualityFinalSize TagID
+	OriginalDefaultCropSize      TagID
+	CameraLabel                  TagID
+	ProfileHueSatMapEncoding     TagID
+	ProfileLookTableEncoding     TagID
+	BaselineExposureOffset       TagID
+	DefaultBlackRender           TagID
+	NewRawImageDigest            TagID
+	RawToPreviewGain             TagID
+	DefaultUserCrop              TagID
+	DepthFormat                  TagID
+	DepthNear                    TagID
+	DepthFar                     TagID
+	DepthUnits                   TagID
+	DepthMeasureType             TagID
+	EnhanceParams                TagID
+	ProfileGainTableMap          TagID
+	SemanticName                 TagID
+	SemanticInstanceID           TagID
+	CalibrationIlluminant3       TagID
+	CameraCalibration3           TagID
+	ColorMatrix3                 TagID
+	ForwardMatrix3               TagID
+	IlluminantData1              TagID
+	IlluminantData2              TagID
+	IlluminantData3              TagID
+	MaskSubArea                  TagID
+	ProfileHueSatMapData3        TagID
+	ReductionMatrix3             TagID
+	ExifIFDPointer               TagID
+	GPSInfoIFDPointer            TagID
+}{
+	ImageWidth:                   TagID{Ifd0, 0x0100},
+	ImageLength:                  TagID{Ifd0, 0x0101},
+	BitsPerSample:                TagID{Ifd0, 0x0102},
+	Compression:                  TagID{Ifd0, 0x0103},
+	PhotometricInterpretation:    TagID{Ifd0, 0x0106},
+	Orientation:                  TagID{Ifd0, 0x0112},
+	SamplesPerPixel:              TagID{Ifd0, 0x0115},
+	PlanarConfiguration:          TagID{Ifd0, 0x011C},
+	YCbCrSubSampling:             TagID{Ifd0, 0x0212},
+	YCbCrPositioning:             TagID{Ifd0, 0x0213},
+	XResolution:                  TagID{Ifd0, 0x011A},
+	YResolution:                  TagID{Ifd0, 0x011B},
+	ResolutionUnit:               TagID{Ifd0, 0x0128},
+	DateTime:                     TagID{Ifd0, 0x0132},
+	ImageDescription:             TagID{Ifd0, 0x010E},
+	Make:                         TagID{Ifd0, 0x010F},
+	Model:                        TagID{Ifd0, 0x0110},
+	Software:                     TagID{Ifd0, 0x0131},
+	Artist:                       TagID{Ifd0, 0x013B},
+	Copyright:                    TagID{Ifd0, 0x8298},
+	SubIFDs:                      TagID{Ifd0, 0x014A},
+	NewSubfileType:               TagID{Ifd0, 0x00FE},
+	SubfileType:                  TagID{Ifd0, 0x00FF},
+	Threshholding:                TagID{Ifd0, 0x0107},
+	CellWidth:                    TagID{Ifd0, 0x0108},
+	CellLength:                   TagID{Ifd0, 0x0109},
+	FillOrder:                    TagID{Ifd0, 0x010A},
+	DocumentName:                 TagID{Ifd0, 0x010D},
+	StripOffsets:                 TagID{Ifd0, 0x0111},
+	RowsPerStrip:                 TagID{Ifd0, 0x0116},
+	StripByteCounts:              TagID{Ifd0, 0x0117},
+	MinSampleValue:               TagID{Ifd0, 0x0118},
+	MaxSampleValue:               TagID{Ifd0, 0x0119},
+	PageName:                     TagID{Ifd0, 0x011D},
+	XPosition:                    TagID{Ifd0, 0x011E},
+	YPosition:                    TagID{Ifd0, 0x011F},
+	FreeOffsets:                  TagID{Ifd0, 0x0120},
+	FreeByteCounts:               TagID{Ifd0, 0x0121},
+	GrayResponseUnit:             TagID{Ifd0, 0x0122},
+	GrayResponseCurve:            TagID{Ifd0, 0x0123},
+	T4Options:                    TagID{Ifd0, 0x0124},
+	T6Options:                    TagID{Ifd0, 0x0125},
+	PageNumber:                   TagID{Ifd0, 0x0129},
+	TransferFunction:             TagID{Ifd0, 0x012D},
+	HostComputer:                 TagID{Ifd0, 0x013C},
+	Predictor:                    TagID{Ifd0, 0x013D},
+	WhitePoint:                   TagID{Ifd0, 0x013E},
+	PrimaryChromaticities:        TagID{Ifd0, 0x013F},
+	ColorMap:                     TagID{Ifd0, 0x0140},
+	HalftoneHints:                TagID{Ifd0, 0x0141},
+	TileWidth:                    TagID{Ifd0, 0x0142},
+	TileLength:                   TagID{Ifd0, 0x0143},
+	TileOffsets:                  TagID{Ifd0, 0x0144},
+	TileByteCounts:               TagID{Ifd0, 0x0145},
+	InkSet:                       TagID{Ifd0, 0x014C},
+	InkNames:                     TagID{Ifd0, 0x014D},
+	NumberOfInks:                 TagID{Ifd0, 0x014E},
+	DotRange:                     TagID{Ifd0, 0x0150},
+	TargetPrinter:                TagID{Ifd0, 0x0151},
+	ExtraSamples:                 TagID{Ifd0, 0x0152},
+	SampleFormat:                 TagID{Ifd0, 0x0153},
+	SMinSampleValue:              TagID{Ifd0, 0x0154},
+	SMaxSampleValue:              TagID{Ifd0, 0x0155},
+	TransferRange:                TagID{Ifd0, 0x0156},
+	YCbCrCoefficients:            TagID{Ifd0, 0x0211},
+	ReferenceBlackWhite:          TagID{Ifd0, 0x0214},
+	XPTitle:                      TagID{Ifd0, 0x9C9B},
+	XPComment:                    TagID{Ifd0, 0x9C9C},
+	XPAuthor:                     TagID{Ifd0, 0x9C9D},
+	XPKeywords:                   TagID{Ifd0, 0x9C9E},
+	XPSubject:                    TagID{Ifd0, 0x9C9F},
+	ModelPixelScale:              TagID{Ifd0, 0x830E},
+	ModelTiepoint:                TagID{Ifd0, 0x8482},
+	ModelTransformation:          TagID{Ifd0, 0x85D8},
+	GeoKeyDirectory:              TagID{Ifd0, 0x87AF},
+	GeoDoubleParams:              TagID{Ifd0, 0x87B0},
+	GeoASCIIParams:               TagID{Ifd0, 0x87B1},
+	CFARepeatPatternDim:          TagID{Ifd0, 0x828D},
+	CFAPattern2:                  TagID{Ifd0, 0x828E},
+	DNGVersion:                   TagID{Ifd0, 0xC612},
+	DNGBackwardVersion:           TagID{Ifd0, 0xC613},
+	UniqueCameraModel:            TagID{Ifd0, 0xC614},
+	LocalizedCameraModel:         TagID{Ifd0, 0xC615},
+	CFAPlaneColor:                TagID{Ifd0, 0xC616},
+	CFALayout:                    TagID{Ifd0, 0xC617},
+	LinearizationTable:           TagID{Ifd0, 0xC618},
+	BlackLevelRepeatDim:          TagID{Ifd0, 0xC619},
+	BlackLevel:                   TagID{Ifd0, 0xC61A},
+	BlackLevelDeltaH:             TagID{Ifd0, 0xC61B},
+	BlackLevelDeltaV:             TagID{Ifd0, 0xC61C},
+	WhiteLevel:                   TagID{Ifd0, 0xC61D},
+	DefaultScale:                 TagID{Ifd0, 0xC61E},
+	DefaultCropOrigin:            TagID{Ifd0, 0xC61F},
+	DefaultCropSize:              TagID{Ifd0, 0xC620},
+	ColorMatrix1:                 TagID{Ifd0, 0xC621},
+	ColorMatrix2:                 TagID{Ifd0, 0xC622},
+	CameraCalibration1:           TagID{Ifd0, 0xC623},
+	CameraCalibration2:           TagID{Ifd0, 0xC624},
+	ReductionMatrix1:             TagID{Ifd0, 0xC625},
+	ReductionMatrix2:             TagID{Ifd0, 0xC626},
+	AnalogBalance:                TagID{Ifd0, 0xC627},
+	AsShotNeutral:                TagID{Ifd0, 0xC628},
+	AsShotWhiteXY:                TagID{Ifd0, 0xC629},
+	BaselineExposure:             TagID{Ifd0, 0xC62A},
+	BaselineNoise:                TagID{Ifd0, 0xC62B},
+	BaselineSharpness:            TagID{Ifd0, 0xC62C},
+	BayerGreenSplit:              TagID{Ifd0, 0xC62D},
+	LinearResponseLimit:          TagID{Ifd0, 0xC62E},
+	CameraSerialNumber:           TagID{Ifd0, 0xC62F},
+	DNGLensInfo:                  TagID{Ifd0, 0xC630},
+	ChromaBlurRadius:             TagID{Ifd0, 0xC631},
+	AntiAliasStrength:            TagID{Ifd0, 0xC632},
+	ShadowScale:                  TagID{Ifd0, 0xC633},
+	DNGPrivateData:               TagID{Ifd0, 0xC634},
+	MakerNoteSafety:              TagID{Ifd0, 0xC635},
+	CalibrationIlluminant1:       TagID{Ifd0, 0xC65A},
+	CalibrationIlluminant2:       TagID{Ifd0, 0xC65B},
+	BestQualityScale:             TagID{Ifd0, 0xC65C},
+	RawDataUniqueID:              TagID{Ifd0, 0xC65D},
+	OriginalRawFileName:          TagID{Ifd0, 0xC68B},
+	OriginalRawFileData:          TagID{Ifd0, 0xC68C},
+	ActiveArea:                   TagID{Ifd0, 0xC68D},
+	MaskedAreas:                  TagID{Ifd0, 0xC68E},
+	AsShotICCProfile:             TagID{Ifd0, 0xC68F},
+	AsShotPreProfileMatrix:       TagID{Ifd0, 0xC690},
+	CurrentICCProfile:            TagID{Ifd0, 0xC691},
+	CurrentPreProfileMatrix:      TagID{Ifd0, 0xC692},
+	ColorimetricReference:        TagID{Ifd0, 0xC6BF},
+	CameraCalibrationSignature:   TagID{Ifd0, 0xC6F3},
+	ProfileCalibrationSignature:  TagID{Ifd0, 0xC6F4},
+	ExtraCameraProfiles:          TagID{Ifd0, 0xC6F5},
+	AsShotProfileName:            TagID{Ifd0, 0xC6F6},
+	NoiseReductionApplied:        TagID{Ifd0, 0xC6F7},
+	ProfileName:                  TagID{Ifd0, 0xC6F8},
+	ProfileHueSatMapDims:         TagID{Ifd0, 0xC6F9},
+	ProfileHueSatMapData1:        TagID{Ifd0, 0xC6FA},
+	ProfileHueSatMapData2:        TagID{Ifd0, 0xC6FB},
+	ProfileToneCurve:             TagID{Ifd0, 0xC6FC},
+	ProfileEmbedPolicy:           TagID{Ifd0, 0xC6FD},
+	ProfileCopyright:             TagID{Ifd0, 0xC6FE},
+	ForwardMatrix1:               TagID{Ifd0, 0xC714},
+	ForwardMatrix2:               TagID{Ifd0, 0xC715},
+	PreviewApplicationName:       TagID{Ifd0, 0xC716},
+	PreviewApplicationVersion:    TagID{Ifd0, 0xC717},
+	PreviewSettingsName:          TagID{Ifd0, 0xC718},
+	PreviewSettingsDigest:        TagID{Ifd0, 0xC719},
+	PreviewColorSpace:            TagID{Ifd0, 0xC71A},
+	PreviewDateTime:              TagID{Ifd0, 0xC71B},
+	RawImageDigest:               TagID{Ifd0, 0xC71C},
+	OriginalRawFileDigest:        TagID{Ifd0, 0xC71D},
+	SubTileBlockSize:             TagID{Ifd0, 0xC71E},
+	RowInterleaveFactor:          TagID{Ifd0, 0xC71F},
+	ProfileLookTableDims:         TagID{Ifd0, 0xC725},
+	ProfileLookTableData:         TagID{Ifd0, 0xC726},
+	OpcodeList1:                  TagID{Ifd0, 0xC740},
+	OpcodeList2:                  TagID{Ifd0, 0xC741},
+	OpcodeList3:                  TagID{Ifd0, 0xC74E},
+	NoiseProfile:                 TagID{Ifd0, 0xC761},
+	TimeCodes:                    TagID{Ifd0, 0xC763},
+	FrameRate:                    TagID{Ifd0, 0xC764},
+	TStop:                        TagID{Ifd0, 0xC772},
+	ReelName:                     TagID{Ifd0, 0xC789},
+	OriginalDefaultFinalSize:     TagID{Ifd0, 0xC791},
+	OriginalBestQualityFinalSize: TagID{Ifd0, 0xC792},
+	OriginalDefaultCropSize:      TagID{Ifd0, 0xC793},
+	CameraLabel:                  TagID{Ifd0, 0xC7A1},
+	ProfileHueSatMapEncoding:     TagID{Ifd0, 0xC7A3},
+	ProfileLookTableEncoding:     TagID{Ifd0, 0xC7A4},
+	BaselineExposureOffset:       TagID{Ifd0, 0xC7A5},
+	DefaultBlackRender:           TagID{Ifd0, 0xC7A6},
+	NewRawImageDigest:            TagID{Ifd0, 0xC7A7},
+	RawToPreviewGain:             TagID{Ifd0, 0xC7A8},
+	DefaultUserCrop:              TagID{Ifd0, 0xC7B5},
+	DepthFormat:                  TagID{Ifd0, 0xC7E9},
+	DepthNear:                    TagID{Ifd0, 0xC7EA},
+	DepthFar:                     TagID{Ifd0, 0xC7EB},
+	DepthUnits:                   TagID{Ifd0, 0xC7EC},
+	DepthMeasureType:             TagID{Ifd0, 0xC7ED},
+	EnhanceParams:                TagID{Ifd0, 0xC7EE},
+	ProfileGainTableMap:          TagID{Ifd0, 0xCD2D},
+	SemanticName:                 TagID{Ifd0, 0xCD2E},
+	SemanticInstanceID:           TagID{Ifd0, 0xCD30},
+	CalibrationIlluminant3:       TagID{Ifd0, 0xCD31},
+	CameraCalibration3:           TagID{Ifd0, 0xCD32},
+	ColorMatrix3:                 TagID{Ifd0, 0xCD33},
+	ForwardMatrix3:               TagID{Ifd0, 0xCD34},
+	IlluminantData1:              TagID{Ifd0, 0xCD35},
+	IlluminantData2:              TagID{Ifd0, 0xCD36},
+	IlluminantData3:              TagID{Ifd0, 0xCD37},
+	MaskSubArea:                  TagID{Ifd0, 0xCD38},
+	ProfileHueSatMapData3:        TagID{Ifd0, 0xCD39},
+	ReductionMatrix3:             TagID{Ifd0, 0xCD3A},
+	ExifIFDPointer:               TagID{Ifd0, 0x8769},
+	GPSInfoIFDPointer:            TagID{Ifd0, 0x8825},
+}
+
+// ExifIFD holds the tags of the Exif sub-IFD.
+var ExifIFD = struct {
+	InteroperabilityIFDPointer TagID
+	ExifVersion                TagID
+	FlashpixVersion            TagID
+	ColorSpace                 TagID
+	ComponentsConfiguration    TagID
+	CompressedBitsPerPixel     TagID
+	PixelXDimension            TagID
+	PixelYDimension            TagID
+	MakerNote                  TagID
+	UserComment                TagID
+	RelatedSoundFile           TagID
+	DateTimeOriginal           TagID
+	DateTimeDigitized          TagID
+	SubSecTime                 TagID
+	SubSecTimeOriginal         TagID
+	SubSecTimeDigitized        TagID
+	OffsetTime                 TagID
+	OffsetTimeOriginal         TagID
+	OffsetTimeDigitized        TagID
+	ImageUniqueID              TagID
+	ExposureTime               TagID
+	FNumber                    TagID
+	ExposureProgram            TagID
+	SpectralSensitivity        TagID
+	ISOSpeedRatings            TagID
+	OECF                       TagID
+	ShutterSpeedValue          TagID
+	ApertureValue              TagID
+	BrightnessValue            TagID
+	ExposureBiasValue          TagID
+	MaxApertureValue           TagID
+	SubjectDistance            TagID
+	MeteringMode               TagID
+	LightSource                TagID
+	Flash                      TagID
+	FocalLength                TagID
+	SubjectArea                TagID
+	FlashEnergy                TagID
+	SpatialFrequencyResponse   TagID
+	FocalPlaneXResolution      TagID
+	FocalPlaneYResolution      TagID
+	FocalPlaneResolutionUnit   TagID
+	SubjectLocation            TagID
+	ExposureIndex              TagID
+	SensingMethod              TagID
+	FileSource                 TagID
+	SceneType                  TagID
+	CFAPattern                 TagID
+	CustomRendered             TagID
+	ExposureMode               TagID
+	WhiteBalance               TagID
+	DigitalZoomRatio           TagID
+	FocalLengthIn35mmFilm      TagID
+	SceneCaptureType           TagID
+	GainControl                TagID
+	Contrast                   TagID
+	Saturation                 TagID
+	Sharpness                  TagID
+	DeviceSettingDescription   TagID
+	SubjectDistanceRange       TagID
+	CameraOwnerName            TagID
+	BodySerialNumber           TagID
+	LensMake                   TagID
+	LensModel                  TagID
+	LensSerialNumber           TagID
+}{
+	InteroperabilityIFDPointer: TagID{IfdExif, 0xA005},
+	ExifVersion:                TagID{IfdExif, 0x9000},
+	FlashpixVersion:            TagID{IfdExif, 0xA000},
+	ColorSpace:                 TagID{IfdExif, 0xA001},
+	ComponentsConfiguration:    TagID{IfdExif, 0x9101},
+	CompressedBitsPerPixel:     TagID{IfdExif, 0x9102},
+	PixelXDimension:            TagID{IfdExif, 0xA002},
+	PixelYDimension:            TagID{IfdExif, 0xA003},
+	MakerNote:                  TagID{IfdExif, 0x927C},
+	UserComment:                TagID{IfdExif, 0x9286},
+	RelatedSoundFile:           TagID{IfdExif, 0xA004},
+	DateTimeOriginal:           TagID{IfdExif, 0x9003},
+	DateTimeDigitized:          TagID{IfdExif, 0x9004},
+	SubSecTime:                 TagID{IfdExif, 0x9290},
+	SubSecTimeOriginal:         TagID{IfdExif, 0x9291},
+	SubSecTimeDigitized:        TagID{IfdExif, 0x9292},
+	OffsetTime:                 TagID{IfdExif, 0x9010},
+	OffsetTimeOriginal:         TagID{IfdExif, 0x9011},
+	OffsetTimeDigitized:        TagID{IfdExif, 0x9012},
+	ImageUniqueID:              TagID{IfdExif, 0xA420},
+	ExposureTime:               TagID{IfdExif, 0x829A},
+	FNumber:                    TagID{IfdExif, 0x829D},
+	ExposureProgram:            TagID{IfdExif, 0x8822},
+	SpectralSensitivity:        TagID{IfdExif, 0x8824},
+	ISOSpeedRatings:            TagID{IfdExif, 0x8827},
+	OECF:                       TagID{IfdExif, 0x8828},
+	ShutterSpeedValue:          TagID{IfdExif, 0x9201},
+	ApertureValue:              TagID{IfdExif, 0x9202},
+	BrightnessValue:            TagID{IfdExif, 0x9203},
+	ExposureBiasValue:          TagID{IfdExif, 0x9204},
+	MaxApertureValue:           TagID{IfdExif, 0x9205},
+	SubjectDistance:            TagID{IfdExif, 0x9206},
+	MeteringMode:               TagID{IfdExif, 0x9207},
+	LightSource:                TagID{IfdExif, 0x9208},
+	Flash:                      TagID{IfdExif, 0x9209},
+	FocalLength:                TagID{IfdExif, 0x920A},
+	SubjectArea:                TagID{IfdExif, 0x9214},
+	FlashEnergy:                TagID{IfdExif, 0xA20B},
+	SpatialFrequencyResponse:   TagID{IfdExif, 0xA20C},
+	FocalPlaneXResolution:      TagID{IfdExif, 0xA20E},
+	FocalPlaneYResolution:      TagID{IfdExif, 0xA20F},
+	FocalPlaneResolutionUnit:   TagID{IfdExif, 0xA210},
+	SubjectLocation:            TagID{IfdExif, 0xA214},
+	ExposureIndex:              TagID{IfdExif, 0xA215},
+	SensingMethod:              TagID{IfdExif, 0xA217},
+	FileSource:                 TagID{IfdExif, 0xA300},
+	SceneType:                  TagID{IfdExif, 0xA301},
+	CFAPattern:                 TagID{IfdExif, 0xA302},
+	CustomRendered:             TagID{IfdExif, 0xA401},
+	ExposureMode:               TagID{IfdExif, 0xA402},
+	WhiteBalance:               TagID{IfdExif, 0xA403},
+	DigitalZoomRatio:           TagID{IfdExif, 0xA404},
+	FocalLengthIn35mmFilm:      TagID{IfdExif, 0xA405},
+	SceneCaptureType:           TagID{IfdExif, 0xA406},
+	GainControl:                TagID{IfdExif, 0xA407},
+	Contrast:                   TagID{IfdExif, 0xA408},
+	Saturation:                 TagID{IfdExif, 0xA409},
+	Sharpness:                  TagID{IfdExif, 0xA40A},
+	DeviceSettingDescription:   TagID{IfdExif, 0xA40B},
+	SubjectDistanceRange:       TagID{IfdExif, 0xA40C},
+	CameraOwnerName:            TagID{IfdExif, 0xA430},
+	BodySerialNumber:           TagID{IfdExif, 0xA431},
+	LensMake:                   TagID{IfdExif, 0xA433},
+	LensModel:                  TagID{IfdExif, 0xA434},
+	LensSerialNumber:           TagID{IfdExif, 0xA435},
+}
+
+// IFD1 holds the tags of IFD1, which describes the thumbnail image.
+// Its field names are those of the Thumb fields without the prefix.
+var IFD1 = struct {
+	ImageWidth                  TagID
+	ImageLength                 TagID
+	BitsPerSample               TagID
+	Compression                 TagID
+	PhotometricInterpretation   TagID
+	StripOffsets                TagID
+	Orientation                 TagID
+	SamplesPerPixel             TagID
+	RowsPerStrip                TagID
+	StripByteCounts             TagID
+	XResolution                 TagID
+	YResolution                 TagID
+	PlanarConfiguration         TagID
+	ResolutionUnit              TagID
+	JPEGInterchangeFormat       TagID
+	JPEGInterchangeFormatLength TagID
+	YCbCrSubSampling            TagID
+	YCbCrPositioning            TagID
+}{
+	ImageWidth:                  TagID{Ifd1, 0x0100},
+	ImageLength:                 TagID{Ifd1, 0x0101},
+	BitsPerSample:               TagID{Ifd1, 0x0102},
+	Compression:                 TagID{Ifd1, 0x0103},
+	PhotometricInterpretation:   TagID{Ifd1, 0x0106},
+	StripOffsets:                TagID{Ifd1, 0x0111},
+	Orientation:                 TagID{Ifd1, 0x0112},
+	SamplesPerPixel:             TagID{Ifd1, 0x0115},
+	RowsPerStrip:                TagID{Ifd1, 0x0116},
+	StripByteCounts:             TagID{Ifd1, 0x0117},
+	XResolution:                 TagID{Ifd1, 0x011A},
+	YResolution:                 TagID{Ifd1, 0x011B},
+	PlanarConfiguration:         TagID{Ifd1, 0x011C},
+	ResolutionUnit:              TagID{Ifd1, 0x0128},
+	JPEGInterchangeFormat:       TagID{Ifd1, 0x0201},
+	JPEGInterchangeFormatLength: TagID{Ifd1, 0x0202},
+	YCbCrSubSampling:            TagID{Ifd1, 0x0212},
+	YCbCrPositioning:            TagID{Ifd1, 0x0213},
+}
+
+// GPS holds the tags of the GPS sub-IFD. Its field names are those of
+// the GPS fields without the prefix.
+var GPS = struct {
+	VersionID         TagID
+	LatitudeRef       TagID
+	Latitude          TagID
+	LongitudeRef      TagID
+	Longitude         TagID
+	AltitudeRef       TagID
+	Altitude          TagID
+	TimeStamp         TagID
+	Satelites         TagID
+	Status            TagID
+	MeasureMode       TagID
+	DOP               TagID
+	SpeedRef          TagID
+	Speed             TagID
+	TrackRef          TagID
+	Track             TagID
+	ImgDirectionRef   TagID
+	ImgDirection      TagID
+	MapDatum          TagID
+	DestLatitudeRef   TagID
+	DestLatitude      TagID
+	DestLongitudeRef  TagID
+	DestLongitude     TagID
+	DestBearingRef    TagID
+	DestBearing       TagID
+	DestDistanceRef   TagID
+	DestDistance      TagID
+	ProcessingMethod  TagID
+	AreaInformation   TagID
+	DateStamp         TagID
+	Differential      TagID
+	HPositioningError TagID
+}{
+	VersionID:         TagID{IfdGPS, 0x0000},
+	LatitudeRef:       TagID{IfdGPS, 0x0001},
+	Latitude:          TagID{IfdGPS, 0x0002},
+	LongitudeRef:      TagID{IfdGPS, 0x0003},
+	Longitude:         TagID{IfdGPS, 0x0004},
+	AltitudeRef:       TagID{IfdGPS, 0x0005},
+	Altitude:          TagID{IfdGPS, 0x0006},
+	TimeStamp:         TagID{IfdGPS, 0x0007},
+	Satelites:         TagID{IfdGPS, 0x0008},
+	Status:            TagID{IfdGPS, 0x0009},
+	MeasureMode:       TagID{IfdGPS, 0x000A},
+	DOP:               TagID{IfdGPS, 0x000B},
+	SpeedRef:          TagID{IfdGPS, 0x000C},
+	Speed:             TagID{IfdGPS, 0x000D},
+	TrackRef:          TagID{IfdGPS, 0x000E},
+	Track:             TagID{IfdGPS, 0x000F},
+	ImgDirectionRef:   TagID{IfdGPS, 0x0010},
+	ImgDirection:      TagID{IfdGPS, 0x0011},
+	MapDatum:          TagID{IfdGPS, 0x0012},
+	DestLatitudeRef:   TagID{IfdGPS, 0x0013},
+	DestLatitude:      TagID{IfdGPS, 0x0014},
+	DestLongitudeRef:  TagID{IfdGPS, 0x0015},
+	DestLongitude:     TagID{IfdGPS, 0x0016},
+	DestBearingRef:    TagID{IfdGPS, 0x0017},
+	DestBearing:       TagID{IfdGPS, 0x0018},
+	DestDistanceRef:   TagID{IfdGPS, 0x0019},
+	DestDistance:      TagID{IfdGPS, 0x001A},
+	ProcessingMethod:  TagID{IfdGPS, 0x001B},
+	AreaInformation:   TagID{IfdGPS, 0x001C},
+	DateStamp:         TagID{IfdGPS, 0x001D},
+	Differential:      TagID{IfdGPS, 0x001E},
+	HPositioningError: TagID{IfdGPS, 0x001F},
+}
+
+// Interop holds the tags of the Interoperability sub-IFD.
+var Interop = struct {
+	Index   TagID
+	Version TagID
+}{
+	Index:   TagID{IfdInterop, 0x0001},
+	Version: TagID{IfdInterop, 0x0002},
+}
+
+var descriptions = map[FieldName]Description{
+	ImageWidth:                       {"Image width", "Number of columns of image data.", "pixels"},
+	ImageLength:                      {"Image height", "Number of rows of image data.", "pixels"},
+	BitsPerSample:                    {"Bits per sample", "Number of bits per image component.", "bits"},
+	Compression:                      {"Compression", "Compression scheme used for the image data.", ""},
+	PhotometricInterpretation:        {"Photometric interpretation", "Color space of the image data components.", ""},
+	Orientation:                      {"Orientation", "Orientation of the image with respect to the rows and columns.", ""},
+	SamplesPerPixel:                  {"Samples per pixel", "Number of components per pixel.", ""},
+	PlanarConfiguration:              {"Planar configuration", "Whether pixel components are stored chunky or planar.", ""},
+	YCbCrSubSampling:                 {"YCbCr subsampling", "Sampling ratio of chrominance components to the luminance component.", ""},
+	YCbCrPositioning:                 {"YCbCr positioning", "Position of chrominance components relative to the luminance component.", ""},
+	XResolution:                      {"Horizontal resolution", "Number of pixels per resolution unit in the width direction.", "pixels per ResolutionUnit"},
+	YResolution:                      {"Vertical resolution", "Number of pixels per resolution unit in the height direction.", "pixels per ResolutionUnit"},
+	ResolutionUnit:                   {"Resolution unit", "Unit of XResolution and YResolution (2 = inches, 3 = centimeters).", ""},
+	DateTime:                         {"Date and time", "Date and time the file was last changed.", ""},
+	ImageDescription:                 {"Image description", "Title or description of the image.", ""},
+	Make:                             {"Camera make", "Manufacturer of the recording equipment.", ""},
+	Model:                            {"Camera model", "Model name or number of the recording equipment.", ""},
+	Software:                         {"Software", "Name and version of the software or firmware that created the image.", ""},
+	Artist:                           {"Artist", "Name of the person who created the image.", ""},
+	Copyright:                        {"Copyright", "Copyright notice of the photographer and editor.", ""},
+	ExifIFDPointer:                   {"Exif IFD pointer", "Offset of the Exif IFD.", "bytes"},
+	GPSInfoIFDPointer:                {"GPS IFD pointer", "Offset of the GPS IFD.", "bytes"},
+	InteroperabilityIFDPointer:       {"Interoperability IFD pointer", "Offset of the Interoperability IFD.", "bytes"},
+	SubIFDs:                          {"Sub-IFD offsets", "Offsets of the IFDs of other images, e.g. the raw image data of raw formats.", "bytes"},
+	ExifVersion:                      {"Exif version", "Version of the Exif standard the file conforms to.", ""},
+	FlashpixVersion:                  {"FlashPix version", "Version of the FlashPix format supported.", ""},
+	ColorSpace:                       {"Color space", "Color space of the image (1 = sRGB, 65535 = uncalibrated).", ""},
+	ComponentsConfiguration:          {"Components configuration", "Order of the channels of each pixel.", ""},
+	CompressedBitsPerPixel:           {"Compressed bits per pixel", "Average compression ratio of the image.", "bits per pixel"},
+	PixelXDimension:                  {"Image width", "Width of the meaningful image data.", "pixels"},
+	PixelYDimension:                  {"Image height", "Height of the meaningful image data.", "pixels"},
+	MakerNote:                        {"Maker note", "Manufacturer specific data.", ""},
+	UserComment:                      {"User comment", "Comments by the user.", ""},
+	RelatedSoundFile:                 {"Related sound file", "Name of an audio file related to the image.", ""},
+	DateTimeOriginal:                 {"Date taken", "Date and time the original image was captured.", ""},
+	DateTimeDigitized:                {"Date digitized", "Date and time the image was stored as digital data.", ""},
+	SubSecTime:                       {"Subseconds", "Fractions of seconds of DateTime.", ""},
+	SubSecTimeOriginal:               {"Subseconds taken", "Fractions of seconds of DateTimeOriginal.", ""},
+	SubSecTimeDigitized:              {"Subseconds digitized", "Fractions of seconds of DateTimeDigitized.", ""},
+	OffsetTime:                       {"Time zone", "UTC offset of DateTime.", ""},
+	OffsetTimeOriginal:               {"Time zone taken", "UTC offset of DateTimeOriginal.", ""},
+	OffsetTimeDigitized:              {"Time zone digitized", "UTC offset of DateTimeDigitized.", ""},
+	ImageUniqueID:                    {"Image unique ID", "Identifier unique to the image.", ""},
+	ExposureTime:                     {"Exposure time", "Time the shutter was open.", "seconds"},
+	FNumber:                          {"F-number", "Ratio of focal length to the aperture diameter.", ""},
+	ExposureProgram:                  {"Exposure program", "Program used by the camera to set the exposure.", ""},
+	SpectralSensitivity:              {"Spectral sensitivity", "Spectral sensitivity of each channel of the camera.", ""},
+	ISOSpeedRatings:                  {"ISO speed", "Sensitivity of the camera as specified in ISO 12232.", "ISO"},
+	OECF:                             {"OECF", "Opto-electronic conversion function specified in ISO 14524.", ""},
+	ShutterSpeedValue:                {"Shutter speed", "Shutter speed in the APEX system.", "APEX"},
+	ApertureValue:                    {"Aperture", "Lens aperture in the APEX system.", "APEX"},
+	BrightnessValue:                  {"Brightness", "Brightness of the subject in the APEX system.", "APEX"},
+	ExposureBiasValue:                {"Exposure bias", "Exposure compensation applied.", "APEX"},
+	MaxApertureValue:                 {"Maximum aperture", "Smallest F-number of the lens in the APEX system.", "APEX"},
+	SubjectDistance:                  {"Subject distance", "Distance to the subject.", "meters"},
+	MeteringMode:                     {"Metering mode", "Method used to measure the exposure.", ""},
+	LightSource:                      {"Light source", "Kind of light source, e.g. daylight or tungsten.", ""},
+	Flash:                            {"Flash", "Status of the flash when the image was captured.", ""},
+	FocalLength:                      {"Focal length", "Actual focal length of the lens.", "millimeters"},
+	SubjectArea:                      {"Subject area", "Location and area of the main subject.", "pixels"},
+	FlashEnergy:                      {"Flash energy", "Strobe energy at the time of capture.", "BCPS"},
+	SpatialFrequencyResponse:         {"Spatial frequency response", "Spatial frequency table and response values as specified in ISO 12233.", ""},
+	FocalPlaneXResolution:            {"Focal plane horizontal resolution", "Number of pixels in the image width per FocalPlaneResolutionUnit on the sensor.", "pixels per FocalPlaneResolutionUnit"},
+	FocalPlaneYResolution:            {"Focal plane vertical resolution", "Number of pixels in the image height per FocalPlaneResolutionUnit on the sensor.", "pixels per FocalPlaneResolutionUnit"},
+	FocalPlaneResolutionUnit:         {"Focal plane resolution unit", "Unit of FocalPlaneXResolution and FocalPlaneYResolution.", ""},
+	SubjectLocation:                  {"Subject location", "Location of the main subject.", "pixels"},
+	ExposureIndex:                    {"Exposure index", "Exposure index selected on the camera.", ""},
+	SensingMethod:                    {"Sensing method", "Type of image sensor.", ""},
+	FileSource:                       {"File source", "Source of the image, e.g. a digital camera or scanner.", ""},
+	SceneType:                        {"Scene type", "Type of scene, e.g. directly photographed.", ""},
+	CFAPattern:                       {"CFA pattern", "Color filter array geometric pattern of the sensor.", ""},
+	CustomRendered:                   {"Custom rendered", "Whether special processing was applied to the image.", ""},
+	ExposureMode:                     {"Exposure mode", "Whether the exposure was set automatically, manually or by bracketing.", ""},
+	WhiteBalance:                     {"White balance", "Whether white balance was set automatically or manually.", ""},
+	DigitalZoomRatio:                 {"Digital zoom ratio", "Digital zoom ratio at the time of capture; 0 if not used.", ""},
+	FocalLengthIn35mmFilm:            {"Focal length (35mm)", "Equivalent focal length for a 35mm film camera.", "millimeters"},
+	SceneCaptureType:                 {"Scene capture type", "Type of scene shot, e.g. landscape or portrait.", ""},
+	GainControl:                      {"Gain control", "Degree of overall image gain adjustment.", ""},
+	Contrast:                         {"Contrast", "Contrast processing applied by the camera.", ""},
+	Saturation:                       {"Saturation", "Saturation processing applied by the camera.", ""},
+	Sharpness:                        {"Sharpness", "Sharpness processing applied by the camera.", ""},
+	DeviceSettingDescription:         {"Device settings", "Picture-taking conditions of a particular camera model.", ""},
+	SubjectDistanceRange:             {"Subject distance range", "Rough distance to the subject, e.g. macro or distant view.", ""},
+	CameraOwnerName:                  {"Camera owner", "Name of the owner of the camera.", ""},
+	BodySerialNumber:                 {"Body serial number", "Serial number of the camera body.", ""},
+	LensMake:                         {"Lens make", "Manufacturer of the lens.", ""},
+	LensModel:                        {"Lens model", "Model name or number of the lens.", ""},
+	LensSerialNumber:                 {"Lens serial number", "Serial number of the lens.", ""},
+	NewSubfileType:                   {"New subfile type", "Kind of data in the subfile, e.g. a reduced resolution version of another image.", ""},
+	SubfileType:                      {"Subfile type", "Kind of data in the subfile (obsolete, see NewSubfileType).", ""},
+	Threshholding:                    {"Thresholding", "Technique used to convert gray to black and white pixels.", ""},
+	CellWidth:                        {"Cell width", "Width of the dithering or halftoning matrix.", "pixels"},
+	CellLength:                       {"Cell height", "Height of the dithering or halftoning matrix.", "pixels"},
+	FillOrder:                        {"Fill order", "Logical order of bits within a byte.", ""},
+	DocumentName:                     {"Document name", "Name of the document the image was scanned from.", ""},
+	StripOffsets:                     {"Strip offsets", "Offsets of the strips of image data.", "bytes"},
+	RowsPerStrip:                     {"Rows per strip", "Number of rows of image data in each strip.", ""},
+	StripByteCounts:                  {"Strip byte counts", "Size of each strip of image data after compression.", "bytes"},
+	MinSampleValue:                   {"Minimum sample value", "Minimum component value used.", ""},
+	MaxSampleValue:                   {"Maximum sample value", "Maximum component value used.", ""},
+	PageName:                         {"Page name", "Name of the page the image was scanned from.", ""},
+	XPosition:                        {"Horizontal position", "Horizontal offset of the image from the left side of the page.", "ResolutionUnit"},
+	YPosition:                        {"Vertical position", "Vertical offset of the image from the top of the page.", "ResolutionUnit"},
+	FreeOffsets:                      {"Free offsets", "Offsets of unused byte ranges of the file.", "bytes"},
+	FreeByteCounts:                   {"Free byte counts", "Sizes of unused byte ranges of the file.", "bytes"},
+	GrayResponseUnit:                 {"Gray response unit", "Precision of the values of GrayResponseCurve.", ""},
+	GrayResponseCurve:                {"Gray response curve", "Optical density of each possible pixel value of grayscale data.", ""},
+	T4Options:                        {"T4 options", "Options of CCITT Group 3 compression.", ""},
+	T6Options:                        {"T6 options", "Options of CCITT Group 4 compression.", ""},
+	PageNumber:                       {"Page number", "Page number of the image and total number of pages.", ""},
+	TransferFunction:                 {"Transfer function", "Transfer function of the image, as a table.", ""},
+	HostComputer:                     {"Host computer", "Computer or operating system used to create the image.", ""},
+	Predictor:                        {"Predictor", "Prediction scheme used before compression.", ""},
+	WhitePoint:                       {"White point", "Chromaticity of the white point of the image.", ""},
+	PrimaryChromaticities:            {"Primary chromaticities", "Chromaticities of the primary colors of the image.", ""},
+	ColorMap:                         {"Color map", "Color palette of palette-color images.", ""},
+	HalftoneHints:                    {"Halftone hints", "Range of gray values to retain detail in when halftoning.", ""},
+	TileWidth:                        {"Tile width", "Number of columns in each tile.", "pixels"},
+	TileLength:                       {"Tile height", "Number of rows in each tile.", "pixels"},
+	TileOffsets:                      {"Tile offsets", "Offsets of the tiles of image data.", "bytes"},
+	TileByteCounts:                   {"Tile byte counts", "Size of each tile of image data after compression.", "bytes"},
+	InkSet:                           {"Ink set", "Set of inks used in separated images.", ""},
+	InkNames:                         {"Ink names", "Names of the inks used in separated images.", ""},
+	NumberOfInks:                     {"Number of inks", "Number of inks used in separated images.", ""},
+	DotRange:                         {"Dot range", "Component values corresponding to 0% and 100% dots.", ""},
+	TargetPrinter:                    {"Target printer", "Printing environment the separated image is intended for.", ""},
+	ExtraSamples:                     {"Extra samples", "Meaning of the extra components of each pixel, e.g. alpha.", ""},
+	SampleFormat:                     {"Sample format", "How to interpret each component: unsigned, signed or floating point.", ""},
+	SMinSampleValue:                  {"Minimum sample value", "Minimum component value used, in the sample format.", ""},
+	SMaxSampleValue:                  {"Maximum sample value", "Maximum component value used, in the sample format.", ""},
+	TransferRange:                    {"Transfer range", "Range of values of the transfer function.", ""},
+	YCbCrCoefficients:                {"YCbCr coefficients", "Coefficients of the transformation from RGB to YCbCr image data.", ""},
+	ReferenceBlackWhite:              {"Reference black and white", "Headroom and footroom of the reference black and white points.", ""},
+	XPTitle:                          {"Title", "Title of the image (Windows).", ""},
+	XPComment:                        {"Comment", "Comment on the image (Windows).", ""},
+	XPAuthor:                         {"Author", "Author of the image (Windows).", ""},
+	XPKeywords:                       {"Keywords", "Keywords describing the image (Windows).", ""},
+	XPSubject:                        {"Subject", "Subject of the image (Windows).", ""},
+	ModelPixelScale:                  {"Model pixel scale", "Size of a raster pixel in model space units (GeoTIFF).", ""},
+	ModelTiepoint:                    {"Model tiepoints", "Raster points and the model space points they map to (GeoTIFF).", ""},
+	ModelTransformation:              {"Model transformation", "Matrix transforming raster space into model space (GeoTIFF).", ""},
+	GeoKeyDirectory:                  {"GeoKey directory", "Keys describing the coordinate reference system (GeoTIFF).", ""},
+	GeoDoubleParams:                  {"GeoKey double values", "Floating point values of the GeoKey directory (GeoTIFF).", ""},
+	GeoASCIIParams:                   {"GeoKey ASCII values", "ASCII values of the GeoKey directory (GeoTIFF).", ""},
+	CFARepeatPatternDim:              {"CFA repeat pattern dimensions", "Rows and columns of the repeating pattern of the color filter array.", ""},
+	CFAPattern2:                      {"CFA pattern", "Color filter array pattern of the raw image, as color plane indexes.", ""},
+	DNGVersion:                       {"DNG version", "Version of the DNG specification the file conforms to.", ""},
+	DNGBackwardVersion:               {"DNG backward version", "Oldest version of the DNG specification a reader must support to read the file.", ""},
+	UniqueCameraModel:                {"Unique camera model", "Unique, non-localized name of the camera model.", ""},
+	LocalizedCameraModel:             {"Localized camera model", "Localized name of the camera model.", ""},
+	CFAPlaneColor:                    {"CFA plane colors", "Colors of the color planes of the color filter array.", ""},
+	CFALayout:                        {"CFA layout", "Spatial layout of the color filter array, e.g. rectangular or staggered.", ""},
+	LinearizationTable:               {"Linearization table", "Lookup table mapping stored raw values to linear values.", ""},
+	BlackLevelRepeatDim:              {"Black level repeat dimensions", "Rows and columns of the repeating pattern of BlackLevel.", ""},
+	BlackLevel:                       {"Black level", "Zero light encoding level of the raw image.", ""},
+	BlackLevelDeltaH:                 {"Black level delta H", "Black level offset of each column of the raw image.", ""},
+	BlackLevelDeltaV:                 {"Black level delta V", "Black level offset of each row of the raw image.", ""},
+	WhiteLevel:                       {"White level", "Fully saturated encoding level of the raw image.", ""},
+	DefaultScale:                     {"Default scale", "Horizontal and vertical scale factors giving square pixels.", ""},
+	DefaultCropOrigin:                {"Default crop origin", "Origin of the final image area, in raw image coordinates.", "pixels"},
+	DefaultCropSize:                  {"Default crop size", "Size of the final image area, in raw image coordinates.", "pixels"},
+	ColorMatrix1:                     {"Color matrix 1", "Matrix converting XYZ values to reference camera values under the first calibration illuminant.", ""},
+	ColorMatrix2:                     {"Color matrix 2", "Matrix converting XYZ values to reference camera values under the second calibration illuminant.", ""},
+	CameraCalibration1:               {"Camera calibration 1", "Matrix transforming reference camera values to individual camera values under the first calibration illuminant.", ""},
+	CameraCalibration2:               {"Camera calibration 2", "Matrix transforming reference camera values to individual camera values under the second calibration illuminant.", ""},
+	ReductionMatrix1:                 {"Reduction matrix 1", "Matrix reducing the color planes of the camera to three under the first calibration illuminant.", ""},
+	ReductionMatrix2:                 {"Reduction matrix 2", "Matrix reducing the color planes of the camera to three under the second calibration illuminant.", ""},
+	AnalogBalance:                    {"Analog balance", "Gain applied to each color plane before digitization.", ""},
+	AsShotNeutral:                    {"As shot neutral", "White balance selected when shooting, as the camera values of a neutral color.", ""},
+	AsShotWhiteXY:                    {"As shot white XY", "White balance selected when shooting, as x-y chromaticity coordinates.", ""},
+	BaselineExposure:                 {"Baseline exposure", "Exposure compensation needed to render the image as intended by the camera model.", "EV"},
+	BaselineNoise:                    {"Baseline noise", "Noise level of the camera model at ISO 100, relative to a reference camera.", ""},
+	BaselineSharpness:                {"Baseline sharpness", "Sharpening needed by the camera model, relative to a reference camera.", ""},
+	BayerGreenSplit:                  {"Bayer green split", "How closely the values of the green pixels of the two rows of a Bayer pattern track each other.", ""},
+	LinearResponseLimit:              {"Linear response limit", "Fraction of the encoding range above which the response may become non-linear.", ""},
+	CameraSerialNumber:               {"Camera serial number", "Serial number of the camera.", ""},
+	DNGLensInfo:                      {"Lens information", "Minimum and maximum focal lengths and the maximum apertures at those focal lengths.", ""},
+	ChromaBlurRadius:                 {"Chroma blur radius", "Radius of the chroma blur needed to remove color aliasing.", "pixels"},
+	AntiAliasStrength:                {"Anti-alias strength", "Strength of the anti-alias filter of the camera, from 0 (none) to 1.", ""},
+	ShadowScale:                      {"Shadow scale", "Scale factor applied to the shadows of the image.", ""},
+	DNGPrivateData:                   {"DNG private data", "Private data of the program that created the file.", ""},
+	MakerNoteSafety:                  {"Maker note safety", "Whether the maker note remains valid when the file is edited.", ""},
+	CalibrationIlluminant1:           {"Calibration illuminant 1", "Light source of the first set of color calibration tags.", ""},
+	CalibrationIlluminant2:           {"Calibration illuminant 2", "Light source of the second set of color calibration tags.", ""},
+	BestQualityScale:                 {"Best quality scale", "Scale factor to apply to DefaultScale for the best quality rendering.", ""},
+	RawDataUniqueID:                  {"Raw data unique ID", "Unique identifier of the raw image data.", ""},
+	OriginalRawFileName:              {"Original raw file name", "Name of the raw file the DNG file was converted from.", ""},
+	OriginalRawFileData:              {"Original raw file data", "Compressed contents of the raw file the DNG file was converted from.", ""},
+	ActiveArea:                       {"Active area", "Top, left, bottom and right edges of the non-masked pixels of the raw image.", "pixels"},
+	MaskedAreas:                      {"Masked areas", "Rectangles of masked pixels of the raw image, as top, left, bottom and right edges.", "pixels"},
+	AsShotICCProfile:                 {"As shot ICC profile", "ICC profile rendering the image as shot.", ""},
+	AsShotPreProfileMatrix:           {"As shot pre-profile matrix", "Matrix applied to the camera color values before the as shot ICC profile.", ""},
+	CurrentICCProfile:                {"Current ICC profile", "ICC profile rendering the image with the current settings.", ""},
+	CurrentPreProfileMatrix:          {"Current pre-profile matrix", "Matrix applied to the camera color values before the current ICC profile.", ""},
+	ColorimetricReference:            {"Colorimetric reference", "Whether the color values are scene referred or output referred.", ""},
+	CameraCalibrationSignature:       {"Camera calibration signature", "Identifies the calibration the camera calibration matrices belong to.", ""},
+	ProfileCalibrationSignature:      {"Profile calibration signature", "Identifies the calibration the camera profile was made for.", ""},
+	ExtraCameraProfiles:              {"Extra camera profiles", "Offsets of additional camera profiles.", ""},
+	AsShotProfileName:                {"As shot profile name", "Name of the camera profile selected when shooting.", ""},
+	NoiseReductionApplied:            {"Noise reduction applied", "Amount of noise reduction already applied to the raw data.", ""},
+	ProfileName:                      {"Profile name", "Name of the camera profile.", ""},
+	ProfileHueSatMapDims:             {"Hue/saturation map dimensions", "Numbers of hue, saturation and value divisions of the hue/saturation maps.", ""},
+	ProfileHueSatMapData1:            {"Hue/saturation map 1", "Hue/saturation map of the first calibration illuminant.", ""},
+	ProfileHueSatMapData2:            {"Hue/saturation map 2", "Hue/saturation map of the second calibration illuminant.", ""},
+	ProfileToneCurve:                 {"Profile tone curve", "Default tone curve of the camera profile.", ""},
+	ProfileEmbedPolicy:               {"Profile embed policy", "Usage rules of the camera profile, e.g. whether it may be copied.", ""},
+	ProfileCopyright:                 {"Profile copyright", "Copyright notice of the camera profile.", ""},
+	ForwardMatrix1:                   {"Forward matrix 1", "Matrix converting white balanced camera values to XYZ values under the first calibration illuminant.", ""},
+	ForwardMatrix2:                   {"Forward matrix 2", "Matrix converting white balanced camera values to XYZ values under the second calibration illuminant.", ""},
+	PreviewApplicationName:           {"Preview application name", "Name of the application that created the preview image.", ""},
+	PreviewApplicationVersion:        {"Preview application version", "Version of the application that created the preview image.", ""},
+	PreviewSettingsName:              {"Preview settings name", "Name of the conversion settings used to render the preview image.", ""},
+	PreviewSettingsDigest:            {"Preview settings digest", "MD5 digest of the conversion settings used to render the preview image.", ""},
+	PreviewColorSpace:                {"Preview color space", "Color space of the preview image.", ""},
+	PreviewDateTime:                  {"Preview date and time", "Date and time the preview image was rendered, in ISO 8601 format.", ""},
+	RawImageDigest:                   {"Raw image digest", "MD5 digest of the raw image data.", ""},
+	OriginalRawFileDigest:            {"Original raw file digest", "MD5 digest of the data of OriginalRawFileData.", ""},
+	SubTileBlockSize:                 {"Sub-tile block size", "Rows and columns of the blocks the tiles of the raw image are interleaved by.", ""},
+	RowInterleaveFactor:              {"Row interleave factor", "Number of interleaved fields of the rows of the raw image.", ""},
+	ProfileLookTableDims:             {"Look table dimensions", "Numbers of hue, saturation and value divisions of the look table.", ""},
+	ProfileLookTableData:             {"Look table", "Hue/saturation look table of the camera profile.", ""},
+	OpcodeList1:                      {"Opcode list 1", "Processing applied to the raw image as read from the file.", ""},
+	OpcodeList2:                      {"Opcode list 2", "Processing applied to the raw image after mapping it to linear values.", ""},
+	OpcodeList3:                      {"Opcode list 3", "Processing applied to the raw image after demosaicing.", ""},
+	NoiseProfile:                     {"Noise profile", "Parameters of the noise model of the raw image.", ""},
+	TimeCodes:                        {"Time codes", "SMPTE time codes of the video frame.", ""},
+	FrameRate:                        {"Frame rate", "Frame rate of the video the image belongs to.", "frames per second"},
+	TStop:                            {"T-stop", "T-stop of the lens, or its minimum and maximum.", ""},
+	ReelName:                         {"Reel name", "Name of the video reel the image belongs to.", ""},
+	OriginalDefaultFinalSize:         {"Original default final size", "Default final size of the image before it was resized.", "pixels"},
+	OriginalBestQualityFinalSize:     {"Original best quality final size", "Best quality final size of the image before it was resized.", "pixels"},
+	OriginalDefaultCropSize:          {"Original default crop size", "Default crop size of the image before it was resized.", "pixels"},
+	CameraLabel:                      {"Camera label", "Label of the camera, e.g. in a multi-camera setup.", ""},
+	ProfileHueSatMapEncoding:         {"Hue/saturation map encoding", "Encoding of the value axis of the hue/saturation maps.", ""},
+	ProfileLookTableEncoding:         {"Look table encoding", "Encoding of the value axis of the look table.", ""},
+	BaselineExposureOffset:           {"Baseline exposure offset", "Offset applied to BaselineExposure when the camera profile is used.", "EV"},
+	DefaultBlackRender:               {"Default black render", "Whether black levels are subtracted automatically by the rendering.", ""},
+	NewRawImageDigest:                {"New raw image digest", "MD5 digest of the raw image data, computed the way of DNG 1.4.", ""},
+	RawToPreviewGain:                 {"Raw to preview gain", "Gain between the raw image and the preview image.", ""},
+	DefaultUserCrop:                  {"Default user crop", "Top, left, bottom and right edges of the crop selected by the user, relative to the default crop.", ""},
+	DepthFormat:                      {"Depth format", "Encoding of the depth map values.", ""},
+	DepthNear:                        {"Depth near", "Distance of the nearest depth map value.", ""},
+	DepthFar:                         {"Depth far", "Distance of the farthest depth map value.", ""},
+	DepthUnits:                       {"Depth units", "Unit of DepthNear and DepthFar.", ""},
+	DepthMeasureType:                 {"Depth measure type", "How the depth map distances are measured.", ""},
+	EnhanceParams:                    {"Enhance parameters", "Parameters of the enhancement applied to an enhanced image.", ""},
+	ProfileGainTableMap:              {"Profile gain table map", "Spatially varying gains applied to the raw image.", ""},
+	SemanticName:                     {"Semantic name", "Name of the semantic mask, e.g. sky or skin.", ""},
+	SemanticInstanceID:               {"Semantic instance ID", "Identifier of the instance of the semantic mask.", ""},
+	CalibrationIlluminant3:           {"Calibration illuminant 3", "Light source of the third set of color calibration tags.", ""},
+	CameraCalibration3:               {"Camera calibration 3", "Matrix transforming reference camera values to individual camera values under the third calibration illuminant.", ""},
+	ColorMatrix3:                     {"Color matrix 3", "Matrix converting XYZ values to reference camera values under the third calibration illuminant.", ""},
+	ForwardMatrix3:                   {"Forward matrix 3", "Matrix converting white balanced camera values to XYZ values under the third calibration illuminant.", ""},
+	IlluminantData1:                  {"Illuminant data 1", "Spectral data of the first calibration illuminant.", ""},
+	IlluminantData2:                  {"Illuminant data 2", "Spectral data of the second calibration illuminant.", ""},
+	IlluminantData3:                  {"Illuminant data 3", "Spectral data of the third calibration illuminant.", ""},
+	MaskSubArea:                      {"Mask sub-area", "Area of the image covered by the semantic mask.", ""},
+	ProfileHueSatMapData3:            {"Hue/saturation map 3", "Hue/saturation map of the third calibration illuminant.", ""},
+	ReductionMatrix3:                 {"Reduction matrix 3", "Matrix reducing the color planes of the camera to three under the third calibration illuminant.", ""},
+	ThumbJPEGInterchangeFormat:       {"Thumbnail offset", "Offset of the JPEG thumbnail image.", "bytes"},
+	ThumbJPEGInterchangeFormatLength: {"Thumbnail length", "Size of the JPEG thumbnail image.", "bytes"},
+	ThumbImageWidth:                  {"Thumbnail width", "Number of columns of the thumbnail image.", "pixels"},
+	ThumbImageLength:                 {"Thumbnail height", "Number of rows of the thumbnail image.", "pixels"},
+	ThumbBitsPerSample:               {"Thumbnail bits per sample", "Number of bits per component of the thumbnail image.", "bits"},
+	ThumbCompression:                 {"Thumbnail compression", "Compression scheme used for the thumbnail image.", ""},
+	ThumbPhotometricInterpretation:   {"Thumbnail photometric interpretation", "Pixel composition of the thumbnail image.", ""},
+	ThumbStripOffsets:                {"Thumbnail strip offsets", "Offsets of the strips of an uncompressed thumbnail image.", "bytes"},
+	ThumbOrientation:                 {"Thumbnail orientation", "Orientation of the thumbnail image with respect to the rows and columns.", ""},
+	ThumbSamplesPerPixel:             {"Thumbnail samples per pixel", "Number of components per pixel of the thumbnail image.", ""},
+	ThumbRowsPerStrip:                {"Thumbnail rows per strip", "Number of rows per strip of an uncompressed thumbnail image.", ""},
+	ThumbStripByteCounts:             {"Thumbnail strip byte counts", "Sizes of the strips of an uncompressed thumbnail image.", "bytes"},
+	ThumbXResolution:                 {"Thumbnail horizontal resolution", "Number of thumbnail pixels per ThumbResolutionUnit in the width direction.", "pixels per ThumbResolutionUnit"},
+	ThumbYResolution:                 {"Thumbnail vertical resolution", "Number of thumbnail pixels per ThumbResolutionUnit in the height direction.", "pixels per ThumbResolutionUnit"},
+	ThumbPlanarConfiguration:         {"Thumbnail planar configuration", "Whether the thumbnail pixel components are stored chunky or planar.", ""},
+	ThumbResolutionUnit:              {"Thumbnail resolution unit", "Unit of ThumbXResolution and ThumbYResolution.", ""},
+	ThumbYCbCrSubSampling:            {"Thumbnail YCbCr subsampling", "Sampling ratio of chrominance components of the thumbnail image.", ""},
+	ThumbYCbCrPositioning:            {"Thumbnail YCbCr positioning", "Position of chrominance components relative to luminance in the thumbnail image.", ""},
+	GPSVersionID:                     {"GPS version", "Version of the GPS IFD.", ""},
+	GPSLatitudeRef:                   {"Latitude reference", "Whether the latitude is north (N) or south (S).", ""},
+	GPSLatitude:                      {"Latitude", "Latitude as degrees, minutes and seconds.", "degrees"},
+	GPSLongitudeRef:                  {"Longitude reference", "Whether the longitude is east (E) or west (W).", ""},
+	GPSLongitude:                     {"Longitude", "Longitude as degrees, minutes and seconds.", "degrees"},
+	GPSAltitudeRef:                   {"Altitude reference", "Whether the altitude is above (0) or below (1) sea level.", ""},
+	GPSAltitude:                      {"Altitude", "Altitude relative to GPSAltitudeRef.", "meters"},
+	GPSTimeStamp:                     {"GPS time", "Time as UTC hours, minutes and seconds.", ""},
+	GPSSatelites:                     {"GPS satellites", "Satellites used for the measurement.", ""},
+	GPSStatus:                        {"GPS status", "Status of the GPS receiver (A = measurement in progress, V = interoperability).", ""},
+	GPSMeasureMode:                   {"GPS measure mode", "Whether the measurement is two- or three-dimensional.", ""},
+	GPSDOP:                           {"GPS precision", "Dilution of precision of the measurement.", ""},
+	GPSSpeedRef:                      {"Speed unit", "Unit of GPSSpeed (K = km/h, M = mph, N = knots).", ""},
+	GPSSpeed:                         {"Speed", "Speed of movement of the GPS receiver.", "GPSSpeedRef"},
+	GPSTrackRef:                      {"Track reference", "Reference for GPSTrack (T = true north, M = magnetic north).", ""},
+	GPSTrack:                         {"Track", "Direction of movement of the GPS receiver.", "degrees"},
+	GPSImgDirectionRef:               {"Image direction reference", "Reference for GPSImgDirection (T = true north, M = magnetic north).", ""},
+	GPSImgDirection:                  {"Image direction", "Direction the camera was pointing in.", "degrees"},
+	GPSMapDatum:                      {"Map datum", "Geodetic survey data used by the GPS receiver.", ""},
+	GPSDestLatitudeRef:               {"Destination latitude reference", "Whether the destination latitude is north (N) or south (S).", ""},
+	GPSDestLatitude:                  {"Destination latitude", "Latitude of the destination point.", "degrees"},
+	GPSDestLongitudeRef:              {"Destination longitude reference", "Whether the destination longitude is east (E) or west (W).", ""},
+	GPSDestLongitude:                 {"Destination longitude", "Longitude of the destination point.", "degrees"},
+	GPSDestBearingRef:                {"Destination bearing reference", "Reference for GPSDestBearing (T = true north, M = magnetic north).", ""},
+	GPSDestBearing:                   {"Destination bearing", "Bearing to the destination point.", "degrees"},
+	GPSDestDistanceRef:               {"Destination distance unit", "Unit of GPSDestDistance (K = km, M = miles, N = nautical miles).", ""},
+	GPSDestDistance:                  {"Destination distance", "Distance to the destination point.", "GPSDestDistanceRef"},
+	GPSProcessingMethod:              {"GPS processing method", "Name of the method used for location finding.", ""},
+	GPSAreaInformation:               {"GPS area", "Name of the GPS area.", ""},
+	GPSDateStamp:                     {"GPS date", "UTC date of the measurement.", ""},
+	GPSDifferential:                  {"GPS differential", "Whether differential correction was applied.", ""},
+	GPSHPositioningError:             {"Horizontal positioning error", "Horizontal positioning error of the measurement.", "meters"},
+	InteroperabilityIndex:            {"Interoperability index", "Interoperability rule the file conforms to, e.g. R98.", ""},
+	InteroperabilityVersion:          {"Interoperability version", "Version of the interoperability rule, e.g. 0100.", ""},
+}
+
+var fieldSpecs = map[FieldName]FieldSpec{
+	ImageWidth:                   {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong}, 1, false},
+	ImageLength:                  {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong}, 1, false},
+	BitsPerSample:                {Ifd0, []tiff.DataType{tiff.DTShort}, 3, false},
+	Compression:                  {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	PhotometricInterpretation:    {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	Orientation:                  {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	SamplesPerPixel:              {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	PlanarConfiguration:          {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	YCbCrSubSampling:             {Ifd0, []tiff.DataType{tiff.DTShort}, 2, false},
+	YCbCrPositioning:             {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	XResolution:                  {Ifd0, []tiff.DataType{tiff.DTRational}, 1, false},
+	YResolution:                  {Ifd0, []tiff.DataType{tiff.DTRational}, 1, false},
+	ResolutionUnit:               {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	DateTime:                     {Ifd0, []tiff.DataType{tiff.DTAscii}, 20, false},
+	ImageDescription:             {Ifd0, []tiff.DataType{tiff.DTAscii}, 0, false},
+	Make:                         {Ifd0, []tiff.DataType{tiff.DTAscii}, 0, false},
+	Model:                        {Ifd0, []tiff.DataType{tiff.DTAscii}, 0, false},
+	Software:                     {Ifd0, []tiff.DataType{tiff.DTAscii}, 0, false},
+	Artist:                       {Ifd0, []tiff.DataType{tiff.DTAscii}, 0, false},
+	Copyright:                    {Ifd0, []tiff.DataType{tiff.DTAscii}, 0, false},
+	ExifVersion:                  {IfdExif, []tiff.DataType{tiff.DTUndefined}, 4, false},
+	FlashpixVersion:              {IfdExif, []tiff.DataType{tiff.DTUndefined}, 4, false},
+	ColorSpace:                   {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	ComponentsConfiguration:      {IfdExif, []tiff.DataType{tiff.DTUndefined}, 4, false},
+	CompressedBitsPerPixel:       {IfdExif, []tiff.DataType{tiff.DTRational}, 1, false},
+	PixelXDimension:              {IfdExif, []tiff.DataType{tiff.DTShort, tiff.DTLong}, 1, false},
+	PixelYDimension:              {IfdExif, []tiff.DataType{tiff.DTShort, tiff.DTLong}, 1, false},
+	MakerNote:                    {IfdExif, []tiff.DataType{tiff.DTUndefined}, 0, false},
+	UserComment:                  {IfdExif, []tiff.DataType{tiff.DTUndefined}, 0, false},
+	DateTimeOriginal:             {IfdExif, []tiff.DataType{tiff.DTAscii}, 20, false},
+	DateTimeDigitized:            {IfdExif, []tiff.DataType{tiff.DTAscii}, 20, false},
+	SubSecTime:                   {IfdExif, []tiff.DataType{tiff.DTAscii}, 0, false},
+	SubSecTimeOriginal:           {IfdExif, []tiff.DataType{tiff.DTAscii}, 0, false},
+	SubSecTimeDigitized:          {IfdExif, []tiff.DataType{tiff.DTAscii}, 0, false},
+	OffsetTime:                   {IfdExif, []tiff.DataType{tiff.DTAscii}, 7, false},
+	OffsetTimeOriginal:           {IfdExif, []tiff.DataType{tiff.DTAscii}, 7, false},
+	OffsetTimeDigitized:          {IfdExif, []tiff.DataType{tiff.DTAscii}, 7, false},
+	ImageUniqueID:                {IfdExif, []tiff.DataType{tiff.DTAscii}, 33, false},
+	ExposureTime:                 {IfdExif, []tiff.DataType{tiff.DTRational}, 1, false},
+	FNumber:                      {IfdExif, []tiff.DataType{tiff.DTRational}, 1, false},
+	ExposureProgram:              {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	ISOSpeedRatings:              {IfdExif, []tiff.DataType{tiff.DTShort}, 0, false},
+	ShutterSpeedValue:            {IfdExif, []tiff.DataType{tiff.DTSRational}, 1, false},
+	ApertureValue:                {IfdExif, []tiff.DataType{tiff.DTRational}, 1, false},
+	BrightnessValue:              {IfdExif, []tiff.DataType{tiff.DTSRational}, 1, false},
+	ExposureBiasValue:            {IfdExif, []tiff.DataType{tiff.DTSRational}, 1, false},
+	MaxApertureValue:             {IfdExif, []tiff.DataType{tiff.DTRational}, 1, false},
+	SubjectDistance:              {IfdExif, []tiff.DataType{tiff.DTRational}, 1, false},
+	MeteringMode:                 {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	LightSource:                  {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	Flash:                        {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	FocalLength:                  {IfdExif, []tiff.DataType{tiff.DTRational}, 1, false},
+	FocalPlaneXResolution:        {IfdExif, []tiff.DataType{tiff.DTRational}, 1, false},
+	FocalPlaneYResolution:        {IfdExif, []tiff.DataType{tiff.DTRational}, 1, false},
+	FocalPlaneResolutionUnit:     {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	SensingMethod:                {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	FileSource:                   {IfdExif, []tiff.DataType{tiff.DTUndefined}, 1, false},
+	SceneType:                    {IfdExif, []tiff.DataType{tiff.DTUndefined}, 1, false},
+	CustomRendered:               {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	ExposureMode:                 {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	WhiteBalance:                 {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	DigitalZoomRatio:             {IfdExif, []tiff.DataType{tiff.DTRational}, 1, false},
+	FocalLengthIn35mmFilm:        {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	SceneCaptureType:             {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	GainControl:                  {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	Contrast:                     {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	Saturation:                   {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	Sharpness:                    {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	SubjectDistanceRange:         {IfdExif, []tiff.DataType{tiff.DTShort}, 1, false},
+	CameraOwnerName:              {IfdExif, []tiff.DataType{tiff.DTAscii}, 0, false},
+	BodySerialNumber:             {IfdExif, []tiff.DataType{tiff.DTAscii}, 0, false},
+	LensMake:                     {IfdExif, []tiff.DataType{tiff.DTAscii}, 0, false},
+	LensModel:                    {IfdExif, []tiff.DataType{tiff.DTAscii}, 0, false},
+	LensSerialNumber:             {IfdExif, []tiff.DataType{tiff.DTAscii}, 0, false},
+	CFARepeatPatternDim:          {Ifd0, []tiff.DataType{tiff.DTShort}, 2, false},
+	CFAPattern2:                  {Ifd0, []tiff.DataType{tiff.DTByte}, 0, false},
+	DNGVersion:                   {Ifd0, []tiff.DataType{tiff.DTByte}, 4, false},
+	DNGBackwardVersion:           {Ifd0, []tiff.DataType{tiff.DTByte}, 4, false},
+	UniqueCameraModel:            {Ifd0, []tiff.DataType{tiff.DTAscii}, 0, false},
+	LocalizedCameraModel:         {Ifd0, []tiff.DataType{tiff.DTAscii, tiff.DTByte}, 0, false},
+	CFAPlaneColor:                {Ifd0, []tiff.DataType{tiff.DTByte}, 0, false},
+	CFALayout:                    {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	LinearizationTable:           {Ifd0, []tiff.DataType{tiff.DTShort}, 0, false},
+	BlackLevelRepeatDim:          {Ifd0, []tiff.DataType{tiff.DTShort}, 2, false},
+	BlackLevel:                   {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong, tiff.DTRational}, 0, false},
+	BlackLevelDeltaH:             {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	BlackLevelDeltaV:             {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	WhiteLevel:                   {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong}, 0, false},
+	DefaultScale:                 {Ifd0, []tiff.DataType{tiff.DTRational}, 2, false},
+	DefaultCropOrigin:            {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong, tiff.DTRational}, 2, false},
+	DefaultCropSize:              {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong, tiff.DTRational}, 2, false},
+	ColorMatrix1:                 {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	ColorMatrix2:                 {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	CameraCalibration1:           {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	CameraCalibration2:           {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	ReductionMatrix1:             {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	ReductionMatrix2:             {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	AnalogBalance:                {Ifd0, []tiff.DataType{tiff.DTRational}, 0, false},
+	AsShotNeutral:                {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTRational}, 0, false},
+	AsShotWhiteXY:                {Ifd0, []tiff.DataType{tiff.DTRational}, 2, false},
+	BaselineExposure:             {Ifd0, []tiff.DataType{tiff.DTSRational}, 1, false},
+	BaselineNoise:                {Ifd0, []tiff.DataType{tiff.DTRational}, 1, false},
+	BaselineSharpness:            {Ifd0, []tiff.DataType{tiff.DTRational}, 1, false},
+	BayerGreenSplit:              {Ifd0, []tiff.DataType{tiff.DTLong}, 1, false},
+	LinearResponseLimit:          {Ifd0, []tiff.DataType{tiff.DTRational}, 1, false},
+	CameraSerialNumber:           {Ifd0, []tiff.DataType{tiff.DTAscii}, 0, false},
+	DNGLensInfo:                  {Ifd0, []tiff.DataType{tiff.DTRational}, 4, false},
+	ChromaBlurRadius:             {Ifd0, []tiff.DataType{tiff.DTRational}, 1, false},
+	AntiAliasStrength:            {Ifd0, []tiff.DataType{tiff.DTRational}, 1, false},
+	ShadowScale:                  {Ifd0, []tiff.DataType{tiff.DTRational}, 1, false},
+	DNGPrivateData:               {Ifd0, []tiff.DataType{tiff.DTByte}, 0, false},
+	MakerNoteSafety:              {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	CalibrationIlluminant1:       {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	CalibrationIlluminant2:       {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	BestQualityScale:             {Ifd0, []tiff.DataType{tiff.DTRational}, 1, false},
+	RawDataUniqueID:              {Ifd0, []tiff.DataType{tiff.DTByte}, 16, false},
+	OriginalRawFileName:          {Ifd0, []tiff.DataType{tiff.DTAscii, tiff.DTByte}, 0, false},
+	OriginalRawFileData:          {Ifd0, []tiff.DataType{tiff.DTUndefined}, 0, false},
+	ActiveArea:                   {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong}, 4, false},
+	MaskedAreas:                  {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong}, 0, false},
+	AsShotICCProfile:             {Ifd0, []tiff.DataType{tiff.DTUndefined}, 0, false},
+	AsShotPreProfileMatrix:       {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	CurrentICCProfile:            {Ifd0, []tiff.DataType{tiff.DTUndefined}, 0, false},
+	CurrentPreProfileMatrix:      {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	ColorimetricReference:        {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	CameraCalibrationSignature:   {Ifd0, []tiff.DataType{tiff.DTAscii, tiff.DTByte}, 0, false},
+	ProfileCalibrationSignature:  {Ifd0, []tiff.DataType{tiff.DTAscii, tiff.DTByte}, 0, false},
+	ExtraCameraProfiles:          {Ifd0, []tiff.DataType{tiff.DTLong}, 0, false},
+	AsShotProfileName:            {Ifd0, []tiff.DataType{tiff.DTAscii, tiff.DTByte}, 0, false},
+	NoiseReductionApplied:        {Ifd0, []tiff.DataType{tiff.DTRational}, 1, false},
+	ProfileName:                  {Ifd0, []tiff.DataType{tiff.DTAscii, tiff.DTByte}, 0, false},
+	ProfileHueSatMapDims:         {Ifd0, []tiff.DataType{tiff.DTLong}, 3, false},
+	ProfileHueSatMapData1:        {Ifd0, []tiff.DataType{tiff.DTFloat}, 0, false},
+	ProfileHueSatMapData2:        {Ifd0, []tiff.DataType{tiff.DTFloat}, 0, false},
+	ProfileToneCurve:             {Ifd0, []tiff.DataType{tiff.DTFloat}, 0, false},
+	ProfileEmbedPolicy:           {Ifd0, []tiff.DataType{tiff.DTLong}, 1, false},
+	ProfileCopyright:             {Ifd0, []tiff.DataType{tiff.DTAscii, tiff.DTByte}, 0, false},
+	ForwardMatrix1:               {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	ForwardMatrix2:               {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	PreviewApplicationName:       {Ifd0, []tiff.DataType{tiff.DTAscii, tiff.DTByte}, 0, false},
+	PreviewApplicationVersion:    {Ifd0, []tiff.DataType{tiff.DTAscii, tiff.DTByte}, 0, false},
+	PreviewSettingsName:          {Ifd0, []tiff.DataType{tiff.DTAscii, tiff.DTByte}, 0, false},
+	PreviewSettingsDigest:        {Ifd0, []tiff.DataType{tiff.DTByte}, 16, false},
+	PreviewColorSpace:            {Ifd0, []tiff.DataType{tiff.DTLong}, 1, false},
+	PreviewDateTime:              {Ifd0, []tiff.DataType{tiff.DTAscii}, 0, false},
+	RawImageDigest:               {Ifd0, []tiff.DataType{tiff.DTByte}, 16, false},
+	OriginalRawFileDigest:        {Ifd0, []tiff.DataType{tiff.DTByte}, 16, false},
+	SubTileBlockSize:             {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong}, 2, false},
+	RowInterleaveFactor:          {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong}, 1, false},
+	ProfileLookTableDims:         {Ifd0, []tiff.DataType{tiff.DTLong}, 3, false},
+	ProfileLookTableData:         {Ifd0, []tiff.DataType{tiff.DTFloat}, 0, false},
+	OpcodeList1:                  {Ifd0, []tiff.DataType{tiff.DTUndefined}, 0, false},
+	OpcodeList2:                  {Ifd0, []tiff.DataType{tiff.DTUndefined}, 0, false},
+	OpcodeList3:                  {Ifd0, []tiff.DataType{tiff.DTUndefined}, 0, false},
+	NoiseProfile:                 {Ifd0, []tiff.DataType{tiff.DTDouble}, 0, false},
+	TimeCodes:                    {Ifd0, []tiff.DataType{tiff.DTByte}, 0, false},
+	FrameRate:                    {Ifd0, []tiff.DataType{tiff.DTSRational}, 1, false},
+	TStop:                        {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	ReelName:                     {Ifd0, []tiff.DataType{tiff.DTAscii}, 0, false},
+	OriginalDefaultFinalSize:     {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong}, 2, false},
+	OriginalBestQualityFinalSize: {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong}, 2, false},
+	OriginalDefaultCropSize:      {Ifd0, []tiff.DataType{tiff.DTShort, tiff.DTLong, tiff.DTRational}, 2, false},
+	CameraLabel:                  {Ifd0, []tiff.DataType{tiff.DTAscii}, 0, false},
+	ProfileHueSatMapEncoding:     {Ifd0, []tiff.DataType{tiff.DTLong}, 1, false},
+	ProfileLookTableEncoding:     {Ifd0, []tiff.DataType{tiff.DTLong}, 1, false},
+	BaselineExposureOffset:       {Ifd0, []tiff.DataType{tiff.DTSRational}, 1, false},
+	DefaultBlackRender:           {Ifd0, []tiff.DataType{tiff.DTLong}, 1, false},
+	NewRawImageDigest:            {Ifd0, []tiff.DataType{tiff.DTByte}, 16, false},
+	RawToPreviewGain:             {Ifd0, []tiff.DataType{tiff.DTDouble}, 1, false},
+	DefaultUserCrop:              {Ifd0, []tiff.DataType{tiff.DTRational}, 4, false},
+	DepthFormat:                  {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	DepthNear:                    {Ifd0, []tiff.DataType{tiff.DTRational}, 1, false},
+	DepthFar:                     {Ifd0, []tiff.DataType{tiff.DTRational}, 1, false},
+	DepthUnits:                   {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	DepthMeasureType:             {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	ProfileGainTableMap:          {Ifd0, []tiff.DataType{tiff.DTUndefined}, 0, false},
+	CalibrationIlluminant3:       {Ifd0, []tiff.DataType{tiff.DTShort}, 1, false},
+	CameraCalibration3:           {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	ColorMatrix3:                 {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	ForwardMatrix3:               {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	IlluminantData1:              {Ifd0, []tiff.DataType{tiff.DTUndefined}, 0, false},
+	IlluminantData2:              {Ifd0, []tiff.DataType{tiff.DTUndefined}, 0, false},
+	IlluminantData3:              {Ifd0, []tiff.DataType{tiff.DTUndefined}, 0, false},
+	ProfileHueSatMapData3:        {Ifd0, []tiff.DataType{tiff.DTFloat}, 0, false},
+	ReductionMatrix3:             {Ifd0, []tiff.DataType{tiff.DTSRational}, 0, false},
+	GPSVersionID:                 {IfdGPS, []tiff.DataType{tiff.DTByte}, 4, false},
+	GPSLatitudeRef:               {IfdGPS, []tiff.DataType{tiff.DTAscii}, 2, false},
+	GPSLatitude:                  {IfdGPS, []tiff.DataType{tiff.DTRational}, 3, false},
+	GPSLongitudeRef:              {IfdGPS, []tiff.DataType{tiff.DTAscii}, 2, false},
+	GPSLongitude:                 {IfdGPS, []tiff.DataType{tiff.DTRational}, 3, false},
+	GPSAltitudeRef:               {IfdGPS, []tiff.DataType{tiff.DTByte}, 1, false},
+	GPSAltitude:                  {IfdGPS, []tiff.DataType{tiff.DTRational}, 1, false},
+	GPSTimeStamp:                 {IfdGPS, []tiff.DataType{tiff.DTRational}, 3, false},
+	GPSImgDirectionRef:           {IfdGPS, []tiff.DataType{tiff.DTAscii}, 2, false},
+	GPSImgDirection:              {IfdGPS, []tiff.DataType{tiff.DTRational}, 1, false},
+	GPSDateStamp:                 {IfdGPS, []tiff.DataType{tiff.DTAscii}, 11, false},
 }
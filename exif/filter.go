@@ -0,0 +1,148 @@
+package exif
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// An Op is the comparison operator of a Filter.
+type Op int
+
+const (
+	OpEq       Op = iota // equal
+	OpNe                 // not equal
+	OpLt                 // less than
+	OpLe                 // less than or equal
+	OpGt                 // greater than
+	OpGe                 // greater than or equal
+	OpContains           // string field contains the value
+)
+
+var opNames = map[Op]string{
+	OpEq:       "==",
+	OpNe:       "!=",
+	OpLt:       "<",
+	OpLe:       "<=",
+	OpGt:       ">",
+	OpGe:       ">=",
+	OpContains: "contains",
+}
+
+func (op Op) String() string {
+	if s, ok := opNames[op]; ok {
+		return s
+	}
+	return fmt.Sprintf("Op(%d)", int(op))
+}
+
+// A Filter is a predicate on the value of a field, e.g.
+// Filter{ISOSpeedRatings, OpGt, 3200} or Filter{Model, OpContains, "X-T"}.
+//
+// Value is an int, int64 or float64 for numeric fields, compared with the
+// first value of the field (rationals as their quotient), or a string for
+// ASCII fields, compared with the field value stripped of surrounding
+// spaces. A filter does not match if the field is missing, or if its value
+// does not have the kind of Value.
+type Filter struct {
+	Field FieldName
+	Op    Op
+	Value interface{}
+}
+
+func (f Filter) String() string {
+	return fmt.Sprintf("%v %v %#v", f.Field, f.Op, f.Value)
+}
+
+// Match reports whether the field of x satisfies f.
+func (f Filter) Match(x *Exif) bool {
+	tag, err := x.Get(f.Field)
+	if err != nil {
+		return false
+	}
+	if s, ok := f.Value.(string); ok {
+		v, err := tag.StringVal()
+		if err != nil {
+			return false
+		}
+		v = strings.TrimSpace(v)
+		if f.Op == OpContains {
+			return strings.Contains(v, s)
+		}
+		return compare(f.Op, strings.Compare(v, s))
+	}
+
+	var want float64
+	switch v := f.Value.(type) {
+	case int:
+		want = float64(v)
+	case int64:
+		want = float64(v)
+	case float64:
+		want = v
+	default:
+		return false
+	}
+	if tag.Count == 0 || f.Op == OpContains {
+		return false
+	}
+	var got float64
+	switch tag.Format() {
+	case tiff.IntVal:
+		n, err := tag.Int64(0)
+		if err != nil {
+			return false
+		}
+		got = float64(n)
+	case tiff.RatVal:
+		num, den, err := tag.Rat2(0)
+		if err != nil || den == 0 {
+			return false
+		}
+		got = float64(num) / float64(den)
+	case tiff.FloatVal:
+		if got, err = tag.Float(0); err != nil {
+			return false
+		}
+	default:
+		return false
+	}
+	switch {
+	case got < want:
+		return compare(f.Op, -1)
+	case got > want:
+		return compare(f.Op, 1)
+	}
+	return compare(f.Op, 0)
+}
+
+// compare reports whether the result c of a three-way comparison satisfies
+// op.
+func compare(op Op, c int) bool {
+	switch op {
+	case OpEq:
+		return c == 0
+	case OpNe:
+		return c != 0
+	case OpLt:
+		return c < 0
+	case OpLe:
+		return c <= 0
+	case OpGt:
+		return c > 0
+	case OpGe:
+		return c >= 0
+	}
+	return false
+}
+
+// MatchAll reports whether x satisfies all filters.
+func MatchAll(x *Exif, filters ...Filter) bool {
+	for _, f := range filters {
+		if !f.Match(x) {
+			return false
+		}
+	}
+	return true
+}
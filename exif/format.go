@@ -0,0 +1,75 @@
+package exif
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// Format implements fmt.Formatter. The verbs are:
+//
+//	%s   the fields available through Get, one per line, as String
+//	%v   the fields available through Get on one line, sorted by name
+//	%+v  all tags, grouped by IFD in load order, with their field names
+//	%#v  like %+v, with the IFD offsets and the raw type, count and value
+//	     offset of each tag
+func (x *Exif) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 's':
+		fmt.Fprint(f, x.String())
+	case verb == 'v' && f.Flag('#'):
+		x.formatIfds(f, true)
+	case verb == 'v' && f.Flag('+'):
+		x.formatIfds(f, false)
+	case verb == 'v':
+		names := make([]string, 0, len(x.main))
+		for name := range x.main {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		fmt.Fprint(f, "{")
+		for i, name := range names {
+			if i > 0 {
+				fmt.Fprint(f, " ")
+			}
+			fmt.Fprintf(f, "%s:%s", name, x.main[FieldName(name)].Tag)
+		}
+		fmt.Fprint(f, "}")
+	default:
+		fmt.Fprintf(f, "%%!%c(*exif.Exif)", verb)
+	}
+}
+
+// formatIfds writes the tags of each IFD of x, with their raw encoding if
+// raw is true.
+func (x *Exif) formatIfds(f fmt.State, raw bool) {
+	names := map[*tiff.Tag]FieldName{}
+	for _, fl := range x.fields {
+		names[fl.Tag] = fl.Name
+	}
+	for ifd := Ifd0; ifd <= IfdSubIFD; ifd++ {
+		for i, d := range x.dirs[ifd] {
+			fmt.Fprintf(f, "%v", ifd)
+			if len(x.dirs[ifd]) > 1 {
+				fmt.Fprintf(f, "[%d]", i)
+			}
+			if raw {
+				fmt.Fprintf(f, " at offset %d, %d bytes, next IFD at %d", d.Layout.Offset, d.Layout.Length, d.Layout.Next)
+			}
+			fmt.Fprintln(f, ":")
+			for _, t := range d.Tags {
+				name, ok := names[t]
+				if !ok {
+					name = FieldName(fmt.Sprintf("%v%x", UnknownPrefix, t.Id))
+				}
+				if raw {
+					off, _ := t.ValueOffset()
+					fmt.Fprintf(f, "\t0x%04x %s type=%d count=%d offset=%d inline=%v: %s\n", t.Id, name, t.Type, t.Count, off, t.Inlined(), t)
+				} else {
+					fmt.Fprintf(f, "\t%s: %s\n", name, t)
+				}
+			}
+		}
+	}
+}
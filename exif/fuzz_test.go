@@ -0,0 +1,114 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// corruptExifs holds minimized inputs that used to crash or hang the decoder
+// (see also testdata/fuzz/FuzzDecode), or that exercise the same code paths.
+// None of them may cause a panic; those with wantErr must fail to decode.
+var corruptExifs = []struct {
+	name    string
+	hex     string
+	wantErr bool
+}{
+	{"IFD0 offset past end", "4D4D002A 7FFFFFF0", true},
+	{"tag count 0x7fff", "4D4D002A 00000008 7FFF 0100 0003 00000001 00010000", true},
+	{"recursive IFD chain", "4D4D002A 00000008" +
+		"0001 0100 0003 00000001 00010000 0000001A" +
+		"0001 0101 0003 00000001 00020000 00000008", true},
+	{"zero-component tag", "4D4D002A 00000008 0001 0100 0003 00000000 00000000 00000000", false},
+	{"overflowing value size", "4D4D002A 00000008 0001 011A 0005 20000001 00000008 00000000", true},
+	{"sub-IFD pointing to IFD0", "4D4D002A 00000008 0001 8769 0004 00000001 00000008 00000000", true},
+	{"sub-IFD offset past end", "4D4D002A 00000008 0001 8825 0004 00000001 7FFFFFFF 00000000", true},
+	{"thumbnail out of range", "4D4D002A 00000008" +
+		"0001 0100 0003 00000001 00010000 0000001A" +
+		"0002 0201 0004 00000001 0000FFFF 0202 0004 00000001 00000010 00000000", false},
+	{"truncated APP1", "FFD8 FFE1 0100 457869660000 4D4D002A", true},
+	{"APP1 without exif header", "FFD8 FFE1 0004 0000 FFD9", true},
+	{"JPEG XL box past end", "0000000C 4A584C20 0D0A870A FFFFFFFF 45786966", true},
+	{"CR3 ftyp box of 2^63 bytes", "00000001 66747970 7FFFFFFFFFFFFFFF 63727820 00000001", true},
+}
+
+func corruptExifData(t testing.TB, h string) []byte {
+	b, err := hex.DecodeString(strings.Replace(h, " ", "", -1))
+	if err != nil {
+		t.Fatalf("invalid hex fixture %q: %v", h, err)
+	}
+	return b
+}
+
+// exercise calls the accessors that interpret tag values, which must not
+// panic no matter what was decoded.
+func exercise(x *Exif) {
+	_ = x.String()
+	x.DateTime()
+	x.LatLong()
+	x.JpegThumbnail()
+	x.MarshalJSON()
+	x.MarshalBinary()
+	x.ExifToolFields()
+	for _, v := range []ValueStyle{ValueRaw, ValueNumeric, ValueHuman} {
+		x.ToMap(MapOptions{Values: v, Unknown: true, Binary: true})
+	}
+	x.Compliance()
+	x.Edits()
+	x.Verify()
+	x.Validate(Exif232)
+	x.Previews()
+	x.LocalDateTime(nil)
+	x.GPSDateTime()
+	x.Altitude()
+	x.Speed()
+	x.Track()
+	x.DOP()
+	x.Flash()
+	x.MakerNote()
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	for _, tt := range corruptExifs {
+		t.Run(tt.name, func(t *testing.T) {
+			x, err := Decode(bytes.NewReader(corruptExifData(t, tt.hex)))
+			if tt.wantErr && err == nil {
+				t.Error("no error decoding corrupt exif data")
+			}
+			if x != nil {
+				exercise(x)
+			}
+
+			x, _ = (&Decoder{Lenient: true}).Decode(bytes.NewReader(corruptExifData(t, tt.hex)))
+			if x != nil {
+				exercise(x)
+			}
+		})
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, tt := range corruptExifs {
+		f.Add(corruptExifData(f, tt.hex))
+	}
+	for _, name := range []string{
+		"samples/raw.exif",
+		"corrupt/max_uint32_exif.jpg",
+		"corrupt/huge_tag_exif.jpg",
+		"corrupt/infinite_loop_exif.jpg",
+	} {
+		if b, err := os.ReadFile(filepath.Join(*dataDir, name)); err == nil {
+			f.Add(b)
+		}
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		for _, dec := range []*Decoder{{}, {Lenient: true}} {
+			if x, _ := dec.Decode(bytes.NewReader(b)); x != nil {
+				exercise(x)
+			}
+		}
+	})
+}
@@ -0,0 +1,326 @@
+//go:build ignore
+// +build ignore
+
+// gen_fields generates the field constants and tag tables of a package from
+// its field registry, a JSON file listing the known fields with their
+// descriptions, data types and counts, and the tag IDs they are stored under
+// in each IFD (or maker note) space:
+//
+//	go run gen_fields.go -o fields.go fields.json
+//
+// The registries of the exif and mknote packages are fields.json in their
+// directories. To add a tag, add its field to a const block and its tag ID to
+// the spaces it can be found in, and run go generate.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// registry is the content of a fields.json file.
+type registry struct {
+	// Package is the name of the package the code is generated for.
+	Package string
+	// Doc holds comment lines written after the imports.
+	Doc    []string
+	Consts []*block
+	Spaces []*space
+}
+
+// A block is a const block of field names, with a doc comment.
+type block struct {
+	Doc    []string
+	Fields []*field
+	// Groups split the block into groups of fields headed by a comment.
+	Groups []*group
+}
+
+type group struct {
+	Comment string
+	Fields  []*field
+}
+
+type field struct {
+	// Name is the name of the constant, and Value the field name if it is
+	// not Name.
+	Name    string
+	Value   string
+	Comment string
+	// Types and Count are the data types and number of values the
+	// specifications define for the field (0 for any count).
+	Types []string
+	Count int
+	// Title, Text and Unit make up the Description of the field.
+	Title string
+	Text  string
+	Unit  string
+}
+
+// A space is a set of tags, such as those of an IFD or maker note, whose IDs
+// map to fields through a table. Several spaces may share a table.
+type space struct {
+	Name string
+	// Ifd is the IfdID of the space, if any.
+	Ifd      string
+	Table    string
+	TableDoc []string
+	// Namespace, if set, is the name of a variable listing the TagIDs of
+	// the space, named after their fields without Prefix.
+	Namespace    string
+	NamespaceDoc []string
+	Prefix       string
+	Tags         []*tag
+}
+
+type tag struct {
+	ID string
+	// Field is the name of the constant of the field, which may be
+	// qualified with the exif package.
+	Field string
+}
+
+var dataTypes = map[string]string{
+	"BYTE":      "tiff.DTByte",
+	"ASCII":     "tiff.DTAscii",
+	"SHORT":     "tiff.DTShort",
+	"LONG":      "tiff.DTLong",
+	"RATIONAL":  "tiff.DTRational",
+	"SBYTE":     "tiff.DTSByte",
+	"UNDEFINED": "tiff.DTUndefined",
+	"SSHORT":    "tiff.DTSShort",
+	"SLONG":     "tiff.DTSLong",
+	"SRATIONAL": "tiff.DTSRational",
+	"FLOAT":     "tiff.DTFloat",
+	"DOUBLE":    "tiff.DTDouble",
+}
+
+func main() {
+	out := flag.String("o", "fields.go", "output file")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Fatal("usage: go run gen_fields.go [-o fields.go] fields.json")
+	}
+	data, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var reg registry
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&reg); err != nil {
+		log.Fatalf("%v: %v", flag.Arg(0), err)
+	}
+	src, err := generate(&reg, flag.Arg(0))
+	if err != nil {
+		log.Fatalf("%v: %v", flag.Arg(0), err)
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate returns the source of the generated file for reg, read from the
+// named file.
+func generate(reg *registry, name string) ([]byte, error) {
+	fields := map[string]*field{}
+	var order []*field
+	for _, b := range reg.Consts {
+		for _, f := range b.fields() {
+			if fields[f.Name] != nil {
+				return nil, fmt.Errorf("duplicate field %v", f.Name)
+			}
+			for _, t := range f.Types {
+				if dataTypes[t] == "" {
+					return nil, fmt.Errorf("field %v: unknown data type %v", f.Name, t)
+				}
+			}
+			fields[f.Name] = f
+			order = append(order, f)
+		}
+	}
+
+	// the IFD of the first space holding each field, for its spec
+	ifds := map[string]string{}
+	tables := map[string]map[uint64]string{}
+	for _, sp := range reg.Spaces {
+		if tables[sp.Table] == nil {
+			tables[sp.Table] = map[uint64]string{}
+		}
+		for _, t := range sp.Tags {
+			id, err := strconv.ParseUint(t.ID, 0, 16)
+			if err != nil {
+				return nil, fmt.Errorf("space %v: bad tag ID %q", sp.Name, t.ID)
+			}
+			if f := tables[sp.Table][id]; f != "" {
+				return nil, fmt.Errorf("space %v: tag 0x%04X is both %v and %v in table %v", sp.Name, id, f, t.Field, sp.Table)
+			}
+			tables[sp.Table][id] = t.Field
+			if !strings.Contains(t.Field, ".") && fields[t.Field] == nil {
+				return nil, fmt.Errorf("space %v: tag 0x%04X has unknown field %v", sp.Name, id, t.Field)
+			}
+			if sp.Namespace != "" && !strings.HasPrefix(t.Field, sp.Prefix) {
+				return nil, fmt.Errorf("space %v: field %v lacks the prefix %v", sp.Name, t.Field, sp.Prefix)
+			}
+			if _, ok := ifds[t.Field]; !ok && sp.Ifd != "" {
+				ifds[t.Field] = sp.Ifd
+			}
+		}
+	}
+
+	fieldName := "FieldName"
+	if reg.Package != "exif" {
+		fieldName = "exif.FieldName"
+	}
+	var specs, descs bool
+	for _, f := range order {
+		specs = specs || len(f.Types) > 0
+		descs = descs || f.Title != ""
+	}
+
+	var buf bytes.Buffer
+	p := func(format string, args ...interface{}) { fmt.Fprintf(&buf, format+"\n", args...) }
+	doc := func(lines []string) {
+		for _, l := range lines {
+			p("// %s", l)
+		}
+	}
+	p("// Code generated by gen_fields.go from %s; DO NOT EDIT.", name)
+	p("")
+	p("package %s", reg.Package)
+	p("")
+	switch {
+	case reg.Package != "exif":
+		p("import %q", "github.com/rwcarlsen/goexif/exif")
+	case specs:
+		p("import %q", "github.com/rwcarlsen/goexif/tiff")
+	}
+	p("")
+	if len(reg.Doc) > 0 {
+		doc(reg.Doc)
+		p("")
+	}
+
+	for _, b := range reg.Consts {
+		doc(b.Doc)
+		p("const (")
+		groups := b.Groups
+		if len(b.Fields) > 0 {
+			groups = append([]*group{{Fields: b.Fields}}, groups...)
+		}
+		for i, g := range groups {
+			if i > 0 {
+				p("")
+			}
+			if g.Comment != "" {
+				p("\t// %s", g.Comment)
+			}
+			for _, f := range g.Fields {
+				value := f.Value
+				if value == "" {
+					value = f.Name
+				}
+				comment := ""
+				if f.Comment != "" {
+					comment = " // " + f.Comment
+				}
+				p("\t%s %s = %q%s", f.Name, fieldName, value, comment)
+			}
+		}
+		p(")")
+		p("")
+	}
+
+	var tableOrder []string
+	spaces := map[string][]*space{}
+	for _, sp := range reg.Spaces {
+		if spaces[sp.Table] == nil {
+			tableOrder = append(tableOrder, sp.Table)
+		}
+		spaces[sp.Table] = append(spaces[sp.Table], sp)
+	}
+	for _, table := range tableOrder {
+		doc(spaces[table][0].TableDoc)
+		p("var %s = map[uint16]%s{", table, fieldName)
+		shared := len(spaces[table]) > 1
+		for i, sp := range spaces[table] {
+			if shared {
+				if i > 0 {
+					p("")
+				}
+				p("\t// %s", sp.Name)
+			}
+			for _, t := range sp.Tags {
+				id, _ := strconv.ParseUint(t.ID, 0, 16)
+				p("\t0x%04X: %s,", id, t.Field)
+			}
+		}
+		p("}")
+		p("")
+	}
+
+	for _, sp := range reg.Spaces {
+		if sp.Namespace == "" {
+			continue
+		}
+		doc(sp.NamespaceDoc)
+		p("var %s = struct {", sp.Namespace)
+		for _, t := range sp.Tags {
+			p("\t%s TagID", strings.TrimPrefix(t.Field, sp.Prefix))
+		}
+		p("}{")
+		for _, t := range sp.Tags {
+			id, _ := strconv.ParseUint(t.ID, 0, 16)
+			p("\t%s: TagID{%s, 0x%04X},", strings.TrimPrefix(t.Field, sp.Prefix), sp.Ifd, id)
+		}
+		p("}")
+		p("")
+	}
+
+	if descs {
+		p("var descriptions = map[%s]Description{", fieldName)
+		for _, f := range order {
+			if f.Title != "" {
+				p("\t%s: {%q, %q, %q},", f.Name, f.Title, f.Text, f.Unit)
+			}
+		}
+		p("}")
+		p("")
+	}
+
+	if specs {
+		p("var fieldSpecs = map[%s]FieldSpec{", fieldName)
+		for _, f := range order {
+			if len(f.Types) == 0 {
+				continue
+			}
+			if ifds[f.Name] == "" {
+				return nil, fmt.Errorf("field %v has data types but is in no IFD", f.Name)
+			}
+			types := make([]string, len(f.Types))
+			for i, t := range f.Types {
+				types[i] = dataTypes[t]
+			}
+			p("\t%s: {%s, []tiff.DataType{%s}, %d, false},", f.Name, ifds[f.Name], strings.Join(types, ", "), f.Count)
+		}
+		p("}")
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// fields returns the fields of b, in order.
+func (b *block) fields() []*field {
+	fields := b.Fields
+	for _, g := range b.Groups {
+		fields = append(fields, g.Fields...)
+	}
+	return fields
+}
@@ -0,0 +1,116 @@
+package exif
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// A Geofence is an area of the surface of the Earth, such as the
+// surroundings of a home address, used to decide which photos to redact.
+type Geofence interface {
+	// Contains reports whether the point at latitude lat and longitude
+	// long, in degrees, lies within the area.
+	Contains(lat, long float64) bool
+}
+
+// A Circle is a Geofence holding the points within Radius meters of the
+// point at latitude Lat and longitude Long, in degrees.
+type Circle struct {
+	Lat, Long float64
+	Radius    float64
+}
+
+// Contains implements Geofence, using the great-circle distance.
+func (c Circle) Contains(lat, long float64) bool {
+	return distance(c.Lat, c.Long, lat, long) <= c.Radius
+}
+
+// distance returns the great-circle distance in meters between two points
+// given by their latitude and longitude in degrees, with the haversine
+// formula.
+func distance(lat1, long1, lat2, long2 float64) float64 {
+	rad := math.Pi / 180
+	dlat := (lat2 - lat1) * rad
+	dlong := (long2 - long1) * rad
+	a := math.Sin(dlat/2)*math.Sin(dlat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dlong/2)*math.Sin(dlong/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// A Polygon is a Geofence given by the latitude and longitude, in degrees,
+// of its vertices in order, e.g. the outline of a property. The last vertex
+// is joined to the first. Edges are straight lines in latitude and
+// longitude, which is accurate enough for areas of a few kilometers;
+// polygons must not cross the 180th meridian.
+type Polygon [][2]float64
+
+// Contains implements Geofence. Points on the edges may be reported on
+// either side.
+func (p Polygon) Contains(lat, long float64) bool {
+	// Count the edges crossed by a ray going east from the point.
+	var in bool
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		lat1, long1 := p[i][0], p[i][1]
+		lat2, long2 := p[j][0], p[j][1]
+		if (lat1 > lat) != (lat2 > lat) && long < long1+(lat-lat1)*(long2-long1)/(lat2-lat1) {
+			in = !in
+		}
+	}
+	return in
+}
+
+// InGeofence reports whether the GPS coordinates of x lie within any of
+// fences. It returns false if x has no valid coordinates.
+func (x *Exif) InGeofence(fences ...Geofence) bool {
+	lat, long, err := x.LatLong()
+	if err != nil {
+		return false
+	}
+	for _, f := range fences {
+		if f.Contains(lat, long) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveGPSWithin is like RemoveGPS, but only removes the GPS sub-IFD of
+// images whose coordinates lie within any of fences, e.g. circles around
+// the home addresses of the photographer, so that photos taken elsewhere
+// stay geotagged. Other images are copied unchanged. It reports whether the
+// GPS data was removed. Like RemoveGPS, it decodes the image leniently and
+// fails on a GPS IFD that cannot be decoded; an error is also returned if
+// the GPS IFD holds coordinates that cannot be parsed, as whether they lie
+// within the fences is unknown.
+func RemoveGPSWithin(r io.Reader, w io.Writer, fences ...Geofence) (removed bool, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return false, err
+	}
+	x, err := decodeIdentifying(data, IfdGPS)
+	if err != nil {
+		return false, err
+	}
+	if len(x.dirs[IfdGPS]) == 0 {
+		_, err = w.Write(data)
+		return false, err
+	}
+	if _, _, err := x.LatLong(); err != nil {
+		_, latErr := x.Get(GPSLatitude)
+		_, longErr := x.Get(GPSLongitude)
+		if latErr == nil || longErr == nil {
+			return false, fmt.Errorf("exif: cannot read the GPS position: %v", err)
+		}
+	}
+	if x.InGeofence(fences...) {
+		if err := x.removeGPS(data); err != nil {
+			return false, err
+		}
+		removed = true
+	}
+	_, err = w.Write(data)
+	return removed, err
+}
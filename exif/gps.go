@@ -0,0 +1,206 @@
+package exif
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// AltitudeRef is the value of the GPSAltitudeRef field.
+type AltitudeRef uint8
+
+const (
+	AboveSeaLevel AltitudeRef = 0
+	BelowSeaLevel AltitudeRef = 1
+)
+
+func (ref AltitudeRef) String() string {
+	switch ref {
+	case AboveSeaLevel:
+		return "Above sea level"
+	case BelowSeaLevel:
+		return "Below sea level"
+	}
+	return fmt.Sprintf("AltitudeRef(%d)", uint8(ref))
+}
+
+// NorthRef is the reference for a GPS direction, as given by the
+// GPSImgDirectionRef, GPSTrackRef and GPSDestBearingRef fields.
+type NorthRef string
+
+const (
+	TrueNorth     NorthRef = "T"
+	MagneticNorth NorthRef = "M"
+)
+
+// Speed is a speed in kilometers per hour, the default unit of GPSSpeed.
+type Speed float64
+
+const (
+	kmPerMile         = 1.609344
+	kmPerNauticalMile = 1.852
+)
+
+// KilometersPerHour returns s in kilometers per hour.
+func (s Speed) KilometersPerHour() float64 { return float64(s) }
+
+// MilesPerHour returns s in miles per hour.
+func (s Speed) MilesPerHour() float64 { return float64(s) / kmPerMile }
+
+// Knots returns s in knots.
+func (s Speed) Knots() float64 { return float64(s) / kmPerNauticalMile }
+
+// MetersPerSecond returns s in meters per second.
+func (s Speed) MetersPerSecond() float64 { return float64(s) / 3.6 }
+
+// ratField returns the first value of field name as a float (see GetFloat).
+func (x *Exif) ratField(name FieldName) (float64, error) {
+	return x.GetFloat(name)
+}
+
+// stringField returns the value of the string field name.
+func (x *Exif) stringField(name FieldName) (string, error) {
+	tag, err := x.Get(name)
+	if err != nil {
+		return "", err
+	}
+	return tag.StringVal()
+}
+
+// Altitude returns the GPS altitude of the photo in meters, which is negative
+// below sea level, and the altitude reference it was recorded with. A missing
+// GPSAltitudeRef is treated as AboveSeaLevel.
+func (x *Exif) Altitude() (meters float64, ref AltitudeRef, err error) {
+	meters, err = x.ratField(GPSAltitude)
+	if err != nil {
+		return 0, 0, err
+	}
+	if v, err := x.intField(GPSAltitudeRef); err == nil {
+		ref = AltitudeRef(v)
+	}
+	if ref == BelowSeaLevel {
+		meters = -meters
+	}
+	return meters, ref, nil
+}
+
+// Speed returns the speed of the GPS receiver when the photo was taken,
+// converted from the unit given by GPSSpeedRef ("K" for km/h, "M" for mph,
+// "N" for knots; km/h if missing).
+func (x *Exif) Speed() (Speed, error) {
+	v, err := x.ratField(GPSSpeed)
+	if err != nil {
+		return 0, err
+	}
+	ref, err := x.stringField(GPSSpeedRef)
+	if IsTagNotPresentError(err) {
+		ref = "K"
+	} else if err != nil {
+		return 0, err
+	}
+	switch ref {
+	case "K":
+		return Speed(v), nil
+	case "M":
+		return Speed(v * kmPerMile), nil
+	case "N":
+		return Speed(v * kmPerNauticalMile), nil
+	}
+	return 0, fmt.Errorf("exif: unknown GPSSpeedRef %q", ref)
+}
+
+// direction returns the direction in degrees stored in field name and its
+// reference stored in field refName (true north if missing).
+func (x *Exif) direction(name, refName FieldName) (float64, NorthRef, error) {
+	deg, err := x.ratField(name)
+	if err != nil {
+		return 0, "", err
+	}
+	ref, err := x.stringField(refName)
+	if IsTagNotPresentError(err) {
+		return deg, TrueNorth, nil
+	} else if err != nil {
+		return 0, "", err
+	}
+	switch NorthRef(ref) {
+	case TrueNorth, MagneticNorth:
+		return deg, NorthRef(ref), nil
+	}
+	return 0, "", fmt.Errorf("exif: unknown %v %q", refName, ref)
+}
+
+// ImgDirection returns the direction the camera was pointing in, in degrees
+// from 0 to 359.99, and whether it is relative to true or magnetic north.
+func (x *Exif) ImgDirection() (degrees float64, ref NorthRef, err error) {
+	return x.direction(GPSImgDirection, GPSImgDirectionRef)
+}
+
+// Track returns the direction of movement of the GPS receiver, in degrees
+// from 0 to 359.99, and whether it is relative to true or magnetic north.
+func (x *Exif) Track() (degrees float64, ref NorthRef, err error) {
+	return x.direction(GPSTrack, GPSTrackRef)
+}
+
+// DestBearing returns the bearing to the destination point, in degrees from
+// 0 to 359.99, and whether it is relative to true or magnetic north.
+func (x *Exif) DestBearing() (degrees float64, ref NorthRef, err error) {
+	return x.direction(GPSDestBearing, GPSDestBearingRef)
+}
+
+// DOP returns the GPS dilution of precision (HDOP for 2D measurements, PDOP
+// for 3D measurements, see GPSMeasureMode).
+func (x *Exif) DOP() (float64, error) {
+	return x.ratField(GPSDOP)
+}
+
+// PositioningError returns the horizontal positioning error of the GPS fix,
+// in meters.
+func (x *Exif) PositioningError() (meters float64, err error) {
+	return x.ratField(GPSHPositioningError)
+}
+
+// GPSDateTime returns the UTC time of the GPS fix, combining the GPSDateStamp
+// and GPSTimeStamp fields. Fractional seconds (e.g. 4513/100) are kept to the
+// nanosecond. Dates written with dashes ("2006-01-02") instead of colons are
+// accepted.
+func (x *Exif) GPSDateTime() (time.Time, error) {
+	dateTag, err := x.Get(GPSDateStamp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	timeTag, err := x.Get(GPSTimeStamp)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	dateStr, err := dateTag.StringVal()
+	if err != nil {
+		return time.Time{}, err
+	}
+	dateStr = strings.Replace(strings.TrimSpace(dateStr), "-", ":", -1)
+	date, err := time.Parse("2006:01:02", dateStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("exif: invalid GPSDateStamp: %v", err)
+	}
+
+	if timeTag.Count != 3 {
+		return time.Time{}, errors.New("exif: GPSTimeStamp does not hold 3 values")
+	}
+	var ns float64
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		num, den, err := timeTag.Rat2(i)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if den == 0 {
+			return time.Time{}, errors.New("exif: GPSTimeStamp has a zero denominator")
+		}
+		ns += ratFloat(num, den) * float64(unit)
+	}
+	if ns < 0 || ns >= float64(24*time.Hour+time.Second) {
+		return time.Time{}, errors.New("exif: GPSTimeStamp out of range")
+	}
+	return date.Add(time.Duration(math.Round(ns))), nil
+}
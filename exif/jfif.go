@@ -0,0 +1,235 @@
+package exif
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// ErrNoJFIF is returned by JFIF when the JPEG stream has no JFIF APP0
+// segment.
+var ErrNoJFIF = errors.New("exif: no JFIF segment")
+
+// JFIF density units.
+const (
+	JFIFNoUnits = 0 // the densities only give the pixel aspect ratio
+	JFIFDPI     = 1 // dots per inch
+	JFIFDPCM    = 2 // dots per centimeter
+)
+
+// JFIF holds the data of the JFIF APP0 segment written by many JPEG
+// encoders, and of its JFXX extension segments.
+type JFIF struct {
+	// Major and Minor are the JFIF version, e.g. 1 and 2 for 1.02.
+	Major, Minor int
+	// Units is the unit of XDensity and YDensity: JFIFNoUnits, JFIFDPI or
+	// JFIFDPCM.
+	Units       int
+	XDensity    int
+	YDensity    int
+	ThumbWidth  int
+	ThumbHeight int
+	// Thumbnail holds the ThumbWidth x ThumbHeight pixels of the thumbnail
+	// as RGB triplets, from the JFIF segment or from an RGB or palette
+	// JFXX thumbnail, or is nil.
+	Thumbnail []byte
+	// JPEGThumbnail holds the JPEG stream of a JFXX thumbnail, or is nil.
+	JPEGThumbnail []byte
+}
+
+// JFXX thumbnail extension codes.
+const (
+	jfxxJPEG    = 0x10
+	jfxxPalette = 0x11
+	jfxxRGB     = 0x13
+)
+
+// JFIF returns the data of the JFIF APP0 segment of the JPEG stream the EXIF
+// data was decoded from, or ErrNoJFIF.
+func (x *Exif) JFIF() (*JFIF, error) {
+	segs := x.AppSegments("JFIF")
+	if len(segs) == 0 || segs[0].Marker != jpeg_APP0 {
+		return nil, ErrNoJFIF
+	}
+	j, err := parseJFIF(segs[0].Data)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range x.AppSegments("JFXX") {
+		if seg.Marker == jpeg_APP0 {
+			j.parseJFXX(seg.Data)
+		}
+	}
+	return j, nil
+}
+
+// parseJFIF parses the payload of a JFIF APP0 segment, starting with the
+// "JFIF\x00" identifier.
+func parseJFIF(b []byte) (*JFIF, error) {
+	if len(b) < 14 {
+		return nil, errors.New("exif: JFIF segment too short")
+	}
+	j := &JFIF{
+		Major:       int(b[5]),
+		Minor:       int(b[6]),
+		Units:       int(b[7]),
+		XDensity:    int(binary.BigEndian.Uint16(b[8:])),
+		YDensity:    int(binary.BigEndian.Uint16(b[10:])),
+		ThumbWidth:  int(b[12]),
+		ThumbHeight: int(b[13]),
+	}
+	if n := 3 * j.ThumbWidth * j.ThumbHeight; n > 0 {
+		if len(b) < 14+n {
+			return nil, errors.New("exif: JFIF thumbnail data truncated")
+		}
+		j.Thumbnail = b[14 : 14+n]
+	}
+	return j, nil
+}
+
+// parseJFXX adds the thumbnail of the JFXX extension segment b to j, unless
+// it is malformed or j already has a thumbnail of the same kind.
+func (j *JFIF) parseJFXX(b []byte) {
+	if len(b) < 6 {
+		return
+	}
+	code, data := b[5], b[6:]
+	switch code {
+	case jfxxJPEG:
+		if j.JPEGThumbnail == nil {
+			j.JPEGThumbnail = data
+		}
+	case jfxxRGB:
+		if j.Thumbnail != nil || len(data) < 2 {
+			return
+		}
+		w, h := int(data[0]), int(data[1])
+		if n := 3 * w * h; n > 0 && len(data) >= 2+n {
+			j.ThumbWidth, j.ThumbHeight, j.Thumbnail = w, h, data[2:2+n]
+		}
+	case jfxxPalette:
+		if j.Thumbnail != nil || len(data) < 2+768 {
+			return
+		}
+		w, h := int(data[0]), int(data[1])
+		palette, idx := data[2:2+768], data[2+768:]
+		if w*h == 0 || len(idx) < w*h {
+			return
+		}
+		rgb := make([]byte, 0, 3*w*h)
+		for _, c := range idx[:w*h] {
+			rgb = append(rgb, palette[3*int(c):3*int(c)+3]...)
+		}
+		j.ThumbWidth, j.ThumbHeight, j.Thumbnail = w, h, rgb
+	}
+}
+
+// ThumbnailImage returns the RGB thumbnail of j as an image, or nil if j has
+// none.
+func (j *JFIF) ThumbnailImage() image.Image {
+	if j.Thumbnail == nil {
+		return nil
+	}
+	img := image.NewRGBA(image.Rect(0, 0, j.ThumbWidth, j.ThumbHeight))
+	for i := 0; i < j.ThumbWidth*j.ThumbHeight; i++ {
+		p := j.Thumbnail[3*i:]
+		img.Set(i%j.ThumbWidth, i/j.ThumbWidth, color.RGBA{p[0], p[1], p[2], 0xFF})
+	}
+	return img
+}
+
+// DPI returns the densities of j in dots per inch. ok is false if the
+// densities only give the pixel aspect ratio.
+func (j *JFIF) DPI() (x, y float64, ok bool) {
+	switch j.Units {
+	case JFIFDPI:
+		return float64(j.XDensity), float64(j.YDensity), true
+	case JFIFDPCM:
+		return float64(j.XDensity) * 2.54, float64(j.YDensity) * 2.54, true
+	}
+	return 0, 0, false
+}
+
+func (j *JFIF) String() string {
+	units := map[int]string{JFIFNoUnits: "aspect ratio", JFIFDPI: "dpi", JFIFDPCM: "dpcm"}[j.Units]
+	if units == "" {
+		units = fmt.Sprintf("units %d", j.Units)
+	}
+	s := fmt.Sprintf("JFIF %d.%02d, %dx%d %s", j.Major, j.Minor, j.XDensity, j.YDensity, units)
+	if j.Thumbnail != nil {
+		s += fmt.Sprintf(", %dx%d thumbnail", j.ThumbWidth, j.ThumbHeight)
+	}
+	return s
+}
+
+// A Resolution is the pixel density of an image, reconciled from the EXIF
+// XResolution, YResolution and ResolutionUnit fields and the JFIF segment.
+type Resolution struct {
+	// X and Y are the horizontal and vertical densities in dots per inch.
+	X, Y float64
+	// FromJFIF reports that the densities were taken from the JFIF segment
+	// because the EXIF data gives none.
+	FromJFIF bool
+	// Mismatch reports that both the EXIF data and the JFIF segment give
+	// densities and that they disagree, in which case the EXIF ones are
+	// used. Since JFIF densities are integers, densities rounding to the
+	// same integer agree.
+	Mismatch bool
+}
+
+// Resolution returns the pixel density of the image in dots per inch. The EXIF
+// fields are used when present, with the default ResolutionUnit of inches,
+// and the JFIF segment otherwise. Densities given without units, which only
+// give the pixel aspect ratio, are ignored. An error is returned if neither
+// gives the density.
+func (x *Exif) Resolution() (*Resolution, error) {
+	ex, ey, exifOK := x.exifDPI()
+	j, err := x.JFIF()
+	var jx, jy float64
+	var jfifOK bool
+	if err == nil {
+		jx, jy, jfifOK = j.DPI()
+	}
+
+	switch {
+	case exifOK:
+		r := &Resolution{X: ex, Y: ey}
+		if jfifOK {
+			// compare in the unit of the JFIF segment
+			f := 1.0
+			if j.Units == JFIFDPCM {
+				f = 2.54
+			}
+			r.Mismatch = math.Round(ex/f) != math.Round(jx/f) || math.Round(ey/f) != math.Round(jy/f)
+		}
+		return r, nil
+	case jfifOK:
+		return &Resolution{X: jx, Y: jy, FromJFIF: true}, nil
+	}
+	return nil, errors.New("exif: no image resolution")
+}
+
+// exifDPI returns the XResolution and YResolution fields in dots per inch.
+// ok is false if they are missing or malformed, or if ResolutionUnit is not
+// inches or centimeters.
+func (x *Exif) exifDPI() (xdpi, ydpi float64, ok bool) {
+	xres, err1 := x.GetFloat(XResolution)
+	yres, err2 := x.GetFloat(YResolution)
+	if err1 != nil || err2 != nil || xres <= 0 || yres <= 0 {
+		return 0, 0, false
+	}
+	unit, err := x.intField(ResolutionUnit)
+	if err != nil {
+		unit = 2
+	}
+	switch unit {
+	case 2:
+		return xres, yres, true
+	case 3:
+		return xres * 2.54, yres * 2.54, true
+	}
+	return 0, 0, false
+}
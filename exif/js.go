@@ -0,0 +1,146 @@
+//go:build js && wasm
+
+package exif
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"syscall/js"
+)
+
+// jsChunkSize is the number of bytes JSReader reads from a Blob at once.
+const jsChunkSize = 64 << 10
+
+// A JSReader is an io.ReaderAt reading the bytes of a JavaScript Blob, such
+// as a File picked by the user in a browser, or of an ArrayBuffer or typed
+// array, with Go compiled to WebAssembly. Blobs are read in chunks with
+// Blob.slice and arrayBuffer, so that only the parts of a large file needed
+// to decode its EXIF data are loaded into memory.
+//
+// Reading a Blob waits for JavaScript promises, so it must not be done from
+// a js.Func callback directly, which would deadlock: start a goroutine from
+// the callback instead.
+type JSReader struct {
+	v    js.Value // the Blob, or a Uint8Array
+	blob bool
+	size int64
+
+	// the last chunk read from a Blob
+	chunk    []byte
+	chunkOff int64
+}
+
+// NewJSReader returns a JSReader reading from v, which must be a Blob (or
+// File), an ArrayBuffer or a typed array.
+func NewJSReader(v js.Value) (*JSReader, error) {
+	global := js.Global()
+	switch {
+	case v.Type() != js.TypeObject:
+		return nil, fmt.Errorf("exif: cannot read from JavaScript %v", v.Type())
+	case jsInstanceOf(v, "Blob"):
+		return &JSReader{v: v, blob: true, size: int64(v.Get("size").Int())}, nil
+	case jsInstanceOf(v, "ArrayBuffer"):
+		v = global.Get("Uint8Array").New(v)
+	case global.Get("ArrayBuffer").Call("isView", v).Bool():
+		v = global.Get("Uint8Array").New(v.Get("buffer"), v.Get("byteOffset"), v.Get("byteLength"))
+	default:
+		return nil, errors.New("exif: JavaScript value is not a Blob, ArrayBuffer or typed array")
+	}
+	return &JSReader{v: v, size: int64(v.Get("byteLength").Int())}, nil
+}
+
+// jsInstanceOf reports whether v is an instance of the global constructor
+// name, which may not be defined, e.g. Blob in old versions of Node.js.
+func jsInstanceOf(v js.Value, name string) bool {
+	c := js.Global().Get(name)
+	return c.Type() == js.TypeFunction && v.InstanceOf(c)
+}
+
+// Size returns the size in bytes of the data read by r.
+func (r *JSReader) Size() int64 { return r.size }
+
+// ReadAt implements io.ReaderAt.
+func (r *JSReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("exif: negative offset")
+	}
+	var n int
+	for n < len(p) && off < r.size {
+		var m int
+		if r.blob {
+			if off < r.chunkOff || off >= r.chunkOff+int64(len(r.chunk)) {
+				if err := r.readChunk(off); err != nil {
+					return n, err
+				}
+			}
+			m = copy(p[n:], r.chunk[off-r.chunkOff:])
+		} else {
+			end := off + int64(len(p)-n)
+			if end > r.size {
+				end = r.size
+			}
+			m = js.CopyBytesToGo(p[n:], r.v.Call("subarray", off, end))
+		}
+		n += m
+		off += int64(m)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// readChunk reads the chunk of the Blob starting at off.
+func (r *JSReader) readChunk(off int64) error {
+	end := off + jsChunkSize
+	if end > r.size {
+		end = r.size
+	}
+	buf, err := jsAwait(r.v.Call("slice", off, end).Call("arrayBuffer"))
+	if err != nil {
+		return err
+	}
+	chunk := make([]byte, end-off)
+	js.CopyBytesToGo(chunk, js.Global().Get("Uint8Array").New(buf))
+	r.chunk, r.chunkOff = chunk, off
+	return nil
+}
+
+// jsAwait waits for the promise p to settle and returns its value.
+func jsAwait(p js.Value) (js.Value, error) {
+	done := make(chan struct{})
+	var val js.Value
+	var err error
+	onDone := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		val = args[0]
+		close(done)
+		return nil
+	})
+	defer onDone.Release()
+	onErr := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		err = fmt.Errorf("exif: reading JavaScript Blob failed: %v", args[0].Call("toString").String())
+		close(done)
+		return nil
+	})
+	defer onErr.Release()
+	p.Call("then", onDone, onErr)
+	<-done
+	return val, err
+}
+
+// DecodeJS is like Decode, but reads the JavaScript Blob, File, ArrayBuffer
+// or typed array v with a JSReader.
+func DecodeJS(v js.Value) (*Exif, error) {
+	return new(Decoder).DecodeJS(v)
+}
+
+// DecodeJS is like the package-level DecodeJS function, but honors the
+// options set in dec.
+func (dec *Decoder) DecodeJS(v js.Value) (*Exif, error) {
+	r, err := NewJSReader(v)
+	if err != nil {
+		return nil, err
+	}
+	return dec.DecodeReaderAt(r, r.Size())
+}
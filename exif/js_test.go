@@ -0,0 +1,44 @@
+//go:build js && wasm
+
+package exif
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"syscall/js"
+	"testing"
+)
+
+func TestDecodeJS(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(arr, data)
+
+	for name, v := range map[string]js.Value{
+		"ArrayBuffer": arr.Get("buffer"),
+		"Uint8Array":  arr,
+		"DataView":    js.Global().Get("DataView").New(arr.Get("buffer")),
+		"Blob":        js.Global().Get("Blob").New([]interface{}{arr}),
+	} {
+		x, err := DecodeJS(v)
+		if err != nil {
+			t.Errorf("%v: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(x.Raw, want.Raw) || len(x.Fields()) != len(want.Fields()) {
+			t.Errorf("%v: decoded data differs", name)
+		}
+	}
+
+	if _, err := DecodeJS(js.ValueOf(42)); err == nil {
+		t.Error("DecodeJS accepted a number")
+	}
+}
@@ -0,0 +1,60 @@
+package exif
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// LogFields lists the fields logged by Exif.LogValue: those describing the
+// camera, the capture settings and the image, but not its location or the
+// serial numbers identifying its owner.
+var LogFields = []FieldName{
+	Make, Model, LensModel, Software,
+	DateTimeOriginal, OffsetTimeOriginal,
+	PixelXDimension, PixelYDimension, Orientation,
+	ExposureTime, FNumber, ISOSpeedRatings, FocalLength, Flash,
+}
+
+// LogValue implements slog.LogValuer, logging the LogFields of x present as
+// a group of attributes keyed by field name, e.g.
+//
+//	logger.Info("upload", "exif", x)
+//
+// Values are rendered as ToMap does with ValueRaw, and binary values are
+// omitted. Use Log to select other fields.
+func (x *Exif) LogValue() slog.Value {
+	return x.Log(LogFields...).LogValue()
+}
+
+// Log returns a slog.LogValuer logging the named fields of x present, in the
+// order given, like LogValue. If no names are given, all fields are logged
+// in the order of their names.
+func (x *Exif) Log(names ...FieldName) slog.LogValuer {
+	return exifLog{x, names}
+}
+
+type exifLog struct {
+	x     *Exif
+	names []FieldName
+}
+
+func (l exifLog) LogValue() slog.Value {
+	names := l.names
+	if len(names) == 0 {
+		for name := range l.x.main {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	}
+	var attrs []slog.Attr
+	for _, name := range names {
+		tag, err := l.x.Get(name)
+		if err != nil || isBinary(tag) {
+			continue
+		}
+		if v := mapValue(tag, false); v != nil {
+			attrs = append(attrs, slog.Any(string(name), v))
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
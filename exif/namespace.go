@@ -0,0 +1,46 @@
+package exif
+
+import (
+	"fmt"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// TagID identifies a tag by the IFD it is stored in and its tag ID. Unlike a
+// FieldName, which may be loaded from several IFDs (e.g. a camera writing
+// DateTime to IFD0 and the Exif sub-IFD), a TagID is unambiguous. The IFD0,
+// IFD1, ExifIFD, GPS and Interop namespaces list the known TagIDs; the
+// FieldName constants remain available as before.
+type TagID struct {
+	Ifd IfdID
+	ID  uint16
+}
+
+// Name returns the field name the tag is loaded under, or the UnknownPrefix
+// name if it is not a known field of its IFD.
+func (t TagID) Name() FieldName {
+	var m map[uint16]FieldName
+	switch t.Ifd {
+	case Ifd0, IfdExif:
+		m = exifFields
+	case Ifd1:
+		m = thumbnailFields
+	case IfdGPS:
+		m = gpsFields
+	case IfdInterop:
+		m = interopFields
+	}
+	if name, ok := m[t.ID]; ok {
+		return name
+	}
+	return FieldName(fmt.Sprintf("%v%x", UnknownPrefix, t.ID))
+}
+
+func (t TagID) String() string {
+	return fmt.Sprintf("%v:0x%04x", t.Ifd, t.ID)
+}
+
+// GetTag retrieves the tag identified by id. It is GetTagByID(id.Ifd, id.ID).
+func (x *Exif) GetTag(id TagID) (*tiff.Tag, error) {
+	return x.GetTagByID(id.Ifd, id.ID)
+}
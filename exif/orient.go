@@ -0,0 +1,168 @@
+package exif
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"io"
+)
+
+// DecodeImageOriented decodes the JPEG image in r with image/jpeg together
+// with its EXIF data, and returns the image transformed according to its
+// Orientation tag so that it is upright. A missing or undecodable EXIF block
+// is not an error: the image is then returned as stored, with a nil *Exif.
+func DecodeImageOriented(r io.Reader) (image.Image, *Exif, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	x, err := Decode(bytes.NewReader(data))
+	if err != nil && IsCriticalError(err) {
+		return img, nil, nil
+	}
+	orientation := 1
+	if tag, err := x.Get(Orientation); err == nil && tag.Count > 0 {
+		if o, err := tag.Int(0); err == nil {
+			orientation = o
+		}
+	}
+	return orient(img, orientation), x, nil
+}
+
+// orient returns img transformed to display it upright given its EXIF
+// orientation. img is returned as is for orientation 1 and invalid values.
+// RGBA, NRGBA, Gray and CMYK images, and YCbCr images whose size is a
+// multiple of the chroma subsampling, are transformed by copying their
+// pixel bytes; other images are converted to RGBA pixel by pixel.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	r := image.Rect(0, 0, w, h)
+	if orientation >= 5 {
+		// the transpositions swap width and height
+		r = image.Rect(0, 0, h, w)
+	}
+	if w > 0 && h > 0 {
+		switch src := img.(type) {
+		case *image.RGBA:
+			dst := image.NewRGBA(r)
+			orientPlane(dst.Pix, dst.Stride, src.Pix[src.PixOffset(b.Min.X, b.Min.Y):], src.Stride, w, h, 4, orientation)
+			return dst
+		case *image.NRGBA:
+			dst := image.NewNRGBA(r)
+			orientPlane(dst.Pix, dst.Stride, src.Pix[src.PixOffset(b.Min.X, b.Min.Y):], src.Stride, w, h, 4, orientation)
+			return dst
+		case *image.Gray:
+			dst := image.NewGray(r)
+			orientPlane(dst.Pix, dst.Stride, src.Pix[src.PixOffset(b.Min.X, b.Min.Y):], src.Stride, w, h, 1, orientation)
+			return dst
+		case *image.CMYK:
+			dst := image.NewCMYK(r)
+			orientPlane(dst.Pix, dst.Stride, src.Pix[src.PixOffset(b.Min.X, b.Min.Y):], src.Stride, w, h, 4, orientation)
+			return dst
+		case *image.YCbCr:
+			if dst := orientYCbCr(src, orientation); dst != nil {
+				return dst
+			}
+		}
+	}
+
+	dst := image.NewRGBA(r)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := orientPoint(x, y, w, h, orientation)
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// orientPoint returns the position of pixel (x, y) of a w×h image once
+// transformed for orientation.
+func orientPoint(x, y, w, h, orientation int) (dx, dy int) {
+	switch orientation {
+	case 2: // mirrored horizontally
+		return w - 1 - x, y
+	case 3: // rotated 180
+		return w - 1 - x, h - 1 - y
+	case 4: // mirrored vertically
+		return x, h - 1 - y
+	case 5: // transposed
+		return y, x
+	case 6: // needs rotating 90 clockwise
+		return h - 1 - y, x
+	case 7: // transversed
+		return h - 1 - y, w - 1 - x
+	case 8: // needs rotating 90 counterclockwise
+		return y, w - 1 - x
+	}
+	return x, y
+}
+
+// orientPlane copies the w×h pixels of bpp bytes each of src, whose rows are
+// stride bytes apart, to dst, whose rows are dstStride bytes apart,
+// transformed for orientation. Rows are copied whole when the orientation
+// keeps them in order.
+func orientPlane(dst []byte, dstStride int, src []byte, stride, w, h, bpp, orientation int) {
+	for y := 0; y < h; y++ {
+		row := src[y*stride : y*stride+w*bpp]
+		if orientation == 4 {
+			copy(dst[(h-1-y)*dstStride:], row)
+			continue
+		}
+		for x := 0; x < w; x++ {
+			dx, dy := orientPoint(x, y, w, h, orientation)
+			i := dy*dstStride + dx*bpp
+			copy(dst[i:i+bpp], row[x*bpp:])
+		}
+	}
+}
+
+// orientYCbCr returns src transformed for orientation by transforming its
+// planes, or nil if its bounds do not start at the origin, its size is not a
+// multiple of its chroma subsampling or the transposed subsampling is not
+// supported by image.YCbCr.
+func orientYCbCr(src *image.YCbCr, orientation int) *image.YCbCr {
+	sx, sy, ratio := 1, 1, src.SubsampleRatio
+	switch ratio {
+	case image.YCbCrSubsampleRatio422:
+		sx = 2
+	case image.YCbCrSubsampleRatio420:
+		sx, sy = 2, 2
+	case image.YCbCrSubsampleRatio440:
+		sy = 2
+	case image.YCbCrSubsampleRatio411:
+		sx = 4
+	case image.YCbCrSubsampleRatio410:
+		sx, sy = 4, 2
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if b.Min != (image.Point{}) || w%sx != 0 || h%sy != 0 {
+		return nil
+	}
+	r := image.Rect(0, 0, w, h)
+	if orientation >= 5 {
+		r = image.Rect(0, 0, h, w)
+		switch ratio {
+		case image.YCbCrSubsampleRatio422:
+			ratio = image.YCbCrSubsampleRatio440
+		case image.YCbCrSubsampleRatio440:
+			ratio = image.YCbCrSubsampleRatio422
+		case image.YCbCrSubsampleRatio411, image.YCbCrSubsampleRatio410:
+			return nil
+		}
+	}
+	dst := image.NewYCbCr(r, ratio)
+	orientPlane(dst.Y, dst.YStride, src.Y, src.YStride, w, h, 1, orientation)
+	orientPlane(dst.Cb, dst.CStride, src.Cb, src.CStride, w/sx, h/sy, 1, orientation)
+	orientPlane(dst.Cr, dst.CStride, src.Cr, src.CStride, w/sx, h/sy, 1, orientation)
+	return dst
+}
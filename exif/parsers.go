@@ -0,0 +1,90 @@
+package exif
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// DefaultPriority is the priority of the parsers registered by
+// RegisterParsers. The parser loading the standard fields, which the parsers
+// registered by other packages depend on, has the lowest priority possible
+// and always runs first.
+const DefaultPriority = 0
+
+type registration struct {
+	p        Parser
+	priority int
+}
+
+var (
+	parsersMu sync.RWMutex
+	// parsers holds the registered parsers in call order.
+	parsers []registration
+)
+
+func init() {
+	RegisterParsersPriority(math.MinInt, &parser{})
+}
+
+// RegisterParsers registers one or more parsers to be automatically called
+// when decoding EXIF data via the Decode function, with DefaultPriority.
+// It is safe for concurrent use, e.g. by init functions of several packages;
+// decodes already in progress are not affected.
+func RegisterParsers(ps ...Parser) {
+	RegisterParsersPriority(DefaultPriority, ps...)
+}
+
+// RegisterParsersPriority is like RegisterParsers, but registers the parsers
+// with the given priority. Decode calls parsers in order of increasing
+// priority, and parsers of equal priority in the order they were registered,
+// so a parser that uses the fields loaded by another one should be
+// registered with a higher priority.
+func RegisterParsersPriority(priority int, ps ...Parser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	for _, p := range ps {
+		parsers = append(parsers, registration{p, priority})
+	}
+	sort.SliceStable(parsers, func(i, j int) bool {
+		return parsers[i].priority < parsers[j].priority
+	})
+}
+
+// UnregisterParsers removes every registration of the given parsers, which
+// must be comparable (e.g. pointers, as the parsers of the mknote package
+// are). Parsers that are not registered are ignored.
+func UnregisterParsers(ps ...Parser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	kept := parsers[:0]
+	for _, r := range parsers {
+		if !containsParser(ps, r.p) {
+			kept = append(kept, r)
+		}
+	}
+	for i := len(kept); i < len(parsers); i++ {
+		parsers[i] = registration{}
+	}
+	parsers = kept
+}
+
+// Parsers returns the registered parsers in the order Decode calls them.
+func Parsers() []Parser {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	ps := make([]Parser, len(parsers))
+	for i, r := range parsers {
+		ps[i] = r.p
+	}
+	return ps
+}
+
+func containsParser(ps []Parser, p Parser) bool {
+	for _, q := range ps {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
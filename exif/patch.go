@@ -0,0 +1,279 @@
+package exif
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// PatchTag overwrites the value of the tag for field name in place, without
+// re-encoding the file: w must write to the same file (or a byte-identical
+// copy of it) that x was decoded from, e.g. an *os.File opened read-write.
+// Only the bytes of the tag value are written, which makes this suitable for
+// same-size edits of very large files.
+//
+// val holds the new raw value bytes, encoded in the byte order of x.Tiff.Order,
+// and must have the same length as the current value. x itself is not
+// updated; decode the file again to observe the change.
+func (x *Exif) PatchTag(w io.WriterAt, name FieldName, val []byte) error {
+	f, err := x.GetField(name)
+	if err != nil {
+		return err
+	}
+	return x.patchTag(w, f, val)
+}
+
+// PatchInt is like PatchTag, but encodes the integer values v according to
+// the type of the tag, which must be one of the tiff integer types and hold
+// exactly len(v) values. E.g. PatchInt(w, Orientation, 1) normalizes the
+// orientation of a photo.
+func (x *Exif) PatchInt(w io.WriterAt, name FieldName, v ...int64) error {
+	f, err := x.GetField(name)
+	if err != nil {
+		return err
+	}
+	return x.patchInt(w, f, v)
+}
+
+// PatchString is like PatchTag, but writes the ASCII string s, padded with
+// NUL bytes to the length of the current value. s must be shorter than the
+// current value, leaving room for at least one terminating NUL byte.
+func (x *Exif) PatchString(w io.WriterAt, name FieldName, s string) error {
+	f, err := x.GetField(name)
+	if err != nil {
+		return err
+	}
+	return x.patchString(w, f, s)
+}
+
+// PatchTagID is like PatchTag, but patches the tag identified by id, e.g.
+// IFD0.Make, which is unambiguous for tags that occur in several IFDs.
+func (x *Exif) PatchTagID(w io.WriterAt, id TagID, val []byte) error {
+	f, err := x.fieldByID(id)
+	if err != nil {
+		return err
+	}
+	return x.patchTag(w, f, val)
+}
+
+// PatchIntID is like PatchInt, but patches the tag identified by id.
+func (x *Exif) PatchIntID(w io.WriterAt, id TagID, v ...int64) error {
+	f, err := x.fieldByID(id)
+	if err != nil {
+		return err
+	}
+	return x.patchInt(w, f, v)
+}
+
+// PatchStringID is like PatchString, but patches the tag identified by id.
+func (x *Exif) PatchStringID(w io.WriterAt, id TagID, s string) error {
+	f, err := x.fieldByID(id)
+	if err != nil {
+		return err
+	}
+	return x.patchString(w, f, s)
+}
+
+// fieldByID returns the field of the tag identified by id, see GetTag.
+func (x *Exif) fieldByID(id TagID) (*Field, error) {
+	tag, err := x.GetTag(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range x.fields {
+		if f.Ifd == id.Ifd && f.Tag == tag {
+			return f, nil
+		}
+	}
+	return &Field{Name: id.Name(), Ifd: id.Ifd, Tag: tag}, nil
+}
+
+func (x *Exif) patchTag(w io.WriterAt, f *Field, val []byte) error {
+	if len(val) != len(f.Tag.Val) {
+		return fmt.Errorf("exif: cannot patch %v: new value is %d bytes, want %d", f.Name, len(val), len(f.Tag.Val))
+	}
+	off, err := x.patchOffset(f)
+	if err != nil {
+		return err
+	}
+	_, err = w.WriteAt(val, off)
+	return err
+}
+
+func (x *Exif) patchInt(w io.WriterAt, f *Field, v []int64) error {
+	tag := f.Tag
+	if int(tag.Count) != len(v) {
+		return fmt.Errorf("exif: cannot patch %v: got %d values, want %d", f.Name, len(v), tag.Count)
+	}
+	order := x.Tiff.Order
+	val := make([]byte, len(tag.Val))
+	for i, n := range v {
+		switch tag.Type {
+		case tiff.DTByte, tiff.DTSByte:
+			val[i] = byte(n)
+		case tiff.DTShort, tiff.DTSShort:
+			order.PutUint16(val[2*i:], uint16(n))
+		case tiff.DTLong, tiff.DTSLong:
+			order.PutUint32(val[4*i:], uint32(n))
+		default:
+			return fmt.Errorf("exif: cannot patch %v: not an integer tag", f.Name)
+		}
+	}
+	return x.patchTag(w, f, val)
+}
+
+func (x *Exif) patchString(w io.WriterAt, f *Field, s string) error {
+	tag := f.Tag
+	if tag.Type != tiff.DTAscii {
+		return fmt.Errorf("exif: cannot patch %v: not an ASCII tag", f.Name)
+	}
+	if len(s) >= len(tag.Val) {
+		return fmt.Errorf("exif: cannot patch %v: %q does not fit in %d bytes", f.Name, s, len(tag.Val))
+	}
+	val := make([]byte, len(tag.Val))
+	copy(val, s)
+	return x.patchTag(w, f, val)
+}
+
+// patchOffset returns the offset of the value of f in the stream x was
+// decoded from.
+func (x *Exif) patchOffset(f *Field) (int64, error) {
+	base, ok := x.TiffOffset()
+	if !ok {
+		return 0, errors.New("exif: cannot patch: offset of the EXIF data in the file is unknown")
+	}
+	if f.Ifd == IfdMakerNote {
+		// Maker note offsets may be relative to the maker note rather
+		// than to the tiff header.
+		return 0, fmt.Errorf("exif: cannot patch maker note field %v", f.Name)
+	}
+	tag := f.Tag
+	off, ok := tag.ValueOffset()
+	if !ok {
+		return 0, fmt.Errorf("exif: cannot patch %v: value offset is unknown", f.Name)
+	}
+	if off+int64(len(tag.Val)) > int64(len(x.Raw)) {
+		return 0, fmt.Errorf("exif: cannot patch %v: value lies outside the EXIF data", f.Name)
+	}
+	return base + off, nil
+}
+
+// RemoveGPS copies the image in r to w with its GPS sub-IFD removed: the GPS
+// pointer is deleted from IFD0, and the GPS IFD and its tag values are
+// overwritten with zero bytes. Nothing else is moved, so all other tags,
+// including maker notes whose offsets are absolute, are left intact. Images
+// without a GPS sub-IFD are copied unchanged. GPS data stored elsewhere,
+// e.g. in XMP packets, is not removed.
+//
+// The image is decoded leniently, so that the GPS IFD is removed even if
+// some of its entries cannot be decoded. An error is returned if IFD0
+// points to a GPS IFD that cannot be decoded at all.
+func RemoveGPS(r io.Reader, w io.Writer) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	x, err := decodeIdentifying(data, IfdGPS)
+	if err != nil {
+		return err
+	}
+	if len(x.dirs[IfdGPS]) > 0 {
+		if err := x.removeGPS(data); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// identifyingPointers are the tags of the IFDs pointing to the sub-IFDs
+// decodeIdentifying checks.
+var identifyingPointers = map[IfdID]TagID{
+	IfdExif: IFD0.ExifIFDPointer,
+	IfdGPS:  IFD0.GPSInfoIFDPointer,
+}
+
+// decodeIdentifying decodes data leniently for the functions removing or
+// rewriting identifying data, which must find the tags of sub-IFDs holding
+// entries that cannot be decoded. As leaving the data of a sub-IFD in place
+// must not go unnoticed, an error is returned if any of ifds is pointed to
+// but could not be decoded.
+func decodeIdentifying(data []byte, ifds ...IfdID) (*Exif, error) {
+	x, err := (&Decoder{Lenient: true}).Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	for _, ifd := range ifds {
+		if len(x.dirs[ifd]) > 0 {
+			continue
+		}
+		_, extra := x.extra[ifd]
+		if _, err := x.GetTag(identifyingPointers[ifd]); err == nil || extra {
+			return nil, fmt.Errorf("exif: %v cannot be decoded", ifd)
+		}
+	}
+	return x, nil
+}
+
+// removeGPS removes the GPS sub-IFD from data, the stream x was decoded
+// from, as described by RemoveGPS.
+func (x *Exif) removeGPS(data []byte) error {
+	base, ok := x.TiffOffset()
+	if !ok || len(x.dirs[Ifd0]) == 0 {
+		return errors.New("exif: cannot remove GPS data: offset of the EXIF data in the file is unknown")
+	}
+	if _, ok := x.extra[IfdGPS]; ok {
+		return errors.New("exif: cannot remove GPS data stored outside the EXIF data")
+	}
+	if base+int64(len(x.Raw)) > int64(len(data)) || !bytes.Equal(data[base:base+int64(len(x.Raw))], x.Raw) {
+		return errors.New("exif: cannot remove GPS data: EXIF data is not contiguous in the file")
+	}
+	raw := data[base : base+int64(len(x.Raw))]
+	order := x.Tiff.Order
+	zero := func(start, end int64) {
+		if start < 0 || end > int64(len(raw)) || start > end {
+			return
+		}
+		for i := start; i < end; i++ {
+			raw[i] = 0
+		}
+	}
+
+	// Delete the pointer entry from IFD0, moving the entries after it and
+	// the next IFD offset down.
+	ifd0 := x.dirs[Ifd0][0].Layout.Offset
+	if ifd0 < 0 || ifd0+2 > int64(len(raw)) {
+		return errors.New("exif: cannot remove GPS data: IFD0 lies outside the EXIF data")
+	}
+	n := int64(order.Uint16(raw[ifd0:]))
+	entries := ifd0 + 2
+	end := entries + 12*n + 4
+	if end > int64(len(raw)) {
+		return errors.New("exif: cannot remove GPS data: IFD0 lies outside the EXIF data")
+	}
+	for i := int64(0); i < n; i++ {
+		e := entries + 12*i
+		if order.Uint16(raw[e:]) != 0x8825 {
+			continue
+		}
+		copy(raw[e:], raw[e+12:end])
+		zero(end-12, end)
+		order.PutUint16(raw[ifd0:], uint16(n-1))
+		break
+	}
+
+	for _, d := range x.dirs[IfdGPS] {
+		for _, t := range d.Tags {
+			if !t.Inlined() {
+				zero(int64(t.ValOffset), int64(t.ValOffset)+int64(len(t.Val)))
+			}
+		}
+		if d.Layout.Offset >= 0 {
+			zero(d.Layout.Offset, d.Layout.Offset+d.Layout.Length)
+		}
+	}
+	return nil
+}
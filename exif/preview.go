@@ -0,0 +1,224 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// ErrNoPreview is returned by ExtractPreview for files without an embedded
+// JPEG image.
+var ErrNoPreview = errors.New("exif: no embedded JPEG image found")
+
+// A Preview is a JPEG image embedded in a file, such as the preview images
+// of raw files or the thumbnail of IFD1.
+type Preview struct {
+	// Source tells where the image was found: the IFD holding it (e.g.
+	// "IFD0" or "SubIFD1"), "JpgFromRaw" for Panasonic RW2 files, or the
+	// section or box holding it in X3F and CR3 files.
+	Source string
+	// Offset is the offset of Data in the file, or -1 if unknown.
+	Offset int64
+	Data   []byte
+	// Width and Height are the dimensions given by the SOF segment of Data.
+	Width, Height int
+}
+
+// Previews returns the JPEG images stored in the tiff data of x: the images
+// given by the JPEGInterchangeFormat and JPEGInterchangeFormatLength tags or
+// by a single strip of any IFD or sub-IFD, and the JpgFromRaw tag of
+// Panasonic RW2 files. Lossless JPEG data, such as the raw image data of CR2
+// and DNG files, is not returned. Offsets are those of the images in x.Raw,
+// which is the entire file for TIFF based raw formats (e.g. CR2, NEF, ARW,
+// DNG, RW2 and PEF).
+func (x *Exif) Previews() []Preview {
+	var ps []Preview
+	for i, d := range x.Tiff.Dirs {
+		ps = x.dirPreviews(ps, fmt.Sprintf("IFD%d", i), d)
+	}
+	for i, d := range x.dirs[IfdSubIFD] {
+		ps = x.dirPreviews(ps, fmt.Sprintf("%v%d", IfdSubIFD, i), d)
+	}
+	if t, err := x.GetTagByID(Ifd0, 0x002E); err == nil && t.Type == tiff.DTUndefined {
+		ps = addPreview(ps, "JpgFromRaw", x.tiffFileOffset(int64(t.ValOffset)), t.Val)
+	}
+	return ps
+}
+
+// dirPreviews appends the JPEG images referenced by the tags of d to ps.
+func (x *Exif) dirPreviews(ps []Preview, source string, d *tiff.Dir) []Preview {
+	for _, p := range [][2]uint16{{0x0201, 0x0202}, {0x0111, 0x0117}} {
+		var off, n *tiff.Tag
+		for _, t := range d.Tags {
+			switch t.Id {
+			case p[0]:
+				off = t
+			case p[1]:
+				n = t
+			}
+		}
+		if off == nil || n == nil || off.Count != 1 || n.Count != 1 {
+			continue
+		}
+		start, err1 := off.Int64(0)
+		length, err2 := n.Int64(0)
+		if err1 != nil || err2 != nil || start < 0 || length <= 0 || start > int64(len(x.Raw)) || length > int64(len(x.Raw))-start {
+			continue
+		}
+		ps = addPreview(ps, source, x.tiffFileOffset(start), x.Raw[start:start+length])
+	}
+	return ps
+}
+
+// tiffFileOffset returns the offset in the file of the offset off in x.Raw,
+// or -1 if unknown.
+func (x *Exif) tiffFileOffset(off int64) int64 {
+	if x.tiffOffset < 0 {
+		return -1
+	}
+	return x.tiffOffset + off
+}
+
+// addPreview appends the JPEG image data to ps, unless the data is not a
+// JPEG image with a baseline, extended or progressive frame.
+func addPreview(ps []Preview, source string, offset int64, data []byte) []Preview {
+	w, h, ok := jpegFrame(data)
+	if !ok {
+		return ps
+	}
+	return append(ps, Preview{Source: source, Offset: offset, Data: data, Width: w, Height: h})
+}
+
+// jpegFrame returns the dimensions given by the SOF segment of the JPEG
+// image data, and whether it has one of a baseline, extended sequential or
+// progressive Huffman coded frame, which image decoders support.
+func jpegFrame(data []byte) (width, height int, ok bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0, 0, false
+	}
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return 0, 0, false
+		}
+		c := data[pos+1]
+		if c == 0xFF {
+			// fill byte
+			pos++
+			continue
+		}
+		if c == 0x01 || c >= 0xD0 && c <= 0xD7 {
+			pos += 2
+			continue
+		}
+		if c == jpeg_SOS || c == jpeg_EOI {
+			return 0, 0, false
+		}
+		n := int(binary.BigEndian.Uint16(data[pos+2:]))
+		switch c {
+		case 0xC0, 0xC1, 0xC2:
+			if n < 7 || pos+2+n > len(data) {
+				return 0, 0, false
+			}
+			height = int(binary.BigEndian.Uint16(data[pos+5:]))
+			width = int(binary.BigEndian.Uint16(data[pos+7:]))
+			return width, height, true
+		case 0xC3, 0xC5, 0xC6, 0xC7, 0xC9, 0xCA, 0xCB, 0xCD, 0xCE, 0xCF:
+			// lossless, hierarchical or arithmetic coded
+			return 0, 0, false
+		}
+		pos += 2 + n
+	}
+	return 0, 0, false
+}
+
+// ExtractPreview returns the largest JPEG image embedded in the raw file in
+// r, so that galleries can show raw files without a raw developer. The
+// images of TIFF based raw formats are those returned by Previews; for
+// Sigma X3F files, the JPEG image sections are used, and for Canon CR3
+// files the PRVW preview. For other files, such as JPEG files, the thumbnail
+// of IFD1 is returned. Images are compared by their number of pixels.
+// ErrNoPreview is returned if no image is found.
+func ExtractPreview(r io.Reader) (*Preview, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var ps []Preview
+	switch {
+	case bytes.HasPrefix(data, []byte(x3fSignature)):
+		ps, err = x3fPreviews(data)
+	case len(data) >= 8 && string(data[4:8]) == "ftyp":
+		ps, err = cr3Previews(data)
+	default:
+		var x *Exif
+		x, err = (&Decoder{Lenient: true}).Decode(bytes.NewReader(data))
+		if x != nil {
+			ps, err = x.Previews(), nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var best *Preview
+	for i := range ps {
+		p := &ps[i]
+		if best == nil || p.Width*p.Height > best.Width*best.Height {
+			best = p
+		}
+	}
+	if best == nil {
+		return nil, ErrNoPreview
+	}
+	return best, nil
+}
+
+// x3fPreviews returns the JPEG images of the image sections of the X3F file
+// data.
+func x3fPreviews(data []byte) ([]Preview, error) {
+	sections, err := x3fSections(data)
+	if err != nil {
+		return nil, err
+	}
+	var ps []Preview
+	for _, s := range sections {
+		// see x3fPreview for the layout of image sections
+		if s.typ != "IMAG" && s.typ != "IMA2" || len(s.data) < 28 || string(s.data[:4]) != "SECi" {
+			continue
+		}
+		const formatJPEG = 18
+		if binary.LittleEndian.Uint32(s.data[12:]) == formatJPEG {
+			ps = addPreview(ps, s.typ, s.offset+28, s.data[28:])
+		}
+	}
+	return ps, nil
+}
+
+// cr3PreviewUUID is the type of the top-level uuid box of CR3 files holding
+// the PRVW box.
+var cr3PreviewUUID = []byte{0xEA, 0xF4, 0x2B, 0x5E, 0x1C, 0x98, 0x4B, 0x88, 0xB9, 0xFB, 0xB7, 0xDC, 0x40, 0x6E, 0x4D, 0x16}
+
+// cr3Previews returns the JPEG image of the PRVW box of the CR3 file data.
+// The uuid box holding it starts with 8 bytes of unknown meaning, and the
+// JPEG image follows a 16 byte header in the PRVW box.
+func cr3Previews(data []byte) ([]Preview, error) {
+	var ps []Preview
+	err := eachBox(data, func(typ string, payload []byte) error {
+		if typ != "uuid" || len(payload) < 16+8 || !bytes.Equal(payload[:16], cr3PreviewUUID) {
+			return nil
+		}
+		return eachBox(payload[16+8:], func(typ string, payload []byte) error {
+			if typ == "PRVW" && len(payload) > 16 {
+				offset := int64(cap(data) - cap(payload[16:]))
+				ps = addPreview(ps, typ, offset, payload[16:])
+			}
+			return nil
+		})
+	})
+	return ps, err
+}
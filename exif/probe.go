@@ -0,0 +1,493 @@
+package exif
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// Format identifies the file format of an image.
+type Format int
+
+const (
+	FormatUnknown Format = iota
+	FormatJPEG
+	FormatTIFF
+	FormatPNG
+	FormatWebP
+	FormatHEIC
+)
+
+var formatNames = map[Format]string{
+	FormatUnknown: "unknown",
+	FormatJPEG:    "JPEG",
+	FormatTIFF:    "TIFF",
+	FormatPNG:     "PNG",
+	FormatWebP:    "WebP",
+	FormatHEIC:    "HEIC",
+}
+
+func (f Format) String() string {
+	if s, ok := formatNames[f]; ok {
+		return s
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// ErrUnknownFormat is returned by Probe for data in an unrecognized format.
+var ErrUnknownFormat = errors.New("exif: unknown image format")
+
+// maxProbeBox bounds the size of the metadata chunks and boxes Probe reads
+// into memory.
+const maxProbeBox = 16 << 20
+
+// Probe identifies the format of the image in r and returns its dimensions
+// and orientation without decoding the image or all of its metadata, reading
+// only as far into r as needed. It is meant as a fast pre-pass for
+// thumbnailers and the like.
+//
+// The dimensions are those of the stored image, before the orientation is
+// applied. They are read from the SOF segment of JPEG files, IFD0 of TIFF
+// files, the IHDR chunk of PNG files, the VP8X, VP8 or VP8L chunk of WebP
+// files and the ispe property of the primary image of HEIC files. The
+// orientation is an EXIF Orientation value from 1 to 8 read from the embedded
+// EXIF data (or for HEIC, derived from the irot and imir properties), and 1
+// if none is recorded.
+func Probe(r io.Reader) (f Format, width, height, orientation int, err error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(12)
+
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8}):
+		f = FormatJPEG
+		width, height, orientation, err = probeJPEG(br)
+	case bytes.HasPrefix(head, []byte("II*\x00")), bytes.HasPrefix(head, []byte("MM\x00*")):
+		f = FormatTIFF
+		width, height, orientation, err = probeTIFF(br)
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		f = FormatPNG
+		width, height, orientation, err = probePNG(br)
+	case len(head) == 12 && string(head[:4]) == "RIFF" && string(head[8:]) == "WEBP":
+		f = FormatWebP
+		width, height, orientation, err = probeWebP(br)
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		f = FormatHEIC
+		width, height, orientation, err = probeHEIC(br)
+	default:
+		return FormatUnknown, 0, 0, 0, ErrUnknownFormat
+	}
+	if err == ErrUnknownFormat {
+		return FormatUnknown, 0, 0, 0, err
+	} else if err != nil {
+		return f, 0, 0, 0, err
+	}
+	return f, width, height, orientation, nil
+}
+
+// tiffOrientation returns the orientation recorded in IFD0 of the tiff data,
+// or 1 if there is none.
+func tiffOrientation(data []byte) int {
+	t, err := (&tiff.Decoder{Lenient: true}).Decode(bytes.NewReader(data))
+	if err != nil || len(t.Dirs) == 0 {
+		return 1
+	}
+	return dirOrientation(t.Dirs[0])
+}
+
+func dirOrientation(d *tiff.Dir) int {
+	for _, tag := range d.Tags {
+		if tag.Id != 0x0112 || tag.Count == 0 {
+			continue
+		}
+		if o, err := tag.Int(0); err == nil && o >= 1 && o <= 8 {
+			return o
+		}
+	}
+	return 1
+}
+
+func probeJPEG(r *bufio.Reader) (width, height, orientation int, err error) {
+	orientation = 1
+	if _, err := r.Discard(2); err != nil {
+		return 0, 0, 0, err
+	}
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("exif: JPEG marker read failed: %v", err)
+		} else if c != 0xFF {
+			return 0, 0, 0, errors.New("exif: invalid JPEG marker")
+		}
+		for c == 0xFF {
+			// skip fill bytes
+			if c, err = r.ReadByte(); err != nil {
+				return 0, 0, 0, fmt.Errorf("exif: JPEG marker read failed: %v", err)
+			}
+		}
+
+		switch {
+		case c == 0x01, c >= 0xD0 && c <= 0xD8:
+			// markers without a payload
+			continue
+		case c == jpeg_SOS, c == jpeg_EOI:
+			return 0, 0, 0, errors.New("exif: no SOF segment in JPEG file")
+		}
+		var lenBytes [2]byte
+		if _, err := io.ReadFull(r, lenBytes[:]); err != nil {
+			return 0, 0, 0, fmt.Errorf("exif: JPEG segment read failed: %v", err)
+		}
+		n := int(binary.BigEndian.Uint16(lenBytes[:])) - 2
+		if n < 0 {
+			return 0, 0, 0, errors.New("exif: invalid JPEG segment length")
+		}
+
+		switch {
+		case c >= 0xC0 && c <= 0xCF && c != 0xC4 && c != 0xC8 && c != 0xCC:
+			// start of frame: precision, height, width
+			var sof [5]byte
+			if n < len(sof) {
+				return 0, 0, 0, errors.New("exif: JPEG SOF segment too short")
+			}
+			if _, err := io.ReadFull(r, sof[:]); err != nil {
+				return 0, 0, 0, fmt.Errorf("exif: JPEG SOF segment read failed: %v", err)
+			}
+			height = int(binary.BigEndian.Uint16(sof[1:]))
+			width = int(binary.BigEndian.Uint16(sof[3:]))
+			return width, height, orientation, nil
+		case c == jpeg_APP1:
+			data := make([]byte, n)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return 0, 0, 0, fmt.Errorf("exif: JPEG segment read failed: %v", err)
+			}
+			if bytes.HasPrefix(data, exifHeader) {
+				orientation = tiffOrientation(data[len(exifHeader):])
+			}
+		default:
+			if _, err := r.Discard(n); err != nil {
+				return 0, 0, 0, fmt.Errorf("exif: JPEG segment read failed: %v", err)
+			}
+		}
+	}
+}
+
+// probeTIFF reads the dimensions and orientation from the IFD0 entries,
+// which hold these values inline.
+func probeTIFF(r *bufio.Reader) (width, height, orientation int, err error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, 0, 0, fmt.Errorf("exif: TIFF header read failed: %v", err)
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if header[0] == 'I' {
+		order = binary.LittleEndian
+	}
+	off := order.Uint32(header[4:])
+	if off < 8 {
+		return 0, 0, 0, errors.New("exif: invalid TIFF IFD0 offset")
+	}
+	if _, err := r.Discard(int(off - 8)); err != nil {
+		return 0, 0, 0, fmt.Errorf("exif: TIFF IFD0 read failed: %v", err)
+	}
+	var count [2]byte
+	if _, err := io.ReadFull(r, count[:]); err != nil {
+		return 0, 0, 0, fmt.Errorf("exif: TIFF IFD0 read failed: %v", err)
+	}
+
+	orientation = 1
+	entry := make([]byte, 12)
+	for n := order.Uint16(count[:]); n > 0; n-- {
+		if _, err := io.ReadFull(r, entry); err != nil {
+			return 0, 0, 0, fmt.Errorf("exif: TIFF IFD0 read failed: %v", err)
+		}
+		var v int
+		switch tiff.DataType(order.Uint16(entry[2:])) {
+		case tiff.DTShort:
+			v = int(order.Uint16(entry[8:]))
+		case tiff.DTLong:
+			v = int(order.Uint32(entry[8:]))
+		default:
+			continue
+		}
+		switch order.Uint16(entry) {
+		case 0x0100:
+			width = v
+		case 0x0101:
+			height = v
+		case 0x0112:
+			if v >= 1 && v <= 8 {
+				orientation = v
+			}
+		}
+	}
+	if width == 0 || height == 0 {
+		return 0, 0, 0, errors.New("exif: no image dimensions in TIFF IFD0")
+	}
+	return width, height, orientation, nil
+}
+
+func probePNG(r *bufio.Reader) (width, height, orientation int, err error) {
+	orientation = 1
+	if _, err := r.Discard(8); err != nil {
+		return 0, 0, 0, err
+	}
+	var hdr [8]byte
+	for {
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return 0, 0, 0, fmt.Errorf("exif: PNG chunk read failed: %v", err)
+		}
+		n := binary.BigEndian.Uint32(hdr[:4])
+		typ := string(hdr[4:])
+		switch typ {
+		case "IHDR", "eXIf":
+			if n > maxProbeBox {
+				return 0, 0, 0, fmt.Errorf("exif: PNG %v chunk too large", typ)
+			}
+			data := make([]byte, n)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return 0, 0, 0, fmt.Errorf("exif: PNG chunk read failed: %v", err)
+			}
+			if typ == "eXIf" {
+				orientation = tiffOrientation(data)
+			} else if len(data) < 8 {
+				return 0, 0, 0, errors.New("exif: PNG IHDR chunk too short")
+			} else {
+				width = int(binary.BigEndian.Uint32(data))
+				height = int(binary.BigEndian.Uint32(data[4:]))
+			}
+			n = 0
+		case "IDAT", "IEND":
+			// the eXIf chunk must precede the image data
+			if width == 0 {
+				return 0, 0, 0, errors.New("exif: no IHDR chunk in PNG file")
+			}
+			return width, height, orientation, nil
+		}
+		// skip the rest of the chunk and its CRC
+		if _, err := r.Discard(int(n) + 4); err != nil {
+			return 0, 0, 0, fmt.Errorf("exif: PNG chunk read failed: %v", err)
+		}
+	}
+}
+
+func probeWebP(r *bufio.Reader) (width, height, orientation int, err error) {
+	orientation = 1
+	if _, err := r.Discard(12); err != nil {
+		return 0, 0, 0, err
+	}
+	var hdr [8]byte
+	var hasExif bool
+	for {
+		if _, err := io.ReadFull(r, hdr[:]); err == io.EOF && width > 0 {
+			return width, height, orientation, nil
+		} else if err != nil {
+			return 0, 0, 0, fmt.Errorf("exif: WebP chunk read failed: %v", err)
+		}
+		typ := string(hdr[:4])
+		n := binary.LittleEndian.Uint32(hdr[4:])
+		if n > maxProbeBox {
+			if _, err := r.Discard(int(n + n&1)); err != nil {
+				return 0, 0, 0, fmt.Errorf("exif: WebP chunk read failed: %v", err)
+			}
+			continue
+		}
+		data := make([]byte, n+n&1)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return 0, 0, 0, fmt.Errorf("exif: WebP chunk read failed: %v", err)
+		}
+		data = data[:n]
+
+		switch typ {
+		case "VP8X":
+			if len(data) < 10 {
+				return 0, 0, 0, errors.New("exif: WebP VP8X chunk too short")
+			}
+			hasExif = data[0]&0x08 != 0
+			width = 1 + int(uint32(data[4])|uint32(data[5])<<8|uint32(data[6])<<16)
+			height = 1 + int(uint32(data[7])|uint32(data[8])<<8|uint32(data[9])<<16)
+			if !hasExif {
+				return width, height, orientation, nil
+			}
+		case "VP8 ":
+			if width > 0 {
+				break
+			}
+			if len(data) < 10 || !bytes.Equal(data[3:6], []byte{0x9D, 0x01, 0x2A}) {
+				return 0, 0, 0, errors.New("exif: invalid WebP VP8 chunk")
+			}
+			width = int(binary.LittleEndian.Uint16(data[6:]) & 0x3FFF)
+			height = int(binary.LittleEndian.Uint16(data[8:]) & 0x3FFF)
+		case "VP8L":
+			if width > 0 {
+				break
+			}
+			if len(data) < 5 || data[0] != 0x2F {
+				return 0, 0, 0, errors.New("exif: invalid WebP VP8L chunk")
+			}
+			bits := binary.LittleEndian.Uint32(data[1:])
+			width = 1 + int(bits&0x3FFF)
+			height = 1 + int(bits>>14&0x3FFF)
+		case "EXIF":
+			orientation = tiffOrientation(bytes.TrimPrefix(data, exifHeader))
+			if width > 0 {
+				return width, height, orientation, nil
+			}
+		}
+		if width > 0 && !hasExif {
+			// simple format files have no metadata chunks
+			return width, height, orientation, nil
+		}
+	}
+}
+
+// heifBrands holds the ftyp brands of HEIF still images.
+var heifBrands = map[string]bool{
+	"heic": true, "heix": true, "heim": true, "heis": true,
+	"hevc": true, "hevx": true, "mif1": true, "msf1": true,
+}
+
+func probeHEIC(r *bufio.Reader) (width, height, orientation int, err error) {
+	h, err := readBoxHeader(r)
+	if err != nil || h.typ != "ftyp" || h.size < 8 || h.size > maxProbeBox {
+		return 0, 0, 0, errors.New("exif: invalid ISO BMFF ftyp box")
+	}
+	ftyp := make([]byte, h.size)
+	if _, err := io.ReadFull(r, ftyp); err != nil {
+		return 0, 0, 0, fmt.Errorf("exif: ISO BMFF box read failed: %v", err)
+	}
+	// major brand, minor version and compatible brands
+	isHEIF := heifBrands[string(ftyp[:4])]
+	for i := 8; i+4 <= len(ftyp); i += 4 {
+		isHEIF = isHEIF || heifBrands[string(ftyp[i:i+4])]
+	}
+	if !isHEIF {
+		// e.g. a video or a Canon CR3 file
+		return 0, 0, 0, ErrUnknownFormat
+	}
+
+	for {
+		h, err := readBoxHeader(r)
+		if err != nil {
+			return 0, 0, 0, errors.New("exif: no meta box in HEIC file")
+		}
+		if h.typ == "meta" {
+			if h.size < 4 || h.size > maxProbeBox {
+				return 0, 0, 0, fmt.Errorf("exif: invalid HEIC meta box size %d", h.size)
+			}
+			meta := make([]byte, h.size)
+			if _, err := io.ReadFull(r, meta); err != nil {
+				return 0, 0, 0, fmt.Errorf("exif: HEIC meta box read failed: %v", err)
+			}
+			return heifPrimaryImage(meta[4:])
+		}
+		if h.size < 0 {
+			return 0, 0, 0, errors.New("exif: no meta box in HEIC file")
+		}
+		if _, err := io.CopyN(io.Discard, r, h.size); err != nil {
+			return 0, 0, 0, fmt.Errorf("exif: ISO BMFF box read failed: %v", err)
+		}
+	}
+}
+
+// heifPrimaryImage returns the dimensions and orientation of the primary
+// item of meta, the payload of a HEIF meta box after its version and flags.
+func heifPrimaryImage(meta []byte) (width, height, orientation int, err error) {
+	m, err := parseHeifMeta(meta)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var mirror bool
+	var rot int // clockwise quarter turns applied after mirroring horizontally
+	for _, p := range m.itemProps(m.primary) {
+		switch p.typ {
+		case "ispe":
+			if len(p.data) >= 12 {
+				width = int(binary.BigEndian.Uint32(p.data[4:]))
+				height = int(binary.BigEndian.Uint32(p.data[8:]))
+			}
+		case "irot":
+			if len(p.data) >= 1 {
+				// anti-clockwise quarter turns
+				rot = (rot + 4 - int(p.data[0]&3)) % 4
+			}
+		case "imir":
+			if len(p.data) >= 1 {
+				// mirroring about the vertical axis (0) is a horizontal
+				// mirror, about the horizontal axis (1) a vertical one
+				mirror, rot = !mirror, (4-rot)%4
+				if p.data[0]&1 == 1 {
+					rot = (rot + 2) % 4
+				}
+			}
+		}
+	}
+	if width == 0 || height == 0 {
+		return 0, 0, 0, errors.New("exif: no ispe property for the HEIC primary image")
+	}
+	orientation = [2][4]int{{1, 6, 3, 8}, {2, 7, 4, 5}}[btoi(mirror)][rot]
+	return width, height, orientation, nil
+}
+
+type heifProp struct {
+	typ  string
+	data []byte
+}
+
+// parseIpma adds the 1-based property indices associated with each item in
+// the payload of an ipma box to assoc.
+func parseIpma(b []byte, assoc map[uint32][]int) error {
+	errShort := errors.New("exif: HEIC ipma box too short")
+	if len(b) < 8 {
+		return errShort
+	}
+	version, flags := b[0], b[3]
+	n := binary.BigEndian.Uint32(b[4:])
+	b = b[8:]
+	for ; n > 0; n-- {
+		var item uint32
+		if version < 1 {
+			if len(b) < 2 {
+				return errShort
+			}
+			item, b = uint32(binary.BigEndian.Uint16(b)), b[2:]
+		} else {
+			if len(b) < 4 {
+				return errShort
+			}
+			item, b = binary.BigEndian.Uint32(b), b[4:]
+		}
+		if len(b) < 1 {
+			return errShort
+		}
+		count := int(b[0])
+		b = b[1:]
+		for ; count > 0; count-- {
+			var idx int
+			if flags&1 == 1 {
+				if len(b) < 2 {
+					return errShort
+				}
+				idx, b = int(binary.BigEndian.Uint16(b)&0x7FFF), b[2:]
+			} else {
+				if len(b) < 1 {
+					return errShort
+				}
+				idx, b = int(b[0]&0x7F), b[1:]
+			}
+			assoc[item] = append(assoc[item], idx)
+		}
+	}
+	return nil
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+package exif
+
+import "github.com/rwcarlsen/goexif/tiff"
+
+// A Provenance locates a decoded tag in the stream its Exif was decoded
+// from, e.g. for hex viewers to highlight it or for tools patching it in
+// place.
+type Provenance struct {
+	// EntryOffset is the offset of the tag's 12-byte IFD entry, or -1 if
+	// it is not known.
+	EntryOffset int64
+	// ValueOffset and ValueLength give the byte range of the tag's value.
+	// For inlined values (see tiff.Tag.Inlined) it lies inside the entry.
+	ValueOffset int64
+	ValueLength int64
+}
+
+// Provenance returns the location of tag, one of the tags of x, in the
+// stream x was decoded from. ok is false if it is not known: if tag is not
+// one of the tags of x, if the offset of the EXIF data in the stream is not
+// known (see TiffOffset), or if tag belongs to a maker note, whose offsets
+// may be relative to the maker note rather than to the tiff header.
+func (x *Exif) Provenance(tag *tiff.Tag) (p Provenance, ok bool) {
+	base, ok := x.TiffOffset()
+	if !ok {
+		return p, false
+	}
+	ifd, found := x.ifdOf(tag)
+	if !found || ifd == IfdMakerNote {
+		return p, false
+	}
+	if _, ok := x.extra[ifd]; ok {
+		// decoded from data stored outside of x.Raw
+		return p, false
+	}
+	off, ok := tag.ValueOffset()
+	if !ok || off+int64(len(tag.Val)) > int64(len(x.Raw)) {
+		return p, false
+	}
+	p.EntryOffset = -1
+	if e := tag.EntryOffset(); e > 0 {
+		p.EntryOffset = base + e
+	}
+	p.ValueOffset = base + off
+	p.ValueLength = int64(len(tag.Val))
+	return p, true
+}
+
+// ifdOf returns the IFD of x holding tag.
+func (x *Exif) ifdOf(tag *tiff.Tag) (IfdID, bool) {
+	for ifd, dirs := range x.dirs {
+		for _, d := range dirs {
+			for _, t := range d.Tags {
+				if t == tag {
+					return ifd, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
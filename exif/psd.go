@@ -0,0 +1,115 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// psdSignature starts every Photoshop PSD (and large document PSB) file.
+const psdSignature = "8BPS"
+
+// Signatures of the PDF and PostScript files Adobe Illustrator writes.
+const (
+	pdfSignature = "%PDF"
+	psSignature  = "%!PS"
+)
+
+// psdExifResource is the ID of the Photoshop image resource holding the
+// tiff-encoded EXIF data (EXIF data 1).
+const psdExifResource = 0x0422
+
+// psdResource is a Photoshop image resource.
+type psdResource struct {
+	id   uint16
+	data []byte
+	// offset of data in the file
+	offset int64
+}
+
+// psdResources returns the image resources of the PSD or PSB file data,
+// stored after the 26 byte file header and the color mode data.
+func psdResources(data []byte) ([]psdResource, error) {
+	if len(data) < 26+4 || string(data[:4]) != psdSignature {
+		return nil, errors.New("exif: not a PSD file")
+	}
+	pos := int64(26)
+	colorLen := int64(binary.BigEndian.Uint32(data[pos:]))
+	pos += 4 + colorLen
+	if pos+4 > int64(len(data)) {
+		return nil, errors.New("exif: PSD color mode data exceeds file size")
+	}
+	resLen := int64(binary.BigEndian.Uint32(data[pos:]))
+	pos += 4
+	if pos+resLen > int64(len(data)) {
+		return nil, errors.New("exif: PSD image resources exceed file size")
+	}
+	return parseResources(data[:pos+resLen], pos)
+}
+
+// parseResources parses the image resource blocks stored in data from pos
+// to its end: the "8BIM" signature, the resource ID, a Pascal string name
+// padded to an even length, the data length and the data, also padded to an
+// even length. On error, the resources parsed so far are returned too.
+func parseResources(data []byte, pos int64) ([]psdResource, error) {
+	var res []psdResource
+	for pos+8 <= int64(len(data)) {
+		if string(data[pos:pos+4]) != "8BIM" {
+			return res, fmt.Errorf("exif: invalid PSD image resource signature %q", data[pos:pos+4])
+		}
+		id := binary.BigEndian.Uint16(data[pos+4:])
+		nameLen := int64(data[pos+6])
+		pos += 6 + (nameLen+2)&^1
+		if pos+4 > int64(len(data)) {
+			return res, fmt.Errorf("exif: PSD image resource %#04x truncated", id)
+		}
+		size := int64(binary.BigEndian.Uint32(data[pos:]))
+		pos += 4
+		if pos+size > int64(len(data)) {
+			return res, fmt.Errorf("exif: PSD image resource %#04x exceeds file size", id)
+		}
+		res = append(res, psdResource{id: id, data: data[pos : pos+size], offset: pos})
+		pos += (size + 1) &^ 1
+	}
+	return res, nil
+}
+
+// psdExif returns the tiff-encoded EXIF data stored in the image resources
+// of the PSD file in r and its offset in the file. For Illustrator files,
+// which are PDF or PostScript documents, the first EXIF image resource
+// stored uncompressed (as in the private data Illustrator writes when saving
+// with Photoshop compatible metadata) is used. A file larger than maxBytes,
+// if positive, fails with an error wrapping ErrLimitExceeded.
+func psdExif(r io.Reader, maxBytes int64) ([]byte, int64, error) {
+	data, err := readLimited(r, maxBytes, "PSD file")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var res []psdResource
+	if bytes.HasPrefix(data, []byte(psdSignature)) {
+		if res, err = psdResources(data); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		sig := []byte{'8', 'B', 'I', 'M', psdExifResource >> 8, psdExifResource & 0xFF}
+		if i := bytes.Index(data, sig); i >= 0 {
+			// only the first resource is needed
+			res, _ = parseResources(data, int64(i))
+		}
+	}
+
+	for _, rs := range res {
+		if rs.id != psdExifResource {
+			continue
+		}
+		raw, off := rs.data, rs.offset
+		if bytes.HasPrefix(raw, exifHeader) {
+			raw, off = raw[len(exifHeader):], off+int64(len(exifHeader))
+		}
+		return raw, off, nil
+	}
+	return nil, 0, errors.New("exif: no EXIF image resource found")
+}
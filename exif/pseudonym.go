@@ -0,0 +1,296 @@
+package exif
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// PseudonymFields lists the identifying fields Pseudonymize replaces with
+// pseudonyms: the owner and serial number fields of the EXIF data, and those
+// of the maker notes, as named by package mknote. Maker note fields are only
+// found if their parsers are registered (see RegisterParsers).
+var PseudonymFields = []FieldName{
+	Artist,
+	Copyright,
+	CameraOwnerName,
+	BodySerialNumber,
+	LensSerialNumber,
+	"OwnerName",
+	"SerialNumber",
+	"InternalSerialNumber",
+	"Nikon.SerialNO",
+	"Panasonic.LensSerialNumber",
+	"Panasonic.AccessorySerialNumber",
+}
+
+// pseudoCell is the size in degrees of the cells of the grid GPS
+// coordinates are snapped to before being pseudonymized.
+const pseudoCell = 0.01
+
+// Pseudonymize copies the image in r to w with the fields in PseudonymFields
+// and the GPS IFD replaced with pseudonyms, so that a dataset stays linkable
+// per camera, lens or owner without identifying them.
+//
+// Pseudonyms are derived from the HMAC-SHA256 of the original values keyed
+// with salt: the same value always gets the same pseudonym for a given salt,
+// but cannot be recovered from it without the salt. Values are rewritten in
+// place like with PatchTag and nothing else moves, so the pseudonym of an
+// ASCII field is a hex string as long as the original value, and that of
+// another field as many bytes of the HMAC. GPSLatitude and GPSLongitude, and
+// GPSDestLatitude and GPSDestLongitude (with their Ref fields), are replaced
+// with a uniformly distributed pseudo-location derived from the coordinates
+// snapped to a grid of 0.01 degrees, about a kilometer: photos taken at the
+// same place keep matching coordinates. The values of all other fields of
+// the GPS IFD but GPSVersionID, such as GPSAltitude, GPSImgDirection and
+// GPSAreaInformation, are zeroed. An error is returned if coordinates are
+// present but cannot be parsed, or if a value cannot be rewritten; use
+// RemoveGPS to drop the GPS IFD of such files altogether.
+//
+// The image is decoded leniently, so that the fields of IFDs holding entries
+// that cannot be decoded are still replaced. An error is returned if the
+// Exif or GPS IFD, or a maker note whose parser is registered, cannot be
+// decoded at all, as its identifying fields would be left in place.
+func Pseudonymize(r io.Reader, w io.Writer, salt []byte) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	x, err := decodeIdentifying(data, IfdExif, IfdGPS)
+	if err != nil {
+		return err
+	}
+	for _, warn := range x.warnings {
+		var perr *parserError
+		if errors.As(warn, &perr) {
+			return fmt.Errorf("exif: cannot pseudonymize: %v", warn)
+		}
+	}
+	if err := x.pseudonymize(sliceWriter(data), salt); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// sliceWriter is an io.WriterAt writing into a byte slice.
+type sliceWriter []byte
+
+func (s sliceWriter) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(s)) {
+		return 0, errors.New("exif: write outside of the data")
+	}
+	return copy(s[off:], p), nil
+}
+
+// pseudonymize patches the values described by Pseudonymize in w, which
+// writes to the stream x was decoded from.
+func (x *Exif) pseudonymize(w io.WriterAt, salt []byte) error {
+	pseudonym := func(name FieldName, val string) []byte {
+		mac := hmac.New(sha256.New, salt)
+		io.WriteString(mac, string(name)+"\x00"+val)
+		return mac.Sum(nil)
+	}
+
+	for _, name := range PseudonymFields {
+		f, err := x.GetField(name)
+		if err != nil {
+			continue
+		}
+		tag := f.Tag
+		val := make([]byte, len(tag.Val))
+		if tag.Type == tiff.DTAscii {
+			s, err := tag.StringVal()
+			if err != nil {
+				return fmt.Errorf("exif: cannot pseudonymize %v: %v", name, err)
+			}
+			if s = strings.TrimSpace(s); s == "" || len(tag.Val) < 2 {
+				continue
+			}
+			s = hex.EncodeToString(pseudonym(name, s))
+			if n := len(tag.Val) - 1; len(s) > n {
+				s = s[:n]
+			}
+			copy(val, s)
+		} else {
+			if len(val) == 0 {
+				continue
+			}
+			if len(val) > sha256.Size {
+				return fmt.Errorf("exif: cannot pseudonymize %v: value longer than %d bytes", name, sha256.Size)
+			}
+			copy(val, pseudonym(name, hex.EncodeToString(tag.Val)))
+		}
+		if err := x.patchPseudonym(w, f, val); err != nil {
+			return err
+		}
+	}
+
+	gps := map[FieldName]bool{GPSVersionID: true}
+	for _, c := range [][4]FieldName{
+		{GPSLatitude, GPSLatitudeRef, GPSLongitude, GPSLongitudeRef},
+		{GPSDestLatitude, GPSDestLatitudeRef, GPSDestLongitude, GPSDestLongitudeRef},
+	} {
+		_, latErr := x.Get(c[0])
+		_, longErr := x.Get(c[2])
+		if latErr != nil && longErr != nil {
+			continue
+		}
+		for _, name := range c {
+			gps[name] = true
+		}
+		lat, err := x.degrees(c[0], c[1], "S")
+		if err != nil {
+			return fmt.Errorf("exif: cannot pseudonymize %v: %v", c[0], err)
+		}
+		long, err := x.degrees(c[2], c[3], "W")
+		if err != nil {
+			return fmt.Errorf("exif: cannot pseudonymize %v: %v", c[2], err)
+		}
+		cell := fmt.Sprintf("%.0f,%.0f", math.Floor(lat/pseudoCell), math.Floor(long/pseudoCell))
+		h := pseudonym(GPSInfoIFDPointer, cell)
+		lat = float64(binary.BigEndian.Uint32(h))/(1<<32)*180 - 90
+		long = float64(binary.BigEndian.Uint32(h[4:]))/(1<<32)*360 - 180
+		if err := x.patchDegrees(w, c[0], c[1], lat, "N", "S"); err != nil {
+			return err
+		}
+		if err := x.patchDegrees(w, c[2], c[3], long, "E", "W"); err != nil {
+			return err
+		}
+	}
+	for _, f := range x.IfdFields(IfdGPS) {
+		if gps[f.Name] {
+			continue
+		}
+		val := make([]byte, len(f.Tag.Val))
+		if f.Tag.Format() == tiff.RatVal {
+			// zero over one rather than an undefined zero over zero
+			for i := 4; i < len(val); i += 8 {
+				x.Tiff.Order.PutUint32(val[i:], 1)
+			}
+		}
+		if err := x.patchPseudonym(w, f, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// degrees returns the value in degrees of the GPS coordinate field name,
+// negated if the field ref is neg.
+func (x *Exif) degrees(name, ref FieldName, neg string) (float64, error) {
+	tag, err := x.Get(name)
+	if err != nil {
+		return 0, err
+	}
+	refTag, err := x.Get(ref)
+	if err != nil {
+		return 0, err
+	}
+	deg, err := tagDegrees(tag)
+	if err != nil {
+		return 0, err
+	}
+	s, err := refTag.StringVal()
+	if err != nil {
+		return 0, err
+	}
+	if s == neg {
+		deg = -deg
+	}
+	return deg, nil
+}
+
+// patchPseudonym writes val over the value of f in w, like PatchTag. The
+// values of maker note fields, whose offsets may not be relative to the tiff
+// header, are located through their IFD entry in the maker note data.
+func (x *Exif) patchPseudonym(w io.WriterAt, f *Field, val []byte) error {
+	if f.Ifd != IfdMakerNote {
+		off, err := x.patchOffset(f)
+		if err != nil {
+			return err
+		}
+		_, err = w.WriteAt(val, off)
+		return err
+	}
+	base, ok := x.TiffOffset()
+	if _, extra := x.extra[IfdExif]; !ok || extra {
+		return fmt.Errorf("exif: cannot pseudonymize %v: offset of the maker note in the file is unknown", f.Name)
+	}
+	off, ok := x.noteValueOffset(f.Tag)
+	if !ok {
+		return fmt.Errorf("exif: cannot pseudonymize %v: value not found in the maker note", f.Name)
+	}
+	_, err := w.WriteAt(val, base+off)
+	return err
+}
+
+// noteValueOffset returns the offset in x.Raw of the value of tag, a tag of
+// the maker note, found by searching the maker note data for the only IFD
+// entry holding the tag.
+func (x *Exif) noteValueOffset(tag *tiff.Tag) (int64, bool) {
+	note, noteOff, _, err := x.MakerNote()
+	if err != nil || tag.Order() == nil {
+		return 0, false
+	}
+	order := tag.Order()
+	entry := make([]byte, 12)
+	order.PutUint16(entry, tag.Id)
+	order.PutUint16(entry[2:], uint16(tag.Type))
+	order.PutUint32(entry[4:], tag.Count)
+	if tag.Inlined() {
+		entry = append(entry[:8], tag.Val...)
+	} else {
+		order.PutUint32(entry[8:], tag.ValOffset)
+	}
+	i := bytes.Index(note, entry)
+	if i < 0 || bytes.Contains(note[i+1:], entry) {
+		return 0, false
+	}
+	v := int64(i) + 8
+	if !tag.Inlined() {
+		// the offsets of the maker note are relative to an unknown base,
+		// found from that of the entry
+		v = int64(tag.ValOffset) - (tag.EntryOffset() - int64(i))
+	}
+	if v < 0 || v+int64(len(tag.Val)) > int64(len(note)) || !bytes.Equal(note[v:v+int64(len(tag.Val))], tag.Val) {
+		return 0, false
+	}
+	return noteOff + v, true
+}
+
+// patchDegrees writes deg as the degrees, minutes and seconds of the
+// rational field name, and its sign as pos or neg in the field ref.
+func (x *Exif) patchDegrees(w io.WriterAt, name, ref FieldName, deg float64, pos, neg string) error {
+	tag, err := x.Get(name)
+	if err != nil {
+		return err
+	}
+	if tag.Type != tiff.DTRational || tag.Count != 3 {
+		return fmt.Errorf("exif: cannot pseudonymize %v: want 3 rationals", name)
+	}
+	sign := pos
+	if deg < 0 {
+		sign, deg = neg, -deg
+	}
+	secs := uint32(math.Round(deg * 3600 * 100))
+	val := make([]byte, 24)
+	order := x.Tiff.Order
+	for i, v := range [][2]uint32{{secs / 360000, 1}, {secs / 6000 % 60, 1}, {secs % 6000, 100}} {
+		order.PutUint32(val[8*i:], v[0])
+		order.PutUint32(val[8*i+4:], v[1])
+	}
+	if err := x.PatchTag(w, name, val); err != nil {
+		return err
+	}
+	return x.PatchString(w, ref, sign)
+}
@@ -0,0 +1,179 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// DecodeFile is like Decode, but reads the named file with DecodeReaderAt.
+func DecodeFile(name string) (*Exif, error) {
+	return new(Decoder).DecodeFile(name)
+}
+
+// DecodeFile is like the package-level DecodeFile function, but honors the
+// options set in dec.
+func (dec *Decoder) DecodeFile(name string) (*Exif, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return dec.DecodeReaderAt(f, fi.Size())
+}
+
+// DecodeReaderAt is like Decode, but reads the file of the given size from r.
+// For TIFF files (and TIFF based raw formats), the IFD chain and sub-IFDs are
+// walked at their absolute offsets, wherever in the file they are stored,
+// and only the header, the IFDs, the tag values they reference and the JPEG
+// thumbnail are read from r. Image data is skipped even when it is stored
+// between IFDs, e.g. before an IFD0 stored at the end of the file. Raw then
+// holds the region of the file ending with the last of them, in which the
+// bytes that were not read are zero.
+func DecodeReaderAt(r io.ReaderAt, size int64) (*Exif, error) {
+	return new(Decoder).DecodeReaderAt(r, size)
+}
+
+// DecodeReaderAt is like the package-level DecodeReaderAt function, but
+// honors the options set in dec.
+func (dec *Decoder) DecodeReaderAt(r io.ReaderAt, size int64) (*Exif, error) {
+	header := make([]byte, 4)
+	if _, err := r.ReadAt(header, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	order, _, ok := tiff.ParseHeader(header)
+	if !ok {
+		return dec.Decode(io.NewSectionReader(r, 0, size))
+	}
+
+	rr := &recordingReaderAt{r: r}
+	extent, thumbs, err := walkTiff(rr, size, order)
+	if err != nil {
+		return nil, decodeError{cause: err}
+	}
+	if max := dec.Limits.MaxBytes; max > 0 && extent > max {
+		return nil, decodeError{cause: fmt.Errorf("%w: tiff data of %d bytes exceeds %d bytes", ErrLimitExceeded, extent, max)}
+	}
+	raw := make([]byte, extent)
+	copy(raw, header)
+	for _, s := range rr.spans {
+		if s.off < extent {
+			copy(raw[s.off:], s.data)
+		}
+	}
+	for _, t := range thumbs {
+		if t[1] > extent {
+			t[1] = extent
+		}
+		if _, err := r.ReadAt(raw[t[0]:t[1]], t[0]); err != nil && err != io.EOF {
+			return nil, err
+		}
+	}
+	return dec.Decode(bytes.NewReader(raw))
+}
+
+// A recordingReaderAt keeps a copy of the data read from r, so that the
+// metadata read while walking a tiff file is not read again to decode it.
+type recordingReaderAt struct {
+	r     io.ReaderAt
+	spans []span
+}
+
+// A span holds the data read at offset off.
+type span struct {
+	off  int64
+	data []byte
+}
+
+func (rr *recordingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := rr.r.ReadAt(p, off)
+	if n > 0 {
+		rr.spans = append(rr.spans, span{off, append([]byte(nil), p[:n]...)})
+	}
+	return n, err
+}
+
+// dirPointers holds the ids of the tags pointing to IFDs holding metadata:
+// the Exif, GPS and Interoperability IFDs and the SubIFDs of IFD0.
+var dirPointers = map[uint16]bool{exifPointer: true, gpsPointer: true, interopPointer: true, 0x014A: true}
+
+// maxExtentIfds bounds the number of IFDs tiffExtent visits.
+const maxExtentIfds = 1024
+
+// tiffExtent returns the end of the region of the tiff file of the given
+// size in r holding the IFDs and sub-IFDs reached from the IFD chain, the tag
+// values they reference and the JPEG thumbnail.
+func tiffExtent(r io.ReaderAt, size int64, order binary.ByteOrder) (int64, error) {
+	extent, _, err := walkTiff(r, size, order)
+	return extent, err
+}
+
+// walkTiff reads the IFDs and sub-IFDs reached from the IFD chain of the
+// tiff file of the given size in r, and the tag values they reference. It
+// returns the end of the region holding them and the JPEG thumbnails, and
+// the start and end offsets of the thumbnails, which are not read.
+func walkTiff(r io.ReaderAt, size int64, order binary.ByteOrder) (extent int64, thumbs [][2]int64, err error) {
+	var buf [4]byte
+	if _, err := r.ReadAt(buf[:], 4); err != nil {
+		return 0, nil, fmt.Errorf("tiff: could not read offset to first IFD: %v", err)
+	}
+	extent = 8
+	grow := func(end int64) {
+		if end > extent {
+			extent = end
+		}
+	}
+	todo := []int64{int64(order.Uint32(buf[:]))}
+	seen := map[int64]bool{}
+	for len(todo) > 0 && len(seen) < maxExtentIfds {
+		off := todo[0]
+		todo = todo[1:]
+		if off == 0 || seen[off] || off >= size {
+			continue
+		}
+		seen[off] = true
+
+		sr := io.NewSectionReader(r, 0, size)
+		sr.Seek(off, io.SeekStart)
+		d, next, err := (&tiff.Decoder{Lenient: true}).DecodeDir(sr, order)
+		if err != nil {
+			return 0, nil, err
+		}
+		grow(d.Layout.Offset + d.Layout.Length)
+		var thumbOff, thumbLen int64
+		for _, t := range d.Tags {
+			if !t.Inlined() {
+				grow(int64(t.ValOffset) + int64(len(t.Val)))
+			}
+			switch {
+			case dirPointers[t.Id]:
+				for i := 0; i < int(t.Count); i++ {
+					if v, err := t.Int64(i); err == nil {
+						todo = append(todo, v)
+					}
+				}
+			case t.Id == 0x0201 && t.Count > 0:
+				thumbOff, _ = t.Int64(0)
+			case t.Id == 0x0202 && t.Count > 0:
+				thumbLen, _ = t.Int64(0)
+			}
+		}
+		if thumbOff > 0 && thumbLen > 0 && thumbOff < size {
+			grow(thumbOff + thumbLen)
+			thumbs = append(thumbs, [2]int64{thumbOff, thumbOff + thumbLen})
+		}
+		todo = append(todo, int64(next))
+	}
+	if extent > size {
+		extent = size
+	}
+	return extent, thumbs, nil
+}
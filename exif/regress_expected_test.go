@@ -22,6 +22,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"SAMSUNG DIGITAL CAMERA         "`,
 		InteroperabilityIFDPointer:       `1009`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"Samsung Techwin"`,
 		MaxApertureValue:                 `"32/10"`,
@@ -34,8 +35,14 @@ var regressExpected = map[string]map[FieldName]string{
 		ResolutionUnit:                   `2`,
 		SceneType:                        `""`,
 		Software:                         `"M5011S-1031"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `1039`,
 		ThumbJPEGInterchangeFormatLength: `3530`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYCbCrPositioning:            `2`,
+		ThumbYResolution:                 `"72/1"`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `2`,
 		YResolution:                      `"72/1"`,
@@ -65,6 +72,7 @@ var regressExpected = map[string]map[FieldName]string{
 		FocalPlaneYResolution:            `"2112000/169"`,
 		InteroperabilityIFDPointer:       `2824`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"Canon"`,
 		MakerNote:                        `""`,
 		MaxApertureValue:                 `"95/32"`,
@@ -77,8 +85,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneCaptureType:                 `0`,
 		SensingMethod:                    `2`,
 		ShutterSpeedValue:                `"338/32"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `5108`,
 		ThumbJPEGInterchangeFormatLength: `4323`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"180/1"`,
+		ThumbYResolution:                 `"180/1"`,
 		UserComment:                      `""`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"180/1"`,
@@ -112,6 +124,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"          "`,
 		InteroperabilityIFDPointer:       `1026`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"NIKON"`,
 		MakerNote:                        `""`,
@@ -128,8 +141,12 @@ var regressExpected = map[string]map[FieldName]string{
 		Sharpness:                        `0`,
 		Software:                         `"E3200v1.1"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `4596`,
 		ThumbJPEGInterchangeFormatLength: `4546`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"300/1"`,
+		ThumbYResolution:                 `"300/1"`,
 		UserComment:                      `"                                                                                                                     "`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"300/1"`,
@@ -161,6 +178,7 @@ var regressExpected = map[string]map[FieldName]string{
 		FocalPlaneYResolution:            `"1704000/210"`,
 		InteroperabilityIFDPointer:       `1844`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"Canon"`,
 		MakerNote:                        `""`,
 		MaxApertureValue:                 `"95/32"`,
@@ -173,8 +191,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneCaptureType:                 `0`,
 		SensingMethod:                    `2`,
 		ShutterSpeedValue:                `"202/32"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `2036`,
 		ThumbJPEGInterchangeFormatLength: `6465`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"180/1"`,
+		ThumbYResolution:                 `"180/1"`,
 		UserComment:                      `""`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"180/1"`,
@@ -205,6 +227,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `64`,
 		InteroperabilityIFDPointer:       `31048`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"PENTAX Corporation"`,
 		MakerNote:                        `""`,
 		MaxApertureValue:                 `"27/10"`,
@@ -219,8 +242,12 @@ var regressExpected = map[string]map[FieldName]string{
 		Sharpness:                        `0`,
 		Software:                         `"Optio S6 Ver 1.00"`,
 		SubjectDistanceRange:             `2`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `31172`,
 		ThumbJPEGInterchangeFormatLength: `7063`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `2`,
@@ -250,6 +277,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"                               "`,
 		InteroperabilityIFDPointer:       `2278`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"SONY"`,
 		MakerNote:                        `""`,
@@ -264,8 +292,13 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneCaptureType:                 `0`,
 		SceneType:                        `""`,
 		Sharpness:                        `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `2484`,
 		ThumbJPEGInterchangeFormatLength: `13571`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `2`,
@@ -297,6 +330,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `200`,
 		InteroperabilityIFDPointer:       `13816`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"EASTMAN KODAK COMPANY"`,
 		MakerNote:                        `""`,
@@ -315,8 +349,13 @@ var regressExpected = map[string]map[FieldName]string{
 		ShutterSpeedValue:                `"680/100"`,
 		Software:                         `"KODAK EASYSHARE C713 ZOOM DIGITAL CAMERA"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `13848`,
 		ThumbJPEGInterchangeFormatLength: `3436`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"480/1"`,
 		YCbCrPositioning:                 `2`,
@@ -345,6 +384,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"Digital image  "`,
 		InteroperabilityIFDPointer:       `832`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"Digital Camera                 "`,
 		MakerNote:                        `"6106789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567890123456"`,
@@ -359,8 +399,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SensingMethod:                    `2`,
 		ShutterSpeedValue:                `"491/100"`,
 		Software:                         `"1.00.018PR         "`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `956`,
 		ThumbJPEGInterchangeFormatLength: `7024`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"180/1"`,
+		ThumbYResolution:                 `"180/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"180/1"`,
 		YCbCrPositioning:                 `2`,
@@ -390,6 +434,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `200`,
 		InteroperabilityIFDPointer:       `30974`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"PENTAX Corporation "`,
 		MakerNote:                        `""`,
 		MaxApertureValue:                 `"28/10"`,
@@ -404,8 +449,12 @@ var regressExpected = map[string]map[FieldName]string{
 		Sharpness:                        `0`,
 		Software:                         `"Optio S5z Ver 1.00 "`,
 		SubjectDistanceRange:             `2`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `31098`,
 		ThumbJPEGInterchangeFormatLength: `8800`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `1`,
@@ -436,6 +485,7 @@ var regressExpected = map[string]map[FieldName]string{
 		FocalPlaneYResolution:            `"1200000/168"`,
 		InteroperabilityIFDPointer:       `2226`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"Canon"`,
 		MakerNote:                        `""`,
 		MaxApertureValue:                 `"107/32"`,
@@ -448,8 +498,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneCaptureType:                 `0`,
 		SensingMethod:                    `2`,
 		ShutterSpeedValue:                `"189/32"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `5108`,
 		ThumbJPEGInterchangeFormatLength: `6306`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"180/1"`,
+		ThumbYResolution:                 `"180/1"`,
 		UserComment:                      `""`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"180/1"`,
@@ -481,6 +535,7 @@ var regressExpected = map[string]map[FieldName]string{
 		GainControl:                      `2`,
 		InteroperabilityIFDPointer:       `27298`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"CASIO COMPUTER CO.,LTD."`,
 		MakerNote:                        `""`,
@@ -495,8 +550,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneCaptureType:                 `0`,
 		Sharpness:                        `0`,
 		Software:                         `"1.00             "`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `27422`,
 		ThumbJPEGInterchangeFormatLength: `8332`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `1`,
@@ -529,6 +588,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"          "`,
 		InteroperabilityIFDPointer:       `1026`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"NIKON"`,
 		MakerNote:                        `""`,
@@ -545,8 +605,12 @@ var regressExpected = map[string]map[FieldName]string{
 		Sharpness:                        `0`,
 		Software:                         `"COOLPIX L3v1.2"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `4596`,
 		ThumbJPEGInterchangeFormatLength: `10120`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"300/1"`,
+		ThumbYResolution:                 `"300/1"`,
 		UserComment:                      `"                                                                                                                     "`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"300/1"`,
@@ -580,6 +644,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"          "`,
 		InteroperabilityIFDPointer:       `1026`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"NIKON"`,
 		MakerNote:                        `""`,
@@ -596,8 +661,12 @@ var regressExpected = map[string]map[FieldName]string{
 		Sharpness:                        `0`,
 		Software:                         `"COOLPIX S6V1.0"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `4596`,
 		ThumbJPEGInterchangeFormatLength: `5274`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"300/1"`,
+		ThumbYResolution:                 `"300/1"`,
 		UserComment:                      `"                                                                                                                     "`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"300/1"`,
@@ -631,6 +700,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"          "`,
 		InteroperabilityIFDPointer:       `1026`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"NIKON"`,
 		MakerNote:                        `""`,
@@ -647,8 +717,12 @@ var regressExpected = map[string]map[FieldName]string{
 		Sharpness:                        `0`,
 		Software:                         `"E3700v1.2"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `4596`,
 		ThumbJPEGInterchangeFormatLength: `5967`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"300/1"`,
+		ThumbYResolution:                 `"300/1"`,
 		UserComment:                      `"                                                                                                                     "`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"300/1"`,
@@ -678,6 +752,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"My beautiful picture"`,
 		InteroperabilityIFDPointer:       `1170`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"CEC"`,
 		MakerNote:                        `""`,
@@ -693,8 +768,13 @@ var regressExpected = map[string]map[FieldName]string{
 		SensingMethod:                    `2`,
 		ShutterSpeedValue:                `"7/1"`,
 		Software:                         `"DVWare 1.0"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `1306`,
 		ThumbJPEGInterchangeFormatLength: `6292`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"1/1"`,
+		ThumbYResolution:                 `"1/1"`,
 		XResolution:                      `"320/1"`,
 		YCbCrPositioning:                 `2`,
 		YResolution:                      `"384/1"`,
@@ -725,6 +805,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"Digital StillCamera"`,
 		InteroperabilityIFDPointer:       `1010`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"Vivitar"`,
 		MakerNote:                        `""`,
@@ -742,8 +823,14 @@ var regressExpected = map[string]map[FieldName]string{
 		ShutterSpeedValue:                `"678/100"`,
 		Software:                         `"Ver 1.00    "`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `1156`,
 		ThumbJPEGInterchangeFormatLength: `20544`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYCbCrPositioning:            `2`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `2`,
@@ -790,8 +877,13 @@ var regressExpected = map[string]map[FieldName]string{
 		Sharpness:                        `0`,
 		ShutterSpeedValue:                `"73/10"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `8472`,
 		ThumbJPEGInterchangeFormatLength: `3060`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"230/1"`,
 		YCbCrPositioning:                 `1`,
@@ -822,6 +914,7 @@ var regressExpected = map[string]map[FieldName]string{
 		FocalPlaneYResolution:            `"1944000/168"`,
 		InteroperabilityIFDPointer:       `2206`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"Canon"`,
 		MakerNote:                        `""`,
 		MaxApertureValue:                 `"147/32"`,
@@ -834,8 +927,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneCaptureType:                 `0`,
 		SensingMethod:                    `2`,
 		ShutterSpeedValue:                `"277/32"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `5108`,
 		ThumbJPEGInterchangeFormatLength: `2084`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"180/1"`,
+		ThumbYResolution:                 `"180/1"`,
 		UserComment:                      `""`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"180/1"`,
@@ -870,6 +967,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `64`,
 		InteroperabilityIFDPointer:       `1158`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"FUJIFILM"`,
 		MakerNote:                        `""`,
@@ -887,8 +985,14 @@ var regressExpected = map[string]map[FieldName]string{
 		ShutterSpeedValue:                `"764/100"`,
 		Software:                         `"Digital Camera FinePix Z1      Ver1.00"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `1306`,
 		ThumbJPEGInterchangeFormatLength: `9900`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYCbCrPositioning:            `2`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `2`,
@@ -918,6 +1022,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `100`,
 		InteroperabilityIFDPointer:       `3620`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `4`,
 		Make:                             `"Polaroid"`,
 		MakerNote:                        `""`,
@@ -933,8 +1038,13 @@ var regressExpected = map[string]map[FieldName]string{
 		Sharpness:                        `0`,
 		ShutterSpeedValue:                `"5907/1000"`,
 		Software:                         `"00.00.1240a"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `3756`,
 		ThumbJPEGInterchangeFormatLength: `5972`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"1/1"`,
+		ThumbYResolution:                 `"1/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"288/3"`,
 		YCbCrPositioning:                 `2`,
@@ -966,6 +1076,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `80`,
 		InteroperabilityIFDPointer:       `3334`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"Canon"`,
 		MakerNote:                        `""`,
 		MaxApertureValue:                 `"116/32"`,
@@ -978,8 +1089,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneCaptureType:                 `0`,
 		SensingMethod:                    `2`,
 		ShutterSpeedValue:                `"266/32"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `5108`,
 		ThumbJPEGInterchangeFormatLength: `6594`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"180/1"`,
+		ThumbYResolution:                 `"180/1"`,
 		UserComment:                      `""`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"180/1"`,
@@ -1007,6 +1122,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"DCFC1247.JPG                   "`,
 		InteroperabilityIFDPointer:       `1011`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"Polaroid"`,
 		MaxApertureValue:                 `"30/10"`,
@@ -1018,8 +1134,14 @@ var regressExpected = map[string]map[FieldName]string{
 		ResolutionUnit:                   `2`,
 		SceneType:                        `""`,
 		Software:                         `"A520_CT019"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `1041`,
 		ThumbJPEGInterchangeFormatLength: `13506`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYCbCrPositioning:            `2`,
+		ThumbYResolution:                 `"72/1"`,
 		UserComment:                      `""`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `2`,
@@ -1037,6 +1159,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"                               "`,
 		InteroperabilityIFDPointer:       `612`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"Sony Ericsson"`,
 		Model:                            `"Z550a"`,
 		Orientation:                      `1`,
@@ -1044,8 +1167,13 @@ var regressExpected = map[string]map[FieldName]string{
 		PixelYDimension:                  `1024`,
 		ResolutionUnit:                   `2`,
 		Software:                         `"R6GA004     prgCXC1250583_GENERIC_M 2.0"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `748`,
 		ThumbJPEGInterchangeFormatLength: `4641`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `2`,
 		YResolution:                      `"72/1"`,
@@ -1076,6 +1204,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `160`,
 		InteroperabilityIFDPointer:       `3334`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"Canon"`,
 		MakerNote:                        `""`,
 		MaxApertureValue:                 `"95/32"`,
@@ -1088,8 +1217,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneCaptureType:                 `0`,
 		SensingMethod:                    `2`,
 		ShutterSpeedValue:                `"287/32"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `5108`,
 		ThumbJPEGInterchangeFormatLength: `5513`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"180/1"`,
+		ThumbYResolution:                 `"180/1"`,
 		UserComment:                      `""`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"180/1"`,
@@ -1123,6 +1256,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"          "`,
 		InteroperabilityIFDPointer:       `33536`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"NIKON"`,
 		MakerNote:                        `""`,
@@ -1139,8 +1273,12 @@ var regressExpected = map[string]map[FieldName]string{
 		Sharpness:                        `1`,
 		Software:                         `"COOLPIX L18 V1.1"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `33660`,
 		ThumbJPEGInterchangeFormatLength: `9697`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"300/1"`,
+		ThumbYResolution:                 `"300/1"`,
 		UserComment:                      `"       "`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"300/1"`,
@@ -1170,6 +1308,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `100`,
 		InteroperabilityIFDPointer:       `31040`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"PENTAX Corporation"`,
 		MakerNote:                        `""`,
 		MaxApertureValue:                 `"28/10"`,
@@ -1184,8 +1323,13 @@ var regressExpected = map[string]map[FieldName]string{
 		Sharpness:                        `0`,
 		Software:                         `"Optio S50 Ver 1.00"`,
 		SubjectDistanceRange:             `3`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `31176`,
 		ThumbJPEGInterchangeFormatLength: `6015`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `2`,
@@ -1204,6 +1348,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ExposureTime:                     `"1/4"`,
 		Flash:                            `16`,
 		FocalLength:                      `"47/1"`,
+		HostComputer:                     `"Mac OS X 10.4.9"`,
 		ISOSpeedRatings:                  `200`,
 		Make:                             `"Canon"`,
 		MeteringMode:                     `1`,
@@ -1213,8 +1358,12 @@ var regressExpected = map[string]map[FieldName]string{
 		PixelYDimension:                  `2100`,
 		ResolutionUnit:                   `2`,
 		Software:                         `"Adobe Photoshop CS3 Macintosh"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `606`,
 		ThumbJPEGInterchangeFormatLength: `7150`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		XResolution:                      `"3500000/10000"`,
 		YCbCrPositioning:                 `1`,
 		YResolution:                      `"3500000/10000"`,
@@ -1245,6 +1394,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `160`,
 		InteroperabilityIFDPointer:       `8728`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"EASTMAN KODAK COMPANY"`,
 		MakerNote:                        `""`,
@@ -1262,8 +1412,13 @@ var regressExpected = map[string]map[FieldName]string{
 		Sharpness:                        `0`,
 		ShutterSpeedValue:                `"9/1"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `9032`,
 		ThumbJPEGInterchangeFormatLength: `4569`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"480/1"`,
 		YCbCrPositioning:                 `1`,
@@ -1297,6 +1452,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `100`,
 		InteroperabilityIFDPointer:       `1158`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"FUJIFILM"`,
 		MakerNote:                        `"FUJIFILM0130" !"#,012NORMAL d"`,
@@ -1314,8 +1470,14 @@ var regressExpected = map[string]map[FieldName]string{
 		ShutterSpeedValue:                `"820/100"`,
 		Software:                         `"Digital Camera FinePix E550    Ver1.00"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `1306`,
 		ThumbJPEGInterchangeFormatLength: `8596`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYCbCrPositioning:            `2`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `1`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `2`,
@@ -1344,6 +1506,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `80`,
 		ImageDescription:                 `"                               "`,
 		InteroperabilityIFDPointer:       `6640`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"SONY"`,
 		MakerNote:                        `""`,
@@ -1358,8 +1521,13 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneCaptureType:                 `0`,
 		SceneType:                        `""`,
 		Sharpness:                        `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `6892`,
 		ThumbJPEGInterchangeFormatLength: `4029`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XPKeywords:                       `[106,0,117,0,110,0,101,0,32,0,57,0,32,0,50,0,48,0,49,0,48,0,0,0]`,
 		XResolution:                      `"72/1"`,
@@ -1389,6 +1557,7 @@ var regressExpected = map[string]map[FieldName]string{
 		FocalPlaneResolutionUnit:         `2`,
 		FocalPlaneXResolution:            `"3648000/241"`,
 		FocalPlaneYResolution:            `"2736000/181"`,
+		HostComputer:                     `"Mac OS X 10.6.4"`,
 		ISOSpeedRatings:                  `800`,
 		ImageDescription:                 `"                               "`,
 		Make:                             `"Canon"`,
@@ -1404,8 +1573,13 @@ var regressExpected = map[string]map[FieldName]string{
 		SensingMethod:                    `2`,
 		ShutterSpeedValue:                `"106/32"`,
 		Software:                         `"QuickTime 7.6.6"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `3408`,
 		ThumbJPEGInterchangeFormatLength: `5126`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"4718592/65536"`,
+		ThumbYCbCrPositioning:            `1`,
+		ThumbYResolution:                 `"4718592/65536"`,
 		UserComment:                      `""`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"4718592/65536"`,
@@ -1438,6 +1612,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"OLYMPUS DIGITAL CAMERA         "`,
 		InteroperabilityIFDPointer:       `1714`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"OLYMPUS IMAGING CORP.  "`,
 		MakerNote:                        `""`,
@@ -1453,8 +1628,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneType:                        `""`,
 		Sharpness:                        `0`,
 		Software:                         `"Version 1.0                    "`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `9204`,
 		ThumbJPEGInterchangeFormatLength: `3562`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		UserComment:                      `"                                                                                                                             "`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
@@ -1481,8 +1660,12 @@ var regressExpected = map[string]map[FieldName]string{
 		ResolutionUnit:                   `2`,
 		SceneCaptureType:                 `0`,
 		Software:                         `"V 12.40"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `25601`,
 		ThumbJPEGInterchangeFormatLength: `3385`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"300/1"`,
+		ThumbYResolution:                 `"300/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"300/1"`,
 		YCbCrPositioning:                 `1`,
@@ -1505,6 +1688,7 @@ var regressExpected = map[string]map[FieldName]string{
 		FlashpixVersion:                  `"0100"`,
 		InteroperabilityIFDPointer:       `538`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"LG Elec."`,
 		MeteringMode:                     `2`,
 		Model:                            `"GU295"`,
@@ -1516,8 +1700,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneType:                        `""`,
 		Sharpness:                        `0`,
 		Software:                         `"GU295-MSM1530032L-V10i-APR-22-2010-ATT-US"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `662`,
 		ThumbJPEGInterchangeFormatLength: `9850`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `1`,
@@ -1547,6 +1735,7 @@ var regressExpected = map[string]map[FieldName]string{
 		GPSVersionID:                     `[2,2,0,0]`,
 		InteroperabilityIFDPointer:       `472`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"HTC"`,
 		Model:                            `"RAPH800"`,
 		Orientation:                      `1`,
@@ -1555,8 +1744,12 @@ var regressExpected = map[string]map[FieldName]string{
 		ResolutionUnit:                   `2`,
 		SceneType:                        `""`,
 		Software:                         `"M7500BSAAAAAAD3050"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `920`,
 		ThumbJPEGInterchangeFormatLength: `22806`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `1`,
@@ -1601,8 +1794,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SubSecTimeOriginal:               `"65"`,
 		SubjectDistance:                  `"63/100"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `802`,
 		ThumbJPEGInterchangeFormatLength: `9117`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"300/1"`,
 		YResolution:                      `"300/1"`,
@@ -1633,12 +1830,14 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `800`,
 		InteroperabilityIFDPointer:       `1120`,
 		InteroperabilityIndex:            `"R03"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"Canon"`,
 		MeteringMode:                     `5`,
 		Model:                            `"Canon EOS 5D Mark II"`,
 		Orientation:                      `1`,
 		PixelXDimension:                  `576`,
 		PixelYDimension:                  `864`,
+		PrimaryChromaticities:            `["64/100","33/100","21/100","71/100","15/100","6/100"]`,
 		ResolutionUnit:                   `2`,
 		SceneCaptureType:                 `0`,
 		ShutterSpeedValue:                `"393216/65536"`,
@@ -1646,11 +1845,17 @@ var regressExpected = map[string]map[FieldName]string{
 		SubSecTime:                       `"92"`,
 		SubSecTimeDigitized:              `"92"`,
 		SubSecTimeOriginal:               `"92"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `1266`,
 		ThumbJPEGInterchangeFormatLength: `6186`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		UserComment:                      `""`,
 		WhiteBalance:                     `1`,
+		WhitePoint:                       `["313/1000","329/1000"]`,
 		XResolution:                      `"720000/10000"`,
+		YCbCrCoefficients:                `["299/1000","587/1000","114/1000"]`,
 		YCbCrPositioning:                 `2`,
 		YResolution:                      `"720000/10000"`,
 	},
@@ -1682,6 +1887,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageUniqueID:                    `"7fa4f6d028df5f2fc1bad8102be81064"`,
 		InteroperabilityIFDPointer:       `3604`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"NIKON CORPORATION"`,
 		MakerNote:                        `""`,
@@ -1702,8 +1908,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SubSecTimeDigitized:              `"50"`,
 		SubSecTimeOriginal:               `"50"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `3728`,
 		ThumbJPEGInterchangeFormatLength: `3670`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		UserComment:                      `"ASCII                                    "`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"300/1"`,
@@ -1727,6 +1937,7 @@ var regressExpected = map[string]map[FieldName]string{
 		FlashpixVersion:                  `"0100"`,
 		InteroperabilityIFDPointer:       `518`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"PANTECH"`,
 		MeteringMode:                     `2`,
 		Model:                            `"P2020"`,
@@ -1738,8 +1949,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneType:                        `""`,
 		Sharpness:                        `0`,
 		Software:                         `"M6290A-KPVMZL-2.6.0140T"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `642`,
 		ThumbJPEGInterchangeFormatLength: `12226`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `1`,
@@ -1771,6 +1986,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `100`,
 		InteroperabilityIFDPointer:       `10506`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"Panasonic"`,
 		MakerNote:                        `""`,
@@ -1787,8 +2003,14 @@ var regressExpected = map[string]map[FieldName]string{
 		SensingMethod:                    `2`,
 		Sharpness:                        `0`,
 		Software:                         `"Ver.1.0  "`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `11764`,
 		ThumbJPEGInterchangeFormatLength: `7486`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"180/1"`,
+		ThumbYCbCrPositioning:            `2`,
+		ThumbYResolution:                 `"180/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"180/1"`,
 		YCbCrPositioning:                 `2`,
@@ -1821,6 +2043,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `"                               "`,
 		InteroperabilityIFDPointer:       `3288`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"Canon"`,
 		MakerNote:                        `""`,
 		MaxApertureValue:                 `"95/32"`,
@@ -1833,8 +2056,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SceneCaptureType:                 `2`,
 		SensingMethod:                    `2`,
 		ShutterSpeedValue:                `"189/32"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `5108`,
 		ThumbJPEGInterchangeFormatLength: `4855`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"180/1"`,
+		ThumbYResolution:                 `"180/1"`,
 		UserComment:                      `""`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"180/1"`,
@@ -1865,13 +2092,18 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `801`,
 		InteroperabilityIFDPointer:       `322`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		Make:                             `"HTC"`,
 		Model:                            `"ADR6400L"`,
 		PixelXDimension:                  `3264`,
 		PixelYDimension:                  `1952`,
 		ResolutionUnit:                   `2`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `696`,
 		ThumbJPEGInterchangeFormatLength: `38469`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `1`,
 		YResolution:                      `"72/1"`,
@@ -1879,6 +2111,8 @@ var regressExpected = map[string]map[FieldName]string{
 	"2012-12-21-11-15-19-sep-IMG_0001.jpg": map[FieldName]string{
 		ApertureValue:                    `"286720/65536"`,
 		Artist:                           `""`,
+		BodySerialNumber:                 `"082033000088"`,
+		CameraOwnerName:                  `""`,
 		ColorSpace:                       `1`,
 		ComponentsConfiguration:          `""`,
 		Copyright:                        `""`,
@@ -1904,7 +2138,9 @@ var regressExpected = map[string]map[FieldName]string{
 		ISOSpeedRatings:                  `1600`,
 		InteroperabilityIFDPointer:       `8806`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LensModel:                        `"EF-S18-55mm f/3.5-5.6 IS II"`,
+		LensSerialNumber:                 `"00002e61db"`,
 		Make:                             `"Canon"`,
 		MakerNote:                        `""`,
 		MeteringMode:                     `5`,
@@ -1918,8 +2154,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SubSecTime:                       `"00"`,
 		SubSecTimeDigitized:              `"00"`,
 		SubSecTimeOriginal:               `"00"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `10924`,
 		ThumbJPEGInterchangeFormatLength: `14327`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		UserComment:                      `""`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
@@ -1952,6 +2192,7 @@ var regressExpected = map[string]map[FieldName]string{
 		ImageDescription:                 `""`,
 		InteroperabilityIFDPointer:       `4838`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"Polaroid"`,
 		MakerNote:                        `" BARCODE:A265KS008000; ZP:812; FP:124; AWB:235,679; PWB:476,304; PMF:12,11610; LV:493; LUM:3-8-9-8-1-11;20;26;19;10;A:1,F1:6,F2:18;ET:145, W:2, F:3 ;FV:        41FV:        36FV:        43FV:       223FV:       258FV:         9FV:       466FV:       216FP: 10FP:  8FP:  6FP:  6FP:  6FP:  0FP:  8FP:  8AFS: 110"`,
@@ -1968,8 +2209,13 @@ var regressExpected = map[string]map[FieldName]string{
 		Sharpness:                        `0`,
 		ShutterSpeedValue:                `"5907/1000"`,
 		Software:                         `"  1.0"`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `4974`,
 		ThumbJPEGInterchangeFormatLength: `5863`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"1/1"`,
+		ThumbYResolution:                 `"1/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"288/3"`,
 		YCbCrPositioning:                 `2`,
@@ -2001,6 +2247,7 @@ var regressExpected = map[string]map[FieldName]string{
 		GainControl:                      `0`,
 		InteroperabilityIFDPointer:       `28448`,
 		InteroperabilityIndex:            `"R98"`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"NIKON CORPORATION"`,
 		MakerNote:                        `""`,
@@ -2021,8 +2268,13 @@ var regressExpected = map[string]map[FieldName]string{
 		SubSecTimeDigitized:              `"00"`,
 		SubSecTimeOriginal:               `"00"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `28588`,
 		ThumbJPEGInterchangeFormatLength: `8886`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"300/1"`,
+		ThumbYCbCrPositioning:            `2`,
+		ThumbYResolution:                 `"300/1"`,
 		UserComment:                      `"ASCII                                    "`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"300/1"`,
@@ -2054,6 +2306,7 @@ var regressExpected = map[string]map[FieldName]string{
 		GainControl:                      `0`,
 		ISOSpeedRatings:                  `80`,
 		InteroperabilityIFDPointer:       `17674`,
+		InteroperabilityVersion:          `"0100"`,
 		LightSource:                      `0`,
 		Make:                             `"EASTMAN KODAK COMPANY"`,
 		MakerNote:                        `""`,
@@ -2072,8 +2325,13 @@ var regressExpected = map[string]map[FieldName]string{
 		ShutterSpeedValue:                `"994/100"`,
 		Software:                         `"KODAK EASYSHARE C813 ZOOM DIGITAL CAMERA"`,
 		SubjectDistanceRange:             `0`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `17818`,
 		ThumbJPEGInterchangeFormatLength: `5175`,
+		ThumbOrientation:                 `1`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"480/1"`,
 		YCbCrPositioning:                 `2`,
@@ -2081,6 +2339,7 @@ var regressExpected = map[string]map[FieldName]string{
 	},
 	"FailedHash-NoDate-sep-remembory.jpg": map[FieldName]string{
 		ExifIFDPointer:  `192`,
+		HostComputer:    `"Apple Mac OS X"`,
 		Make:            `"Brother"`,
 		Model:           `"MFC-7840W"`,
 		Orientation:     `1`,
@@ -2284,8 +2543,12 @@ var regressExpected = map[string]map[FieldName]string{
 		SubSecTimeDigitized:              `"880"`,
 		SubSecTimeOriginal:               `"880"`,
 		SubjectArea:                      `[1631,1223,881,881]`,
+		ThumbCompression:                 `6`,
 		ThumbJPEGInterchangeFormat:       `1244`,
 		ThumbJPEGInterchangeFormatLength: `10875`,
+		ThumbResolutionUnit:              `2`,
+		ThumbXResolution:                 `"72/1"`,
+		ThumbYResolution:                 `"72/1"`,
 		WhiteBalance:                     `0`,
 		XResolution:                      `"72/1"`,
 		YCbCrPositioning:                 `1`,
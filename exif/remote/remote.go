@@ -0,0 +1,151 @@
+// Package remote decodes EXIF data from files in object storage (e.g. S3,
+// GCS or plain HTTP servers supporting Range requests) without downloading
+// whole images.
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// A RangeFunc fetches the length bytes of an object starting at offset, e.g.
+// with a GetObject request carrying a "Range: bytes=offset-(offset+length-1)"
+// header. It may return fewer bytes than requested at the end of the object.
+type RangeFunc func(offset, length int64) ([]byte, error)
+
+// DefaultBlockSize is the block size used by NewReaderAt. It is large enough
+// for the EXIF segment of a JPEG file (at most 64 KiB) to be fetched together
+// with the start of the file in a single request.
+const DefaultBlockSize = 128 << 10
+
+// A ReaderAt implements io.ReaderAt on top of a RangeFunc. Data is fetched in
+// blocks which are cached, so that repeated and neighbouring reads do not
+// cause further requests. Contiguous missing blocks are fetched with a single
+// request. A ReaderAt is safe for concurrent use.
+type ReaderAt struct {
+	fetch RangeFunc
+	size  int64
+	// BlockSize is the size of the blocks data is fetched and cached in, or
+	// DefaultBlockSize if not positive. It must not be changed once data
+	// has been read.
+	BlockSize int64
+
+	mu     sync.Mutex
+	blocks map[int64][]byte
+}
+
+// NewReaderAt returns a ReaderAt reading the object of the given size
+// through fetch.
+func NewReaderAt(fetch RangeFunc, size int64) *ReaderAt {
+	return &ReaderAt{
+		fetch:     fetch,
+		size:      size,
+		BlockSize: DefaultBlockSize,
+		blocks:    map[int64][]byte{},
+	}
+}
+
+// Size returns the size of the object.
+func (r *ReaderAt) Size() int64 { return r.size }
+
+// ReadAt implements io.ReaderAt.
+func (r *ReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("remote: negative offset")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	end := off + int64(len(p))
+	if end > r.size {
+		end = r.size
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	bs := r.blockSize()
+	if err := r.load(off, end, bs); err != nil {
+		return 0, err
+	}
+	n := 0
+	for n < int(end-off) {
+		pos := off + int64(n)
+		b := r.blocks[pos/bs]
+		i := pos % bs
+		if i >= int64(len(b)) {
+			// the object is shorter than r.size claimed
+			return n, io.ErrUnexpectedEOF
+		}
+		n += copy(p[n:end-off], b[i:])
+	}
+	if end < off+int64(len(p)) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// blockSize returns the block size in use.
+func (r *ReaderAt) blockSize() int64 {
+	if r.BlockSize <= 0 {
+		return DefaultBlockSize
+	}
+	return r.BlockSize
+}
+
+// load fetches the blocks of size bs covering [off, end) that are not cached
+// yet.
+func (r *ReaderAt) load(off, end, bs int64) error {
+	first, last := off/bs, (end-1)/bs
+	for i := first; i <= last; i++ {
+		if _, ok := r.blocks[i]; ok {
+			continue
+		}
+		// fetch the run of missing blocks starting at i in one request
+		j := i
+		for j+1 <= last {
+			if _, ok := r.blocks[j+1]; ok {
+				break
+			}
+			j++
+		}
+		start := i * bs
+		length := (j+1)*bs - start
+		if start+length > r.size {
+			length = r.size - start
+		}
+		data, err := r.fetch(start, length)
+		if err != nil {
+			return fmt.Errorf("remote: fetching bytes %d-%d: %v", start, start+length-1, err)
+		}
+		for k := i; k <= j; k++ {
+			lo := (k - i) * bs
+			if lo > int64(len(data)) {
+				lo = int64(len(data))
+			}
+			hi := lo + bs
+			if hi > int64(len(data)) {
+				hi = int64(len(data))
+			}
+			r.blocks[k] = data[lo:hi]
+		}
+		i = j
+	}
+	return nil
+}
+
+// DecodeRemote decodes the EXIF data of the object of the given size read
+// through fetch with dec (or exif.Decode if dec is nil). For JPEG files, the
+// EXIF segment normally arrives with the first request. For TIFF files (and
+// TIFF based raw formats), only the blocks holding the IFDs, the tag values
+// and the thumbnail are fetched, so that image data is not downloaded even
+// when IFDs are stored after it; see exif.Decoder.DecodeReaderAt.
+func DecodeRemote(fetch RangeFunc, size int64, dec *exif.Decoder) (*exif.Exif, error) {
+	if dec == nil {
+		dec = new(exif.Decoder)
+	}
+	return dec.DecodeReaderAt(NewReaderAt(fetch, size), size)
+}
@@ -0,0 +1,109 @@
+package remote
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// fetcher serves ranges of data and records the requests made.
+type fetcher struct {
+	data     []byte
+	requests int
+	fetched  int64
+}
+
+func (f *fetcher) fetch(offset, length int64) ([]byte, error) {
+	f.requests++
+	b := make([]byte, length)
+	n, err := bytes.NewReader(f.data).ReadAt(b, offset)
+	if err == io.EOF {
+		err = nil
+	}
+	f.fetched += int64(n)
+	return b[:n], err
+}
+
+func TestReaderAt(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	f := &fetcher{data: data}
+	r := NewReaderAt(f.fetch, int64(len(data)))
+	r.BlockSize = 100
+
+	p := make([]byte, 250)
+	if n, err := r.ReadAt(p, 50); err != nil || n != 250 || !bytes.Equal(p, data[50:300]) {
+		t.Fatalf("ReadAt(250 bytes at 50) = %d, %v", n, err)
+	}
+	if f.requests != 1 {
+		t.Errorf("got %d requests for contiguous blocks, want 1", f.requests)
+	}
+	// blocks 1 and 2 are cached, only block 3 is missing
+	if _, err := r.ReadAt(p[:200], 150); err != nil {
+		t.Fatal(err)
+	}
+	if f.requests != 2 || f.fetched != 400 {
+		t.Errorf("got %d requests fetching %d bytes, want 2 fetching 400", f.requests, f.fetched)
+	}
+	if n, err := r.ReadAt(p, 900); err != io.EOF || n != 100 || !bytes.Equal(p[:n], data[900:]) {
+		t.Errorf("ReadAt at end = %d, %v; want 100, EOF", n, err)
+	}
+
+	for _, bs := range []int64{0, -1} {
+		f := &fetcher{data: data}
+		r := NewReaderAt(f.fetch, int64(len(data)))
+		r.BlockSize = bs
+		if n, err := r.ReadAt(p, 50); err != nil || n != 250 || !bytes.Equal(p, data[50:300]) {
+			t.Errorf("BlockSize %d: ReadAt(250 bytes at 50) = %d, %v", bs, n, err)
+		}
+		if f.requests != 1 || f.fetched != int64(len(data)) {
+			t.Errorf("BlockSize %d: got %d requests fetching %d bytes, want 1 fetching %d", bs, f.requests, f.fetched, len(data))
+		}
+	}
+}
+
+func TestDecodeRemote(t *testing.T) {
+	jpeg, err := os.ReadFile(filepath.Join("..", "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(filepath.Join("..", "samples", "raw.exif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a TIFF file with 1 MiB of image data after the metadata
+	tif := append(append([]byte{}, raw[6:]...), make([]byte, 1<<20)...)
+
+	for _, tt := range []struct {
+		name string
+		data []byte
+	}{
+		{"jpeg", jpeg},
+		{"tiff", tif},
+	} {
+		want, err := exif.Decode(bytes.NewReader(tt.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := &fetcher{data: tt.data}
+		x, err := DecodeRemote(f.fetch, int64(len(tt.data)), nil)
+		if err != nil {
+			t.Errorf("%v: %v", tt.name, err)
+			continue
+		}
+		got, _ := x.MarshalJSON()
+		wantJSON, _ := want.MarshalJSON()
+		if !bytes.Equal(got, wantJSON) {
+			t.Errorf("%v: got %s, want %s", tt.name, got, wantJSON)
+		}
+		if f.requests > 2 || f.fetched > DefaultBlockSize {
+			t.Errorf("%v: %d requests fetched %d bytes", tt.name, f.requests, f.fetched)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package exif
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// maxExifFix bounds the size of the EXIF segment data fixExifLength reads
+// when its tiff data extends past the declared segment length.
+const maxExifFix = 1 << 20
+
+// fixExifLength checks the EXIF segment seg, whose declared data length is
+// declared and which has just been read from br, against its tiff data. Some
+// phones declare a segment length shorter or longer than the tiff data, so
+// that the data following the declared end does not start a segment, or the
+// segment exceeds the file. Then the extent of the tiff structure (see
+// tiffExtent) is trusted instead: the tiff data missing from seg is read from
+// br, up to maxExifFix bytes, and the data read past the tiff data is pushed
+// back to br from the first marker following it, so that the segments it
+// holds are still read. It returns the counting reader br reads from
+// afterwards, and a warning if seg was fixed.
+func fixExifLength(seg *Segment, declared int, br *bufio.Reader, cr *countingReader) (*countingReader, error) {
+	order, _, ok := tiff.ParseHeader(seg.Data[len(exifHeader):])
+	if !ok {
+		return cr, nil
+	}
+	if next, err := br.Peek(1); len(seg.Data) == declared && (err != nil || next[0] == 0xFF) {
+		// the next segment or the end of the file follows
+		return cr, nil
+	}
+
+	gr := &growReader{br: br, data: seg.Data}
+	ext, err := tiffExtent(gr, maxExifFix-int64(len(exifHeader)), order)
+	if err != nil {
+		return cr, nil
+	}
+	end := len(exifHeader) + int(ext)
+	if end > len(gr.data) {
+		// the tiff data is truncated by the end of the file
+		end = len(gr.data)
+	}
+	data, tail := gr.data[:end], gr.data[end:]
+	if i := nextMarker(tail); i >= 0 {
+		tail = tail[i:]
+	} else {
+		tail = nil
+	}
+	if len(tail) > 0 {
+		buffered, _ := br.Peek(br.Buffered())
+		rest := append(append([]byte(nil), tail...), buffered...)
+		start := cr.n - int64(br.Buffered()) - int64(len(tail))
+		cr = &countingReader{r: io.MultiReader(bytes.NewReader(rest), cr), n: start}
+		br.Reset(cr)
+	}
+	seg.Data = data
+	if len(data) == declared {
+		return cr, nil
+	}
+	return cr, fmt.Errorf("exif: EXIF segment at offset %d declares %d bytes of data, but holds %d", seg.Offset, declared, len(data))
+}
+
+// nextMarker returns the index of the first marker in b starting a segment,
+// or -1.
+func nextMarker(b []byte) int {
+	for i := 0; i+1 < len(b); i++ {
+		if b[i] == 0xFF && b[i+1] >= 0xC0 && b[i+1] != 0xFF {
+			return i
+		}
+	}
+	return -1
+}
+
+// growReader reads the tiff data following the EXIF header of data, reading
+// more data from br as needed, up to maxExifFix bytes.
+type growReader struct {
+	br   *bufio.Reader
+	data []byte
+	err  error
+}
+
+func (g *growReader) ReadAt(p []byte, off int64) (int, error) {
+	off += int64(len(exifHeader))
+	if end := off + int64(len(p)); end > int64(len(g.data)) && g.err == nil {
+		if end > maxExifFix {
+			end = maxExifFix
+		}
+		if n := end - int64(len(g.data)); n > 0 {
+			buf := make([]byte, n)
+			m, err := io.ReadFull(g.br, buf)
+			g.data, g.err = append(g.data, buf[:m]...), err
+		}
+	}
+	if off >= int64(len(g.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, g.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,238 @@
+package exif
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// A TimeShift is a transform of the timestamps of an image, applied by
+// ShiftTimes: shifting them by a duration, e.g. to correct a camera clock
+// set to the wrong time, and converting them to another time zone, e.g. to
+// normalize a collection to UTC.
+type TimeShift struct {
+	// By is added to the time of each timestamp, including the GPS time.
+	By time.Duration
+	// Zone, if non-nil, is the time zone the local timestamps are
+	// converted to. The moments they record are kept, so the GPS time is
+	// left unchanged.
+	Zone *time.Location
+	// From is the time zone of the local timestamps without an OffsetTime
+	// field. If nil, it is inferred by comparing the local time with the
+	// GPS time, as done by LocalDateTime.
+	From *time.Location
+}
+
+// The local timestamps of an image and the fields giving their fractional
+// seconds and UTC offsets.
+var timestamps = []struct{ date, subSec, offset FieldName }{
+	{DateTime, SubSecTime, OffsetTime},
+	{DateTimeOriginal, SubSecTimeOriginal, OffsetTimeOriginal},
+	{DateTimeDigitized, SubSecTimeDigitized, OffsetTimeDigitized},
+}
+
+// A fieldPatch is a new value for a field written by PatchTag.
+type fieldPatch struct {
+	name FieldName
+	val  []byte
+}
+
+// ShiftTimes rewrites the timestamps of the file x was decoded from in place
+// as described by s, using PatchTag: DateTime, DateTimeOriginal and
+// DateTimeDigitized together with their SubSecTime and OffsetTime fields, and
+// GPSDateStamp and GPSTimeStamp. Times are converted in the time zone of each
+// timestamp (or in s.Zone), so the UTC offsets written follow daylight
+// saving time changes of named zones such as time.LoadLocation returns.
+//
+// Fields are rewritten at their current length: fractional seconds keep
+// their number of digits and missing fields are not added. If a timestamp
+// cannot be rewritten, e.g. because its time zone is not known when
+// converting to s.Zone, an error is returned and nothing is written.
+func (x *Exif) ShiftTimes(w io.WriterAt, s TimeShift) error {
+	var patches []fieldPatch
+	var inferred *time.Location // time zone inferred from the GPS time
+	for _, ts := range timestamps {
+		tag, err := x.Get(ts.date)
+		if err != nil {
+			continue
+		}
+		const layout = "2006:01:02 15:04:05"
+		wall, err := time.Parse(layout, trimText(tag.Val))
+		if err != nil {
+			return fmt.Errorf("exif: cannot shift %v: %v", ts.date, err)
+		}
+		subSec, _ := x.Get(ts.subSec)
+		digits := 0
+		if subSec != nil {
+			d := trimText(subSec.Val)
+			n, err := strconv.Atoi(d)
+			if err != nil || n < 0 || len(d) > 9 {
+				return fmt.Errorf("exif: cannot shift %v: invalid %v %q", ts.date, ts.subSec, d)
+			}
+			digits = len(d)
+			wall = wall.Add(time.Duration(n) * time.Duration(math.Pow10(9-digits)))
+		}
+
+		zone := s.From
+		offset, _ := x.Get(ts.offset)
+		if offset != nil {
+			if zone, err = parseOffset(trimText(offset.Val)); err != nil {
+				return fmt.Errorf("exif: cannot shift %v: invalid %v: %v", ts.date, ts.offset, err)
+			}
+		}
+		if zone == nil {
+			if inferred == nil {
+				if t, err := x.LocalDateTime(nil); err == nil {
+					inferred = t.Location()
+				}
+			}
+			zone = inferred
+		}
+		if zone == nil && s.Zone != nil {
+			return fmt.Errorf("exif: cannot convert %v to %v: its time zone is unknown", ts.date, s.Zone)
+		}
+
+		out := s.Zone
+		if out == nil {
+			out = zone
+		}
+		if zone == nil {
+			// only the wall clock time is known
+			zone, out = time.UTC, time.UTC
+		}
+		t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), zone)
+		t = t.Add(s.By).In(out)
+
+		val, err := textVal(tag, t.Format(layout))
+		if err != nil {
+			return err
+		}
+		patches = append(patches, fieldPatch{ts.date, val})
+		if subSec != nil {
+			frac := fmt.Sprintf("%0*d", digits, t.Nanosecond()/int(math.Pow10(9-digits)))
+			val := append([]byte(nil), subSec.Val...)
+			copy(val, frac)
+			patches = append(patches, fieldPatch{ts.subSec, val})
+		}
+		if offset != nil {
+			val, err := textVal(offset, t.Format("-07:00"))
+			if err != nil {
+				return err
+			}
+			patches = append(patches, fieldPatch{ts.offset, val})
+		}
+	}
+
+	if s.By != 0 && x.hasGPSTime() {
+		p, err := x.shiftGPSTime(s.By)
+		if err != nil {
+			return err
+		}
+		patches = append(patches, p...)
+	}
+
+	for _, p := range patches {
+		if f, _ := x.GetField(p.name); f != nil {
+			if _, err := x.patchOffset(f); err != nil {
+				return err
+			}
+		}
+	}
+	for _, p := range patches {
+		if err := x.PatchTag(w, p.name, p.val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ShiftFile applies s to the timestamps of the named file in place, as
+// described by ShiftTimes, e.g. for batch edits of a directory of photos.
+func ShiftFile(name string, s TimeShift) error {
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	x, err := DecodeReaderAt(f, fi.Size())
+	if err != nil && (x == nil || IsCriticalError(err)) {
+		return err
+	}
+	if err := x.ShiftTimes(f, s); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// parseOffset parses an OffsetTime value such as "+09:00".
+func parseOffset(s string) (*time.Location, error) {
+	t, err := time.Parse("-07:00", strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	_, off := t.Zone()
+	return time.FixedZone("", off), nil
+}
+
+// textVal returns the value of the ASCII tag holding s, padded with NUL
+// bytes to the length of the current value.
+func textVal(tag *tiff.Tag, s string) ([]byte, error) {
+	if tag.Type != tiff.DTAscii || len(s) >= len(tag.Val) {
+		return nil, fmt.Errorf("exif: cannot rewrite tag %#04x: %q does not fit", tag.Id, s)
+	}
+	val := make([]byte, len(tag.Val))
+	copy(val, s)
+	return val, nil
+}
+
+func (x *Exif) hasGPSTime() bool {
+	_, err1 := x.Get(GPSDateStamp)
+	_, err2 := x.Get(GPSTimeStamp)
+	return err1 == nil && err2 == nil
+}
+
+// shiftGPSTime returns the patches of GPSDateStamp and GPSTimeStamp adding d
+// to the GPS time. The denominator of the seconds is kept.
+func (x *Exif) shiftGPSTime(d time.Duration) ([]fieldPatch, error) {
+	t, err := x.GPSDateTime()
+	if err != nil {
+		return nil, fmt.Errorf("exif: cannot shift GPS time: %v", err)
+	}
+	t = t.Add(d)
+	dateTag, _ := x.Get(GPSDateStamp)
+	date, err := textVal(dateTag, t.Format("2006:01:02"))
+	if err != nil {
+		return nil, err
+	}
+
+	timeTag, _ := x.Get(GPSTimeStamp)
+	if timeTag.Type != tiff.DTRational {
+		return nil, fmt.Errorf("exif: cannot shift GPS time: GPSTimeStamp is not rational")
+	}
+	_, den, err := timeTag.Rat2(2)
+	if err != nil {
+		return nil, err
+	}
+	sec := float64(t.Second()) + float64(t.Nanosecond())/1e9
+	order := x.Tiff.Order
+	val := make([]byte, 24)
+	for i, r := range [][2]uint32{
+		{uint32(t.Hour()), 1},
+		{uint32(t.Minute()), 1},
+		{uint32(math.Round(sec * float64(den))), uint32(den)},
+	} {
+		order.PutUint32(val[8*i:], r[0])
+		order.PutUint32(val[8*i+4:], r[1])
+	}
+	return []fieldPatch{{GPSDateStamp, date}, {GPSTimeStamp, val}}, nil
+}
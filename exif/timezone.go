@@ -0,0 +1,67 @@
+package exif
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// A TimeZoneLookup finds the time zone in effect at a position, typically
+// using a time zone boundary database. The exif package does not ship such a
+// database; see LocalDateTime.
+type TimeZoneLookup interface {
+	// Lookup returns the location for the given latitude and longitude in
+	// degrees, as returned by LatLong.
+	Lookup(lat, long float64) (*time.Location, error)
+}
+
+// TimeZoneLookupFunc adapts an ordinary function to the TimeZoneLookup
+// interface.
+type TimeZoneLookupFunc func(lat, long float64) (*time.Location, error)
+
+// Lookup calls f(lat, long).
+func (f TimeZoneLookupFunc) Lookup(lat, long float64) (*time.Location, error) {
+	return f(lat, long)
+}
+
+// maxZoneOffset is the largest UTC offset in use anywhere (UTC+14).
+const maxZoneOffset = 14 * time.Hour
+
+// LocalDateTime returns the creation time of the photo (see DateTime) in the
+// time zone it was taken in.
+//
+// If lookup is non-nil and the photo has GPS coordinates, the time zone is
+// the one lookup reports for them. Otherwise the time zone is inferred by
+// comparing the camera's local time with the GPS time (GPSDateStamp and
+// GPSTimeStamp, which are in UTC), rounded to the nearest quarter hour; the
+// returned location is then a fixed zone without DST rules. An error is
+// returned if neither method yields a time zone.
+func (x *Exif) LocalDateTime(lookup TimeZoneLookup) (time.Time, error) {
+	if lookup != nil {
+		if lat, long, err := x.LatLong(); err == nil {
+			loc, err := lookup.Lookup(lat, long)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("exif: time zone lookup failed: %v", err)
+			}
+			return x.dateTimeIn(loc)
+		}
+	}
+
+	local, err := x.dateTimeIn(time.UTC)
+	if err != nil {
+		return time.Time{}, err
+	}
+	utc, err := x.GPSDateTime()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("exif: cannot infer time zone: %v", err)
+	}
+	offset := local.Sub(utc)
+	// GPS time is usually recorded within seconds of the shutter, but some
+	// cameras record the time of the last fix.
+	offset = time.Duration(math.Round(float64(offset)/float64(15*time.Minute))) * 15 * time.Minute
+	if offset > maxZoneOffset || offset < -maxZoneOffset {
+		return time.Time{}, errors.New("exif: local time and GPS time are too far apart to infer a time zone")
+	}
+	return local.Add(-offset).In(time.FixedZone("", int(offset/time.Second))), nil
+}
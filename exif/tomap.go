@@ -0,0 +1,167 @@
+package exif
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// ValueStyle selects how ToMap renders field values.
+type ValueStyle int
+
+const (
+	// ValueRaw renders values as stored: strings, int64 values, rationals
+	// as "num/den" strings and float64 values. Fields holding several
+	// values are rendered as slices of these.
+	ValueRaw ValueStyle = iota
+	// ValueNumeric is like ValueRaw, but renders rationals as float64
+	// values, as needed by search indexes mapping them to numeric fields.
+	// Fields holding a rational with a zero denominator are omitted.
+	ValueNumeric
+	// ValueHuman renders values as text as ExifToolFields does, e.g.
+	// "1/250" for ExposureTime and "Rotate 90 CW" for Orientation.
+	ValueHuman
+)
+
+// MapOptions holds the options of ToMap.
+type MapOptions struct {
+	Values ValueStyle
+	// Unknown includes the tags without a known field name, keyed by
+	// UnknownPrefix followed by the tag ID in hex (e.g. "UnknownTag_9999").
+	Unknown bool
+	// Binary includes the fields of undefined type that do not hold text,
+	// such as MakerNote, as []byte values. They are omitted otherwise,
+	// unless ValueHuman has a text rendering for them.
+	Binary bool
+}
+
+// ToMap returns the fields available through Get as a flat map keyed by
+// field name (e.g. "FNumber" or "Canon.ImageType" for maker note fields),
+// with values rendered as selected by opts, e.g. for templates, structured
+// logging or documents fed to search indexes.
+func (x *Exif) ToMap(opts MapOptions) map[string]interface{} {
+	m := map[string]interface{}{}
+	add := func(name FieldName, tag *tiff.Tag) {
+		binary := isBinary(tag)
+		var v interface{}
+		switch {
+		case opts.Values == ValueHuman:
+			v = x.exifToolValue(name, tag)
+			if s, ok := v.(string); ok && binary && strings.HasPrefix(s, "(Binary data") {
+				v = nil
+			}
+		case binary:
+		default:
+			v = mapValue(tag, opts.Values == ValueNumeric)
+		}
+		if v == nil && binary && opts.Binary {
+			v = tag.Val
+		}
+		if v != nil {
+			m[string(name)] = v
+		}
+	}
+
+	for name, f := range x.main {
+		if opts.Unknown || !strings.HasPrefix(string(name), UnknownPrefix) {
+			add(name, f.Tag)
+		}
+	}
+	if !opts.Unknown {
+		return m
+	}
+	named := map[*tiff.Tag]bool{}
+	for _, f := range x.fields {
+		named[f.Tag] = true
+	}
+	for ifd := Ifd0; ifd <= IfdSubIFD; ifd++ {
+		for _, d := range x.dirs[ifd] {
+			for _, t := range d.Tags {
+				name := FieldName(fmt.Sprintf("%v%x", UnknownPrefix, t.Id))
+				if _, ok := m[string(name)]; !ok && !named[t] {
+					add(name, t)
+				}
+			}
+		}
+	}
+	return m
+}
+
+// isBinary reports whether tag is of undefined type and does not hold
+// printable ASCII text.
+func isBinary(tag *tiff.Tag) bool {
+	if f := tag.Format(); f != tiff.UndefVal && f != tiff.OtherVal {
+		return false
+	}
+	for _, b := range []byte(trimText(tag.Val)) {
+		if b < 0x20 || b > 0x7E {
+			return true
+		}
+	}
+	return false
+}
+
+// mapValue returns the value of tag rendered as described by ValueRaw, or
+// by ValueNumeric if numeric is true. It returns nil if the value cannot be
+// rendered.
+func mapValue(tag *tiff.Tag, numeric bool) interface{} {
+	switch tag.Format() {
+	case tiff.StringVal:
+		s, _ := tag.StringVal()
+		return strings.TrimRight(s, "\x00 ")
+	case tiff.UndefVal, tiff.OtherVal:
+		return trimText(tag.Val)
+	}
+
+	n := int(tag.Count)
+	switch {
+	case n == 0:
+		return nil
+	case tag.Format() == tiff.IntVal:
+		vals := make([]int64, n)
+		for i := range vals {
+			v, err := tag.Int64(i)
+			if err != nil {
+				return nil
+			}
+			vals[i] = v
+		}
+		if n == 1 {
+			return vals[0]
+		}
+		return vals
+	case tag.Format() == tiff.FloatVal, tag.Format() == tiff.RatVal && numeric:
+		vals := make([]float64, n)
+		for i := range vals {
+			v, ok := ratValue(tag, i)
+			if tag.Format() == tiff.FloatVal {
+				var err error
+				v, err = tag.Float(i)
+				ok = err == nil
+			}
+			if !ok {
+				return nil
+			}
+			vals[i] = v
+		}
+		if n == 1 {
+			return vals[0]
+		}
+		return vals
+	case tag.Format() == tiff.RatVal:
+		vals := make([]string, n)
+		for i := range vals {
+			num, den, err := tag.Rat2(i)
+			if err != nil {
+				return nil
+			}
+			vals[i] = fmt.Sprintf("%d/%d", num, den)
+		}
+		if n == 1 {
+			return vals[0]
+		}
+		return vals
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package exif
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// A FieldSpec gives the constraints a Schema places on a field.
+type FieldSpec struct {
+	// Ifd is the IFD the field must be stored in.
+	Ifd IfdID
+	// Types lists the data types the field may have.
+	Types []tiff.DataType
+	// Count is the number of values the field must have, or 0 if any
+	// number is allowed. For ASCII fields it includes the terminating NUL.
+	Count uint32
+	// Required makes the field mandatory.
+	Required bool
+}
+
+// A Schema maps each field it constrains to its FieldSpec. Fields not in the
+// schema are not checked.
+type Schema map[FieldName]FieldSpec
+
+var (
+	dtShort     = []tiff.DataType{tiff.DTShort}
+	dtShortLong = []tiff.DataType{tiff.DTShort, tiff.DTLong}
+	dtASCII     = []tiff.DataType{tiff.DTAscii}
+	dtRational  = []tiff.DataType{tiff.DTRational}
+	dtSRational = []tiff.DataType{tiff.DTSRational}
+	dtUndef     = []tiff.DataType{tiff.DTUndefined}
+	dtByte      = []tiff.DataType{tiff.DTByte}
+	dtLong      = []tiff.DataType{tiff.DTLong}
+	dtFloat     = []tiff.DataType{tiff.DTFloat}
+	dtDouble    = []tiff.DataType{tiff.DTDouble}
+	dtASCIIByte = []tiff.DataType{tiff.DTAscii, tiff.DTByte}
+
+	dtShortRational     = []tiff.DataType{tiff.DTShort, tiff.DTRational}
+	dtShortLongRational = []tiff.DataType{tiff.DTShort, tiff.DTLong, tiff.DTRational}
+)
+
+// Exif232 holds the constraints of the Exif 2.32 specification (CIPA
+// DC-008-2019) on the types and counts of the fields of IFD0, the Exif
+// sub-IFD and the GPS sub-IFD. Of the fields the specification makes
+// mandatory, the resolution fields and ExifVersion, which virtually all
+// writers include, are required.
+var Exif232 = Schema{
+	ImageWidth:                {Ifd0, dtShortLong, 1, false},
+	ImageLength:               {Ifd0, dtShortLong, 1, false},
+	BitsPerSample:             {Ifd0, dtShort, 3, false},
+	Compression:               {Ifd0, dtShort, 1, false},
+	PhotometricInterpretation: {Ifd0, dtShort, 1, false},
+	Orientation:               {Ifd0, dtShort, 1, false},
+	SamplesPerPixel:           {Ifd0, dtShort, 1, false},
+	PlanarConfiguration:       {Ifd0, dtShort, 1, false},
+	YCbCrSubSampling:          {Ifd0, dtShort, 2, false},
+	YCbCrPositioning:          {Ifd0, dtShort, 1, false},
+	XResolution:               {Ifd0, dtRational, 1, true},
+	YResolution:               {Ifd0, dtRational, 1, true},
+	ResolutionUnit:            {Ifd0, dtShort, 1, true},
+	DateTime:                  {Ifd0, dtASCII, 20, false},
+	ImageDescription:          {Ifd0, dtASCII, 0, false},
+	Make:                      {Ifd0, dtASCII, 0, false},
+	Model:                     {Ifd0, dtASCII, 0, false},
+	Software:                  {Ifd0, dtASCII, 0, false},
+	Artist:                    {Ifd0, dtASCII, 0, false},
+	Copyright:                 {Ifd0, dtASCII, 0, false},
+
+	ExposureTime:             {IfdExif, dtRational, 1, false},
+	FNumber:                  {IfdExif, dtRational, 1, false},
+	ExposureProgram:          {IfdExif, dtShort, 1, false},
+	ISOSpeedRatings:          {IfdExif, dtShort, 0, false},
+	ExifVersion:              {IfdExif, dtUndef, 4, true},
+	DateTimeOriginal:         {IfdExif, dtASCII, 20, false},
+	DateTimeDigitized:        {IfdExif, dtASCII, 20, false},
+	ComponentsConfiguration:  {IfdExif, dtUndef, 4, false},
+	CompressedBitsPerPixel:   {IfdExif, dtRational, 1, false},
+	ShutterSpeedValue:        {IfdExif, dtSRational, 1, false},
+	ApertureValue:            {IfdExif, dtRational, 1, false},
+	BrightnessValue:          {IfdExif, dtSRational, 1, false},
+	ExposureBiasValue:        {IfdExif, dtSRational, 1, false},
+	MaxApertureValue:         {IfdExif, dtRational, 1, false},
+	SubjectDistance:          {IfdExif, dtRational, 1, false},
+	MeteringMode:             {IfdExif, dtShort, 1, false},
+	LightSource:              {IfdExif, dtShort, 1, false},
+	Flash:                    {IfdExif, dtShort, 1, false},
+	FocalLength:              {IfdExif, dtRational, 1, false},
+	MakerNote:                {IfdExif, dtUndef, 0, false},
+	UserComment:              {IfdExif, dtUndef, 0, false},
+	SubSecTime:               {IfdExif, dtASCII, 0, false},
+	SubSecTimeOriginal:       {IfdExif, dtASCII, 0, false},
+	SubSecTimeDigitized:      {IfdExif, dtASCII, 0, false},
+	OffsetTime:               {IfdExif, dtASCII, 7, false},
+	OffsetTimeOriginal:       {IfdExif, dtASCII, 7, false},
+	OffsetTimeDigitized:      {IfdExif, dtASCII, 7, false},
+	FlashpixVersion:          {IfdExif, dtUndef, 4, false},
+	ColorSpace:               {IfdExif, dtShort, 1, false},
+	PixelXDimension:          {IfdExif, dtShortLong, 1, false},
+	PixelYDimension:          {IfdExif, dtShortLong, 1, false},
+	FocalPlaneXResolution:    {IfdExif, dtRational, 1, false},
+	FocalPlaneYResolution:    {IfdExif, dtRational, 1, false},
+	FocalPlaneResolutionUnit: {IfdExif, dtShort, 1, false},
+	SensingMethod:            {IfdExif, dtShort, 1, false},
+	FileSource:               {IfdExif, dtUndef, 1, false},
+	SceneType:                {IfdExif, dtUndef, 1, false},
+	CustomRendered:           {IfdExif, dtShort, 1, false},
+	ExposureMode:             {IfdExif, dtShort, 1, false},
+	WhiteBalance:             {IfdExif, dtShort, 1, false},
+	DigitalZoomRatio:         {IfdExif, dtRational, 1, false},
+	FocalLengthIn35mmFilm:    {IfdExif, dtShort, 1, false},
+	SceneCaptureType:         {IfdExif, dtShort, 1, false},
+	GainControl:              {IfdExif, dtShort, 1, false},
+	Contrast:                 {IfdExif, dtShort, 1, false},
+	Saturation:               {IfdExif, dtShort, 1, false},
+	Sharpness:                {IfdExif, dtShort, 1, false},
+	SubjectDistanceRange:     {IfdExif, dtShort, 1, false},
+	ImageUniqueID:            {IfdExif, dtASCII, 33, false},
+	CameraOwnerName:          {IfdExif, dtASCII, 0, false},
+	BodySerialNumber:         {IfdExif, dtASCII, 0, false},
+	LensMake:                 {IfdExif, dtASCII, 0, false},
+	LensModel:                {IfdExif, dtASCII, 0, false},
+	LensSerialNumber:         {IfdExif, dtASCII, 0, false},
+
+	GPSVersionID:       {IfdGPS, dtByte, 4, false},
+	GPSLatitudeRef:     {IfdGPS, dtASCII, 2, false},
+	GPSLatitude:        {IfdGPS, dtRational, 3, false},
+	GPSLongitudeRef:    {IfdGPS, dtASCII, 2, false},
+	GPSLongitude:       {IfdGPS, dtRational, 3, false},
+	GPSAltitudeRef:     {IfdGPS, dtByte, 1, false},
+	GPSAltitude:        {IfdGPS, dtRational, 1, false},
+	GPSTimeStamp:       {IfdGPS, dtRational, 3, false},
+	GPSImgDirectionRef: {IfdGPS, dtASCII, 2, false},
+	GPSImgDirection:    {IfdGPS, dtRational, 1, false},
+	GPSDateStamp:       {IfdGPS, dtASCII, 11, false},
+}
+
+// Schemas maps schema names, as accepted by the verify command of
+// cmd/exif, to schemas.
+var Schemas = map[string]Schema{
+	"exif2.32": Exif232,
+	"dng1.6":   DNG16,
+}
+
+// Validators maps the names of Schemas to the functions checking a file
+// against them: Validate with the schema, except for DNG16, which is checked
+// with the other DNG constraints by ValidateDNG.
+var Validators = map[string]func(*Exif) []error{
+	"exif2.32": func(x *Exif) []error { return x.Validate(Exif232) },
+	"dng1.6":   (*Exif).ValidateDNG,
+}
+
+// Validate checks the fields of x available through Get against s and
+// returns the violations found, sorted by field name: missing required
+// fields and fields stored in the wrong IFD or with a data type or count
+// the schema does not allow.
+func (x *Exif) Validate(s Schema) []error {
+	var errs []error
+	for _, name := range sortedNames(s) {
+		spec := s[name]
+		f, ok := x.main[name]
+		if !ok {
+			if spec.Required {
+				errs = append(errs, fmt.Errorf("exif: required field %v is missing", name))
+			}
+			continue
+		}
+		if f.Ifd != spec.Ifd {
+			errs = append(errs, fmt.Errorf("exif: field %v is stored in %v, want %v", name, f.Ifd, spec.Ifd))
+		}
+		errs = append(errs, checkField(name, spec, f.Tag)...)
+	}
+	return errs
+}
+
+// checkField returns the violations of the type and count constraints of
+// spec by tag, the value of field name.
+func checkField(name FieldName, spec FieldSpec, tag *tiff.Tag) []error {
+	var errs []error
+	if !containsType(spec.Types, tag.Type) {
+		errs = append(errs, fmt.Errorf("exif: field %v has type %v, want one of %v", name, tag.Type, spec.Types))
+	}
+	if spec.Count != 0 && tag.Count != spec.Count {
+		errs = append(errs, fmt.Errorf("exif: field %v has %d values, want %d", name, tag.Count, spec.Count))
+	}
+	return errs
+}
+
+// sortedNames returns the names of the fields s constrains, sorted.
+func sortedNames(s Schema) []FieldName {
+	names := make([]FieldName, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+func containsType(types []tiff.DataType, dt tiff.DataType) bool {
+	for _, t := range types {
+		if t == dt {
+			return true
+		}
+	}
+	return false
+}
+
+// Spec returns the data types and number of values the specifications
+// define for the named field, and the IFD it is stored in, as listed in the
+// field registry. ok is false for fields the registry defines no types for.
+func Spec(name FieldName) (spec FieldSpec, ok bool) {
+	spec, ok = fieldSpecs[name]
+	return spec, ok
+}
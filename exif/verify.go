@@ -0,0 +1,71 @@
+package exif
+
+import (
+	"fmt"
+	"sort"
+)
+
+// region is a byte range [start, end) of the tiff data in x.Raw.
+type region struct {
+	start, end int64
+	what       string
+}
+
+// Verify checks the layout of the tiff data in x.Raw, as broken editors
+// often leave offsets pointing at the wrong place when rewriting it. It
+// reports each IFD and tag value of IFD0, IFD1, the Exif, GPS and
+// Interoperability sub-IFDs and the SubIFDs that extends past the end of the
+// data or overlaps an IFD or another tag value. Values stored inside their IFD
+// entry and maker note IFDs, whose offsets may be relative to other data,
+// are not checked.
+//
+// The problems found do not prevent x from being used: the tags involved
+// were decoded, but may hold other data than the writer intended.
+func (x *Exif) Verify() []error {
+	if x.Raw == nil {
+		return nil
+	}
+	size := int64(len(x.Raw))
+
+	var regions []region
+	for _, ifd := range []IfdID{Ifd0, Ifd1, IfdExif, IfdGPS, IfdInterop, IfdSubIFD} {
+		if _, ok := x.extra[ifd]; ok {
+			// stored in separate tiff data
+			continue
+		}
+		for _, d := range x.dirs[ifd] {
+			if d.Layout.Offset < 0 {
+				continue
+			}
+			regions = append(regions, region{d.Layout.Offset, d.Layout.Offset + d.Layout.Length, ifd.String()})
+			for _, t := range d.Tags {
+				if t.Inlined() || len(t.Val) == 0 {
+					continue
+				}
+				start := int64(t.ValOffset)
+				regions = append(regions, region{start, start + int64(len(t.Val)), fmt.Sprintf("%v tag %#04x value", ifd, t.Id)})
+			}
+		}
+	}
+
+	var errs []error
+	for _, r := range regions {
+		if r.end > size {
+			errs = append(errs, fmt.Errorf("exif: %s at %d-%d extends past the end of the %d bytes of tiff data", r.what, r.start, r.end, size))
+		}
+	}
+
+	// Report each region overlapping the farthest reaching region before it.
+	sort.SliceStable(regions, func(i, j int) bool { return regions[i].start < regions[j].start })
+	var reach *region
+	for i := range regions {
+		r := &regions[i]
+		if reach != nil && r.start < reach.end {
+			errs = append(errs, fmt.Errorf("exif: %s at %d-%d overlaps %s at %d-%d", r.what, r.start, r.end, reach.what, reach.start, reach.end))
+		}
+		if reach == nil || r.end > reach.end {
+			reach = r
+		}
+	}
+	return errs
+}
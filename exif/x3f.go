@@ -0,0 +1,149 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+)
+
+// x3fSignature starts every Sigma/Foveon X3F file.
+const x3fSignature = "FOVb"
+
+// x3fSection is an entry of the section directory of an X3F file.
+type x3fSection struct {
+	// typ is "PROP" for properties, "IMAG" or "IMA2" for images and "CAMF"
+	// for camera calibration data.
+	typ  string
+	data []byte
+	// offset of data in the file
+	offset int64
+}
+
+// x3fSections returns the sections of the X3F file data, listed by the
+// section directory whose offset is stored in the last 4 bytes of the file.
+func x3fSections(data []byte) ([]x3fSection, error) {
+	if len(data) < 8 || string(data[:4]) != x3fSignature {
+		return nil, errors.New("exif: not an X3F file")
+	}
+	dirOff := int64(binary.LittleEndian.Uint32(data[len(data)-4:]))
+	if dirOff+12 > int64(len(data)) || string(data[dirOff:dirOff+4]) != "SECd" {
+		return nil, errors.New("exif: X3F section directory not found")
+	}
+	n := int64(binary.LittleEndian.Uint32(data[dirOff+8:]))
+	entries := data[dirOff+12:]
+	if n > int64(len(entries))/12 {
+		return nil, errors.New("exif: X3F section directory truncated")
+	}
+
+	sections := make([]x3fSection, 0, n)
+	for i := int64(0); i < n; i++ {
+		e := entries[12*i:]
+		off := int64(binary.LittleEndian.Uint32(e))
+		size := int64(binary.LittleEndian.Uint32(e[4:]))
+		if off+size > int64(len(data)) {
+			return nil, fmt.Errorf("exif: X3F %q section exceeds file size", e[8:12])
+		}
+		sections = append(sections, x3fSection{typ: string(e[8:12]), data: data[off : off+size], offset: off})
+	}
+	return sections, nil
+}
+
+// x3fPreview returns the first JPEG image of the X3F file data, which holds
+// the EXIF data of the file, and its offset in data.
+func x3fPreview(data []byte) ([]byte, int64, error) {
+	sections, err := x3fSections(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, s := range sections {
+		// image sections have a 28 byte header: "SECi", version, type,
+		// format, columns, rows and row size
+		if s.typ != "IMAG" && s.typ != "IMA2" || len(s.data) < 28+2 || string(s.data[:4]) != "SECi" {
+			continue
+		}
+		const formatJPEG = 18
+		if binary.LittleEndian.Uint32(s.data[12:]) != formatJPEG || s.data[28] != 0xFF || s.data[29] != 0xD8 {
+			continue
+		}
+		return s.data[28:], s.offset + 28, nil
+	}
+	return nil, 0, errors.New("exif: no JPEG preview holding EXIF data in X3F file")
+}
+
+// X3FProperties returns the properties stored in the PROP sections of the
+// Sigma/Foveon X3F file in r, e.g. "CAMMANUF", "CAMMODEL", "CAMSERIAL" or
+// "TIME". Unlike the EXIF data of the file, which Decode reads from its
+// embedded JPEG preview, properties are written by all X3F cameras.
+func X3FProperties(r io.Reader) (map[string]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := x3fSections(data)
+	if err != nil {
+		return nil, err
+	}
+
+	props := map[string]string{}
+	for _, s := range sections {
+		// property sections have a 24 byte header: "SECp", version, entry
+		// count, character format, reserved and character count, followed
+		// by name and value character offsets and the UTF-16 characters
+		if s.typ != "PROP" || len(s.data) < 24 || string(s.data[:4]) != "SECp" {
+			continue
+		}
+		n := int64(binary.LittleEndian.Uint32(s.data[8:]))
+		if format := binary.LittleEndian.Uint32(s.data[12:]); format != 0 {
+			return nil, fmt.Errorf("exif: unsupported X3F property format %d", format)
+		}
+		nChars := int64(binary.LittleEndian.Uint32(s.data[20:]))
+		if 24+8*n+2*nChars > int64(len(s.data)) {
+			return nil, errors.New("exif: X3F property section truncated")
+		}
+		chars := make([]uint16, nChars)
+		for i := range chars {
+			chars[i] = binary.LittleEndian.Uint16(s.data[24+8*n+2*int64(i):])
+		}
+		str := func(off uint32) (string, error) {
+			if int64(off) >= nChars {
+				return "", errors.New("exif: X3F property offset out of range")
+			}
+			end := int64(off)
+			for end < nChars && chars[end] != 0 {
+				end++
+			}
+			return string(utf16.Decode(chars[off:end])), nil
+		}
+		for i := int64(0); i < n; i++ {
+			e := s.data[24+8*i:]
+			name, err := str(binary.LittleEndian.Uint32(e))
+			if err != nil {
+				return nil, err
+			}
+			val, err := str(binary.LittleEndian.Uint32(e[4:]))
+			if err != nil {
+				return nil, err
+			}
+			props[name] = val
+		}
+	}
+	return props, nil
+}
+
+// x3fReader returns a reader of the JPEG preview of the X3F file in r and
+// its offset in the file. A file larger than maxBytes, if positive, fails
+// with an error wrapping ErrLimitExceeded.
+func x3fReader(r io.Reader, maxBytes int64) (*bytes.Reader, int64, error) {
+	data, err := readLimited(r, maxBytes, "X3F file")
+	if err != nil {
+		return nil, 0, err
+	}
+	jpg, off, err := x3fPreview(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(jpg), off, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -13,6 +14,8 @@ import (
 
 var mnote = flag.Bool("mknote", false, "try to parse makernote data")
 var thumb = flag.Bool("thumb", false, "dump thumbail data to stdout (for first listed image file)")
+var exiftool = flag.Bool("j", false, "print fields as JSON like 'exiftool -j'")
+var trace = flag.Bool("trace", false, "log each segment, IFD and tag decoded to stderr")
 
 func main() {
 	flag.Parse()
@@ -22,6 +25,12 @@ func main() {
 		exif.RegisterParsers(mknote.All...)
 	}
 
+	dec := new(exif.Decoder)
+	if *trace {
+		dec.Trace = func(ev tiff.TraceEvent) { log.Print(ev) }
+	}
+
+	var objs []map[string]interface{}
 	for _, name := range fnames {
 		f, err := os.Open(name)
 		if err != nil {
@@ -29,7 +38,7 @@ func main() {
 			continue
 		}
 
-		x, err := exif.Decode(f)
+		x, err := dec.Decode(f)
 		if err != nil {
 			log.Printf("err on %v: %v", name, err)
 			continue
@@ -46,9 +55,24 @@ func main() {
 			return
 		}
 
+		if *exiftool {
+			m := x.ExifToolFields()
+			m["SourceFile"] = name
+			objs = append(objs, m)
+			continue
+		}
+
 		fmt.Printf("\n---- Image '%v' ----\n", name)
 		x.Walk(Walker{})
 	}
+
+	if *exiftool {
+		data, err := json.MarshalIndent(objs, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(data))
+	}
 }
 
 type Walker struct{}
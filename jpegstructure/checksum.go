@@ -0,0 +1,110 @@
+package jpegstructure
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// metadata reports whether marker starts a metadata segment, i.e. an APPn
+// or COM segment.
+func metadata(marker byte) bool {
+	return marker >= APP0 && marker <= APP15 || marker == COM
+}
+
+// HashImage writes the image content of s to h: the segments other than
+// APPn and COM segments (quantization and Huffman tables, frame headers...),
+// then the scans with their entropy-coded data and the non-metadata segments
+// between them, up to the EOI marker. Metadata segments found between scans
+// and any data after EOI are skipped too, so that two streams holding the
+// same image with different metadata hash the same.
+func (s *Structure) HashImage(h hash.Hash) error {
+	for _, seg := range s.Segments {
+		if !metadata(seg.Marker) {
+			writeSegment(h, seg)
+		}
+	}
+
+	data := s.ImageData
+	pos := 0
+	for pos < len(data) {
+		if data[pos] != 0xFF {
+			return fmt.Errorf("jpegstructure: invalid marker in image data at offset %d", pos)
+		}
+		for pos < len(data) && data[pos] == 0xFF {
+			pos++
+		}
+		if pos >= len(data) {
+			break
+		}
+		marker := data[pos]
+		pos++
+		switch {
+		case marker == EOI:
+			h.Write([]byte{0xFF, EOI})
+			return nil
+		case standalone(marker):
+			h.Write([]byte{0xFF, marker})
+			continue
+		}
+		if pos+2 > len(data) {
+			return fmt.Errorf("jpegstructure: segment in image data at offset %d is truncated", pos-2)
+		}
+		n := int(binary.BigEndian.Uint16(data[pos:]))
+		if n < 2 || pos+n > len(data) {
+			return fmt.Errorf("jpegstructure: segment in image data at offset %d has invalid length %d", pos-2, n)
+		}
+		if !metadata(marker) {
+			writeSegment(h, Segment{Marker: marker, Data: data[pos+2 : pos+n]})
+		}
+		pos += n
+		if marker != SOS {
+			continue
+		}
+
+		// The entropy-coded data runs up to the next marker other than a
+		// stuffed zero byte or a restart marker.
+		start := pos
+		for pos < len(data) {
+			if data[pos] == 0xFF && pos+1 < len(data) {
+				if m := data[pos+1]; m != 0x00 && (m < 0xD0 || m > 0xD7) {
+					break
+				}
+				pos++
+			}
+			pos++
+		}
+		h.Write(data[start:pos])
+	}
+	// a truncated stream without EOI marker hashes as far as it goes
+	return nil
+}
+
+func writeSegment(w io.Writer, seg Segment) {
+	w.Write([]byte{0xFF, seg.Marker})
+	if !standalone(seg.Marker) {
+		var n [2]byte
+		binary.BigEndian.PutUint16(n[:], uint16(seg.Length()))
+		w.Write(n[:])
+		w.Write(seg.Data)
+	}
+}
+
+// Checksum returns the SHA-256 checksum of the image content of the JPEG
+// stream in r, excluding its metadata (see HashImage), for deduplication
+// tools to recognize the same image with different metadata.
+func Checksum(r io.Reader) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	s, err := Parse(r)
+	if err != nil {
+		return sum, err
+	}
+	h := sha256.New()
+	if err := s.HashImage(h); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
@@ -0,0 +1,206 @@
+// Package jpegstructure exposes the marker segments of a JPEG stream, so that
+// metadata segments (EXIF, XMP, ICC profiles, comments...) can be inserted,
+// replaced or deleted and the stream written back without decoding or
+// re-encoding the image data.
+package jpegstructure
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Markers of the JPEG segments commonly handled, i.e. the second byte of
+// their 0xFF-prefixed marker.
+const (
+	SOI   = 0xD8 // start of image
+	EOI   = 0xD9 // end of image
+	SOS   = 0xDA // start of scan
+	APP0  = 0xE0 // JFIF
+	APP1  = 0xE1 // EXIF and XMP
+	APP2  = 0xE2 // ICC profile
+	APP13 = 0xED // Photoshop IRB and IPTC
+	APP15 = 0xEF
+	COM   = 0xFE // comment
+)
+
+// MaxData is the largest payload a segment can hold: its length field is 16
+// bits and counts itself.
+const MaxData = 0xFFFF - 2
+
+// A Segment is a marker segment of a JPEG stream.
+type Segment struct {
+	// Marker is the second byte of the segment's marker (e.g. 0xE1 for
+	// APP1).
+	Marker byte
+	// Offset is the offset of the segment's marker from the start of the
+	// stream it was parsed from, or -1 for segments inserted since.
+	Offset int64
+	// Data holds the segment payload, excluding the marker and length. It
+	// is nil for standalone markers, which have no length field.
+	Data []byte
+}
+
+// standalone reports whether marker has no length field and payload.
+func standalone(marker byte) bool {
+	return marker == 0x01 || marker >= 0xD0 && marker <= 0xD7
+}
+
+// Length returns the value of the segment's length field, which counts the
+// payload and itself, or 0 for standalone markers.
+func (seg *Segment) Length() int {
+	if standalone(seg.Marker) {
+		return 0
+	}
+	return len(seg.Data) + 2
+}
+
+func (seg *Segment) String() string {
+	return fmt.Sprintf("marker 0x%02X at offset %d, length %d", seg.Marker, seg.Offset, seg.Length())
+}
+
+// A Structure is a JPEG stream split into its marker segments.
+type Structure struct {
+	// Segments holds the segments after the SOI marker and before the
+	// first SOS marker, in stream order.
+	Segments []Segment
+	// ImageData holds the rest of the stream from the first SOS marker on:
+	// the scans, the segments between them in progressive files, the EOI
+	// marker and any trailing data. It is written back unchanged.
+	ImageData []byte
+}
+
+// Parse reads the JPEG stream in r and splits it into its segments.
+func Parse(r io.Reader) (*Structure, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2 || data[0] != 0xFF || data[1] != SOI {
+		return nil, errors.New("jpegstructure: missing SOI marker")
+	}
+
+	s := new(Structure)
+	pos := 2
+	for {
+		if pos >= len(data) {
+			return nil, errors.New("jpegstructure: missing SOS marker")
+		}
+		if data[pos] != 0xFF {
+			return nil, fmt.Errorf("jpegstructure: invalid marker at offset %d", pos)
+		}
+		start := pos
+		// skip fill bytes
+		for pos < len(data) && data[pos] == 0xFF {
+			pos++
+		}
+		if pos >= len(data) {
+			return nil, errors.New("jpegstructure: missing SOS marker")
+		}
+		marker := data[pos]
+		pos++
+		switch {
+		case marker == SOS:
+			s.ImageData = data[pos-2:]
+			return s, nil
+		case marker == SOI || marker == EOI:
+			return nil, fmt.Errorf("jpegstructure: unexpected marker 0x%02X at offset %d", marker, start)
+		case standalone(marker):
+			s.Segments = append(s.Segments, Segment{Marker: marker, Offset: int64(pos - 2)})
+			continue
+		}
+		if pos+2 > len(data) {
+			return nil, fmt.Errorf("jpegstructure: segment at offset %d is truncated", pos-2)
+		}
+		n := int(binary.BigEndian.Uint16(data[pos:]))
+		if n < 2 || pos+n > len(data) {
+			return nil, fmt.Errorf("jpegstructure: segment at offset %d has invalid length %d", pos-2, n)
+		}
+		s.Segments = append(s.Segments, Segment{Marker: marker, Offset: int64(pos - 2), Data: data[pos+2 : pos+n]})
+		pos += n
+	}
+}
+
+// Index returns the index of the first segment with the given marker whose
+// payload starts with prefix, or -1 if there is none. E.g. Index(APP1,
+// []byte("Exif\x00\x00")) finds the EXIF segment.
+func (s *Structure) Index(marker byte, prefix []byte) int {
+	for i, seg := range s.Segments {
+		if seg.Marker == marker && bytes.HasPrefix(seg.Data, prefix) {
+			return i
+		}
+	}
+	return -1
+}
+
+// check returns an error if seg cannot be written.
+func check(seg Segment) error {
+	switch {
+	case seg.Marker == SOI || seg.Marker == EOI || seg.Marker == SOS || seg.Marker == 0x00 || seg.Marker == 0xFF:
+		return fmt.Errorf("jpegstructure: cannot insert a segment with marker 0x%02X", seg.Marker)
+	case standalone(seg.Marker) && len(seg.Data) > 0:
+		return fmt.Errorf("jpegstructure: standalone marker 0x%02X cannot hold data", seg.Marker)
+	case len(seg.Data) > MaxData:
+		return fmt.Errorf("jpegstructure: segment payload of %d bytes exceeds %d bytes", len(seg.Data), MaxData)
+	}
+	return nil
+}
+
+// Insert inserts seg before the segment at index i, or after the last
+// segment if i is len(s.Segments). seg.Offset is set to -1.
+func (s *Structure) Insert(i int, seg Segment) error {
+	if i < 0 || i > len(s.Segments) {
+		return fmt.Errorf("jpegstructure: insert index %d out of range", i)
+	}
+	if err := check(seg); err != nil {
+		return err
+	}
+	seg.Offset = -1
+	s.Segments = append(s.Segments, Segment{})
+	copy(s.Segments[i+1:], s.Segments[i:])
+	s.Segments[i] = seg
+	return nil
+}
+
+// Replace replaces the segment at index i with seg. seg.Offset is set to -1.
+func (s *Structure) Replace(i int, seg Segment) error {
+	if i < 0 || i >= len(s.Segments) {
+		return fmt.Errorf("jpegstructure: replace index %d out of range", i)
+	}
+	if err := check(seg); err != nil {
+		return err
+	}
+	seg.Offset = -1
+	s.Segments[i] = seg
+	return nil
+}
+
+// Delete deletes the segment at index i.
+func (s *Structure) Delete(i int) error {
+	if i < 0 || i >= len(s.Segments) {
+		return fmt.Errorf("jpegstructure: delete index %d out of range", i)
+	}
+	s.Segments = append(s.Segments[:i], s.Segments[i+1:]...)
+	return nil
+}
+
+// WriteTo implements io.WriterTo, writing the JPEG stream: the SOI marker,
+// the segments and the image data.
+func (s *Structure) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	buf.Write([]byte{0xFF, SOI})
+	for _, seg := range s.Segments {
+		if err := check(seg); err != nil {
+			return 0, err
+		}
+		writeSegment(&buf, seg)
+	}
+	n, err := w.Write(buf.Bytes())
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := w.Write(s.ImageData)
+	return int64(n + m), err
+}
@@ -0,0 +1,151 @@
+package jpegstructure_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/jpegstructure"
+)
+
+func TestRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "exif", "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := jpegstructure.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Segments) == 0 || s.ImageData[1] != jpegstructure.SOS {
+		t.Fatalf("got %d segments, image data starting with %x", len(s.Segments), s.ImageData[:2])
+	}
+	for _, seg := range s.Segments {
+		if seg.Offset < 2 || data[seg.Offset] != 0xFF || data[seg.Offset+1] != seg.Marker {
+			t.Errorf("segment %v does not point at its marker", &seg)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := s.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Error("written stream differs from the parsed one")
+	}
+}
+
+func TestEdit(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "exif", "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := jpegstructure.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := s.Index(jpegstructure.APP1, []byte("Exif\x00\x00"))
+	if i < 0 {
+		t.Fatal("no EXIF segment found")
+	}
+	exifSeg := s.Segments[i]
+
+	if err := s.Insert(0, jpegstructure.Segment{Marker: jpegstructure.COM, Data: []byte("hello")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(i + 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Insert(len(s.Segments), exifSeg); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Segments[len(s.Segments)-1].Offset; got != -1 {
+		t.Errorf("inserted segment offset = %d, want -1", got)
+	}
+	var buf bytes.Buffer
+	if _, err := s.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != len(data)+len("hello")+4 {
+		t.Errorf("edited stream is %d bytes, want %d", buf.Len(), len(data)+len("hello")+4)
+	}
+
+	s, err = jpegstructure.Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seg := s.Segments[0]; seg.Marker != jpegstructure.COM || string(seg.Data) != "hello" {
+		t.Errorf("first segment = %v %q, want the comment", &seg, seg.Data)
+	}
+	x, err := exif.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.Get(exif.Model); err != nil {
+		t.Error(err)
+	}
+
+	if err := s.Replace(0, jpegstructure.Segment{Marker: jpegstructure.COM, Data: make([]byte, jpegstructure.MaxData+1)}); err == nil {
+		t.Error("no error replacing with an oversized segment")
+	}
+	if err := s.Insert(0, jpegstructure.Segment{Marker: jpegstructure.SOS}); err == nil {
+		t.Error("no error inserting an SOS segment")
+	}
+	if err := s.Delete(len(s.Segments)); err == nil {
+		t.Error("no error deleting out of range")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"no SOI":     "\x89PNG",
+		"no SOS":     "\xFF\xD8\xFF\xFE\x00\x04hi",
+		"bad length": "\xFF\xD8\xFF\xFE\x00\x10hi",
+		"bad marker": "\xFF\xD8\x00\xFF\xDA",
+	} {
+		if _, err := jpegstructure.Parse(bytes.NewReader([]byte(data))); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "exif", "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := jpegstructure.Checksum(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := jpegstructure.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(s.Index(jpegstructure.APP1, []byte("Exif\x00\x00"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Insert(0, jpegstructure.Segment{Marker: jpegstructure.COM, Data: []byte("hello")}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := s.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	buf.WriteString("trailing")
+	if got, err := jpegstructure.Checksum(&buf); err != nil {
+		t.Fatal(err)
+	} else if got != want {
+		t.Errorf("checksum changed with the metadata: got %x, want %x", got, want)
+	}
+
+	edited := append([]byte(nil), data...)
+	edited[len(edited)-16] ^= 0x01
+	if got, err := jpegstructure.Checksum(bytes.NewReader(edited)); err != nil {
+		t.Fatal(err)
+	} else if got == want {
+		t.Error("checksum did not change with the image data")
+	}
+}
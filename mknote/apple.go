@@ -0,0 +1,40 @@
+package mknote
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+type apple struct{}
+
+// Parse decodes all Apple makernote data found in x and adds it to x. The
+// note starts with "Apple iOS\0", a 2 byte version and the byte order, and
+// its offsets are relative to the start of the note.
+func (_ *apple) Parse(x *exif.Exif) error {
+	m, err := x.Get(exif.MakerNote)
+	if err != nil {
+		return nil
+	}
+	const hdr = 14
+	if len(m.Val) < hdr || !bytes.HasPrefix(m.Val, []byte("Apple iOS\000")) {
+		return nil
+	}
+
+	var order binary.ByteOrder
+	switch string(m.Val[hdr-2 : hdr]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		order = dirOrder(m.Val[hdr:], x.Tiff.Order)
+	}
+	mkNotesDir, err := decodeNoteDir(m.Val, hdr, fixBase(x, m, hdr, order, 0), order)
+	if err != nil {
+		return err
+	}
+	x.LoadTags(mkNotesDir, makerNoteAppleFields, false)
+	return nil
+}
@@ -0,0 +1,177 @@
+package mknote
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// The value offsets in the IFD of a maker note are relative to a base that
+// depends on the camera and firmware: the tiff header, the start of the
+// file, the start of the note or, for notes moved by editors without
+// updating their offsets, some other point. This is described by a pad: the
+// distance from the base to the start of the note, so that the value at
+// offset o lies at note[o-pad]. Pads are negative for bases after the start
+// of the note.
+
+// notePads returns the pads of the usual bases of the maker note m of y:
+// the tiff header, the start of the note and the start of the file, if
+// known.
+func notePads(y *exif.Exif, m *tiff.Tag) []int64 {
+	pads := []int64{int64(m.ValOffset), 0}
+	if off, ok := y.TiffOffset(); ok && off > 0 {
+		pads = append(pads, off+int64(m.ValOffset))
+	}
+	return pads
+}
+
+// fixBase returns pad, the pad of the base documented for the format of the
+// maker note m of y whose IFD starts at m.Val[start:], if the values of the
+// IFD lie within the note with it. Otherwise, it guesses the base the way
+// ExifTool does: the usual bases are tried, and then the base placing the
+// lowest value offset right after the IFD, where writers store the first
+// value, and the first whose values lie within the note is returned. If none
+// does, pad is returned and the values out of range fail to decode.
+func fixBase(y *exif.Exif, m *tiff.Tag, start int, order binary.ByteOrder, pad int64) int64 {
+	note := m.Val
+	if valuesFit(note, start, order, pad) {
+		return pad
+	}
+	cands := notePads(y, m)
+	if min, end, ok := lowestValue(note, start, order); ok {
+		// with and without the offset to the next IFD, which some
+		// notes omit
+		cands = append(cands, min-end, min-end+4)
+	}
+	for _, p := range cands {
+		if p != pad && valuesFit(note, start, order, p) {
+			return p
+		}
+	}
+	return pad
+}
+
+// noteEntries calls fn with the value size and value offset of the entries
+// of the IFD at note[start:] whose values are stored outside of the entries,
+// and returns the offset of the end of the IFD in the note, including the
+// offset to the next IFD. ok is false if the entries do not fit in the note.
+func noteEntries(note []byte, start int, order binary.ByteOrder, fn func(size, off int64)) (end int64, ok bool) {
+	if start < 0 || start+2 > len(note) {
+		return 0, false
+	}
+	n := int(order.Uint16(note[start:]))
+	end = int64(start) + 2 + 12*int64(n)
+	if end > int64(len(note)) {
+		return 0, false
+	}
+	for i := 0; i < n; i++ {
+		e := note[start+2+12*i:]
+		size := typeSize(tiff.DataType(order.Uint16(e[2:]))) * int64(order.Uint32(e[4:]))
+		if size > 4 {
+			fn(size, int64(order.Uint32(e[8:])))
+		}
+	}
+	return end + 4, true
+}
+
+// typeSize returns the size in bytes of a component of the tiff data type
+// dt, or 0 for unknown types.
+func typeSize(dt tiff.DataType) int64 {
+	switch dt {
+	case tiff.DTByte, tiff.DTAscii, tiff.DTSByte, tiff.DTUndefined:
+		return 1
+	case tiff.DTShort, tiff.DTSShort:
+		return 2
+	case tiff.DTLong, tiff.DTSLong, tiff.DTFloat:
+		return 4
+	case tiff.DTRational, tiff.DTSRational, tiff.DTDouble:
+		return 8
+	}
+	return 0
+}
+
+// valuesFit reports whether the values of the IFD at note[start:] lie
+// within note with pad, without overlapping the entries of the IFD.
+func valuesFit(note []byte, start int, order binary.ByteOrder, pad int64) bool {
+	var offs, sizes []int64
+	end, ok := noteEntries(note, start, order, func(size, off int64) {
+		offs, sizes = append(offs, off-pad), append(sizes, size)
+	})
+	if !ok {
+		return false
+	}
+	entries := end - 4
+	for i, off := range offs {
+		if off < 0 || off+sizes[i] > int64(len(note)) || (off < entries && off+sizes[i] > int64(start)) {
+			return false
+		}
+	}
+	return true
+}
+
+// lowestValue returns the lowest value offset of the IFD at note[start:]
+// and the offset of the end of the IFD, including the offset to the next
+// IFD. ok is false if no value is stored outside of the entries.
+func lowestValue(note []byte, start int, order binary.ByteOrder) (min, end int64, ok bool) {
+	min = -1
+	end, valid := noteEntries(note, start, order, func(size, off int64) {
+		if min < 0 || off < min {
+			min = off
+		}
+	})
+	return min, end, valid && min >= 0
+}
+
+// decodeNoteDir decodes the IFD at note[start:], whose value offsets have
+// the given pad.
+func decodeNoteDir(note []byte, start int, pad int64, order binary.ByteOrder) (*tiff.Dir, error) {
+	d, _, err := tiff.DecodeDir(&noteReader{note: note, pos: int64(start), pad: pad}, order)
+	return d, err
+}
+
+// A noteReader reads the IFD of a maker note from the note bytes, at
+// offsets that have the pad of the note.
+type noteReader struct {
+	note []byte
+	pos  int64 // of the next byte read by Read in note
+	pad  int64
+}
+
+func (r *noteReader) Read(p []byte) (int, error) {
+	if r.pos >= int64(len(r.note)) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.note[r.pos:])
+	r.pos += int64(n)
+	return n, nil
+}
+
+func (r *noteReader) ReadAt(p []byte, off int64) (int, error) {
+	i := off - r.pad
+	if i < 0 {
+		return 0, errors.New("mknote: maker note value offset before the base of its offsets")
+	}
+	if i >= int64(len(r.note)) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.note[i:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Seek only reports the offset of the next byte read, for the entry offsets
+// of the tags.
+func (r *noteReader) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekCurrent {
+		return 0, errors.New("mknote: unsupported seek")
+	}
+	return r.pos + r.pad, nil
+}
+
+// Size returns the offset of the end of the note.
+func (r *noteReader) Size() int64 { return int64(len(r.note)) + r.pad }
@@ -0,0 +1,148 @@
+package mknote
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+type leica struct{}
+
+// Parse decodes all Leica makernote data found in x and adds it to x.
+func (_ *leica) Parse(x *exif.Exif) error {
+	m, err := x.Get(exif.MakerNote)
+	if err != nil || bytes.HasPrefix(m.Val, []byte("Panasonic\000")) {
+		return nil
+	}
+	if !bytes.HasPrefix(m.Val, []byte("LEICA")) && !isLeica(x) {
+		return nil
+	}
+	return loadLeicaNote(x, x, m)
+}
+
+type panasonic struct{}
+
+// Parse decodes all Panasonic makernote data found in x and adds it to x.
+// RW2 (and Leica RWL) files have no maker note of their own, so the maker
+// note of the JPEG preview embedded in them is used instead.
+func (_ *panasonic) Parse(x *exif.Exif) error {
+	m, err := x.Get(exif.MakerNote)
+	if err == nil {
+		if !bytes.HasPrefix(m.Val, []byte("Panasonic\000")) {
+			return nil
+		}
+		return loadLeicaNote(x, x, m)
+	}
+
+	// RW2 JpgFromRaw
+	jpg, err := x.GetTagByID(exif.Ifd0, 0x002e)
+	if err != nil || jpg.Type != tiff.DTUndefined {
+		return nil
+	}
+	y, err := exif.Decode(bytes.NewReader(jpg.Val))
+	if err != nil {
+		return err
+	}
+	m, err = y.Get(exif.MakerNote)
+	if err != nil {
+		return nil
+	}
+	// file offsets are relative to the start of the embedded JPEG
+	return loadLeicaNote(x, y, m)
+}
+
+// isLeica reports whether the Make of x is Leica's.
+func isLeica(x *exif.Exif) bool {
+	return strings.HasPrefix(strings.ToUpper(cameraMake(x)), "LEICA")
+}
+
+func cameraMake(x *exif.Exif) string {
+	tag, err := x.Get(exif.Make)
+	if err != nil {
+		return ""
+	}
+	s, _ := tag.StringVal()
+	return strings.TrimSpace(s)
+}
+
+// noteBase is what the offsets in a maker note are relative to.
+type noteBase int
+
+const (
+	baseTiff noteBase = iota // the tiff data holding the maker note
+	baseNote                 // the start of the maker note
+	baseFile                 // the start of the file
+)
+
+// leicaFormat identifies the format of the Leica or Panasonic maker note
+// note, written by a camera whose Make is camera, following ExifTool. It
+// returns the offset of the IFD in the note, what the offsets in it are
+// relative to and the fields of its tags. Nil fields means the tags are not
+// known and are loaded under their tag ID.
+func leicaFormat(note []byte, camera string) (start int, base noteBase, fields map[uint16]exif.FieldName, ok bool) {
+	has := func(prefix string) bool { return bytes.HasPrefix(note, []byte(prefix)) }
+	switch {
+	case has("Panasonic\000"):
+		return 12, baseTiff, makerNotePanasonicFields, true
+	case has("LEICA CAMERA AG\000"):
+		// D-Lux 7
+		return 18, baseNote, makerNotePanasonicFields, true
+	case has("LEICA\000\000\000"):
+		if camera == "LEICA" {
+			// Digilux and D-Lux cameras built by Panasonic
+			return 8, baseTiff, makerNotePanasonicFields, true
+		}
+		// M8
+		return 8, baseTiff, makerNoteLeicaM8Fields, true
+	case has("LEICA0"):
+		// M9 and M Monochrom
+		return 8, baseNote, nil, true
+	case has("LEICA\000\002\377"):
+		// M10 and S
+		return 8, baseNote, makerNoteLeicaFields, true
+	case has("LEICA\000\002\000"):
+		// S2 and M (Typ 240)
+		return 8, baseFile, makerNoteLeicaFields, true
+	case has("LEICA\000\010\000") && camera == "Leica Camera AG":
+		// M Monochrom (Typ 246)
+		return 8, baseFile, makerNoteLeicaFields, true
+	case has("LEICA\000") && len(note) >= 8 && note[7] == 0 && bytes.IndexByte([]byte("\001\004\005\006\007\010\011\013\020\032"), note[6]) >= 0:
+		// X, T, Q, SL, CL and S (Typ 007) families
+		return 8, baseNote, makerNoteLeicaFields, true
+	case !has("LEICA") && strings.HasPrefix(camera, "Leica Camera AG"):
+		// R8 and R9 digital backs: no header
+		return 0, baseTiff, nil, true
+	}
+	return 0, 0, nil, false
+}
+
+// loadLeicaNote loads the Leica or Panasonic maker note m found in y into x.
+func loadLeicaNote(x, y *exif.Exif, m *tiff.Tag) error {
+	start, base, fields, ok := leicaFormat(m.Val, cameraMake(y))
+	if !ok || len(m.Val) < start {
+		return nil
+	}
+
+	var pad int64
+	switch base {
+	case baseTiff:
+		pad = int64(m.ValOffset)
+	case baseFile:
+		off, ok := y.TiffOffset()
+		if !ok {
+			return errors.New("mknote: Leica maker note uses file offsets, but the offset of the EXIF data in the file is unknown")
+		}
+		pad = off + int64(m.ValOffset)
+	}
+	// the byte order of the note is not recorded
+	order := dirOrder(m.Val[start:], y.Tiff.Order)
+	mkNotesDir, err := decodeNoteDir(m.Val, start, fixBase(y, m, start, order, pad), order)
+	if err != nil {
+		return err
+	}
+	x.LoadTags(mkNotesDir, fields, fields == nil)
+	return nil
+}
@@ -0,0 +1,220 @@
+package mknote
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// The makers whose lens IDs LensID returns.
+const (
+	LensCanon  = "canon"
+	LensNikon  = "nikon"
+	LensPentax = "pentax"
+	LensSony   = "sony"
+)
+
+// A LensDB maps the lens IDs recorded in maker notes, as returned by LensID,
+// to lens names. It is safe for concurrent use.
+type LensDB struct {
+	mu     sync.RWMutex
+	lenses map[string]map[string]string
+}
+
+// NewLensDB returns an empty LensDB.
+func NewLensDB() *LensDB {
+	return &LensDB{lenses: map[string]map[string]string{}}
+}
+
+//go:embed lenses.txt
+var lensesTxt string
+
+// Lenses is the LensDB used by LensName. It initially holds the embedded
+// database, a selection of common lenses, which can be extended or
+// overridden with Add and Load.
+var Lenses = NewLensDB()
+
+func init() {
+	if err := Lenses.Load(strings.NewReader(lensesTxt)); err != nil {
+		panic(err)
+	}
+}
+
+// Add maps the lens ID id of the given maker to name, replacing any name
+// it was mapped to.
+func (db *LensDB) Add(maker, id, name string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	m := db.lenses[maker]
+	if m == nil {
+		m = map[string]string{}
+		db.lenses[maker] = m
+	}
+	m[normLensID(id)] = name
+}
+
+// Name returns the name the lens ID id of the given maker maps to.
+func (db *LensDB) Name(maker, id string) (name string, ok bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	name, ok = db.lenses[maker][normLensID(id)]
+	return name, ok
+}
+
+// Load adds the lenses read from r to db, replacing the names of lenses
+// already in db. Each line holds the maker (one of the Lens constants), the
+// lens ID and the lens name separated by tabs. Empty lines and lines
+// starting with '#' are ignored.
+func (db *LensDB) Load(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		f := strings.SplitN(line, "\t", 3)
+		if len(f) != 3 || f[0] == "" || f[1] == "" {
+			return fmt.Errorf("mknote: lens database line %d: want maker, lens ID and name separated by tabs", n)
+		}
+		db.Add(f[0], f[1], strings.TrimSpace(f[2]))
+	}
+	return s.Err()
+}
+
+// normLensID normalizes the spacing and case of a lens ID.
+func normLensID(id string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(id), " "))
+}
+
+// LensID returns the lens ID recorded in the maker note of x, as used by the
+// lens databases of ExifTool and exiv2: for Canon cameras the LensType of the
+// camera settings as a decimal number, for Nikon cameras the composite
+// LensID built from the lens data in eight hex bytes (e.g. "01 58 50 50 14
+// 14 02 00"), for Pentax cameras the series and model numbers returned by
+// PentaxLensType (e.g. "3 25") and for Sony cameras the LensType. The lens
+// data of newer Nikon cameras is encrypted, and not supported.
+func LensID(x *exif.Exif) (maker, id string, err error) {
+	if tag, err := x.Get(Canon_CameraSettings); err == nil {
+		v, err := tag.Int(22)
+		if err != nil {
+			return "", "", err
+		}
+		return LensCanon, strconv.Itoa(v), nil
+	}
+	if tag, err := x.Get(Nikon_LensData); err == nil {
+		id, err := nikonLensID(x, tag.Val)
+		return LensNikon, id, err
+	}
+	if series, model, err := PentaxLensType(x); err == nil {
+		return LensPentax, fmt.Sprintf("%d %d", series, model), nil
+	}
+	if cameraMakeIs(x, "SONY") {
+		if _, err := x.Get(LensType); err == nil {
+			v, err := x.GetInt64(LensType)
+			if err != nil {
+				return "", "", err
+			}
+			return LensSony, strconv.FormatInt(v, 10), nil
+		}
+	}
+	return "", "", errors.New("mknote: no known lens ID in maker note")
+}
+
+// nikonLensID returns the composite LensID of the Nikon lens data data.
+func nikonLensID(x *exif.Exif, data []byte) (string, error) {
+	if len(data) < 4 {
+		return "", errors.New("mknote: Nikon lens data too short")
+	}
+	var off int
+	switch version := string(data[:4]); version {
+	case "0100":
+		off = 0x06
+	case "0101":
+		off = 0x0b
+	default:
+		return "", fmt.Errorf("mknote: Nikon lens data version %q is encrypted or unknown", version)
+	}
+	if len(data) < off+7 {
+		return "", errors.New("mknote: Nikon lens data too short")
+	}
+	t, err := x.GetInt64(LensType)
+	if err != nil {
+		return "", err
+	}
+	// LensIDNumber, LensFStops, MinFocalLength, MaxFocalLength,
+	// MaxApertureAtMinFocal, MaxApertureAtMaxFocal, MCUVersion
+	b := append(append([]byte(nil), data[off:off+7]...), byte(t))
+	return fmt.Sprintf("% X", b), nil
+}
+
+// LensName returns the name of the lens recorded in the maker note of x,
+// looking up the ID returned by LensID in Lenses. For Sony cameras whose lens
+// is not in Lenses (which includes E-mount lenses, all recorded with
+// LensType 65535), the focal length and aperture range of the lens
+// specification is returned, e.g. "18-55mm F3.5-5.6".
+func LensName(x *exif.Exif) (string, error) {
+	maker, id, err := LensID(x)
+	if err != nil {
+		return "", err
+	}
+	if name, ok := Lenses.Name(maker, id); ok && !(maker == LensSony && id == "65535") {
+		return name, nil
+	}
+	if maker == LensSony {
+		if tag, err := x.Get(Sony_LensSpec); err == nil {
+			if spec, ok := sonyLensSpec(tag.Val); ok {
+				return spec, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("mknote: unknown %s lens %s", maker, id)
+}
+
+// sonyLensSpec describes the focal length and aperture range of a Sony lens
+// specification: a flags byte, the short and long focal lengths as four BCD
+// digits each, the apertures at those focal lengths as two BCD digits each
+// (times 10) and another flags byte.
+func sonyLensSpec(v []byte) (string, bool) {
+	if len(v) != 8 {
+		return "", false
+	}
+	bcd := func(b ...byte) (int, bool) {
+		n := 0
+		for _, c := range b {
+			if c>>4 > 9 || c&0x0f > 9 {
+				return 0, false
+			}
+			n = n*100 + int(c>>4)*10 + int(c&0x0f)
+		}
+		return n, true
+	}
+	short, ok1 := bcd(v[1], v[2])
+	long, ok2 := bcd(v[3], v[4])
+	ap1, ok3 := bcd(v[5])
+	ap2, ok4 := bcd(v[6])
+	if !ok1 || !ok2 || !ok3 || !ok4 || short == 0 || ap1 == 0 {
+		return "", false
+	}
+	focal := fmt.Sprintf("%dmm", short)
+	if long != 0 && long != short {
+		focal = fmt.Sprintf("%d-%dmm", short, long)
+	}
+	ap := "F" + strconv.FormatFloat(float64(ap1)/10, 'f', -1, 64)
+	if ap2 != 0 && ap2 != ap1 {
+		ap += "-" + strconv.FormatFloat(float64(ap2)/10, 'f', -1, 64)
+	}
+	return focal + " " + ap, true
+}
+
+// cameraMakeIs reports whether the Make of x starts with prefix, ignoring
+// case.
+func cameraMakeIs(x *exif.Exif, prefix string) bool {
+	return strings.HasPrefix(strings.ToUpper(cameraMake(x)), prefix)
+}
@@ -3,6 +3,8 @@ package mknote
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
 
 	"github.com/rwcarlsen/goexif/exif"
 	"github.com/rwcarlsen/goexif/tiff"
@@ -13,8 +15,23 @@ var (
 	Canon = &canon{}
 	// NikonV3 is an exif.Parser for nikon makernote data.
 	NikonV3 = &nikonV3{}
+	// Pentax is an exif.Parser for pentax and ricoh makernote data.
+	Pentax = &pentax{}
+	// Leica is an exif.Parser for leica makernote data.
+	Leica = &leica{}
+	// Panasonic is an exif.Parser for panasonic makernote data, including
+	// the makernote of the JPEG preview embedded in RW2 files.
+	Panasonic = &panasonic{}
+	// Hasselblad is an exif.Parser for hasselblad makernote data.
+	Hasselblad = &hasselblad{}
+	// PhaseOne is an exif.Parser for phase one makernote data.
+	PhaseOne = &phaseOne{}
+	// Sony is an exif.Parser for sony makernote data.
+	Sony = &sony{}
+	// Apple is an exif.Parser for apple makernote data.
+	Apple = &apple{}
 	// All is a list of all available makernote parsers
-	All = []exif.Parser{Canon, NikonV3}
+	All = []exif.Parser{Canon, NikonV3, Pentax, Leica, Panasonic, Hasselblad, PhaseOne, Sony, Apple}
 )
 
 type canon struct{}
@@ -35,12 +52,10 @@ func (_ *canon) Parse(x *exif.Exif) error {
 		return nil
 	}
 
-	// Canon notes are a single IFD directory with no header.
-	// Reader offsets need to be w.r.t. the original tiff structure.
-	buf := bytes.NewReader(append(make([]byte, m.ValOffset), m.Val...))
-	buf.Seek(int64(m.ValOffset), 0)
-
-	mkNotesDir, _, err := tiff.DecodeDir(buf, x.Tiff.Order)
+	// Canon notes are a single IFD directory with no header, whose
+	// offsets are relative to the original tiff structure.
+	order := dirOrder(m.Val, x.Tiff.Order)
+	mkNotesDir, err := decodeNoteDir(m.Val, 0, fixBase(x, m, 0, order, int64(m.ValOffset)), order)
 	if err != nil {
 		return err
 	}
@@ -48,6 +63,35 @@ func (_ *canon) Parse(x *exif.Exif) error {
 	return nil
 }
 
+type hasselblad struct{}
+
+// Parse decodes all Hasselblad makernote data found in x and adds it to x.
+func (_ *hasselblad) Parse(x *exif.Exif) error {
+	m, err := x.Get(exif.MakerNote)
+	if err != nil {
+		return nil
+	}
+
+	mk, err := x.Get(exif.Make)
+	if err != nil {
+		return nil
+	}
+
+	if val, err := mk.StringVal(); err != nil || val != "Hasselblad" {
+		return nil
+	}
+
+	// Hasselblad notes are a single IFD directory with no header, whose
+	// offsets are relative to the original tiff structure.
+	order := dirOrder(m.Val, x.Tiff.Order)
+	mkNotesDir, err := decodeNoteDir(m.Val, 0, fixBase(x, m, 0, order, int64(m.ValOffset)), order)
+	if err != nil {
+		return err
+	}
+	x.LoadTags(mkNotesDir, makerNoteHasselbladFields, false)
+	return nil
+}
+
 type nikonV3 struct{}
 
 // Parse decodes all Nikon makernote data found in x and adds it to x.
@@ -55,7 +99,7 @@ func (_ *nikonV3) Parse(x *exif.Exif) error {
 	m, err := x.Get(exif.MakerNote)
 	if err != nil {
 		return nil
-	} else if bytes.Compare(m.Val[:6], []byte("Nikon\000")) != 0 {
+	} else if len(m.Val) < 10 || bytes.Compare(m.Val[:6], []byte("Nikon\000")) != 0 {
 		return nil
 	}
 
@@ -65,6 +109,92 @@ func (_ *nikonV3) Parse(x *exif.Exif) error {
 	if err != nil {
 		return err
 	}
+	if len(mkNotes.Dirs) == 0 {
+		return errors.New("mknote: Nikon maker note has no IFD")
+	}
 	x.LoadTags(mkNotes.Dirs[0], makerNoteNikon3Fields, false)
 	return nil
 }
+
+type pentax struct{}
+
+// Parse decodes all Pentax makernote data found in x and adds it to x. Notes
+// starting with "AOC\0" (most Pentax cameras), "PENTAX \0" (newer models and
+// DNG files) and "RICOH\0" followed by a byte order (Ricoh cameras since the
+// GR III) are supported.
+func (_ *pentax) Parse(x *exif.Exif) error {
+	m, err := x.Get(exif.MakerNote)
+	if err != nil {
+		return nil
+	}
+
+	// hdr is the length of the header preceding the IFD, whose last two
+	// bytes give the byte order of the note
+	var hdr int
+	var base int64
+	switch v := m.Val; {
+	case bytes.HasPrefix(v, []byte("AOC\000")):
+		// offsets are relative to the original tiff structure
+		hdr, base = 6, int64(m.ValOffset)
+	case bytes.HasPrefix(v, []byte("PENTAX \000")):
+		// offsets are relative to the start of the maker note
+		hdr = 10
+	case bytes.HasPrefix(v, []byte("RICOH\000II")), bytes.HasPrefix(v, []byte("RICOH\000MM")):
+		// older Ricoh notes use other tags
+		hdr = 8
+	default:
+		return nil
+	}
+	if len(m.Val) < hdr {
+		return nil
+	}
+
+	var order binary.ByteOrder
+	switch string(m.Val[hdr-2 : hdr]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		// old cameras leave the byte order blank
+		order = dirOrder(m.Val[hdr:], x.Tiff.Order)
+	}
+
+	mkNotesDir, err := decodeNoteDir(m.Val, hdr, fixBase(x, m, hdr, order, base), order)
+	if err != nil {
+		return err
+	}
+	x.LoadTags(mkNotesDir, makerNotePentaxFields, false)
+	return nil
+}
+
+// dirOrder guesses the byte order of the header-less IFD at the start of a
+// maker note. Some cameras write maker notes in a different byte order than
+// the enclosing tiff structure (e.g. little-endian notes in big-endian
+// files), so the entry count and the type of the first entry are checked for
+// plausibility in the host order first and then in the opposite order. If
+// neither is plausible, host is returned.
+func dirOrder(note []byte, host binary.ByteOrder) binary.ByteOrder {
+	other := binary.ByteOrder(binary.LittleEndian)
+	if host == binary.LittleEndian {
+		other = binary.BigEndian
+	}
+	for _, order := range []binary.ByteOrder{host, other} {
+		if plausibleDir(note, order) {
+			return order
+		}
+	}
+	return host
+}
+
+func plausibleDir(note []byte, order binary.ByteOrder) bool {
+	if len(note) < 2+12 {
+		return false
+	}
+	n := int(order.Uint16(note))
+	if n == 0 || 2+12*n > len(note) {
+		return false
+	}
+	typ := tiff.DataType(order.Uint16(note[4:]))
+	return typ >= tiff.DTByte && typ <= tiff.DTDouble
+}
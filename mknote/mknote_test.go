@@ -0,0 +1,621 @@
+package mknote
+
+//go:generate go run ../exif/gen_fields.go -o fields.go fields.json
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+func TestDirOrder(t *testing.T) {
+	// A single entry IFD: tag 0x0001, type short, count 1, value 2.
+	little := "0100 0100 0300 01000000 02000000 00000000"
+	big := "0001 0001 0003 00000001 00020000 00000000"
+	// Two entries in little-endian order: its count reads as 512 in
+	// big-endian order, which runs off the end of the note.
+	little2 := "0200 0100 0300 01000000 02000000 0200 0300 01000000 02000000 00000000"
+
+	for _, tt := range []struct {
+		note string
+		host binary.ByteOrder
+		want binary.ByteOrder
+	}{
+		{little, binary.LittleEndian, binary.LittleEndian},
+		{little2, binary.BigEndian, binary.LittleEndian},
+		{big, binary.BigEndian, binary.BigEndian},
+		{big, binary.LittleEndian, binary.BigEndian},
+		// implausible in both orders: keep the host order
+		{"0000 0000 0000 00000000 00000000", binary.BigEndian, binary.BigEndian},
+	} {
+		note, err := hex.DecodeString(strings.Replace(tt.note, " ", "", -1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := dirOrder(note, tt.host); got != tt.want {
+			t.Errorf("dirOrder(%v, %v) = %v, want %v", tt.note, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestPentax(t *testing.T) {
+	exif.RegisterParsers(Pentax)
+
+	tag := func(id uint16, typ tiff.DataType, val interface{}) *tiff.Tag {
+		tg, err := tiff.NewTag(id, typ, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tg
+	}
+	encode := func(order binary.ByteOrder, d *tiff.Dir) []byte {
+		var buf bytes.Buffer
+		if err := tiff.NewTiff(order, d).Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	// values fit in the IFD entries, so notes whose offsets are relative
+	// to other data can be built from the IFD alone
+	inline := tiff.NewDir(
+		tag(0x0034, tiff.DTByte, []byte{1, 2, 0, 0}),
+		tag(0x003f, tiff.DTByte, []byte{3, 25}),
+		tag(0x005c, tiff.DTByte, []byte{0, 1, 0, 0}),
+	)
+	lensInfo := make([]byte, 128)
+	lensInfo[1], lensInfo[5] = 0x18, 23
+	outside := tiff.NewDir(
+		tag(0x0034, tiff.DTByte, []byte{0, 0, 0, 0}),
+		tag(0x005c, tiff.DTByte, []byte{0, 0, 0, 0}),
+		tag(0x0207, tiff.DTUndefined, lensInfo),
+	)
+
+	for _, tt := range []struct {
+		note          []byte
+		drive         string
+		series, model int
+		sr            bool
+	}{
+		{append([]byte("AOC\000II"), encode(binary.LittleEndian, inline)[8:]...), "Continuous; Self-timer (2 s); Shutter Button; Single Exposure", 3, 25, true},
+		{append([]byte("AOC\000  "), encode(binary.BigEndian, inline)[8:]...), "Continuous; Self-timer (2 s); Shutter Button; Single Exposure", 3, 25, true},
+		{append([]byte("PENTAX \000MM"), encode(binary.BigEndian, inline)[8:]...), "Continuous; Self-timer (2 s); Shutter Button; Single Exposure", 3, 25, true},
+		// offsets are relative to the start of the note, whose header has
+		// the size of a tiff header
+		{append([]byte("RICOH\000II"), encode(binary.LittleEndian, outside)[8:]...), "Single-frame; No Timer; Shutter Button; Single Exposure", 8, 23, false},
+	} {
+		ifd0 := tiff.NewDir(tag(0x010F, tiff.DTAscii, "PENTAX"), tag(0x0110, tiff.DTAscii, "PENTAX K-3"))
+		ifd0.SubDirs = map[uint16]*tiff.Dir{0x8769: tiff.NewDir(tag(0x927C, tiff.DTUndefined, tt.note))}
+		x, err := exif.Decode(bytes.NewReader(encode(binary.LittleEndian, ifd0)))
+		if err != nil {
+			t.Fatalf("%q: %v", tt.note[:8], err)
+		}
+		if got, err := PentaxDriveMode(x); err != nil || got != tt.drive {
+			t.Errorf("%q: PentaxDriveMode = %q, %v, want %q", tt.note[:8], got, err, tt.drive)
+		}
+		if series, model, err := PentaxLensType(x); err != nil || series != tt.series || model != tt.model {
+			t.Errorf("%q: PentaxLensType = %d, %d, %v, want %d, %d", tt.note[:8], series, model, err, tt.series, tt.model)
+		}
+		if on, err := PentaxShakeReduction(x); err != nil || on != tt.sr {
+			t.Errorf("%q: PentaxShakeReduction = %v, %v, want %v", tt.note[:8], on, err, tt.sr)
+		}
+	}
+}
+
+func TestLeicaPanasonic(t *testing.T) {
+	exif.RegisterParsers(Leica, Panasonic)
+
+	tag := func(id uint16, typ tiff.DataType, val interface{}) *tiff.Tag {
+		tg, err := tiff.NewTag(id, typ, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tg
+	}
+	encode := func(order binary.ByteOrder, d *tiff.Dir) []byte {
+		var buf bytes.Buffer
+		if err := tiff.NewTiff(order, d).Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	withNote := func(make string, note []byte) []byte {
+		ifd0 := tiff.NewDir(tag(0x010F, tiff.DTAscii, make))
+		ifd0.SubDirs = map[uint16]*tiff.Dir{0x8769: tiff.NewDir(tag(0x927C, tiff.DTUndefined, note))}
+		return encode(binary.BigEndian, ifd0)
+	}
+
+	// X1 notes have a header the size of a tiff header, and offsets
+	// relative to the start of the note
+	lens := "Elmarit 1:2.8/24 ASPH."
+	x1 := append([]byte("LEICA\x00\x01\x00"), encode(binary.LittleEndian, tiff.NewDir(tag(0x0303, tiff.DTAscii, lens)))[8:]...)
+	x, err := exif.Decode(bytes.NewReader(withNote("LEICA CAMERA AG", x1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := x.Get(LensType); err != nil || got.String() != `"`+lens+`"` {
+		t.Errorf("X1 LensType = %v, %v", got, err)
+	}
+
+	// RW2 files hold the maker note in the EXIF data of an embedded JPEG
+	note := append([]byte("Panasonic\x00\x00\x00"), encode(binary.LittleEndian, tiff.NewDir(
+		tag(0x0002, tiff.DTUndefined, []byte("0131")),
+		tag(0x001f, tiff.DTShort, 6),
+	))[8:]...)
+	app1 := append([]byte("Exif\x00\x00"), withNote("Panasonic", note)...)
+	jpg := append([]byte{0xFF, 0xD8, 0xFF, 0xE1, byte((len(app1) + 2) >> 8), byte(len(app1) + 2)}, app1...)
+	jpg = append(jpg, 0xFF, 0xD9)
+	rw2 := encode(binary.LittleEndian, tiff.NewDir(
+		tag(0x002e, tiff.DTUndefined, jpg),
+		tag(0x010F, tiff.DTAscii, "Panasonic"),
+	))
+	rw2[2] = 0x55
+	x, err = exif.Decode(bytes.NewReader(rw2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := x.Get(FirmwareVersion); err != nil || string(got.Val) != "0131" {
+		t.Errorf("RW2 FirmwareVersion = %v, %v", got, err)
+	}
+	if got, err := x.Get(Panasonic_ShootingMode); err != nil || got.String() != "6" {
+		t.Errorf("RW2 ShootingMode = %v, %v", got, err)
+	}
+}
+
+func TestHasselbladPhaseOne(t *testing.T) {
+	exif.RegisterParsers(Hasselblad, PhaseOne)
+
+	tag := func(id uint16, typ tiff.DataType, val interface{}) *tiff.Tag {
+		tg, err := tiff.NewTag(id, typ, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tg
+	}
+	decode := func(make string, note []byte) *exif.Exif {
+		ifd0 := tiff.NewDir(tag(0x010F, tiff.DTAscii, make))
+		ifd0.SubDirs = map[uint16]*tiff.Dir{0x8769: tiff.NewDir(tag(0x927C, tiff.DTUndefined, note))}
+		var buf bytes.Buffer
+		if err := tiff.NewTiff(binary.LittleEndian, ifd0).Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		x, err := exif.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return x
+	}
+
+	var note bytes.Buffer
+	if err := tiff.NewTiff(binary.LittleEndian, tiff.NewDir(tag(0x0011, tiff.DTShort, 9), tag(0x0016, tiff.DTShort, 4))).Encode(&note); err != nil {
+		t.Fatal(err)
+	}
+	x := decode("Hasselblad", note.Bytes()[8:])
+	if got, err := x.Get(Hasselblad_CoatingCode); err != nil || got.String() != "4" {
+		t.Errorf("Hasselblad CoatingCode = %v, %v", got, err)
+	}
+
+	// Phase One directory: a string and inline float and integer values
+	le := binary.LittleEndian
+	p1 := []byte("IIII\x00waR\x00\x00\x00\x00")
+	model := []byte("IQ4 150MP\x00")
+	modelOff := len(p1)
+	p1 = append(p1, model...)
+	le.PutUint32(p1[8:], uint32(len(p1)))
+	p1 = le.AppendUint32(le.AppendUint32(p1, 3), 0)
+	for _, e := range [][4]uint32{
+		{0x0410, 1, uint32(len(model)), uint32(modelOff)},
+		{0x0403, 4, 4, math.Float32bits(80)},
+		{0x0105, 4, 4, 100},
+	} {
+		for _, v := range e {
+			p1 = le.AppendUint32(p1, v)
+		}
+	}
+	x = decode("Phase One", p1)
+	if got, err := x.Get(PhaseOne_CameraModel); err != nil || got.String() != `"IQ4 150MP"` {
+		t.Errorf("Phase One CameraModel = %v, %v", got, err)
+	}
+	if got, err := x.Get(PhaseOne_FocalLength); err != nil || got.String() != "80" {
+		t.Errorf("Phase One FocalLength = %v, %v", got, err)
+	}
+	if got, err := x.Get(PhaseOne_ISO); err != nil || got.String() != "100" {
+		t.Errorf("Phase One ISO = %v, %v", got, err)
+	}
+	if _, err := decodePhaseOne(p1[:len(p1)-1]); err == nil {
+		t.Error("no error decoding truncated Phase One directory")
+	}
+}
+
+func TestLens(t *testing.T) {
+	exif.RegisterParsers(Canon, NikonV3, Sony)
+
+	tag := func(id uint16, typ tiff.DataType, val interface{}) *tiff.Tag {
+		tg, err := tiff.NewTag(id, typ, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tg
+	}
+	// decode decodes a file holding the maker note built by note, which is
+	// given the offset of the note in the tiff data.
+	decode := func(make string, note func(base uint32) []byte) *exif.Exif {
+		encode := func(n []byte) []byte {
+			ifd0 := tiff.NewDir(tag(0x010F, tiff.DTAscii, make))
+			ifd0.SubDirs = map[uint16]*tiff.Dir{0x8769: tiff.NewDir(tag(0x927C, tiff.DTUndefined, n))}
+			var buf bytes.Buffer
+			if err := tiff.NewTiff(binary.LittleEndian, ifd0).Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+			return buf.Bytes()
+		}
+		x, err := (&exif.Decoder{Lenient: true}).Decode(bytes.NewReader(encode(note(0))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := x.Get(exif.MakerNote)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x, err = exif.Decode(bytes.NewReader(encode(note(m.ValOffset)))); err != nil {
+			t.Fatal(err)
+		}
+		return x
+	}
+	le := binary.LittleEndian
+	// ifd returns a single entry IFD whose value follows it.
+	ifd := func(base uint32, hdr string, id, typ uint16, count uint32, val []byte) []byte {
+		n := []byte(hdr)
+		n = le.AppendUint16(n, 1)
+		n = le.AppendUint16(n, id)
+		n = le.AppendUint16(n, typ)
+		n = le.AppendUint32(n, count)
+		n = le.AppendUint32(n, base+uint32(len(n))+8)
+		n = le.AppendUint32(n, 0)
+		return append(n, val...)
+	}
+
+	settings := make([]byte, 2*25)
+	le.PutUint16(settings[2*22:], 4144)
+	canon := decode("Canon", func(base uint32) []byte { return ifd(base, "", 0x0001, 3, 25, settings) })
+
+	lensData := []byte("0100\x00\x00\x01\x58\x50\x50\x14\x14\x02")
+	var note bytes.Buffer
+	if err := tiff.NewTiff(binary.BigEndian, tiff.NewDir(tag(0x0083, tiff.DTByte, []byte{0}), tag(0x0098, tiff.DTUndefined, lensData))).Encode(&note); err != nil {
+		t.Fatal(err)
+	}
+	nikon := decode("NIKON CORPORATION", func(uint32) []byte { return append([]byte("Nikon\x00\x02\x10\x00\x00"), note.Bytes()...) })
+
+	// the E-mount LensType, whose lens spec is used instead
+	spec := []byte{0, 0x00, 0x18, 0x00, 0x55, 0x35, 0x56, 0}
+	sony := decode("SONY", func(base uint32) []byte {
+		n := ifd(base, "SONY DSC \x00\x00\x00", 0xb02a, 7, 8, spec)
+		// add the inline LensType entry
+		le.PutUint16(n[12:], 2)
+		e := le.AppendUint32(le.AppendUint32(le.AppendUint16(le.AppendUint16(nil, 0xb027), 4), 1), 65535)
+		n = append(n[:26], append(e, n[26:]...)...)
+		le.PutUint32(n[22:], base+uint32(len(n))-8)
+		return n
+	})
+
+	for _, tt := range []struct {
+		x               *exif.Exif
+		maker, id, lens string
+	}{
+		{canon, LensCanon, "4144", "Canon EF 40mm f/2.8 STM"},
+		{nikon, LensNikon, "01 58 50 50 14 14 02 00", "AF Nikkor 50mm f/1.8"},
+		{sony, LensSony, "65535", "18-55mm F3.5-5.6"},
+	} {
+		maker, id, err := LensID(tt.x)
+		if err != nil || maker != tt.maker || id != tt.id {
+			t.Errorf("LensID = %q, %q, %v, want %q, %q", maker, id, err, tt.maker, tt.id)
+		}
+		if got, err := LensName(tt.x); err != nil || got != tt.lens {
+			t.Errorf("%v: LensName = %q, %v, want %q", tt.maker, got, err, tt.lens)
+		}
+	}
+
+	db := NewLensDB()
+	if err := db.Load(strings.NewReader("# custom lenses\n\ncanon\t4144\tMy 40mm\nnikon\t01 58 50 50 14 14 02 00\tMy 50mm\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := db.Name(LensNikon, "01 58 50 50 14 14 02 00"); !ok || got != "My 50mm" {
+		t.Errorf("Name = %q, %v, want My 50mm", got, ok)
+	}
+	if _, ok := db.Name(LensCanon, "1"); ok {
+		t.Error("new LensDB holds the embedded lenses")
+	}
+	if err := db.Load(strings.NewReader("canon 1 no tabs\n")); err == nil {
+		t.Error("no error loading a malformed lens database")
+	}
+	Lenses.Add(LensCanon, "4144", "Overridden")
+	defer Lenses.Add(LensCanon, "4144", "Canon EF 40mm f/2.8 STM")
+	if got, err := LensName(canon); err != nil || got != "Overridden" {
+		t.Errorf("LensName = %q, %v, want Overridden", got, err)
+	}
+}
+
+func TestShutterActuations(t *testing.T) {
+	exif.RegisterParsers(NikonV3, Pentax, Sony)
+
+	tag := func(id uint16, typ tiff.DataType, val interface{}) *tiff.Tag {
+		tg, err := tiff.NewTag(id, typ, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tg
+	}
+	encode := func(order binary.ByteOrder, d *tiff.Dir) []byte {
+		var buf bytes.Buffer
+		if err := tiff.NewTiff(order, d).Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	// the Pentax count is encrypted with the date and time
+	date, tm := []byte{0x07, 0xe4, 0x01, 0x02}, []byte{0x0a, 0x0b, 0x0c}
+	count := binary.LittleEndian.AppendUint32(nil, 1234)
+	for i := range count {
+		b := byte(0)
+		if i < 3 {
+			b = tm[i]
+		}
+		count[i] ^= date[i] ^ ^b
+	}
+	pentax := tiff.NewDir(
+		tag(0x0006, tiff.DTUndefined, date),
+		tag(0x0007, tiff.DTUndefined, tm),
+		tag(0x005d, tiff.DTUndefined, count),
+	)
+
+	for _, tt := range []struct {
+		make string
+		note []byte
+		want int
+	}{
+		{"NIKON CORPORATION", append([]byte("Nikon\x00\x02\x10\x00\x00"), encode(binary.BigEndian, tiff.NewDir(tag(0x00a7, tiff.DTLong, 98765)))...), 98765},
+		{"SONY", append([]byte("SONY DSC \x00\x00\x00"), encode(binary.LittleEndian, tiff.NewDir(tag(0x0001, tiff.DTLong, 4321)))[8:]...), 4321},
+		{"PENTAX", append([]byte("AOC\x00II"), encode(binary.LittleEndian, pentax)[8:]...), 1234},
+	} {
+		ifd0 := tiff.NewDir(tag(0x010F, tiff.DTAscii, tt.make))
+		ifd0.SubDirs = map[uint16]*tiff.Dir{0x8769: tiff.NewDir(tag(0x927C, tiff.DTUndefined, tt.note))}
+		x, err := exif.Decode(bytes.NewReader(encode(binary.LittleEndian, ifd0)))
+		if err != nil {
+			t.Fatalf("%v: %v", tt.make, err)
+		}
+		if got, err := ShutterActuations(x); err != nil || got != tt.want {
+			t.Errorf("%v: ShutterActuations = %d, %v, want %d", tt.make, got, err, tt.want)
+		}
+	}
+}
+
+func TestFixBase(t *testing.T) {
+	exif.RegisterParsers(Canon)
+
+	model, err := tiff.NewTag(0x010F, tiff.DTAscii, "Canon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// encode returns the tiff data of an image whose maker note, at offset
+	// noteOff, is built by note from noteOff.
+	encode := func(note func(noteOff int64) []byte) []byte {
+		var buf bytes.Buffer
+		for noteOff := int64(0); ; {
+			mk, err := tiff.NewTag(0x927C, tiff.DTUndefined, note(noteOff))
+			if err != nil {
+				t.Fatal(err)
+			}
+			ifd0 := tiff.NewDir(model)
+			ifd0.SubDirs = map[uint16]*tiff.Dir{0x8769: tiff.NewDir(mk)}
+			buf.Reset()
+			if err := tiff.NewTiff(binary.LittleEndian, ifd0).Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+			x, err := exif.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := x.Get(exif.MakerNote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if int64(m.ValOffset) == noteOff {
+				return buf.Bytes()
+			}
+			noteOff = int64(m.ValOffset)
+		}
+	}
+	// a Canon note holding ImageType, whose value follows the IFD at
+	// offset base+18
+	const imageType = "Canon EOS R5\x00"
+	canonNote := func(base int64) []byte {
+		le := binary.LittleEndian
+		note := le.AppendUint16(nil, 1)
+		note = le.AppendUint16(le.AppendUint16(note, 0x0006), uint16(tiff.DTAscii))
+		note = le.AppendUint32(le.AppendUint32(note, uint32(len(imageType))), uint32(base+18))
+		note = le.AppendUint32(note, 0)
+		return append(note, imageType...)
+	}
+
+	for name, note := range map[string]func(int64) []byte{
+		"tiff header": func(noteOff int64) []byte { return canonNote(noteOff) },
+		"note":        func(int64) []byte { return canonNote(0) },
+		"moved note":  func(noteOff int64) []byte { return canonNote(noteOff + 100) },
+		"shifted":     func(int64) []byte { return canonNote(-6) },
+	} {
+		x, err := exif.Decode(bytes.NewReader(encode(note)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := x.Get(ImageType); err != nil || got.String() != `"Canon EOS R5"` {
+			t.Errorf("%v: ImageType = %v, %v", name, got, err)
+		}
+	}
+}
+
+// appleNote returns an Apple maker note holding the given ASCII tags, in
+// big-endian order with offsets relative to the start of the note.
+func appleNote(tags map[uint16]string) []byte {
+	be := binary.BigEndian
+	var ids []int
+	for id := range tags {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	note := append([]byte("Apple iOS\x00\x00\x01MM"), 0, byte(len(ids)))
+	off := len(note) + 12*len(ids) + 4
+	var vals []byte
+	for _, id := range ids {
+		v := tags[uint16(id)] + "\x00"
+		note = be.AppendUint16(be.AppendUint16(note, uint16(id)), uint16(tiff.DTAscii))
+		note = be.AppendUint32(be.AppendUint32(note, uint32(len(v))), uint32(off+len(vals)))
+		vals = append(vals, v...)
+	}
+	return append(be.AppendUint32(note, 0), vals...)
+}
+
+func TestApple(t *testing.T) {
+	exif.RegisterParsers(Apple)
+
+	const id = "5A3C0B5D-2C56-4C8E-9B6E-3F9E2D6A1B7C"
+	mk, err := tiff.NewTag(0x927C, tiff.DTUndefined, appleNote(map[uint16]string{0x000B: "burst-1", 0x0011: id}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, err := tiff.NewTag(0x010F, tiff.DTAscii, "Apple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ifd0 := tiff.NewDir(model)
+	ifd0.SubDirs = map[uint16]*tiff.Dir{0x8769: tiff.NewDir(mk)}
+	var buf bytes.Buffer
+	if err := tiff.NewTiff(binary.LittleEndian, ifd0).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	x, err := exif.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[exif.FieldName]string{Apple_ContentIdentifier: id, Apple_BurstUUID: "burst-1"} {
+		if tag, err := x.Get(name); err != nil {
+			t.Errorf("%v: %v", name, err)
+		} else if got, _ := tag.StringVal(); got != want {
+			t.Errorf("%v = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestWhiteBalance(t *testing.T) {
+	exif.RegisterParsers(NikonV3, Pentax)
+
+	tag := func(id uint16, typ tiff.DataType, val interface{}) *tiff.Tag {
+		tg, err := tiff.NewTag(id, typ, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tg
+	}
+	encode := func(order binary.ByteOrder, d *tiff.Dir) []byte {
+		var buf bytes.Buffer
+		if err := tiff.NewTiff(order, d).Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	decode := func(make string, note []byte, tags ...*tiff.Tag) *exif.Exif {
+		ifd0 := tiff.NewDir(tag(0x010F, tiff.DTAscii, make))
+		tags = append(tags, tag(0x927C, tiff.DTUndefined, note))
+		ifd0.SubDirs = map[uint16]*tiff.Dir{0x8769: tiff.NewDir(tags...)}
+		x, err := exif.Decode(bytes.NewReader(encode(binary.LittleEndian, ifd0)))
+		if err != nil {
+			t.Fatalf("%v: %v", make, err)
+		}
+		return x
+	}
+
+	nikon := tiff.NewDir(
+		tag(0x0005, tiff.DTAscii, "SUNNY       "),
+		tag(0x000B, tiff.DTSShort, []int{-2, 1}),
+		tag(0x000C, tiff.DTRational, [][2]int64{{2, 1}, {3, 2}, {1, 1}, {1, 1}}),
+	)
+	x := decode("NIKON CORPORATION", append([]byte("Nikon\x00\x02\x10\x00\x00"), encode(binary.BigEndian, nikon)...),
+		tag(0x9208, tiff.DTShort, 1), tag(0xA403, tiff.DTShort, 0))
+	wb, err := WhiteBalance(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &WhiteBalanceInfo{
+		LightSource: exif.LightSourceDaylight,
+		Mode:        "SUNNY",
+		FineTune:    []int{-2, 1},
+		Levels:      []float64{2, 1, 1.5},
+	}
+	if !reflect.DeepEqual(wb, want) {
+		t.Errorf("Nikon WhiteBalance = %+v, want %+v", wb, want)
+	}
+
+	pentax := encode(binary.LittleEndian, tiff.NewDir(tag(0x0019, tiff.DTShort, 17)))[8:]
+	x = decode("PENTAX", append([]byte("AOC\x00II"), pentax...), tag(0xA403, tiff.DTShort, 1))
+	if wb, err := WhiteBalance(x); err != nil || !wb.Manual || wb.Mode != "Kelvin" {
+		t.Errorf("Pentax WhiteBalance = %+v, %v, want manual Kelvin", wb, err)
+	}
+
+	x = decode("Unknown", []byte("none"))
+	if _, err := WhiteBalance(x); err == nil {
+		t.Error("WhiteBalance succeeded without a white balance setting")
+	}
+}
+
+func TestPseudonymize(t *testing.T) {
+	exif.RegisterParsers(All...)
+	for _, tt := range []struct {
+		file string
+		name exif.FieldName
+	}{
+		{"2011-10-28-18-25-43-sep-2011-10-28-18-25-43.jpg", SerialNumber},
+		{"2012-06-02-10-12-28-sep-2012-06-02-10-12-28.jpg", InternalSerialNumber},
+		{"2012-12-21-11-15-19-sep-IMG_0001.jpg", InternalSerialNumber},
+		{"2099-08-12-19-59-29-sep-2099-08-12-19-59-29a.jpg", Nikon_SerialNO},
+	} {
+		data, err := os.ReadFile(filepath.Join("..", "exif", "samples", tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := exif.Pseudonymize(bytes.NewReader(data), &buf, []byte("salt")); err != nil {
+			t.Errorf("%v: %v", tt.file, err)
+			continue
+		}
+		orig, err := exif.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, err := exif.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("%v: decoding the pseudonymized file: %v", tt.file, err)
+		}
+		o, err := orig.Get(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := x.Get(tt.name)
+		if err != nil {
+			t.Errorf("%v: %v missing after pseudonymization: %v", tt.file, tt.name, err)
+			continue
+		}
+		if bytes.Equal(p.Val, o.Val) || len(p.Val) != len(o.Val) {
+			t.Errorf("%v: %v = %q, want a pseudonym of %q", tt.file, tt.name, p.Val, o.Val)
+		}
+	}
+}
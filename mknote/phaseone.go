@@ -0,0 +1,120 @@
+package mknote
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+type phaseOne struct{}
+
+// Parse decodes all Phase One makernote data found in x and adds it to x.
+func (_ *phaseOne) Parse(x *exif.Exif) error {
+	m, err := x.Get(exif.MakerNote)
+	if err != nil {
+		return nil
+	}
+	d, err := decodePhaseOne(m.Val)
+	if err != nil || d == nil {
+		return err
+	}
+	x.LoadTags(d, makerNotePhaseOneFields, false)
+	return nil
+}
+
+// phaseOneFloats holds the tags whose values are stored as 32-bit floats,
+// which the entry format does not tell apart from integers.
+var phaseOneFloats = map[uint32]bool{
+	0x0106: true,
+	0x0107: true,
+	0x0210: true,
+	0x0211: true,
+	0x0400: true,
+	0x0401: true,
+	0x0402: true,
+	0x0403: true,
+}
+
+// decodePhaseOne decodes the Phase One directory in note, returning a nil
+// Dir if note is not a Phase One maker note. The note starts with its byte
+// order ("IIII" or "MMMM"), a "Raw" signature and the offset of the
+// directory. The directory holds the number of entries, 4 unused bytes and
+// the entries, each made of a 4 byte tag ID, format, size and value (or
+// offset of the value, if larger than 4 bytes), with offsets relative to the
+// start of the note.
+func decodePhaseOne(note []byte) (*tiff.Dir, error) {
+	var order binary.ByteOrder
+	switch {
+	case len(note) < 12:
+		return nil, nil
+	case string(note[:4]) == "IIII" && string(note[5:8]) == "waR":
+		order = binary.LittleEndian
+	case string(note[:4]) == "MMMM" && string(note[4:7]) == "Raw":
+		order = binary.BigEndian
+	default:
+		return nil, nil
+	}
+
+	off := int64(order.Uint32(note[8:]))
+	if off+8 > int64(len(note)) {
+		return nil, errors.New("mknote: Phase One directory offset out of range")
+	}
+	n := int64(order.Uint32(note[off:]))
+	entries := note[off+8:]
+	if n > int64(len(entries))/16 {
+		return nil, errors.New("mknote: Phase One directory truncated")
+	}
+
+	d := new(tiff.Dir)
+	for i := int64(0); i < n; i++ {
+		e := entries[16*i : 16*i+16]
+		id, format, size := order.Uint32(e), order.Uint32(e[4:]), int64(order.Uint32(e[8:]))
+		if id > math.MaxUint16 {
+			continue
+		}
+		val := e[12:16]
+		if size <= 4 {
+			val = val[:size]
+		} else {
+			start := int64(order.Uint32(e[12:]))
+			if start+size > int64(len(note)) {
+				return nil, fmt.Errorf("mknote: Phase One tag 0x%04x value out of range", id)
+			}
+			val = note[start : start+size]
+		}
+
+		var t *tiff.Tag
+		var err error
+		switch {
+		case phaseOneFloats[id] && size%4 == 0:
+			vs := make([]float64, size/4)
+			for j := range vs {
+				vs[j] = float64(math.Float32frombits(order.Uint32(val[4*j:])))
+			}
+			t, err = tiff.NewTag(uint16(id), tiff.DTFloat, vs)
+		case format == 1:
+			if i := bytes.IndexByte(val, 0); i >= 0 {
+				val = val[:i]
+			}
+			t, err = tiff.NewTag(uint16(id), tiff.DTAscii, val)
+		case format == 4 && size%4 == 0:
+			vs := make([]uint32, size/4)
+			for j := range vs {
+				vs[j] = order.Uint32(val[4*j:])
+			}
+			t, err = tiff.NewTag(uint16(id), tiff.DTLong, vs)
+		default:
+			t, err = tiff.NewTag(uint16(id), tiff.DTUndefined, val)
+		}
+		if err != nil {
+			return nil, err
+		}
+		d.Tags = append(d.Tags, t)
+	}
+	return d, nil
+}
@@ -0,0 +1,48 @@
+package mknote
+
+import (
+	"errors"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// ShutterActuations returns the number of shutter actuations recorded in the
+// maker note of x: the ShutterCount of Nikon notes, the Sony_ShutterCount, or
+// the Pentax_ShutterCount, which is encrypted with the Pentax_Date and
+// Pentax_Time of the picture and decrypted as ExifTool does. The maker note
+// parsers must have been registered with exif.RegisterParsers.
+func ShutterActuations(x *exif.Exif) (int, error) {
+	for _, name := range []exif.FieldName{ShutterCount, Sony_ShutterCount} {
+		if _, err := x.Get(name); err == nil {
+			v, err := x.GetInt64(name)
+			return int(v), err
+		}
+	}
+	tag, err := x.Get(Pentax_ShutterCount)
+	if err != nil {
+		return 0, errors.New("mknote: no shutter count in maker note")
+	}
+
+	// The Pentax count is XORed with the Pentax date and the complement of
+	// the Pentax time padded to four bytes.
+	date, err := x.Get(Pentax_Date)
+	if err != nil {
+		return 0, err
+	}
+	tm, err := x.Get(Pentax_Time)
+	if err != nil {
+		return 0, err
+	}
+	if len(tag.Val) != 4 || len(date.Val) != 4 || len(tm.Val) < 3 {
+		return 0, errors.New("mknote: malformed Pentax shutter count, date or time")
+	}
+	var b [4]byte
+	for i := range b {
+		var t byte
+		if i < 3 {
+			t = tm.Val[i]
+		}
+		b[i] = tag.Val[i] ^ date.Val[i] ^ ^t
+	}
+	return int(tag.Order().Uint32(b[:])), nil
+}
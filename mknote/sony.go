@@ -0,0 +1,39 @@
+package mknote
+
+import (
+	"bytes"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+type sony struct{}
+
+// Parse decodes the lens and shutter count fields of the Sony makernote data
+// found in x and adds them to x. Notes starting with "SONY DSC " or "SONY CAM "
+// followed by three NULs, and the header-less notes of newer cameras, are
+// supported.
+func (_ *sony) Parse(x *exif.Exif) error {
+	m, err := x.Get(exif.MakerNote)
+	if err != nil {
+		return nil
+	}
+
+	var hdr int
+	switch v := m.Val; {
+	case bytes.HasPrefix(v, []byte("SONY DSC \000\000\000")),
+		bytes.HasPrefix(v, []byte("SONY CAM \000\000\000")):
+		hdr = 12
+	case cameraMakeIs(x, "SONY") && plausibleDir(v, x.Tiff.Order):
+	default:
+		return nil
+	}
+
+	// offsets are relative to the original tiff structure
+	order := dirOrder(m.Val[hdr:], x.Tiff.Order)
+	mkNotesDir, err := decodeNoteDir(m.Val, hdr, fixBase(x, m, hdr, order, int64(m.ValOffset)), order)
+	if err != nil {
+		return err
+	}
+	x.LoadTags(mkNotesDir, makerNoteSonyFields, false)
+	return nil
+}
@@ -0,0 +1,203 @@
+package mknote
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// A WhiteBalanceInfo gathers the white balance settings recorded in the EXIF
+// fields and the maker note of an image, as needed by raw developers to
+// start from the camera's rendering.
+type WhiteBalanceInfo struct {
+	// Manual reports whether the white balance was set manually, from the
+	// WhiteBalance field, and LightSource is the value of the LightSource
+	// field.
+	Manual      bool
+	LightSource exif.LightSourceType
+	// Mode is the white balance setting of the maker note, e.g. "Daylight"
+	// or "SUNNY" for Nikon cameras, which record it as text, or "" if not
+	// recorded. Unknown settings are given as their number.
+	Mode string
+	// ColorTemperature is the color temperature in kelvin recorded by Canon,
+	// Panasonic and Leica cameras, or 0.
+	ColorTemperature int
+	// FineTune is the Nikon WhiteBalanceFineTune, the adjustment of the
+	// setting along the amber-blue axis, followed by the adjustment along
+	// the green-magenta axis by newer cameras.
+	FineTune []int
+	// Levels are the red, green and blue multipliers of the white balance
+	// recorded by Nikon (with green 1), Leica and Phase One cameras, or nil.
+	Levels []float64
+}
+
+// Names of the white balance settings of Canon ShotInfo and Pentax and
+// Panasonic maker notes, as given by ExifTool.
+var (
+	canonWhiteBalance = map[int]string{
+		0:  "Auto",
+		1:  "Daylight",
+		2:  "Cloudy",
+		3:  "Tungsten",
+		4:  "Fluorescent",
+		5:  "Flash",
+		6:  "Custom",
+		7:  "Black & White",
+		8:  "Shade",
+		9:  "Manual Temperature (Kelvin)",
+		14: "Daylight Fluorescent",
+		15: "Custom 1",
+		16: "Custom 2",
+		17: "Underwater",
+		18: "Custom 3",
+		19: "Custom 4",
+		23: "Auto (ambience priority)",
+	}
+	pentaxWhiteBalance = map[int]string{
+		0:     "Auto",
+		1:     "Daylight",
+		2:     "Shade",
+		3:     "Fluorescent",
+		4:     "Tungsten",
+		5:     "Manual",
+		6:     "Daylight Fluorescent",
+		7:     "Day White Fluorescent",
+		8:     "White Fluorescent",
+		9:     "Flash",
+		10:    "Cloudy",
+		11:    "Warm White Fluorescent",
+		14:    "Multi Auto",
+		15:    "Color Temperature Enhancement",
+		17:    "Kelvin",
+		65534: "Unknown",
+		65535: "User-Selected",
+	}
+	panasonicWhiteBalance = map[int]string{
+		1:  "Auto",
+		2:  "Daylight",
+		3:  "Cloudy",
+		4:  "Incandescent",
+		5:  "Manual",
+		8:  "Flash",
+		10: "Black & White",
+		11: "Manual",
+		12: "Shade",
+		13: "Kelvin",
+		14: "Manual 2",
+		15: "Manual 3",
+		16: "Manual 4",
+		19: "Auto (cool)",
+	}
+	leicaWhiteBalance = map[int]string{
+		0: "Auto",
+		1: "Tungsten",
+		2: "Fluorescent",
+		3: "Daylight Fluorescent",
+		4: "Daylight",
+		5: "Flash",
+		6: "Cloudy",
+		7: "Shade",
+		8: "Manual",
+		9: "Kelvin",
+	}
+)
+
+// WhiteBalance returns the white balance settings of x: the WhiteBalance and
+// LightSource fields, and the settings recorded in the maker notes of Canon
+// (the WhiteBalance of ShotInfo and the ColorTemperature), Nikon (the
+// WhiteBalance, WhiteBalanceFineTune and WB_RBLevels), Pentax, Panasonic,
+// Leica and Phase One cameras. The maker note parsers must have been
+// registered with exif.RegisterParsers. An error is returned if x records
+// no white balance setting.
+func WhiteBalance(x *exif.Exif) (*WhiteBalanceInfo, error) {
+	wb := &WhiteBalanceInfo{}
+	found := false
+	if v, err := x.GetInt64(exif.WhiteBalance); err == nil {
+		wb.Manual, found = v == 1, true
+	}
+	if v, err := x.LightSource(); err == nil {
+		wb.LightSource, found = v, true
+	}
+
+	mode := func(name exif.FieldName, names map[int]string) {
+		if v, err := x.GetInt64(name); err == nil && wb.Mode == "" {
+			wb.Mode = settingName(names, int(v))
+		}
+	}
+	if tag, err := x.Get(Canon_ShotInfo); err == nil && tag.Count > 7 {
+		if v, err := tag.Int(7); err == nil {
+			wb.Mode = settingName(canonWhiteBalance, v)
+		}
+	}
+	if s, err := x.GetString(Nikon_WhiteBalance); err == nil && s != "" {
+		wb.Mode = s
+	}
+	mode(Pentax_WhiteBalance, pentaxWhiteBalance)
+	mode(Panasonic_WhiteBalance, panasonicWhiteBalance)
+	mode(Leica_WhiteBalance, leicaWhiteBalance)
+
+	for _, name := range []exif.FieldName{Canon_ColorTemperature, Panasonic_ColorTempKelvin, Leica_ColorTemperature} {
+		if v, err := x.GetInt64(name); err == nil && v > 0 && wb.ColorTemperature == 0 {
+			wb.ColorTemperature = int(v)
+		}
+	}
+
+	if tag, err := x.Get(WhiteBalanceBias); err == nil && tag.Format() == tiff.IntVal {
+		for i := 0; i < int(tag.Count); i++ {
+			v, _ := tag.Int(i)
+			wb.FineTune = append(wb.FineTune, v)
+		}
+	}
+	if tag, err := x.Get(WB_RBLevels); err == nil {
+		if rb := tagFloats(tag); len(rb) >= 2 {
+			wb.Levels = []float64{rb[0], 1, rb[1]}
+		}
+	}
+	for _, name := range []exif.FieldName{Leica_WB_RGBLevels, PhaseOne_WB_RGBLevels} {
+		if tag, err := x.Get(name); err == nil && wb.Levels == nil {
+			if rgb := tagFloats(tag); len(rgb) >= 3 {
+				wb.Levels = rgb[:3]
+			}
+		}
+	}
+
+	if !found && wb.Mode == "" && wb.ColorTemperature == 0 && wb.FineTune == nil && wb.Levels == nil {
+		return nil, errors.New("mknote: no white balance setting")
+	}
+	return wb, nil
+}
+
+// settingName returns the name of setting v, or v as a number if unknown.
+func settingName(names map[int]string, v int) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return strconv.Itoa(v)
+}
+
+// tagFloats returns the values of a numeric tag as floating point numbers,
+// or nil if the tag is not numeric or has a zero denominator.
+func tagFloats(tag *tiff.Tag) []float64 {
+	var vals []float64
+	for i := 0; i < int(tag.Count); i++ {
+		switch tag.Format() {
+		case tiff.IntVal:
+			v, _ := tag.Int64(i)
+			vals = append(vals, float64(v))
+		case tiff.RatVal:
+			num, den, _ := tag.Rat2(i)
+			if den == 0 {
+				return nil
+			}
+			vals = append(vals, float64(num)/float64(den))
+		case tiff.FloatVal:
+			v, _ := tag.Float(i)
+			vals = append(vals, v)
+		default:
+			return nil
+		}
+	}
+	return vals
+}
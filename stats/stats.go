@@ -0,0 +1,272 @@
+// Package stats aggregates EXIF tag statistics over collections of images,
+// such as the camera models, focal lengths and ISO speeds found in a photo
+// library.
+package stats
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// DefaultExts holds the file extensions (lower case, with leading dot)
+// scanned by Scan when Stats.Exts is nil.
+var DefaultExts = []string{".jpg", ".jpeg", ".tif", ".tiff", ".jxl", ".exif"}
+
+// Stats holds statistics aggregated from a set of decoded images. The zero
+// value is not usable; create one with New.
+type Stats struct {
+	// Files is the number of files added, including those that failed to
+	// decode.
+	Files int
+	// Errors is the number of files that failed to decode.
+	Errors int
+	// Tags counts the number of files each field is present in.
+	Tags map[exif.FieldName]int
+	// Models counts files per camera model (the Model field).
+	Models map[string]int
+	// FocalLengths counts files per focal length in millimeters, rounded to
+	// the nearest millimeter.
+	FocalLengths map[int]int
+	// ISOs counts files per ISO speed (the ISOSpeedRatings field).
+	ISOs map[int]int
+
+	// Exts overrides DefaultExts for Scan.
+	Exts []string `json:"-"`
+	// Workers is the number of files decoded concurrently by Scan, or
+	// runtime.NumCPU() if zero.
+	Workers int `json:"-"`
+}
+
+// New returns an empty Stats.
+func New() *Stats {
+	return &Stats{
+		Tags:         map[exif.FieldName]int{},
+		Models:       map[string]int{},
+		FocalLengths: map[int]int{},
+		ISOs:         map[int]int{},
+	}
+}
+
+// Add adds the decoded image x to s.
+func (s *Stats) Add(x *exif.Exif) {
+	s.Files++
+	x.Walk(walkFunc(func(name exif.FieldName, _ *tiff.Tag) error {
+		s.Tags[name]++
+		return nil
+	}))
+
+	if tag, err := x.Get(exif.Model); err == nil {
+		if model, err := tag.StringVal(); err == nil {
+			if model = strings.TrimSpace(model); model != "" {
+				s.Models[model]++
+			}
+		}
+	}
+	if v, err := x.GetFloat(exif.FocalLength); err == nil {
+		s.FocalLengths[int(math.Round(v))]++
+	}
+	if iso, err := x.GetInt64(exif.ISOSpeedRatings); err == nil {
+		s.ISOs[int(iso)]++
+	}
+}
+
+// AddError records a file that failed to decode.
+func (s *Stats) AddError() {
+	s.Files++
+	s.Errors++
+}
+
+// Scan decodes every file below root with an extension in s.Exts (or
+// DefaultExts) using dec and adds it to s. Files are decoded concurrently.
+// Files that fail to decode are recorded with AddError unless dec returned
+// a usable Exif along with a non-critical error. Scan only returns an error
+// if the directory tree itself cannot be walked.
+func (s *Stats) Scan(root string, dec *exif.Decoder) error {
+	exts := s.Exts
+	if exts == nil {
+		exts = DefaultExts
+	}
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	return scan(root, exts, workers, dec.Decode, func(_ string, x *exif.Exif) {
+		if x == nil {
+			s.AddError()
+		} else {
+			s.Add(x)
+		}
+	})
+}
+
+// Find returns the sorted paths of the files below root with an extension
+// in DefaultExts whose EXIF data satisfies all filters, e.g.
+//
+//	Find(root, dec,
+//		exif.Filter{Field: exif.ISOSpeedRatings, Op: exif.OpGt, Value: 3200},
+//		exif.Filter{Field: exif.Model, Op: exif.OpContains, Value: "X-T"})
+//
+// Only the tags of the filtered fields are decoded (see
+// exif.Decoder.DecodeTags). Files are decoded concurrently; those that
+// fail to decode do not match. Find only returns an error if the directory
+// tree itself cannot be walked.
+func Find(root string, dec *exif.Decoder, filters ...exif.Filter) ([]string, error) {
+	names := make([]exif.FieldName, len(filters))
+	for i, f := range filters {
+		names[i] = f.Field
+	}
+	decode := func(r io.Reader) (*exif.Exif, error) {
+		return dec.DecodeTags(r, names...)
+	}
+	var paths []string
+	err := scan(root, DefaultExts, runtime.NumCPU(), decode, func(path string, x *exif.Exif) {
+		if x != nil && exif.MatchAll(x, filters...) {
+			paths = append(paths, path)
+		}
+	})
+	sort.Strings(paths)
+	return paths, err
+}
+
+// Each decodes every file below root with an extension in DefaultExts using
+// dec, with workers files decoded concurrently (the number of CPUs if workers
+// is not positive), and calls fn in a single goroutine with the path and the
+// result of each, nil if decoding failed. Files are reported in no
+// particular order.
+func Each(root string, dec *exif.Decoder, workers int, fn func(path string, x *exif.Exif)) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return scan(root, DefaultExts, workers, dec.Decode, fn)
+}
+
+// scan decodes every file below root with an extension in exts using
+// workers concurrent calls to decode, and calls fn in a single goroutine
+// with the path and result of each, nil if decoding failed.
+func scan(root string, exts []string, workers int, decode func(io.Reader) (*exif.Exif, error), fn func(string, *exif.Exif)) error {
+	type result struct {
+		path string
+		x    *exif.Exif
+	}
+	paths := make(chan string)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				results <- result{path, decodeFile(path, decode)}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	walkErr := make(chan error, 1)
+	go func() {
+		defer close(paths)
+		walkErr <- filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.Mode().IsRegular() && hasExt(path, exts) {
+				paths <- path
+			}
+			return nil
+		})
+	}()
+
+	for r := range results {
+		fn(r.path, r.x)
+	}
+	return <-walkErr
+}
+
+// decodeFile decodes the file at path, returning nil if that fails.
+func decodeFile(path string, decode func(io.Reader) (*exif.Exif, error)) *exif.Exif {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	x, err := decode(f)
+	if err != nil && (x == nil || exif.IsCriticalError(err)) {
+		return nil
+	}
+	return x
+}
+
+func hasExt(path string, exts []string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteCSV writes s to w as CSV records of the form "category,value,count",
+// where category is one of files, errors, tag, model, focal_length or iso.
+// Records are sorted by category and value.
+func (s *Stats) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"category", "value", "count"})
+	cw.Write([]string{"files", "", strconv.Itoa(s.Files)})
+	cw.Write([]string{"errors", "", strconv.Itoa(s.Errors)})
+
+	tags := map[string]int{}
+	for name, n := range s.Tags {
+		tags[string(name)] = n
+	}
+	writeCounts(cw, "tag", tags)
+	writeCounts(cw, "model", s.Models)
+	writeIntCounts(cw, "focal_length", s.FocalLengths)
+	writeIntCounts(cw, "iso", s.ISOs)
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeCounts(cw *csv.Writer, category string, counts map[string]int) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cw.Write([]string{category, k, strconv.Itoa(counts[k])})
+	}
+}
+
+func writeIntCounts(cw *csv.Writer, category string, counts map[int]int) {
+	keys := make([]int, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, k := range keys {
+		cw.Write([]string{category, strconv.Itoa(k), strconv.Itoa(counts[k])})
+	}
+}
+
+type walkFunc func(exif.FieldName, *tiff.Tag) error
+
+func (f walkFunc) Walk(name exif.FieldName, tag *tiff.Tag) error {
+	return f(name, tag)
+}
@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+func TestScan(t *testing.T) {
+	s := New()
+	if err := s.Scan(filepath.Join("..", "exif", "samples"), new(exif.Decoder)); err != nil {
+		t.Fatal(err)
+	}
+	if s.Files == 0 {
+		t.Fatal("no files scanned")
+	}
+	if s.Errors >= s.Files {
+		t.Fatalf("all %d files failed to decode", s.Files)
+	}
+	if s.Tags[exif.Model] == 0 || len(s.Models) == 0 {
+		t.Errorf("no camera models counted: %v", s.Models)
+	}
+	if len(s.FocalLengths) == 0 || len(s.ISOs) == 0 {
+		t.Errorf("empty histograms: focal lengths %v, ISOs %v", s.FocalLengths, s.ISOs)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV output: %v", err)
+	}
+	want := 3 + len(s.Tags) + len(s.Models) + len(s.FocalLengths) + len(s.ISOs)
+	if len(records) != want {
+		t.Errorf("got %d CSV records, want %d", len(records), want)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := filepath.Join("..", "exif", "samples")
+	filters := []exif.Filter{
+		{Field: exif.ISOSpeedRatings, Op: exif.OpGe, Value: 100},
+		{Field: exif.Make, Op: exif.OpContains, Value: "a"},
+	}
+	got, err := Find(root, new(exif.Decoder), filters...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []string
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() || !hasExt(path, DefaultExts) {
+			return err
+		}
+		x := decodeFile(path, new(exif.Decoder).Decode)
+		if x != nil && exif.MatchAll(x, filters...) {
+			want = append(want, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) == 0 {
+		t.Fatal("no sample matches the filters")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEach(t *testing.T) {
+	root := filepath.Join("..", "exif", "samples")
+	seen := map[string]bool{}
+	var decoded int
+	err := Each(root, new(exif.Decoder), 2, func(path string, x *exif.Exif) {
+		if seen[path] {
+			t.Errorf("%v reported twice", path)
+		}
+		seen[path] = true
+		if x != nil {
+			decoded++
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New()
+	if err := s.Scan(root, new(exif.Decoder)); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != s.Files || decoded != s.Files-s.Errors {
+		t.Errorf("Each reported %d files, %d decoded; Scan counted %d files, %d errors", len(seen), decoded, s.Files, s.Errors)
+	}
+}
@@ -0,0 +1,278 @@
+// Package exifgen builds JPEG images carrying arbitrary EXIF data, so that
+// tests (of this module or of projects using it) can generate their fixtures
+// instead of committing binary sample files.
+package exifgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"math/big"
+	"reflect"
+	"sort"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// Fields maps the fields to generate to their values. A value may be a
+// *tiff.Tag, whose data type and values are used as they are (its ID is
+// replaced by that of the field), or any value accepted by tiff.NewTag, whose
+// data type is that given by exif.Exif232 if the value fits it, and is
+// otherwise inferred from the Go type of the value: ASCII for strings,
+// UNDEFINED for byte slices, SHORT or LONG (SSHORT or SLONG if negative) for
+// integers, RATIONAL (SRATIONAL if negative) for rationals and DOUBLE for
+// floats.
+type Fields map[exif.FieldName]interface{}
+
+// A Generator builds EXIF data. The zero value generates big endian EXIF data
+// and 8x8 pixel images.
+type Generator struct {
+	// Order is the byte order of the tiff data, binary.BigEndian if nil.
+	Order binary.ByteOrder
+	// Width and Height are the size of the generated image, 8 if zero.
+	Width, Height int
+}
+
+// JPEG returns a JPEG image holding fields in its EXIF segment. It is
+// new(Generator).JPEG(fields).
+func JPEG(fields Fields) ([]byte, error) {
+	return new(Generator).JPEG(fields)
+}
+
+// Tiff returns the tiff data holding fields, as stored in the EXIF segment
+// of a JPEG file. It is new(Generator).Tiff(fields).
+func Tiff(fields Fields) ([]byte, error) {
+	return new(Generator).Tiff(fields)
+}
+
+// JPEG returns a JPEG image of a uniform gray, holding fields in an APP1
+// EXIF segment directly following the start of image marker.
+func (g *Generator) JPEG(fields Fields) ([]byte, error) {
+	data, err := g.Tiff(fields)
+	if err != nil {
+		return nil, err
+	}
+	payload := append([]byte("Exif\x00\x00"), data...)
+	if len(payload)+2 > 0xFFFF {
+		return nil, fmt.Errorf("exifgen: EXIF data of %d bytes does not fit in a JPEG segment", len(payload))
+	}
+
+	w, h := g.Width, g.Height
+	if w <= 0 {
+		w = 8
+	}
+	if h <= 0 {
+		h = 8
+	}
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		return nil, err
+	}
+	jpg := buf.Bytes()
+
+	out := make([]byte, 0, len(jpg)+4+len(payload))
+	out = append(out, jpg[:2]...) // SOI
+	out = append(out, 0xFF, 0xE1, byte((len(payload)+2)>>8), byte(len(payload)+2))
+	out = append(out, payload...)
+	return append(out, jpg[2:]...), nil
+}
+
+// Tiff returns the tiff data holding fields: IFD0, followed by IFD1 if any
+// thumbnail field is given, with the Exif, GPS and Interoperability sub-IFDs
+// needed to hold the other fields. The IFD pointer fields are written as
+// needed and must not be given.
+func (g *Generator) Tiff(fields Fields) ([]byte, error) {
+	order := g.Order
+	if order == nil {
+		order = binary.BigEndian
+	}
+
+	names := make([]exif.FieldName, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	dirs := map[exif.IfdID]*tiff.Dir{}
+	for _, name := range names {
+		id, ok := tagIDs[name]
+		if !ok {
+			return nil, fmt.Errorf("exifgen: unknown field %v", name)
+		}
+		if pointers[name] {
+			return nil, fmt.Errorf("exifgen: %v is written as needed and must not be given", name)
+		}
+		tag, err := newTag(name, id.ID, fields[name])
+		if err != nil {
+			return nil, err
+		}
+		d := dirs[id.Ifd]
+		if d == nil {
+			d = tiff.NewDir()
+			dirs[id.Ifd] = d
+		}
+		d.Tags = append(d.Tags, tag)
+	}
+
+	ifd0 := dirs[exif.Ifd0]
+	if ifd0 == nil {
+		ifd0 = tiff.NewDir()
+	}
+	exifDir := dirs[exif.IfdExif]
+	if interop := dirs[exif.IfdInterop]; interop != nil {
+		if exifDir == nil {
+			exifDir = tiff.NewDir()
+		}
+		addSubDir(exifDir, exif.ExifIFD.InteroperabilityIFDPointer.ID, interop)
+	}
+	if exifDir != nil {
+		addSubDir(ifd0, exif.IFD0.ExifIFDPointer.ID, exifDir)
+	}
+	if gps := dirs[exif.IfdGPS]; gps != nil {
+		addSubDir(ifd0, exif.IFD0.GPSInfoIFDPointer.ID, gps)
+	}
+	chain := []*tiff.Dir{ifd0}
+	if ifd1 := dirs[exif.Ifd1]; ifd1 != nil {
+		chain = append(chain, ifd1)
+	}
+
+	var buf bytes.Buffer
+	if err := tiff.NewTiff(order, chain...).Encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func addSubDir(d *tiff.Dir, id uint16, sub *tiff.Dir) {
+	if d.SubDirs == nil {
+		d.SubDirs = map[uint16]*tiff.Dir{}
+	}
+	d.SubDirs[id] = sub
+}
+
+// newTag returns the tag with the given id holding the value of the named
+// field.
+func newTag(name exif.FieldName, id uint16, val interface{}) (*tiff.Tag, error) {
+	if t, ok := val.(*tiff.Tag); ok {
+		c := *t
+		c.Id = id
+		return &c, nil
+	}
+	var types []tiff.DataType
+	if spec, ok := exif.Exif232[name]; ok {
+		types = append(types, spec.Types...)
+	}
+	if typ, ok := inferType(val); ok {
+		types = append(types, typ)
+	}
+	for _, typ := range types {
+		if t, err := tiff.NewTag(id, typ, val); err == nil {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("exifgen: cannot encode %v value %v (%T)", name, val, val)
+}
+
+// inferType returns the data type of a value of the Go type of val.
+func inferType(val interface{}) (tiff.DataType, bool) {
+	switch v := val.(type) {
+	case string:
+		return tiff.DTAscii, true
+	case []byte:
+		return tiff.DTUndefined, true
+	case float64, []float64:
+		return tiff.DTDouble, true
+	case *big.Rat:
+		return ratType(v.Sign() < 0), true
+	case []*big.Rat:
+		neg := false
+		for _, r := range v {
+			neg = neg || r.Sign() < 0
+		}
+		return ratType(neg), true
+	case [2]int64:
+		return ratType((v[0] < 0) != (v[1] < 0)), true
+	case [][2]int64:
+		neg := false
+		for _, r := range v {
+			neg = neg || (r[0] < 0) != (r[1] < 0)
+		}
+		return ratType(neg), true
+	}
+
+	rv := reflect.ValueOf(val)
+	var ints []int64
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Uint16, reflect.Uint32:
+		ints = append(ints, intOf(rv))
+	case reflect.Slice:
+		switch rv.Type().Elem().Kind() {
+		case reflect.Int, reflect.Int64, reflect.Uint16, reflect.Uint32:
+			for i := 0; i < rv.Len(); i++ {
+				ints = append(ints, intOf(rv.Index(i)))
+			}
+		default:
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	short, neg := true, false
+	for _, v := range ints {
+		neg = neg || v < 0
+		short = short && v >= -0x8000 && v <= 0xFFFF
+	}
+	switch {
+	case neg && short:
+		return tiff.DTSShort, true
+	case neg:
+		return tiff.DTSLong, true
+	case short:
+		return tiff.DTShort, true
+	}
+	return tiff.DTLong, true
+}
+
+func intOf(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.Uint16, reflect.Uint32:
+		return int64(v.Uint())
+	}
+	return v.Int()
+}
+
+func ratType(neg bool) tiff.DataType {
+	if neg {
+		return tiff.DTSRational
+	}
+	return tiff.DTRational
+}
+
+// tagIDs maps the fields of the exif namespaces to their TagIDs.
+var tagIDs = map[exif.FieldName]exif.TagID{}
+
+// pointers holds the IFD pointer fields, which Tiff writes as needed.
+var pointers = map[exif.FieldName]bool{
+	exif.ExifIFDPointer:             true,
+	exif.GPSInfoIFDPointer:          true,
+	exif.InteroperabilityIFDPointer: true,
+}
+
+func init() {
+	for _, ns := range []interface{}{exif.IFD0, exif.ExifIFD, exif.GPS, exif.Interop, exif.IFD1} {
+		v := reflect.ValueOf(ns)
+		for i := 0; i < v.NumField(); i++ {
+			id := v.Field(i).Interface().(exif.TagID)
+			if _, ok := tagIDs[id.Name()]; !ok {
+				tagIDs[id.Name()] = id
+			}
+		}
+	}
+}
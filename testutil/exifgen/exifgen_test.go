@@ -0,0 +1,116 @@
+package exifgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/jpeg"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+func TestJPEG(t *testing.T) {
+	fields := Fields{
+		exif.Make:                  "Gopher",
+		exif.Orientation:           6,
+		exif.XResolution:           [2]int64{72, 1},
+		exif.DateTimeOriginal:      "2021:02:03 04:05:06",
+		exif.ExposureBiasValue:     [2]int64{-1, 3},
+		exif.GPSLatitudeRef:        "N",
+		exif.GPSLatitude:           [][2]int64{{40, 1}, {30, 1}, {0, 1}},
+		exif.GPSLongitudeRef:       "W",
+		exif.GPSLongitude:          [][2]int64{{111, 1}, {45, 1}, {0, 1}},
+		exif.InteroperabilityIndex: "R98",
+		exif.ThumbCompression:      6,
+	}
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		g := &Generator{Order: order, Width: 16, Height: 4}
+		data, err := g.JPEG(fields)
+		if err != nil {
+			t.Fatalf("%v: JPEG: %v", order, err)
+		}
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%v: jpeg.DecodeConfig: %v", order, err)
+		}
+		if cfg.Width != 16 || cfg.Height != 4 {
+			t.Errorf("%v: image is %dx%d, want 16x4", order, cfg.Width, cfg.Height)
+		}
+
+		x, err := exif.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%v: Decode: %v", order, err)
+		}
+		if x.Tiff.Order != order {
+			t.Errorf("tiff data is %v, want %v", x.Tiff.Order, order)
+		}
+		for name, want := range map[exif.FieldName]string{
+			exif.Make:                  `"Gopher"`,
+			exif.Orientation:           "6",
+			exif.XResolution:           `"72/1"`,
+			exif.DateTimeOriginal:      `"2021:02:03 04:05:06"`,
+			exif.ExposureBiasValue:     `"-1/3"`,
+			exif.InteroperabilityIndex: `"R98"`,
+			exif.ThumbCompression:      "6",
+		} {
+			tag, err := x.Get(name)
+			if err != nil {
+				t.Errorf("%v: %v", name, err)
+			} else if got := tag.String(); got != want {
+				t.Errorf("%v = %v, want %v", name, got, want)
+			}
+		}
+		if f, err := x.GetField(exif.ExposureBiasValue); err == nil && f.Tag.Type != tiff.DTSRational {
+			t.Errorf("ExposureBiasValue has type %v, want SRATIONAL", f.Tag.Type)
+		}
+		lat, long, err := x.LatLong()
+		if err != nil {
+			t.Errorf("LatLong: %v", err)
+		} else if math.Abs(lat-40.5) > 1e-9 || math.Abs(long+111.75) > 1e-9 {
+			t.Errorf("LatLong = %v, %v, want 40.5, -111.75", lat, long)
+		}
+	}
+}
+
+func TestTiffErrors(t *testing.T) {
+	for _, tt := range []struct {
+		fields Fields
+		err    string
+	}{
+		{Fields{"NoSuchField": 1}, "unknown field"},
+		{Fields{exif.ExifIFDPointer: 8}, "must not be given"},
+		{Fields{exif.Orientation: struct{}{}}, "cannot encode"},
+	} {
+		if _, err := Tiff(tt.fields); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Tiff(%v): got error %v, want %q", tt.fields, err, tt.err)
+		}
+	}
+	if _, err := JPEG(Fields{exif.MakerNote: make([]byte, 70000)}); err == nil {
+		t.Error("JPEG succeeded with EXIF data too large for a segment")
+	}
+}
+
+func TestTag(t *testing.T) {
+	tag, err := tiff.NewTag(0, tiff.DTLong, []int{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := JPEG(Fields{exif.ImageWidth: tag})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := x.GetField(exif.ImageWidth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Tag.Id != 0x0100 || f.Tag.Type != tiff.DTLong || f.Tag.Count != 2 {
+		t.Errorf("got tag 0x%04x of type %v with %d values, want LONG 0x0100 with 2", f.Tag.Id, f.Tag.Type, f.Tag.Count)
+	}
+}
@@ -0,0 +1,67 @@
+package tiff
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// An AllocFunc is called by a Decoder with the number of bytes it is about
+// to allocate to hold the data it decodes, so that services can meter the
+// memory used by each decode. Returning an error fails the decode; errors
+// wrapping ErrLimitExceeded do so even when decoding leniently. The counts
+// are estimates: they cover the tiff data read, the tag values copied out
+// of it and their decoded form, but not the bookkeeping of Tags and Dirs.
+type AllocFunc func(n int64) error
+
+// A Meter counts the bytes reported by the decodes it is the AllocFunc of,
+// and fails them once more than Max bytes have been counted, e.g. to
+// enforce a per-tenant budget. A Meter is safe for concurrent use, so that
+// the decodes of several goroutines can share it.
+type Meter struct {
+	// Max is the number of bytes the decodes may allocate, or 0 for no
+	// limit.
+	Max int64
+
+	n atomic.Int64
+}
+
+// Alloc is the AllocFunc of m. Once Max is exceeded, it returns an error
+// wrapping ErrLimitExceeded; the bytes are counted nonetheless.
+func (m *Meter) Alloc(n int64) error {
+	total := m.n.Add(n)
+	if m.Max > 0 && total > m.Max {
+		return fmt.Errorf("%w: decoding allocates more than %d bytes", ErrLimitExceeded, m.Max)
+	}
+	return nil
+}
+
+// Bytes returns the number of bytes counted by m.
+func (m *Meter) Bytes() int64 { return m.n.Load() }
+
+// Reset sets the count of m back to zero, e.g. at the start of a new
+// accounting period.
+func (m *Meter) Reset() { m.n.Store(0) }
+
+// alloc reports the allocation of n bytes to dec.Alloc, if set.
+func (dec *Decoder) alloc(n int64) error {
+	if dec.Alloc == nil || n <= 0 {
+		return nil
+	}
+	return dec.Alloc(n)
+}
+
+// decodedSize returns an estimate of the bytes used by the decoded form of
+// count values of type typ, see Tag.convertVals.
+func decodedSize(typ DataType, count uint32) int64 {
+	n := int64(count)
+	switch typ {
+	case DTByte, DTShort, DTLong, DTSByte, DTSShort, DTSLong, DTFloat, DTDouble:
+		return 8 * n
+	case DTRational, DTSRational:
+		// the numerators and denominators and a slice per value
+		return 16*n + 24*n
+	case DTAscii:
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package tiff
+
+// minChunk is the number of elements of the smallest slice a Buffer
+// allocates to carve values out of.
+const minChunk = 64
+
+// A Buffer holds memory reused across decodes, for services decoding many
+// files: when Decoder.Buffer is set, the tags decoded, their values and the
+// tag lists of their IFDs are carved out of it rather than allocated one by
+// one. Memory is handed out once until Reset is called, so that the results
+// of the decodes made since stay valid. A Buffer must not be used by
+// concurrent decodes.
+type Buffer struct {
+	tags  []Tag
+	ptrs  []*Tag
+	bytes []byte
+	ints  []int64
+	rats  [][]int64
+}
+
+// Reset makes the memory of b available again to the following decodes. The
+// Tiffs, Dirs and Tags decoded with b before must not be used anymore, as
+// their data is overwritten.
+func (b *Buffer) Reset() {
+	b.tags, b.ptrs, b.bytes, b.ints, b.rats = b.tags[:0], b.ptrs[:0], b.bytes[:0], b.ints[:0], b.rats[:0]
+}
+
+// grown returns the capacity of the slice b allocates when one of capacity
+// c cannot hold n more elements: twice as large, so that a Buffer reset
+// between decodes soon needs no more allocations.
+func grown(c, n int) int {
+	c *= 2
+	if c < minChunk {
+		c = minChunk
+	}
+	if c < n {
+		c = n
+	}
+	return c
+}
+
+// newTags returns n zeroed tags.
+func (b *Buffer) newTags(n int) []Tag {
+	if b == nil {
+		return make([]Tag, n)
+	}
+	if cap(b.tags)-len(b.tags) < n {
+		b.tags = make([]Tag, 0, grown(cap(b.tags), n))
+	}
+	i := len(b.tags)
+	b.tags = b.tags[:i+n]
+	s := b.tags[i : i+n : i+n]
+	for j := range s {
+		s[j] = Tag{}
+	}
+	return s
+}
+
+// newPtrs returns an empty slice with room for n tag pointers.
+func (b *Buffer) newPtrs(n int) []*Tag {
+	if b == nil {
+		return make([]*Tag, 0, n)
+	}
+	if cap(b.ptrs)-len(b.ptrs) < n {
+		b.ptrs = make([]*Tag, 0, grown(cap(b.ptrs), n))
+	}
+	i := len(b.ptrs)
+	b.ptrs = b.ptrs[:i+n]
+	return b.ptrs[i : i : i+n]
+}
+
+// newBytes returns n bytes, which are not zeroed.
+func (b *Buffer) newBytes(n int) []byte {
+	if b == nil {
+		return make([]byte, n)
+	}
+	if cap(b.bytes)-len(b.bytes) < n {
+		b.bytes = make([]byte, 0, grown(cap(b.bytes), n))
+	}
+	i := len(b.bytes)
+	b.bytes = b.bytes[:i+n]
+	return b.bytes[i : i+n : i+n]
+}
+
+// newInts returns n integers, which are not zeroed.
+func (b *Buffer) newInts(n int) []int64 {
+	if b == nil {
+		return make([]int64, n)
+	}
+	if cap(b.ints)-len(b.ints) < n {
+		b.ints = make([]int64, 0, grown(cap(b.ints), n))
+	}
+	i := len(b.ints)
+	b.ints = b.ints[:i+n]
+	return b.ints[i : i+n : i+n]
+}
+
+// newRats returns n rational slices, which are not zeroed.
+func (b *Buffer) newRats(n int) [][]int64 {
+	if b == nil {
+		return make([][]int64, n)
+	}
+	if cap(b.rats)-len(b.rats) < n {
+		b.rats = make([][]int64, 0, grown(cap(b.rats), n))
+	}
+	i := len(b.rats)
+	b.rats = b.rats[:i+n]
+	return b.rats[i : i+n : i+n]
+}
@@ -0,0 +1,64 @@
+package tiff
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Charset decides how the bytes of ASCII values are decoded into the
+// strings returned by Tag.StringVal. Although the TIFF and EXIF
+// specifications only allow 7-bit ASCII, many cameras and editors write
+// UTF-8 or Latin-1 (ISO 8859-1) text into tags such as Artist and
+// Copyright. The bytes as stored remain available from Tag.RawStringVal
+// whatever the charset.
+type Charset int
+
+const (
+	// CharsetAuto decodes values holding valid UTF-8 as UTF-8, and other
+	// values as Latin-1. Plain ASCII values are valid UTF-8.
+	CharsetAuto Charset = iota
+	// CharsetUTF8 decodes values as UTF-8, replacing invalid bytes with
+	// the Unicode replacement character U+FFFD.
+	CharsetUTF8
+	// CharsetLatin1 decodes values as Latin-1, mapping each byte to the
+	// code point of the same value.
+	CharsetLatin1
+	// CharsetRaw does not decode values: StringVal returns the bytes as
+	// stored, which may not be valid UTF-8.
+	CharsetRaw
+)
+
+var charsetNames = map[Charset]string{
+	CharsetAuto:   "auto",
+	CharsetUTF8:   "UTF-8",
+	CharsetLatin1: "Latin-1",
+	CharsetRaw:    "raw",
+}
+
+func (c Charset) String() string {
+	if name, ok := charsetNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Charset(%d)", int(c))
+}
+
+// Decode returns b decoded as text of charset c.
+func (c Charset) Decode(b []byte) string {
+	switch c {
+	case CharsetRaw:
+		return string(b)
+	case CharsetUTF8:
+		return strings.ToValidUTF8(string(b), string(utf8.RuneError))
+	case CharsetAuto:
+		if utf8.Valid(b) {
+			return string(b)
+		}
+	}
+	// Latin-1 code points are those of the first 256 runes.
+	rs := make([]rune, len(b))
+	for i, v := range b {
+		rs[i] = rune(v)
+	}
+	return string(rs)
+}
@@ -0,0 +1,536 @@
+package tiff
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// NewTiff returns a Tiff with the IFD chain dirs, to be encoded by Encode
+// in the given byte order (binary.BigEndian or binary.LittleEndian).
+func NewTiff(order binary.ByteOrder, dirs ...*Dir) *Tiff {
+	return &Tiff{Order: order, Dirs: dirs}
+}
+
+// NewDir returns an IFD holding the given tags, for use with Encode.
+func NewDir(tags ...*Tag) *Dir {
+	return &Dir{Tags: tags, Layout: Layout{Offset: -1}}
+}
+
+// NewTag returns a tag with the given id and data type holding val. The
+// supported Go types of val depend on typ:
+//
+//	DTAscii:                         string or []byte
+//	DTByte, DTUndefined:             []byte or any integer type below
+//	DTShort, DTLong, DTSByte,
+//	DTSShort, DTSLong:               int, int64, uint16, uint32, []int,
+//	                                 []int64, []uint16 or []uint32
+//	DTRational, DTSRational:         *big.Rat, []*big.Rat, [2]int64 or
+//	                                 [][2]int64 (numerator, denominator)
+//	DTFloat, DTDouble:               float64 or []float64
+//
+// A NUL terminator is appended to strings that lack one. An error is returned
+// if val does not fit typ.
+func NewTag(id uint16, typ DataType, val interface{}) (*Tag, error) {
+	t := &Tag{Id: id, Type: typ, order: binary.BigEndian}
+	var err error
+	switch typ {
+	case DTAscii:
+		var b []byte
+		switch v := val.(type) {
+		case string:
+			b = []byte(v)
+		case []byte:
+			b = append([]byte(nil), v...)
+		default:
+			return nil, newTagErr(id, typ, val)
+		}
+		if len(b) == 0 || b[len(b)-1] != 0 {
+			b = append(b, 0)
+		}
+		t.Val = b
+	case DTByte, DTUndefined:
+		if b, ok := val.([]byte); ok {
+			t.Val = append([]byte(nil), b...)
+			break
+		}
+		fallthrough
+	case DTShort, DTLong, DTSByte, DTSShort, DTSLong:
+		ints, ok := intsOf(val)
+		if !ok {
+			return nil, newTagErr(id, typ, val)
+		}
+		t.intVals = ints
+		t.Val, err = encodeVals(t, t.order)
+	case DTRational, DTSRational:
+		rats, ok := ratsOf(val)
+		if !ok {
+			return nil, newTagErr(id, typ, val)
+		}
+		t.ratVals = rats
+		t.Val, err = encodeVals(t, t.order)
+	case DTFloat, DTDouble:
+		switch v := val.(type) {
+		case float64:
+			t.floatVals = []float64{v}
+		case []float64:
+			t.floatVals = append([]float64(nil), v...)
+		default:
+			return nil, newTagErr(id, typ, val)
+		}
+		t.Val, err = encodeVals(t, t.order)
+	default:
+		return nil, fmt.Errorf("tiff: cannot create tag 0x%04x of data type %v", id, typeName(typ))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tiff: tag 0x%04x: %v", id, err)
+	}
+	if len(t.Val) == 0 {
+		return nil, fmt.Errorf("tiff: tag 0x%04x has an empty value", id)
+	}
+	t.Count = uint32(len(t.Val) / int(typeSize[typ]))
+	if err := t.convertVals(nil); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func newTagErr(id uint16, typ DataType, val interface{}) error {
+	return fmt.Errorf("tiff: cannot create tag 0x%04x of data type %v from %T", id, typeName(typ), val)
+}
+
+func intsOf(val interface{}) ([]int64, bool) {
+	var ints []int64
+	switch v := val.(type) {
+	case int:
+		ints = []int64{int64(v)}
+	case int64:
+		ints = []int64{v}
+	case uint16:
+		ints = []int64{int64(v)}
+	case uint32:
+		ints = []int64{int64(v)}
+	case []int:
+		for _, i := range v {
+			ints = append(ints, int64(i))
+		}
+	case []int64:
+		ints = append(ints, v...)
+	case []uint16:
+		for _, i := range v {
+			ints = append(ints, int64(i))
+		}
+	case []uint32:
+		for _, i := range v {
+			ints = append(ints, int64(i))
+		}
+	default:
+		return nil, false
+	}
+	return ints, true
+}
+
+func ratsOf(val interface{}) ([][]int64, bool) {
+	var rats [][]int64
+	switch v := val.(type) {
+	case *big.Rat:
+		return ratsOf([]*big.Rat{v})
+	case []*big.Rat:
+		for _, r := range v {
+			if !r.Num().IsInt64() || !r.Denom().IsInt64() {
+				return nil, false
+			}
+			rats = append(rats, []int64{r.Num().Int64(), r.Denom().Int64()})
+		}
+	case [2]int64:
+		rats = [][]int64{{v[0], v[1]}}
+	case [][2]int64:
+		for _, r := range v {
+			rats = append(rats, []int64{r[0], r[1]})
+		}
+	default:
+		return nil, false
+	}
+	return rats, true
+}
+
+// encodeVals encodes the decoded values of t in the given byte order.
+func encodeVals(t *Tag, order binary.ByteOrder) ([]byte, error) {
+	var b []byte
+	putInt := func(v int64, size int, min, max int64) error {
+		if v < min || v > max {
+			return fmt.Errorf("value %d out of range for data type %v", v, typeName(t.Type))
+		}
+		if size == 1 {
+			b = append(b, byte(v))
+		} else {
+			b = appendUint(b, order, size, uint64(v))
+		}
+		return nil
+	}
+
+	switch t.Type {
+	case DTByte, DTUndefined, DTShort, DTLong, DTSByte, DTSShort, DTSLong:
+		min, max := intRange(t.Type)
+		for _, v := range t.intVals {
+			if err := putInt(v, int(typeSize[t.Type]), min, max); err != nil {
+				return nil, err
+			}
+		}
+	case DTRational, DTSRational:
+		min, max := intRange(DTLong)
+		if t.Type == DTSRational {
+			min, max = intRange(DTSLong)
+		}
+		for _, r := range t.ratVals {
+			if err := putInt(r[0], 4, min, max); err != nil {
+				return nil, err
+			}
+			if err := putInt(r[1], 4, min, max); err != nil {
+				return nil, err
+			}
+		}
+	case DTFloat:
+		for _, v := range t.floatVals {
+			b = appendUint(b, order, 4, uint64(math.Float32bits(float32(v))))
+		}
+	case DTDouble:
+		for _, v := range t.floatVals {
+			b = appendUint(b, order, 8, math.Float64bits(v))
+		}
+	default:
+		return nil, fmt.Errorf("cannot encode values of data type %v", typeName(t.Type))
+	}
+	return b, nil
+}
+
+// appendUint appends the size byte unsigned integer v to b.
+func appendUint(b []byte, order binary.ByteOrder, size int, v uint64) []byte {
+	var tmp [8]byte
+	switch size {
+	case 2:
+		order.PutUint16(tmp[:], uint16(v))
+	case 4:
+		order.PutUint32(tmp[:], uint32(v))
+	case 8:
+		order.PutUint64(tmp[:], v)
+	}
+	return append(b, tmp[:size]...)
+}
+
+// intRange returns the range of values of the integer data type dt.
+func intRange(dt DataType) (min, max int64) {
+	switch dt {
+	case DTByte, DTUndefined:
+		return 0, math.MaxUint8
+	case DTShort:
+		return 0, math.MaxUint16
+	case DTLong:
+		return 0, math.MaxUint32
+	case DTSByte:
+		return math.MinInt8, math.MaxInt8
+	case DTSShort:
+		return math.MinInt16, math.MaxInt16
+	}
+	return math.MinInt32, math.MaxInt32
+}
+
+// valBytes returns the value of t encoded in the given byte order.
+func (t *Tag) valBytes(order binary.ByteOrder) ([]byte, error) {
+	if t.order == order || t.order == nil {
+		return t.Val, nil
+	}
+	switch t.Type {
+	case DTAscii, DTByte, DTSByte, DTUndefined:
+		return t.Val, nil
+	}
+	if _, ok := typeSize[t.Type]; !ok {
+		if typeHandlers[t.Type].Size == 1 {
+			return t.Val, nil
+		}
+		return nil, fmt.Errorf("tiff: cannot change the byte order of tag 0x%04x of data type %v", t.Id, typeName(t.Type))
+	}
+	return encodeVals(t, order)
+}
+
+// Encode writes tf as tiff data to w: the header, followed by each IFD of
+// the chain in tf.Dirs, each one followed by the tag values that do not fit
+// in its entries and by its sub-IFDs (see Dir.SubDirs). Entries are written
+// sorted by tag ID, values are word aligned, and tag values are converted to
+// tf.Order where needed. The offsets of the IFDs and tag values are computed
+// by Encode; the ValOffset and Layout fields of the encoded Tags and Dirs
+// are not used or updated.
+//
+// Data referenced by offset tags other than sub-IFDs (e.g. strip data or a
+// JPEG thumbnail) is not written; such tags are written as they are. Use
+// EncodeWithData to copy the image data as well.
+func (tf *Tiff) Encode(w io.Writer) error {
+	enc := &encoder{order: tf.Order}
+	if err := enc.encode(tf); err != nil {
+		return err
+	}
+	_, err := w.Write(enc.buf)
+	return err
+}
+
+// dataTags pairs the offset and byte count tags of the image data an IFD
+// may reference: strips, tiles and the JPEG thumbnail of IFD1.
+var dataTags = [][2]uint16{{0x0111, 0x0117}, {0x0144, 0x0145}, {0x0201, 0x0202}}
+
+// A chunk is a byte range of the source data of EncodeWithData.
+type chunk struct {
+	off, n int64
+}
+
+// EncodeWithData is like Encode, but also copies the strips, tiles and JPEG
+// thumbnail referenced by the IFDs of tf (and their sub-IFDs) from src, the
+// tiff data tf was decoded from, and points their offset tags at the
+// copies. The image data is streamed after the metadata with io.Copy and
+// never held in memory, so that the metadata of very large files can be
+// rewritten cheaply. The tags of tf are not modified.
+func (tf *Tiff) EncodeWithData(w io.Writer, src io.ReaderAt) error {
+	type fixup struct {
+		tag    *Tag
+		chunks []chunk
+	}
+	var fixups []fixup
+	var clone func(d *Dir, depth int) (*Dir, error)
+	clone = func(d *Dir, depth int) (*Dir, error) {
+		if depth > maxSubDirDepth {
+			return nil, errors.New("tiff: sub-IFDs nested too deeply")
+		}
+		c := *d
+		c.Tags = append([]*Tag(nil), d.Tags...)
+		for _, p := range dataTags {
+			offs, chunks, err := dirChunks(d, p)
+			if err != nil {
+				return nil, err
+			}
+			if offs == nil {
+				continue
+			}
+			// the new offsets are filled in once the metadata is laid out
+			placeholder, err := NewTag(p[0], DTLong, make([]uint32, offs.Count))
+			if err != nil {
+				return nil, err
+			}
+			for i, t := range c.Tags {
+				if t == offs {
+					c.Tags[i] = placeholder
+				}
+			}
+			fixups = append(fixups, fixup{placeholder, chunks})
+		}
+		if d.SubDirs != nil {
+			c.SubDirs = map[uint16]*Dir{}
+			for id, sub := range d.SubDirs {
+				var err error
+				if c.SubDirs[id], err = clone(sub, depth+1); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return &c, nil
+	}
+	out := &Tiff{Order: tf.Order}
+	for _, d := range tf.Dirs {
+		c, err := clone(d, 0)
+		if err != nil {
+			return err
+		}
+		out.Dirs = append(out.Dirs, c)
+	}
+
+	enc := &encoder{order: tf.Order, pos: map[*Tag]int{}}
+	if err := enc.encode(out); err != nil {
+		return err
+	}
+	end := int64(len(enc.buf))
+	for _, f := range fixups {
+		for i, c := range f.chunks {
+			end += end % 2
+			enc.order.PutUint32(enc.buf[enc.pos[f.tag]+4*i:], uint32(end))
+			end += c.n
+		}
+	}
+	if end > math.MaxUint32 {
+		return errors.New("tiff: encoded data exceeds 4 GiB")
+	}
+
+	if _, err := w.Write(enc.buf); err != nil {
+		return err
+	}
+	written := int64(len(enc.buf))
+	for _, f := range fixups {
+		for _, c := range f.chunks {
+			if written%2 == 1 {
+				if _, err := w.Write([]byte{0}); err != nil {
+					return err
+				}
+				written++
+			}
+			n, err := io.Copy(w, io.NewSectionReader(src, c.off, c.n))
+			if err != nil {
+				return err
+			}
+			if n != c.n {
+				return fmt.Errorf("tiff: image data at offset %d is truncated: got %d bytes, want %d", c.off, n, c.n)
+			}
+			written += n
+		}
+	}
+	return nil
+}
+
+// encode appends the tiff data of tf to enc.buf.
+func (enc *encoder) encode(tf *Tiff) error {
+	switch tf.Order {
+	case binary.LittleEndian:
+		enc.buf = append(enc.buf, "II"...)
+	case binary.BigEndian:
+		enc.buf = append(enc.buf, "MM"...)
+	default:
+		return errors.New("tiff: unsupported byte order")
+	}
+	enc.buf = appendUint(enc.buf, enc.order, 2, 42)
+	enc.buf = append(enc.buf, 0, 0, 0, 0)
+
+	next := 4 // position of the offset to the next IFD
+	for _, d := range tf.Dirs {
+		off, err := enc.writeDir(d, 0)
+		if err != nil {
+			return err
+		}
+		enc.order.PutUint32(enc.buf[next:], uint32(off))
+		next = off + 2 + 12*enc.entries(d)
+	}
+	if len(enc.buf) > math.MaxUint32 {
+		return errors.New("tiff: encoded data exceeds 4 GiB")
+	}
+	return nil
+}
+
+// maxSubDirDepth bounds the nesting of sub-IFDs so that cyclic SubDirs do
+// not recurse forever.
+const maxSubDirDepth = 8
+
+type encoder struct {
+	order binary.ByteOrder
+	buf   []byte
+	// pos, if not nil, records the position in buf of the value of each
+	// tag written.
+	pos map[*Tag]int
+}
+
+// entries returns the number of entries written for d.
+func (enc *encoder) entries(d *Dir) int {
+	n := len(d.Tags)
+	for id := range d.SubDirs {
+		if d.tag(id) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (d *Dir) tag(id uint16) *Tag {
+	for _, t := range d.Tags {
+		if t.Id == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// writeDir appends d, its values and sub-IFDs and returns the offset of d.
+// The offset to the next IFD is left zero for the caller to fill in.
+func (enc *encoder) writeDir(d *Dir, depth int) (int, error) {
+	if depth > maxSubDirDepth {
+		return 0, errors.New("tiff: sub-IFDs nested too deeply")
+	}
+	n := enc.entries(d)
+	if n > math.MaxUint16 {
+		return 0, fmt.Errorf("tiff: IFD has %d entries, at most %d are allowed", n, math.MaxUint16)
+	}
+
+	type entry struct {
+		tag *Tag
+		sub *Dir
+	}
+	var ents []entry
+	for _, t := range d.Tags {
+		ents = append(ents, entry{t, d.SubDirs[t.Id]})
+	}
+	for id, sub := range d.SubDirs {
+		if d.tag(id) == nil {
+			ents = append(ents, entry{&Tag{Id: id, Type: DTLong, Count: 1}, sub})
+		}
+	}
+	sort.SliceStable(ents, func(i, j int) bool { return ents[i].tag.Id < ents[j].tag.Id })
+
+	enc.align()
+	off := len(enc.buf)
+	enc.buf = appendUint(enc.buf, enc.order, 2, uint64(n))
+	enc.buf = append(enc.buf, make([]byte, 12*n+4)...)
+
+	for i, e := range ents {
+		pos := off + 2 + 12*i
+		t := e.tag
+		typ, count := t.Type, t.Count
+		var val []byte
+		if e.sub != nil {
+			if typ != DTLong && typ != 13 {
+				typ = DTLong
+			}
+			count = 1
+		} else {
+			var err error
+			if val, err = t.valBytes(enc.order); err != nil {
+				return 0, err
+			}
+			if uint64(len(val)) != uint64(sizeOf(typ))*uint64(count) {
+				return 0, fmt.Errorf("tiff: tag 0x%04x value is %d bytes, want %d for %d values of data type %v", t.Id, len(val), uint64(sizeOf(typ))*uint64(count), count, typeName(typ))
+			}
+		}
+		enc.order.PutUint16(enc.buf[pos:], t.Id)
+		enc.order.PutUint16(enc.buf[pos+2:], uint16(typ))
+		enc.order.PutUint32(enc.buf[pos+4:], count)
+		valPos := pos + 8
+		if len(val) > 4 {
+			enc.align()
+			valPos = len(enc.buf)
+			enc.order.PutUint32(enc.buf[pos+8:], uint32(valPos))
+			enc.buf = append(enc.buf, val...)
+		} else {
+			copy(enc.buf[pos+8:], val)
+		}
+		if enc.pos != nil {
+			enc.pos[t] = valPos
+		}
+	}
+
+	// sub-IFDs follow the values so that their offsets can be filled in
+	for i, e := range ents {
+		if e.sub == nil {
+			continue
+		}
+		subOff, err := enc.writeDir(e.sub, depth+1)
+		if err != nil {
+			return 0, err
+		}
+		enc.order.PutUint32(enc.buf[off+2+12*i+8:], uint32(subOff))
+	}
+	return off, nil
+}
+
+// align pads the data to a word boundary, as required by the tiff
+// specification for IFDs and tag values.
+func (enc *encoder) align() {
+	if len(enc.buf)%2 == 1 {
+		enc.buf = append(enc.buf, 0)
+	}
+}
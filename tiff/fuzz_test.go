@@ -0,0 +1,98 @@
+package tiff
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// corruptTiffs holds minimized inputs that used to crash or hang the decoder,
+// or that exercise the same code paths. All of them must fail to decode
+// without panicking.
+var corruptTiffs = []struct {
+	name string
+	hex  string
+}{
+	{"IFD0 offset past end", "4D4D002A 7FFFFFF0"},
+	{"negative IFD0 offset", "4D4D002A FFFFFFF0"},
+	{"tag count 0x7fff", "4D4D002A 00000008 7FFF 0100 0003 00000001 00010000"},
+	{"truncated IFD entry", "4D4D002A 00000008 0001 0100 0003"},
+	{"missing next IFD offset", "4D4D002A 00000008 0001 0100 0003 00000001 00010000"},
+	{"self-referencing IFD", "4D4D002A 00000008 0001 0100 0003 00000001 00010000 00000008"},
+	{"recursive IFD chain", "4D4D002A 00000008" +
+		"0001 0100 0003 00000001 00010000 0000001A" +
+		"0001 0101 0003 00000001 00020000 00000008"},
+	{"overflowing value size", "4D4D002A 00000008 0001 011A 0005 20000001 00000008 00000000"},
+	{"value offset past end", "4D4D002A 00000008 0001 010F 0002 00000010 7FFFFFFF 00000000"},
+	{"unknown data type", "4D4D002A 00000008 0001 0100 00FF 00000001 00010000 00000000"},
+}
+
+// validTiffs holds minimized inputs with unusual but valid content, which
+// must decode.
+var validTiffs = []struct {
+	name string
+	hex  string
+	tags int
+}{
+	{"zero-component tag", "4D4D002A 00000008 0001 0100 0003 00000000 00000000 00000000", 1},
+}
+
+func corruptTiffData(t testing.TB, h string) []byte {
+	b, err := hex.DecodeString(strings.Replace(h, " ", "", -1))
+	if err != nil {
+		t.Fatalf("invalid hex fixture %q: %v", h, err)
+	}
+	return b
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	for _, tt := range corruptTiffs {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(bytes.NewReader(corruptTiffData(t, tt.hex))); err == nil {
+				t.Error("no error decoding corrupt tiff data")
+			}
+			// Lenient decoding must not panic either, but may succeed.
+			(&Decoder{Lenient: true}).Decode(bytes.NewReader(corruptTiffData(t, tt.hex)))
+		})
+	}
+}
+
+func TestDecodeValid(t *testing.T) {
+	for _, tt := range validTiffs {
+		t.Run(tt.name, func(t *testing.T) {
+			tif, err := Decode(bytes.NewReader(corruptTiffData(t, tt.hex)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := len(tif.Dirs[0].Tags); n != tt.tags {
+				t.Errorf("got %d tags, want %d", n, tt.tags)
+			}
+			for _, tag := range tif.Dirs[0].Tags {
+				if tag.Count == 0 && len(tag.Val) != 0 {
+					t.Errorf("tag 0x%04x without values has %d value bytes", tag.Id, len(tag.Val))
+				}
+			}
+			_ = tif.String()
+		})
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Add(data())
+	for _, tt := range corruptTiffs {
+		f.Add(corruptTiffData(f, tt.hex))
+	}
+	for _, tt := range validTiffs {
+		f.Add(corruptTiffData(f, tt.hex))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		for _, dec := range []*Decoder{{}, {Lenient: true}} {
+			tif, err := dec.Decode(bytes.NewReader(b))
+			if err != nil {
+				continue
+			}
+			_ = tif.String()
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package tiff
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Tag IDs of the GeoTIFF tags, which georeference the raster of an IFD.
+const (
+	TagModelPixelScale     = 0x830E
+	TagModelTiepoint       = 0x8482
+	TagModelTransformation = 0x85D8
+	TagGeoKeyDirectory     = 0x87AF
+	TagGeoDoubleParams     = 0x87B0
+	TagGeoASCIIParams      = 0x87B1
+)
+
+// IDs of commonly used GeoKeys.
+const (
+	GTModelTypeGeoKey      = 1024
+	GTRasterTypeGeoKey     = 1025
+	GeographicTypeGeoKey   = 2048
+	ProjectedCSTypeGeoKey  = 3072
+	VerticalCSTypeGeoKey   = 4096
+	UserDefinedGeoKeyValue = 32767
+)
+
+// A GeoKey is an entry of the GeoKey directory. Depending on where the
+// directory stores its value, Ints, Doubles or ASCII is set.
+type GeoKey struct {
+	ID      uint16
+	Ints    []int
+	Doubles []float64
+	ASCII   string
+}
+
+// GeoTIFF holds the georeferencing information of an IFD.
+type GeoTIFF struct {
+	// Keys maps GeoKey IDs to the keys of the GeoKey directory.
+	Keys map[uint16]GeoKey
+	// Tiepoints holds the (I, J, K, X, Y, Z) tiepoints, mapping raster
+	// point (I, J, K) to model point (X, Y, Z).
+	Tiepoints [][6]float64
+	// PixelScale holds the (ScaleX, ScaleY, ScaleZ) size of a pixel in
+	// model units, or nil if there is none.
+	PixelScale []float64
+	// Transformation holds the 4x4 raster to model transformation matrix in
+	// row-major order, or nil if there is none.
+	Transformation []float64
+
+	width, height int
+}
+
+// DecodeGeoTIFF decodes the GeoTIFF tags of d. It returns an error if d has
+// none, or if the GeoKey directory is malformed.
+func DecodeGeoTIFF(d *Dir) (*GeoTIFF, error) {
+	g := &GeoTIFF{Keys: map[uint16]GeoKey{}}
+	found := false
+	if t := d.tag(TagModelPixelScale); t != nil {
+		found = true
+		var err error
+		if g.PixelScale, err = floats(t); err != nil {
+			return nil, err
+		}
+	}
+	if t := d.tag(TagModelTransformation); t != nil {
+		found = true
+		var err error
+		if g.Transformation, err = floats(t); err != nil {
+			return nil, err
+		}
+		if len(g.Transformation) != 16 {
+			return nil, fmt.Errorf("tiff: ModelTransformation has %d values, want 16", len(g.Transformation))
+		}
+	}
+	if t := d.tag(TagModelTiepoint); t != nil {
+		found = true
+		v, err := floats(t)
+		if err != nil {
+			return nil, err
+		}
+		for ; len(v) >= 6; v = v[6:] {
+			var tp [6]float64
+			copy(tp[:], v)
+			g.Tiepoints = append(g.Tiepoints, tp)
+		}
+	}
+	if t := d.tag(TagGeoKeyDirectory); t != nil {
+		found = true
+		if err := g.decodeKeys(d, t); err != nil {
+			return nil, err
+		}
+	}
+	if !found {
+		return nil, errors.New("tiff: IFD has no GeoTIFF tags")
+	}
+	if t := d.tag(0x0100); t != nil && t.Count > 0 {
+		g.width, _ = t.Int(0)
+	}
+	if t := d.tag(0x0101); t != nil && t.Count > 0 {
+		g.height, _ = t.Int(0)
+	}
+	return g, nil
+}
+
+func floats(t *Tag) ([]float64, error) {
+	v := make([]float64, t.Count)
+	for i := range v {
+		var err error
+		if v[i], err = t.Float(i); err != nil {
+			return nil, fmt.Errorf("tiff: tag 0x%04x: %v", t.Id, err)
+		}
+	}
+	return v, nil
+}
+
+// decodeKeys decodes the GeoKey directory t, whose values may be stored in
+// the GeoDoubleParams and GeoASCIIParams tags of d.
+func (g *GeoTIFF) decodeKeys(d *Dir, t *Tag) error {
+	dir := make([]int, t.Count)
+	for i := range dir {
+		var err error
+		if dir[i], err = t.Int(i); err != nil {
+			return fmt.Errorf("tiff: GeoKeyDirectory: %v", err)
+		}
+	}
+	if len(dir) < 4 {
+		return errors.New("tiff: GeoKeyDirectory is truncated")
+	}
+	var doubles []float64
+	if t := d.tag(TagGeoDoubleParams); t != nil {
+		var err error
+		if doubles, err = floats(t); err != nil {
+			return err
+		}
+	}
+	var ascii string
+	if t := d.tag(TagGeoASCIIParams); t != nil {
+		ascii, _ = t.StringVal()
+	}
+
+	n := dir[3]
+	if 4+4*n > len(dir) {
+		return fmt.Errorf("tiff: GeoKeyDirectory holds %d keys, but only %d values", n, len(dir))
+	}
+	for i := 0; i < n; i++ {
+		e := dir[4+4*i:]
+		k := GeoKey{ID: uint16(e[0])}
+		loc, count, val := e[1], e[2], e[3]
+		switch loc {
+		case 0:
+			k.Ints = []int{val}
+		case TagGeoKeyDirectory:
+			if val+count > len(dir) {
+				return fmt.Errorf("tiff: GeoKey %d values lie outside the GeoKeyDirectory", k.ID)
+			}
+			k.Ints = dir[val : val+count]
+		case TagGeoDoubleParams:
+			if val+count > len(doubles) {
+				return fmt.Errorf("tiff: GeoKey %d values lie outside GeoDoubleParams", k.ID)
+			}
+			k.Doubles = doubles[val : val+count]
+		case TagGeoASCIIParams:
+			if val+count > len(ascii) {
+				return fmt.Errorf("tiff: GeoKey %d value lies outside GeoASCIIParams", k.ID)
+			}
+			k.ASCII = strings.TrimRight(ascii[val:val+count], "|\x00")
+		default:
+			return fmt.Errorf("tiff: GeoKey %d stored in unknown tag 0x%04x", k.ID, loc)
+		}
+		g.Keys[k.ID] = k
+	}
+	return nil
+}
+
+// CRS returns the EPSG code of the coordinate reference system of the
+// model: that of the ProjectedCSTypeGeoKey if present, or else that of the
+// GeographicTypeGeoKey. ok is false if neither key is present. Code
+// UserDefinedGeoKeyValue means the system is described by other keys.
+func (g *GeoTIFF) CRS() (code int, ok bool) {
+	for _, id := range []uint16{ProjectedCSTypeGeoKey, GeographicTypeGeoKey} {
+		if k, found := g.Keys[id]; found && len(k.Ints) == 1 {
+			return k.Ints[0], true
+		}
+	}
+	return 0, false
+}
+
+// Bounds returns the model coordinates of the bounding box of the raster,
+// computed from the pixel scale and first tiepoint, or from the
+// transformation matrix, and the ImageWidth and ImageLength of the IFD.
+func (g *GeoTIFF) Bounds() (minX, minY, maxX, maxY float64, err error) {
+	if g.width <= 0 || g.height <= 0 {
+		return 0, 0, 0, 0, errors.New("tiff: image dimensions unknown")
+	}
+	var toModel func(i, j float64) (x, y float64)
+	switch {
+	case g.Transformation != nil:
+		m := g.Transformation
+		toModel = func(i, j float64) (float64, float64) {
+			return m[0]*i + m[1]*j + m[3], m[4]*i + m[5]*j + m[7]
+		}
+	case len(g.PixelScale) >= 2 && len(g.Tiepoints) > 0:
+		tp, s := g.Tiepoints[0], g.PixelScale
+		toModel = func(i, j float64) (float64, float64) {
+			return tp[3] + (i-tp[0])*s[0], tp[4] - (j-tp[1])*s[1]
+		}
+	default:
+		return 0, 0, 0, 0, errors.New("tiff: no pixel scale and tiepoint or transformation")
+	}
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	w, h := float64(g.width), float64(g.height)
+	for _, c := range [][2]float64{{0, 0}, {w, 0}, {0, h}, {w, h}} {
+		x, y := toModel(c[0], c[1])
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	return minX, minY, maxX, maxY, nil
+}
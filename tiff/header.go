@@ -0,0 +1,91 @@
+package tiff
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// A HeaderVariant is a file format based on tiff whose header holds a magic
+// number other than 42.
+type HeaderVariant struct {
+	Magic  uint16
+	Format string
+}
+
+// HeaderVariants lists the known tiff variants with other magic numbers. The
+// magic number is read in the byte order of the file, e.g. Olympus ORF files
+// start with "IIRO" or "MMOR".
+var HeaderVariants = []HeaderVariant{
+	{0x55, "Panasonic RW2"},
+	{0x4F52, "Olympus ORF"},
+	{0x5352, "Olympus ORF"},
+}
+
+// MagicPolicy decides which magic numbers Decode accepts in the tiff header.
+type MagicPolicy int
+
+const (
+	// MagicDefault accepts 42 and the 85 of Panasonic RW2 files.
+	MagicDefault MagicPolicy = iota
+	// MagicStrict only accepts 42, as required by the tiff specification.
+	MagicStrict
+	// MagicVariants accepts 42 and the magic numbers of HeaderVariants.
+	MagicVariants
+)
+
+// accepts reports whether p accepts the magic number magic.
+func (p MagicPolicy) accepts(magic uint16) bool {
+	switch {
+	case magic == 42:
+		return true
+	case p == MagicDefault:
+		return magic == 0x55
+	case p == MagicVariants:
+		_, ok := Variant(magic)
+		return ok
+	}
+	return false
+}
+
+// Variant returns the HeaderVariant with the given magic number.
+func Variant(magic uint16) (v HeaderVariant, ok bool) {
+	for _, v := range HeaderVariants {
+		if v.Magic == magic {
+			return v, true
+		}
+	}
+	return HeaderVariant{}, false
+}
+
+// ParseHeader returns the byte order and magic number of the tiff header at
+// the start of header. ok is false if header does not start with a byte
+// order mark followed by 42 or the magic number of one of HeaderVariants.
+func ParseHeader(header []byte) (order binary.ByteOrder, magic uint16, ok bool) {
+	if len(header) < 4 {
+		return nil, 0, false
+	}
+	switch string(header[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, 0, false
+	}
+	magic = order.Uint16(header[2:])
+	if magic != 42 {
+		if _, ok := Variant(magic); !ok {
+			return nil, 0, false
+		}
+	}
+	return order, magic, true
+}
+
+// magicError returns the error reported for a magic number p rejects.
+func (p MagicPolicy) magicError(magic uint16) error {
+	if v, ok := Variant(magic); ok {
+		return fmt.Errorf("tiff: magic number 0x%04x of %s files not accepted (see Decoder.Magic)", magic, v.Format)
+	}
+	return errors.New("tiff: could not find special tiff marker")
+}
@@ -0,0 +1,60 @@
+package tiff
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// maxLogText is the size above which the values of tags of undefined type
+// are logged by their size rather than as text.
+const maxLogText = 64
+
+// LogValue implements slog.LogValuer, logging t as a group of its tag ID,
+// data type, count and value. Integers and floats are logged as int64 and
+// float64 values, rationals as "num/den" strings, and tags holding several
+// values as slices of these. Values of undefined type are logged as text if
+// they are short printable ASCII text, and by their size otherwise, so that
+// large binary values such as maker notes are not written to logs.
+func (t *Tag) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", fmt.Sprintf("0x%04x", t.Id)),
+		slog.String("type", typeName(t.Type)),
+		slog.Int64("count", int64(t.Count)),
+		slog.Any("value", t.logValue()),
+	)
+}
+
+func (t *Tag) logValue() interface{} {
+	switch t.format {
+	case StringVal:
+		s, _ := t.StringVal()
+		return strings.TrimRight(s, "\x00 ")
+	case UndefVal, OtherVal:
+		s := strings.TrimRight(string(t.Val), "\x00 ")
+		if len(s) > maxLogText || strings.IndexFunc(s, func(r rune) bool { return r < 0x20 || r > 0x7E }) >= 0 {
+			return fmt.Sprintf("(%d bytes)", len(t.Val))
+		}
+		return s
+	}
+
+	n := int(t.Count)
+	var vals []interface{}
+	for i := 0; i < n; i++ {
+		switch t.format {
+		case IntVal:
+			v, _ := t.Int64(i)
+			vals = append(vals, v)
+		case FloatVal:
+			v, _ := t.Float(i)
+			vals = append(vals, v)
+		case RatVal:
+			num, den, _ := t.Rat2(i)
+			vals = append(vals, fmt.Sprintf("%d/%d", num, den))
+		}
+	}
+	if len(vals) == 1 {
+		return vals[0]
+	}
+	return vals
+}
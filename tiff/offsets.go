@@ -0,0 +1,146 @@
+package tiff
+
+import "fmt"
+
+// SetInt sets the i'th value of the integer tag t to v. Val is updated in
+// place and keeps the byte order of t, so that it can be written back over
+// the value the tag was decoded from (see ValueOffset) or encoded as usual. It
+// returns an error if the tag's Format is not IntVal or v does not fit its
+// data type. It panics if i is out of range.
+func (t *Tag) SetInt(i int, v int64) error {
+	if t.format != IntVal {
+		return t.typeErr(IntVal)
+	}
+	size, ok := typeSize[t.Type]
+	if !ok {
+		return fmt.Errorf("tiff: cannot set the values of tag 0x%04x of data type %v", t.Id, typeName(t.Type))
+	}
+	if min, max := intRange(t.Type); v < min || v > max {
+		return fmt.Errorf("tiff: value %d out of range for tag 0x%04x of data type %v", v, t.Id, typeName(t.Type))
+	}
+	b := t.Val[int(size)*i : int(size)*(i+1)]
+	if size == 1 {
+		b[0] = byte(v)
+	} else {
+		copy(b, appendUint(nil, t.order, int(size), uint64(v)))
+	}
+	t.intVals[i] = v
+	return nil
+}
+
+// A DataChunk is a byte range of the image data referenced by an IFD: a
+// strip, a tile or a JPEG thumbnail.
+type DataChunk struct {
+	// OffsetTag and CountTag are the IDs of the tags giving the offset and
+	// the size of the chunk, e.g. StripOffsets and StripByteCounts, and
+	// Index is the index of its values in them.
+	OffsetTag, CountTag uint16
+	Index               int
+	// Offset is the offset of the chunk in the tiff data, and Size its
+	// length in bytes.
+	Offset, Size int64
+}
+
+// DataChunks returns the image data referenced by d through the
+// StripOffsets and StripByteCounts, TileOffsets and TileByteCounts, and
+// JPEGInterchangeFormat and JPEGInterchangeFormatLength tags, in that order.
+// An error is returned if the offset and count tags of a pair do not hold
+// the same number of integers.
+func (d *Dir) DataChunks() ([]DataChunk, error) {
+	var chunks []DataChunk
+	for _, p := range dataTags {
+		_, cs, err := dirChunks(d, p)
+		if err != nil {
+			return nil, err
+		}
+		for i, c := range cs {
+			chunks = append(chunks, DataChunk{p[0], p[1], i, c.off, c.n})
+		}
+	}
+	return chunks, nil
+}
+
+// dirChunks returns the offset tag of the data tag pair p of d and the
+// chunks they reference.
+func dirChunks(d *Dir, p [2]uint16) (*Tag, []chunk, error) {
+	offs, counts := d.tag(p[0]), d.tag(p[1])
+	if offs == nil || counts == nil || offs.Count == 0 {
+		return nil, nil, nil
+	}
+	if offs.Count != counts.Count {
+		return nil, nil, fmt.Errorf("tiff: tag 0x%04x has %d values, but tag 0x%04x has %d", p[0], offs.Count, p[1], counts.Count)
+	}
+	chunks := make([]chunk, offs.Count)
+	for i := range chunks {
+		off, err := offs.Int64(i)
+		if err != nil {
+			return nil, nil, err
+		}
+		n, err := counts.Int64(i)
+		if err != nil {
+			return nil, nil, err
+		}
+		chunks[i] = chunk{off, n}
+	}
+	return offs, chunks, nil
+}
+
+// ShiftDataOffsets adds delta to the offsets of the image data referenced by
+// d that lie at or after off, as needed after inserting delta bytes at off
+// in the tiff data (or removing -delta bytes), e.g. to grow the metadata of
+// a file. The offset tags are updated with SetInt, keeping their data type
+// and size, and the tags changed are returned so that they can be written
+// back at their ValueOffset for in-place edits. The offsets of IFDs and of
+// tag values are not changed. If an offset would not fit its tag, an error
+// is returned and no tag is changed.
+func (d *Dir) ShiftDataOffsets(off, delta int64) ([]*Tag, error) {
+	return shiftDataOffsets([]*Dir{d}, off, delta)
+}
+
+// ShiftDataOffsets is like Dir.ShiftDataOffsets, for all IFDs of the IFD
+// chain of tf. IFDs outside the chain, such as the IFDs listed by a
+// SubIFDs tag, must be shifted separately.
+func (tf *Tiff) ShiftDataOffsets(off, delta int64) ([]*Tag, error) {
+	return shiftDataOffsets(tf.Dirs, off, delta)
+}
+
+func shiftDataOffsets(dirs []*Dir, off, delta int64) ([]*Tag, error) {
+	type update struct {
+		tag *Tag
+		i   int
+		v   int64
+	}
+	var updates []update
+	for _, d := range dirs {
+		for _, p := range dataTags {
+			tag, chunks, err := dirChunks(d, p)
+			if err != nil {
+				return nil, err
+			}
+			for i, c := range chunks {
+				if c.off < off {
+					continue
+				}
+				if _, ok := typeSize[tag.Type]; !ok {
+					return nil, fmt.Errorf("tiff: cannot set the values of tag 0x%04x of data type %v", tag.Id, typeName(tag.Type))
+				}
+				v := c.off + delta
+				if min, max := intRange(tag.Type); v < min || v > max || v < 0 {
+					return nil, fmt.Errorf("tiff: shifted offset %d does not fit tag 0x%04x of data type %v", v, tag.Id, typeName(tag.Type))
+				}
+				updates = append(updates, update{tag, i, v})
+			}
+		}
+	}
+
+	var changed []*Tag
+	for _, u := range updates {
+		if err := u.tag.SetInt(u.i, u.v); err != nil {
+			return changed, err
+		}
+		if len(changed) == 0 || changed[len(changed)-1] != u.tag {
+			changed = append(changed, u.tag)
+		}
+	}
+	return changed, nil
+}
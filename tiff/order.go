@@ -0,0 +1,103 @@
+package tiff
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// ErrOrderMismatch is wrapped by the errors returned by CheckOrder when the
+// byte order declared by a tiff header is inconsistent with the content of
+// the tiff data.
+var ErrOrderMismatch = errors.New("tiff: byte order inconsistent with content")
+
+// orderRanges holds the valid values of SHORT tags of IFD0 with few valid
+// values, which are out of range when read in the wrong byte order.
+var orderRanges = map[uint16][2]uint16{
+	0x010A: {1, 2}, // FillOrder
+	0x0112: {1, 8}, // Orientation
+	0x011C: {1, 2}, // PlanarConfiguration
+	0x0128: {1, 3}, // ResolutionUnit
+	0x0213: {1, 2}, // YCbCrPositioning
+}
+
+// CheckOrder returns the byte order suggested by the content of the tiff
+// data in data, to help repair files whose header is corrupted: the order in
+// which the offset to the first IFD points to a plausible IFD, preferring
+// the order declared by the header. An error wrapping ErrOrderMismatch is
+// returned with the suggested order if the header declares another order or
+// none, or if the values of tags of that IFD with few valid values (such as
+// Orientation) only make sense swapped, which reveals writers mixing byte
+// orders. Another error is returned if no plausible IFD is found.
+func CheckOrder(data []byte) (binary.ByteOrder, error) {
+	if len(data) < 8 {
+		return nil, errors.New("tiff: data too short for a tiff header")
+	}
+	var declared binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		declared = binary.LittleEndian
+	case "MM":
+		declared = binary.BigEndian
+	}
+	orders := []binary.ByteOrder{binary.LittleEndian, binary.BigEndian}
+	if declared == binary.BigEndian {
+		orders[0], orders[1] = orders[1], orders[0]
+	}
+	var order binary.ByteOrder
+	for _, o := range orders {
+		if plausibleDir(data, int64(o.Uint32(data[4:])), o, false) {
+			order = o
+			break
+		}
+	}
+	if order == nil {
+		return nil, errors.New("tiff: no plausible IFD in either byte order")
+	}
+	if order != declared {
+		return order, fmt.Errorf("%w: header declares %q, but the first IFD is in %v order (see Decoder.Order)", ErrOrderMismatch, data[:2], order)
+	}
+
+	off := int64(order.Uint32(data[4:]))
+	var valid int
+	var swapped []string
+	for i := int64(0); i < int64(order.Uint16(data[off:])); i++ {
+		e := data[off+2+12*i:]
+		id := order.Uint16(e)
+		r, ok := orderRanges[id]
+		if !ok || DataType(order.Uint16(e[2:])) != DTShort || order.Uint32(e[4:]) != 1 {
+			continue
+		}
+		v := order.Uint16(e[8:])
+		switch sv := bits.ReverseBytes16(v); {
+		case v >= r[0] && v <= r[1]:
+			valid++
+		case sv >= r[0] && sv <= r[1]:
+			swapped = append(swapped, fmt.Sprintf("0x%04x", id))
+		}
+	}
+	if len(swapped) > valid {
+		return order, fmt.Errorf("%w: the values of tags %s of the first IFD only make sense swapped", ErrOrderMismatch, strings.Join(swapped, ", "))
+	}
+	return order, nil
+}
+
+// orderError returns the error of CheckOrder if the header of data is
+// invalid because its byte order mark is corrupted, i.e. if its content is
+// in the other byte order, and err otherwise.
+func (dec *Decoder) orderError(data []byte, err error) error {
+	if dec.Order != nil {
+		return err
+	}
+	order, oerr := CheckOrder(data)
+	mark := "II"
+	if order == binary.BigEndian {
+		mark = "MM"
+	}
+	if errors.Is(oerr, ErrOrderMismatch) && string(data[:2]) != mark {
+		return oerr
+	}
+	return err
+}
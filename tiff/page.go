@@ -0,0 +1,104 @@
+package tiff
+
+import "fmt"
+
+// Tag IDs of the tags describing the pages of multi-page files.
+const (
+	TagNewSubfileType = 0x00FE
+	TagImageWidth     = 0x0100
+	TagImageLength    = 0x0101
+	TagPageName       = 0x011D
+	TagPageNumber     = 0x0129
+)
+
+// A Page is one image of a multi-page file, such as a scanned document or a
+// fax, described by one IFD of the IFD chain.
+type Page struct {
+	// Index is the position of the IFD of the page in the IFD chain,
+	// starting at 0.
+	Index int
+	Dir   *Dir
+	// Width and Height are the ImageWidth and ImageLength of the page, or
+	// 0 if unknown.
+	Width, Height int
+	// Name is the PageName of the page, e.g. a document name.
+	Name string
+	// Number and Total are the page number, starting at 0, and the total
+	// number of pages given by the PageNumber tag, and are -1 if it is
+	// missing. Total is 0 if the writer did not know it.
+	Number, Total int
+	// Reduced reports that NewSubfileType marks the page as a reduced
+	// resolution version of another page, such as a thumbnail.
+	Reduced bool
+}
+
+func (p *Page) String() string {
+	s := fmt.Sprintf("IFD%d: %dx%d", p.Index, p.Width, p.Height)
+	switch {
+	case p.Total > 0:
+		s += fmt.Sprintf(", page %d of %d", p.Number+1, p.Total)
+	case p.Number >= 0:
+		s += fmt.Sprintf(", page %d", p.Number+1)
+	}
+	return s
+}
+
+// NumPages returns the number of pages of tf, one per IFD of the IFD chain.
+func (tf *Tiff) NumPages() int {
+	return len(tf.Dirs)
+}
+
+// Page returns the page described by the IFD at index n of the IFD chain,
+// which is not necessarily the page numbered n by its PageNumber tag.
+func (tf *Tiff) Page(n int) (*Page, error) {
+	if n < 0 || n >= len(tf.Dirs) {
+		return nil, fmt.Errorf("tiff: page %d out of range, file has %d pages", n, len(tf.Dirs))
+	}
+	d := tf.Dirs[n]
+	p := &Page{Index: n, Dir: d, Number: -1, Total: -1}
+	p.Width = dirInt(d, TagImageWidth)
+	p.Height = dirInt(d, TagImageLength)
+	if t := d.tag(TagPageName); t != nil {
+		p.Name, _ = t.StringVal()
+	}
+	if t := d.tag(TagPageNumber); t != nil && t.Count == 2 && t.Format() == IntVal {
+		p.Number, _ = t.Int(0)
+		p.Total, _ = t.Int(1)
+	}
+	p.Reduced = dirInt(d, TagNewSubfileType)&1 != 0
+	return p, nil
+}
+
+// Pages returns the pages of tf in the order of the IFD chain.
+func (tf *Tiff) Pages() []*Page {
+	pages := make([]*Page, len(tf.Dirs))
+	for i := range pages {
+		pages[i], _ = tf.Page(i)
+	}
+	return pages
+}
+
+// EachPage calls fn for each page of tf that is not a reduced resolution
+// version of another page, in the order of the IFD chain, until fn returns
+// an error, which is returned.
+func (tf *Tiff) EachPage(fn func(p *Page) error) error {
+	for _, p := range tf.Pages() {
+		if p.Reduced {
+			continue
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dirInt returns the first value of the integer tag id of d, or 0 if d does
+// not hold it.
+func dirInt(d *Dir, id uint16) int {
+	if t := d.tag(id); t != nil && t.Count > 0 && t.Format() == IntVal {
+		v, _ := t.Int(0)
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package tiff
+
+import "encoding/binary"
+
+// maxRecoverTags is the largest entry count a scanned IFD may have to be
+// considered plausible.
+const maxRecoverTags = 1000
+
+// plausibleDir reports whether data holds an IFD at offset off that looks
+// sane: at least one entry, all entries and the next IFD offset fit in
+// data, and each entry has a known data type and a value that fits in data.
+// If sorted is true, the tag IDs must also be in ascending order, as the
+// tiff specification requires, and the counts nonzero, which rules out most
+// random data when scanning; tags with a count of zero are valid otherwise.
+func plausibleDir(data []byte, off int64, order binary.ByteOrder, sorted bool) bool {
+	if off < 8 || off+2 > int64(len(data)) {
+		return false
+	}
+	n := int64(order.Uint16(data[off:]))
+	if n == 0 || n > maxRecoverTags || off+2+12*n+4 > int64(len(data)) {
+		return false
+	}
+	var prev uint16
+	for i := int64(0); i < n; i++ {
+		e := data[off+2+12*i:]
+		id := order.Uint16(e)
+		if sorted && i > 0 && id <= prev {
+			return false
+		}
+		prev = id
+		size := uint64(sizeOf(DataType(order.Uint16(e[2:]))))
+		count := uint64(order.Uint32(e[4:]))
+		if size == 0 || (sorted && count == 0) {
+			return false
+		}
+		if n := size * count; n > 4 && uint64(order.Uint32(e[8:]))+n > uint64(len(data)) {
+			return false
+		}
+	}
+	return true
+}
+
+// scanDir returns the offset of the first plausible IFD in data, trying the
+// word aligned offsets after the tiff header.
+func scanDir(data []byte, order binary.ByteOrder) (int64, bool) {
+	for off := int64(8); off+2 <= int64(len(data)); off += 2 {
+		if plausibleDir(data, off, order, true) {
+			return off, true
+		}
+	}
+	return 0, false
+}
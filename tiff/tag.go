@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"strings"
 	"unicode"
@@ -85,6 +86,71 @@ var typeSize = map[DataType]uint32{
 	DTDouble:    8,
 }
 
+// Values holds the values of a tag decoded by a TypeHandler. Only the field
+// matching the handler's Format is used: Ints for IntVal, Floats for
+// FloatVal, Rats (numerator-denominator pairs) for RatVal and String for
+// StringVal. Numeric formats must hold one value per tag component.
+type Values struct {
+	Ints   []int64
+	Floats []float64
+	Rats   [][2]int64
+	String string
+}
+
+// A TypeHandler describes a data type not defined by the tiff specification,
+// such as the IFD type (13) or vendor specific types, so that tags of that
+// type can be decoded.
+type TypeHandler struct {
+	// Name is the name of the type, used in error messages.
+	Name string
+	// Size is the size in bytes of a single component of the type.
+	Size uint32
+	// Format is the format the components are decoded into.
+	Format Format
+	// Decode decodes the count components of a tag stored in val in the
+	// given byte order. It is not called for the UndefVal and OtherVal
+	// formats, which leave the value undecoded and may leave Decode nil.
+	Decode func(val []byte, count uint32, order binary.ByteOrder) (Values, error)
+}
+
+var typeHandlers = map[DataType]TypeHandler{}
+
+// RegisterType registers h to decode the tags of data type dt, replacing any
+// handler registered before. Tags of types without a handler fail to decode.
+// RegisterType panics if dt is one of the types defined by the tiff
+// specification (DTByte through DTDouble) or if h.Size is zero. Unlike
+// exif.RegisterParsers, it is not safe for concurrent use with decoding and
+// should be called before decoding starts, e.g. from an init function.
+func RegisterType(dt DataType, h TypeHandler) {
+	if _, ok := typeSize[dt]; ok {
+		panic(fmt.Sprintf("tiff: cannot register handler for standard data type %v", dt))
+	}
+	if h.Size == 0 {
+		panic(fmt.Sprintf("tiff: handler for data type %v has zero size", dt))
+	}
+	typeHandlers[dt] = h
+}
+
+// sizeOf returns the size in bytes of a single component of type dt, or
+// zero for unknown types.
+func sizeOf(dt DataType) uint32 {
+	if n, ok := typeSize[dt]; ok {
+		return n
+	}
+	return typeHandlers[dt].Size
+}
+
+// typeName returns the name of data type dt.
+func typeName(dt DataType) string {
+	if name, ok := typeNames[dt]; ok {
+		return name
+	}
+	if h, ok := typeHandlers[dt]; ok {
+		return h.Name
+	}
+	return fmt.Sprintf("unknown type %d", uint16(dt))
+}
+
 // Tag reflects the parsed content of a tiff IFD tag.
 type Tag struct {
 	// Id is the 2-byte tiff tag identifier.
@@ -98,14 +164,18 @@ type Tag struct {
 	Val []byte
 	// ValOffset holds byte offset of the tag value w.r.t. the beginning of the
 	// reader it was decoded from. Zero if the tag value fit inside the offset
-	// field.
+	// field (see ValueOffset).
 	ValOffset uint32
 
+	entry     int64
+	inline    bool
+	hasOff    bool // the offset of the value is known
 	order     binary.ByteOrder
 	intVals   []int64
 	floatVals []float64
 	ratVals   [][]int64
 	strVal    string
+	charset   Charset
 	format    Format
 }
 
@@ -114,188 +184,309 @@ type Tag struct {
 // generally be relative to the beginning of the tiff structure (not relative
 // to the beginning of the tag).
 func DecodeTag(r ReadAtReader, order binary.ByteOrder) (*Tag, error) {
-	t := new(Tag)
-	t.order = order
+	return new(Decoder).DecodeTag(r, order)
+}
 
-	err := binary.Read(r, order, &t.Id)
-	if err != nil {
+// DecodeTag is like the package-level DecodeTag function, but honors the
+// limits and hook set in dec.
+func (dec *Decoder) DecodeTag(r ReadAtReader, order binary.ByteOrder) (*Tag, error) {
+	entryOffset := readerOffset(r)
+	entry := make([]byte, 12)
+	n, err := io.ReadFull(r, entry)
+	switch {
+	case n < 2:
 		return nil, errors.New("tiff: tag id read failed: " + err.Error())
-	}
-
-	err = binary.Read(r, order, &t.Type)
-	if err != nil {
+	case n < 4:
 		return nil, errors.New("tiff: tag type read failed: " + err.Error())
+	case n < 8:
+		return nil, errors.New("tiff: tag component count read failed: " + err.Error())
+	}
+	t := new(Tag)
+	t.Id = order.Uint16(entry)
+	t.Type = DataType(order.Uint16(entry[2:]))
+	t.Count = order.Uint32(entry[4:])
+	if n < 12 {
+		return t, errors.New("tiff: tag offset read failed: " + err.Error())
 	}
+	return t, dec.decodeEntry(t, entry, r, entryOffset, order, nil)
+}
 
-	err = binary.Read(r, order, &t.Count)
-	if err != nil {
-		return nil, errors.New("tiff: tag component count read failed: " + err.Error())
+// decodeEntry decodes the 12-byte IFD entry at entryOffset (or -1 if
+// unknown) into t, reading values stored outside of the entry from r. If
+// inline is not nil, values stored inside the entry are copied to it rather
+// than to a new slice; it must have room for 4 bytes.
+func (dec *Decoder) decodeEntry(t *Tag, entry []byte, r io.ReaderAt, entryOffset int64, order binary.ByteOrder, inline []byte) error {
+	t.order = order
+	if entryOffset >= 0 {
+		t.entry = entryOffset
 	}
+	t.Id = order.Uint16(entry)
+	t.Type = DataType(order.Uint16(entry[2:]))
+	t.Count = order.Uint32(entry[4:])
 
 	// There seems to be a relatively common corrupt tag which has a Count of
 	// MaxUint32. This is probably not a valid value, so return early.
 	if t.Count == 1<<32-1 {
-		return t, errors.New("invalid Count offset in tag")
+		return errors.New("invalid Count offset in tag")
 	}
 
-	valLen := typeSize[t.Type] * t.Count
-	if valLen == 0 {
-		return t, errors.New("zero length tag value")
+	size := uint64(sizeOf(t.Type)) * uint64(t.Count)
+	if max := dec.Limits.MaxTagValueSize; max > 0 && size > uint64(max) {
+		return fmt.Errorf("%w: tag 0x%04x value is %d bytes, limit is %d", ErrLimitExceeded, t.Id, size, max)
+	}
+	// A value this large cannot fit in a tiff file; the count is corrupt.
+	if size > math.MaxUint32 {
+		return ErrShortReadTagValue
 	}
 
-	if valLen > 4 {
-		binary.Read(r, order, &t.ValOffset)
+	if sizeOf(t.Type) == 0 {
+		return fmt.Errorf("tiff: tag 0x%04x has unknown data type %v", t.Id, uint16(t.Type))
+	}
 
-		// Use a bytes.Buffer so we don't allocate a huge slice if the tag
-		// is corrupt.
-		var buff bytes.Buffer
-		sr := io.NewSectionReader(r, int64(t.ValOffset), int64(valLen))
-		n, err := io.Copy(&buff, sr)
+	// Tags without values (Count 0) are decoded as empty values.
+	valLen := uint32(size)
+	n := decodedSize(t.Type, t.Count)
+	if valLen > 4 {
+		n += int64(valLen)
+	}
+	if err := dec.alloc(n); err != nil {
+		return err
+	}
+	if valLen > 4 {
+		t.ValOffset = order.Uint32(entry[8:])
+		t.hasOff = true
+		val, err := readValue(r, int64(t.ValOffset), int64(valLen), dec.Buffer)
 		if err != nil {
-			return t, errors.New("tiff: tag value read failed: " + err.Error())
-		} else if n != int64(valLen) {
-			return t, ErrShortReadTagValue
+			return err
 		}
-		t.Val = buff.Bytes()
-
+		t.Val = val
 	} else {
-		val := make([]byte, valLen)
-		if _, err = io.ReadFull(r, val); err != nil {
-			return t, errors.New("tiff: tag offset read failed: " + err.Error())
-		}
 		// ignore padding.
-		if _, err = io.ReadFull(r, make([]byte, 4-valLen)); err != nil {
-			return t, errors.New("tiff: tag offset read failed: " + err.Error())
+		if inline == nil {
+			inline = make([]byte, valLen)
 		}
+		t.Val = inline[:valLen:valLen]
+		copy(t.Val, entry[8:])
+		t.inline = true
+		t.hasOff = entryOffset >= 0
+	}
 
-		t.Val = val
+	if dec.Hook != nil && !dec.Hook(RawTag{dec.dir, t.Id, t.Type, t.Count, entryOffset, order, t.Val}) {
+		return ErrSkipped
+	}
+	t.charset = dec.Charset
+	return t.convertVals(dec.Buffer)
+}
+
+// readValue reads the n bytes of a tag value at offset off of r, into memory
+// of b if not nil.
+func readValue(r io.ReaderAt, off, n int64, b *Buffer) ([]byte, error) {
+	if sr, ok := r.(interface{ Size() int64 }); ok {
+		// The value can be read in place without risking a huge
+		// allocation for corrupt tags.
+		if off+n > sr.Size() {
+			return nil, ErrShortReadTagValue
+		}
+		val := b.newBytes(int(n))
+		if m, err := r.ReadAt(val, off); m != len(val) {
+			if err != nil && err != io.EOF {
+				return nil, errors.New("tiff: tag value read failed: " + err.Error())
+			}
+			return nil, ErrShortReadTagValue
+		}
+		return val, nil
+	}
+
+	// Use a bytes.Buffer so we don't allocate a huge slice if the tag
+	// is corrupt.
+	var buff bytes.Buffer
+	sr := io.NewSectionReader(r, off, n)
+	m, err := io.Copy(&buff, sr)
+	if err != nil {
+		return nil, errors.New("tiff: tag value read failed: " + err.Error())
+	} else if m != n {
+		return nil, ErrShortReadTagValue
+	}
+	return buff.Bytes(), nil
+}
+
+// readerOffset returns the current read offset of r, or -1 if it cannot be
+// determined.
+func readerOffset(r io.Reader) int64 {
+	s, ok := r.(io.Seeker)
+	if !ok {
+		return -1
+	}
+	off, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1
+	}
+	return off
+}
+
+// RawBytes returns the undecoded bytes of the tag's value as stored in the
+// tiff data, in the byte order reported by Order. Values shorter than four
+// bytes do not include the padding of the IFD entry's offset field. The
+// returned slice must not be modified.
+func (t *Tag) RawBytes() []byte { return t.Val }
+
+// Inlined reports whether the tag's value was stored inside the offset field
+// of its IFD entry rather than elsewhere in the tiff data.
+func (t *Tag) Inlined() bool { return t.inline }
+
+// ValueOffset returns the byte offset of the tag's value w.r.t. the beginning
+// of the reader it was decoded from: ValOffset for values stored outside the
+// IFD entry, and the offset of the entry's offset field for inlined values
+// (see Inlined). ok is false if the offset is unknown, i.e. for inlined values
+// decoded from a reader whose position could not be determined and for tags
+// created with NewTag.
+func (t *Tag) ValueOffset() (off int64, ok bool) {
+	if t.inline {
+		return t.entry + 8, t.hasOff
+	}
+	return int64(t.ValOffset), t.hasOff
+}
+
+// EntryOffset returns the byte offset of the tag's 12-byte IFD entry w.r.t.
+// the beginning of the reader it was decoded from, or zero if it could not
+// be determined (e.g. for tags created with NewTag).
+func (t *Tag) EntryOffset() int64 { return t.entry }
+
+// Order returns the byte order the tag's value is encoded in.
+func (t *Tag) Order() binary.ByteOrder { return t.order }
+
+// tagBinaryLen is the length of the fixed size part of a tag encoded by
+// MarshalBinary: byte order, flags, id, type, count, value offset and entry
+// offset.
+const tagBinaryLen = 1 + 1 + 2 + 2 + 4 + 4 + 8
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding the tag
+// including its byte order and raw value bytes so that it can be cached and
+// restored with UnmarshalBinary without the original tiff data.
+func (t *Tag) MarshalBinary() ([]byte, error) {
+	b := make([]byte, tagBinaryLen, tagBinaryLen+len(t.Val))
+	b[0] = 'M'
+	if t.order == binary.LittleEndian {
+		b[0] = 'I'
+	}
+	if t.inline {
+		b[1] |= 1
 	}
+	if t.hasOff {
+		b[1] |= 2
+	}
+	binary.BigEndian.PutUint16(b[2:], t.Id)
+	binary.BigEndian.PutUint16(b[4:], uint16(t.Type))
+	binary.BigEndian.PutUint32(b[6:], t.Count)
+	binary.BigEndian.PutUint32(b[10:], t.ValOffset)
+	binary.BigEndian.PutUint64(b[14:], uint64(t.entry))
+	return append(b, t.Val...), nil
+}
 
-	return t, t.convertVals()
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring a tag
+// encoded by MarshalBinary.
+func (t *Tag) UnmarshalBinary(data []byte) error {
+	if len(data) < tagBinaryLen {
+		return errors.New("tiff: binary tag data too short")
+	}
+	*t = Tag{}
+	switch data[0] {
+	case 'I':
+		t.order = binary.LittleEndian
+	case 'M':
+		t.order = binary.BigEndian
+	default:
+		return errors.New("tiff: invalid byte order in binary tag data")
+	}
+	t.inline = data[1]&1 != 0
+	t.hasOff = data[1]&2 != 0
+	t.Id = binary.BigEndian.Uint16(data[2:])
+	t.Type = DataType(binary.BigEndian.Uint16(data[4:]))
+	t.Count = binary.BigEndian.Uint32(data[6:])
+	t.ValOffset = binary.BigEndian.Uint32(data[10:])
+	t.entry = int64(binary.BigEndian.Uint64(data[14:]))
+	if uint64(len(data)-tagBinaryLen) != uint64(sizeOf(t.Type))*uint64(t.Count) {
+		return errors.New("tiff: binary tag value length does not match its type and count")
+	}
+	t.Val = append([]byte(nil), data[tagBinaryLen:]...)
+	return t.convertVals(nil)
 }
 
-func (t *Tag) convertVals() error {
-	r := bytes.NewReader(t.Val)
+// convertVals decodes the value of the tag, allocating the decoded values
+// from b if not nil.
+func (t *Tag) convertVals(b *Buffer) error {
+	if size, ok := typeSize[t.Type]; ok && t.Type != DTAscii && t.Type != DTUndefined {
+		if uint64(len(t.Val)) < uint64(size)*uint64(t.Count) {
+			return io.ErrUnexpectedEOF
+		}
+	}
+	val, order := t.Val, t.order
 
 	switch t.Type {
 	case DTAscii:
 		if len(t.Val) <= 0 {
 			break
 		}
-		nullPos := bytes.IndexByte(t.Val, 0)
-		if nullPos == -1 {
-			t.strVal = string(t.Val)
-		} else {
-			// ignore all trailing NULL bytes, in case of a broken t.Count
-			t.strVal = string(t.Val[:nullPos])
-		}
+		t.strVal = t.charset.Decode(t.rawString())
 	case DTByte:
-		var v uint8
-		t.intVals = make([]int64, int(t.Count))
+		t.intVals = b.newInts(int(t.Count))
 		for i := range t.intVals {
-			err := binary.Read(r, t.order, &v)
-			if err != nil {
-				return err
-			}
-			t.intVals[i] = int64(v)
+			t.intVals[i] = int64(val[i])
 		}
 	case DTShort:
-		var v uint16
-		t.intVals = make([]int64, int(t.Count))
+		t.intVals = b.newInts(int(t.Count))
 		for i := range t.intVals {
-			err := binary.Read(r, t.order, &v)
-			if err != nil {
-				return err
-			}
-			t.intVals[i] = int64(v)
+			t.intVals[i] = int64(order.Uint16(val[2*i:]))
 		}
 	case DTLong:
-		var v uint32
-		t.intVals = make([]int64, int(t.Count))
+		t.intVals = b.newInts(int(t.Count))
 		for i := range t.intVals {
-			err := binary.Read(r, t.order, &v)
-			if err != nil {
-				return err
-			}
-			t.intVals[i] = int64(v)
+			t.intVals[i] = int64(order.Uint32(val[4*i:]))
 		}
 	case DTSByte:
-		var v int8
-		t.intVals = make([]int64, int(t.Count))
+		t.intVals = b.newInts(int(t.Count))
 		for i := range t.intVals {
-			err := binary.Read(r, t.order, &v)
-			if err != nil {
-				return err
-			}
-			t.intVals[i] = int64(v)
+			t.intVals[i] = int64(int8(val[i]))
 		}
 	case DTSShort:
-		var v int16
-		t.intVals = make([]int64, int(t.Count))
+		t.intVals = b.newInts(int(t.Count))
 		for i := range t.intVals {
-			err := binary.Read(r, t.order, &v)
-			if err != nil {
-				return err
-			}
-			t.intVals[i] = int64(v)
+			t.intVals[i] = int64(int16(order.Uint16(val[2*i:])))
 		}
 	case DTSLong:
-		var v int32
-		t.intVals = make([]int64, int(t.Count))
+		t.intVals = b.newInts(int(t.Count))
 		for i := range t.intVals {
-			err := binary.Read(r, t.order, &v)
-			if err != nil {
-				return err
-			}
-			t.intVals[i] = int64(v)
+			t.intVals[i] = int64(int32(order.Uint32(val[4*i:])))
 		}
 	case DTRational:
-		t.ratVals = make([][]int64, int(t.Count))
+		t.ratVals = b.newRats(int(t.Count))
+		flat := b.newInts(2 * int(t.Count))
 		for i := range t.ratVals {
-			var n, d uint32
-			err := binary.Read(r, t.order, &n)
-			if err != nil {
-				return err
-			}
-			err = binary.Read(r, t.order, &d)
-			if err != nil {
-				return err
-			}
-			t.ratVals[i] = []int64{int64(n), int64(d)}
+			flat[2*i] = int64(order.Uint32(val[8*i:]))
+			flat[2*i+1] = int64(order.Uint32(val[8*i+4:]))
+			t.ratVals[i] = flat[2*i : 2*i+2 : 2*i+2]
 		}
 	case DTSRational:
-		t.ratVals = make([][]int64, int(t.Count))
+		t.ratVals = b.newRats(int(t.Count))
+		flat := b.newInts(2 * int(t.Count))
 		for i := range t.ratVals {
-			var n, d int32
-			err := binary.Read(r, t.order, &n)
-			if err != nil {
-				return err
-			}
-			err = binary.Read(r, t.order, &d)
-			if err != nil {
-				return err
-			}
-			t.ratVals[i] = []int64{int64(n), int64(d)}
+			flat[2*i] = int64(int32(order.Uint32(val[8*i:])))
+			flat[2*i+1] = int64(int32(order.Uint32(val[8*i+4:])))
+			t.ratVals[i] = flat[2*i : 2*i+2 : 2*i+2]
 		}
 	case DTFloat: // float32
 		t.floatVals = make([]float64, int(t.Count))
 		for i := range t.floatVals {
-			var v float32
-			err := binary.Read(r, t.order, &v)
-			if err != nil {
-				return err
-			}
-			t.floatVals[i] = float64(v)
+			t.floatVals[i] = float64(math.Float32frombits(order.Uint32(val[4*i:])))
 		}
 	case DTDouble:
 		t.floatVals = make([]float64, int(t.Count))
 		for i := range t.floatVals {
-			var u float64
-			err := binary.Read(r, t.order, &u)
-			if err != nil {
-				return err
-			}
-			t.floatVals[i] = u
+			t.floatVals[i] = math.Float64frombits(order.Uint64(val[8*i:]))
+		}
+	default:
+		if h, ok := typeHandlers[t.Type]; ok {
+			return t.convertCustom(h)
 		}
 	}
 
@@ -317,12 +508,52 @@ func (t *Tag) convertVals() error {
 	return nil
 }
 
+// convertCustom decodes the value of a tag whose type is registered with
+// RegisterType.
+func (t *Tag) convertCustom(h TypeHandler) error {
+	t.format = h.Format
+	if h.Format == UndefVal || h.Format == OtherVal {
+		return nil
+	}
+	if h.Decode == nil {
+		t.format = OtherVal
+		return fmt.Errorf("tiff: no decode function for data type %v", h.Name)
+	}
+	v, err := h.Decode(t.Val, t.Count, t.order)
+	if err != nil {
+		t.format = OtherVal
+		return fmt.Errorf("tiff: decoding %v value failed: %v", h.Name, err)
+	}
+
+	var n int
+	switch h.Format {
+	case IntVal:
+		t.intVals, n = v.Ints, len(v.Ints)
+	case FloatVal:
+		t.floatVals, n = v.Floats, len(v.Floats)
+	case RatVal:
+		t.ratVals = make([][]int64, len(v.Rats))
+		for i, r := range v.Rats {
+			t.ratVals[i] = []int64{r[0], r[1]}
+		}
+		n = len(v.Rats)
+	case StringVal:
+		t.strVal = v.String
+		return nil
+	}
+	if n != int(t.Count) {
+		t.format = OtherVal
+		return fmt.Errorf("tiff: %v handler decoded %d values, want %d", h.Name, n, t.Count)
+	}
+	return nil
+}
+
 // Format returns a value indicating which method can be called to retrieve the
 // tag's value properly typed (e.g. integer, rational, etc.).
 func (t *Tag) Format() Format { return t.format }
 
 func (t *Tag) typeErr(to Format) error {
-	return &wrongFmtErr{typeNames[t.Type], formatNames[to]}
+	return &wrongFmtErr{typeName(t.Type), formatNames[to]}
 }
 
 // Rat returns the tag's i'th value as a rational number. It returns a nil and
@@ -346,8 +577,40 @@ func (t *Tag) Rat2(i int) (num, den int64, err error) {
 	return t.ratVals[i][0], t.ratVals[i][1], nil
 }
 
-// Int64 returns the tag's i'th value as an integer. It returns an error if the
-// tag's Format is not IntVal. It panics if i is out of range.
+// SignedRat returns the tag's i'th value as a signed rational number, with
+// the sign carried by the numerator. The numerators of values of type
+// DTRational are reinterpreted as signed, as stored by writers using the
+// unsigned type for signed fields such as ExposureBiasValue: 0xFFFFFFFF/3 is
+// returned as -1/3.
+// It returns an error if the tag's Format is not RatVal. It panics if i is out
+// of range.
+func (t *Tag) SignedRat(i int) (num, den int64, err error) {
+	if t.format != RatVal {
+		return 0, 0, t.typeErr(RatVal)
+	}
+	num, den = t.ratVals[i][0], t.ratVals[i][1]
+	if t.Type == DTRational {
+		num = int64(int32(num))
+	}
+	if den < 0 {
+		num, den = -num, -den
+	}
+	return num, den, nil
+}
+
+// Signed reports whether the tag's data type holds signed values: the signed
+// integer and rational types and the floating point types.
+func (t *Tag) Signed() bool {
+	switch t.Type {
+	case DTSByte, DTSShort, DTSLong, DTSRational, DTFloat, DTDouble:
+		return true
+	}
+	return false
+}
+
+// Int64 returns the tag's i'th value as an integer. Values of the signed
+// types DTSByte, DTSShort and DTSLong are sign-extended. It returns an error if
+// the tag's Format is not IntVal. It panics if i is out of range.
 func (t *Tag) Int64(i int) (int64, error) {
 	if t.format != IntVal {
 		return 0, t.typeErr(IntVal)
@@ -382,45 +645,108 @@ func (t *Tag) StringVal() (string, error) {
 	return t.strVal, nil
 }
 
-// String returns a nicely formatted version of the tag.
+// RawStringVal returns the bytes of the tag's value up to the first NUL
+// byte, as stored, whatever the Charset it was decoded with. It returns an
+// error if the tag is not of type DTAscii.
+func (t *Tag) RawStringVal() ([]byte, error) {
+	if t.Type != DTAscii {
+		return nil, t.typeErr(StringVal)
+	}
+	return t.rawString(), nil
+}
+
+func (t *Tag) rawString() []byte {
+	// ignore all trailing NULL bytes, in case of a broken t.Count
+	if nullPos := bytes.IndexByte(t.Val, 0); nullPos != -1 {
+		return t.Val[:nullPos]
+	}
+	return t.Val
+}
+
+// MaxStringLen is the length above which Tag.String, and so Dir.String and
+// Tiff.String, truncate the rendering of a value, ending it with an ellipsis
+// and the number of values of the tag, e.g. `"JFIF..." (65536 bytes)`. It
+// keeps large values such as maker notes and thumbnails out of logs. It
+// bounds the bytes of string and undefined values, and the rendered length
+// of the list of numeric values. Zero or less disables truncation.
+var MaxStringLen = 1024
+
+// String returns a nicely formatted version of the tag, truncated to
+// MaxStringLen.
 func (t *Tag) String() string {
-	data, err := t.MarshalJSON()
+	data, truncated, err := t.marshal(MaxStringLen)
 	if err != nil {
 		return "ERROR: " + err.Error()
 	}
 
 	if t.Count == 1 {
-		return strings.Trim(fmt.Sprintf("%s", data), "[]")
+		data = bytes.Trim(data, "[]")
+	}
+	if !truncated {
+		return string(data)
+	}
+	unit := "values"
+	if t.format == StringVal || t.format == UndefVal || t.Type == DTByte {
+		unit = "bytes"
 	}
-	return fmt.Sprintf("%s", data)
+	end := len(data) - 1
+	return fmt.Sprintf("%s...%s (%d %s)", data[:end], data[end:], t.Count, unit)
 }
 
 func (t *Tag) MarshalJSON() ([]byte, error) {
+	data, _, err := t.marshal(0)
+	return data, err
+}
+
+// marshal returns the JSON rendering of the tag's value, truncated to max
+// bytes of string and undefined values or to the values rendering in max
+// bytes (but at least one), unless max is zero or less, and whether it was
+// truncated.
+func (t *Tag) marshal(max int) (data []byte, truncated bool, err error) {
 	switch t.format {
-	case StringVal, UndefVal:
-		return nullString(t.Val), nil
+	case StringVal:
+		if t.Type != DTAscii {
+			// decoded by a registered TypeHandler
+			data, truncated = nullString([]byte(t.strVal), max)
+			return data, truncated, nil
+		}
+		data, truncated = nullString(t.Val, max)
+		return data, truncated, nil
+	case UndefVal:
+		data, truncated = nullString(t.Val, max)
+		return data, truncated, nil
 	case OtherVal:
-		return []byte(fmt.Sprintf("unknown tag type '%v'", t.Type)), nil
+		return []byte(fmt.Sprintf("unknown tag type '%v'", t.Type)), false, nil
 	}
 
 	rv := []string{}
+	n := -1
 	for i := 0; i < int(t.Count); i++ {
+		var v string
 		switch t.format {
 		case RatVal:
-			n, d, _ := t.Rat2(i)
-			rv = append(rv, fmt.Sprintf(`"%v/%v"`, n, d))
+			num, den, _ := t.Rat2(i)
+			v = fmt.Sprintf(`"%v/%v"`, num, den)
 		case FloatVal:
-			v, _ := t.Float(i)
-			rv = append(rv, fmt.Sprintf("%v", v))
+			f, _ := t.Float(i)
+			v = fmt.Sprintf("%v", f)
 		case IntVal:
-			v, _ := t.Int(i)
-			rv = append(rv, fmt.Sprintf("%v", v))
+			d, _ := t.Int(i)
+			v = fmt.Sprintf("%v", d)
+		}
+		if n += len(v) + 1; max > 0 && n > max && len(rv) > 0 {
+			truncated = true
+			break
 		}
+		rv = append(rv, v)
 	}
-	return []byte(fmt.Sprintf(`[%s]`, strings.Join(rv, ","))), nil
+	return []byte(fmt.Sprintf(`[%s]`, strings.Join(rv, ","))), truncated, nil
 }
 
-func nullString(in []byte) []byte {
+// nullString returns the printable bytes of in as a JSON string, or an empty
+// one if they are not valid UTF-8, truncated to max bytes unless max is zero
+// or less, and whether it was truncated.
+func nullString(in []byte, max int) ([]byte, bool) {
 	rv := bytes.Buffer{}
 	rv.WriteByte('"')
 	for _, b := range in {
@@ -428,12 +754,18 @@ func nullString(in []byte) []byte {
 			rv.WriteByte(b)
 		}
 	}
-	rv.WriteByte('"')
 	rvb := rv.Bytes()
-	if utf8.Valid(rvb) {
-		return rvb
+	if !utf8.Valid(rvb) {
+		return []byte(`""`), false
+	}
+	truncated := false
+	if n := max + 1; max > 0 && len(rvb) > n {
+		for n > 1 && !utf8.RuneStart(rvb[n]) {
+			n--
+		}
+		rvb, truncated = rvb[:n], true
 	}
-	return []byte(`""`)
+	return append(rvb, '"'), truncated
 }
 
 type wrongFmtErr struct {
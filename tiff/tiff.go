@@ -8,7 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 )
 
 // ReadAtReader is used when decoding Tiff tags and directories
@@ -24,6 +23,186 @@ type Tiff struct {
 	Dirs []*Dir
 	// The tiff's byte-encoding (i.e. big/little endian).
 	Order binary.ByteOrder
+	// Warnings holds the problems that caused IFDs to be skipped when
+	// decoding leniently. Problems with individual tags are recorded in the
+	// Warnings of the Dir they belong to.
+	Warnings []error
+}
+
+// A Decoder holds the options used to decode tiff data. The zero value
+// decodes strictly, exactly like the package-level Decode and DecodeDir
+// functions.
+type Decoder struct {
+	// Lenient makes decoding best-effort: tags and IFDs that cannot be
+	// decoded are skipped and recorded as warnings instead of failing the
+	// whole decode.
+	Lenient bool
+	// Limits bounds the resources used while decoding. Exceeding any of
+	// them fails the decode, even when decoding leniently.
+	Limits Limits
+	// Recover makes Decode salvage partially corrupted files whose offset
+	// to the first IFD is bogus: the data is scanned for the first
+	// structure that looks like an IFD (a sane entry count, known data
+	// types and values that fit in the data), which is decoded instead and
+	// recorded in the Warnings of the Tiff.
+	Recover bool
+	// Magic decides which magic numbers are accepted in the tiff header,
+	// e.g. to accept Olympus ORF files or to reject anything but 42.
+	Magic MagicPolicy
+	// Want, if set, selects the tags to decode by ID: the entries of tags
+	// it returns false for are skipped without reading their values.
+	Want func(id uint16) bool
+	// Trace, if set, is called for each IFD and tag decoded, including
+	// those that fail to decode, to help debug files that do not decode as
+	// expected.
+	Trace TraceFunc
+	// Hook, if set, is called for each IFD entry selected by Want once
+	// its value has been read, before the value is decoded. Tags it
+	// returns false for are skipped.
+	Hook HookFunc
+	// Charset decides how the values of ASCII tags are decoded into the
+	// strings returned by Tag.StringVal. The zero value, CharsetAuto,
+	// decodes them as UTF-8 if valid and as Latin-1 otherwise.
+	Charset Charset
+	// Alloc, if set, is called with the number of bytes about to be
+	// allocated for the decoded data, see AllocFunc and Meter.
+	Alloc AllocFunc
+	// Order, if set, forces the byte order of the tiff data, ignoring the
+	// byte order mark of the header, to decode files whose header is
+	// corrupted. Otherwise the byte order is checked with CheckOrder: an
+	// invalid header suggesting another order fails with the error of
+	// CheckOrder, and other inconsistencies are recorded in the Warnings of
+	// the Tiff.
+	Order binary.ByteOrder
+	// Buffer, if set, holds the memory the tags decoded and their values are
+	// carved out of, reused across decodes once reset, see Buffer.
+	Buffer *Buffer
+
+	// dir names the IFD being decoded, see WithDir.
+	dir string
+}
+
+// A RawTag is an IFD entry whose value has not been decoded yet, as passed
+// to a HookFunc.
+type RawTag struct {
+	// Dir names the IFD holding the entry (e.g. "IFD0"), if known.
+	Dir   string
+	ID    uint16
+	Type  DataType
+	Count uint32
+	// Offset is the offset of the entry from the start of the tiff data,
+	// or -1 if unknown.
+	Offset int64
+	// Order is the byte order of the tiff data.
+	Order binary.ByteOrder
+	// Val holds the raw bytes of the value. It must not be modified.
+	Val []byte
+}
+
+// A HookFunc intercepts IFD entries before their values are decoded, e.g. to
+// capture proprietary tags, collect statistics or veto the decoding of
+// expensive tags: returning false skips the tag.
+type HookFunc func(t RawTag) bool
+
+// ErrSkipped is returned by Decoder.DecodeTag for tags skipped by
+// Decoder.Hook.
+var ErrSkipped = errors.New("tiff: tag skipped by hook")
+
+// A TraceFunc receives the events reported while decoding.
+type TraceFunc func(TraceEvent)
+
+// TraceKind identifies the decode step reported by a TraceEvent.
+type TraceKind int
+
+const (
+	TraceSegment TraceKind = iota // a container segment holding metadata, e.g. a JPEG APPn segment
+	TraceIFD                      // an IFD
+	TraceTag                      // an IFD entry
+)
+
+var traceKindNames = map[TraceKind]string{
+	TraceSegment: "segment",
+	TraceIFD:     "IFD",
+	TraceTag:     "tag",
+}
+
+func (k TraceKind) String() string {
+	if s, ok := traceKindNames[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("TraceKind(%d)", int(k))
+}
+
+// A TraceEvent reports a step of decoding.
+type TraceEvent struct {
+	Kind TraceKind
+	// Dir names the IFD the event belongs to (e.g. "IFD0"), if known.
+	Dir string
+	// Offset is the offset of the reported item from the start of the tiff
+	// data (from the start of the file for TraceSegment events), or -1 if
+	// unknown.
+	Offset int64
+	// Length is the length in bytes of the reported item.
+	Length int64
+	// Tag is the decoded tag of a TraceTag event, if decoding succeeded.
+	Tag *Tag
+	// Err is the problem encountered, if any.
+	Err error
+	// Msg describes the item.
+	Msg string
+}
+
+func (ev TraceEvent) String() string {
+	s := ev.Kind.String()
+	if ev.Dir != "" {
+		s = ev.Dir + " " + s
+	}
+	s = fmt.Sprintf("%s at %d (%d bytes): %s", s, ev.Offset, ev.Length, ev.Msg)
+	if ev.Err != nil {
+		s += ": " + ev.Err.Error()
+	}
+	return s
+}
+
+func (dec *Decoder) trace(ev TraceEvent) {
+	if dec.Trace != nil {
+		dec.Trace(ev)
+	}
+}
+
+// WithDir returns a copy of dec that reports trace events and tags passed
+// to Hook as belonging to the IFD name. It returns dec itself if neither
+// dec.Trace nor dec.Hook is set.
+func (dec *Decoder) WithDir(name string) *Decoder {
+	if dec.Trace == nil && dec.Hook == nil {
+		return dec
+	}
+	d := *dec
+	d.dir = name
+	if dec.Trace != nil {
+		d.Trace = func(ev TraceEvent) {
+			ev.Dir = name
+			dec.Trace(ev)
+		}
+	}
+	return &d
+}
+
+// ErrLimitExceeded is returned (possibly wrapped, see errors.Is) when
+// decoding would exceed one of the limits set on a Decoder.
+var ErrLimitExceeded = errors.New("tiff: decode limit exceeded")
+
+// Limits bounds the resources a Decoder may use, so that crafted files cannot
+// make it consume unbounded memory or time. A zero field means no limit.
+type Limits struct {
+	// MaxBytes is the maximum size in bytes of the tiff data read.
+	MaxBytes int64
+	// MaxIfds is the maximum number of IFDs in the IFD chain.
+	MaxIfds int
+	// MaxTagsPerIfd is the maximum number of tags a single IFD may hold.
+	MaxTagsPerIfd int
+	// MaxTagValueSize is the maximum size in bytes of a single tag value.
+	MaxTagValueSize uint32
 }
 
 // Decode parses tiff-encoded data from r and returns a Tiff struct that
@@ -31,72 +210,123 @@ type Tiff struct {
 // should be the first byte of the tiff-encoded data and not necessarily the
 // first byte of an os.File object.
 func Decode(r io.Reader) (*Tiff, error) {
-	data, err := ioutil.ReadAll(r)
+	return new(Decoder).Decode(r)
+}
+
+// Decode is like the package-level Decode function, but honors the options
+// set in dec.
+func (dec *Decoder) Decode(r io.Reader) (*Tiff, error) {
+	if max := dec.Limits.MaxBytes; max > 0 {
+		r = io.LimitReader(r, max+1)
+	}
+	data, err := io.ReadAll(r)
 	if err != nil {
 		return nil, errors.New("tiff: could not read data")
 	}
-	buf := bytes.NewReader(data)
+	if err := dec.alloc(int64(len(data))); err != nil {
+		return nil, err
+	}
+	return dec.DecodeBytes(data)
+}
 
+// DecodeBytes is like Decode, but decodes the tiff data in data in place
+// rather than reading a copy of it. Tag values are still copied out of data.
+func (dec *Decoder) DecodeBytes(data []byte) (*Tiff, error) {
+	if max := dec.Limits.MaxBytes; max > 0 && int64(len(data)) > max {
+		return nil, fmt.Errorf("%w: tiff data exceeds %d bytes", ErrLimitExceeded, max)
+	}
+	buf := bytes.NewReader(data)
 	t := new(Tiff)
 
 	// read byte order
-	bo := make([]byte, 2)
-	if _, err = io.ReadFull(buf, bo); err != nil {
+	if len(data) < 2 {
 		return nil, errors.New("tiff: could not read tiff byte order")
 	}
-	if string(bo) == "II" {
+	if dec.Order != nil {
+		t.Order = dec.Order
+	} else if string(data[:2]) == "II" {
 		t.Order = binary.LittleEndian
-	} else if string(bo) == "MM" {
+	} else if string(data[:2]) == "MM" {
 		t.Order = binary.BigEndian
 	} else {
-		return nil, errors.New("tiff: could not read tiff byte order")
+		return nil, dec.orderError(data, errors.New("tiff: could not read tiff byte order"))
 	}
 
-	// check for special tiff marker
-	var sp int16
-	err = binary.Read(buf, t.Order, &sp)
-	if err != nil || 42 != sp {
+	// check for special tiff marker, which is 0x55 in Panasonic RW2 files
+	// and differs in other variants, see HeaderVariants
+	if len(data) < 4 {
 		return nil, errors.New("tiff: could not find special tiff marker")
 	}
+	if sp := t.Order.Uint16(data[2:]); !dec.Magic.accepts(sp) {
+		return nil, dec.orderError(data, dec.Magic.magicError(sp))
+	}
 
 	// load offset to first IFD
-	var offset int32
-	err = binary.Read(buf, t.Order, &offset)
-	if err != nil {
+	if len(data) < 8 {
 		return nil, errors.New("tiff: could not read offset to first IFD")
 	}
+	offset := int32(t.Order.Uint32(data[4:]))
+	if dec.Order == nil {
+		if _, err := CheckOrder(data); errors.Is(err, ErrOrderMismatch) {
+			t.Warnings = append(t.Warnings, err)
+		}
+	}
+	if dec.Recover && !plausibleDir(data, int64(offset), t.Order, false) {
+		if off, ok := scanDir(data, t.Order); ok {
+			t.Warnings = append(t.Warnings, fmt.Errorf("tiff: bogus offset %d to first IFD, recovered IFD at offset %d", offset, off))
+			offset = int32(off)
+		}
+	}
 
 	// load IFD's
-	var d *Dir
-	prev := offset
+	seen := map[int32]bool{}
 	for offset != 0 {
+		if max := dec.Limits.MaxIfds; max > 0 && len(t.Dirs) >= max {
+			return nil, fmt.Errorf("%w: more than %d IFDs", ErrLimitExceeded, max)
+		}
+
+		var d *Dir
+		dirOffset := offset
 		// seek to offset
 		_, err := buf.Seek(int64(offset), 0)
 		if err != nil {
-			return nil, errors.New("tiff: seek to IFD failed")
+			err = errors.New("tiff: seek to IFD failed")
+		} else if buf.Len() == 0 {
+			err = errors.New("tiff: seek offset after EOF")
+		} else {
+			// load the dir
+			dd := dec
+			if dec.dir == "" {
+				// not a tiff structure embedded in a named IFD
+				dd = dec.WithDir(fmt.Sprintf("IFD%d", len(t.Dirs)))
+			}
+			d, offset, err = dd.DecodeDir(buf, t.Order)
 		}
-
-		if buf.Len() == 0 {
-			return nil, errors.New("tiff: seek offset after EOF")
+		seen[dirOffset] = true
+		if err == nil && seen[offset] {
+			err = errors.New("tiff: recursive IFD")
 		}
-
-		// load the dir
-		d, offset, err = DecodeDir(buf, t.Order)
 		if err != nil {
-			return nil, err
+			if !dec.Lenient || errors.Is(err, ErrLimitExceeded) {
+				return nil, err
+			}
+			// Keep what was decoded so far and give up on the rest of
+			// the IFD chain.
+			if d != nil {
+				t.Dirs = append(t.Dirs, d)
+			}
+			t.Warnings = append(t.Warnings, fmt.Errorf("tiff: IFD %d skipped: %v", len(t.Dirs), err))
+			break
 		}
 
-		if offset == prev {
-			return nil, errors.New("tiff: recursive IFD")
-		}
-		prev = offset
-
 		t.Dirs = append(t.Dirs, d)
 	}
 
 	return t, nil
 }
 
+// String returns a nicely formatted version of the IFDs of tf, whose values
+// are truncated to MaxStringLen.
 func (tf *Tiff) String() string {
 	var buf bytes.Buffer
 	fmt.Fprint(&buf, "Tiff{")
@@ -110,6 +340,31 @@ func (tf *Tiff) String() string {
 // Dir provides access to the parsed content of a tiff Image File Directory (IFD).
 type Dir struct {
 	Tags []*Tag
+	// Warnings holds the problems that caused tags to be skipped when
+	// decoding leniently.
+	Warnings []error
+	// Layout describes where the IFD is stored in the tiff data.
+	Layout Layout
+	// SubDirs holds the sub-IFDs (e.g. the Exif IFD) written by
+	// Tiff.Encode, keyed by the ID of the tag pointing to them. That tag is
+	// added to the IFD if missing. Decoding does not fill in SubDirs.
+	SubDirs map[uint16]*Dir
+}
+
+// Layout describes the storage of an IFD, for diagnostic tools that need to
+// visualize the structure of a file and for writers planning its layout.
+// Tag values stored outside the IFD are described by the ValOffset of each
+// Tag instead.
+type Layout struct {
+	// Offset is the offset of the IFD (i.e. of its entry count) in the tiff
+	// data, or -1 if unknown.
+	Offset int64
+	// Length is the length in bytes of the IFD as read: the entry count,
+	// the 12 byte entries and the next IFD offset.
+	Length int64
+	// Next is the offset of the next IFD stored at the end of the IFD, or
+	// 0 if there is none.
+	Next int32
 }
 
 // DecodeDir parses a tiff-encoded IFD from r and returns a Dir object.  offset
@@ -117,33 +372,167 @@ type Dir struct {
 // byte of the IFD. ReadAt offsets should generally be relative to the
 // beginning of the tiff structure (not relative to the beginning of the IFD).
 func DecodeDir(r ReadAtReader, order binary.ByteOrder) (d *Dir, offset int32, err error) {
+	return new(Decoder).DecodeDir(r, order)
+}
+
+// DecodeDir is like the package-level DecodeDir function, but honors the
+// options set in dec. When decoding strictly, the first error decoding the
+// IFD is returned along with a partial Dir holding the tags decoded before
+// it. When decoding leniently, tags that fail to decode and a truncated IFD
+// are recorded in the Warnings of the returned Dir instead, and the returned
+// error is nil unless a limit of dec.Limits is exceeded, in which case it
+// wraps ErrLimitExceeded and the Dir is partial as well.
+func (dec *Decoder) DecodeDir(r ReadAtReader, order binary.ByteOrder) (d *Dir, offset int32, err error) {
 	d = new(Dir)
 
 	// get num of tags in ifd
-	var nTags int16
-	err = binary.Read(r, order, &nTags)
-	if err != nil {
-		return nil, 0, errors.New("tiff: failed to read IFD tag count: " + err.Error())
+	pos := readerOffset(r)
+	d.Layout.Offset = pos
+	var nTags uint16
+	if dec.Trace != nil {
+		start := pos
+		defer func() { dec.traceDir(start, nTags, d, offset, err) }()
+	}
+	// entry is reused for the tag count, each entry and the next offset
+	entry := make([]byte, 12)
+	if _, err = io.ReadFull(r, entry[:2]); err != nil {
+		return d, 0, errors.New("tiff: failed to read IFD tag count: " + err.Error())
+	}
+	nTags = order.Uint16(entry)
+	if max := dec.Limits.MaxTagsPerIfd; max > 0 && int(nTags) > max {
+		return d, 0, fmt.Errorf("%w: IFD has %d tags, limit is %d", ErrLimitExceeded, nTags, max)
+	}
+	if pos >= 0 {
+		pos += 2
 	}
 
-	// load tags
-	for n := 0; n < int(nTags); n++ {
-		t, err := DecodeTag(r, order)
+	// check the entries and next IFD offset fit in the remaining data
+	n := int(nTags)
+	if avail := remaining(r, pos); avail >= 0 && 12*int64(nTags)+4 > avail {
+		derr := &DirError{Offset: d.Layout.Offset, Count: nTags, Avail: avail}
+		if !dec.Lenient {
+			return d, 0, derr
+		}
+		d.Warnings = append(d.Warnings, derr)
+		n = int(avail / 12)
+	}
+
+	// load tags, allocating them and their inline values in bulk
+	tags := dec.Buffer.newTags(n)
+	inline := dec.Buffer.newBytes(4 * n)
+	if n > 0 {
+		d.Tags = dec.Buffer.newPtrs(n)
+	}
+	for i := 0; i < n; i++ {
+		entryOffset := int64(-1)
+		if pos >= 0 {
+			entryOffset = pos + 12*int64(i)
+		}
+		if _, err := io.ReadFull(r, entry); err != nil {
+			err = errors.New("tiff: failed to read IFD entry: " + err.Error())
+			if !dec.Lenient {
+				return d, 0, err
+			}
+			d.Warnings = append(d.Warnings, err)
+			d.Layout.Length = 2 + 12*int64(i)
+			return d, 0, nil
+		}
+		if dec.Want != nil && !dec.Want(order.Uint16(entry)) {
+			continue
+		}
+		t := &tags[i]
+		err := dec.decodeEntry(t, entry, r, entryOffset, order, inline[4*i:])
+		if dec.Trace != nil {
+			dec.traceTag(entryOffset, entry, order, t, err)
+		}
+		if err == ErrSkipped {
+			continue
+		}
 		if err != nil {
-			return nil, 0, err
+			if !dec.Lenient || errors.Is(err, ErrLimitExceeded) {
+				return d, 0, err
+			}
+			id := order.Uint16(entry)
+			d.Warnings = append(d.Warnings, fmt.Errorf("tiff: tag 0x%04x skipped: %v", id, err))
+			continue
 		}
 		d.Tags = append(d.Tags, t)
 	}
+	d.Layout.Length = 2 + 12*int64(n)
+	if n < int(nTags) {
+		// truncated IFD: there is no offset to the next IFD
+		return d, 0, nil
+	}
 
 	// get offset to next ifd
-	err = binary.Read(r, order, &offset)
-	if err != nil {
-		return nil, 0, errors.New("tiff: falied to read offset to next IFD: " + err.Error())
+	if _, err = io.ReadFull(r, entry[:4]); err != nil {
+		err = errors.New("tiff: falied to read offset to next IFD: " + err.Error())
+		if !dec.Lenient {
+			return d, 0, err
+		}
+		d.Warnings = append(d.Warnings, err)
+		return d, 0, nil
 	}
+	offset = int32(order.Uint32(entry))
+	d.Layout.Length += 4
+	d.Layout.Next = offset
 
 	return d, offset, nil
 }
 
+func (dec *Decoder) traceDir(pos int64, nTags uint16, d *Dir, next int32, err error) {
+	ev := TraceEvent{Kind: TraceIFD, Offset: pos, Err: err}
+	ev.Msg = fmt.Sprintf("%d entries", nTags)
+	if d != nil {
+		ev.Length = d.Layout.Length
+		ev.Msg += fmt.Sprintf(", %d tags decoded, %d warnings, next IFD at %d", len(d.Tags), len(d.Warnings), next)
+	}
+	dec.Trace(ev)
+}
+
+func (dec *Decoder) traceTag(offset int64, entry []byte, order binary.ByteOrder, t *Tag, err error) {
+	ev := TraceEvent{Kind: TraceTag, Offset: offset, Length: 12, Err: err}
+	id, typ, count := order.Uint16(entry), DataType(order.Uint16(entry[2:])), order.Uint32(entry[4:])
+	ev.Msg = fmt.Sprintf("tag 0x%04x, type %v, count %d", id, typeName(typ), count)
+	if err == nil {
+		ev.Tag = t
+		if t.Inlined() {
+			ev.Msg += ", value inline"
+		} else {
+			ev.Msg += fmt.Sprintf(", value at %d", t.ValOffset)
+		}
+	}
+	dec.Trace(ev)
+}
+
+// A DirError is returned by DecodeDir when the entry count of an IFD does
+// not fit in the data remaining after it. When decoding leniently it is
+// recorded as a warning instead and the entries that fit are decoded.
+type DirError struct {
+	// Offset is the offset of the IFD in the tiff data, or -1 if unknown.
+	Offset int64
+	// Count is the number of entries the IFD claims to hold.
+	Count uint16
+	// Avail is the number of bytes remaining after the entry count.
+	Avail int64
+}
+
+func (e *DirError) Error() string {
+	return fmt.Sprintf("tiff: IFD at offset %d has %d entries (%d bytes) but only %d bytes remain", e.Offset, e.Count, 12*int64(e.Count)+4, e.Avail)
+}
+
+// remaining returns the number of bytes of r after offset pos, or -1 if
+// unknown.
+func remaining(r io.Reader, pos int64) int64 {
+	sr, ok := r.(interface{ Size() int64 })
+	if !ok || pos < 0 || pos > sr.Size() {
+		return -1
+	}
+	return sr.Size() - pos
+}
+
+// String returns a nicely formatted version of the tags of d, whose values
+// are truncated to MaxStringLen.
 func (d *Dir) String() string {
 	s := "Dir{"
 	for _, t := range d.Tags {
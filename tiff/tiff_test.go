@@ -4,9 +4,15 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"math/big"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -247,3 +253,1051 @@ func data() []byte {
 	}
 	return dat
 }
+
+func TestDecodeDirLenient(t *testing.T) {
+	// An IFD with two tags, the first of which has a corrupt Count.
+	ifd, _ := hex.DecodeString("0002" +
+		"0001" + "0004" + "FFFFFFFF" + "00000000" +
+		"0002" + "0003" + "00000001" + "00070000" +
+		"00000000")
+
+	d, _, err := DecodeDir(bytes.NewReader(ifd), binary.BigEndian)
+	if err == nil {
+		t.Fatal("strict decode of corrupt IFD succeeded")
+	}
+	if d == nil || len(d.Tags) != 0 {
+		t.Errorf("strict decode: got Dir %v, want a partial Dir without tags", d)
+	}
+
+	dec := &Decoder{Lenient: true}
+	d, offset, err := dec.DecodeDir(bytes.NewReader(ifd), binary.BigEndian)
+	if err != nil {
+		t.Fatalf("lenient decode failed: %v", err)
+	}
+	if offset != 0 {
+		t.Errorf("next IFD offset: got %v, want 0", offset)
+	}
+	if len(d.Tags) != 1 || d.Tags[0].Id != 2 {
+		t.Fatalf("got tags %v, want only tag 0x0002", d.Tags)
+	}
+	if len(d.Warnings) != 1 {
+		t.Errorf("got %d warnings, want 1: %v", len(d.Warnings), d.Warnings)
+	}
+}
+
+func TestDecodeDirCount(t *testing.T) {
+	entry := "0112" + "0003" + "00000001" + "00060000"
+	tests := []struct {
+		name    string
+		ifd     string
+		wantErr bool
+		// tags decoded when decoding leniently
+		wantTags int
+	}{
+		{"zero", "0000" + "00000000", false, 0},
+		{"exact", "0001" + entry + "00000000", false, 1},
+		// 0xFFFF would be negative as an int16
+		{"negative", "FFFF" + entry + "00000000", true, 1},
+		{"too many", "0003" + entry + entry + "00000000", true, 2},
+		{"missing next offset", "0001" + entry, true, 1},
+	}
+	for _, tt := range tests {
+		ifd, _ := hex.DecodeString(tt.ifd)
+		d, _, err := DecodeDir(bytes.NewReader(ifd), binary.BigEndian)
+		if tt.wantErr {
+			if _, ok := err.(*DirError); !ok {
+				t.Errorf("%v: got error %v, want a *DirError", tt.name, err)
+			}
+		} else if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+		} else if len(d.Tags) != tt.wantTags {
+			t.Errorf("%v: got %d tags, want %d", tt.name, len(d.Tags), tt.wantTags)
+		}
+
+		d, _, err = (&Decoder{Lenient: true}).DecodeDir(bytes.NewReader(ifd), binary.BigEndian)
+		if err != nil {
+			t.Errorf("%v: lenient decode failed: %v", tt.name, err)
+			continue
+		}
+		if len(d.Tags) != tt.wantTags {
+			t.Errorf("%v: lenient decode got %d tags, want %d", tt.name, len(d.Tags), tt.wantTags)
+		}
+		if hasWarning := len(d.Warnings) > 0; hasWarning != tt.wantErr {
+			t.Errorf("%v: got warnings %v", tt.name, d.Warnings)
+		}
+	}
+}
+
+func TestDirLayout(t *testing.T) {
+	// IFD0 with one tag at offset 8, pointing to IFD1 with two tags at 26.
+	data, _ := hex.DecodeString("4D4D002A00000008" +
+		"0001" + "0112" + "0003" + "00000001" + "00060000" + "0000001A" +
+		"0002" + "0103" + "0003" + "00000001" + "00060000" +
+		"0201" + "0004" + "00000001" + "00000000" + "00000000")
+
+	tif, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Layout{
+		{Offset: 8, Length: 18, Next: 26},
+		{Offset: 26, Length: 30, Next: 0},
+	}
+	if len(tif.Dirs) != len(want) {
+		t.Fatalf("got %d IFDs, want %d", len(tif.Dirs), len(want))
+	}
+	for i, d := range tif.Dirs {
+		if d.Layout != want[i] {
+			t.Errorf("IFD%d: got layout %+v, want %+v", i, d.Layout, want[i])
+		}
+	}
+}
+
+func TestTagRawBytes(t *testing.T) {
+	// An IFD with an inlined short and an out-of-line rational.
+	ifd, _ := hex.DecodeString("0002" +
+		"0112" + "0003" + "00000001" + "00060000" +
+		"011A" + "0005" + "00000001" + "0000001E" +
+		"00000000" +
+		"0000004800000001")
+
+	d, _, err := DecodeDir(bytes.NewReader(ifd), binary.BigEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Tags) != 2 {
+		t.Fatalf("got %d tags, want 2", len(d.Tags))
+	}
+
+	short, rat := d.Tags[0], d.Tags[1]
+	if off, ok := short.ValueOffset(); !short.Inlined() || !ok || off != 10 || short.ValOffset != 0 {
+		t.Errorf("short: got inlined=%v offset=%v, %v, ValOffset %v, want inlined at 10", short.Inlined(), off, ok, short.ValOffset)
+	}
+	if got, want := short.RawBytes(), []byte{0x00, 0x06}; !bytes.Equal(got, want) {
+		t.Errorf("short raw bytes: got %x, want %x", got, want)
+	}
+	if off, ok := rat.ValueOffset(); rat.Inlined() || !ok || off != 30 || rat.ValOffset != 30 {
+		t.Errorf("rational: got inlined=%v offset=%v, %v, want out-of-line at 30", rat.Inlined(), off, ok)
+	}
+	if got, want := rat.RawBytes(), ifd[30:38]; !bytes.Equal(got, want) {
+		t.Errorf("rational raw bytes: got %x, want %x", got, want)
+	}
+	if rat.Order() != binary.BigEndian {
+		t.Errorf("got byte order %v, want big endian", rat.Order())
+	}
+}
+
+func TestRegisterType(t *testing.T) {
+	RegisterType(13, TypeHandler{
+		Name:   "ifd",
+		Size:   4,
+		Format: IntVal,
+		Decode: func(val []byte, count uint32, order binary.ByteOrder) (Values, error) {
+			v := Values{Ints: make([]int64, count)}
+			for i := range v.Ints {
+				v.Ints[i] = int64(order.Uint32(val[4*i:]))
+			}
+			return v, nil
+		},
+	})
+	RegisterType(129, TypeHandler{
+		Name:   "utf-8",
+		Size:   1,
+		Format: StringVal,
+		Decode: func(val []byte, count uint32, order binary.ByteOrder) (Values, error) {
+			return Values{String: string(bytes.TrimRight(val, "\x00"))}, nil
+		},
+	})
+	RegisterType(0x8001, TypeHandler{Name: "vendor blob", Size: 2, Format: UndefVal})
+
+	tests := []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{"ifd", "8769" + "000D" + "00000001" + "0000002A", "42"},
+		{"utf-8", "010E" + "0081" + "00000003" + "C3A90000", `"é"`},
+		{"undefined", "C000" + "8001" + "00000002" + "01020304", `""`},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.tag)
+		tag, err := DecodeTag(bytes.NewReader(data), binary.BigEndian)
+		if err != nil {
+			t.Errorf("%v: %v", tt.name, err)
+			continue
+		}
+		if got := tag.String(); got != tt.want {
+			t.Errorf("%v: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	data, _ := hex.DecodeString("0001" + "0063" + "00000001" + "00000000")
+	if _, err := DecodeTag(bytes.NewReader(data), binary.BigEndian); err == nil {
+		t.Error("no error decoding tag of unregistered type")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a standard data type did not panic")
+		}
+	}()
+	RegisterType(DTShort, TypeHandler{Name: "short", Size: 2})
+}
+
+func TestEncode(t *testing.T) {
+	mustTag := func(id uint16, typ DataType, val interface{}) *Tag {
+		tag, err := NewTag(id, typ, val)
+		if err != nil {
+			t.Fatalf("NewTag(0x%04x): %v", id, err)
+		}
+		return tag
+	}
+
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		exif := NewDir(
+			mustTag(0x9003, DTAscii, "2020:01:02 03:04:05"),
+			mustTag(0x829A, DTRational, [2]int64{1, 250}),
+			mustTag(0x9204, DTSRational, [][2]int64{{-1, 3}}),
+		)
+		ifd0 := NewDir(
+			mustTag(0x0110, DTAscii, "Renderer"),
+			mustTag(0x010F, DTAscii, "Odd"), // odd length value, stored inline
+			mustTag(0x0112, DTShort, 6),
+			mustTag(0x0102, DTShort, []int{8, 8, 8}),
+			mustTag(0x011A, DTRational, big.NewRat(300, 1)),
+			mustTag(0x9C9B, DTByte, []byte("T\x00i\x00t\x00l\x00e\x00")),
+			mustTag(0xA500, DTDouble, 2.2),
+		)
+		ifd0.SubDirs = map[uint16]*Dir{0x8769: exif}
+		ifd1 := NewDir(mustTag(0x0103, DTShort, 6))
+
+		var buf bytes.Buffer
+		if err := NewTiff(order, ifd0, ifd1).Encode(&buf); err != nil {
+			t.Fatalf("%v: Encode: %v", order, err)
+		}
+		tf, err := Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("%v: Decode: %v", order, err)
+		}
+		if tf.Order != order || len(tf.Dirs) != 2 {
+			t.Fatalf("%v: decoded order %v with %d IFDs", order, tf.Order, len(tf.Dirs))
+		}
+		d := tf.Dirs[0]
+		for i := 1; i < len(d.Tags); i++ {
+			if d.Tags[i-1].Id >= d.Tags[i].Id {
+				t.Errorf("%v: IFD0 entries not sorted: %v", order, d)
+			}
+		}
+		want := map[uint16]string{
+			0x010F: `"Odd"`,
+			0x0110: `"Renderer"`,
+			0x0112: `6`,
+			0x0102: `[8,8,8]`,
+			0x011A: `"300/1"`,
+			0xA500: `2.2`,
+		}
+		var ptr *Tag
+		for _, tag := range d.Tags {
+			if tag.Id == 0x8769 {
+				ptr = tag
+			} else if w, ok := want[tag.Id]; ok && tag.String() != w {
+				t.Errorf("%v: tag 0x%04x = %v, want %v", order, tag.Id, tag, w)
+			}
+		}
+		if ptr == nil {
+			t.Fatalf("%v: no Exif IFD pointer in IFD0", order)
+		}
+		off, _ := ptr.Int64(0)
+		r := bytes.NewReader(buf.Bytes())
+		r.Seek(off, io.SeekStart)
+		sub, _, err := DecodeDir(r, order)
+		if err != nil {
+			t.Fatalf("%v: decoding Exif IFD: %v", order, err)
+		}
+		if len(sub.Tags) != 3 || sub.Tags[0].String() != `"1/250"` || sub.Tags[1].String() != `"2020:01:02 03:04:05"` || sub.Tags[2].String() != `"-1/3"` {
+			t.Errorf("%v: Exif IFD = %v", order, sub)
+		}
+		if s := tf.Dirs[1].Tags[0].String(); s != "6" {
+			t.Errorf("%v: IFD1 compression = %v", order, s)
+		}
+	}
+
+	if _, err := NewTag(0x0112, DTShort, 70000); err == nil {
+		t.Error("no error creating short tag with out of range value")
+	}
+	if _, err := NewTag(0x0112, DTShort, "six"); err == nil {
+		t.Error("no error creating short tag from a string")
+	}
+}
+
+func TestDecodeDirTrace(t *testing.T) {
+	// an orientation tag and a tag whose value lies past the end of the data
+	ifd, _ := hex.DecodeString("0002" + "0112" + "0003" + "00000001" + "00060000" + "0110" + "0002" + "00000010" + "00001000" + "00000000")
+	var events []TraceEvent
+	dec := &Decoder{Lenient: true, Trace: func(ev TraceEvent) { events = append(events, ev) }}
+	if _, _, err := dec.WithDir("IFD0").DecodeDir(bytes.NewReader(ifd), binary.BigEndian); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %v", len(events), events)
+	}
+	if ev := events[0]; ev.Kind != TraceTag || ev.Tag == nil || ev.Offset != 2 || ev.Dir != "IFD0" {
+		t.Errorf("first tag event = %v", ev)
+	}
+	if ev := events[1]; ev.Kind != TraceTag || ev.Err == nil || ev.Offset != 14 {
+		t.Errorf("failed tag event = %v", ev)
+	}
+	if ev := events[2]; ev.Kind != TraceIFD || ev.Offset != 0 || ev.Length != int64(len(ifd)) {
+		t.Errorf("IFD event = %v", ev)
+	}
+}
+
+func TestDecodeRecover(t *testing.T) {
+	mk, _ := NewTag(0x010F, DTAscii, "Canon")
+	orient, _ := NewTag(0x0112, DTShort, 1)
+	var buf bytes.Buffer
+	if err := NewTiff(binary.BigEndian, NewDir(mk, orient)).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[4:], 0x7fff0000)
+
+	if _, err := Decode(bytes.NewReader(data)); err == nil {
+		t.Error("no error decoding tiff with bogus IFD0 offset")
+	}
+	tf, err := (&Decoder{Recover: true}).Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tf.Dirs) != 1 || len(tf.Dirs[0].Tags) != 2 || tf.Dirs[0].Tags[0].Id != 0x010F {
+		t.Fatalf("recovered dirs = %v", tf.Dirs)
+	}
+	if len(tf.Warnings) != 1 {
+		t.Errorf("warnings = %v, want the recovery", tf.Warnings)
+	}
+
+	// nothing resembling an IFD: the original error is kept
+	junk := append(data[:8:8], bytes.Repeat([]byte{0xff}, 40)...)
+	if _, err := (&Decoder{Recover: true}).Decode(bytes.NewReader(junk)); err == nil {
+		t.Error("no error recovering tiff without any IFD")
+	}
+
+	// a valid IFD0 holding a tag with a count of zero is not mistaken for
+	// a bogus one, not even with another plausible IFD after it, and its
+	// byte order is checked
+	zero, err := hex.DecodeString("49492A00080000000200" +
+		"0E010200000000000000000012010300010000000100000000000000" +
+		"0100000103000100000005000000" + "00000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf, err = (&Decoder{Recover: true}).Decode(bytes.NewReader(zero))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tf.Dirs) != 1 || len(tf.Dirs[0].Tags) != 2 || tf.Dirs[0].Tags[0].Count != 0 || len(tf.Warnings) != 0 {
+		t.Fatalf("recovered dirs = %v, warnings = %v", tf.Dirs, tf.Warnings)
+	}
+	if order, err := CheckOrder(zero); order != binary.LittleEndian || err != nil {
+		t.Errorf("CheckOrder = %v, %v, want LittleEndian", order, err)
+	}
+	copy(zero, "MM")
+	if order, err := CheckOrder(zero); order != binary.LittleEndian || !errors.Is(err, ErrOrderMismatch) {
+		t.Errorf("CheckOrder with a swapped header = %v, %v, want LittleEndian and ErrOrderMismatch", order, err)
+	}
+}
+
+func TestEncodeWithData(t *testing.T) {
+	// two strips in the source data, referenced by short offsets
+	src := make([]byte, 80)
+	copy(src[40:], "STRIPONE")
+	copy(src[60:], "TWO!!!")
+	width, _ := NewTag(0x0100, DTShort, 4)
+	offs, _ := NewTag(0x0111, DTShort, []int{40, 60})
+	counts, _ := NewTag(0x0117, DTShort, []int{8, 6})
+	tf := NewTiff(binary.LittleEndian, NewDir(width, offs, counts))
+
+	var buf bytes.Buffer
+	if err := tf.EncodeWithData(&buf, bytes.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := offs.Int(0); v != 40 || offs.Type != DTShort {
+		t.Errorf("source offsets tag modified: %v", offs)
+	}
+	got, err := Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := got.Dirs[0]
+	o, c := d.tag(0x0111), d.tag(0x0117)
+	if o == nil || c == nil || o.Type != DTLong || o.Count != 2 {
+		t.Fatalf("strip tags = %v, %v", o, c)
+	}
+	for i, want := range []string{"STRIPONE", "TWO!!!"} {
+		off, _ := o.Int(i)
+		n, _ := c.Int(i)
+		if off+n > buf.Len() || string(buf.Bytes()[off:off+n]) != want {
+			t.Errorf("strip %d at offset %d, length %d: want %q", i, off, n, want)
+		}
+	}
+
+	// source data too short for the strips
+	if err := tf.EncodeWithData(io.Discard, bytes.NewReader(src[:50])); err == nil {
+		t.Error("no error copying truncated strip data")
+	}
+}
+
+func TestDecodeGeoTIFF(t *testing.T) {
+	width, _ := NewTag(0x0100, DTShort, 100)
+	length, _ := NewTag(0x0101, DTShort, 50)
+	scale, _ := NewTag(TagModelPixelScale, DTDouble, []float64{0.5, 0.5, 0})
+	tie, _ := NewTag(TagModelTiepoint, DTDouble, []float64{0, 0, 0, 500000, 4000000, 0})
+	keys, _ := NewTag(TagGeoKeyDirectory, DTShort, []int{
+		1, 1, 0, 3,
+		GTModelTypeGeoKey, 0, 1, 1,
+		ProjectedCSTypeGeoKey, 0, 1, 32633,
+		3073, TagGeoASCIIParams, 8, 0,
+	})
+	ascii, _ := NewTag(TagGeoASCIIParams, DTAscii, "UTM 33N|")
+	g, err := DecodeGeoTIFF(NewDir(width, length, scale, tie, keys, ascii))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, ok := g.CRS(); !ok || code != 32633 {
+		t.Errorf("CRS = %d, %v, want 32633", code, ok)
+	}
+	if k := g.Keys[3073]; k.ASCII != "UTM 33N" {
+		t.Errorf("citation = %q", k.ASCII)
+	}
+	minX, minY, maxX, maxY, err := g.Bounds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if minX != 500000 || minY != 3999975 || maxX != 500050 || maxY != 4000000 {
+		t.Errorf("bounds = %v %v %v %v", minX, minY, maxX, maxY)
+	}
+
+	if _, err := DecodeGeoTIFF(NewDir(width)); err == nil {
+		t.Error("no error decoding IFD without GeoTIFF tags")
+	}
+	bad, _ := NewTag(TagGeoKeyDirectory, DTShort, []int{1, 1, 0, 2, GTModelTypeGeoKey, 0, 1, 1})
+	if _, err := DecodeGeoTIFF(NewDir(bad)); err == nil {
+		t.Error("no error decoding truncated GeoKey directory")
+	}
+}
+
+func TestDecodeBuffer(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.tif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := &Decoder{Buffer: new(Buffer)}
+	first, err := dec.DecodeBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := dec.DecodeBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != want.String() || second.String() != want.String() {
+		t.Errorf("decodes sharing a Buffer gave %v and %v, want %v", first, second, want)
+	}
+	dec.Buffer.Reset()
+	got, err := dec.DecodeBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("decode after Reset gave %v, want %v", got, want)
+	}
+}
+
+func TestDecodeBytes(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(*dataDir, "sample1.tif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := new(Decoder).DecodeBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("DecodeBytes gave %v, want %v", got, want)
+	}
+	for i := range data {
+		data[i] = 0
+	}
+	if got.String() != want.String() {
+		t.Error("decoded tags share memory with the data")
+	}
+
+	dec := &Decoder{Limits: Limits{MaxBytes: 16}}
+	if _, err := dec.DecodeBytes(data); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("got error %v, want ErrLimitExceeded", err)
+	}
+}
+
+func BenchmarkDecodeLargeDir(b *testing.B) {
+	tags := make([]*Tag, 1000)
+	for i := range tags {
+		tag, err := NewTag(uint16(0x1000+i), DTShort, []int{i, i + 1, i + 2})
+		if err != nil {
+			b.Fatal(err)
+		}
+		tags[i] = tag
+	}
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		var buf bytes.Buffer
+		if err := NewTiff(order, NewDir(tags...)).Encode(&buf); err != nil {
+			b.Fatal(err)
+		}
+		data := buf.Bytes()
+		b.Run(order.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			dec := new(Decoder)
+			for i := 0; i < b.N; i++ {
+				if _, err := dec.DecodeBytes(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestHeaderVariants(t *testing.T) {
+	tag, err := NewTag(0x0112, DTShort, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := NewTiff(binary.LittleEndian, NewDir(tag)).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	withMagic := func(magic string) []byte {
+		return append([]byte("II"+magic), buf.Bytes()[4:]...)
+	}
+
+	for _, tt := range []struct {
+		magic  string
+		policy MagicPolicy
+		ok     bool
+	}{
+		{"*\x00", MagicStrict, true},
+		{"U\x00", MagicDefault, true},
+		{"U\x00", MagicStrict, false},
+		{"RO", MagicDefault, false},
+		{"RO", MagicVariants, true},
+		{"RS", MagicVariants, true},
+		{"XX", MagicVariants, false},
+	} {
+		_, err := (&Decoder{Magic: tt.policy}).Decode(bytes.NewReader(withMagic(tt.magic)))
+		if (err == nil) != tt.ok {
+			t.Errorf("magic %q, policy %d: got error %v, want ok %v", tt.magic, tt.policy, err, tt.ok)
+		}
+	}
+	if _, err := Decode(bytes.NewReader(withMagic("RO"))); err == nil || !strings.Contains(err.Error(), "Olympus ORF") {
+		t.Errorf("got error %v, want it to name Olympus ORF", err)
+	}
+
+	for _, tt := range []struct {
+		header string
+		order  binary.ByteOrder
+		magic  uint16
+		ok     bool
+	}{
+		{"II*\x00", binary.LittleEndian, 42, true},
+		{"MM\x00*", binary.BigEndian, 42, true},
+		{"MMOR", binary.BigEndian, 0x4F52, true},
+		{"IIRO", binary.LittleEndian, 0x4F52, true},
+		{"IIU\x00", binary.LittleEndian, 0x55, true},
+		{"II\x00*", nil, 0, false},
+		{"MM", nil, 0, false},
+		{"\xff\xd8\xff\xe1", nil, 0, false},
+	} {
+		order, magic, ok := ParseHeader([]byte(tt.header))
+		if order != tt.order || magic != tt.magic || ok != tt.ok {
+			t.Errorf("ParseHeader(%q) = %v, 0x%x, %v, want %v, 0x%x, %v", tt.header, order, magic, ok, tt.order, tt.magic, tt.ok)
+		}
+	}
+}
+
+func TestDecodeDirHook(t *testing.T) {
+	// an orientation tag and a make tag stored after the IFD
+	ifd, _ := hex.DecodeString("0002" + "0112" + "0003" + "00000001" + "00060000" + "010F" + "0002" + "00000006" + "0000001E" + "00000000" + "43616E6F6E00")
+	var got []RawTag
+	dec := &Decoder{Hook: func(rt RawTag) bool {
+		got = append(got, rt)
+		return rt.ID != 0x0112
+	}}
+	d, _, err := dec.WithDir("IFD0").DecodeDir(bytes.NewReader(ifd), binary.BigEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("hook called for %d tags, want 2", len(got))
+	}
+	if rt := got[0]; rt.Dir != "IFD0" || rt.ID != 0x0112 || rt.Type != DTShort || rt.Count != 1 || rt.Offset != 2 || !bytes.Equal(rt.Val, []byte{0, 6}) {
+		t.Errorf("first hook call = %+v", rt)
+	}
+	if rt := got[1]; rt.ID != 0x010F || rt.Offset != 14 || string(rt.Val) != "Canon\x00" || rt.Order != binary.BigEndian {
+		t.Errorf("second hook call = %+v", rt)
+	}
+	if len(d.Tags) != 1 || d.Tags[0].Id != 0x010F {
+		t.Errorf("got tags %v, want the make tag only", d.Tags)
+	}
+
+	if _, err := dec.DecodeTag(bytes.NewReader(ifd[2:]), binary.BigEndian); err != ErrSkipped {
+		t.Errorf("DecodeTag: got error %v, want ErrSkipped", err)
+	}
+}
+
+func TestSignedValues(t *testing.T) {
+	tests := []struct {
+		typ      DataType
+		val      []uint32 // stored values, rationals as num, den pairs
+		ints     []int64
+		num, den int64
+	}{
+		{typ: DTSByte, val: []uint32{0xFE}, ints: []int64{-2}},
+		{typ: DTSShort, val: []uint32{0xFFFE}, ints: []int64{-2}},
+		{typ: DTSLong, val: []uint32{0xFFFFFFFE}, ints: []int64{-2}},
+		{typ: DTShort, val: []uint32{0xFFFE}, ints: []int64{0xFFFE}},
+		{typ: DTSRational, val: []uint32{0xFFFFFFFF, 3}, num: -1, den: 3},
+		{typ: DTSRational, val: []uint32{1, 0xFFFFFFFD}, num: -1, den: 3},
+		{typ: DTRational, val: []uint32{0xFFFFFFFF, 3}, num: -1, den: 3},
+	}
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		for _, tst := range tests {
+			size := int(sizeOf(tst.typ))
+			val := make([]byte, size*len(tst.val))
+			for i, v := range tst.val {
+				switch size {
+				case 1:
+					val[i] = byte(v)
+				case 2:
+					order.PutUint16(val[2*i:], uint16(v))
+				default:
+					order.PutUint32(val[4*i:], v)
+				}
+			}
+			entry := make([]byte, 12)
+			order.PutUint16(entry, 0x9204)
+			order.PutUint16(entry[2:], uint16(tst.typ))
+			order.PutUint32(entry[4:], uint32(len(val)/size))
+			if len(val) > 4 {
+				order.PutUint32(entry[8:], 12)
+				entry = append(entry, val...)
+			} else {
+				copy(entry[8:], val)
+			}
+
+			tag, err := DecodeTag(bytes.NewReader(entry), order)
+			if err != nil {
+				t.Fatalf("%v %v: %v", order, typeName(tst.typ), err)
+			}
+			if want := tst.typ != DTShort && tst.typ != DTRational; tag.Signed() != want {
+				t.Errorf("%v %v: Signed() = %v, want %v", order, typeName(tst.typ), tag.Signed(), want)
+			}
+			if tst.ints != nil {
+				if v, err := tag.Int64(0); err != nil || v != tst.ints[0] {
+					t.Errorf("%v %v: Int64 = %v, %v, want %v", order, typeName(tst.typ), v, err, tst.ints[0])
+				}
+				continue
+			}
+			num, den, err := tag.SignedRat(0)
+			if err != nil || num != tst.num || den != tst.den {
+				t.Errorf("%v %v: SignedRat = %v/%v, %v, want %v/%v", order, typeName(tst.typ), num, den, err, tst.num, tst.den)
+			}
+		}
+	}
+}
+
+// zeroCountTiffs holds IFDs with tags without values, as written by some
+// cameras and editors.
+var zeroCountTiffs = []struct {
+	name string
+	hex  string
+}{
+	{"short", "4D4D002A 00000008 0001 0100 0003 00000000 00000000 00000000"},
+	{"ascii with offset", "49492A00 08000000 0100 0F01 0200 00000000 FFFFFF7F 00000000"},
+	{"rational with offset", "4D4D002A 00000008 0001 011A 0005 00000000 7FFFFFFF 00000000"},
+	{"undefined", "49492A00 08000000 0100 7C92 0700 00000000 00000000 00000000"},
+	{"among other tags", "4D4D002A 00000008 0003" +
+		"0100 0003 00000001 00100000" +
+		"010F 0002 00000000 00000000" +
+		"0112 0003 00000001 00010000" +
+		"00000000"},
+}
+
+func TestDecodeZeroCount(t *testing.T) {
+	for _, tt := range zeroCountTiffs {
+		b, err := hex.DecodeString(strings.Replace(tt.hex, " ", "", -1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tif, err := Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var empty *Tag
+		for _, tag := range tif.Dirs[0].Tags {
+			if tag.Count == 0 {
+				empty = tag
+			}
+		}
+		if empty == nil || len(empty.Val) != 0 {
+			t.Errorf("%s: got tags %v, want one with an empty value", tt.name, tif.Dirs[0].Tags)
+			continue
+		}
+		_ = tif.String()
+		if _, err := empty.MarshalJSON(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+
+		var buf bytes.Buffer
+		if err := tif.Encode(&buf); err != nil {
+			t.Errorf("%s: encode failed: %v", tt.name, err)
+			continue
+		}
+		tif2, err := Decode(&buf)
+		if err != nil {
+			t.Errorf("%s: decoding the encoded data failed: %v", tt.name, err)
+			continue
+		}
+		if got, want := len(tif2.Dirs[0].Tags), len(tif.Dirs[0].Tags); got != want {
+			t.Errorf("%s: got %d tags after encoding, want %d", tt.name, got, want)
+		}
+	}
+
+	// every standard data type
+	for typ := DTByte; typ <= DTDouble; typ++ {
+		b, _ := hex.DecodeString("4D4D002A0000000800010100" + fmt.Sprintf("%04X", uint16(typ)) + "000000000000000000000000")
+		tif, err := Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Errorf("%v: %v", typeName(typ), err)
+			continue
+		}
+		tag := tif.Dirs[0].Tags[0]
+		if tag.Count != 0 || len(tag.Val) != 0 || tag.Format() == OtherVal {
+			t.Errorf("%v: got count %d, %d value bytes and format %v", typeName(typ), tag.Count, len(tag.Val), tag.Format())
+		}
+		if s := tag.String(); s == "" {
+			t.Errorf("%v: empty String", typeName(typ))
+		}
+	}
+}
+
+func TestCharset(t *testing.T) {
+	tests := []struct {
+		val     string
+		charset Charset
+		want    string
+	}{
+		{"Jane Doe", CharsetAuto, "Jane Doe"},
+		{"J\xc3\xbcrgen", CharsetAuto, "Jürgen"},
+		{"J\xfcrgen", CharsetAuto, "Jürgen"},
+		{"\xa9 2019", CharsetAuto, "© 2019"},
+		{"J\xfcrgen", CharsetUTF8, "J�rgen"},
+		{"J\xc3\xbcrgen", CharsetLatin1, "JÃ¼rgen"},
+		{"J\xfcrgen", CharsetRaw, "J\xfcrgen"},
+	}
+	for _, tt := range tests {
+		val := append([]byte(tt.val), 0, 0)
+		entry := make([]byte, 12)
+		binary.BigEndian.PutUint16(entry, 0x013B)
+		binary.BigEndian.PutUint16(entry[2:], uint16(DTAscii))
+		binary.BigEndian.PutUint32(entry[4:], uint32(len(val)))
+		binary.BigEndian.PutUint32(entry[8:], 12)
+		dec := &Decoder{Charset: tt.charset}
+		tag, err := dec.DecodeTag(bytes.NewReader(append(entry, val...)), binary.BigEndian)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := tag.StringVal(); got != tt.want {
+			t.Errorf("%q as %v: got %q, want %q", tt.val, tt.charset, got, tt.want)
+		}
+		if raw, err := tag.RawStringVal(); err != nil || string(raw) != tt.val {
+			t.Errorf("%q as %v: got raw value %q (%v)", tt.val, tt.charset, raw, err)
+		}
+	}
+}
+
+func TestPages(t *testing.T) {
+	mustTag := func(id uint16, typ DataType, val interface{}) *Tag {
+		tag, err := NewTag(id, typ, val)
+		if err != nil {
+			t.Fatalf("NewTag(0x%04x): %v", id, err)
+		}
+		return tag
+	}
+	page := func(width, number, total int, extra ...*Tag) *Dir {
+		tags := []*Tag{
+			mustTag(TagImageWidth, DTLong, width),
+			mustTag(TagImageLength, DTShort, 100),
+			mustTag(TagPageNumber, DTShort, []int{number, total}),
+		}
+		return NewDir(append(tags, extra...)...)
+	}
+	tf := &Tiff{Order: binary.LittleEndian, Dirs: []*Dir{
+		page(800, 1, 2, mustTag(TagPageName, DTAscii, "scan")),
+		page(80, 1, 2, mustTag(TagNewSubfileType, DTLong, 1)),
+		page(1600, 0, 2),
+	}}
+	var buf bytes.Buffer
+	if err := tf.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	tf, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := tf.NumPages(); n != 3 {
+		t.Fatalf("NumPages() = %d, want 3", n)
+	}
+	p, err := tf.Page(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Width != 800 || p.Height != 100 || p.Name != "scan" || p.Number != 1 || p.Total != 2 || p.Reduced {
+		t.Errorf("Page(0) = %+v", p)
+	}
+	if s := p.String(); s != "IFD0: 800x100, page 2 of 2" {
+		t.Errorf("String() = %q", s)
+	}
+	if p, _ := tf.Page(1); !p.Reduced {
+		t.Errorf("Page(1) = %+v, want a reduced resolution page", p)
+	}
+	if _, err := tf.Page(3); err == nil {
+		t.Error("no error for page out of range")
+	}
+
+	var got []string
+	tf.EachPage(func(p *Page) error {
+		got = append(got, p.String())
+		return nil
+	})
+	want := []string{"IFD0: 800x100, page 2 of 2", "IFD2: 1600x100, page 1 of 2"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("EachPage visited %q, want %q", got, want)
+	}
+	stop := errors.New("stop")
+	if err := tf.EachPage(func(p *Page) error { return stop }); err != stop {
+		t.Errorf("EachPage returned %v, want %v", err, stop)
+	}
+}
+
+func TestShiftDataOffsets(t *testing.T) {
+	// IFD with two SHORT strips and a LONG tile
+	b, err := hex.DecodeString(strings.Replace("4D4D002A 00000008 0004"+
+		"0111 0003 00000002 0008012C"+
+		"0117 0003 00000002 000A000A"+
+		"0144 0004 00000001 000001F4"+
+		"0145 0004 00000001 00000004"+
+		"00000000", " ", "", -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := tf.Dirs[0]
+
+	chunks, err := d.DataChunks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DataChunk{{0x0111, 0x0117, 0, 8, 10}, {0x0111, 0x0117, 1, 300, 10}, {0x0144, 0x0145, 0, 500, 4}}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("DataChunks() = %v, want %v", chunks, want)
+	}
+
+	changed, err := tf.ShiftDataOffsets(200, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 2 || changed[0].Id != 0x0111 || changed[1].Id != 0x0144 {
+		t.Fatalf("changed tags %v, want StripOffsets and TileOffsets", changed)
+	}
+	if got := hex.EncodeToString(changed[0].Val); got != "00080190" {
+		t.Errorf("StripOffsets value = %s, want 00080190", got)
+	}
+	if v, _ := changed[1].Int64(0); v != 600 {
+		t.Errorf("TileOffsets = %d, want 600", v)
+	}
+
+	// The SHORT strip offsets cannot hold 70008; nothing is changed.
+	if _, err := tf.ShiftDataOffsets(0, 70000); err == nil {
+		t.Error("no error for an offset overflowing its tag")
+	}
+	if v, _ := d.Tags[0].Int64(0); v != 8 {
+		t.Errorf("StripOffsets changed to %d after a failed shift", v)
+	}
+
+	if err := d.Tags[0].SetInt(0, -1); err == nil {
+		t.Error("SetInt accepted a negative SHORT")
+	}
+}
+
+func TestMeter(t *testing.T) {
+	tag, err := NewTag(0x1000, DTShort, []int{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := NewTiff(binary.LittleEndian, NewDir(tag)).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// the 8 value bytes read and the 4 decoded values
+	const want = 8 + 4*8
+	m := new(Meter)
+	if _, err := (&Decoder{Alloc: m.Alloc}).DecodeBytes(data); err != nil {
+		t.Fatal(err)
+	}
+	if m.Bytes() != want {
+		t.Errorf("DecodeBytes counted %d bytes, want %d", m.Bytes(), want)
+	}
+	m.Reset()
+	if _, err := (&Decoder{Alloc: m.Alloc}).Decode(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Bytes(); got != int64(len(data))+want {
+		t.Errorf("Decode counted %d bytes, want %d", got, int64(len(data))+want)
+	}
+
+	for _, lenient := range []bool{false, true} {
+		m := &Meter{Max: want - 1}
+		if _, err := (&Decoder{Lenient: lenient, Alloc: m.Alloc}).DecodeBytes(data); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("lenient %v: got error %v, want ErrLimitExceeded", lenient, err)
+		}
+	}
+}
+
+func TestTagLogValue(t *testing.T) {
+	b, err := hex.DecodeString(strings.Replace("4D4D002A 00000008 0005"+
+		"0102 0003 00000002 00080008"+
+		"010F 0002 00000004 466F6F00"+
+		"011A 0005 00000001 0000004A"+
+		"9000 0007 00000004 30323330"+
+		"927C 0007 00000004 01020304"+
+		"00000000 0000012C 00000001", " ", "", -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"[id=0x0102 type=short count=2 value=[8 8]]",
+		"[id=0x010f type=ascii count=4 value=Foo]",
+		"[id=0x011a type=rational count=1 value=300/1]",
+		"[id=0x9000 type=undefined count=4 value=0230]",
+		"[id=0x927c type=undefined count=4 value=(4 bytes)]",
+	}
+	for i, tag := range tf.Dirs[0].Tags {
+		if got := tag.LogValue().String(); got != want[i] {
+			t.Errorf("LogValue() = %v, want %v", got, want[i])
+		}
+	}
+}
+
+func TestMaxStringLen(t *testing.T) {
+	defer func(n int) { MaxStringLen = n }(MaxStringLen)
+	MaxStringLen = 8
+
+	note, err := NewTag(0x927C, DTUndefined, []byte("ABCDEFGHIJKLMNOP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bits, err := NewTag(0x0102, DTShort, []int{8, 8, 8, 8, 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	short, err := NewTag(0x010F, DTAscii, "Foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	long, err := NewTag(0x010E, DTAscii, "ABCDEFGHIJ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tag, want := range map[*Tag]string{
+		note:  `"ABCDEFGH..." (16 bytes)`,
+		bits:  `[8,8,8,8...] (5 values)`,
+		short: `"Foo"`,
+		long:  `"ABCDEFGH..." (11 bytes)`,
+	} {
+		if got := tag.String(); got != want {
+			t.Errorf("tag 0x%04x String() = %s, want %s", tag.Id, got, want)
+		}
+	}
+	if got, want := NewDir(note, short).String(), `Dir{"ABCDEFGH..." (16 bytes), "Foo", }`; got != want {
+		t.Errorf("Dir.String() = %s, want %s", got, want)
+	}
+
+	MaxStringLen = 0
+	if got := note.String(); got != `"ABCDEFGHIJKLMNOP"` {
+		t.Errorf("untruncated String() = %s", got)
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	orient, err := NewTag(0x0112, DTShort, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	camera, err := NewTag(0x010F, DTAscii, "Camera")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := NewTiff(binary.LittleEndian, NewDir(camera, orient)).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if order, err := CheckOrder(data); order != binary.LittleEndian || err != nil {
+		t.Errorf("CheckOrder = %v, %v, want LittleEndian", order, err)
+	}
+
+	// a corrupted byte order mark
+	bad := append([]byte("MM"), data[2:]...)
+	if order, err := CheckOrder(bad); order != binary.LittleEndian || !errors.Is(err, ErrOrderMismatch) {
+		t.Errorf("corrupted CheckOrder = %v, %v, want LittleEndian and ErrOrderMismatch", order, err)
+	}
+	if _, err := Decode(bytes.NewReader(bad)); !errors.Is(err, ErrOrderMismatch) {
+		t.Errorf("corrupted Decode error = %v, want ErrOrderMismatch", err)
+	}
+	tf, err := (&Decoder{Order: binary.LittleEndian}).Decode(bytes.NewReader(bad))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tf.Dirs) != 1 || len(tf.Dirs[0].Tags) != 2 || tf.Dirs[0].Tags[1].String() != "6" || len(tf.Warnings) != 0 {
+		t.Errorf("forced order decoded %v, warnings %v", tf, tf.Warnings)
+	}
+
+	// an Orientation written in big-endian order
+	swapped := append([]byte(nil), data...)
+	off := binary.LittleEndian.Uint32(swapped[4:])
+	binary.BigEndian.PutUint16(swapped[off+2+12+8:], 6)
+	if tf, err := Decode(bytes.NewReader(swapped)); err != nil || len(tf.Warnings) != 1 || !errors.Is(tf.Warnings[0], ErrOrderMismatch) {
+		t.Errorf("swapped value Decode = %v, %v, want an ErrOrderMismatch warning", tf, err)
+	}
+
+	if _, err := CheckOrder([]byte("II*\x00\xff\xff\xff\xff")); err == nil || errors.Is(err, ErrOrderMismatch) {
+		t.Errorf("CheckOrder without IFD error = %v", err)
+	}
+}
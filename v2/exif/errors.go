@@ -0,0 +1,58 @@
+package exif
+
+import (
+	"fmt"
+
+	exifv1 "github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// ErrLimitExceeded is wrapped by the errors returned when decoding would
+// exceed one of the limits set by WithLimits.
+var ErrLimitExceeded = tiff.ErrLimitExceeded
+
+// A DecodeError is returned when an image holds no EXIF data that can be
+// decoded. Err is the error of version 1, and may wrap ErrLimitExceeded.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string { return e.Err.Error() }
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// A PartialError is returned with an Exif when some of its sub-IFDs (e.g.
+// the GPS IFD) could not be decoded, the tags of the others being usable.
+type PartialError struct {
+	Err error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("exif: some IFDs could not be decoded: %v", e.Err)
+}
+
+func (e *PartialError) Unwrap() error { return e.Err }
+
+// A NotFoundError is returned for a tag not present in an Exif.
+type NotFoundError struct {
+	ID TagID
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("exif: tag %v (%v) is not present", e.ID.Name(), e.ID)
+}
+
+// Unwrap returns the error of version 1 for the tag, so that
+// exifv1.IsTagNotPresentError and errors.As find it.
+func (e *NotFoundError) Unwrap() error { return exifv1.TagNotPresentError(e.ID.Name()) }
+
+// A WriteError is returned when a Writer cannot overwrite the value of a
+// tag. Err is the error of version 1.
+type WriteError struct {
+	ID  TagID
+	Err error
+}
+
+func (e *WriteError) Error() string { return e.Err.Error() }
+
+func (e *WriteError) Unwrap() error { return e.Err }
@@ -0,0 +1,171 @@
+// Package exif is version 2 of the API of package
+// github.com/rwcarlsen/goexif/exif, which stays frozen as version 1. Decoding
+// takes a context and functional options instead of a Decoder, failures are
+// reported by typed errors, tags are addressed by the IFD they are stored in
+// (see TagID), and tag values are overwritten in place by a Writer.
+//
+// Version 2 is built on version 1, so that both decode the same, and Exif
+// values convert between them without decoding again (see FromV1 and
+// Exif.V1). Package exifcompat helps migrating code one call site at a time.
+package exif
+
+import (
+	"context"
+	"io"
+
+	exifv1 "github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// Types shared with version 1.
+type (
+	// TagID identifies a tag by the IFD it is stored in and its tag ID.
+	// The IFD0, IFD1, ExifIFD, GPS and Interop namespaces list the known
+	// TagIDs, e.g. ExifIFD.DateTimeOriginal.
+	TagID = exifv1.TagID
+	// IfdID identifies an IFD.
+	IfdID = exifv1.IfdID
+	// Buffer holds memory reused across decodes, see WithBuffer.
+	Buffer = exifv1.Buffer
+)
+
+// IFDs a tag can be stored in.
+const (
+	Ifd0         = exifv1.Ifd0
+	Ifd1         = exifv1.Ifd1
+	IfdExif      = exifv1.IfdExif
+	IfdGPS       = exifv1.IfdGPS
+	IfdInterop   = exifv1.IfdInterop
+	IfdMakerNote = exifv1.IfdMakerNote
+	IfdSubIFD    = exifv1.IfdSubIFD
+)
+
+// The known tags of each IFD.
+var (
+	IFD0    = exifv1.IFD0
+	IFD1    = exifv1.IFD1
+	ExifIFD = exifv1.ExifIFD
+	GPS     = exifv1.GPS
+	Interop = exifv1.Interop
+)
+
+// An Exif holds the EXIF data decoded from an image.
+type Exif struct {
+	x *exifv1.Exif
+}
+
+// Decode decodes the EXIF data of the image in r, in any format supported by
+// version 1. Reading r stops with the error of ctx once ctx is done. A
+// *DecodeError is returned if r holds no EXIF data that can be decoded. If
+// some sub-IFDs cannot be decoded, the Exif is returned with a
+// *PartialError.
+func Decode(ctx context.Context, r io.Reader, opts ...Option) (*Exif, error) {
+	o := newOptions(opts)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r = ctxReader{ctx, r}
+	var x *exifv1.Exif
+	var err error
+	if len(o.tags) > 0 {
+		x, err = o.dec.DecodeTags(r, o.names()...)
+	} else {
+		x, err = o.dec.Decode(r)
+	}
+	return wrap(ctx, x, err)
+}
+
+// DecodeReaderAt is like Decode, but reads only the parts of the size bytes
+// of r needed to decode the EXIF data, see the DecodeReaderAt method of
+// version 1. WithTags has no effect.
+func DecodeReaderAt(ctx context.Context, r io.ReaderAt, size int64, opts ...Option) (*Exif, error) {
+	o := newOptions(opts)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	x, err := o.dec.DecodeReaderAt(ctxReaderAt{ctx, r}, size)
+	return wrap(ctx, x, err)
+}
+
+// wrap returns the Exif and typed error for the results of a decode with
+// version 1.
+func wrap(ctx context.Context, x *exifv1.Exif, err error) (*Exif, error) {
+	switch {
+	case err == nil:
+		return &Exif{x}, nil
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case x == nil || exifv1.IsCriticalError(err):
+		return nil, &DecodeError{Err: err}
+	}
+	return &Exif{x}, &PartialError{Err: err}
+}
+
+// Tag returns the tag identified by id, or a *NotFoundError if x holds no
+// such tag.
+func (x *Exif) Tag(id TagID) (*tiff.Tag, error) {
+	tag, err := x.x.GetTag(id)
+	if err != nil {
+		return nil, &NotFoundError{ID: id}
+	}
+	return tag, nil
+}
+
+// Tags returns the tags of the IFD ifd, if decoded, in the order they were
+// loaded. The tags of maker notes are those found by the parsers registered
+// with version 1.
+func (x *Exif) Tags(ifd IfdID) []*tiff.Tag {
+	fields := x.x.IfdFields(ifd)
+	tags := make([]*tiff.Tag, len(fields))
+	for i, f := range fields {
+		tags[i] = f.Tag
+	}
+	return tags
+}
+
+// Raw returns the tiff data holding the EXIF data, as found in the image.
+func (x *Exif) Raw() []byte {
+	return x.x.Raw
+}
+
+// Warnings returns the problems worked around while decoding, see WithLenient.
+func (x *Exif) Warnings() []error {
+	return x.x.Warnings()
+}
+
+// FromV1 returns the Exif of version 2 for x, which shares its data.
+func FromV1(x *exifv1.Exif) *Exif {
+	return &Exif{x}
+}
+
+// V1 returns the Exif of version 1 for x, which shares its data, to call
+// code not migrated yet.
+func (x *Exif) V1() *exifv1.Exif {
+	return x.x
+}
+
+// ctxReader reads from r until ctx is done.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// ctxReaderAt reads from r until ctx is done.
+type ctxReaderAt struct {
+	ctx context.Context
+	r   io.ReaderAt
+}
+
+func (r ctxReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.ReadAt(p, off)
+}
@@ -0,0 +1,167 @@
+package exif
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	exifv1 "github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+func sample(t *testing.T) []byte {
+	data, err := os.ReadFile(filepath.Join("..", "..", "exif", "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDecode(t *testing.T) {
+	data := sample(t)
+	x, err := Decode(context.Background(), bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := x.Tag(IFD0.Model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := tag.StringVal(); s != "NIKON D2H" {
+		t.Errorf("Model = %q, want NIKON D2H", s)
+	}
+	if len(x.Tags(IfdGPS)) == 0 {
+		t.Error("no GPS tags")
+	}
+
+	_, err = x.Tag(Interop.Index)
+	var nf *NotFoundError
+	if !errors.As(err, &nf) || nf.ID != Interop.Index {
+		t.Errorf("got error %v, want a NotFoundError", err)
+	}
+	var v1err exifv1.TagNotPresentError
+	if !errors.As(err, &v1err) {
+		t.Errorf("NotFoundError does not unwrap to a TagNotPresentError")
+	}
+
+	if got := x.V1(); got.Raw == nil || FromV1(got).Raw() == nil {
+		t.Error("no tiff data after converting to version 1 and back")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	data := sample(t)
+	var de *DecodeError
+	if _, err := Decode(context.Background(), strings.NewReader("not an image")); !errors.As(err, &de) {
+		t.Errorf("got error %v, want a DecodeError", err)
+	}
+	_, err := Decode(context.Background(), bytes.NewReader(data), WithLimits(tiff.Limits{MaxTagsPerIfd: 2}))
+	if !errors.As(err, &de) || !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("got error %v, want a DecodeError wrapping ErrLimitExceeded", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Decode(ctx, bytes.NewReader(data)); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	r := &cancelReader{data: data, cancel: cancel}
+	if _, err := Decode(ctx, r); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v for a decode canceled while reading, want context.Canceled", err)
+	}
+	if _, err := DecodeReaderAt(ctx, bytes.NewReader(data), int64(len(data))); !errors.Is(err, context.Canceled) {
+		t.Errorf("DecodeReaderAt: got error %v, want context.Canceled", err)
+	}
+}
+
+// cancelReader reads data 16 bytes at a time, calling cancel after the
+// first read.
+type cancelReader struct {
+	data   []byte
+	cancel func()
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	defer r.cancel()
+	if len(p) > 16 {
+		p = p[:16]
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestWithBuffer(t *testing.T) {
+	data := sample(t)
+	var b Buffer
+	for i := 0; i < 2; i++ {
+		x, err := Decode(context.Background(), bytes.NewReader(data), WithBuffer(&b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tag, err := x.Tag(IFD0.Model); err != nil || tag.String() != `"NIKON D2H"` {
+			t.Errorf("decode %d: Model = %v, %v", i, tag, err)
+		}
+		b.Reset()
+	}
+}
+
+func TestWithTags(t *testing.T) {
+	x, err := Decode(context.Background(), bytes.NewReader(sample(t)), WithTags(IFD0.Make, ExifIFD.DateTimeOriginal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []TagID{IFD0.Make, ExifIFD.DateTimeOriginal} {
+		if _, err := x.Tag(id); err != nil {
+			t.Errorf("%v: %v", id, err)
+		}
+	}
+	if _, err := x.Tag(IFD0.Model); err == nil {
+		t.Error("Model decoded, want only the requested tags")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	data := sample(t)
+	x, err := Decode(context.Background(), bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := x.NewWriter(writerAt(data))
+	if err := w.SetString(IFD0.Make, "ACME"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetInt(IFD0.Orientation, 6); err != nil {
+		t.Fatal(err)
+	}
+	var we *WriteError
+	if err := w.SetString(IFD0.Make, strings.Repeat("x", 64)); !errors.As(err, &we) || we.ID != IFD0.Make {
+		t.Errorf("got error %v writing a string too long, want a WriteError", err)
+	}
+	if err := w.SetInt(IFD0.Make, 1); !errors.As(err, &we) {
+		t.Errorf("got error %v writing an integer to an ASCII tag, want a WriteError", err)
+	}
+
+	y, err := Decode(context.Background(), bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag, _ := y.Tag(IFD0.Make); tag == nil || tag.String() != `"ACME"` {
+		t.Errorf("Make = %v after writing, want ACME", tag)
+	}
+	if tag, _ := y.Tag(IFD0.Orientation); tag == nil || tag.String() != "6" {
+		t.Errorf("Orientation = %v after writing, want 6", tag)
+	}
+}
+
+// writerAt writes into a byte slice.
+type writerAt []byte
+
+func (w writerAt) WriteAt(p []byte, off int64) (int, error) {
+	return copy(w[off:], p), nil
+}
@@ -0,0 +1,75 @@
+package exif
+
+import (
+	exifv1 "github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// An Option sets an option of Decode and DecodeReaderAt.
+type Option func(*options)
+
+type options struct {
+	dec  exifv1.Decoder
+	tags []TagID
+}
+
+func newOptions(opts []Option) *options {
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// names returns the field names of the tags of o.
+func (o *options) names() []exifv1.FieldName {
+	names := make([]exifv1.FieldName, len(o.tags))
+	for i, id := range o.tags {
+		names[i] = id.Name()
+	}
+	return names
+}
+
+// WithLenient makes decoding best-effort: tags, IFDs and maker notes that
+// cannot be decoded are skipped, and the problems encountered are reported
+// by Exif.Warnings.
+func WithLenient() Option {
+	return func(o *options) { o.dec.Lenient = true }
+}
+
+// WithLimits bounds the resources used while decoding, see tiff.Limits.
+// Exceeding a limit fails the decode with an error wrapping
+// ErrLimitExceeded, even when decoding leniently.
+func WithLimits(l tiff.Limits) Option {
+	return func(o *options) { o.dec.Limits = l }
+}
+
+// WithRecover makes decoding salvage files whose offset to IFD0 is bogus by
+// scanning the tiff data for a plausible IFD.
+func WithRecover() Option {
+	return func(o *options) { o.dec.Recover = true }
+}
+
+// WithVerify makes decoding check the layout of the tiff data and report
+// the problems found by Exif.Warnings.
+func WithVerify() Option {
+	return func(o *options) { o.dec.Verify = true }
+}
+
+// WithCharset decides how the values of ASCII tags are decoded into
+// strings, see tiff.Charset.
+func WithCharset(c tiff.Charset) Option {
+	return func(o *options) { o.dec.Charset = c }
+}
+
+// WithBuffer makes decoding reuse the memory of b, see Buffer. The Exif
+// values decoded with b must not be used once it is reset.
+func WithBuffer(b *Buffer) Option {
+	return func(o *options) { o.dec.Buffer = b }
+}
+
+// WithTags makes Decode only decode the tags ids, for services that need a
+// few tags from many files. The values of other tags are not read.
+func WithTags(ids ...TagID) Option {
+	return func(o *options) { o.tags = append(o.tags, ids...) }
+}
@@ -0,0 +1,56 @@
+package exif
+
+import (
+	"io"
+
+	exifv1 "github.com/rwcarlsen/goexif/exif"
+)
+
+// A Writer overwrites tag values in place in the file an Exif was decoded
+// from, without re-encoding it, like the Patch methods of version 1: only
+// the bytes of each value are written, so new values must have the size of
+// the old ones. The Exif is not updated; decode the file again to observe
+// the changes.
+type Writer struct {
+	x *Exif
+	w io.WriterAt
+}
+
+// NewWriter returns a Writer writing to w, which must write to the file x
+// was decoded from (or a byte-identical copy of it), e.g. an *os.File opened
+// read-write.
+func (x *Exif) NewWriter(w io.WriterAt) *Writer {
+	return &Writer{x: x, w: w}
+}
+
+// SetRaw overwrites the value of the tag id with the raw bytes val, encoded
+// in the byte order of the tiff data, which must have the length of the
+// current value.
+func (w *Writer) SetRaw(id TagID, val []byte) error {
+	return w.wrap(id, w.x.x.PatchTagID(w.w, id, val))
+}
+
+// SetInt overwrites the values of the integer tag id with v, which must
+// hold as many values as the tag, e.g. SetInt(IFD0.Orientation, 1).
+func (w *Writer) SetInt(id TagID, v ...int64) error {
+	return w.wrap(id, w.x.x.PatchIntID(w.w, id, v...))
+}
+
+// SetString overwrites the value of the ASCII tag id with s, padded with NUL
+// bytes to the length of the current value, which must leave room for at
+// least one terminating NUL byte.
+func (w *Writer) SetString(id TagID, s string) error {
+	return w.wrap(id, w.x.x.PatchStringID(w.w, id, s))
+}
+
+// wrap returns the typed error for err, the error of version 1 writing the
+// tag id.
+func (w *Writer) wrap(id TagID, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case exifv1.IsTagNotPresentError(err):
+		return &NotFoundError{ID: id}
+	}
+	return &WriteError{ID: id, Err: err}
+}
@@ -0,0 +1,54 @@
+// Package exifcompat helps migrating code from version 1 of the exif package
+// API to version 2 one call site at a time: it translates the options of a
+// version 1 Decoder, and decodes with them into an Exif of either version.
+// Exif values convert between the versions with exif.FromV1 and Exif.V1.
+package exifcompat
+
+import (
+	"context"
+	"io"
+
+	exifv1 "github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/v2/exif"
+)
+
+// Options returns the options of version 2 setting the options of dec. The
+// options without a counterpart in version 2 are ignored: Duplicates, as
+// tags are addressed by IFD, and the options only available from version 1,
+// such as Magic, Trace and Hook.
+func Options(dec *exifv1.Decoder) []exif.Option {
+	if dec == nil {
+		return nil
+	}
+	opts := []exif.Option{exif.WithLimits(dec.Limits), exif.WithCharset(dec.Charset)}
+	if dec.Lenient {
+		opts = append(opts, exif.WithLenient())
+	}
+	if dec.Recover {
+		opts = append(opts, exif.WithRecover())
+	}
+	if dec.Verify {
+		opts = append(opts, exif.WithVerify())
+	}
+	if dec.Buffer != nil {
+		opts = append(opts, exif.WithBuffer(dec.Buffer))
+	}
+	return opts
+}
+
+// Decode decodes r like version 2 with the options of dec, see Options, for
+// call sites configuring a version 1 Decoder.
+func Decode(ctx context.Context, dec *exifv1.Decoder, r io.Reader) (*exif.Exif, error) {
+	return exif.Decode(ctx, r, Options(dec)...)
+}
+
+// DecodeV1 is like Decode, but returns the Exif of version 1, for call sites
+// whose results are still used by code not migrated yet. Unlike version 1,
+// it reports errors with the types of version 2.
+func DecodeV1(ctx context.Context, dec *exifv1.Decoder, r io.Reader) (*exifv1.Exif, error) {
+	x, err := Decode(ctx, dec, r)
+	if x == nil {
+		return nil, err
+	}
+	return x.V1(), err
+}
@@ -0,0 +1,44 @@
+package exifcompat
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	exifv1 "github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+	"github.com/rwcarlsen/goexif/v2/exif"
+)
+
+func TestDecode(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "exif", "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := exifv1.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := DecodeV1(context.Background(), &exifv1.Decoder{Lenient: true}, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := x.MarshalJSON()
+	if w, _ := want.MarshalJSON(); !bytes.Equal(got, w) {
+		t.Errorf("DecodeV1 gave %s, want %s", got, w)
+	}
+
+	dec := &exifv1.Decoder{Limits: tiff.Limits{MaxBytes: 16}}
+	_, err = Decode(context.Background(), dec, bytes.NewReader(data))
+	var de *exif.DecodeError
+	if !errors.As(err, &de) || !errors.Is(err, exif.ErrLimitExceeded) {
+		t.Errorf("got error %v, want a DecodeError wrapping ErrLimitExceeded", err)
+	}
+	if opts := Options(nil); opts != nil {
+		t.Errorf("Options(nil) = %v, want none", opts)
+	}
+}
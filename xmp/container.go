@@ -0,0 +1,143 @@
+package xmp
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// Namespaces of the container schemas describing the images appended to a
+// JPEG file after its primary image: Dynamic Depth, and the older Google
+// container of motion photos and Ultra HDR gain maps. GDepth is the
+// namespace of the older Google depth maps, stored in the XMP packet itself.
+const (
+	NsContainer      = "http://ns.google.com/photos/dd/1.0/container/"
+	NsItem           = "http://ns.google.com/photos/dd/1.0/item/"
+	NsGContainer     = "http://ns.google.com/photos/1.0/container/"
+	NsGContainerItem = "http://ns.google.com/photos/1.0/container/item/"
+	NsGDepth         = "http://ns.google.com/photos/1.0/depthmap/"
+)
+
+// ErrNoContainer is returned by Packet.ContainerItems when a packet has no
+// container directory.
+var ErrNoContainer = errors.New("xmp: no container directory")
+
+// A ContainerItem is an item of a container directory: the primary image
+// or an image appended to the file after it.
+type ContainerItem struct {
+	// Mime is the media type of the item, e.g. "image/jpeg".
+	Mime string
+	// Semantic is the role of the item, e.g. "Primary", "Depth",
+	// "Confidence" or "GainMap".
+	Semantic string
+	// URI is the Dynamic Depth URI used by other properties to refer to
+	// the item, if any.
+	URI string
+	// Length is the length in bytes of the item, which may be 0 for the
+	// primary item, and Padding the number of bytes following it.
+	Length, Padding int64
+}
+
+// ContainerItems returns the items of the Dynamic Depth or Google container
+// directory of p, or ErrNoContainer.
+func (p *Packet) ContainerItems() ([]ContainerItem, error) {
+	nsItem := NsItem
+	dir, ok := p.Get(NsContainer, "Directory")
+	if !ok {
+		nsItem = NsGContainerItem
+		if dir, ok = p.Get(NsGContainer, "Directory"); !ok {
+			return nil, ErrNoContainer
+		}
+	}
+	var items []ContainerItem
+	for i, li := range dir.Items {
+		var fields Property
+		for name, f := range li.Fields {
+			if name.Local == "Item" {
+				fields = f
+			}
+		}
+		text := func(name string) string {
+			f, _ := fields.Field(nsItem, name)
+			return strings.TrimSpace(f.Value)
+		}
+		item := ContainerItem{Mime: text("Mime"), Semantic: text("Semantic"), URI: text("URI")}
+		for _, n := range []struct {
+			name string
+			v    *int64
+		}{{"Length", &item.Length}, {"Padding", &item.Padding}} {
+			if s := text(n.name); s != "" {
+				v, err := strconv.ParseInt(s, 10, 64)
+				if err != nil || v < 0 {
+					return nil, fmt.Errorf("xmp: invalid %v %q of container item %d", n.name, s, i)
+				}
+				*n.v = v
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// containerKinds maps the semantics of container items to the kind of
+// their images.
+var containerKinds = map[string]exif.AuxKind{
+	"Depth":      exif.AuxDepth,
+	"Confidence": exif.AuxConfidence,
+	"GainMap":    exif.AuxGainMap,
+}
+
+// ContainerImages returns the auxiliary images of the JPEG file data, whose
+// XMP packet is p: the items of its container directory other than the
+// primary image, such as the depth maps of Dynamic Depth files, located at
+// the end of data by their lengths, and the GDepth depth map held by p. The
+// Type of the images is the semantic of their item, or "GDepth". An error is
+// returned if the items do not fit in data.
+func ContainerImages(p *Packet, data []byte) ([]exif.AuxImage, error) {
+	var imgs []exif.AuxImage
+	items, err := p.ContainerItems()
+	if err != nil && err != ErrNoContainer {
+		return nil, err
+	}
+	if len(items) > 1 {
+		// the first item is the primary image, and the others follow it
+		// up to the end of the file
+		var size int64
+		for _, it := range items[1:] {
+			size += it.Length + it.Padding
+		}
+		off := int64(len(data)) - size
+		if off < 0 {
+			return nil, fmt.Errorf("xmp: container items of %d bytes exceed the %d bytes of the file", size, len(data))
+		}
+		for _, it := range items[1:] {
+			imgs = append(imgs, exif.AuxImage{
+				Kind:   containerKinds[it.Semantic],
+				Type:   it.Semantic,
+				MIME:   it.Mime,
+				Offset: off,
+				Data:   data[off : off+it.Length],
+			})
+			off += it.Length + it.Padding
+		}
+	}
+
+	if s := p.Text(NsGDepth, "Data"); s != "" {
+		depth, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+		if err != nil {
+			return nil, fmt.Errorf("xmp: invalid GDepth:Data: %v", err)
+		}
+		imgs = append(imgs, exif.AuxImage{
+			Kind:   exif.AuxDepth,
+			Type:   "GDepth",
+			MIME:   p.Text(NsGDepth, "Mime"),
+			Offset: -1,
+			Data:   depth,
+		})
+	}
+	return imgs, nil
+}
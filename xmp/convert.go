@@ -0,0 +1,396 @@
+package xmp
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// propForm selects how Convert renders the value of an EXIF field.
+type propForm int
+
+const (
+	// formValue renders text, integers and rationals ("n/d") as simple
+	// values, and fields holding several values as a Seq.
+	formValue propForm = iota
+	// formSeq is like formValue, but renders a Seq for single values too.
+	formSeq
+	// formLangAlt renders text as a language alternative.
+	formLangAlt
+	// formDate renders a date with its fractional seconds and UTC offset.
+	formDate
+	// formVersion renders undefined data holding text, e.g. ExifVersion.
+	formVersion
+	// formByte renders the first byte of undefined data as an integer.
+	formByte
+	// formBytes renders the bytes of undefined data as a Seq of integers.
+	formBytes
+	// formComment renders undefined data prefixed by its character code,
+	// such as GPSProcessingMethod, as text, and UserComment as a language
+	// alternative.
+	formComment
+	formFlash
+	formGPSVersion
+	formGPSCoord
+	formGPSTime
+)
+
+type conversion struct {
+	field exif.FieldName
+	form  propForm
+	props []xmlProp
+}
+
+// conversions follows the XMP specification part 2 (tiff:, exif: schemas),
+// the CIPA Exif 2.3 metadata for XMP guidelines (exifEX: schema) and the
+// Metadata Working Group Guidelines for Handling Image Metadata 2.0, which
+// relate a few fields to properties of the dc:, xmp: and photoshop: schemas.
+var conversions = []conversion{
+	{exif.ImageWidth, formValue, []xmlProp{{NsTIFF, "ImageWidth"}}},
+	{exif.ImageLength, formValue, []xmlProp{{NsTIFF, "ImageLength"}}},
+	{exif.BitsPerSample, formSeq, []xmlProp{{NsTIFF, "BitsPerSample"}}},
+	{exif.Compression, formValue, []xmlProp{{NsTIFF, "Compression"}}},
+	{exif.PhotometricInterpretation, formValue, []xmlProp{{NsTIFF, "PhotometricInterpretation"}}},
+	{exif.Orientation, formValue, []xmlProp{{NsTIFF, "Orientation"}}},
+	{exif.SamplesPerPixel, formValue, []xmlProp{{NsTIFF, "SamplesPerPixel"}}},
+	{exif.PlanarConfiguration, formValue, []xmlProp{{NsTIFF, "PlanarConfiguration"}}},
+	{exif.YCbCrSubSampling, formSeq, []xmlProp{{NsTIFF, "YCbCrSubSampling"}}},
+	{exif.YCbCrPositioning, formValue, []xmlProp{{NsTIFF, "YCbCrPositioning"}}},
+	{exif.XResolution, formValue, []xmlProp{{NsTIFF, "XResolution"}}},
+	{exif.YResolution, formValue, []xmlProp{{NsTIFF, "YResolution"}}},
+	{exif.ResolutionUnit, formValue, []xmlProp{{NsTIFF, "ResolutionUnit"}}},
+	{exif.TransferFunction, formSeq, []xmlProp{{NsTIFF, "TransferFunction"}}},
+	{exif.WhitePoint, formSeq, []xmlProp{{NsTIFF, "WhitePoint"}}},
+	{exif.PrimaryChromaticities, formSeq, []xmlProp{{NsTIFF, "PrimaryChromaticities"}}},
+	{exif.YCbCrCoefficients, formSeq, []xmlProp{{NsTIFF, "YCbCrCoefficients"}}},
+	{exif.ReferenceBlackWhite, formSeq, []xmlProp{{NsTIFF, "ReferenceBlackWhite"}}},
+	{exif.DateTime, formDate, []xmlProp{{NsXMP, "ModifyDate"}}},
+	{exif.ImageDescription, formLangAlt, []xmlProp{{NsDC, "description"}}},
+	{exif.Make, formValue, []xmlProp{{NsTIFF, "Make"}}},
+	{exif.Model, formValue, []xmlProp{{NsTIFF, "Model"}}},
+	{exif.Software, formValue, []xmlProp{{NsTIFF, "Software"}, {NsXMP, "CreatorTool"}}},
+	{exif.Artist, formSeq, []xmlProp{{NsDC, "creator"}}},
+	{exif.Copyright, formLangAlt, []xmlProp{{NsDC, "rights"}}},
+
+	{exif.ExifVersion, formVersion, []xmlProp{{NsEXIF, "ExifVersion"}}},
+	{exif.FlashpixVersion, formVersion, []xmlProp{{NsEXIF, "FlashpixVersion"}}},
+	{exif.ColorSpace, formValue, []xmlProp{{NsEXIF, "ColorSpace"}}},
+	{exif.ComponentsConfiguration, formBytes, []xmlProp{{NsEXIF, "ComponentsConfiguration"}}},
+	{exif.CompressedBitsPerPixel, formValue, []xmlProp{{NsEXIF, "CompressedBitsPerPixel"}}},
+	{exif.PixelXDimension, formValue, []xmlProp{{NsEXIF, "PixelXDimension"}}},
+	{exif.PixelYDimension, formValue, []xmlProp{{NsEXIF, "PixelYDimension"}}},
+	{exif.UserComment, formComment, []xmlProp{{NsEXIF, "UserComment"}}},
+	{exif.RelatedSoundFile, formValue, []xmlProp{{NsEXIF, "RelatedSoundFile"}}},
+	{exif.DateTimeOriginal, formDate, []xmlProp{{NsEXIF, "DateTimeOriginal"}, {NsPhotoshop, "DateCreated"}}},
+	{exif.DateTimeDigitized, formDate, []xmlProp{{NsEXIF, "DateTimeDigitized"}, {NsXMP, "CreateDate"}}},
+	{exif.ExposureTime, formValue, []xmlProp{{NsEXIF, "ExposureTime"}}},
+	{exif.FNumber, formValue, []xmlProp{{NsEXIF, "FNumber"}}},
+	{exif.ExposureProgram, formValue, []xmlProp{{NsEXIF, "ExposureProgram"}}},
+	{exif.SpectralSensitivity, formValue, []xmlProp{{NsEXIF, "SpectralSensitivity"}}},
+	{exif.ISOSpeedRatings, formSeq, []xmlProp{{NsEXIF, "ISOSpeedRatings"}}},
+	{exif.ISOSpeedRatings, formValue, []xmlProp{{NsExifEX, "PhotographicSensitivity"}}},
+	{exif.ShutterSpeedValue, formValue, []xmlProp{{NsEXIF, "ShutterSpeedValue"}}},
+	{exif.ApertureValue, formValue, []xmlProp{{NsEXIF, "ApertureValue"}}},
+	{exif.BrightnessValue, formValue, []xmlProp{{NsEXIF, "BrightnessValue"}}},
+	{exif.ExposureBiasValue, formValue, []xmlProp{{NsEXIF, "ExposureBiasValue"}}},
+	{exif.MaxApertureValue, formValue, []xmlProp{{NsEXIF, "MaxApertureValue"}}},
+	{exif.SubjectDistance, formValue, []xmlProp{{NsEXIF, "SubjectDistance"}}},
+	{exif.MeteringMode, formValue, []xmlProp{{NsEXIF, "MeteringMode"}}},
+	{exif.LightSource, formValue, []xmlProp{{NsEXIF, "LightSource"}}},
+	{exif.Flash, formFlash, []xmlProp{{NsEXIF, "Flash"}}},
+	{exif.FocalLength, formValue, []xmlProp{{NsEXIF, "FocalLength"}}},
+	{exif.SubjectArea, formSeq, []xmlProp{{NsEXIF, "SubjectArea"}}},
+	{exif.FlashEnergy, formValue, []xmlProp{{NsEXIF, "FlashEnergy"}}},
+	{exif.FocalPlaneXResolution, formValue, []xmlProp{{NsEXIF, "FocalPlaneXResolution"}}},
+	{exif.FocalPlaneYResolution, formValue, []xmlProp{{NsEXIF, "FocalPlaneYResolution"}}},
+	{exif.FocalPlaneResolutionUnit, formValue, []xmlProp{{NsEXIF, "FocalPlaneResolutionUnit"}}},
+	{exif.SubjectLocation, formSeq, []xmlProp{{NsEXIF, "SubjectLocation"}}},
+	{exif.ExposureIndex, formValue, []xmlProp{{NsEXIF, "ExposureIndex"}}},
+	{exif.SensingMethod, formValue, []xmlProp{{NsEXIF, "SensingMethod"}}},
+	{exif.FileSource, formByte, []xmlProp{{NsEXIF, "FileSource"}}},
+	{exif.SceneType, formByte, []xmlProp{{NsEXIF, "SceneType"}}},
+	{exif.CustomRendered, formValue, []xmlProp{{NsEXIF, "CustomRendered"}}},
+	{exif.ExposureMode, formValue, []xmlProp{{NsEXIF, "ExposureMode"}}},
+	{exif.WhiteBalance, formValue, []xmlProp{{NsEXIF, "WhiteBalance"}}},
+	{exif.DigitalZoomRatio, formValue, []xmlProp{{NsEXIF, "DigitalZoomRatio"}}},
+	{exif.FocalLengthIn35mmFilm, formValue, []xmlProp{{NsEXIF, "FocalLengthIn35mmFilm"}}},
+	{exif.SceneCaptureType, formValue, []xmlProp{{NsEXIF, "SceneCaptureType"}}},
+	{exif.GainControl, formValue, []xmlProp{{NsEXIF, "GainControl"}}},
+	{exif.Contrast, formValue, []xmlProp{{NsEXIF, "Contrast"}}},
+	{exif.Saturation, formValue, []xmlProp{{NsEXIF, "Saturation"}}},
+	{exif.Sharpness, formValue, []xmlProp{{NsEXIF, "Sharpness"}}},
+	{exif.SubjectDistanceRange, formValue, []xmlProp{{NsEXIF, "SubjectDistanceRange"}}},
+	{exif.ImageUniqueID, formValue, []xmlProp{{NsEXIF, "ImageUniqueID"}}},
+	{exif.CameraOwnerName, formValue, []xmlProp{{NsExifEX, "CameraOwnerName"}}},
+	{exif.BodySerialNumber, formValue, []xmlProp{{NsExifEX, "BodySerialNumber"}}},
+	{exif.LensMake, formValue, []xmlProp{{NsExifEX, "LensMake"}}},
+	{exif.LensModel, formValue, []xmlProp{{NsExifEX, "LensModel"}}},
+	{exif.LensSerialNumber, formValue, []xmlProp{{NsExifEX, "LensSerialNumber"}}},
+
+	{exif.GPSVersionID, formGPSVersion, []xmlProp{{NsEXIF, "GPSVersionID"}}},
+	{exif.GPSLatitude, formGPSCoord, []xmlProp{{NsEXIF, "GPSLatitude"}}},
+	{exif.GPSLongitude, formGPSCoord, []xmlProp{{NsEXIF, "GPSLongitude"}}},
+	{exif.GPSAltitudeRef, formValue, []xmlProp{{NsEXIF, "GPSAltitudeRef"}}},
+	{exif.GPSAltitude, formValue, []xmlProp{{NsEXIF, "GPSAltitude"}}},
+	{exif.GPSTimeStamp, formGPSTime, []xmlProp{{NsEXIF, "GPSTimeStamp"}}},
+	{exif.GPSSatelites, formValue, []xmlProp{{NsEXIF, "GPSSatellites"}}},
+	{exif.GPSStatus, formValue, []xmlProp{{NsEXIF, "GPSStatus"}}},
+	{exif.GPSMeasureMode, formValue, []xmlProp{{NsEXIF, "GPSMeasureMode"}}},
+	{exif.GPSDOP, formValue, []xmlProp{{NsEXIF, "GPSDOP"}}},
+	{exif.GPSSpeedRef, formValue, []xmlProp{{NsEXIF, "GPSSpeedRef"}}},
+	{exif.GPSSpeed, formValue, []xmlProp{{NsEXIF, "GPSSpeed"}}},
+	{exif.GPSTrackRef, formValue, []xmlProp{{NsEXIF, "GPSTrackRef"}}},
+	{exif.GPSTrack, formValue, []xmlProp{{NsEXIF, "GPSTrack"}}},
+	{exif.GPSImgDirectionRef, formValue, []xmlProp{{NsEXIF, "GPSImgDirectionRef"}}},
+	{exif.GPSImgDirection, formValue, []xmlProp{{NsEXIF, "GPSImgDirection"}}},
+	{exif.GPSMapDatum, formValue, []xmlProp{{NsEXIF, "GPSMapDatum"}}},
+	{exif.GPSDestLatitude, formGPSCoord, []xmlProp{{NsEXIF, "GPSDestLatitude"}}},
+	{exif.GPSDestLongitude, formGPSCoord, []xmlProp{{NsEXIF, "GPSDestLongitude"}}},
+	{exif.GPSDestBearingRef, formValue, []xmlProp{{NsEXIF, "GPSDestBearingRef"}}},
+	{exif.GPSDestBearing, formValue, []xmlProp{{NsEXIF, "GPSDestBearing"}}},
+	{exif.GPSDestDistanceRef, formValue, []xmlProp{{NsEXIF, "GPSDestDistanceRef"}}},
+	{exif.GPSDestDistance, formValue, []xmlProp{{NsEXIF, "GPSDestDistance"}}},
+	{exif.GPSProcessingMethod, formComment, []xmlProp{{NsEXIF, "GPSProcessingMethod"}}},
+	{exif.GPSAreaInformation, formComment, []xmlProp{{NsEXIF, "GPSAreaInformation"}}},
+	{exif.GPSDifferential, formValue, []xmlProp{{NsEXIF, "GPSDifferential"}}},
+	{exif.GPSHPositioningError, formValue, []xmlProp{{NsExifEX, "GPSHPositioningError"}}},
+}
+
+// The fractional seconds and UTC offset fields of each date field.
+var dateFields = map[exif.FieldName][2]exif.FieldName{
+	exif.DateTime:          {exif.SubSecTime, exif.OffsetTime},
+	exif.DateTimeOriginal:  {exif.SubSecTimeOriginal, exif.OffsetTimeOriginal},
+	exif.DateTimeDigitized: {exif.SubSecTimeDigitized, exif.OffsetTimeDigitized},
+}
+
+// Convert returns a packet holding the standard XMP properties of the
+// fields of x, e.g. for writing an .xmp sidecar file with Encode so that
+// Lightroom and other asset management software see the EXIF metadata of
+// files they cannot read it from. Fields are mapped as described by the XMP
+// specification part 2 and the Metadata Working Group guidelines: to the
+// exif: and tiff: properties of the same names, to the exifEX: properties of
+// the Exif 2.3 fields, and to dc:description, dc:creator, dc:rights,
+// xmp:CreatorTool, xmp:ModifyDate, xmp:CreateDate and photoshop:DateCreated.
+// Maker notes, thumbnails and fields without a standard property are
+// omitted, as are values that cannot be converted.
+func Convert(x *exif.Exif) *Packet {
+	p := &Packet{Properties: map[xml.Name]Property{}}
+	for _, c := range conversions {
+		tag, err := x.Get(c.field)
+		if err != nil {
+			continue
+		}
+		prop, ok := convert(x, c, tag)
+		if !ok {
+			continue
+		}
+		for _, xp := range c.props {
+			p.Properties[xml.Name{Space: xp.ns, Local: xp.name}] = prop
+		}
+	}
+	return p
+}
+
+// convert returns the property holding the value of tag as selected by c.
+func convert(x *exif.Exif, c conversion, tag *tiff.Tag) (Property, bool) {
+	switch c.form {
+	case formValue, formSeq:
+		vals := tagValues(tag)
+		if len(vals) == 0 {
+			return Property{}, false
+		}
+		if len(vals) == 1 && c.form == formValue {
+			return Property{Value: vals[0]}, true
+		}
+		if c.field == exif.Artist {
+			// several authors are separated by semicolons
+			vals = splitList(vals[0])
+		}
+		return seq(vals), true
+	case formLangAlt:
+		if s := text(tag); s != "" {
+			return langAlt(s), true
+		}
+	case formDate:
+		return dateProp(x, c.field, tag)
+	case formVersion:
+		if s := strings.TrimRight(string(tag.Val), "\x00 "); s != "" {
+			return Property{Value: s}, true
+		}
+	case formByte:
+		if len(tag.Val) > 0 {
+			return Property{Value: strconv.Itoa(int(tag.Val[0]))}, true
+		}
+	case formBytes:
+		if len(tag.Val) > 0 {
+			vals := make([]string, len(tag.Val))
+			for i, b := range tag.Val {
+				vals[i] = strconv.Itoa(int(b))
+			}
+			return seq(vals), true
+		}
+	case formComment:
+		// the first 8 bytes identify the character code
+		if len(tag.Val) > 8 {
+			s := strings.TrimRight(string(tag.Val[8:]), "\x00 ")
+			if s == "" {
+				break
+			}
+			if c.field == exif.UserComment {
+				return langAlt(s), true
+			}
+			return Property{Value: s}, true
+		}
+	case formFlash:
+		return flashProp(tag)
+	case formGPSVersion:
+		if len(tag.Val) == 4 {
+			return Property{Value: fmt.Sprintf("%d.%d.%d.%d", tag.Val[0], tag.Val[1], tag.Val[2], tag.Val[3])}, true
+		}
+	case formGPSCoord:
+		return coordProp(x, c.field, tag)
+	case formGPSTime:
+		if t, err := x.GPSDateTime(); err == nil {
+			return Property{Value: t.Format("2006-01-02T15:04:05.999999999Z07:00")}, true
+		}
+	}
+	return Property{}, false
+}
+
+// tagValues returns the values of tag in XMP text form.
+func tagValues(tag *tiff.Tag) []string {
+	var vals []string
+	switch tag.Format() {
+	case tiff.StringVal:
+		if s := text(tag); s != "" {
+			vals = append(vals, s)
+		}
+	case tiff.IntVal:
+		for i := 0; i < int(tag.Count); i++ {
+			v, _ := tag.Int64(i)
+			vals = append(vals, strconv.FormatInt(v, 10))
+		}
+	case tiff.RatVal:
+		for i := 0; i < int(tag.Count); i++ {
+			num, den, _ := tag.Rat2(i)
+			vals = append(vals, fmt.Sprintf("%d/%d", num, den))
+		}
+	case tiff.FloatVal:
+		for i := 0; i < int(tag.Count); i++ {
+			v, _ := tag.Float(i)
+			vals = append(vals, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+	return vals
+}
+
+func text(tag *tiff.Tag) string {
+	s, err := tag.StringVal()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(s, "\x00"))
+}
+
+func splitList(s string) []string {
+	var vals []string
+	for _, v := range strings.Split(s, ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
+
+func seq(vals []string) Property {
+	p := Property{Array: "Seq"}
+	for _, v := range vals {
+		p.Items = append(p.Items, Property{Value: v})
+	}
+	return p
+}
+
+func langAlt(s string) Property {
+	return Property{Array: "Alt", Items: []Property{{Value: s, Lang: "x-default"}}}
+}
+
+// dateProp returns the XMP date of the date field name, including the
+// fractional seconds and UTC offset given by the fields of dateFields.
+func dateProp(x *exif.Exif, name exif.FieldName, tag *tiff.Tag) (Property, bool) {
+	t, err := time.Parse("2006:01:02 15:04:05", text(tag))
+	if err != nil {
+		return Property{}, false
+	}
+	s := t.Format("2006-01-02T15:04:05")
+	if sub, err := x.Get(dateFields[name][0]); err == nil {
+		if d := text(sub); d != "" && strings.Trim(d, "0123456789") == "" {
+			s += "." + d
+		}
+	}
+	if off, err := x.Get(dateFields[name][1]); err == nil {
+		if o, err := time.Parse("-07:00", text(off)); err == nil {
+			s += o.Format("Z07:00")
+		}
+	}
+	return Property{Value: s}, true
+}
+
+// flashProp returns the exif:Flash structure describing the bits of the
+// Flash field.
+func flashProp(tag *tiff.Tag) (Property, bool) {
+	if tag.Count == 0 {
+		return Property{}, false
+	}
+	v, err := tag.Int64(0)
+	if err != nil {
+		return Property{}, false
+	}
+	flag := func(b int64) Property {
+		if v&b != 0 {
+			return Property{Value: "True"}
+		}
+		return Property{Value: "False"}
+	}
+	return Property{Fields: map[xml.Name]Property{
+		{Space: NsEXIF, Local: "Fired"}:      flag(0x01),
+		{Space: NsEXIF, Local: "Return"}:     {Value: strconv.FormatInt(v>>1&3, 10)},
+		{Space: NsEXIF, Local: "Mode"}:       {Value: strconv.FormatInt(v>>3&3, 10)},
+		{Space: NsEXIF, Local: "Function"}:   flag(0x20),
+		{Space: NsEXIF, Local: "RedEyeMode"}: flag(0x40),
+	}}, true
+}
+
+// coordProp returns the XMP GPSCoordinate of the coordinate field name,
+// e.g. "37,46.2058N": the degrees, the minutes and the reference given by
+// the reference field of name.
+func coordProp(x *exif.Exif, name exif.FieldName, tag *tiff.Tag) (Property, bool) {
+	ref, err := x.Get(name + "Ref")
+	if err != nil || tag.Count != 3 {
+		return Property{}, false
+	}
+	r := strings.ToUpper(text(ref))
+	if r != "N" && r != "S" && r != "E" && r != "W" {
+		return Property{}, false
+	}
+	var v [3]float64
+	for i := range v {
+		num, den, err := tag.Rat2(i)
+		if err != nil || den == 0 {
+			return Property{}, false
+		}
+		v[i] = float64(num) / float64(den)
+	}
+	deg := v[0] + v[1]/60 + v[2]/3600
+	whole := math.Floor(deg)
+	min := math.Round((deg-whole)*60*1e8) / 1e8
+	if min >= 60 {
+		whole, min = whole+1, 0
+	}
+	return Property{Value: fmt.Sprintf("%d,%s%s", int(whole), strconv.FormatFloat(min, 'f', -1, 64), r)}, true
+}
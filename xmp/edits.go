@@ -0,0 +1,48 @@
+package xmp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// Edits is like the Edits method of x, but also inspects the XMP packet p
+// (which may be nil) for evidence of editing: an editor named by
+// xmp:CreatorTool, an xmpMM:DerivedFrom reference to the source document and
+// the events of the xmpMM:History other than its creation.
+func Edits(x *exif.Exif, p *Packet) *exif.EditReport {
+	r := x.Edits()
+	if p == nil {
+		return r
+	}
+	if tool := p.Text(NsXMP, "CreatorTool"); tool != "" && exif.IsEditor(tool) {
+		r.Clues = append(r.Clues, exif.EditClue{Kind: exif.ClueSoftware, Detail: fmt.Sprintf("CreatorTool is %q", tool)})
+	}
+	if _, ok := p.Get(NsXMPMM, "DerivedFrom"); ok {
+		r.Clues = append(r.Clues, exif.EditClue{Kind: exif.ClueHistory, Detail: "derived from another document"})
+	}
+	history, _ := p.Get(NsXMPMM, "History")
+	for _, ev := range history.Items {
+		action := eventField(ev, "action")
+		if action == "" || action == "created" {
+			continue
+		}
+		detail := action
+		if agent := eventField(ev, "softwareAgent"); agent != "" {
+			detail += fmt.Sprintf(" by %q", agent)
+		}
+		if when := eventField(ev, "when"); when != "" {
+			detail += " at " + when
+		}
+		r.Clues = append(r.Clues, exif.EditClue{Kind: exif.ClueHistory, Detail: detail})
+	}
+	return r
+}
+
+// eventField returns the value of the stEvt field name of the history event
+// ev, or "" if it is missing.
+func eventField(ev Property, name string) string {
+	f, _ := ev.Field(NsStEvt, name)
+	return strings.TrimSpace(f.Value)
+}
@@ -0,0 +1,147 @@
+package xmp
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// prefixes holds the usual prefixes of the namespaces Encode declares.
+var prefixes = map[string]string{
+	NsRDF:            "rdf",
+	NsXMP:            "xmp",
+	NsXMPMM:          "xmpMM",
+	NsDC:             "dc",
+	NsTIFF:           "tiff",
+	NsEXIF:           "exif",
+	NsExifEX:         "exifEX",
+	NsPhotoshop:      "photoshop",
+	NsStEvt:          "stEvt",
+	NsGPano:          "GPano",
+	NsContainer:      "Container",
+	NsItem:           "Item",
+	NsGContainer:     "GContainer",
+	NsGContainerItem: "GContainerItem",
+	NsGDepth:         "GDepth",
+	NsGCamera:        "GCamera",
+}
+
+// Encode writes p to w as an x:xmpmeta document, the content of .xmp
+// sidecar files. Properties are written as elements sorted by namespace and
+// name, in a single rdf:Description. Namespaces without a usual prefix are
+// declared with the prefixes ns1, ns2 and so on.
+func (p *Packet) Encode(w io.Writer) error {
+	names := make([]xml.Name, 0, len(p.Properties))
+	for name := range p.Properties {
+		names = append(names, name)
+	}
+	sortNames(names)
+
+	enc := &encoder{prefix: map[string]string{}}
+	for _, name := range names {
+		enc.declare(name.Space, p.Properties[name])
+	}
+
+	b := bufio.NewWriter(w)
+	enc.w = b
+	b.WriteString("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n")
+	b.WriteString(" <rdf:RDF xmlns:rdf=\"" + NsRDF + "\">\n")
+	b.WriteString("  <rdf:Description rdf:about=\"\"")
+	for _, ns := range enc.order {
+		fmt.Fprintf(b, "\n    xmlns:%s=\"%s\"", enc.prefix[ns], escape(ns))
+	}
+	b.WriteString(">\n")
+	for _, name := range names {
+		enc.property(name, p.Properties[name], 3)
+	}
+	b.WriteString("  </rdf:Description>\n </rdf:RDF>\n</x:xmpmeta>\n")
+	return b.Flush()
+}
+
+type encoder struct {
+	w *bufio.Writer
+	// prefix maps the namespaces declared to their prefix, in order.
+	prefix map[string]string
+	order  []string
+}
+
+// declare assigns prefixes to the namespace ns of a property and to the
+// namespaces of the fields of prop.
+func (enc *encoder) declare(ns string, prop Property) {
+	if _, ok := enc.prefix[ns]; !ok && ns != NsRDF && ns != nsXML {
+		prefix, ok := prefixes[ns]
+		if !ok {
+			prefix = fmt.Sprintf("ns%d", len(enc.order)+1)
+		}
+		enc.prefix[ns] = prefix
+		enc.order = append(enc.order, ns)
+	}
+	for _, it := range prop.Items {
+		enc.declare(NsRDF, it)
+	}
+	for _, name := range fieldNames(prop) {
+		enc.declare(name.Space, prop.Fields[name])
+	}
+}
+
+// property writes the element of property prop named name.
+func (enc *encoder) property(name xml.Name, prop Property, depth int) {
+	indent := strings.Repeat(" ", depth)
+	tag := enc.qname(name)
+	enc.w.WriteString(indent + "<" + tag)
+	if prop.Lang != "" {
+		enc.w.WriteString(` xml:lang="` + escape(prop.Lang) + `"`)
+	}
+	switch {
+	case prop.Array != "":
+		enc.w.WriteString(">\n" + indent + " <rdf:" + prop.Array + ">\n")
+		for _, it := range prop.Items {
+			enc.property(xml.Name{Space: NsRDF, Local: "li"}, it, depth+2)
+		}
+		enc.w.WriteString(indent + " </rdf:" + prop.Array + ">\n" + indent)
+	case prop.Fields != nil:
+		enc.w.WriteString(` rdf:parseType="Resource">` + "\n")
+		for _, n := range fieldNames(prop) {
+			enc.property(n, prop.Fields[n], depth+1)
+		}
+		enc.w.WriteString(indent)
+	default:
+		enc.w.WriteString(">" + escape(prop.Value))
+	}
+	enc.w.WriteString("</" + tag + ">\n")
+}
+
+func (enc *encoder) qname(name xml.Name) string {
+	if name.Space == NsRDF {
+		return "rdf:" + name.Local
+	}
+	return enc.prefix[name.Space] + ":" + name.Local
+}
+
+// fieldNames returns the names of the fields of prop, sorted.
+func fieldNames(prop Property) []xml.Name {
+	names := make([]xml.Name, 0, len(prop.Fields))
+	for n := range prop.Fields {
+		names = append(names, n)
+	}
+	sortNames(names)
+	return names
+}
+
+func sortNames(names []xml.Name) {
+	sort.Slice(names, func(i, j int) bool {
+		if names[i].Space != names[j].Space {
+			return names[i].Space < names[j].Space
+		}
+		return names[i].Local < names[j].Local
+	})
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
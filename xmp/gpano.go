@@ -0,0 +1,150 @@
+package xmp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NsGPano is the namespace of the Photo Sphere XMP metadata of panoramas
+// (the GPano schema), written by panorama cameras and stitching software
+// and read by 360° viewers.
+const NsGPano = "http://ns.google.com/photos/1.0/panorama/"
+
+// ErrNoPanorama is returned by Packet.Panorama when a packet has no GPano
+// properties.
+var ErrNoPanorama = errors.New("xmp: no GPano panorama properties")
+
+// A Panorama holds the GPano properties of a panorama image, which place the
+// image within the full panorama it is cropped from and orient the panorama.
+type Panorama struct {
+	// ProjectionType is the projection of the panorama, usually
+	// "equirectangular".
+	ProjectionType    string
+	UsePanoramaViewer bool
+
+	// FullWidth and FullHeight are the size in pixels of the full
+	// panorama: 360° by 180° for an equirectangular projection.
+	FullWidth, FullHeight int
+	// CroppedWidth and CroppedHeight are the size in pixels of the image
+	// within the full panorama, and CroppedLeft and CroppedTop the offset of
+	// its top-left corner. The image covers the full panorama if they are
+	// not given.
+	CroppedWidth, CroppedHeight int
+	CroppedLeft, CroppedTop     int
+
+	// Heading is the compass heading in degrees of the center of the full
+	// panorama, if HasHeading. Pitch and Roll are the angles in degrees the
+	// camera was tilted and rolled by.
+	Heading    float64
+	HasHeading bool
+	Pitch      float64
+	Roll       float64
+
+	// InitialHeading, InitialPitch and InitialRoll are the orientation in
+	// degrees viewers start with, and InitialFOV their horizontal field of
+	// view in degrees, or 0 if not given.
+	InitialHeading, InitialPitch, InitialRoll float64
+	InitialFOV                                float64
+}
+
+// Panorama returns the GPano properties of p, or ErrNoPanorama. An error is
+// returned if the size of the full panorama is missing or if a property
+// does not hold a valid number.
+func (p *Packet) Panorama() (*Panorama, error) {
+	found := false
+	for name := range p.Properties {
+		found = found || name.Space == NsGPano
+	}
+	if !found {
+		return nil, ErrNoPanorama
+	}
+
+	var err error
+	text := func(name string) (string, bool) {
+		prop, ok := p.Get(NsGPano, name)
+		return strings.TrimSpace(prop.Text()), ok
+	}
+	integer := func(name string, v *int) bool {
+		s, ok := text(name)
+		if !ok || err != nil {
+			return false
+		}
+		n, perr := strconv.Atoi(s)
+		if perr != nil || n < 0 {
+			err = fmt.Errorf("xmp: invalid GPano:%v %q", name, s)
+			return false
+		}
+		*v = n
+		return true
+	}
+	number := func(name string, v *float64) bool {
+		s, ok := text(name)
+		if !ok || err != nil {
+			return false
+		}
+		f, perr := parseNumber(s)
+		if perr != nil {
+			err = fmt.Errorf("xmp: invalid GPano:%v %q", name, s)
+			return false
+		}
+		*v = f
+		return true
+	}
+
+	pano := &Panorama{ProjectionType: "equirectangular"}
+	if s, ok := text("ProjectionType"); ok && s != "" {
+		pano.ProjectionType = s
+	}
+	if s, ok := text("UsePanoramaViewer"); ok {
+		pano.UsePanoramaViewer = strings.EqualFold(s, "true")
+	}
+	if !integer("FullPanoWidthPixels", &pano.FullWidth) || !integer("FullPanoHeightPixels", &pano.FullHeight) {
+		if err == nil {
+			err = errors.New("xmp: GPano panorama without FullPanoWidthPixels and FullPanoHeightPixels")
+		}
+		return nil, err
+	}
+	if !integer("CroppedAreaImageWidthPixels", &pano.CroppedWidth) {
+		pano.CroppedWidth = pano.FullWidth
+	}
+	if !integer("CroppedAreaImageHeightPixels", &pano.CroppedHeight) {
+		pano.CroppedHeight = pano.FullHeight
+	}
+	integer("CroppedAreaLeftPixels", &pano.CroppedLeft)
+	integer("CroppedAreaTopPixels", &pano.CroppedTop)
+	pano.HasHeading = number("PoseHeadingDegrees", &pano.Heading)
+	number("PosePitchDegrees", &pano.Pitch)
+	number("PoseRollDegrees", &pano.Roll)
+	number("InitialViewHeadingDegrees", &pano.InitialHeading)
+	number("InitialViewPitchDegrees", &pano.InitialPitch)
+	number("InitialViewRollDegrees", &pano.InitialRoll)
+	number("InitialHorizontalFOVDegrees", &pano.InitialFOV)
+	if err != nil {
+		return nil, err
+	}
+	if pano.FullWidth == 0 || pano.FullHeight == 0 {
+		return nil, fmt.Errorf("xmp: empty GPano panorama of %dx%d pixels", pano.FullWidth, pano.FullHeight)
+	}
+	if pano.CroppedLeft+pano.CroppedWidth > pano.FullWidth || pano.CroppedTop+pano.CroppedHeight > pano.FullHeight {
+		return nil, fmt.Errorf("xmp: GPano cropped area %dx%d+%d+%d exceeds the %dx%d panorama",
+			pano.CroppedWidth, pano.CroppedHeight, pano.CroppedLeft, pano.CroppedTop, pano.FullWidth, pano.FullHeight)
+	}
+	return pano, nil
+}
+
+// Full reports whether the image covers the full panorama.
+func (p *Panorama) Full() bool {
+	return p.CroppedWidth == p.FullWidth && p.CroppedHeight == p.FullHeight
+}
+
+// Bounds returns the area of an equirectangular panorama the image covers,
+// as the longitudes and latitudes in degrees of its left, right, top and
+// bottom edges, with longitude 0 at the center of the full panorama and
+// latitude 90 at its top, as needed to texture a sphere with the image.
+func (p *Panorama) Bounds() (left, right, top, bottom float64) {
+	lon := func(x int) float64 { return 360*float64(x)/float64(p.FullWidth) - 180 }
+	lat := func(y int) float64 { return 90 - 180*float64(y)/float64(p.FullHeight) }
+	return lon(p.CroppedLeft), lon(p.CroppedLeft + p.CroppedWidth), lat(p.CroppedTop), lat(p.CroppedTop + p.CroppedHeight)
+}
@@ -0,0 +1,145 @@
+package xmp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/mknote"
+)
+
+// NsGCamera is the namespace of the Google camera properties, which flag
+// motion photos.
+const NsGCamera = "http://ns.google.com/photos/1.0/camera/"
+
+// ErrNoPairing is returned by Pair when a file holds no pairing
+// identifier or motion photo video.
+var ErrNoPairing = errors.New("xmp: no pairing identifier or motion photo video")
+
+// A Pairing holds what ties a still image to its companion video or to the
+// other photos of its burst, so that library software can keep them
+// together.
+type Pairing struct {
+	// ContentID is the Apple content identifier of a Live Photo, which the
+	// companion video holds as its com.apple.quicktime.content.identifier
+	// metadata, and BurstID the Apple identifier shared by the photos of a
+	// burst.
+	ContentID string
+	BurstID   string
+	// Video is the video embedded in the file after the still image by
+	// Google and Samsung motion photos, and VideoOffset its offset in the
+	// file.
+	Video       []byte
+	VideoOffset int64
+	// PresentationTime is the time in microseconds of the still image in
+	// the video, or -1 if unknown.
+	PresentationTime int64
+}
+
+// Pair returns the pairing identifiers and the embedded motion photo video
+// of an image file, given its EXIF data x, its XMP packet p and its content
+// data, any of which may be nil. Apple identifiers are read from the maker
+// note, which requires the mknote.Apple parser to be registered; motion
+// photos are described by the GCamera properties and the container
+// directory of p (or the older micro video properties), or by the trailer
+// Samsung cameras append to the file. ErrNoPairing is returned if none is
+// found.
+func Pair(x *exif.Exif, p *Packet, data []byte) (*Pairing, error) {
+	pr := &Pairing{PresentationTime: -1}
+	if x != nil {
+		pr.ContentID = exifString(x, mknote.Apple_ContentIdentifier)
+		pr.BurstID = exifString(x, mknote.Apple_BurstUUID)
+	}
+	if p != nil {
+		if err := pr.googleVideo(p, data); err != nil {
+			return nil, err
+		}
+	}
+	if pr.Video == nil {
+		if video, off, ok := samsungVideo(data); ok {
+			pr.Video, pr.VideoOffset = video, off
+		}
+	}
+	if pr.ContentID == "" && pr.BurstID == "" && pr.Video == nil {
+		return nil, ErrNoPairing
+	}
+	return pr, nil
+}
+
+func exifString(x *exif.Exif, name exif.FieldName) string {
+	tag, err := x.Get(name)
+	if err != nil {
+		return ""
+	}
+	s, _ := tag.StringVal()
+	return strings.TrimSpace(s)
+}
+
+// googleVideo sets the video of Google motion photos, described by p, in
+// the file data.
+func (pr *Pairing) googleVideo(p *Packet, data []byte) error {
+	ts := func(name string) {
+		if v, err := strconv.ParseInt(p.Text(NsGCamera, name), 10, 64); err == nil && v >= 0 {
+			pr.PresentationTime = v
+		}
+	}
+	switch {
+	case p.Text(NsGCamera, "MotionPhoto") == "1":
+		ts("MotionPhotoPresentationTimestampUs")
+		imgs, err := ContainerImages(p, data)
+		if err != nil {
+			return err
+		}
+		for _, img := range imgs {
+			if img.Type == "MotionPhoto" {
+				pr.Video, pr.VideoOffset = img.Data, img.Offset
+			}
+		}
+	case p.Text(NsGCamera, "MicroVideo") == "1":
+		ts("MicroVideoPresentationTimestampUs")
+		s := p.Text(NsGCamera, "MicroVideoOffset")
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n <= 0 || n > int64(len(data)) {
+			return fmt.Errorf("xmp: invalid GCamera:MicroVideoOffset %q", s)
+		}
+		pr.VideoOffset = int64(len(data)) - n
+		pr.Video = data[pr.VideoOffset:]
+	}
+	return nil
+}
+
+// samsungVideo returns the MotionPhoto_Data block of the trailer Samsung
+// cameras append to files, and its offset in data. The trailer ends with
+// the size of its directory and "SEFT". The directory starts with "SEFH", a
+// version and the number of entries, each made of 2 unused bytes, the type
+// of a block, its offset back from the directory and its size. Blocks start
+// with 2 unused bytes, their type, the size of their name and the name.
+func samsungVideo(data []byte) ([]byte, int64, bool) {
+	le := binary.LittleEndian
+	if len(data) < 8 || !bytes.HasSuffix(data, []byte("SEFT")) {
+		return nil, 0, false
+	}
+	dir := int64(len(data)) - 8 - int64(le.Uint32(data[len(data)-8:]))
+	if dir < 0 || dir+12 > int64(len(data))-8 || string(data[dir:dir+4]) != "SEFH" {
+		return nil, 0, false
+	}
+	n := int64(le.Uint32(data[dir+8:]))
+	for i := int64(0); i < n && dir+12+12*i+12 <= int64(len(data)); i++ {
+		e := data[dir+12+12*i:]
+		start, size := dir-int64(le.Uint32(e[4:])), int64(le.Uint32(e[8:]))
+		if start < 0 || size < 8 || start+size > dir {
+			continue
+		}
+		block := data[start : start+size]
+		nameLen := int64(le.Uint32(block[4:]))
+		if nameLen > size-8 || string(block[8:8+nameLen]) != "MotionPhoto_Data" {
+			continue
+		}
+		return block[8+nameLen:], start + 8 + nameLen, true
+	}
+	return nil, 0, false
+}
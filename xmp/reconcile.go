@@ -0,0 +1,238 @@
+package xmp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// Source identifies where a reconciled value was taken from.
+type Source int
+
+const (
+	FromExif Source = iota
+	FromXMP
+)
+
+func (s Source) String() string {
+	if s == FromXMP {
+		return "XMP"
+	}
+	return "EXIF"
+}
+
+// A Value is the reconciled value of an EXIF field and the XMP properties
+// describing the same thing.
+type Value struct {
+	Field exif.FieldName
+	// Value is the reconciled value in its XMP text form, e.g.
+	// "2006-01-02T15:04:05" for dates and "1/250" for rationals.
+	Value  string
+	Source Source
+	// Exif and XMP hold the values found in either source, empty if
+	// missing.
+	Exif, XMP string
+	// Conflict reports whether both sources hold a value and the values
+	// differ.
+	Conflict bool
+}
+
+// Reconciled holds the result of Reconcile.
+type Reconciled struct {
+	// Values holds a value for each mapped field present in at least one of
+	// the sources.
+	Values []Value
+}
+
+// Get returns the reconciled value of field name.
+func (r *Reconciled) Get(name exif.FieldName) (Value, bool) {
+	for _, v := range r.Values {
+		if v.Field == name {
+			return v, true
+		}
+	}
+	return Value{}, false
+}
+
+// Conflicts returns the values for which EXIF and XMP disagree.
+func (r *Reconciled) Conflicts() []Value {
+	var vs []Value
+	for _, v := range r.Values {
+		if v.Conflict {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+type valueKind int
+
+const (
+	kindText valueKind = iota
+	kindInt
+	kindRat
+	kindDate
+)
+
+// mapping relates an EXIF field to the XMP properties holding the same
+// information, in order of preference.
+type mapping struct {
+	field exif.FieldName
+	kind  valueKind
+	props []xmlProp
+}
+
+type xmlProp struct{ ns, name string }
+
+// mappings follows the Metadata Working Group Guidelines for Handling Image
+// Metadata 2.0 and the XMP specification part 2 (exif:, tiff: schemas).
+var mappings = []mapping{
+	{exif.DateTimeOriginal, kindDate, []xmlProp{{NsPhotoshop, "DateCreated"}, {NsEXIF, "DateTimeOriginal"}}},
+	{exif.DateTimeDigitized, kindDate, []xmlProp{{NsXMP, "CreateDate"}, {NsEXIF, "DateTimeDigitized"}}},
+	{exif.DateTime, kindDate, []xmlProp{{NsXMP, "ModifyDate"}}},
+	{exif.ImageDescription, kindText, []xmlProp{{NsDC, "description"}}},
+	{exif.Artist, kindText, []xmlProp{{NsDC, "creator"}}},
+	{exif.Copyright, kindText, []xmlProp{{NsDC, "rights"}}},
+	{exif.Make, kindText, []xmlProp{{NsTIFF, "Make"}}},
+	{exif.Model, kindText, []xmlProp{{NsTIFF, "Model"}}},
+	{exif.Software, kindText, []xmlProp{{NsXMP, "CreatorTool"}, {NsTIFF, "Software"}}},
+	{exif.Orientation, kindInt, []xmlProp{{NsTIFF, "Orientation"}}},
+	{exif.ExposureTime, kindRat, []xmlProp{{NsEXIF, "ExposureTime"}}},
+	{exif.FNumber, kindRat, []xmlProp{{NsEXIF, "FNumber"}}},
+	{exif.ISOSpeedRatings, kindInt, []xmlProp{{NsExifEX, "PhotographicSensitivity"}, {NsEXIF, "ISOSpeedRatings"}}},
+	{exif.FocalLength, kindRat, []xmlProp{{NsEXIF, "FocalLength"}}},
+	{exif.FocalLengthIn35mmFilm, kindInt, []xmlProp{{NsEXIF, "FocalLengthIn35mmFilm"}}},
+	{exif.ExposureBiasValue, kindRat, []xmlProp{{NsEXIF, "ExposureBiasValue"}}},
+	{exif.LensMake, kindText, []xmlProp{{NsExifEX, "LensMake"}}},
+	{exif.LensModel, kindText, []xmlProp{{NsExifEX, "LensModel"}}},
+	{exif.PixelXDimension, kindInt, []xmlProp{{NsEXIF, "PixelXDimension"}}},
+	{exif.PixelYDimension, kindInt, []xmlProp{{NsEXIF, "PixelYDimension"}}},
+}
+
+// Reconcile merges the EXIF data in x with the XMP packet p (embedded in the
+// same file or read from a sidecar) following the precedence rules of the
+// Metadata Working Group: the native EXIF value is preferred when present,
+// and XMP values are used for fields missing from EXIF. Where both are
+// present but disagree, the value is flagged as a conflict. Dates are
+// compared by their wall clock time, to the precision of the less precise
+// value, so that an XMP date with a time zone does not conflict with the
+// equivalent EXIF date without one.
+//
+// Either x or p may be nil.
+func Reconcile(x *exif.Exif, p *Packet) *Reconciled {
+	r := &Reconciled{}
+	for _, m := range mappings {
+		var ev, xv string
+		if x != nil {
+			if tag, err := x.Get(m.field); err == nil {
+				ev = exifText(tag, m.kind)
+			}
+		}
+		if p != nil {
+			for _, prop := range m.props {
+				if xv = strings.TrimSpace(p.Text(prop.ns, prop.name)); xv != "" {
+					break
+				}
+			}
+		}
+
+		v := Value{Field: m.field, Exif: ev, XMP: xv}
+		switch {
+		case ev != "":
+			v.Value, v.Source = ev, FromExif
+			v.Conflict = xv != "" && !equal(m.kind, ev, xv)
+		case xv != "":
+			v.Value, v.Source = xv, FromXMP
+		default:
+			continue
+		}
+		r.Values = append(r.Values, v)
+	}
+	return r
+}
+
+// exifText returns the value of tag in the text form used by XMP.
+func exifText(tag *tiff.Tag, kind valueKind) string {
+	if tag.Count == 0 {
+		return ""
+	}
+	switch kind {
+	case kindDate:
+		s, err := tag.StringVal()
+		if err != nil {
+			return ""
+		}
+		t, err := time.Parse("2006:01:02 15:04:05", strings.TrimSpace(s))
+		if err != nil {
+			return ""
+		}
+		return t.Format("2006-01-02T15:04:05")
+	case kindRat:
+		num, den, err := tag.Rat2(0)
+		if err != nil {
+			return ""
+		}
+		return fmt.Sprintf("%d/%d", num, den)
+	case kindInt:
+		v, err := tag.Int64(0)
+		if err != nil {
+			return ""
+		}
+		return strconv.FormatInt(v, 10)
+	}
+	s, err := tag.StringVal()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(s)
+}
+
+// equal reports whether the EXIF value ev and the XMP value xv agree.
+func equal(kind valueKind, ev, xv string) bool {
+	switch kind {
+	case kindDate:
+		// compare wall clock times, ignoring any time zone and
+		// fractional seconds of the XMP date
+		if len(xv) > 10 {
+			if i := strings.IndexAny(xv[10:], "Z+-."); i >= 0 {
+				xv = xv[:10+i]
+			}
+		}
+		if len(xv) < len(ev) {
+			return strings.HasPrefix(ev, xv)
+		}
+		return xv == ev
+	case kindRat, kindInt:
+		e, err1 := parseNumber(ev)
+		x, err2 := parseNumber(xv)
+		if err1 != nil || err2 != nil {
+			return ev == xv
+		}
+		return math.Abs(e-x) <= 1e-6*math.Max(1, math.Abs(e))
+	}
+	return ev == xv
+}
+
+// parseNumber parses an XMP integer, real or rational ("n/d") value.
+func parseNumber(s string) (float64, error) {
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		num, err := strconv.ParseFloat(s[:i], 64)
+		if err != nil {
+			return 0, err
+		}
+		den, err := strconv.ParseFloat(s[i+1:], 64)
+		if err != nil {
+			return 0, err
+		}
+		if den == 0 {
+			return 0, fmt.Errorf("xmp: zero denominator in %q", s)
+		}
+		return num / den, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,260 @@
+// Package xmp implements decoding and encoding of XMP (Extensible Metadata
+// Platform) packets as embedded in JPEG files or stored in .xmp sidecar
+// files, the conversion of EXIF metadata decoded by goexif/exif to XMP, and
+// the reconciliation of XMP with EXIF metadata, and the decoding of the GPano
+// properties of panoramas.
+package xmp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// Namespaces of common XMP schemas.
+const (
+	NsRDF       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	NsXMP       = "http://ns.adobe.com/xap/1.0/"
+	NsXMPMM     = "http://ns.adobe.com/xap/1.0/mm/"
+	NsDC        = "http://purl.org/dc/elements/1.1/"
+	NsTIFF      = "http://ns.adobe.com/tiff/1.0/"
+	NsEXIF      = "http://ns.adobe.com/exif/1.0/"
+	NsExifEX    = "http://cipa.jp/exif/1.0/"
+	NsPhotoshop = "http://ns.adobe.com/photoshop/1.0/"
+	NsStEvt     = "http://ns.adobe.com/xap/1.0/sType/ResourceEvent#"
+
+	nsXML = "http://www.w3.org/XML/1998/namespace"
+)
+
+// JPEGHeader starts the payload of the APP1 segment holding the XMP packet
+// of a JPEG file.
+var JPEGHeader = []byte("http://ns.adobe.com/xap/1.0/\x00")
+
+// ErrNotFound is returned by Extract if there is no XMP packet.
+var ErrNotFound = errors.New("xmp: no XMP packet found")
+
+// A Property is the value of an XMP property: a simple value, an array of
+// values or a structure with named fields.
+type Property struct {
+	// Value is the value of a simple property.
+	Value string
+	// Lang is the language of the value (the xml:lang attribute), mostly
+	// used for the items of language alternatives.
+	Lang string
+	// Array is "Seq", "Bag" or "Alt" if the property is an array, and empty
+	// otherwise.
+	Array string
+	// Items holds the items of an array.
+	Items []Property
+	// Fields holds the fields of a structure.
+	Fields map[xml.Name]Property
+}
+
+// Text returns the value of p as text: the value of a simple property, the
+// default (or first) item of an alternative array, or the items of any other
+// array joined by "; ".
+func (p Property) Text() string {
+	switch p.Array {
+	case "":
+		return p.Value
+	case "Alt":
+		for _, it := range p.Items {
+			if it.Lang == "x-default" {
+				return it.Value
+			}
+		}
+		if len(p.Items) > 0 {
+			return p.Items[0].Value
+		}
+		return ""
+	}
+	vals := make([]string, len(p.Items))
+	for i, it := range p.Items {
+		vals[i] = it.Text()
+	}
+	return strings.Join(vals, "; ")
+}
+
+// Field returns the field of the structure p with the given namespace and
+// name.
+func (p Property) Field(ns, name string) (Property, bool) {
+	f, ok := p.Fields[xml.Name{Space: ns, Local: name}]
+	return f, ok
+}
+
+// A Packet holds the top-level properties of a decoded XMP packet.
+type Packet struct {
+	Properties map[xml.Name]Property
+}
+
+// Get returns the top-level property with the given namespace and name.
+func (p *Packet) Get(ns, name string) (Property, bool) {
+	prop, ok := p.Properties[xml.Name{Space: ns, Local: name}]
+	return prop, ok
+}
+
+// Text returns the text (see Property.Text) of the property with the given
+// namespace and name, or "" if it is not present.
+func (p *Packet) Text(ns, name string) string {
+	prop, _ := p.Get(ns, name)
+	return prop.Text()
+}
+
+// Extract returns the XMP packet embedded in the JPEG file x was decoded from.
+func Extract(x *exif.Exif) (*Packet, error) {
+	for _, seg := range x.Segments() {
+		if bytes.HasPrefix(seg.Data, JPEGHeader) {
+			return Decode(bytes.NewReader(seg.Data[len(JPEGHeader):]))
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// node is an element of the XML tree of a packet.
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*node
+	text     string
+}
+
+func (n *node) attr(space, local string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// Decode decodes the XMP packet in r, which may be an x:xmpmeta document, a
+// bare rdf:RDF element or an xpacket wrapped packet.
+func Decode(r io.Reader) (*Packet, error) {
+	root, err := parseTree(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &Packet{Properties: map[xml.Name]Property{}}
+	var found bool
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n.name.Space == NsRDF && n.name.Local == "RDF" {
+			found = true
+			for _, c := range n.children {
+				if c.name.Space == NsRDF && c.name.Local == "Description" {
+					for name, prop := range descriptionFields(c) {
+						p.Properties[name] = prop
+					}
+				}
+			}
+			return
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(root)
+	if !found {
+		return nil, errors.New("xmp: no rdf:RDF element")
+	}
+	return p, nil
+}
+
+func parseTree(r io.Reader) (*node, error) {
+	dec := xml.NewDecoder(r)
+	root := &node{}
+	stack := []*node{root}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("xmp: %v", err)
+		}
+		top := stack[len(stack)-1]
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: tok.Name, attrs: tok.Attr}
+			top.children = append(top.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			top.text += string(tok)
+		}
+	}
+	return root, nil
+}
+
+// isPropertyAttr reports whether a is an XMP property in attribute form.
+func isPropertyAttr(a xml.Attr) bool {
+	switch a.Name.Space {
+	case NsRDF, nsXML, "xml", "xmlns", "":
+		return false
+	}
+	return true
+}
+
+// descriptionFields returns the properties given by the attributes and
+// children of an rdf:Description element.
+func descriptionFields(n *node) map[xml.Name]Property {
+	fields := map[xml.Name]Property{}
+	for _, a := range n.attrs {
+		if isPropertyAttr(a) {
+			fields[a.Name] = Property{Value: a.Value}
+		}
+	}
+	for _, c := range n.children {
+		fields[c.name] = parseProperty(c)
+	}
+	return fields
+}
+
+// parseProperty parses a property element.
+func parseProperty(n *node) Property {
+	var p Property
+	if lang, ok := n.attr(nsXML, "lang"); ok {
+		p.Lang = lang
+	}
+	if res, ok := n.attr(NsRDF, "resource"); ok {
+		p.Value = res
+		return p
+	}
+	if pt, _ := n.attr(NsRDF, "parseType"); pt == "Resource" {
+		p.Fields = descriptionFields(&node{children: n.children})
+		return p
+	}
+
+	for _, c := range n.children {
+		if c.name.Space != NsRDF {
+			continue
+		}
+		switch c.name.Local {
+		case "Seq", "Bag", "Alt":
+			p.Array = c.name.Local
+			for _, li := range c.children {
+				if li.name.Space == NsRDF && li.name.Local == "li" {
+					p.Items = append(p.Items, parseProperty(li))
+				}
+			}
+			return p
+		case "Description":
+			p.Fields = descriptionFields(c)
+			return p
+		}
+	}
+
+	// structures may also be given by property attributes
+	fields := descriptionFields(&node{attrs: n.attrs, children: n.children})
+	if len(fields) > 0 {
+		p.Fields = fields
+		return p
+	}
+	p.Value = n.text
+	return p
+}
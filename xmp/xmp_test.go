@@ -0,0 +1,437 @@
+package xmp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/mknote"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+const testPacket = `<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:tiff="http://ns.adobe.com/tiff/1.0/"
+    xmlns:photoshop="http://ns.adobe.com/photoshop/1.0/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:exifEX="http://cipa.jp/exif/1.0/"
+    xmlns:xmpMM="http://ns.adobe.com/xap/1.0/mm/"
+    xmlns:stEvt="http://ns.adobe.com/xap/1.0/sType/ResourceEvent#"
+    tiff:Model="iPhone 8"
+    photoshop:DateCreated="2018-04-03T07:54:33.852+02:00">
+   <dc:description>
+    <rdf:Alt>
+     <rdf:li xml:lang="de">Ein Baum</rdf:li>
+     <rdf:li xml:lang="x-default">A tree</rdf:li>
+    </rdf:Alt>
+   </dc:description>
+   <dc:subject>
+    <rdf:Bag><rdf:li>tree</rdf:li><rdf:li>park</rdf:li></rdf:Bag>
+   </dc:subject>
+   <exifEX:PhotographicSensitivity>80</exifEX:PhotographicSensitivity>
+   <xmpMM:History>
+    <rdf:Seq>
+     <rdf:li stEvt:action="saved" stEvt:softwareAgent="Editor 1.0"/>
+     <rdf:li rdf:parseType="Resource">
+      <stEvt:action>converted</stEvt:action>
+     </rdf:li>
+    </rdf:Seq>
+   </xmpMM:History>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+func TestDecode(t *testing.T) {
+	p, err := Decode(strings.NewReader(testPacket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct{ ns, name, want string }{
+		{NsTIFF, "Model", "iPhone 8"},
+		{NsDC, "description", "A tree"},
+		{NsDC, "subject", "tree; park"},
+		{NsExifEX, "PhotographicSensitivity", "80"},
+	} {
+		if got := p.Text(tt.ns, tt.name); got != tt.want {
+			t.Errorf("%v = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	hist, ok := p.Get(NsXMPMM, "History")
+	if !ok || hist.Array != "Seq" || len(hist.Items) != 2 {
+		t.Fatalf("History = %+v, want a Seq of 2 items", hist)
+	}
+	const stEvt = "http://ns.adobe.com/xap/1.0/sType/ResourceEvent#"
+	for i, want := range []string{"saved", "converted"} {
+		if f, _ := hist.Items[i].Field(stEvt, "action"); f.Value != want {
+			t.Errorf("History[%d] action = %q, want %q", i, f.Value, want)
+		}
+	}
+
+	if _, err := Decode(strings.NewReader("<x/>")); err == nil {
+		t.Error("no error decoding XML without rdf:RDF")
+	}
+}
+
+func TestExtract(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("..", "exif", "samples", "raw.exif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seg := func(payload []byte) []byte {
+		n := len(payload) + 2
+		return append([]byte{0xFF, 0xE1, byte(n >> 8), byte(n)}, payload...)
+	}
+	var buf bytes.Buffer
+	buf.Write([]byte{0xFF, 0xD8})
+	buf.Write(seg(raw))
+	buf.Write(seg(append(append([]byte{}, JPEGHeader...), testPacket...)))
+	buf.Write([]byte{0xFF, 0xD9})
+
+	x, err := exif.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := Extract(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := Reconcile(x, p)
+	for _, tt := range []struct {
+		field    exif.FieldName
+		value    string
+		source   Source
+		conflict bool
+	}{
+		{exif.DateTimeOriginal, "2018-04-03T07:54:33", FromExif, false},
+		{exif.Model, "iPhone 7", FromExif, true},
+		{exif.ImageDescription, "A tree", FromXMP, false},
+		{exif.ISOSpeedRatings, "80", FromExif, false},
+	} {
+		v, ok := r.Get(tt.field)
+		if !ok {
+			t.Errorf("%v: no reconciled value", tt.field)
+			continue
+		}
+		if v.Value != tt.value || v.Source != tt.source || v.Conflict != tt.conflict {
+			t.Errorf("%v: got %+v, want %q from %v, conflict %v", tt.field, v, tt.value, tt.source, tt.conflict)
+		}
+	}
+	if c := r.Conflicts(); len(c) != 1 || c[0].XMP != "iPhone 8" {
+		t.Errorf("got conflicts %+v, want only Model", c)
+	}
+}
+
+func TestEdits(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("..", "exif", "samples", "raw.exif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := exif.Decode(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := Edits(x, nil); r.Edited() {
+		t.Errorf("camera-original EXIF reported as edited: %v", r.Clues)
+	}
+	p, err := Decode(strings.NewReader(testPacket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Edits(x, p)
+	var got []string
+	for _, c := range r.Clues {
+		got = append(got, c.String())
+	}
+	want := []string{`history: saved by "Editor 1.0"`, "history: converted"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("clues = %q, want %q", got, want)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "exif", "sample1.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	x, err := exif.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Convert(x).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("decoding the encoded packet: %v\n%s", err, buf.Bytes())
+	}
+
+	for _, tt := range []struct{ ns, name, want string }{
+		{NsTIFF, "Make", "NIKON CORPORATION"},
+		{NsTIFF, "Software", "Opanda PowerExif"},
+		{NsXMP, "CreatorTool", "Opanda PowerExif"},
+		{NsEXIF, "FNumber", "45/10"},
+		{NsEXIF, "ExifVersion", "0220"},
+	} {
+		if got := p.Text(tt.ns, tt.name); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := p.Text(NsEXIF, "GPSLatitude"); !regexp.MustCompile(`^\d+,\d+(\.\d+)?[NS]$`).MatchString(got) {
+		t.Errorf("GPSLatitude: got %q, want a GPSCoordinate", got)
+	}
+	flash, ok := p.Get(NsEXIF, "Flash")
+	if f, _ := flash.Field(NsEXIF, "Fired"); !ok || (f.Value != "True" && f.Value != "False") {
+		t.Errorf("Flash: got %+v, want a Flash structure", flash)
+	}
+	if c := Reconcile(x, p).Conflicts(); len(c) > 0 {
+		t.Errorf("converted packet conflicts with the EXIF data: %+v", c)
+	}
+}
+
+func TestPanorama(t *testing.T) {
+	const packet = `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:GPano="http://ns.google.com/photos/1.0/panorama/"
+    GPano:UsePanoramaViewer="True"
+    GPano:ProjectionType="equirectangular"
+    GPano:FullPanoWidthPixels="8000"
+    GPano:FullPanoHeightPixels="4000"
+    GPano:CroppedAreaImageWidthPixels="4000"
+    GPano:CroppedAreaImageHeightPixels="2000"
+    GPano:CroppedAreaLeftPixels="2000"
+    GPano:CroppedAreaTopPixels="1000">
+   <GPano:PoseHeadingDegrees>350.5</GPano:PoseHeadingDegrees>
+   <GPano:PosePitchDegrees>-2</GPano:PosePitchDegrees>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+	p, err := Decode(strings.NewReader(packet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pano, err := p.Panorama()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Panorama{
+		ProjectionType:    "equirectangular",
+		UsePanoramaViewer: true,
+		FullWidth:         8000, FullHeight: 4000,
+		CroppedWidth: 4000, CroppedHeight: 2000,
+		CroppedLeft: 2000, CroppedTop: 1000,
+		Heading: 350.5, HasHeading: true, Pitch: -2,
+	}
+	if *pano != want {
+		t.Errorf("Panorama() = %+v, want %+v", *pano, want)
+	}
+	if pano.Full() {
+		t.Error("cropped panorama is full")
+	}
+	if l, r, top, b := pano.Bounds(); l != -90 || r != 90 || top != 45 || b != -45 {
+		t.Errorf("Bounds() = %v, %v, %v, %v, want -90, 90, 45, -45", l, r, top, b)
+	}
+
+	// the cropped area defaults to the full panorama
+	p, err = Decode(strings.NewReader(strings.Replace(packet, "Cropped", "Other", -1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pano, err := p.Panorama(); err != nil || !pano.Full() {
+		t.Errorf("Panorama() = %+v, %v, want a full panorama", pano, err)
+	}
+
+	for _, bad := range []string{
+		strings.Replace(packet, `"8000"`, `"wide"`, 1),
+		strings.Replace(packet, "FullPanoHeightPixels", "FullHeight", 1),
+		strings.Replace(packet, `CroppedAreaLeftPixels="2000"`, `CroppedAreaLeftPixels="5000"`, 1),
+	} {
+		p, err := Decode(strings.NewReader(bad))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pano, err := p.Panorama(); err == nil {
+			t.Errorf("Panorama() = %+v, want an error", pano)
+		}
+	}
+	if _, err := (&Packet{}).Panorama(); err != ErrNoPanorama {
+		t.Errorf("Panorama() of an empty packet = %v, want ErrNoPanorama", err)
+	}
+}
+
+func TestContainerImages(t *testing.T) {
+	const packet = `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:Container="http://ns.google.com/photos/dd/1.0/container/"
+    xmlns:Item="http://ns.google.com/photos/dd/1.0/item/"
+    xmlns:GDepth="http://ns.google.com/photos/1.0/depthmap/"
+    GDepth:Mime="image/png"
+    GDepth:Data="iVBORw0K">
+   <Container:Directory>
+    <rdf:Seq>
+     <rdf:li rdf:parseType="Resource">
+      <Container:Item Item:Mime="image/jpeg" Item:Semantic="Primary" Item:Length="0"/>
+     </rdf:li>
+     <rdf:li rdf:parseType="Resource">
+      <Container:Item Item:Mime="image/png" Item:Semantic="Depth" Item:Length="5" Item:Padding="2" Item:URI="android/depthmap"/>
+     </rdf:li>
+     <rdf:li rdf:parseType="Resource">
+      <Container:Item Item:Mime="image/jpeg" Item:Semantic="Confidence" Item:Length="3"/>
+     </rdf:li>
+    </rdf:Seq>
+   </Container:Directory>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+	p, err := Decode(strings.NewReader(packet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := p.ContainerItems()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[1] != (ContainerItem{"image/png", "Depth", "android/depthmap", 5, 2}) {
+		t.Fatalf("ContainerItems() = %+v", items)
+	}
+
+	data := []byte("primary image|depth..con")
+	imgs, err := ContainerImages(p, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		kind exif.AuxKind
+		typ  string
+		off  int64
+		data string
+	}{
+		{exif.AuxDepth, "Depth", 14, "depth"},
+		{exif.AuxConfidence, "Confidence", 21, "con"},
+		{exif.AuxDepth, "GDepth", -1, "\x89PNG\r\n"},
+	}
+	if len(imgs) != len(want) {
+		t.Fatalf("ContainerImages() returned %d images, want %d", len(imgs), len(want))
+	}
+	for i, w := range want {
+		img := imgs[i]
+		if img.Kind != w.kind || img.Type != w.typ || img.Offset != w.off || string(img.Data) != w.data {
+			t.Errorf("image %d = %v %q at %d: %q, want %v %q at %d: %q", i, img.Kind, img.Type, img.Offset, img.Data, w.kind, w.typ, w.off, w.data)
+		}
+	}
+
+	if _, err := ContainerImages(p, data[:5]); err == nil {
+		t.Error("no error for container items exceeding the file")
+	}
+	if _, err := (&Packet{}).ContainerItems(); err != ErrNoContainer {
+		t.Errorf("ContainerItems() of an empty packet = %v, want ErrNoContainer", err)
+	}
+}
+
+func TestPair(t *testing.T) {
+	exif.RegisterParsers(mknote.Apple)
+
+	// an Apple maker note holding the content identifier, with offsets
+	// relative to the start of the note
+	const id = "5A3C0B5D-2C56-4C8E-9B6E-3F9E2D6A1B7C\x00"
+	be := binary.BigEndian
+	note := append([]byte("Apple iOS\x00\x00\x01MM"), 0, 1)
+	note = be.AppendUint16(be.AppendUint16(note, 0x0011), uint16(tiff.DTAscii))
+	note = be.AppendUint32(be.AppendUint32(note, uint32(len(id))), uint32(len(note)+12))
+	note = append(be.AppendUint32(note, 0), id...)
+	mk, err := tiff.NewTag(0x927C, tiff.DTUndefined, note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ifd0 := tiff.NewDir()
+	ifd0.SubDirs = map[uint16]*tiff.Dir{0x8769: tiff.NewDir(mk)}
+	var buf bytes.Buffer
+	if err := tiff.NewTiff(binary.LittleEndian, ifd0).Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	x, err := exif.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr, err := Pair(x, nil, nil); err != nil || pr.ContentID != strings.TrimRight(id, "\x00") || pr.Video != nil {
+		t.Errorf("Pair of a Live Photo = %+v, %v", pr, err)
+	}
+
+	motion := func(props, items string) *Packet {
+		p, err := Decode(strings.NewReader(`<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:GCamera="http://ns.google.com/photos/1.0/camera/"
+    xmlns:Container="http://ns.google.com/photos/1.0/container/"
+    xmlns:Item="http://ns.google.com/photos/1.0/container/item/"
+    ` + props + `>
+   <Container:Directory><rdf:Seq>` + items + `</rdf:Seq></Container:Directory>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	item := func(attrs string) string {
+		return `<rdf:li rdf:parseType="Resource"><Container:Item ` + attrs + `/></rdf:li>`
+	}
+	data := []byte("still image|gain|video")
+	for _, tt := range []struct {
+		name string
+		p    *Packet
+		ts   int64
+	}{
+		{"motion photo", motion(`GCamera:MotionPhoto="1" GCamera:MotionPhotoPresentationTimestampUs="1500000"`,
+			item(`Item:Mime="image/jpeg" Item:Semantic="Primary"`)+
+				item(`Item:Mime="image/jpeg" Item:Semantic="GainMap" Item:Length="4" Item:Padding="1"`)+
+				item(`Item:Mime="video/mp4" Item:Semantic="MotionPhoto" Item:Length="5"`)), 1500000},
+		{"micro video", motion(`GCamera:MicroVideo="1" GCamera:MicroVideoOffset="5"`, ""), -1},
+	} {
+		pr, err := Pair(nil, tt.p, data)
+		if err != nil || string(pr.Video) != "video" || pr.VideoOffset != 17 || pr.PresentationTime != tt.ts {
+			t.Errorf("Pair of a %v = %+v, %v", tt.name, pr, err)
+		}
+	}
+
+	// a Samsung trailer holding the video and another block
+	le := binary.LittleEndian
+	block := func(typ uint16, name, val string) []byte {
+		b := le.AppendUint16(le.AppendUint16(nil, 0), typ)
+		return append(append(le.AppendUint32(b, uint32(len(name))), name...), val...)
+	}
+	video := block(0x0a30, "MotionPhoto_Data", "video")
+	other := block(0x0a01, "Image_UTC_Data", "1600000000000")
+	samsung := append(append([]byte("still image"), video...), other...)
+	dir := le.AppendUint32(le.AppendUint32([]byte("SEFH"), 107), 2)
+	for _, b := range []struct {
+		typ      uint16
+		off, len int
+	}{{0x0a30, len(video) + len(other), len(video)}, {0x0a01, len(other), len(other)}} {
+		dir = le.AppendUint32(le.AppendUint32(le.AppendUint16(le.AppendUint16(dir, 0), b.typ), uint32(b.off)), uint32(b.len))
+	}
+	samsung = append(append(samsung, dir...), le.AppendUint32(nil, uint32(len(dir)))...)
+	samsung = append(samsung, "SEFT"...)
+	if pr, err := Pair(nil, nil, samsung); err != nil || string(pr.Video) != "video" || pr.VideoOffset != int64(bytes.Index(samsung, []byte("video"))) {
+		t.Errorf("Pair of a Samsung motion photo = %+v, %v", pr, err)
+	}
+
+	if _, err := Pair(nil, &Packet{}, []byte("still image")); err != ErrNoPairing {
+		t.Errorf("Pair of a still image = %v, want ErrNoPairing", err)
+	}
+}